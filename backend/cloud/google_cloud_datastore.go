@@ -25,6 +25,13 @@ type LimitedIterator interface {
 	IsDone(errorFromLastNext error) bool
 }
 
+// FilterAndValue holds a filter expression such as "PlayerName >=" along with the value
+// which the filter compares with the property.
+type FilterAndValue struct {
+	FilterExpression string
+	ValueToMatch     interface{}
+}
+
 // LimitedClient defines the subset of the functions of the
 // datastore.Client struct used by the inCloudDatastorePersister
 // struct.
@@ -44,6 +51,16 @@ type LimitedClient interface {
 		filterExpression string,
 		valueToMatch interface{}) LimitedIterator
 
+	// AllMatchingInOrder should return an iterator to at most the given number of
+	// entities of the kind known to the client which are selected by all of the given
+	// filters, ordered by the properties given by the order expressions, which have a
+	// leading "-" for descending order, with ties in each property broken by the next.
+	AllMatchingInOrder(
+		executionContext context.Context,
+		filtersAndValues []FilterAndValue,
+		orderExpressions []string,
+		maximumNumber int) LimitedIterator
+
 	Get(
 		executionContext context.Context,
 		keyName string,
@@ -57,6 +74,25 @@ type LimitedClient interface {
 	Delete(
 		executionContext context.Context,
 		keyName string) error
+
+	// RunInTransaction should run the given function with a transaction, committing
+	// the transaction if the function returns nil, so that the entities read through
+	// the transaction are only written if no other client has changed them since they
+	// were read. The function may be run more than once if the transaction conflicts
+	// with another. It should return the error from the function or from committing
+	// the transaction.
+	RunInTransaction(
+		executionContext context.Context,
+		transactionFunction func(LimitedTransaction) error) error
+}
+
+// LimitedTransaction defines the subset of the functions of the datastore.Transaction
+// struct used by the persisters, with entities identified by their key names as for
+// LimitedClient.
+type LimitedTransaction interface {
+	Get(keyName string, deserializationDestination interface{}) error
+
+	Put(keyName string, deserializationSource interface{}) error
 }
 
 // DatastoreClientProvider defines a factory interface which should provide
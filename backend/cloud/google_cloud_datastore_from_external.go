@@ -97,6 +97,39 @@ func (wrappingClient *WrappingLimitedClient) AllMatching(
 	return &WrappingLimitedIterator{wrappedInterface: resultIterator}
 }
 
+// AllMatchingInOrder returns an iterator to at most the given number of entities
+// which are selected by all of the given filters, ordered by the given properties in
+// turn. The Google Cloud Datastore only allows inequality filters on the same property
+// as the first ordering, and needs a composite index for some combinations of filters
+// and orders.
+func (wrappingClient *WrappingLimitedClient) AllMatchingInOrder(
+	executionContext context.Context,
+	filtersAndValues []FilterAndValue,
+	orderExpressions []string,
+	maximumNumber int) LimitedIterator {
+	queryOnMatchingValues := datastore.NewQuery(wrappingClient.keyKind)
+
+	for _, filterAndValue := range filtersAndValues {
+		queryOnMatchingValues =
+			queryOnMatchingValues.Filter(
+				filterAndValue.FilterExpression,
+				filterAndValue.ValueToMatch)
+	}
+
+	for _, orderExpression := range orderExpressions {
+		queryOnMatchingValues = queryOnMatchingValues.Order(orderExpression)
+	}
+
+	queryOnMatchingValues = queryOnMatchingValues.Limit(maximumNumber)
+
+	resultIterator :=
+		wrappingClient.wrappedInterface.Run(
+			executionContext,
+			queryOnMatchingValues)
+
+	return &WrappingLimitedIterator{wrappedInterface: resultIterator}
+}
+
 // Get implements a wrapper for the Google Cloud Datastore client's Get
 // function.
 func (wrappingClient *WrappingLimitedClient) Get(
@@ -133,12 +166,62 @@ func (wrappingClient *WrappingLimitedClient) Delete(
 		wrappingClient.keyForKind(nameForKey))
 }
 
+// RunInTransaction runs the given function with a WrappingLimitedTransaction around a
+// transaction of the wrapped client, committing it if the function returns nil.
+func (wrappingClient *WrappingLimitedClient) RunInTransaction(
+	executionContext context.Context,
+	transactionFunction func(LimitedTransaction) error) error {
+	_, errorFromTransaction :=
+		wrappingClient.wrappedInterface.RunInTransaction(
+			executionContext,
+			func(wrappedTransaction *datastore.Transaction) error {
+				return transactionFunction(
+					&WrappingLimitedTransaction{
+						wrappedInterface: wrappedTransaction,
+						keyKind:          wrappingClient.keyKind,
+					})
+			})
+
+	return errorFromTransaction
+}
+
 // keyForKind makes a basic key for the given name, for the client's kind.
 func (wrappingClient *WrappingLimitedClient) keyForKind(
 	nameForKey string) *datastore.Key {
 	return datastore.NameKey(wrappingClient.keyKind, nameForKey, nil)
 }
 
+// WrappingLimitedTransaction wraps around a datastore.Transaction to implement
+// the LimitedTransaction interface for entities of the kind of the client which
+// started the transaction.
+type WrappingLimitedTransaction struct {
+	wrappedInterface *datastore.Transaction
+	keyKind          string
+}
+
+// Get deserializes the entity with the given key name into the given destination
+// as part of the transaction.
+func (wrappingTransaction *WrappingLimitedTransaction) Get(
+	nameForKey string,
+	deserializationDestination interface{}) error {
+	return wrappingTransaction.wrappedInterface.Get(
+		datastore.NameKey(wrappingTransaction.keyKind, nameForKey, nil),
+		deserializationDestination)
+}
+
+// Put serializes the given source as the entity with the given key name when the
+// transaction is committed.
+func (wrappingTransaction *WrappingLimitedTransaction) Put(
+	nameForKey string,
+	deserializationSource interface{}) error {
+	_, errorFromPut :=
+		wrappingTransaction.wrappedInterface.Put(
+			datastore.NameKey(wrappingTransaction.keyKind, nameForKey, nil),
+			deserializationSource)
+
+	return errorFromPut
+}
+
 // FixedProjectAndKeyDatastoreClientProvider creates new datastore.Client objects.
 type FixedProjectAndKeyDatastoreClientProvider struct {
 	projectIdentifier string
@@ -82,6 +82,16 @@ func (mockClient *mockLimitedClient) AllMatching(
 	return mockClient.IteratorToReturn
 }
 
+// AllMatchingInOrder returns an iterator to the entities which are
+// selected by the given filters in the given orders.
+func (mockClient *mockLimitedClient) AllMatchingInOrder(
+	executionContext context.Context,
+	filtersAndValues []cloud.FilterAndValue,
+	orderExpressions []string,
+	maximumNumber int) cloud.LimitedIterator {
+	return mockClient.IteratorToReturn
+}
+
 func (mockClient *mockLimitedClient) Get(
 	executionContext context.Context,
 	keyName string,
@@ -102,6 +112,12 @@ func (mockClient *mockLimitedClient) Delete(
 	return mockClient.ErrorToReturn
 }
 
+func (mockClient *mockLimitedClient) RunInTransaction(
+	executionContext context.Context,
+	transactionFunction func(cloud.LimitedTransaction) error) error {
+	return mockClient.ErrorToReturn
+}
+
 func TestInvalidProjectNameProducesError(unitTest *testing.T) {
 	invalidProjectIdentifier := ""
 	clientProvider :=
@@ -0,0 +1,379 @@
+// Package configuration holds the settings with which the backend is started, which
+// are read from a file, from environment variables and from command-line flags, and
+// checks that they make sense together before anything is started with them.
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/defaults"
+	"github.com/benoleary/ilutulestikud/backend/player"
+)
+
+const (
+	// BackendInMemory keeps the state of a store in memory, so that it is lost when the
+	// server stops.
+	BackendInMemory = "memory"
+
+	// BackendPostgresql keeps the state of a store in a PostgreSQL database.
+	BackendPostgresql = "postgresql"
+
+	// BackendCloudDatastore keeps the state of a store in the Google Cloud Datastore.
+	BackendCloudDatastore = "datastore"
+)
+
+// StoreSettings holds the backend which a store uses to persist its state, along with
+// the settings for connecting to that backend. Only the settings for the chosen backend
+// may be given. A store in PostgreSQL must have a connection string, while a store in
+// the Cloud Datastore uses the Ilutulestikud project unless another is given.
+type StoreSettings struct {
+	Backend              string
+	PostgresqlConnection string
+	DatastoreProject     string
+}
+
+// DatastoreClientProvider returns a provider of clients for the Cloud Datastore project
+// of the store, which creates clients for entities of the given kind.
+func (storeSettings StoreSettings) DatastoreClientProvider(
+	keyKind string) cloud.DatastoreClientProvider {
+	if storeSettings.DatastoreProject == "" {
+		return cloud.NewIlutulestikudDatastoreClientProvider(keyKind)
+	}
+
+	return cloud.NewFixedProjectAndKeyDatastoreClientProvider(
+		storeSettings.DatastoreProject,
+		keyKind)
+}
+
+// Stores holds the settings for each store of the backend.
+type Stores struct {
+	Player      StoreSettings
+	Game        StoreSettings
+	Lobby       StoreSettings
+	Series      StoreSettings
+	Spectator   StoreSettings
+	History     StoreSettings
+	Idempotency StoreSettings
+}
+
+// Configuration holds all the settings with which the backend is started. The HTTP
+// endpoints listen on ListenAddress and the gRPC service listens on RPCListenAddress,
+// unless it is empty, in which case the gRPC service is not started. Cross-origin
+// requests are allowed from each of AllowedOrigins, where "*" allows any origin. The
+// players with the identifiers in AdministratorIdentifiers are always administrators.
+type Configuration struct {
+	ListenAddress            string
+	RPCListenAddress         string
+	AllowedOrigins           []string
+	BackendVersion           string
+	ChatLogLength            int
+	ChatColors               []string
+	ChatBackgroundColor      string
+	AdministratorIdentifiers []string
+	Stores                   Stores
+}
+
+// namedStore pairs the settings of a store with the name by which it is known in
+// flags, environment variables and error messages, along with the backends which the
+// store can use and whether the store holds the state of games.
+type namedStore struct {
+	storeName        string
+	storeSettings    *StoreSettings
+	possibleBackends []string
+	isStateOfGames   bool
+}
+
+// Default returns the configuration which the local server has always used, with every
+// store in the Google Cloud Datastore of the Ilutulestikud project.
+func Default() Configuration {
+	inCloudDatastore := StoreSettings{Backend: BackendCloudDatastore}
+
+	return Configuration{
+		ListenAddress:            ":8080",
+		RPCListenAddress:         ":50051",
+		AllowedOrigins:           []string{"http://localhost:4233"},
+		BackendVersion:           "Local version 2.0",
+		ChatLogLength:            8,
+		ChatColors:               defaults.AvailableColors(),
+		ChatBackgroundColor:      defaults.ChatBackgroundColor(),
+		AdministratorIdentifiers: []string{},
+		Stores: Stores{
+			Player:      inCloudDatastore,
+			Game:        inCloudDatastore,
+			Lobby:       inCloudDatastore,
+			Series:      inCloudDatastore,
+			Spectator:   inCloudDatastore,
+			History:     inCloudDatastore,
+			Idempotency: inCloudDatastore,
+		},
+	}
+}
+
+// Validate returns an error describing the first setting which is not valid, either on
+// its own or in combination with the other settings, or nil if all the settings are
+// valid.
+func (configuration *Configuration) Validate() error {
+	errorFromAddresses := configuration.validateAddresses()
+	if errorFromAddresses != nil {
+		return errorFromAddresses
+	}
+
+	errorFromOrigins := configuration.validateOrigins()
+	if errorFromOrigins != nil {
+		return errorFromOrigins
+	}
+
+	if configuration.BackendVersion == "" {
+		return fmt.Errorf("Backend version must not be empty")
+	}
+
+	if configuration.ChatLogLength < 1 {
+		return fmt.Errorf(
+			"Chat log length must be at least 1, not %v",
+			configuration.ChatLogLength)
+	}
+
+	errorFromColors := configuration.validateColors()
+	if errorFromColors != nil {
+		return errorFromColors
+	}
+
+	return configuration.validateStores()
+}
+
+// validateAddresses checks that the addresses for listening are of the form host:port
+// and that the HTTP endpoints and the gRPC service do not try to listen on the same
+// address.
+func (configuration *Configuration) validateAddresses() error {
+	_, _, errorFromHttpAddress := net.SplitHostPort(configuration.ListenAddress)
+	if errorFromHttpAddress != nil {
+		return fmt.Errorf(
+			"Listen address %q is not of the form host:port: %v",
+			configuration.ListenAddress,
+			errorFromHttpAddress)
+	}
+
+	if configuration.RPCListenAddress == "" {
+		return nil
+	}
+
+	_, _, errorFromRpcAddress := net.SplitHostPort(configuration.RPCListenAddress)
+	if errorFromRpcAddress != nil {
+		return fmt.Errorf(
+			"RPC listen address %q is not of the form host:port: %v",
+			configuration.RPCListenAddress,
+			errorFromRpcAddress)
+	}
+
+	if configuration.RPCListenAddress == configuration.ListenAddress {
+		return fmt.Errorf(
+			"RPC listen address must differ from listen address %v",
+			configuration.ListenAddress)
+	}
+
+	return nil
+}
+
+// validateOrigins checks that each allowed origin is either "*" on its own or a scheme
+// and host with neither path nor query, as sent by browsers in the Origin header.
+func (configuration *Configuration) validateOrigins() error {
+	for _, allowedOrigin := range configuration.AllowedOrigins {
+		if allowedOrigin == "*" {
+			if len(configuration.AllowedOrigins) > 1 {
+				return fmt.Errorf(
+					"Allowed origin * allows any origin so cannot be combined with others in %v",
+					configuration.AllowedOrigins)
+			}
+
+			continue
+		}
+
+		parsedOrigin, errorFromParse := url.Parse(allowedOrigin)
+		if (errorFromParse != nil) ||
+			((parsedOrigin.Scheme != "http") && (parsedOrigin.Scheme != "https")) ||
+			(parsedOrigin.Host == "") ||
+			(parsedOrigin.Path != "") ||
+			(parsedOrigin.RawQuery != "") ||
+			(parsedOrigin.Fragment != "") ||
+			(parsedOrigin.User != nil) {
+			return fmt.Errorf(
+				"Allowed origin %q is not of the form http://host or https://host",
+				allowedOrigin)
+		}
+	}
+
+	return nil
+}
+
+// validateColors checks that there is at least one chat color, that no chat color is
+// listed twice, and that the chat colors and the background color are colors which the
+// player package can compare with each other.
+func (configuration *Configuration) validateColors() error {
+	if len(configuration.ChatColors) == 0 {
+		return fmt.Errorf("There must be at least one chat color")
+	}
+
+	_, errorFromBackground :=
+		player.ContrastRatio(
+			configuration.ChatBackgroundColor,
+			configuration.ChatBackgroundColor)
+	if errorFromBackground != nil {
+		return fmt.Errorf(
+			"Chat background color is not valid: %v",
+			errorFromBackground)
+	}
+
+	listedColors := make(map[string]bool, len(configuration.ChatColors))
+	for _, chatColor := range configuration.ChatColors {
+		if listedColors[chatColor] {
+			return fmt.Errorf("Chat color %v is listed more than once", chatColor)
+		}
+
+		listedColors[chatColor] = true
+
+		_, errorFromContrast :=
+			player.ContrastRatio(chatColor, configuration.ChatBackgroundColor)
+		if errorFromContrast != nil {
+			return fmt.Errorf(
+				"Chat color %v must be #RRGGBB or a color of the default palette: %v",
+				chatColor,
+				errorFromContrast)
+		}
+	}
+
+	return nil
+}
+
+// validateStores checks that each store uses a backend which it supports, with the
+// settings needed for that backend and only those, and that the players are kept at
+// least as long as the games which refer to them, as games which outlive the server
+// would otherwise refer to players who do not.
+func (configuration *Configuration) validateStores() error {
+	allStores := configuration.namedStores()
+
+	for _, storeToCheck := range allStores {
+		errorFromStore := storeToCheck.validate()
+		if errorFromStore != nil {
+			return errorFromStore
+		}
+	}
+
+	if configuration.Stores.Player.Backend != BackendInMemory {
+		return nil
+	}
+
+	for _, storeToCheck := range allStores {
+		if storeToCheck.isStateOfGames &&
+			(storeToCheck.storeSettings.Backend != BackendInMemory) {
+			return fmt.Errorf(
+				"Player store in memory cannot be combined with %v store in %v,"+
+					" as the %v store would refer to players who are lost when the server stops",
+				storeToCheck.storeName,
+				storeToCheck.storeSettings.Backend,
+				storeToCheck.storeName)
+		}
+	}
+
+	return nil
+}
+
+// namedStores returns the settings of every store, along with the name and the possible
+// backends of the store, in a fixed order.
+func (configuration *Configuration) namedStores() []namedStore {
+	backendsForGames := []string{BackendInMemory, BackendCloudDatastore}
+
+	return []namedStore{
+		{
+			storeName:     "player",
+			storeSettings: &configuration.Stores.Player,
+			possibleBackends: []string{
+				BackendInMemory,
+				BackendPostgresql,
+				BackendCloudDatastore,
+			},
+		},
+		{
+			storeName:        "game",
+			storeSettings:    &configuration.Stores.Game,
+			possibleBackends: backendsForGames,
+			isStateOfGames:   true,
+		},
+		{
+			storeName:        "lobby",
+			storeSettings:    &configuration.Stores.Lobby,
+			possibleBackends: backendsForGames,
+			isStateOfGames:   true,
+		},
+		{
+			storeName:        "series",
+			storeSettings:    &configuration.Stores.Series,
+			possibleBackends: backendsForGames,
+			isStateOfGames:   true,
+		},
+		{
+			storeName:        "spectator",
+			storeSettings:    &configuration.Stores.Spectator,
+			possibleBackends: backendsForGames,
+			isStateOfGames:   true,
+		},
+		{
+			storeName:        "history",
+			storeSettings:    &configuration.Stores.History,
+			possibleBackends: backendsForGames,
+			isStateOfGames:   true,
+		},
+		{
+			storeName:        "idempotency",
+			storeSettings:    &configuration.Stores.Idempotency,
+			possibleBackends: []string{BackendInMemory, BackendCloudDatastore},
+		},
+	}
+}
+
+// validate checks that the store uses one of its possible backends, with the settings
+// needed for that backend and without settings for other backends.
+func (storeToCheck namedStore) validate() error {
+	chosenBackend := storeToCheck.storeSettings.Backend
+	isPossible := false
+	for _, possibleBackend := range storeToCheck.possibleBackends {
+		if possibleBackend == chosenBackend {
+			isPossible = true
+		}
+	}
+
+	if !isPossible {
+		return fmt.Errorf(
+			"Store %v cannot use backend %q, only one of %v",
+			storeToCheck.storeName,
+			chosenBackend,
+			storeToCheck.possibleBackends)
+	}
+
+	hasPostgresqlConnection := storeToCheck.storeSettings.PostgresqlConnection != ""
+	if (chosenBackend == BackendPostgresql) && !hasPostgresqlConnection {
+		return fmt.Errorf(
+			"Store %v uses %v so must have a PostgreSQL connection",
+			storeToCheck.storeName,
+			BackendPostgresql)
+	}
+
+	if (chosenBackend != BackendPostgresql) && hasPostgresqlConnection {
+		return fmt.Errorf(
+			"Store %v uses %v so cannot have a PostgreSQL connection",
+			storeToCheck.storeName,
+			chosenBackend)
+	}
+
+	hasDatastoreProject := storeToCheck.storeSettings.DatastoreProject != ""
+	if (chosenBackend != BackendCloudDatastore) && hasDatastoreProject {
+		return fmt.Errorf(
+			"Store %v uses %v so cannot have a Cloud Datastore project",
+			storeToCheck.storeName,
+			chosenBackend)
+	}
+
+	return nil
+}
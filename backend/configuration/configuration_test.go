@@ -0,0 +1,548 @@
+package configuration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/configuration"
+)
+
+// environmentFrom returns a function to look up environment variables in the given map
+// rather than in the environment of the process.
+func environmentFrom(
+	environmentVariables map[string]string) func(string) (string, bool) {
+	return func(variableName string) (string, bool) {
+		variableValue, isSet := environmentVariables[variableName]
+		return variableValue, isSet
+	}
+}
+
+// writeConfigurationFile writes the given content into a file in a temporary directory
+// and returns the path of the file.
+func writeConfigurationFile(unitTest *testing.T, fileContent string) string {
+	temporaryDirectory, errorFromDirectory := ioutil.TempDir("", "configuration_test")
+	if errorFromDirectory != nil {
+		unitTest.Fatalf("could not create temporary directory: %v", errorFromDirectory)
+	}
+
+	unitTest.Cleanup(func() { os.RemoveAll(temporaryDirectory) })
+
+	filePath := filepath.Join(temporaryDirectory, "configuration.json")
+	errorFromWrite := ioutil.WriteFile(filePath, []byte(fileContent), 0600)
+	if errorFromWrite != nil {
+		unitTest.Fatalf("could not write configuration file: %v", errorFromWrite)
+	}
+
+	return filePath
+}
+
+// inMemoryStores returns settings which keep every store in memory.
+func inMemoryStores() configuration.Stores {
+	inMemory := configuration.StoreSettings{Backend: configuration.BackendInMemory}
+	return configuration.Stores{
+		Player:      inMemory,
+		Game:        inMemory,
+		Lobby:       inMemory,
+		Series:      inMemory,
+		Spectator:   inMemory,
+		History:     inMemory,
+		Idempotency: inMemory,
+	}
+}
+
+func TestDefaultIsValidAndLoadedWithoutOverrides(unitTest *testing.T) {
+	defaultConfiguration := configuration.Default()
+
+	errorFromValidation := defaultConfiguration.Validate()
+	if errorFromValidation != nil {
+		unitTest.Fatalf("default configuration is not valid: %v", errorFromValidation)
+	}
+
+	loadedConfiguration, errorFromLoad :=
+		configuration.Load("test", []string{}, environmentFrom(nil))
+	if errorFromLoad != nil {
+		unitTest.Fatalf("could not load configuration: %v", errorFromLoad)
+	}
+
+	if !reflect.DeepEqual(loadedConfiguration, defaultConfiguration) {
+		unitTest.Fatalf(
+			"loaded configuration %+v without overrides instead of default %+v",
+			loadedConfiguration,
+			defaultConfiguration)
+	}
+}
+
+func TestFlagsOverrideEnvironmentWhichOverridesFile(unitTest *testing.T) {
+	filePath :=
+		writeConfigurationFile(
+			unitTest,
+			`{
+				"ListenAddress": ":1000",
+				"RPCListenAddress": ":2000",
+				"BackendVersion": "from file",
+				"ChatLogLength": 3,
+				"Stores": {"Player": {"Backend": "postgresql", "PostgresqlConnection": "file"}}
+			}`)
+
+	testCases := []struct {
+		testName              string
+		commandLineArguments  []string
+		environmentVariables  map[string]string
+		expectedListenAddress string
+		expectedRPCAddress    string
+		expectedVersion       string
+		expectedChatLogLength int
+		expectedConnection    string
+	}{
+		{
+			testName:              "File from flag",
+			commandLineArguments:  []string{"-config-file", filePath},
+			environmentVariables:  nil,
+			expectedListenAddress: ":1000",
+			expectedRPCAddress:    ":2000",
+			expectedVersion:       "from file",
+			expectedChatLogLength: 3,
+			expectedConnection:    "file",
+		},
+		{
+			testName:             "File from environment",
+			commandLineArguments: []string{},
+			environmentVariables: map[string]string{
+				configuration.FileEnvironmentVariable: filePath,
+			},
+			expectedListenAddress: ":1000",
+			expectedRPCAddress:    ":2000",
+			expectedVersion:       "from file",
+			expectedChatLogLength: 3,
+			expectedConnection:    "file",
+		},
+		{
+			testName:             "Environment over file",
+			commandLineArguments: []string{"-config-file", filePath},
+			environmentVariables: map[string]string{
+				"ILUTULESTIKUD_LISTEN_ADDRESS":               ":1001",
+				"ILUTULESTIKUD_BACKEND_VERSION":              "from environment",
+				"ILUTULESTIKUD_CHAT_LOG_LENGTH":              "4",
+				"ILUTULESTIKUD_PLAYER_POSTGRESQL_CONNECTION": "environment",
+			},
+			expectedListenAddress: ":1001",
+			expectedRPCAddress:    ":2000",
+			expectedVersion:       "from environment",
+			expectedChatLogLength: 4,
+			expectedConnection:    "environment",
+		},
+		{
+			testName: "Flags over environment",
+			commandLineArguments: []string{
+				"-config-file", filePath,
+				"-listen-address", ":1002",
+				"-rpc-listen-address=",
+				"-chat-log-length", "5",
+				"-player-postgresql-connection", "flag",
+			},
+			environmentVariables: map[string]string{
+				"ILUTULESTIKUD_LISTEN_ADDRESS":               ":1001",
+				"ILUTULESTIKUD_BACKEND_VERSION":              "from environment",
+				"ILUTULESTIKUD_CHAT_LOG_LENGTH":              "4",
+				"ILUTULESTIKUD_PLAYER_POSTGRESQL_CONNECTION": "environment",
+			},
+			expectedListenAddress: ":1002",
+			expectedRPCAddress:    "",
+			expectedVersion:       "from environment",
+			expectedChatLogLength: 5,
+			expectedConnection:    "flag",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			loadedConfiguration, errorFromLoad :=
+				configuration.Load(
+					"test",
+					testCase.commandLineArguments,
+					environmentFrom(testCase.environmentVariables))
+			if errorFromLoad != nil {
+				unitTest.Fatalf("could not load configuration: %v", errorFromLoad)
+			}
+
+			if (loadedConfiguration.ListenAddress != testCase.expectedListenAddress) ||
+				(loadedConfiguration.RPCListenAddress != testCase.expectedRPCAddress) ||
+				(loadedConfiguration.BackendVersion != testCase.expectedVersion) ||
+				(loadedConfiguration.ChatLogLength != testCase.expectedChatLogLength) ||
+				(loadedConfiguration.Stores.Player.PostgresqlConnection !=
+					testCase.expectedConnection) {
+				unitTest.Fatalf(
+					"loaded configuration %+v did not have expected addresses %q and %q,"+
+						" version %q, chat log length %v and connection %q",
+					loadedConfiguration,
+					testCase.expectedListenAddress,
+					testCase.expectedRPCAddress,
+					testCase.expectedVersion,
+					testCase.expectedChatLogLength,
+					testCase.expectedConnection)
+			}
+
+			// The other stores keep their default settings, as the file only overrides
+			// the fields which it has.
+			if loadedConfiguration.Stores.Game != configuration.Default().Stores.Game {
+				unitTest.Fatalf(
+					"game store %+v was changed from default %+v",
+					loadedConfiguration.Stores.Game,
+					configuration.Default().Stores.Game)
+			}
+		})
+	}
+}
+
+func TestListsFromEnvironmentAndFlags(unitTest *testing.T) {
+	loadedConfiguration, errorFromLoad :=
+		configuration.Load(
+			"test",
+			[]string{"-allowed-origins", "https://example.com, http://localhost:4233"},
+			environmentFrom(map[string]string{
+				"ILUTULESTIKUD_ADMINISTRATORS": "first,,second",
+				"ILUTULESTIKUD_CHAT_COLORS":    "red, #00FFFF ,white",
+			}))
+	if errorFromLoad != nil {
+		unitTest.Fatalf("could not load configuration: %v", errorFromLoad)
+	}
+
+	expectedOrigins := []string{"https://example.com", "http://localhost:4233"}
+	if !reflect.DeepEqual(loadedConfiguration.AllowedOrigins, expectedOrigins) {
+		unitTest.Fatalf(
+			"allowed origins %v were not expected %v",
+			loadedConfiguration.AllowedOrigins,
+			expectedOrigins)
+	}
+
+	expectedAdministrators := []string{"first", "second"}
+	if !reflect.DeepEqual(loadedConfiguration.AdministratorIdentifiers, expectedAdministrators) {
+		unitTest.Fatalf(
+			"administrators %v were not expected %v",
+			loadedConfiguration.AdministratorIdentifiers,
+			expectedAdministrators)
+	}
+
+	expectedColors := []string{"red", "#00FFFF", "white"}
+	if !reflect.DeepEqual(loadedConfiguration.ChatColors, expectedColors) {
+		unitTest.Fatalf(
+			"chat colors %v were not expected %v",
+			loadedConfiguration.ChatColors,
+			expectedColors)
+	}
+
+	withoutOrigins, errorFromEmptyLoad :=
+		configuration.Load(
+			"test",
+			[]string{"-allowed-origins="},
+			environmentFrom(nil))
+	if (errorFromEmptyLoad != nil) || (len(withoutOrigins.AllowedOrigins) != 0) {
+		unitTest.Fatalf(
+			"empty flag gave allowed origins %v and error %v instead of no origins",
+			withoutOrigins.AllowedOrigins,
+			errorFromEmptyLoad)
+	}
+}
+
+func TestRejectUnparsableSources(unitTest *testing.T) {
+	fileWithUnknownField :=
+		writeConfigurationFile(unitTest, `{"ListenAddres": ":1000"}`)
+	fileWithWrongType :=
+		writeConfigurationFile(unitTest, `{"ChatLogLength": "eight"}`)
+
+	testCases := []struct {
+		testName             string
+		commandLineArguments []string
+		environmentVariables map[string]string
+		expectedInError      string
+	}{
+		{
+			testName:             "Unknown flag",
+			commandLineArguments: []string{"-listen-adress", ":1000"},
+			expectedInError:      "listen-adress",
+		},
+		{
+			testName:             "Unexpected argument",
+			commandLineArguments: []string{"-listen-address", ":1000", "extra"},
+			expectedInError:      "extra",
+		},
+		{
+			testName:             "Missing file",
+			commandLineArguments: []string{"-config-file", "/does/not/exist.json"},
+			expectedInError:      "configuration file",
+		},
+		{
+			testName:             "Unknown field in file",
+			commandLineArguments: []string{"-config-file", fileWithUnknownField},
+			expectedInError:      "ListenAddres",
+		},
+		{
+			testName:             "Wrong type in file",
+			commandLineArguments: []string{"-config-file", fileWithWrongType},
+			expectedInError:      "ChatLogLength",
+		},
+		{
+			testName:             "Chat log length from environment not integer",
+			environmentVariables: map[string]string{"ILUTULESTIKUD_CHAT_LOG_LENGTH": "eight"},
+			expectedInError:      "ILUTULESTIKUD_CHAT_LOG_LENGTH",
+		},
+		{
+			testName:             "Chat log length from flag not integer",
+			commandLineArguments: []string{"-chat-log-length", "eight"},
+			expectedInError:      "-chat-log-length",
+		},
+		{
+			testName:             "Invalid combination from different sources",
+			commandLineArguments: []string{"-player-store", "postgresql"},
+			environmentVariables: map[string]string{
+				"ILUTULESTIKUD_GAME_POSTGRESQL_CONNECTION": "host=localhost",
+			},
+			expectedInError: "must have a PostgreSQL connection",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			_, errorFromLoad :=
+				configuration.Load(
+					"test",
+					testCase.commandLineArguments,
+					environmentFrom(testCase.environmentVariables))
+			if (errorFromLoad == nil) ||
+				!strings.Contains(errorFromLoad.Error(), testCase.expectedInError) {
+				unitTest.Fatalf(
+					"expected error containing %q, instead got %v",
+					testCase.expectedInError,
+					errorFromLoad)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsValidCombinations(unitTest *testing.T) {
+	testCases := []struct {
+		testName            string
+		changeConfiguration func(*configuration.Configuration)
+	}{
+		{
+			testName:            "Default",
+			changeConfiguration: func(*configuration.Configuration) {},
+		},
+		{
+			testName: "Everything in memory without gRPC or cross-origin requests",
+			changeConfiguration: func(validConfiguration *configuration.Configuration) {
+				validConfiguration.Stores = inMemoryStores()
+				validConfiguration.RPCListenAddress = ""
+				validConfiguration.AllowedOrigins = []string{}
+			},
+		},
+		{
+			testName: "Players in PostgreSQL with games in memory",
+			changeConfiguration: func(validConfiguration *configuration.Configuration) {
+				validConfiguration.Stores = inMemoryStores()
+				validConfiguration.Stores.Player =
+					configuration.StoreSettings{
+						Backend:              configuration.BackendPostgresql,
+						PostgresqlConnection: "host=localhost dbname=ilutulestikud",
+					}
+			},
+		},
+		{
+			testName: "Stores in different Datastore projects",
+			changeConfiguration: func(validConfiguration *configuration.Configuration) {
+				validConfiguration.Stores.Game.DatastoreProject = "other-project"
+				validConfiguration.Stores.Idempotency =
+					configuration.StoreSettings{Backend: configuration.BackendInMemory}
+			},
+		},
+		{
+			testName: "Any origin and hex colors",
+			changeConfiguration: func(validConfiguration *configuration.Configuration) {
+				validConfiguration.AllowedOrigins = []string{"*"}
+				validConfiguration.ChatColors = []string{"#FF8800", "white"}
+				validConfiguration.ChatBackgroundColor = "#202020"
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			validConfiguration := configuration.Default()
+			testCase.changeConfiguration(&validConfiguration)
+
+			errorFromValidation := validConfiguration.Validate()
+			if errorFromValidation != nil {
+				unitTest.Fatalf(
+					"configuration %+v was not valid: %v",
+					validConfiguration,
+					errorFromValidation)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsInvalidCombinations(unitTest *testing.T) {
+	testCases := []struct {
+		testName            string
+		changeConfiguration func(*configuration.Configuration)
+		expectedInError     string
+	}{
+		{
+			testName: "Listen address without port",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.ListenAddress = "localhost"
+			},
+			expectedInError: "Listen address",
+		},
+		{
+			testName: "RPC listen address without port",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.RPCListenAddress = "8081"
+			},
+			expectedInError: "RPC listen address",
+		},
+		{
+			testName: "Same address for HTTP and gRPC",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.RPCListenAddress = invalidConfiguration.ListenAddress
+			},
+			expectedInError: "must differ",
+		},
+		{
+			testName: "Origin with path",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.AllowedOrigins = []string{"http://localhost:4233/app"}
+			},
+			expectedInError: "http://localhost:4233/app",
+		},
+		{
+			testName: "Origin without scheme",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.AllowedOrigins = []string{"localhost:4233"}
+			},
+			expectedInError: "localhost:4233",
+		},
+		{
+			testName: "Any origin with others",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.AllowedOrigins = []string{"*", "https://example.com"}
+			},
+			expectedInError: "cannot be combined",
+		},
+		{
+			testName: "Empty version",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.BackendVersion = ""
+			},
+			expectedInError: "version",
+		},
+		{
+			testName: "Zero chat log length",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.ChatLogLength = 0
+			},
+			expectedInError: "Chat log length",
+		},
+		{
+			testName: "No chat colors",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.ChatColors = []string{}
+			},
+			expectedInError: "at least one chat color",
+		},
+		{
+			testName: "Duplicate chat color",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.ChatColors = []string{"red", "blue", "red"}
+			},
+			expectedInError: "more than once",
+		},
+		{
+			testName: "Unknown chat color",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.ChatColors = []string{"red", "teal"}
+			},
+			expectedInError: "teal",
+		},
+		{
+			testName: "Unknown background color",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.ChatBackgroundColor = "#12345"
+			},
+			expectedInError: "background",
+		},
+		{
+			testName: "Unknown backend",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.Stores.Lobby.Backend = "redis"
+			},
+			expectedInError: "redis",
+		},
+		{
+			testName: "Games in PostgreSQL",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.Stores.Game =
+					configuration.StoreSettings{
+						Backend:              configuration.BackendPostgresql,
+						PostgresqlConnection: "host=localhost",
+					}
+			},
+			expectedInError: "Store game cannot use backend",
+		},
+		{
+			testName: "PostgreSQL without connection",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.Stores.Player =
+					configuration.StoreSettings{Backend: configuration.BackendPostgresql}
+			},
+			expectedInError: "must have a PostgreSQL connection",
+		},
+		{
+			testName: "PostgreSQL connection for Datastore",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.Stores.Player.PostgresqlConnection = "host=localhost"
+			},
+			expectedInError: "cannot have a PostgreSQL connection",
+		},
+		{
+			testName: "Datastore project for memory",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.Stores.Idempotency =
+					configuration.StoreSettings{
+						Backend:          configuration.BackendInMemory,
+						DatastoreProject: "other-project",
+					}
+			},
+			expectedInError: "cannot have a Cloud Datastore project",
+		},
+		{
+			testName: "Players in memory with games persisted",
+			changeConfiguration: func(invalidConfiguration *configuration.Configuration) {
+				invalidConfiguration.Stores = inMemoryStores()
+				invalidConfiguration.Stores.History =
+					configuration.StoreSettings{Backend: configuration.BackendCloudDatastore}
+			},
+			expectedInError: "Player store in memory cannot be combined with history store",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			invalidConfiguration := configuration.Default()
+			testCase.changeConfiguration(&invalidConfiguration)
+
+			errorFromValidation := invalidConfiguration.Validate()
+			if (errorFromValidation == nil) ||
+				!strings.Contains(errorFromValidation.Error(), testCase.expectedInError) {
+				unitTest.Fatalf(
+					"expected error containing %q, instead got %v",
+					testCase.expectedInError,
+					errorFromValidation)
+			}
+		})
+	}
+}
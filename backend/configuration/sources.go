@@ -0,0 +1,296 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FileFlagName is the name of the command-line flag which gives the path of the
+	// configuration file.
+	FileFlagName = "config-file"
+
+	// FileEnvironmentVariable is the environment variable which gives the path of the
+	// configuration file if the command-line flag does not.
+	FileEnvironmentVariable = "ILUTULESTIKUD_CONFIG_FILE"
+
+	// environmentPrefix starts the name of the environment variable for every setting.
+	environmentPrefix = "ILUTULESTIKUD_"
+)
+
+// setting describes a single setting which can be given as a command-line flag or as
+// an environment variable, with a function to apply the value given as text.
+type setting struct {
+	flagName    string
+	description string
+	applyValue  func(configuration *Configuration, valueText string) error
+}
+
+// environmentVariable returns the name of the environment variable for the setting,
+// which is the name of the flag in upper case with underscores instead of hyphens,
+// after the prefix for the backend.
+func (settingToName setting) environmentVariable() string {
+	return environmentPrefix +
+		strings.ToUpper(strings.Replace(settingToName.flagName, "-", "_", -1))
+}
+
+// Load returns the configuration given by the default configuration overridden by the
+// configuration file, if there is one, then by the environment variables from the given
+// function to look them up, then by the given command-line arguments, which should not
+// include the name of the program. The configuration file is JSON with the fields of
+// Configuration, of which only those to be overridden need be present. Lists are given
+// in environment variables and flags as comma-separated values. It returns an error if
+// any value cannot be parsed or if the resulting configuration is not valid.
+func Load(
+	programName string,
+	commandLineArguments []string,
+	lookUpEnvironment func(string) (string, bool)) (Configuration, error) {
+	allSettings := allSettings()
+
+	flagSet := flag.NewFlagSet(programName, flag.ContinueOnError)
+	filePath :=
+		flagSet.String(
+			FileFlagName,
+			"",
+			"path of the JSON configuration file (or "+FileEnvironmentVariable+")")
+	for _, settingForFlag := range allSettings {
+		flagSet.String(
+			settingForFlag.flagName,
+			"",
+			settingForFlag.description+" (or "+settingForFlag.environmentVariable()+")")
+	}
+
+	errorFromParse := flagSet.Parse(commandLineArguments)
+	if errorFromParse != nil {
+		return Configuration{}, errorFromParse
+	}
+
+	if flagSet.NArg() > 0 {
+		return Configuration{}, fmt.Errorf("Unexpected arguments %v", flagSet.Args())
+	}
+
+	loadedConfiguration := Default()
+
+	if *filePath == "" {
+		*filePath, _ = lookUpEnvironment(FileEnvironmentVariable)
+	}
+
+	if *filePath != "" {
+		errorFromFile := loadedConfiguration.applyFile(*filePath)
+		if errorFromFile != nil {
+			return Configuration{}, errorFromFile
+		}
+	}
+
+	for _, settingFromEnvironment := range allSettings {
+		environmentVariable := settingFromEnvironment.environmentVariable()
+		valueText, isSet := lookUpEnvironment(environmentVariable)
+		if !isSet {
+			continue
+		}
+
+		errorFromApplying := settingFromEnvironment.applyValue(&loadedConfiguration, valueText)
+		if errorFromApplying != nil {
+			return Configuration{}, fmt.Errorf(
+				"Environment variable %v is not valid: %v",
+				environmentVariable,
+				errorFromApplying)
+		}
+	}
+
+	settingsByFlag := make(map[string]setting, len(allSettings))
+	for _, settingForFlag := range allSettings {
+		settingsByFlag[settingForFlag.flagName] = settingForFlag
+	}
+
+	var errorFromFlags error
+	flagSet.Visit(func(givenFlag *flag.Flag) {
+		settingFromFlag, isSetting := settingsByFlag[givenFlag.Name]
+		if !isSetting || (errorFromFlags != nil) {
+			return
+		}
+
+		errorFromApplying :=
+			settingFromFlag.applyValue(&loadedConfiguration, givenFlag.Value.String())
+		if errorFromApplying != nil {
+			errorFromFlags =
+				fmt.Errorf(
+					"Flag -%v is not valid: %v",
+					givenFlag.Name,
+					errorFromApplying)
+		}
+	})
+
+	if errorFromFlags != nil {
+		return Configuration{}, errorFromFlags
+	}
+
+	errorFromValidation := loadedConfiguration.Validate()
+	if errorFromValidation != nil {
+		return Configuration{}, errorFromValidation
+	}
+
+	return loadedConfiguration, nil
+}
+
+// applyFile overrides the configuration with the fields present in the JSON file at
+// the given path, rejecting fields which are not part of Configuration so that a
+// misspelt setting is not silently ignored.
+func (configuration *Configuration) applyFile(filePath string) error {
+	fileContent, errorFromRead := ioutil.ReadFile(filePath)
+	if errorFromRead != nil {
+		return fmt.Errorf("Could not read configuration file: %v", errorFromRead)
+	}
+
+	fileDecoder := json.NewDecoder(bytes.NewReader(fileContent))
+	fileDecoder.DisallowUnknownFields()
+
+	errorFromDecode := fileDecoder.Decode(configuration)
+	if errorFromDecode != nil {
+		return fmt.Errorf(
+			"Configuration file %v is not valid: %v",
+			filePath,
+			errorFromDecode)
+	}
+
+	return nil
+}
+
+// allSettings returns every setting which can be given as a flag or as an environment
+// variable, with the settings of the stores last.
+func allSettings() []setting {
+	generalSettings := []setting{
+		{
+			flagName:    "listen-address",
+			description: "host:port on which the HTTP endpoints listen",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.ListenAddress = valueText
+				return nil
+			},
+		},
+		{
+			flagName:    "rpc-listen-address",
+			description: "host:port on which the gRPC service listens, or empty for none",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.RPCListenAddress = valueText
+				return nil
+			},
+		},
+		{
+			flagName:    "allowed-origins",
+			description: "comma-separated origins from which cross-origin requests are allowed",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.AllowedOrigins = listFromText(valueText)
+				return nil
+			},
+		},
+		{
+			flagName:    "backend-version",
+			description: "version of the backend reported to the frontend",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.BackendVersion = valueText
+				return nil
+			},
+		},
+		{
+			flagName:    "chat-log-length",
+			description: "number of chat messages kept for each game",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				chatLogLength, errorFromConversion := strconv.Atoi(valueText)
+				if errorFromConversion != nil {
+					return fmt.Errorf("%q is not an integer", valueText)
+				}
+
+				configuration.ChatLogLength = chatLogLength
+				return nil
+			},
+		},
+		{
+			flagName:    "chat-colors",
+			description: "comma-separated chat colors suggested to players",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.ChatColors = listFromText(valueText)
+				return nil
+			},
+		},
+		{
+			flagName:    "chat-background-color",
+			description: "background color of the chat, as #RRGGBB",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.ChatBackgroundColor = valueText
+				return nil
+			},
+		},
+		{
+			flagName:    "administrators",
+			description: "comma-separated identifiers of players who are always administrators",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				configuration.AdministratorIdentifiers = listFromText(valueText)
+				return nil
+			},
+		},
+	}
+
+	defaultConfiguration := Default()
+	for storeIndex, storeForSettings := range defaultConfiguration.namedStores() {
+		generalSettings =
+			append(generalSettings, storeSettings(storeIndex, storeForSettings.storeName)...)
+	}
+
+	return generalSettings
+}
+
+// storeSettings returns the settings for the backend of the store with the given index
+// in the list from namedStores and for the connection to that backend, named by the
+// given name of the store.
+func storeSettings(storeIndex int, storeName string) []setting {
+	selectStore := func(configuration *Configuration) *StoreSettings {
+		return configuration.namedStores()[storeIndex].storeSettings
+	}
+
+	return []setting{
+		{
+			flagName:    storeName + "-store",
+			description: "backend of the " + storeName + " store: memory, postgresql or datastore",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				selectStore(configuration).Backend = valueText
+				return nil
+			},
+		},
+		{
+			flagName:    storeName + "-postgresql-connection",
+			description: "PostgreSQL connection string for the " + storeName + " store",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				selectStore(configuration).PostgresqlConnection = valueText
+				return nil
+			},
+		},
+		{
+			flagName:    storeName + "-datastore-project",
+			description: "Cloud Datastore project for the " + storeName + " store",
+			applyValue: func(configuration *Configuration, valueText string) error {
+				selectStore(configuration).DatastoreProject = valueText
+				return nil
+			},
+		},
+	}
+}
+
+// listFromText splits the given text at commas into a list of values without the
+// surrounding spaces, ignoring empty values, so that an empty text gives an empty list.
+func listFromText(valueText string) []string {
+	listedValues := make([]string, 0)
+	for _, listedValue := range strings.Split(valueText, ",") {
+		trimmedValue := strings.TrimSpace(listedValue)
+		if trimmedValue != "" {
+			listedValues = append(listedValues, trimmedValue)
+		}
+	}
+
+	return listedValues
+}
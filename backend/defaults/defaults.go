@@ -13,3 +13,9 @@ func AvailableColors() []string {
 		"white",
 	}
 }
+
+// ChatBackgroundColor returns the color of the background against which the frontend
+// shows the names and messages of players in their chat colors.
+func ChatBackgroundColor() string {
+	return "#000000"
+}
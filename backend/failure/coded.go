@@ -0,0 +1,73 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CodeUnclassified is the code given for errors which do not carry a code of their own.
+const CodeUnclassified = "unclassified"
+
+// codeCarrier defines what an error has to do to be reported with a code other than
+// CodeUnclassified.
+type codeCarrier interface {
+	ErrorCode() string
+}
+
+// Coded is an error which carries a stable code identifying its kind along with a
+// message for humans, so that clients can react to the kind of error without matching
+// on the text of the message.
+type Coded struct {
+	errorCode string
+	message   string
+}
+
+// NewSentinel creates a Coded error with the given code and message, which is meant
+// to be stored in a package variable and compared against with errors.Is.
+func NewSentinel(errorCode string, errorMessage string) *Coded {
+	return &Coded{
+		errorCode: errorCode,
+		message:   errorMessage,
+	}
+}
+
+// Error returns the message of the error.
+func (coded *Coded) Error() string {
+	return coded.message
+}
+
+// ErrorCode returns the code of the error.
+func (coded *Coded) ErrorCode() string {
+	return coded.errorCode
+}
+
+// Is returns true if the given error is a Coded error with the same code, so that
+// errors created by Errorf match their sentinel when compared with errors.Is.
+func (coded *Coded) Is(targetError error) bool {
+	targetCoded, isCoded := targetError.(*Coded)
+
+	return isCoded && (targetCoded.errorCode == coded.errorCode)
+}
+
+// Errorf returns an error with the same code as this one but with the message formatted
+// from the given format and arguments, so that the message can include the details of
+// the particular case.
+func (coded *Coded) Errorf(
+	messageFormat string,
+	messageArguments ...interface{}) error {
+	return &Coded{
+		errorCode: coded.errorCode,
+		message:   fmt.Sprintf(messageFormat, messageArguments...),
+	}
+}
+
+// CodeOf returns the code of the first error in the chain of the given error which
+// carries a code, or CodeUnclassified if there is none.
+func CodeOf(errorToClassify error) string {
+	var carrierOfCode codeCarrier
+	if errors.As(errorToClassify, &carrierOfCode) {
+		return carrierOfCode.ErrorCode()
+	}
+
+	return CodeUnclassified
+}
@@ -0,0 +1,105 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+type errorWithOwnCode struct{}
+
+func (ownCode *errorWithOwnCode) Error() string {
+	return "own code"
+}
+
+func (ownCode *errorWithOwnCode) ErrorCode() string {
+	return "own-code"
+}
+
+func TestCodedErrorsMatchSentinelWithSameCode(unitTest *testing.T) {
+	testSentinel := failure.NewSentinel("test-code", "Test message")
+	otherSentinel := failure.NewSentinel("other-code", "Test message")
+	detailedError := testSentinel.Errorf("Test message about %v", "details")
+	wrappedError := fmt.Errorf("Wrapped: %w", detailedError)
+
+	testCases := []struct {
+		testName        string
+		errorToCheck    error
+		expectedIsMatch bool
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			testName:        "sentinel itself",
+			errorToCheck:    testSentinel,
+			expectedIsMatch: true,
+			expectedCode:    "test-code",
+			expectedMessage: "Test message",
+		},
+		{
+			testName:        "detailed error",
+			errorToCheck:    detailedError,
+			expectedIsMatch: true,
+			expectedCode:    "test-code",
+			expectedMessage: "Test message about details",
+		},
+		{
+			testName:        "wrapped detailed error",
+			errorToCheck:    wrappedError,
+			expectedIsMatch: true,
+			expectedCode:    "test-code",
+			expectedMessage: "Wrapped: Test message about details",
+		},
+		{
+			testName:        "other sentinel",
+			errorToCheck:    otherSentinel,
+			expectedIsMatch: false,
+			expectedCode:    "other-code",
+			expectedMessage: "Test message",
+		},
+		{
+			testName:        "error with own code",
+			errorToCheck:    &errorWithOwnCode{},
+			expectedIsMatch: false,
+			expectedCode:    "own-code",
+			expectedMessage: "own code",
+		},
+		{
+			testName:        "error without code",
+			errorToCheck:    fmt.Errorf("Test message"),
+			expectedIsMatch: false,
+			expectedCode:    failure.CodeUnclassified,
+			expectedMessage: "Test message",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			if errors.Is(testCase.errorToCheck, testSentinel) != testCase.expectedIsMatch {
+				unitTest.Fatalf(
+					"errors.Is(%v, %v) did not return %v",
+					testCase.errorToCheck,
+					testSentinel,
+					testCase.expectedIsMatch)
+			}
+
+			actualCode := failure.CodeOf(testCase.errorToCheck)
+			if actualCode != testCase.expectedCode {
+				unitTest.Fatalf(
+					"CodeOf(%v) returned %v instead of %v",
+					testCase.errorToCheck,
+					actualCode,
+					testCase.expectedCode)
+			}
+
+			if testCase.errorToCheck.Error() != testCase.expectedMessage {
+				unitTest.Fatalf(
+					"Error() returned %q instead of %q",
+					testCase.errorToCheck.Error(),
+					testCase.expectedMessage)
+			}
+		})
+	}
+}
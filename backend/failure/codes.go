@@ -0,0 +1,67 @@
+package failure
+
+// These are the codes which are sent to clients with errors. They are part of the
+// interface of the backend, so existing codes must not be changed.
+const (
+	// CodeNotAuthenticated is for requests which need a session token but have none.
+	CodeNotAuthenticated = "not-authenticated"
+
+	// CodeForbidden is for requests which the authenticated player may not make.
+	CodeForbidden = "forbidden"
+
+	// CodeIncorrectCredentials is for failed attempts to log in.
+	CodeIncorrectCredentials = "incorrect-credentials"
+
+	// CodeUnknownPlayer is for requests about players who are not registered.
+	CodeUnknownPlayer = "unknown-player"
+
+	// CodeDeletedPlayer is for requests about players who have been deleted.
+	CodeDeletedPlayer = "deleted-player"
+
+	// CodeNameTaken is for attempts to register a player or create a game with a name
+	// which is already used.
+	CodeNameTaken = "name-taken"
+
+	// CodePlayerInGame is for attempts to delete a player who is still in a game.
+	CodePlayerInGame = "player-in-game"
+
+	// CodeUnknownGame is for requests about games which do not exist.
+	CodeUnknownGame = "unknown-game"
+
+	// CodeUnknownRuleset is for requests with a ruleset identifier which is not known.
+	CodeUnknownRuleset = "unknown-ruleset"
+
+	// CodeNotParticipant is for requests about a game by a player who is not in it.
+	CodeNotParticipant = "not-participant"
+
+	// CodeNotYourTurn is for turns taken by a player when it is another player's turn.
+	CodeNotYourTurn = "not-your-turn"
+
+	// CodeGameFinished is for turns taken in a game which has already finished.
+	CodeGameFinished = "game-finished"
+
+	// CodeGameNotFinished is for requests which need a game to have finished first.
+	CodeGameNotFinished = "game-not-finished"
+
+	// CodeNoHintsLeft is for hints given when there are no hints available.
+	CodeNoHintsLeft = "no-hints-left"
+
+	// CodeHintToSelf is for hints given by a player to themself.
+	CodeHintToSelf = "hint-to-self"
+
+	// CodeInvalidCardIndex is for turns which indicate a card outside the hand.
+	CodeInvalidCardIndex = "invalid-card-index"
+
+	// CodeTurnConflict is for turns based on a turn of a game which has since been
+	// taken.
+	CodeTurnConflict = "turn-conflict"
+
+	// CodeNotInvited is for attempts to join games to which the player was not invited.
+	CodeNotInvited = "not-invited"
+
+	// CodeNoOpenSeats is for attempts to join lobbies which are already full.
+	CodeNoOpenSeats = "no-open-seats"
+
+	// CodeSpectatingNotAllowed is for attempts to spectate games which do not allow it.
+	CodeSpectatingNotAllowed = "spectating-not-allowed"
+)
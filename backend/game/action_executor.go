@@ -29,7 +29,7 @@ func ExecutorOfActionsForPlayer(
 	gameParticipants := stateOfGame.Read().PlayerNames()
 
 	for _, gameParticipant := range gameParticipants {
-		if gameParticipant == actingPlayer.Name() {
+		if gameParticipant == actingPlayer.Identifier() {
 			actionExecutor :=
 				&ActionExecutor{
 					gameRuleset:      stateOfGame.Read().Ruleset(),
@@ -45,7 +45,7 @@ func ExecutorOfActionsForPlayer(
 	// If we have not yet returned a pointer, then the player was not
 	// a participant.
 	notFoundError :=
-		fmt.Errorf(
+		ErrNotParticipant.Errorf(
 			"No player with name %v is a participant in game %v",
 			actingPlayer.Name(),
 			stateOfGame.Read().Name())
@@ -68,8 +68,17 @@ func (actionExecutor *ActionExecutor) RecordChatMessage(
 // of the acting player, or returns an error if it was not possible.
 func (actionExecutor *ActionExecutor) TakeTurnByDiscarding(
 	executionContext context.Context,
+	expectedTurn int,
 	indexInHand int) error {
-	// First we must determine if the player is allowed to take an action.
+	// A turn based on an out-of-date view of the game is rejected before anything else,
+	// as the reason for any other error would be that the view is out of date.
+	errorFromTurn :=
+		ErrorIfTurnIsNot(actionExecutor.gameState.Read(), expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
+	// Then we must determine if the player is allowed to take an action.
 	discardedCard, errorFromHand :=
 		actionExecutor.cardFromHandIfTurnElseError(indexInHand)
 
@@ -100,6 +109,7 @@ func (actionExecutor *ActionExecutor) TakeTurnByDiscarding(
 
 	return actionExecutor.gameState.EnactTurnByDiscardingAndReplacing(
 		executionContext,
+		expectedTurn,
 		actionMessage,
 		actionExecutor.actingPlayer,
 		indexInHand,
@@ -113,8 +123,17 @@ func (actionExecutor *ActionExecutor) TakeTurnByDiscarding(
 // discard pile while causing a mistake, or returns an error if it was not possible.
 func (actionExecutor *ActionExecutor) TakeTurnByPlaying(
 	executionContext context.Context,
+	expectedTurn int,
 	indexInHand int) error {
-	// First we must determine if the player is allowed to take an action.
+	// A turn based on an out-of-date view of the game is rejected before anything else,
+	// as the reason for any other error would be that the view is out of date.
+	errorFromTurn :=
+		ErrorIfTurnIsNot(actionExecutor.gameState.Read(), expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
+	// Then we must determine if the player is allowed to take an action.
 	selectedCard, errorFromHand :=
 		actionExecutor.cardFromHandIfTurnElseError(indexInHand)
 
@@ -141,6 +160,7 @@ func (actionExecutor *ActionExecutor) TakeTurnByPlaying(
 
 		return actionExecutor.gameState.EnactTurnByDiscardingAndReplacing(
 			executionContext,
+			expectedTurn,
 			actionMessage,
 			actionExecutor.actingPlayer,
 			indexInHand,
@@ -164,6 +184,7 @@ func (actionExecutor *ActionExecutor) TakeTurnByPlaying(
 
 	return actionExecutor.gameState.EnactTurnByPlayingAndReplacing(
 		executionContext,
+		expectedTurn,
 		actionMessage,
 		actionExecutor.actingPlayer,
 		indexInHand,
@@ -176,8 +197,15 @@ func (actionExecutor *ActionExecutor) TakeTurnByPlaying(
 // it was not possible.
 func (actionExecutor *ActionExecutor) TakeTurnByHintingColor(
 	executionContext context.Context,
+	expectedTurn int,
 	receivingPlayer string,
 	hintedColor string) error {
+	errorFromTurn :=
+		ErrorIfTurnIsNot(actionExecutor.gameState.Read(), expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
 	visibleHandOfReceiver, inferredHandOfReceiverBeforeHint, errorFromHand :=
 		actionExecutor.handOfHintReceiver(receivingPlayer)
 
@@ -199,6 +227,7 @@ func (actionExecutor *ActionExecutor) TakeTurnByHintingColor(
 
 	return actionExecutor.gameState.EnactTurnByUpdatingHandWithHint(
 		executionContext,
+		expectedTurn,
 		actionMessage,
 		actionExecutor.actingPlayer,
 		receivingPlayer,
@@ -211,8 +240,15 @@ func (actionExecutor *ActionExecutor) TakeTurnByHintingColor(
 // if it was not possible.
 func (actionExecutor *ActionExecutor) TakeTurnByHintingIndex(
 	executionContext context.Context,
+	expectedTurn int,
 	receivingPlayer string,
 	hintedIndex int) error {
+	errorFromTurn :=
+		ErrorIfTurnIsNot(actionExecutor.gameState.Read(), expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
 	visibleHandOfReceiver, inferredHandOfReceiverBeforeHint, errorFromHand :=
 		actionExecutor.handOfHintReceiver(receivingPlayer)
 
@@ -234,6 +270,7 @@ func (actionExecutor *ActionExecutor) TakeTurnByHintingIndex(
 
 	return actionExecutor.gameState.EnactTurnByUpdatingHandWithHint(
 		executionContext,
+		expectedTurn,
 		actionMessage,
 		actionExecutor.actingPlayer,
 		receivingPlayer,
@@ -243,9 +280,9 @@ func (actionExecutor *ActionExecutor) TakeTurnByHintingIndex(
 
 func (actionExecutor *ActionExecutor) handOfHintReceiver(
 	receivingPlayer string) ([]card.Defined, []card.Inferred, error) {
-	if receivingPlayer == actionExecutor.actingPlayer.Name() {
+	if receivingPlayer == actionExecutor.actingPlayer.Identifier() {
 		errorToReturn :=
-			fmt.Errorf(
+			ErrHintToSelf.Errorf(
 				"Player %v cannot give a hint to self",
 				actionExecutor.actingPlayer.Name())
 		return nil, nil, errorToReturn
@@ -253,7 +290,7 @@ func (actionExecutor *ActionExecutor) handOfHintReceiver(
 
 	readonlyGame := actionExecutor.gameState.Read()
 	if readonlyGame.NumberOfReadyHints() <= 0 {
-		return nil, nil, fmt.Errorf("No hints available to use")
+		return nil, nil, ErrNoHintsLeft
 	}
 
 	// First we must determine if the player is allowed to take an action,
@@ -285,7 +322,7 @@ func (actionExecutor *ActionExecutor) handOfHintReceiver(
 func (actionExecutor *ActionExecutor) playerHandIfTurnElseError() ([]card.Defined, error) {
 	gameReadState := actionExecutor.gameState.Read()
 	if IsFinished(gameReadState) {
-		return nil, fmt.Errorf("Game is finished, cannot take turn")
+		return nil, ErrGameFinished.Errorf("Game is finished, cannot take turn")
 	}
 
 	// The turn number starts from 1.
@@ -293,9 +330,9 @@ func (actionExecutor *ActionExecutor) playerHandIfTurnElseError() ([]card.Define
 		(gameReadState.Turn() - 1) % len(actionExecutor.gameParticipants)
 	playerForCurrentTurn := actionExecutor.gameParticipants[indexOfPlayerForCurrentTurn]
 
-	if playerForCurrentTurn != actionExecutor.actingPlayer.Name() {
+	if playerForCurrentTurn != actionExecutor.actingPlayer.Identifier() {
 		errorToReturn :=
-			fmt.Errorf(
+			ErrNotYourTurn.Errorf(
 				"Player %v is not the current player (%v) so cannot take a turn",
 				actionExecutor.actingPlayer.Name(),
 				playerForCurrentTurn)
@@ -304,7 +341,7 @@ func (actionExecutor *ActionExecutor) playerHandIfTurnElseError() ([]card.Define
 	}
 
 	playerHand, errorFromVisibleHand :=
-		gameReadState.VisibleHand(actionExecutor.actingPlayer.Name())
+		gameReadState.VisibleHand(actionExecutor.actingPlayer.Identifier())
 
 	if errorFromVisibleHand != nil {
 		errorToReturn :=
@@ -330,7 +367,7 @@ func (actionExecutor *ActionExecutor) cardFromHandIfTurnElseError(
 
 	handSize := len(playerHand)
 	if (indexInHand < 0) || (indexInHand >= handSize) {
-		errorFromOutOfRange := fmt.Errorf(
+		errorFromOutOfRange := ErrInvalidCardIndex.Errorf(
 			"Index %v is out of the acceptable range %v to %v of the player's hand",
 			indexInHand,
 			0,
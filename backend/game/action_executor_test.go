@@ -2,9 +2,11 @@ package game_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 )
 
@@ -141,7 +143,10 @@ func TestRejectTakeTurnByDiscardingIfTooManyMistakesMade(unitTest *testing.T) {
 
 	indexInHandToDiscard := 1
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding == nil {
 		unitTest.Fatalf(
@@ -187,12 +192,121 @@ func TestRejectTakeTurnByDiscardingIfNotPlayerTurn(unitTest *testing.T) {
 
 	indexInHandToDiscard := 1
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
-	if errorFromTakeTurnByDiscarding == nil {
+	if !errors.Is(errorFromTakeTurnByDiscarding, game.ErrNotYourTurn) {
 		unitTest.Fatalf(
-			"TakeTurnByDiscarding(%v) produced nil error when not player's turn",
+			"TakeTurnByDiscarding(%v) produced error %v rather than one of kind %v when not player's turn",
+			indexInHandToDiscard,
+			errorFromTakeTurnByDiscarding,
+			game.ErrNotYourTurn)
+	}
+}
+
+func TestRejectTakeTurnByDiscardingIfTurnStale(unitTest *testing.T) {
+	gameName := "Test game"
+	testPlayersInOriginalOrder :=
+		[]string{
+			playerNamesAvailableInTest[0],
+			playerNamesAvailableInTest[1],
+			playerNamesAvailableInTest[2],
+		}
+	playerName := testPlayersInOriginalOrder[0]
+	gameCollection, mockPersister, _ :=
+		prepareCollection(unitTest, testPlayersInOriginalOrder)
+
+	mockReadAndWriteState := NewMockGameState(unitTest)
+	mockReadAndWriteState.ReturnForName = gameName
+	mockReadAndWriteState.ReturnForPlayerNames = testPlayersInOriginalOrder
+	mockReadAndWriteState.ReturnForRuleset = testRuleset
+	mockReadAndWriteState.ReturnForTurn = 4
+	mockReadAndWriteState.ReturnForVersion = 9
+
+	mockPersister.TestErrorForReadAndWriteGame = nil
+	mockPersister.ReturnForReadAndWriteGame = mockReadAndWriteState
+
+	executorForPlayer, errorFromExecuteAction :=
+		gameCollection.ExecuteAction(
+			context.Background(),
+			gameName,
+			playerName)
+
+	if errorFromExecuteAction != nil {
+		unitTest.Fatalf(
+			"ExecuteAction(%v, %v) produced error %v",
+			gameName,
+			playerName,
+			errorFromExecuteAction)
+	}
+
+	staleTurn := mockReadAndWriteState.ReturnForTurn - 1
+	indexInHandToDiscard := 1
+	errorFromTakeTurnByDiscarding :=
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			staleTurn,
 			indexInHandToDiscard)
+
+	_, isTurnConflict := errorFromTakeTurnByDiscarding.(*game.TurnConflictError)
+	if !isTurnConflict {
+		unitTest.Fatalf(
+			"TakeTurnByDiscarding(%v, %v) produced error %v rather than a turn conflict",
+			staleTurn,
+			indexInHandToDiscard,
+			errorFromTakeTurnByDiscarding)
+	}
+}
+
+func TestErrorIfTurnIsNotComparesTurnRatherThanVersion(unitTest *testing.T) {
+	mockReadonlyState := NewMockGameState(unitTest)
+	mockReadonlyState.ReturnForName = "Test game"
+	mockReadonlyState.ReturnForTurn = 4
+	mockReadonlyState.ReturnForVersion = 9
+
+	testCases := []struct {
+		testName         string
+		expectedTurn     int
+		expectedConflict bool
+	}{
+		{
+			testName:         "current turn",
+			expectedTurn:     4,
+			expectedConflict: false,
+		},
+		{
+			testName:         "turn not given",
+			expectedTurn:     game.TurnNotGiven,
+			expectedConflict: false,
+		},
+		{
+			testName:         "previous turn",
+			expectedTurn:     3,
+			expectedConflict: true,
+		},
+		{
+			testName:         "version rather than turn",
+			expectedTurn:     9,
+			expectedConflict: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			errorFromCheck := game.ErrorIfTurnIsNot(mockReadonlyState, testCase.expectedTurn)
+
+			_, isTurnConflict := errorFromCheck.(*game.TurnConflictError)
+			if (isTurnConflict != testCase.expectedConflict) ||
+				(!isTurnConflict && (errorFromCheck != nil)) {
+				unitTest.Fatalf(
+					"ErrorIfTurnIsNot(..., %v) produced error %v, expected conflict %v",
+					testCase.expectedTurn,
+					errorFromCheck,
+					testCase.expectedConflict)
+			}
+		})
 	}
 }
 
@@ -234,7 +348,10 @@ func TestRejectTakeTurnByDiscardingIfErrorGettingHand(unitTest *testing.T) {
 
 	indexInHandToDiscard := 1
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding == nil {
 		unitTest.Fatalf(
@@ -283,7 +400,10 @@ func TestRejectTakeTurnByDiscardingIfPlayerHandAlreadyTooSmall(unitTest *testing
 
 	indexInHandToDiscard := 1
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding == nil {
 		unitTest.Fatalf(
@@ -337,7 +457,10 @@ func TestRejectTakeTurnByDiscardingIfIndexNegative(unitTest *testing.T) {
 
 	indexInHandToDiscard := -1
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding == nil {
 		unitTest.Fatalf(
@@ -391,7 +514,10 @@ func TestRejectTakeTurnByDiscardingIfIndexTooLarge(unitTest *testing.T) {
 
 	indexInHandToDiscard := correctHandSize
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding == nil {
 		unitTest.Fatalf(
@@ -456,7 +582,10 @@ func TestTakeTurnByDiscardingWhenAlreadyMaximumHints(unitTest *testing.T) {
 	}
 
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding != nil {
 		unitTest.Fatalf(
@@ -565,7 +694,10 @@ func TestTakeTurnByDiscardingWhenLessThanMaximumHints(unitTest *testing.T) {
 	}
 
 	errorFromTakeTurnByDiscarding :=
-		executorForPlayer.TakeTurnByDiscarding(context.Background(), indexInHandToDiscard)
+		executorForPlayer.TakeTurnByDiscarding(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToDiscard)
 
 	if errorFromTakeTurnByDiscarding != nil {
 		unitTest.Fatalf(
@@ -656,7 +788,10 @@ func TestRejectTakeTurnByPlayingIfTooManyMistakesMade(unitTest *testing.T) {
 
 	indexInHandToPlay := 1
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying == nil {
 		unitTest.Fatalf(
@@ -702,7 +837,10 @@ func TestRejectTakeTurnByPlayingIfNotPlayerTurn(unitTest *testing.T) {
 
 	indexInHandToPlay := 1
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying == nil {
 		unitTest.Fatalf(
@@ -749,7 +887,10 @@ func TestRejectTakeTurnByPlayingIfErrorGettingHand(unitTest *testing.T) {
 
 	indexInHandToPlay := 1
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying == nil {
 		unitTest.Fatalf(
@@ -798,7 +939,10 @@ func TestRejectTakeTurnByPlayingIfPlayerHandAlreadyTooSmall(unitTest *testing.T)
 
 	indexInHandToPlay := 1
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying == nil {
 		unitTest.Fatalf(
@@ -852,7 +996,10 @@ func TestRejectTakeTurnByPlayingIfIndexNegative(unitTest *testing.T) {
 
 	indexInHandToPlay := -1
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying == nil {
 		unitTest.Fatalf(
@@ -906,7 +1053,10 @@ func TestRejectTakeTurnByPlayingIfIndexTooLarge(unitTest *testing.T) {
 
 	indexInHandToPlay := correctHandSize
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying == nil {
 		unitTest.Fatalf(
@@ -982,7 +1132,10 @@ func TestMistakeWhenTakingTurnByPlaying(unitTest *testing.T) {
 	}
 
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToAttemptToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToAttemptToPlay)
 
 	if errorFromTakeTurnByPlaying != nil {
 		unitTest.Fatalf(
@@ -1105,7 +1258,10 @@ func TestTakeTurnByPlayingWithNoBonusHintWhenLessThanMaximumHints(unitTest *test
 	}
 
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying != nil {
 		unitTest.Fatalf(
@@ -1228,7 +1384,10 @@ func TestTakeTurnByPlayingWithBonusHintWhenAlreadyAtMaximumHints(unitTest *testi
 	}
 
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying != nil {
 		unitTest.Fatalf(
@@ -1351,7 +1510,10 @@ func TestTakeTurnByPlayingWithBonusHintWhenLessThanMaximumHints(unitTest *testin
 	}
 
 	errorFromTakeTurnByPlaying :=
-		executorForPlayer.TakeTurnByPlaying(context.Background(), indexInHandToPlay)
+		executorForPlayer.TakeTurnByPlaying(
+			context.Background(),
+			mockReadAndWriteState.ReturnForTurn,
+			indexInHandToPlay)
 
 	if errorFromTakeTurnByPlaying != nil {
 		unitTest.Fatalf(
@@ -1526,6 +1688,7 @@ func TestRejectTakeTurnByHintWithoutCallingPersisterWriteFunction(unitTest *test
 			errorFromColorHint :=
 				executorForPlayer.TakeTurnByHintingColor(
 					context.Background(),
+					mockReadAndWriteState.ReturnForTurn,
 					testCase.receiverName,
 					testColor)
 
@@ -1539,6 +1702,7 @@ func TestRejectTakeTurnByHintWithoutCallingPersisterWriteFunction(unitTest *test
 			errorFromIndexHint :=
 				executorForPlayer.TakeTurnByHintingIndex(
 					context.Background(),
+					mockReadAndWriteState.ReturnForTurn,
 					testCase.receiverName,
 					testIndex)
 
@@ -1633,6 +1797,7 @@ func TestPropagateErrorFromTakeTurnByHintFromCallingPersisterWriteFunction(unitT
 			errorFromColorHint :=
 				executorForPlayer.TakeTurnByHintingColor(
 					context.Background(),
+					mockReadAndWriteState.ReturnForTurn,
 					receivingPlayer,
 					testColor)
 
@@ -1689,6 +1854,7 @@ func TestPropagateErrorFromTakeTurnByHintFromCallingPersisterWriteFunction(unitT
 			errorFromIndexHint :=
 				executorForPlayer.TakeTurnByHintingIndex(
 					context.Background(),
+					mockReadAndWriteState.ReturnForTurn,
 					receivingPlayer,
 					testIndex)
 
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"sync"
+)
+
+// ChangeNotice tells a subscriber that the game with the given name has changed, for
+// example because a turn was taken or a chat message was recorded. It carries no
+// information about the state of the game, so that each subscriber can read the state
+// as seen by its own player.
+type ChangeNotice struct {
+	GameName string
+}
+
+// changeNotifier keeps track of the subscribers to the changes of each game, so that
+// they can be notified without polling. It only knows about the changes made through
+// the collection in the same process, so subscribers to a backend which runs as several
+// instances are only notified of the changes made through the same instance.
+type changeNotifier struct {
+	mutualExclusion     sync.Mutex
+	subscriptionsByGame map[string]map[chan ChangeNotice]bool
+}
+
+// newChangeNotifier creates a change notifier without any subscriptions.
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{
+		mutualExclusion:     sync.Mutex{},
+		subscriptionsByGame: make(map[string]map[chan ChangeNotice]bool, 0),
+	}
+}
+
+// subscribe returns a channel which receives a notice whenever the given game changes,
+// along with the function which ends the subscription. The channel holds at most one
+// notice which has not yet been received, so that a slow subscriber never blocks the
+// change, and several changes in quick succession may arrive as a single notice.
+func (notifier *changeNotifier) subscribe(
+	gameName string) (<-chan ChangeNotice, func()) {
+	subscriptionChannel := make(chan ChangeNotice, 1)
+
+	notifier.mutualExclusion.Lock()
+	defer notifier.mutualExclusion.Unlock()
+
+	subscriptionsForGame, hasSubscriptions := notifier.subscriptionsByGame[gameName]
+	if !hasSubscriptions {
+		subscriptionsForGame = make(map[chan ChangeNotice]bool, 0)
+		notifier.subscriptionsByGame[gameName] = subscriptionsForGame
+	}
+
+	subscriptionsForGame[subscriptionChannel] = true
+
+	unsubscribe := func() {
+		notifier.mutualExclusion.Lock()
+		defer notifier.mutualExclusion.Unlock()
+
+		// The subscriptions are looked up again in case the function is called more
+		// than once, after the game has had all its subscriptions removed and then
+		// new subscriptions made.
+		currentSubscriptions := notifier.subscriptionsByGame[gameName]
+		delete(currentSubscriptions, subscriptionChannel)
+
+		if len(currentSubscriptions) == 0 {
+			delete(notifier.subscriptionsByGame, gameName)
+		}
+	}
+
+	return subscriptionChannel, unsubscribe
+}
+
+// notify sends a notice to every subscriber to the given game which does not already
+// have a notice waiting.
+func (notifier *changeNotifier) notify(gameName string) {
+	notifier.mutualExclusion.Lock()
+	defer notifier.mutualExclusion.Unlock()
+
+	for subscriptionChannel := range notifier.subscriptionsByGame[gameName] {
+		select {
+		case subscriptionChannel <- ChangeNotice{GameName: gameName}:
+		default:
+		}
+	}
+}
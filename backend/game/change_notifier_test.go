@@ -0,0 +1,122 @@
+package game_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+// assertNoticeWaiting checks that the given subscription has a notice for the given
+// game waiting, without blocking if it does not.
+func assertNoticeWaiting(
+	unitTest *testing.T,
+	testIdentifier string,
+	changeNotices <-chan game.ChangeNotice,
+	expectedGameName string) {
+	select {
+	case changeNotice := <-changeNotices:
+		if changeNotice.GameName != expectedGameName {
+			unitTest.Fatalf(
+				testIdentifier+"/received notice %+v, expected notice for game %v",
+				changeNotice,
+				expectedGameName)
+		}
+	default:
+		unitTest.Fatalf(testIdentifier+"/did not receive notice for game %v", expectedGameName)
+	}
+}
+
+// assertNoNoticeWaiting checks that the given subscription has no notice waiting.
+func assertNoNoticeWaiting(
+	unitTest *testing.T,
+	testIdentifier string,
+	changeNotices <-chan game.ChangeNotice) {
+	select {
+	case changeNotice := <-changeNotices:
+		unitTest.Fatalf(testIdentifier+"/received unexpected notice %+v", changeNotice)
+	default:
+	}
+}
+
+func TestSubscribersNotifiedOfChanges(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			gameCollection := collectionAndDescription.GameCollection
+			playerNames := playerNamesAvailableInTest[:2]
+			gameName := "Subscribed game"
+			otherGameName := "Other game"
+
+			for _, nameToAdd := range []string{gameName, otherGameName} {
+				errorFromAdd :=
+					gameCollection.AddNew(executionContext, nameToAdd, testRuleset, playerNames)
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf("AddNew(%v, ...) produced unexpected error %v", nameToAdd, errorFromAdd)
+				}
+			}
+
+			firstNotices, unsubscribeFirst :=
+				gameCollection.SubscribeToChanges(executionContext, gameName)
+			defer unsubscribeFirst()
+			secondNotices, unsubscribeSecond :=
+				gameCollection.SubscribeToChanges(executionContext, gameName)
+			otherNotices, unsubscribeOther :=
+				gameCollection.SubscribeToChanges(executionContext, otherGameName)
+			defer unsubscribeOther()
+
+			actionExecutor, errorFromExecutor :=
+				gameCollection.ExecuteAction(executionContext, gameName, playerNames[0])
+
+			if errorFromExecutor != nil {
+				unitTest.Fatalf("ExecuteAction(...) produced unexpected error %v", errorFromExecutor)
+			}
+
+			// Two changes before the notices are received arrive as a single notice.
+			errorFromChat := actionExecutor.RecordChatMessage(executionContext, "Hello")
+			if errorFromChat != nil {
+				unitTest.Fatalf("RecordChatMessage(...) produced unexpected error %v", errorFromChat)
+			}
+
+			errorFromTurn :=
+				actionExecutor.TakeTurnByDiscarding(
+					executionContext,
+					currentTurn(unitTest, gameCollection, gameName, playerNames[0]),
+					0)
+			if errorFromTurn != nil {
+				unitTest.Fatalf("TakeTurnByDiscarding(...) produced unexpected error %v", errorFromTurn)
+			}
+
+			assertNoticeWaiting(unitTest, "first subscriber", firstNotices, gameName)
+			assertNoNoticeWaiting(unitTest, "first subscriber after notice", firstNotices)
+			assertNoticeWaiting(unitTest, "second subscriber", secondNotices, gameName)
+			assertNoNoticeWaiting(unitTest, "subscriber to other game", otherNotices)
+
+			// A turn which is not taken successfully does not change the game.
+			errorFromInvalidTurn :=
+				actionExecutor.TakeTurnByDiscarding(
+					executionContext,
+					currentTurn(unitTest, gameCollection, gameName, playerNames[0]),
+					0)
+			if errorFromInvalidTurn == nil {
+				unitTest.Fatalf("TakeTurnByDiscarding(...) out of turn did not produce an error")
+			}
+
+			assertNoNoticeWaiting(unitTest, "first subscriber after invalid turn", firstNotices)
+
+			unsubscribeSecond()
+
+			// Ending a subscription a second time should not affect other subscriptions.
+			unsubscribeSecond()
+
+			errorFromDelete := gameCollection.Delete(executionContext, gameName)
+			if errorFromDelete != nil {
+				unitTest.Fatalf("Delete(...) produced unexpected error %v", errorFromDelete)
+			}
+
+			assertNoticeWaiting(unitTest, "first subscriber after delete", firstNotices, gameName)
+			assertNoNoticeWaiting(unitTest, "second subscriber after unsubscribing", secondNotices)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"context"
+
+	"github.com/benoleary/ilutulestikud/backend/player"
+)
+
+// playerWithColorInGame wraps around the state of a participant of a game so that its
+// chat color is the color in which the participant is shown in that game, which may
+// differ from the color which the player chose so that the participants of the game
+// can be told apart.
+type playerWithColorInGame struct {
+	player.ReadonlyState
+	colorInGame string
+}
+
+// Color returns the color in which the participant is shown in the game.
+func (participantState *playerWithColorInGame) Color() string {
+	return participantState.colorInGame
+}
+
+// providerWithColorsInGame wraps around a player provider so that the participants of
+// a particular game are given with the colors in which they are shown in that game,
+// while any other player is given as the wrapped provider gives them.
+type providerWithColorsInGame struct {
+	wrappedProvider ReadonlyPlayerProvider
+	colorsInGame    map[string]string
+}
+
+// newProviderWithColorsInGame creates a provider around the given provider which gives
+// the given participants with the given colors, in the same order as the participants.
+func newProviderWithColorsInGame(
+	wrappedProvider ReadonlyPlayerProvider,
+	participantNames []string,
+	participantColors []string) ReadonlyPlayerProvider {
+	colorsInGame := make(map[string]string, len(participantNames))
+	for participantIndex, participantName := range participantNames {
+		if participantIndex < len(participantColors) {
+			colorsInGame[participantName] = participantColors[participantIndex]
+		}
+	}
+
+	return &providerWithColorsInGame{
+		wrappedProvider: wrappedProvider,
+		colorsInGame:    colorsInGame,
+	}
+}
+
+// Get returns the state of the given player from the wrapped provider, with the color
+// in which the player is shown in the game if the player is a participant who is shown
+// in a color other than their own.
+func (gameProvider *providerWithColorsInGame) Get(
+	executionContext context.Context,
+	playerIdentifier string) (player.ReadonlyState, error) {
+	playerState, errorFromGet :=
+		gameProvider.wrappedProvider.Get(executionContext, playerIdentifier)
+
+	if errorFromGet != nil {
+		return nil, errorFromGet
+	}
+
+	colorInGame, isParticipant := gameProvider.colorsInGame[playerIdentifier]
+
+	if !isParticipant || (colorInGame == playerState.Color()) {
+		return playerState, nil
+	}
+
+	return &playerWithColorInGame{
+		ReadonlyState: playerState,
+		colorInGame:   colorInGame,
+	}, nil
+}
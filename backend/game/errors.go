@@ -0,0 +1,54 @@
+package game
+
+import (
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+// These are the kinds of error from this package which clients may want to handle
+// in particular ways. Errors of these kinds are created by calling Errorf on them, so
+// that they can be recognized with errors.Is.
+var (
+	// ErrUnknownGame is returned when there is no game with the requested name.
+	ErrUnknownGame = failure.NewSentinel(failure.CodeUnknownGame, "Game does not exist")
+
+	// ErrNameTaken is returned when a game is created with the name of an existing game.
+	ErrNameTaken = failure.NewSentinel(failure.CodeNameTaken, "Game already exists")
+
+	// ErrUnknownRuleset is returned when a ruleset identifier is not recognized.
+	ErrUnknownRuleset = failure.NewSentinel(failure.CodeUnknownRuleset, "Ruleset not recognized")
+
+	// ErrNotParticipant is returned when a player who is not a participant of a game
+	// tries to act in it.
+	ErrNotParticipant = failure.NewSentinel(failure.CodeNotParticipant, "Player is not a participant")
+
+	// ErrNotYourTurn is returned when a player tries to take a turn out of order.
+	ErrNotYourTurn = failure.NewSentinel(failure.CodeNotYourTurn, "Player is not the current player")
+
+	// ErrGameFinished is returned when a turn is taken in a game which is over.
+	ErrGameFinished = failure.NewSentinel(failure.CodeGameFinished, "Game is finished")
+
+	// ErrGameNotFinished is returned when something which needs a game to be over is
+	// requested for a game which is still in progress.
+	ErrGameNotFinished = failure.NewSentinel(failure.CodeGameNotFinished, "Game is not yet finished")
+
+	// ErrNoHintsLeft is returned when a hint is given when no hints are available.
+	ErrNoHintsLeft = failure.NewSentinel(failure.CodeNoHintsLeft, "No hints available to use")
+
+	// ErrHintToSelf is returned when a player tries to give a hint to themself.
+	ErrHintToSelf = failure.NewSentinel(failure.CodeHintToSelf, "Player cannot give a hint to self")
+
+	// ErrInvalidCardIndex is returned when a turn indicates a card outside the hand of
+	// the player.
+	ErrInvalidCardIndex = failure.NewSentinel(failure.CodeInvalidCardIndex, "Index is out of range of the hand")
+
+	// ErrNotInvited is returned when a player tries to join or decline a game to which
+	// they were not invited.
+	ErrNotInvited = failure.NewSentinel(failure.CodeNotInvited, "Player has not been invited to the game")
+
+	// ErrNoOpenSeats is returned when a player tries to join a lobby which is full.
+	ErrNoOpenSeats = failure.NewSentinel(failure.CodeNoOpenSeats, "Lobby has no open seats")
+
+	// ErrSpectatingNotAllowed is returned when a player tries to spectate a game which
+	// does not allow it, or in which they are a participant.
+	ErrSpectatingNotAllowed = failure.NewSentinel(failure.CodeSpectatingNotAllowed, "Game cannot be spectated")
+)
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/game/card"
+)
+
+// FinishedGameParticipant holds what a single participant did over the course of a
+// finished game. It has to be an exported struct with only exported data members so
+// that it serializes easily as part of a FinishedGame.
+type FinishedGameParticipant struct {
+	PlayerName         string
+	NumberOfTurnsTaken int
+	NumberOfDiscards   int
+	NumberOfMistakes   int
+}
+
+// FinishedGame is the record of a game which has finished, kept after the game itself
+// may have been deleted so that statistics about the players can be derived from it.
+// The player names are the identifiers of the participants in their original turn
+// order, kept as a separate slice so that persisters can easily query for the records
+// which have a particular participant, and the number of players is kept separately
+// so that persisters can easily query for the records with a particular number of
+// players. It has to be an exported struct with only exported data members so that
+// it serializes easily.
+type FinishedGame struct {
+	GameName                string
+	RulesetIdentifier       int
+	NumberOfPlayers         int
+	PlayerNames             []string
+	Participants            []FinishedGameParticipant
+	Score                   int
+	MaximumScore            int
+	IsOverBecauseOfMistakes bool
+	NumberOfMistakesMade    int
+	NumberOfTurnsTaken      int
+	TimeOfCreation          time.Time
+	TimeOfFinish            time.Time
+}
+
+// NewFinishedGame creates the record of the given game as it is at the moment, which
+// should be the moment that it finished.
+func NewFinishedGame(gameState ReadonlyState) FinishedGame {
+	participantsInGame := gameState.PlayerNames()
+	numberOfPlayers := len(participantsInGame)
+
+	// A view for a player with an empty name is not restricted to any participant,
+	// and derives the score in the same way as for the views of the participants.
+	neutralView :=
+		createViewWithoutPlayerMap(
+			gameState,
+			numberOfPlayers,
+			participantsInGame,
+			"")
+
+	// The turn number starts at 1 for the first turn, which has not yet been taken.
+	numberOfTurnsTaken := gameState.Turn() - 1
+
+	participantRecords := make([]FinishedGameParticipant, numberOfPlayers)
+	playerNames := make([]string, numberOfPlayers)
+
+	for playerIndex, participantName := range participantsInGame {
+		playerNames[playerIndex] = participantName
+
+		// The players take turns in their original order, so the players earlier in
+		// the order take one more turn than the others if the number of turns taken
+		// is not a multiple of the number of players.
+		turnsTakenByPlayer := numberOfTurnsTaken / numberOfPlayers
+		if playerIndex < (numberOfTurnsTaken % numberOfPlayers) {
+			turnsTakenByPlayer++
+		}
+
+		participantRecords[playerIndex] = FinishedGameParticipant{
+			PlayerName:         participantName,
+			NumberOfTurnsTaken: turnsTakenByPlayer,
+			NumberOfDiscards:   gameState.NumberOfDiscardsByPlayer(participantName),
+			NumberOfMistakes:   gameState.NumberOfMistakesByPlayer(participantName),
+		}
+	}
+
+	return FinishedGame{
+		GameName:                gameState.Name(),
+		RulesetIdentifier:       gameState.Ruleset().BackendIdentifier(),
+		NumberOfPlayers:         numberOfPlayers,
+		PlayerNames:             playerNames,
+		Participants:            participantRecords,
+		Score:                   neutralView.Score(),
+		MaximumScore:            MaximumScore(gameState.Ruleset()),
+		IsOverBecauseOfMistakes: IsOverBecauseOfMistakes(gameState),
+		NumberOfMistakesMade:    gameState.NumberOfMistakesMade(),
+		NumberOfTurnsTaken:      numberOfTurnsTaken,
+		TimeOfCreation:          gameState.CreationTime(),
+		TimeOfFinish:            time.Now(),
+	}
+}
+
+// IsPerfect returns true if the game finished with the highest score possible under
+// its ruleset.
+func (finishedGame *FinishedGame) IsPerfect() bool {
+	return finishedGame.Score >= finishedGame.MaximumScore
+}
+
+// ElapsedTime returns the time between the creation of the game and its finish.
+func (finishedGame *FinishedGame) ElapsedTime() time.Duration {
+	return finishedGame.TimeOfFinish.Sub(finishedGame.TimeOfCreation)
+}
+
+// Participant returns the record of the given participant in the game, along with
+// true, or an empty record along with false if the player was not a participant.
+func (finishedGame *FinishedGame) Participant(
+	playerName string) (FinishedGameParticipant, bool) {
+	for _, participantRecord := range finishedGame.Participants {
+		if participantRecord.PlayerName == playerName {
+			return participantRecord, true
+		}
+	}
+
+	return FinishedGameParticipant{}, false
+}
+
+// MaximumScore returns the score which the players would have if they played every
+// card of every sequence of every color suit of the given ruleset.
+func MaximumScore(gameRuleset Ruleset) int {
+	alreadyCounted := make(map[card.Defined]bool, 0)
+	maximumScore := 0
+
+	// The full set of cards includes duplicates, but only one copy of each card can
+	// be played.
+	for _, cardInSet := range gameRuleset.CopyOfFullCardset() {
+		if alreadyCounted[cardInSet] {
+			continue
+		}
+
+		alreadyCounted[cardInSet] = true
+		maximumScore += gameRuleset.PointsForCard(cardInSet)
+	}
+
+	return maximumScore
+}
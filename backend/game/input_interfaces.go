@@ -10,11 +10,28 @@ import (
 )
 
 // ReadonlyPlayerProvider defines an interface for structs to provide
-// player.ReadonlyStates for given player names.
+// player.ReadonlyStates for given player identifiers. Games refer to players
+// by their identifiers rather than by their display names, which may change,
+// so the player names held by games are player identifiers.
 type ReadonlyPlayerProvider interface {
 	Get(
 		executionContext context.Context,
-		playerName string) (player.ReadonlyState, error)
+		playerIdentifier string) (player.ReadonlyState, error)
+}
+
+// ChatColorSeparator defines an interface for structs which can work out chat colors
+// for the participants of a game which can be read and can be told apart, without
+// changing the colors which the players chose for themselves.
+type ChatColorSeparator interface {
+	// SeparatedChatColors should return the colors in which the given players are
+	// shown in a game which they share, in the same order as the players. Each player
+	// should keep their own color unless it cannot be read against the background of
+	// the chat or is nearly the same as the color of a player earlier in the list. It
+	// should not change the saved colors of the players, and it should return an error
+	// if no suitable color is available.
+	SeparatedChatColors(
+		executionContext context.Context,
+		playerIdentifiers []string) ([]string, error)
 }
 
 // ReadonlyState defines the interface for structs which should provide read-only
@@ -30,6 +47,11 @@ type ReadonlyState interface {
 	// the order in which they have their first turns.
 	PlayerNames() []string
 
+	// CreatorName should return the name of the player who created the game, such as
+	// the host of the lobby from which it was dealt, who is not necessarily the first
+	// player in the turn order.
+	CreatorName() string
+
 	// CreationTime should return the time object describing the time at which the
 	// state was created.
 	CreationTime() time.Time
@@ -40,6 +62,10 @@ type ReadonlyState interface {
 	// ActionLog should return the action log of the game at the current moment.
 	ActionLog() []message.FromPlayer
 
+	// Version should return a number which increases whenever the state changes, such
+	// as by a turn being taken or a chat message being recorded, and never decreases.
+	Version() int
+
 	// Turn should given the number of the turn (with the first turn being 1 rather
 	// than 0) which is the current turn in the game (assuming 1 turn per player,
 	// not 1 turn being when all players have acted and play returns to the first
@@ -58,6 +84,14 @@ type ReadonlyState interface {
 	// played incorrectly.
 	NumberOfMistakesMade() int
 
+	// NumberOfDiscardsByPlayer should return the number of cards which the given
+	// player has discarded deliberately, not counting cards played incorrectly.
+	NumberOfDiscardsByPlayer(playerName string) int
+
+	// NumberOfMistakesByPlayer should return the number of cards which the given
+	// player has played incorrectly.
+	NumberOfMistakesByPlayer(playerName string) int
+
 	// DeckSize should return the number of cards left to draw from the deck.
 	DeckSize() int
 
@@ -98,9 +132,12 @@ type ReadAndWriteState interface {
 	// index is possible). If there is no card to draw from the deck, it should
 	// increment the number of turns taken with an empty deck of replacing the card in
 	// the hand. It should also add the given numbers to the counts of available hints
-	// and mistakes made respectively.
+	// and mistakes made respectively. It should instead change nothing and return a
+	// TurnConflictError if the state is not at the given expected turn, checking the
+	// turn and changing the state as a single atomic operation.
 	EnactTurnByDiscardingAndReplacing(
 		executionContext context.Context,
+		expectedTurn int,
 		actionMessage string,
 		actingPlayer player.ReadonlyState,
 		indexInHand int,
@@ -117,9 +154,12 @@ type ReadAndWriteState interface {
 	// should increment the number of turns taken with an empty deck of replacing the
 	// card in the hand. It should also add the given number of hints to the count of
 	// ready hints available (such as when playing the end of sequence gives a bonus
-	// hint).
+	// hint). It should instead change nothing and return a TurnConflictError if the
+	// state is not at the given expected turn, checking the turn and changing the
+	// state as a single atomic operation.
 	EnactTurnByPlayingAndReplacing(
 		executionContext context.Context,
+		expectedTurn int,
 		actionMessage string,
 		actingPlayer player.ReadonlyState,
 		indexInHand int,
@@ -130,9 +170,12 @@ type ReadAndWriteState interface {
 	// the given player's inferred hand with the given inferred hand, while also
 	// decrementing the number of available hints appropriately. If the deck is empty,
 	// this function should also increment the number of turns taken with an empty
-	// deck.
+	// deck. It should instead change nothing and return a TurnConflictError if the
+	// state is not at the given expected turn, checking the turn and changing the
+	// state as a single atomic operation.
 	EnactTurnByUpdatingHandWithHint(
 		executionContext context.Context,
+		expectedTurn int,
 		actionMessage string,
 		actingPlayer player.ReadonlyState,
 		receivingPlayerName string,
@@ -143,6 +186,9 @@ type ReadAndWriteState interface {
 // StatePersister defines the interface for structs which should be able to create
 // objects implementing the ReadAndWriteState interface encapsulating the state
 // information for individual games, and for tracking the games by their name.
+// Games are stored under their names normalized according to the naming rules of
+// the persister, so every function which is given the name of a game should look
+// it up by its normalized name.
 type StatePersister interface {
 	// RandomSeed should provide an int64 which can be used as a seed for the
 	// rand.NewSource(...) function.
@@ -165,17 +211,25 @@ type StatePersister interface {
 		playerName string) ([]ReadonlyState, error)
 
 	// AddGame should add an element to the collection which is a new object implementing
-	// the ReadAndWriteState interface from the given argument. It should return an error
-	// if a game with the given name already exists.
+	// the ReadAndWriteState interface from the given arguments, created by the given
+	// player. It should return an error if a game with the given name already exists.
 	AddGame(
 		executionContext context.Context,
 		gameName string,
+		creatorName string,
 		chatLogLength int,
 		initialActionLog []message.FromPlayer,
 		gameRuleset Ruleset,
 		playersInTurnOrderWithInitialHands []PlayerNameWithHand,
 		initialDeck []card.Defined) error
 
+	// IsNameTaken should return true if a game with the same name as the given name,
+	// according to the naming rules of the persister, already exists. It should return
+	// an error if the given name does not follow the naming rules.
+	IsNameTaken(
+		executionContext context.Context,
+		gameName string) (bool, error)
+
 	// RemoveGameFromListForPlayer should remove the given player from the given game in
 	// the sense that the game will no longer show up in the result of
 	// ReadAllWithPlayer(playerName). It should return an error if the player is not a
@@ -188,3 +242,151 @@ type StatePersister interface {
 	// Delete should delete the given game from the persistence store.
 	Delete(executionContext context.Context, gameName string) error
 }
+
+// LobbyPersister defines the interface for structs which should be able to store
+// the lobbies of games which have been created with open seats but which have not
+// yet been dealt, tracking the lobbies by the names of their games. As for games,
+// every function which is given the name of a game should look up its lobby by the
+// name normalized according to the naming rules of the persister.
+type LobbyPersister interface {
+	// ReadLobby should return the lobby for the game with the given name, or an
+	// error if there is no such lobby.
+	ReadLobby(
+		executionContext context.Context,
+		gameName string) (Lobby, error)
+
+	// ReadAllLobbies should return a slice of all the lobbies in the persistence
+	// store. The order is not mandated.
+	ReadAllLobbies(executionContext context.Context) ([]Lobby, error)
+
+	// AddLobby should add the given lobby to the persistence store under the name
+	// of its game normalized according to the naming rules of the persister. It
+	// should return an error if the name does not follow the naming rules or if a
+	// lobby for a game with the same name according to the naming rules already
+	// exists.
+	AddLobby(
+		executionContext context.Context,
+		newLobby Lobby) error
+
+	// AddPlayerToLobby should give the given player a seat in the lobby for the
+	// given game. It should return an error if the lobby does not exist, or if
+	// the player is already seated, or if there are no open seats.
+	AddPlayerToLobby(
+		executionContext context.Context,
+		gameName string,
+		playerName string) error
+
+	// RemovePlayerFromLobby should free the seat of the given player in the lobby
+	// for the given game. It should return an error if the lobby does not exist
+	// or if the player has no seat in it.
+	RemovePlayerFromLobby(
+		executionContext context.Context,
+		gameName string,
+		playerName string) error
+
+	// DeleteLobby should delete the lobby for the given game from the persistence
+	// store.
+	DeleteLobby(
+		executionContext context.Context,
+		gameName string) error
+}
+
+// SeriesPersister defines the interface for structs which should be able to store
+// series of games, where each game in a series after the first is a rematch of the
+// game before it.
+type SeriesPersister interface {
+	// ReadSeriesContainingGame should return the series which contains the game with
+	// the given name, along with true, or an empty series along with false if the
+	// game is not part of any series. It should only return an error if there was a
+	// problem reading from the persistence store.
+	ReadSeriesContainingGame(
+		executionContext context.Context,
+		gameName string) (Series, bool, error)
+
+	// AppendGameToSeries should add the new game to the end of the series which has
+	// the previous game as its last game, creating a new series named after the
+	// previous game if the previous game is not yet part of any series. It should
+	// return an error if the previous game is part of a series but is not its last
+	// game, or if the new game is already part of a series.
+	AppendGameToSeries(
+		executionContext context.Context,
+		previousGameName string,
+		newGameName string) error
+}
+
+// HistoryPersister defines the interface for structs which should be able to store
+// the records of finished games in a form which can be queried for statistics about
+// the players, even after the games themselves have been deleted.
+type HistoryPersister interface {
+	// AddFinishedGame should store the given record of a finished game. Games may
+	// have the same name as earlier games which have since been deleted, so it should
+	// not replace any existing record for a game with the same name.
+	AddFinishedGame(
+		executionContext context.Context,
+		finishedGame FinishedGame) error
+
+	// ReadFinishedGamesWithPlayer should return the records of all the finished games
+	// which had the given player as a participant. The order is not mandated.
+	ReadFinishedGamesWithPlayer(
+		executionContext context.Context,
+		playerName string) ([]FinishedGame, error)
+
+	// ReadHighestScoringGames should return at most the given number of records of
+	// the finished games which match the given criteria and for which
+	// IsCountedForHighestScores is true, ranked by RanksAboveByScore. It should not
+	// have to read every record which matches the criteria.
+	ReadHighestScoringGames(
+		executionContext context.Context,
+		gameCriteria FinishedGameCriteria,
+		maximumNumberOfGames int) ([]FinishedGame, error)
+
+	// ReadFastestPerfectGames should return at most the given number of records of
+	// the finished games which match the given criteria and for which
+	// IsCountedForFastestPerfectGames is true, ranked by RanksAboveBySpeed. It should
+	// not have to read every record which matches the criteria.
+	ReadFastestPerfectGames(
+		executionContext context.Context,
+		gameCriteria FinishedGameCriteria,
+		maximumNumberOfGames int) ([]FinishedGame, error)
+}
+
+// SpectatorPersister defines the interface for structs which should be able to store
+// the spectator galleries of games, tracking the galleries by the names of their games.
+type SpectatorPersister interface {
+	// ReadGallery should return the spectator gallery for the game with the given
+	// name, or a gallery which does not allow spectating if none has been stored
+	// for the game. It should only return an error if there was a problem reading
+	// from the persistence store.
+	ReadGallery(
+		executionContext context.Context,
+		gameName string) (SpectatorGallery, error)
+
+	// SetSpectating should set whether spectating the given game is allowed and how
+	// many turns the neutral view for spectators should lag behind the game.
+	SetSpectating(
+		executionContext context.Context,
+		gameName string,
+		isAllowed bool,
+		delayInTurns int) error
+
+	// AddChatMessage should append the given message to the spectator chat log of
+	// the given game, keeping no more than the given number of messages.
+	AddChatMessage(
+		executionContext context.Context,
+		gameName string,
+		chatMessage message.FromPlayer,
+		chatLogLength int) error
+
+	// AddSnapshot should store the given snapshot for showing to spectators later,
+	// dropping any snapshots which are no longer needed.
+	AddSnapshot(
+		executionContext context.Context,
+		gameName string,
+		newSnapshot NeutralSnapshot) error
+
+	// DeleteGallery should delete the spectator gallery for the given game from the
+	// persistence store. It should not return an error if there was no gallery.
+	DeleteGallery(
+		executionContext context.Context,
+		gameName string) error
+}
@@ -0,0 +1,333 @@
+package game_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRejectInvalidInvitations(unitTest *testing.T) {
+	testCases := []struct {
+		testName    string
+		gameName    string
+		playerNames []string
+	}{
+		{
+			testName:    "Empty game name",
+			gameName:    "",
+			playerNames: playerNamesAvailableInTest[:3],
+		},
+		{
+			testName:    "Too few players",
+			gameName:    "Test game",
+			playerNames: playerNamesAvailableInTest[:1],
+		},
+		{
+			testName:    "Too many players",
+			gameName:    "Test game",
+			playerNames: playerNamesAvailableInTest,
+		},
+		{
+			testName:    "Unregistered player",
+			gameName:    "Test game",
+			playerNames: []string{playerNamesAvailableInTest[0], "Not A Registered Player"},
+		},
+		{
+			testName: "Repeated player",
+			gameName: "Test game",
+			playerNames: []string{
+				playerNamesAvailableInTest[0],
+				playerNamesAvailableInTest[1],
+				playerNamesAvailableInTest[0],
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, collectionAndDescription := range prepareCollections(unitTest) {
+			testIdentifier :=
+				testCase.testName + "/" + collectionAndDescription.CollectionDescription
+
+			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+				gameCollection := collectionAndDescription.GameCollection
+
+				errorFromInvite :=
+					gameCollection.InviteToNew(
+						context.Background(),
+						testCase.gameName,
+						testRuleset,
+						testCase.playerNames)
+
+				if errorFromInvite == nil {
+					unitTest.Fatalf("InviteToNew(...) did not produce expected error")
+				}
+
+				for _, playerName := range playerNamesAvailableInTest {
+					invitationsForPlayer, errorFromView :=
+						gameCollection.ViewInvitationsForPlayer(context.Background(), playerName)
+
+					if errorFromView != nil {
+						unitTest.Fatalf(
+							"ViewInvitationsForPlayer(%v) produced unexpected error %v",
+							playerName,
+							errorFromView)
+					}
+
+					if len(invitationsForPlayer) != 0 {
+						unitTest.Fatalf(
+							"ViewInvitationsForPlayer(%v) returned %v after rejected invitation",
+							playerName,
+							invitationsForPlayer)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGameStartsWhenAllInviteesAccept(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			invitedPlayers := []string{
+				playerNamesAvailableInTest[0],
+				playerNamesAvailableInTest[1],
+				playerNamesAvailableInTest[2],
+			}
+
+			errorFromInvite :=
+				gameCollection.InviteToNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					invitedPlayers)
+
+			if errorFromInvite != nil {
+				unitTest.Fatalf("InviteToNew(...) produced unexpected error %v", errorFromInvite)
+			}
+
+			invitationsForHost, errorFromHostView :=
+				gameCollection.ViewInvitationsForPlayer(context.Background(), invitedPlayers[0])
+
+			if errorFromHostView != nil || len(invitationsForHost) != 0 {
+				unitTest.Fatalf(
+					"ViewInvitationsForPlayer(host) returned %v, %v, expected no invitations",
+					invitationsForHost,
+					errorFromHostView)
+			}
+
+			openLobbies, errorFromOpenLobbies := gameCollection.AllLobbies(context.Background())
+
+			if errorFromOpenLobbies != nil || len(openLobbies) != 0 {
+				unitTest.Fatalf(
+					"AllLobbies() returned %v, %v, expected no open lobbies",
+					openLobbies,
+					errorFromOpenLobbies)
+			}
+
+			errorFromUninvitedAccept :=
+				gameCollection.AcceptInvitation(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[3])
+
+			if errorFromUninvitedAccept == nil {
+				unitTest.Fatalf("AcceptInvitation(...) by uninvited player did not produce expected error")
+			}
+
+			// The players accept in reverse order so that we can check that the
+			// turn order follows the order of invitation.
+			for inviteeIndex := len(invitedPlayers) - 1; inviteeIndex > 0; inviteeIndex-- {
+				invitedPlayer := invitedPlayers[inviteeIndex]
+				invitationsForPlayer, errorFromView :=
+					gameCollection.ViewInvitationsForPlayer(context.Background(), invitedPlayer)
+
+				if errorFromView != nil {
+					unitTest.Fatalf(
+						"ViewInvitationsForPlayer(%v) produced unexpected error %v",
+						invitedPlayer,
+						errorFromView)
+				}
+
+				if len(invitationsForPlayer) != 1 ||
+					invitationsForPlayer[0].GameName != gameName {
+					unitTest.Fatalf(
+						"ViewInvitationsForPlayer(%v) returned %v, expected single invitation to %v",
+						invitedPlayer,
+						invitationsForPlayer,
+						gameName)
+				}
+
+				_, errorFromEarlyView :=
+					gameCollection.ViewState(context.Background(), gameName, invitedPlayers[0])
+
+				if errorFromEarlyView == nil {
+					unitTest.Fatalf("Game was viewable before all invitees accepted")
+				}
+
+				errorFromAccept :=
+					gameCollection.AcceptInvitation(context.Background(), gameName, invitedPlayer)
+
+				if errorFromAccept != nil {
+					unitTest.Fatalf(
+						"AcceptInvitation(%v) produced unexpected error %v",
+						invitedPlayer,
+						errorFromAccept)
+				}
+			}
+
+			gameView, errorFromView :=
+				gameCollection.ViewState(context.Background(), gameName, invitedPlayers[0])
+
+			if errorFromView != nil {
+				unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromView)
+			}
+
+			actualPlayers, _, _ := gameView.CurrentTurnOrder()
+
+			if !reflect.DeepEqual(actualPlayers, invitedPlayers) {
+				unitTest.Fatalf(
+					"Started game had players %v, expected %v",
+					actualPlayers,
+					invitedPlayers)
+			}
+		})
+	}
+}
+
+func TestDecliningInvitationWithdrawsGame(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			invitedPlayers := []string{
+				playerNamesAvailableInTest[0],
+				playerNamesAvailableInTest[1],
+				playerNamesAvailableInTest[2],
+			}
+
+			errorFromInvite :=
+				gameCollection.InviteToNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					invitedPlayers)
+
+			if errorFromInvite != nil {
+				unitTest.Fatalf("InviteToNew(...) produced unexpected error %v", errorFromInvite)
+			}
+
+			errorFromUninvitedDecline :=
+				gameCollection.DeclineInvitation(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[3])
+
+			if errorFromUninvitedDecline == nil {
+				unitTest.Fatalf("DeclineInvitation(...) by uninvited player did not produce expected error")
+			}
+
+			errorFromDecline :=
+				gameCollection.DeclineInvitation(context.Background(), gameName, invitedPlayers[1])
+
+			if errorFromDecline != nil {
+				unitTest.Fatalf("DeclineInvitation(...) produced unexpected error %v", errorFromDecline)
+			}
+
+			invitationsForOtherPlayer, errorFromView :=
+				gameCollection.ViewInvitationsForPlayer(context.Background(), invitedPlayers[2])
+
+			if errorFromView != nil || len(invitationsForOtherPlayer) != 0 {
+				unitTest.Fatalf(
+					"ViewInvitationsForPlayer(...) returned %v, %v after declined invitation",
+					invitationsForOtherPlayer,
+					errorFromView)
+			}
+
+			errorFromAccept :=
+				gameCollection.AcceptInvitation(context.Background(), gameName, invitedPlayers[2])
+
+			if errorFromAccept == nil {
+				unitTest.Fatalf("AcceptInvitation(...) after decline did not produce expected error")
+			}
+		})
+	}
+}
+
+func TestJoiningInvitationLobbyAcceptsInvitation(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			invitedPlayers := []string{
+				playerNamesAvailableInTest[0],
+				playerNamesAvailableInTest[1],
+				playerNamesAvailableInTest[2],
+			}
+
+			errorFromInvite :=
+				gameCollection.InviteToNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					invitedPlayers)
+
+			if errorFromInvite != nil {
+				unitTest.Fatalf("InviteToNew(...) produced unexpected error %v", errorFromInvite)
+			}
+
+			errorFromUninvitedJoin :=
+				gameCollection.JoinLobby(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[3])
+
+			if errorFromUninvitedJoin == nil {
+				unitTest.Fatalf("JoinLobby(...) by uninvited player did not produce expected error")
+			}
+
+			errorFromAccept :=
+				gameCollection.AcceptInvitation(context.Background(), gameName, invitedPlayers[1])
+
+			if errorFromAccept != nil {
+				unitTest.Fatalf("AcceptInvitation(...) produced unexpected error %v", errorFromAccept)
+			}
+
+			// The last invited player joins through JoinLobby rather than AcceptInvitation,
+			// which should still deal the game.
+			errorFromJoin :=
+				gameCollection.JoinLobby(context.Background(), gameName, invitedPlayers[2])
+
+			if errorFromJoin != nil {
+				unitTest.Fatalf("JoinLobby(...) produced unexpected error %v", errorFromJoin)
+			}
+
+			gameView, errorFromView :=
+				gameCollection.ViewState(context.Background(), gameName, invitedPlayers[0])
+
+			if errorFromView != nil {
+				unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromView)
+			}
+
+			actualPlayers, _, _ := gameView.CurrentTurnOrder()
+
+			if !reflect.DeepEqual(actualPlayers, invitedPlayers) {
+				unitTest.Fatalf(
+					"Dealt game had players %v, expected %v",
+					actualPlayers,
+					invitedPlayers)
+			}
+
+			invitationsForLastPlayer, errorFromInvitations :=
+				gameCollection.ViewInvitationsForPlayer(context.Background(), invitedPlayers[2])
+
+			if errorFromInvitations != nil || len(invitationsForLastPlayer) != 0 {
+				unitTest.Fatalf(
+					"ViewInvitationsForPlayer(...) returned %v, %v, expected no invitations",
+					invitationsForLastPlayer,
+					errorFromInvitations)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package game
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultNumberOfLeaderboardEntries is the number of entries in each list of a
+// leaderboard if no number is requested.
+const defaultNumberOfLeaderboardEntries = 10
+
+// maximumNumberOfLeaderboardEntries is the largest number of entries in each list of
+// a leaderboard, so that a single request cannot ask for the whole history.
+const maximumNumberOfLeaderboardEntries = 100
+
+// FinishedGameCriteria holds what the record of a finished game has to match to be
+// counted for a leaderboard. The game must have finished at or after FinishedFrom and
+// before FinishedBefore, where a zero time means that there is no bound, so that the
+// zero value of both covers all time.
+type FinishedGameCriteria struct {
+	RulesetIdentifier int
+	NumberOfPlayers   int
+	FinishedFrom      time.Time
+	FinishedBefore    time.Time
+}
+
+// Matches returns true if the given record of a finished game matches the criteria.
+func (gameCriteria FinishedGameCriteria) Matches(finishedGame FinishedGame) bool {
+	if (finishedGame.RulesetIdentifier != gameCriteria.RulesetIdentifier) ||
+		(finishedGame.NumberOfPlayers != gameCriteria.NumberOfPlayers) {
+		return false
+	}
+
+	if !gameCriteria.FinishedFrom.IsZero() &&
+		finishedGame.TimeOfFinish.Before(gameCriteria.FinishedFrom) {
+		return false
+	}
+
+	if !gameCriteria.FinishedBefore.IsZero() &&
+		!finishedGame.TimeOfFinish.Before(gameCriteria.FinishedBefore) {
+		return false
+	}
+
+	return true
+}
+
+// LeaderboardEntry holds the information about a single finished game which is shown
+// on a leaderboard. The player names are the identifiers of the participants, and the
+// display names are in the same order.
+type LeaderboardEntry struct {
+	GameName           string
+	PlayerNames        []string
+	PlayerDisplayNames []string
+	Score              int
+	NumberOfTurnsTaken int
+	ElapsedTime        time.Duration
+	TimeOfFinish       time.Time
+}
+
+// Leaderboard holds the finished games with the highest scores and the perfect games
+// which were finished in the shortest time, for a single ruleset and number of players
+// over a range of time.
+type Leaderboard struct {
+	GameCriteria        FinishedGameCriteria
+	HighestScores       []LeaderboardEntry
+	FastestPerfectGames []LeaderboardEntry
+}
+
+// NewLeaderboard creates the leaderboard for the given criteria from the given records
+// of finished games, which should already be ranked and limited to the number of
+// entries wanted, as returned by HistoryPersister.ReadHighestScoringGames and
+// HistoryPersister.ReadFastestPerfectGames. The display names of the players are left
+// empty.
+func NewLeaderboard(
+	gameCriteria FinishedGameCriteria,
+	highestScoringGames []FinishedGame,
+	fastestPerfectGames []FinishedGame) Leaderboard {
+	return Leaderboard{
+		GameCriteria:        gameCriteria,
+		HighestScores:       leaderboardEntries(highestScoringGames),
+		FastestPerfectGames: leaderboardEntries(fastestPerfectGames),
+	}
+}
+
+// IsCountedForHighestScores returns true if the given record of a finished game can be
+// ranked among the highest scores. Games which were over because of too many mistakes
+// are left out, as they score nothing.
+func IsCountedForHighestScores(finishedGame FinishedGame) bool {
+	return !finishedGame.IsOverBecauseOfMistakes
+}
+
+// IsCountedForFastestPerfectGames returns true if the given record of a finished game
+// can be ranked among the fastest perfect games.
+func IsCountedForFastestPerfectGames(finishedGame FinishedGame) bool {
+	return IsCountedForHighestScores(finishedGame) && finishedGame.IsPerfect()
+}
+
+// RanksAboveByScore returns true if the first game ranks above the second among the
+// highest scores. Equal scores are ranked by fewer turns, and then by less time
+// elapsed, so that the more efficient game is ranked higher. Any remaining ties are
+// ranked by which game finished first.
+func RanksAboveByScore(firstGame FinishedGame, secondGame FinishedGame) bool {
+	if firstGame.Score != secondGame.Score {
+		return firstGame.Score > secondGame.Score
+	}
+
+	if firstGame.NumberOfTurnsTaken != secondGame.NumberOfTurnsTaken {
+		return firstGame.NumberOfTurnsTaken < secondGame.NumberOfTurnsTaken
+	}
+
+	if firstGame.ElapsedTime() != secondGame.ElapsedTime() {
+		return firstGame.ElapsedTime() < secondGame.ElapsedTime()
+	}
+
+	return firstGame.TimeOfFinish.Before(secondGame.TimeOfFinish)
+}
+
+// RanksAboveBySpeed returns true if the first game ranks above the second among the
+// fastest perfect games. Equal times are ranked by fewer turns, and any remaining ties
+// are ranked by which game finished first.
+func RanksAboveBySpeed(firstGame FinishedGame, secondGame FinishedGame) bool {
+	if firstGame.ElapsedTime() != secondGame.ElapsedTime() {
+		return firstGame.ElapsedTime() < secondGame.ElapsedTime()
+	}
+
+	if firstGame.NumberOfTurnsTaken != secondGame.NumberOfTurnsTaken {
+		return firstGame.NumberOfTurnsTaken < secondGame.NumberOfTurnsTaken
+	}
+
+	return firstGame.TimeOfFinish.Before(secondGame.TimeOfFinish)
+}
+
+// HighestScoringGames returns at most the given number of the given records of
+// finished games which match the given criteria and are counted for the highest
+// scores, ranked by RanksAboveByScore. It is meant for persisters which have to go
+// through every record anyway, such as those which keep the records in memory.
+func HighestScoringGames(
+	finishedGames []FinishedGame,
+	gameCriteria FinishedGameCriteria,
+	maximumNumberOfGames int) []FinishedGame {
+	return rankedGames(
+		finishedGames,
+		gameCriteria,
+		IsCountedForHighestScores,
+		RanksAboveByScore,
+		maximumNumberOfGames)
+}
+
+// FastestPerfectGames returns at most the given number of the given records of
+// finished games which match the given criteria and are counted for the fastest
+// perfect games, ranked by RanksAboveBySpeed. It is meant for the same persisters as
+// HighestScoringGames.
+func FastestPerfectGames(
+	finishedGames []FinishedGame,
+	gameCriteria FinishedGameCriteria,
+	maximumNumberOfGames int) []FinishedGame {
+	return rankedGames(
+		finishedGames,
+		gameCriteria,
+		IsCountedForFastestPerfectGames,
+		RanksAboveBySpeed,
+		maximumNumberOfGames)
+}
+
+// rankedGames returns at most the given number of the given records which match the
+// given criteria and are counted by the given function, ranked by the given function.
+func rankedGames(
+	finishedGames []FinishedGame,
+	gameCriteria FinishedGameCriteria,
+	isCounted func(FinishedGame) bool,
+	ranksAbove func(FinishedGame, FinishedGame) bool,
+	maximumNumberOfGames int) []FinishedGame {
+	countedGames := make([]FinishedGame, 0)
+
+	for _, finishedGame := range finishedGames {
+		if gameCriteria.Matches(finishedGame) && isCounted(finishedGame) {
+			countedGames = append(countedGames, finishedGame)
+		}
+	}
+
+	sort.Slice(countedGames, func(firstIndex int, secondIndex int) bool {
+		return ranksAbove(countedGames[firstIndex], countedGames[secondIndex])
+	})
+
+	if len(countedGames) > maximumNumberOfGames {
+		countedGames = countedGames[:maximumNumberOfGames]
+	}
+
+	return countedGames
+}
+
+// leaderboardEntries returns entries for the given games, in the same order.
+func leaderboardEntries(rankedFinishedGames []FinishedGame) []LeaderboardEntry {
+	rankedEntries := make([]LeaderboardEntry, len(rankedFinishedGames))
+
+	for entryIndex, rankedGame := range rankedFinishedGames {
+		rankedEntries[entryIndex] = LeaderboardEntry{
+			GameName:           rankedGame.GameName,
+			PlayerNames:        rankedGame.PlayerNames,
+			PlayerDisplayNames: make([]string, len(rankedGame.PlayerNames)),
+			Score:              rankedGame.Score,
+			NumberOfTurnsTaken: rankedGame.NumberOfTurnsTaken,
+			ElapsedTime:        rankedGame.ElapsedTime(),
+			TimeOfFinish:       rankedGame.TimeOfFinish,
+		}
+	}
+
+	return rankedEntries
+}
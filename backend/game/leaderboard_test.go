@@ -0,0 +1,320 @@
+package game_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+func newFinishedGameForLeaderboard(
+	gameName string,
+	score int,
+	numberOfTurnsTaken int,
+	elapsedTime time.Duration,
+	timeOfFinish time.Time) game.FinishedGame {
+	return game.FinishedGame{
+		GameName:           gameName,
+		RulesetIdentifier:  1,
+		NumberOfPlayers:    2,
+		PlayerNames:        []string{"A", "B"},
+		Score:              score,
+		MaximumScore:       25,
+		NumberOfTurnsTaken: numberOfTurnsTaken,
+		TimeOfCreation:     timeOfFinish.Add(-elapsedTime),
+		TimeOfFinish:       timeOfFinish,
+	}
+}
+
+func TestRankFinishedGamesForLeaderboard(unitTest *testing.T) {
+	startTime := time.Now()
+
+	overBecauseOfMistakes :=
+		newFinishedGameForLeaderboard("mistakes", 0, 10, time.Minute, startTime)
+	overBecauseOfMistakes.IsOverBecauseOfMistakes = true
+
+	threePlayers :=
+		newFinishedGameForLeaderboard("three players", 25, 10, time.Minute, startTime)
+	threePlayers.NumberOfPlayers = 3
+
+	otherRuleset :=
+		newFinishedGameForLeaderboard("other ruleset", 25, 10, time.Minute, startTime)
+	otherRuleset.RulesetIdentifier = 2
+
+	finishedGames := []game.FinishedGame{
+		newFinishedGameForLeaderboard("low score", 10, 40, time.Hour, startTime),
+		newFinishedGameForLeaderboard("slow perfect", 25, 50, 2*time.Hour, startTime),
+		newFinishedGameForLeaderboard("quick perfect", 25, 50, time.Hour, startTime),
+		newFinishedGameForLeaderboard("few turns perfect", 25, 45, 3*time.Hour, startTime),
+		newFinishedGameForLeaderboard(
+			"same as quick but later",
+			25,
+			50,
+			time.Hour,
+			startTime.Add(time.Minute)),
+		newFinishedGameForLeaderboard(
+			"too early",
+			25,
+			30,
+			time.Minute,
+			startTime.Add(-48*time.Hour)),
+		newFinishedGameForLeaderboard(
+			"too late",
+			25,
+			30,
+			time.Minute,
+			startTime.Add(48*time.Hour)),
+		overBecauseOfMistakes,
+		threePlayers,
+		otherRuleset,
+	}
+
+	allTime := game.FinishedGameCriteria{
+		RulesetIdentifier: 1,
+		NumberOfPlayers:   2,
+	}
+
+	withinDay := game.FinishedGameCriteria{
+		RulesetIdentifier: 1,
+		NumberOfPlayers:   2,
+		FinishedFrom:      startTime.Add(-24 * time.Hour),
+		FinishedBefore:    startTime.Add(24 * time.Hour),
+	}
+
+	testCases := []struct {
+		testName                string
+		gameCriteria            game.FinishedGameCriteria
+		maximumNumberOfEntries  int
+		expectedHighestScores   []string
+		expectedFastestPerfects []string
+	}{
+		{
+			testName:               "All time",
+			gameCriteria:           allTime,
+			maximumNumberOfEntries: 10,
+			expectedHighestScores: []string{
+				"too early",
+				"too late",
+				"few turns perfect",
+				"quick perfect",
+				"same as quick but later",
+				"slow perfect",
+				"low score",
+			},
+			expectedFastestPerfects: []string{
+				"too early",
+				"too late",
+				"quick perfect",
+				"same as quick but later",
+				"slow perfect",
+				"few turns perfect",
+			},
+		},
+		{
+			testName:               "Within day",
+			gameCriteria:           withinDay,
+			maximumNumberOfEntries: 10,
+			expectedHighestScores: []string{
+				"few turns perfect",
+				"quick perfect",
+				"same as quick but later",
+				"slow perfect",
+				"low score",
+			},
+			expectedFastestPerfects: []string{
+				"quick perfect",
+				"same as quick but later",
+				"slow perfect",
+				"few turns perfect",
+			},
+		},
+		{
+			testName:                "Within day limited to two",
+			gameCriteria:            withinDay,
+			maximumNumberOfEntries:  2,
+			expectedHighestScores:   []string{"few turns perfect", "quick perfect"},
+			expectedFastestPerfects: []string{"quick perfect", "same as quick but later"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			actualLeaderboard :=
+				game.NewLeaderboard(
+					testCase.gameCriteria,
+					game.HighestScoringGames(
+						finishedGames,
+						testCase.gameCriteria,
+						testCase.maximumNumberOfEntries),
+					game.FastestPerfectGames(
+						finishedGames,
+						testCase.gameCriteria,
+						testCase.maximumNumberOfEntries))
+
+			assertLeaderboardEntryNames(
+				unitTest,
+				"highest scores",
+				actualLeaderboard.HighestScores,
+				testCase.expectedHighestScores)
+
+			assertLeaderboardEntryNames(
+				unitTest,
+				"fastest perfect games",
+				actualLeaderboard.FastestPerfectGames,
+				testCase.expectedFastestPerfects)
+		})
+	}
+}
+
+func TestRejectInvalidLeaderboardCriteria(unitTest *testing.T) {
+	startTime := time.Now()
+
+	testCases := []struct {
+		testName     string
+		gameCriteria game.FinishedGameCriteria
+	}{
+		{
+			testName: "Invalid ruleset",
+			gameCriteria: game.FinishedGameCriteria{
+				RulesetIdentifier: -1,
+				NumberOfPlayers:   2,
+			},
+		},
+		{
+			testName: "Too few players",
+			gameCriteria: game.FinishedGameCriteria{
+				RulesetIdentifier: testRuleset.BackendIdentifier(),
+				NumberOfPlayers:   testRuleset.MinimumNumberOfPlayers() - 1,
+			},
+		},
+		{
+			testName: "Too many players",
+			gameCriteria: game.FinishedGameCriteria{
+				RulesetIdentifier: testRuleset.BackendIdentifier(),
+				NumberOfPlayers:   testRuleset.MaximumNumberOfPlayers() + 1,
+			},
+		},
+		{
+			testName: "Empty range of time",
+			gameCriteria: game.FinishedGameCriteria{
+				RulesetIdentifier: testRuleset.BackendIdentifier(),
+				NumberOfPlayers:   2,
+				FinishedFrom:      startTime,
+				FinishedBefore:    startTime,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, collectionAndDescription := range prepareCollections(unitTest) {
+			testIdentifier :=
+				testCase.testName + "/" + collectionAndDescription.CollectionDescription
+
+			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+				actualLeaderboard, errorFromLeaderboard :=
+					collectionAndDescription.GameCollection.Leaderboard(
+						context.Background(),
+						testCase.gameCriteria,
+						0)
+
+				if errorFromLeaderboard == nil {
+					unitTest.Fatalf(
+						"Leaderboard(%+v, ...) did not produce expected error, instead gave %+v",
+						testCase.gameCriteria,
+						actualLeaderboard)
+				}
+			})
+		}
+	}
+}
+
+func TestFinishedGameIsRankedOnLeaderboard(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			playerNames := playerNamesAvailableInTest[:2]
+			gameName := "Finished game"
+			startTime := time.Now()
+
+			prepareFinishedGame(unitTest, gameCollection, gameName, playerNames)
+
+			gameCriteria := game.FinishedGameCriteria{
+				RulesetIdentifier: testRuleset.BackendIdentifier(),
+				NumberOfPlayers:   len(playerNames),
+				FinishedFrom:      startTime,
+			}
+
+			actualLeaderboard, errorFromLeaderboard :=
+				gameCollection.Leaderboard(context.Background(), gameCriteria, 0)
+
+			if errorFromLeaderboard != nil {
+				unitTest.Fatalf(
+					"Leaderboard(%+v, ...) produced unexpected error %v",
+					gameCriteria,
+					errorFromLeaderboard)
+			}
+
+			// Each player played a single card, so the game is on the leaderboard for
+			// scores but is not perfect.
+			if (len(actualLeaderboard.HighestScores) != 1) ||
+				(len(actualLeaderboard.FastestPerfectGames) != 0) {
+				unitTest.Fatalf(
+					"Leaderboard(%+v, ...) produced unexpected %+v",
+					gameCriteria,
+					actualLeaderboard)
+			}
+
+			rankedEntry := actualLeaderboard.HighestScores[0]
+
+			if (rankedEntry.GameName != gameName) ||
+				(rankedEntry.NumberOfTurnsTaken != len(playerNames)) ||
+				(len(rankedEntry.PlayerDisplayNames) != len(playerNames)) ||
+				(rankedEntry.PlayerDisplayNames[0] != playerNames[0]) {
+				unitTest.Fatalf(
+					"Leaderboard(%+v, ...) produced unexpected entry %+v",
+					gameCriteria,
+					rankedEntry)
+			}
+
+			laterCriteria := gameCriteria
+			laterCriteria.FinishedFrom = time.Now().Add(time.Hour)
+
+			laterLeaderboard, errorFromLaterLeaderboard :=
+				gameCollection.Leaderboard(context.Background(), laterCriteria, 0)
+
+			if (errorFromLaterLeaderboard != nil) ||
+				(len(laterLeaderboard.HighestScores) != 0) {
+				unitTest.Fatalf(
+					"Leaderboard(%+v, ...) produced %+v, %v, expected no entries",
+					laterCriteria,
+					laterLeaderboard,
+					errorFromLaterLeaderboard)
+			}
+		})
+	}
+}
+
+func assertLeaderboardEntryNames(
+	unitTest *testing.T,
+	listDescription string,
+	actualEntries []game.LeaderboardEntry,
+	expectedNames []string) {
+	if len(actualEntries) != len(expectedNames) {
+		unitTest.Fatalf(
+			"%v produced %+v, expected games %v",
+			listDescription,
+			actualEntries,
+			expectedNames)
+	}
+
+	for entryIndex, actualEntry := range actualEntries {
+		if actualEntry.GameName != expectedNames[entryIndex] {
+			unitTest.Fatalf(
+				"%v produced %+v, expected games %v",
+				listDescription,
+				actualEntries,
+				expectedNames)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lobby encapsulates the state of a game which has been created by a host with
+// a ruleset and a number of seats, but which has not yet been dealt. Players
+// join or leave the open seats until the host starts the game, at which point
+// the lobby is replaced by a game with the seated players in the order in which
+// they took their seats. If the lobby was created with a list of invited
+// players, only those players may take seats (by accepting their invitations),
+// and the game is dealt with the players in the order in which they were
+// invited. It has to be an exported struct with only exported data members so
+// that it serializes easily.
+type Lobby struct {
+	GameName           string
+	RulesetIdentifier  int
+	HostName           string
+	NumberOfSeats      int
+	SeatedPlayerNames  []string
+	InvitedPlayerNames []string
+	TimeOfCreation     time.Time
+}
+
+// NewLobby creates a new lobby with the given host already seated.
+func NewLobby(
+	gameName string,
+	gameRuleset Ruleset,
+	numberOfSeats int,
+	hostName string) Lobby {
+	return Lobby{
+		GameName:          gameName,
+		RulesetIdentifier: gameRuleset.BackendIdentifier(),
+		HostName:          hostName,
+		NumberOfSeats:     numberOfSeats,
+		SeatedPlayerNames: []string{hostName},
+		TimeOfCreation:    time.Now(),
+	}
+}
+
+// NewInvitationLobby creates a new lobby which only the given players may join,
+// with the first of the given players as the host, who is already seated.
+func NewInvitationLobby(
+	gameName string,
+	gameRuleset Ruleset,
+	invitedPlayerNames []string) Lobby {
+	hostName := ""
+	if len(invitedPlayerNames) > 0 {
+		hostName = invitedPlayerNames[0]
+	}
+
+	invitationLobby :=
+		NewLobby(gameName, gameRuleset, len(invitedPlayerNames), hostName)
+
+	invitationLobby.InvitedPlayerNames =
+		make([]string, len(invitedPlayerNames))
+	copy(invitationLobby.InvitedPlayerNames, invitedPlayerNames)
+
+	return invitationLobby
+}
+
+// IsByInvitationOnly returns true if only invited players may take seats in
+// the lobby.
+func (lobby *Lobby) IsByInvitationOnly() bool {
+	return len(lobby.InvitedPlayerNames) > 0
+}
+
+// HasInvitedPlayer returns true if the given player has been invited to take
+// a seat in the lobby, whether or not the player has already accepted.
+func (lobby *Lobby) HasInvitedPlayer(playerName string) bool {
+	for _, invitedPlayer := range lobby.InvitedPlayerNames {
+		if invitedPlayer == playerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PendingInvitees returns the names of the invited players who have not yet
+// accepted their invitations, in the order in which they were invited.
+func (lobby *Lobby) PendingInvitees() []string {
+	pendingInvitees := make([]string, 0)
+
+	for _, invitedPlayer := range lobby.InvitedPlayerNames {
+		if !lobby.HasSeatedPlayer(invitedPlayer) {
+			pendingInvitees = append(pendingInvitees, invitedPlayer)
+		}
+	}
+
+	return pendingInvitees
+}
+
+// PlayerNamesInTurnOrder returns the names of the players in the order in
+// which they should take turns once the game has been dealt: the order of
+// invitation if the lobby is by invitation only, otherwise the order in which
+// the players took their seats.
+func (lobby *Lobby) PlayerNamesInTurnOrder() []string {
+	if lobby.IsByInvitationOnly() {
+		return lobby.InvitedPlayerNames
+	}
+
+	return lobby.SeatedPlayerNames
+}
+
+// HasSeatedPlayer returns true if the given player has a seat in the lobby.
+func (lobby *Lobby) HasSeatedPlayer(playerName string) bool {
+	for _, seatedPlayer := range lobby.SeatedPlayerNames {
+		if seatedPlayer == playerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NumberOfOpenSeats returns the number of seats which are not yet taken.
+func (lobby *Lobby) NumberOfOpenSeats() int {
+	return lobby.NumberOfSeats - len(lobby.SeatedPlayerNames)
+}
+
+// SeatPlayer gives the next open seat to the given player, or returns an error
+// if the player is already seated, if there is no open seat, or if the lobby
+// is by invitation only and the player has not been invited.
+func (lobby *Lobby) SeatPlayer(playerName string) error {
+	if lobby.IsByInvitationOnly() && !lobby.HasInvitedPlayer(playerName) {
+		return ErrNotInvited.Errorf(
+			"Player %v has not been invited to game %v",
+			playerName,
+			lobby.GameName)
+	}
+
+	if lobby.HasSeatedPlayer(playerName) {
+		return fmt.Errorf(
+			"Player %v already has a seat in the lobby for game %v",
+			playerName,
+			lobby.GameName)
+	}
+
+	if lobby.NumberOfOpenSeats() <= 0 {
+		return ErrNoOpenSeats.Errorf(
+			"Lobby for game %v has no open seats",
+			lobby.GameName)
+	}
+
+	lobby.SeatedPlayerNames = append(lobby.SeatedPlayerNames, playerName)
+
+	return nil
+}
+
+// UnseatPlayer frees the seat of the given player, or returns an error if the
+// player does not have a seat.
+func (lobby *Lobby) UnseatPlayer(playerName string) error {
+	numberOfSeatedPlayers := len(lobby.SeatedPlayerNames)
+	remainingPlayers := make([]string, 0, numberOfSeatedPlayers)
+
+	for _, seatedPlayer := range lobby.SeatedPlayerNames {
+		if seatedPlayer != playerName {
+			remainingPlayers = append(remainingPlayers, seatedPlayer)
+		}
+	}
+
+	if len(remainingPlayers) == numberOfSeatedPlayers {
+		return fmt.Errorf(
+			"Player %v does not have a seat in the lobby for game %v",
+			playerName,
+			lobby.GameName)
+	}
+
+	lobby.SeatedPlayerNames = remainingPlayers
+
+	return nil
+}
+
+// ByLobbyCreationTime implements sort interface for []Lobby based on the
+// TimeOfCreation of each lobby.
+type ByLobbyCreationTime []Lobby
+
+// Len implements part of the sort interface for ByLobbyCreationTime.
+func (byCreationTime ByLobbyCreationTime) Len() int {
+	return len(byCreationTime)
+}
+
+// Swap implements part of the sort interface for ByLobbyCreationTime.
+func (byCreationTime ByLobbyCreationTime) Swap(firstIndex int, secondIndex int) {
+	byCreationTime[firstIndex], byCreationTime[secondIndex] =
+		byCreationTime[secondIndex], byCreationTime[firstIndex]
+}
+
+// Less implements part of the sort interface for ByLobbyCreationTime.
+func (byCreationTime ByLobbyCreationTime) Less(firstIndex int, secondIndex int) bool {
+	return byCreationTime[firstIndex].TimeOfCreation.Before(
+		byCreationTime[secondIndex].TimeOfCreation)
+}
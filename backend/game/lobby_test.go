@@ -0,0 +1,413 @@
+package game_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+func TestRejectInvalidNewLobby(unitTest *testing.T) {
+	testCases := []struct {
+		testName      string
+		gameName      string
+		numberOfSeats int
+		hostName      string
+	}{
+		{
+			testName:      "Empty game name",
+			gameName:      "",
+			numberOfSeats: 2,
+			hostName:      playerNamesAvailableInTest[0],
+		},
+		{
+			testName:      "Game name of only spaces",
+			gameName:      "   ",
+			numberOfSeats: 2,
+			hostName:      playerNamesAvailableInTest[0],
+		},
+		{
+			testName:      "Game name with control character",
+			gameName:      "Test\u0007game",
+			numberOfSeats: 2,
+			hostName:      playerNamesAvailableInTest[0],
+		},
+		{
+			testName:      "Too few seats",
+			gameName:      "Test game",
+			numberOfSeats: testRuleset.MinimumNumberOfPlayers() - 1,
+			hostName:      playerNamesAvailableInTest[0],
+		},
+		{
+			testName:      "Too many seats",
+			gameName:      "Test game",
+			numberOfSeats: testRuleset.MaximumNumberOfPlayers() + 1,
+			hostName:      playerNamesAvailableInTest[0],
+		},
+		{
+			testName:      "Unregistered host",
+			gameName:      "Test game",
+			numberOfSeats: 2,
+			hostName:      "Not A Registered Player",
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, collectionAndDescription := range prepareCollections(unitTest) {
+			testIdentifier :=
+				testCase.testName + "/" + collectionAndDescription.CollectionDescription
+
+			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+				gameCollection := collectionAndDescription.GameCollection
+
+				errorFromAdd :=
+					gameCollection.AddLobby(
+						context.Background(),
+						testCase.gameName,
+						testRuleset,
+						testCase.numberOfSeats,
+						testCase.hostName)
+
+				if errorFromAdd == nil {
+					unitTest.Fatalf("AddLobby(...) did not produce expected error")
+				}
+
+				allLobbies, errorFromRead := gameCollection.AllLobbies(context.Background())
+
+				if errorFromRead != nil {
+					unitTest.Fatalf("AllLobbies(...) produced unexpected error %v", errorFromRead)
+				}
+
+				if len(allLobbies) != 0 {
+					unitTest.Fatalf(
+						"AllLobbies(...) returned %v after rejected AddLobby(...)",
+						allLobbies)
+				}
+			})
+		}
+	}
+}
+
+func TestRejectLobbyWithNameOfExistingLobby(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+
+			errorFromFirstAdd :=
+				gameCollection.AddLobby(
+					context.Background(),
+					gameName,
+					testRuleset,
+					2,
+					playerNamesAvailableInTest[0])
+
+			if errorFromFirstAdd != nil {
+				unitTest.Fatalf("First AddLobby(...) produced unexpected error %v", errorFromFirstAdd)
+			}
+
+			errorFromSecondAdd :=
+				gameCollection.AddLobby(
+					context.Background(),
+					gameName,
+					testRuleset,
+					3,
+					playerNamesAvailableInTest[1])
+
+			if errorFromSecondAdd == nil {
+				unitTest.Fatalf("Second AddLobby(...) with same name did not produce expected error")
+			}
+
+			errorFromSimilarAdd :=
+				gameCollection.AddLobby(
+					context.Background(),
+					"  test   GAME ",
+					testRuleset,
+					3,
+					playerNamesAvailableInTest[1])
+
+			if errorFromSimilarAdd == nil {
+				unitTest.Fatalf(
+					"AddLobby(...) with name differing only by case and spaces" +
+						" did not produce expected error")
+			}
+		})
+	}
+}
+
+func TestRejectLobbyAndInvitationWithNameOfExistingGame(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+
+			errorFromAddGame :=
+				gameCollection.AddNew(
+					context.Background(),
+					"Test game",
+					testRuleset,
+					playerNamesAvailableInTest[:2])
+
+			if errorFromAddGame != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAddGame)
+			}
+
+			errorFromAddLobby :=
+				gameCollection.AddLobby(
+					context.Background(),
+					"TEST GAME",
+					testRuleset,
+					2,
+					playerNamesAvailableInTest[2])
+
+			if errorFromAddLobby == nil {
+				unitTest.Fatalf(
+					"AddLobby(...) with name of existing game differing only by case" +
+						" did not produce expected error")
+			}
+
+			errorFromInvite :=
+				gameCollection.InviteToNew(
+					context.Background(),
+					" test game",
+					testRuleset,
+					playerNamesAvailableInTest[2:4])
+
+			if errorFromInvite == nil {
+				unitTest.Fatalf(
+					"InviteToNew(...) with name of existing game differing only by case" +
+						" and spaces did not produce expected error")
+			}
+
+			allLobbies, errorFromRead := gameCollection.AllLobbies(context.Background())
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("AllLobbies(...) produced unexpected error %v", errorFromRead)
+			}
+
+			if len(allLobbies) != 0 {
+				unitTest.Fatalf(
+					"AllLobbies(...) returned %v after rejected AddLobby(...)",
+					allLobbies)
+			}
+		})
+	}
+}
+
+func TestLobbyNameIsNormalized(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+
+			errorFromAdd :=
+				gameCollection.AddLobby(
+					context.Background(),
+					"  Test   game ",
+					testRuleset,
+					2,
+					playerNamesAvailableInTest[0])
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddLobby(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			allLobbies, errorFromRead := gameCollection.AllLobbies(context.Background())
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("AllLobbies(...) produced unexpected error %v", errorFromRead)
+			}
+
+			if (len(allLobbies) != 1) || (allLobbies[0].GameName != "Test game") {
+				unitTest.Fatalf(
+					"AllLobbies(...) returned %v, expected one lobby for \"Test game\"",
+					allLobbies)
+			}
+		})
+	}
+}
+
+func TestStartGameFromFilledLobby(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			hostName := playerNamesAvailableInTest[0]
+			joiningPlayers := []string{
+				playerNamesAvailableInTest[2],
+				playerNamesAvailableInTest[1],
+			}
+
+			errorFromAdd :=
+				gameCollection.AddLobby(
+					context.Background(),
+					gameName,
+					testRuleset,
+					3,
+					hostName)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddLobby(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			errorFromFirstJoin :=
+				gameCollection.JoinLobby(context.Background(), gameName, joiningPlayers[0])
+
+			if errorFromFirstJoin != nil {
+				unitTest.Fatalf("JoinLobby(...) produced unexpected error %v", errorFromFirstJoin)
+			}
+
+			errorFromEarlyStart :=
+				gameCollection.StartGameFromLobby(context.Background(), gameName, hostName)
+
+			if errorFromEarlyStart == nil {
+				unitTest.Fatalf("StartGameFromLobby(...) with open seat did not produce expected error")
+			}
+
+			errorFromSecondJoin :=
+				gameCollection.JoinLobby(context.Background(), gameName, joiningPlayers[1])
+
+			if errorFromSecondJoin != nil {
+				unitTest.Fatalf("JoinLobby(...) produced unexpected error %v", errorFromSecondJoin)
+			}
+
+			errorFromNonHostStart :=
+				gameCollection.StartGameFromLobby(
+					context.Background(),
+					gameName,
+					joiningPlayers[0])
+
+			if errorFromNonHostStart == nil {
+				unitTest.Fatalf("StartGameFromLobby(...) by non-host did not produce expected error")
+			}
+
+			errorFromStart :=
+				gameCollection.StartGameFromLobby(context.Background(), gameName, hostName)
+
+			if errorFromStart != nil {
+				unitTest.Fatalf("StartGameFromLobby(...) produced unexpected error %v", errorFromStart)
+			}
+
+			allLobbies, errorFromRead := gameCollection.AllLobbies(context.Background())
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("AllLobbies(...) produced unexpected error %v", errorFromRead)
+			}
+
+			if len(allLobbies) != 0 {
+				unitTest.Fatalf("AllLobbies(...) returned %v after game was started", allLobbies)
+			}
+
+			gameView, errorFromView :=
+				gameCollection.ViewState(context.Background(), gameName, hostName)
+
+			if errorFromView != nil {
+				unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromView)
+			}
+
+			expectedPlayers := []string{hostName, joiningPlayers[0], joiningPlayers[1]}
+			actualPlayers, _, _ := gameView.CurrentTurnOrder()
+
+			if !reflect.DeepEqual(actualPlayers, expectedPlayers) {
+				unitTest.Fatalf(
+					"Started game had players %v, expected %v",
+					actualPlayers,
+					expectedPlayers)
+			}
+
+			actualCreator, errorFromCreator :=
+				gameCollection.Creator(context.Background(), gameName)
+
+			if (errorFromCreator != nil) || (actualCreator != hostName) {
+				unitTest.Fatalf(
+					"Creator(%v) returned %v, %v - expected host %v",
+					gameName,
+					actualCreator,
+					errorFromCreator,
+					hostName)
+			}
+		})
+	}
+}
+
+func TestLeavingLobby(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			firstGame := "First game"
+			secondGame := "Second game"
+			hostName := playerNamesAvailableInTest[0]
+			joiningPlayer := playerNamesAvailableInTest[1]
+
+			for _, gameName := range []string{firstGame, secondGame} {
+				errorFromAdd :=
+					gameCollection.AddLobby(
+						context.Background(),
+						gameName,
+						testRuleset,
+						2,
+						hostName)
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf("AddLobby(...) produced unexpected error %v", errorFromAdd)
+				}
+
+				errorFromJoin :=
+					gameCollection.JoinLobby(context.Background(), gameName, joiningPlayer)
+
+				if errorFromJoin != nil {
+					unitTest.Fatalf("JoinLobby(...) produced unexpected error %v", errorFromJoin)
+				}
+			}
+
+			errorFromPlayerLeaving :=
+				gameCollection.LeaveLobby(context.Background(), firstGame, joiningPlayer)
+
+			if errorFromPlayerLeaving != nil {
+				unitTest.Fatalf(
+					"LeaveLobby(...) produced unexpected error %v",
+					errorFromPlayerLeaving)
+			}
+
+			errorFromHostLeaving :=
+				gameCollection.LeaveLobby(context.Background(), secondGame, hostName)
+
+			if errorFromHostLeaving != nil {
+				unitTest.Fatalf(
+					"LeaveLobby(...) produced unexpected error %v",
+					errorFromHostLeaving)
+			}
+
+			allLobbies, errorFromRead := gameCollection.AllLobbies(context.Background())
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("AllLobbies(...) produced unexpected error %v", errorFromRead)
+			}
+
+			expectedLobbies := []game.Lobby{
+				game.Lobby{
+					GameName:          firstGame,
+					RulesetIdentifier: testRuleset.BackendIdentifier(),
+					HostName:          hostName,
+					NumberOfSeats:     2,
+					SeatedPlayerNames: []string{hostName},
+				},
+			}
+
+			if len(allLobbies) != 1 {
+				unitTest.Fatalf(
+					"AllLobbies(...) returned %v, expected only lobby for %v",
+					allLobbies,
+					firstGame)
+			}
+
+			allLobbies[0].TimeOfCreation = expectedLobbies[0].TimeOfCreation
+
+			if !reflect.DeepEqual(allLobbies, expectedLobbies) {
+				unitTest.Fatalf(
+					"AllLobbies(...) returned %v, expected %v",
+					allLobbies,
+					expectedLobbies)
+			}
+		})
+	}
+}
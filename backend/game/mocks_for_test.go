@@ -24,6 +24,11 @@ type mockPlayerState struct {
 	MockColor string
 }
 
+// Identifier gets mocked as the same as the name.
+func (mockPlayer *mockPlayerState) Identifier() string {
+	return mockPlayer.MockName
+}
+
 // Name gets mocked.
 func (mockPlayer *mockPlayerState) Name() string {
 	return mockPlayer.MockName
@@ -34,13 +39,34 @@ func (mockPlayer *mockPlayerState) Color() string {
 	return mockPlayer.MockColor
 }
 
+// Role gets mocked.
+func (mockPlayer *mockPlayerState) Role() string {
+	return "player"
+}
+
+// Profile gets mocked as the zero profile.
+func (mockPlayer *mockPlayerState) Profile() player.Profile {
+	return player.Profile{}
+}
+
+// Contacts gets mocked as having no friends and no groups.
+func (mockPlayer *mockPlayerState) Contacts() player.Contacts {
+	return player.Contacts{}
+}
+
 type mockPlayerProvider struct {
-	MockPlayers map[string]*mockPlayerState
+	MockPlayers                     map[string]*mockPlayerState
+	MockColorsInGame                map[string]string
+	ErrorForSeparatedChatColors     error
+	ArgumentsForSeparatedChatColors [][]string
 }
 
 func NewMockPlayerProvider(initialPlayers []string) *mockPlayerProvider {
 	mockProvider := &mockPlayerProvider{
-		MockPlayers: make(map[string]*mockPlayerState, 0),
+		MockPlayers:                     make(map[string]*mockPlayerState, 0),
+		MockColorsInGame:                make(map[string]string, 0),
+		ErrorForSeparatedChatColors:     nil,
+		ArgumentsForSeparatedChatColors: make([][]string, 0),
 	}
 
 	for _, initialPlayer := range initialPlayers {
@@ -65,6 +91,38 @@ func (mockProvider *mockPlayerProvider) Get(
 	return mockPlayer, nil
 }
 
+// SeparatedChatColors gets mocked, recording the players so that tests can check when
+// the colors of the participants of a game are worked out, and returning the mocked
+// color in the game for each player who has one and the color of the player otherwise.
+func (mockProvider *mockPlayerProvider) SeparatedChatColors(
+	executionContext context.Context,
+	playerIdentifiers []string) ([]string, error) {
+	mockProvider.ArgumentsForSeparatedChatColors =
+		append(mockProvider.ArgumentsForSeparatedChatColors, playerIdentifiers)
+
+	if mockProvider.ErrorForSeparatedChatColors != nil {
+		return nil, mockProvider.ErrorForSeparatedChatColors
+	}
+
+	colorsInGame := make([]string, len(playerIdentifiers))
+	for playerIndex, playerIdentifier := range playerIdentifiers {
+		colorInGame, hasColorInGame := mockProvider.MockColorsInGame[playerIdentifier]
+		if hasColorInGame {
+			colorsInGame[playerIndex] = colorInGame
+			continue
+		}
+
+		mockPlayer, isInMap := mockProvider.MockPlayers[playerIdentifier]
+		if !isInMap {
+			return nil, fmt.Errorf("not in map")
+		}
+
+		colorsInGame[playerIndex] = mockPlayer.MockColor
+	}
+
+	return colorsInGame, nil
+}
+
 type mockRuleset struct {
 	ReturnForNumberOfMistakesIndicatingGameOver int
 	ReturnForInferredHandAfterHint              []card.Inferred
@@ -173,6 +231,7 @@ type argumentsForRecordChatMessage struct {
 }
 
 type argumentsForEnactTurnByCardAction struct {
+	TurnInt       int
 	MessageString string
 	PlayerState   player.ReadonlyState
 	IndexInt      int
@@ -182,6 +241,7 @@ type argumentsForEnactTurnByCardAction struct {
 }
 
 type argumentsForEnactTurnByHint struct {
+	TurnInt             int
 	MessageString       string
 	PlayerState         player.ReadonlyState
 	ReceiverName        string
@@ -198,14 +258,18 @@ type mockGameState struct {
 	ReturnForName                                  string
 	ReturnForRuleset                               game.Ruleset
 	ReturnForPlayerNames                           []string
+	ReturnForCreatorName                           string
 	ReturnForCreationTime                          time.Time
 	ReturnForChatLog                               []message.FromPlayer
 	ReturnForActionLog                             []message.FromPlayer
 	ReturnForGameIsFinished                        bool
 	ReturnForTurn                                  int
+	ReturnForVersion                               int
 	ReturnForTurnsTakenWithEmptyDeck               int
 	ReturnForNumberOfReadyHints                    int
 	ReturnForNumberOfMistakesMade                  int
+	ReturnForNumberOfDiscardsByPlayer              map[string]int
+	ReturnForNumberOfMistakesByPlayer              map[string]int
 	ReturnForDeckSize                              int
 	ReturnForPlayedForColor                        map[string][]card.Defined
 	ReturnForNumberOfDiscardedCards                map[card.Defined]int
@@ -231,6 +295,7 @@ func NewMockGameState(testReference *testing.T) *mockGameState {
 		ReturnForName:                                  "",
 		ReturnForRuleset:                               nil,
 		ReturnForPlayerNames:                           nil,
+		ReturnForCreatorName:                           "",
 		ReturnForCreationTime:                          time.Now(),
 		ReturnForChatLog:                               nil,
 		ReturnForActionLog:                             nil,
@@ -239,6 +304,8 @@ func NewMockGameState(testReference *testing.T) *mockGameState {
 		ReturnForTurnsTakenWithEmptyDeck:               0,
 		ReturnForNumberOfReadyHints:                    -1,
 		ReturnForNumberOfMistakesMade:                  -1,
+		ReturnForNumberOfDiscardsByPlayer:              make(map[string]int, 0),
+		ReturnForNumberOfMistakesByPlayer:              make(map[string]int, 0),
 		ReturnForDeckSize:                              -1,
 		ReturnForPlayedForColor:                        make(map[string][]card.Defined, 0),
 		ReturnForNumberOfDiscardedCards:                make(map[card.Defined]int, 0),
@@ -272,6 +339,11 @@ func (mockGame *mockGameState) PlayerNames() []string {
 	return mockGame.ReturnForPlayerNames
 }
 
+// CreatorName gets mocked.
+func (mockGame *mockGameState) CreatorName() string {
+	return mockGame.ReturnForCreatorName
+}
+
 // CreationTime gets mocked.
 func (mockGame *mockGameState) CreationTime() time.Time {
 	return mockGame.ReturnForCreationTime
@@ -297,6 +369,11 @@ func (mockGame *mockGameState) Turn() int {
 	return mockGame.ReturnForTurn
 }
 
+// Version gets mocked.
+func (mockGame *mockGameState) Version() int {
+	return mockGame.ReturnForVersion
+}
+
 // TurnsTakenWithEmptyDeck gets mocked.
 func (mockGame *mockGameState) TurnsTakenWithEmptyDeck() int {
 	return mockGame.ReturnForTurnsTakenWithEmptyDeck
@@ -312,6 +389,16 @@ func (mockGame *mockGameState) NumberOfMistakesMade() int {
 	return mockGame.ReturnForNumberOfMistakesMade
 }
 
+// NumberOfDiscardsByPlayer gets mocked.
+func (mockGame *mockGameState) NumberOfDiscardsByPlayer(playerName string) int {
+	return mockGame.ReturnForNumberOfDiscardsByPlayer[playerName]
+}
+
+// NumberOfMistakesByPlayer gets mocked.
+func (mockGame *mockGameState) NumberOfMistakesByPlayer(playerName string) int {
+	return mockGame.ReturnForNumberOfMistakesByPlayer[playerName]
+}
+
 // DeckSize gets mocked.
 func (mockGame *mockGameState) DeckSize() int {
 	return mockGame.ReturnForDeckSize
@@ -382,6 +469,7 @@ func (mockGame *mockGameState) RecordChatMessage(
 // EnactTurnByDiscardingAndReplacing gets mocked.
 func (mockGame *mockGameState) EnactTurnByDiscardingAndReplacing(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	indexInHand int,
@@ -404,6 +492,7 @@ func (mockGame *mockGameState) EnactTurnByDiscardingAndReplacing(
 		append(
 			mockGame.ArgumentsFromEnactTurnByDiscardingAndReplacing,
 			argumentsForEnactTurnByCardAction{
+				TurnInt:       expectedTurn,
 				MessageString: actionMessage,
 				PlayerState:   actingPlayer,
 				IndexInt:      indexInHand,
@@ -418,6 +507,7 @@ func (mockGame *mockGameState) EnactTurnByDiscardingAndReplacing(
 // EnactTurnByPlayingAndReplacing gets mocked.
 func (mockGame *mockGameState) EnactTurnByPlayingAndReplacing(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	indexInHand int,
@@ -438,6 +528,7 @@ func (mockGame *mockGameState) EnactTurnByPlayingAndReplacing(
 		append(
 			mockGame.ArgumentsFromEnactTurnByPlayingAndReplacing,
 			argumentsForEnactTurnByCardAction{
+				TurnInt:       expectedTurn,
 				MessageString: actionMessage,
 				PlayerState:   actingPlayer,
 				IndexInt:      indexInHand,
@@ -452,6 +543,7 @@ func (mockGame *mockGameState) EnactTurnByPlayingAndReplacing(
 // EnactTurnByUpdatingHandWithHint gets mocked.
 func (mockGame *mockGameState) EnactTurnByUpdatingHandWithHint(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	receivingPlayerName string,
@@ -472,6 +564,7 @@ func (mockGame *mockGameState) EnactTurnByUpdatingHandWithHint(
 		append(
 			mockGame.ArgumentsFromEnactTurnByUpdatingHandWithHint,
 			argumentsForEnactTurnByHint{
+				TurnInt:             expectedTurn,
 				MessageString:       actionMessage,
 				PlayerState:         actingPlayer,
 				ReceiverName:        receivingPlayerName,
@@ -484,6 +577,7 @@ func (mockGame *mockGameState) EnactTurnByUpdatingHandWithHint(
 
 type mockGameDefinition struct {
 	gameName                           string
+	creatorName                        string
 	chatLogLength                      int
 	gameRuleset                        game.Ruleset
 	playersInTurnOrderWithInitialHands []game.PlayerNameWithHand
@@ -504,6 +598,7 @@ type mockGamePersister struct {
 	TestErrorForRandomSeed                  error
 	TestErrorForReadAndWriteGame            error
 	TestErrorForReadAllWithPlayer           error
+	TestErrorForIsNameTaken                 error
 	TestErrorForAddGame                     error
 	ArgumentsForAddGame                     []mockGameDefinition
 	TestErrorForRemoveGameFromListForPlayer error
@@ -524,6 +619,7 @@ func NewMockGamePersister(
 		TestErrorForRandomSeed:                  testError,
 		TestErrorForReadAndWriteGame:            testError,
 		TestErrorForReadAllWithPlayer:           testError,
+		TestErrorForIsNameTaken:                 testError,
 		TestErrorForAddGame:                     testError,
 		ArgumentsForAddGame:                     make([]mockGameDefinition, 0),
 		TestErrorForRemoveGameFromListForPlayer: testError,
@@ -571,9 +667,23 @@ func (mockImplementation *mockGamePersister) ReadAllWithPlayer(
 	return mockImplementation.ReturnForReadAllWithPlayer, mockImplementation.ReturnForNontestError
 }
 
+func (mockImplementation *mockGamePersister) IsNameTaken(
+	executionContext context.Context,
+	gameName string) (bool, error) {
+	if mockImplementation.TestErrorForIsNameTaken != nil {
+		mockImplementation.TestReference.Fatalf(
+			"IsNameTaken(%v): %v",
+			gameName,
+			mockImplementation.TestErrorForIsNameTaken)
+	}
+
+	return false, mockImplementation.ReturnForNontestError
+}
+
 func (mockImplementation *mockGamePersister) AddGame(
 	executionContext context.Context,
 	gameName string,
+	creatorName string,
 	chatLogLength int,
 	initialActionLog []message.FromPlayer,
 	gameRuleset game.Ruleset,
@@ -581,8 +691,9 @@ func (mockImplementation *mockGamePersister) AddGame(
 	initialDeck []card.Defined) error {
 	if mockImplementation.TestErrorForAddGame != nil {
 		mockImplementation.TestReference.Fatalf(
-			"AddGame(%v, %v, %v, %v, %v, %v): %v",
+			"AddGame(%v, %v, %v, %v, %v, %v, %v): %v",
 			gameName,
+			creatorName,
 			chatLogLength,
 			initialActionLog,
 			gameRuleset,
@@ -594,6 +705,7 @@ func (mockImplementation *mockGamePersister) AddGame(
 	addedGame :=
 		mockGameDefinition{
 			gameName:                           gameName,
+			creatorName:                        creatorName,
 			chatLogLength:                      chatLogLength,
 			gameRuleset:                        gameRuleset,
 			playersInTurnOrderWithInitialHands: playersInTurnOrderWithInitialHands,
@@ -644,3 +756,20 @@ func (mockImplementation *mockGamePersister) Delete(
 
 	return mockImplementation.ReturnForNontestError
 }
+
+// failingSnapshotPersister wraps around a spectator persister to fail to add
+// any snapshot, while passing every other call on to the wrapped persister.
+type failingSnapshotPersister struct {
+	game.SpectatorPersister
+	ArgumentsForAddSnapshot []string
+}
+
+func (mockImplementation *failingSnapshotPersister) AddSnapshot(
+	executionContext context.Context,
+	gameName string,
+	newSnapshot game.NeutralSnapshot) error {
+	mockImplementation.ArgumentsForAddSnapshot =
+		append(mockImplementation.ArgumentsForAddSnapshot, gameName)
+
+	return fmt.Errorf("mock error adding snapshot for game %v", gameName)
+}
@@ -127,6 +127,9 @@ type ViewForPlayer interface {
 	// Turn should just wrap around the read-only game state's Turn function.
 	Turn() int
 
+	// Version should just wrap around the read-only game state's Version function.
+	Version() int
+
 	// Score should derive the score from the cards in the played area.
 	Score() int
 
@@ -172,10 +175,16 @@ type ViewForPlayer interface {
 	// cards in their hand which was inferred directly from the hints officially given so
 	// far.
 	KnowledgeOfOwnHand(holdingPlayer string) ([]card.Inferred, error)
+
+	// ParticipantDisplayName should return the current display name of the participant
+	// with the given identifier, or the identifier itself if the participant is not known.
+	ParticipantDisplayName(participantIdentifier string) string
 }
 
 // ExecutorForPlayer should encapsulate functions to execute actions by a particular player
-// on the state of the game.
+// on the state of the game. Each turn should be based on the turn of the game which the
+// acting player saw, and should return a TurnConflictError rather than be taken if
+// another turn has been taken since, unless the expected turn is TurnNotGiven.
 type ExecutorForPlayer interface {
 	// RecordChatMessage should record the given chat message from the acting player, or
 	// return an error if it was not possible.
@@ -187,6 +196,7 @@ type ExecutorForPlayer interface {
 	// hand of the acting player, or return an error if it was not possible.
 	TakeTurnByDiscarding(
 		executionContext context.Context,
+		expectedTurn int,
 		indexInHand int) error
 
 	// TakeTurnByPlaying should enact a turn by attempting to play the indicated card from
@@ -195,6 +205,7 @@ type ExecutorForPlayer interface {
 	// possible.
 	TakeTurnByPlaying(
 		executionContext context.Context,
+		expectedTurn int,
 		indexInHand int) error
 
 	// TakeTurnByHintingColor should enact a turn by giving a hint to the receiving player
@@ -202,6 +213,7 @@ type ExecutorForPlayer interface {
 	// not possible.
 	TakeTurnByHintingColor(
 		executionContext context.Context,
+		expectedTurn int,
 		receivingPlayer string,
 		hintedColor string) error
 
@@ -210,6 +222,7 @@ type ExecutorForPlayer interface {
 	// was not possible.
 	TakeTurnByHintingIndex(
 		executionContext context.Context,
+		expectedTurn int,
 		receivingPlayer string,
 		hintedIndex int) error
 }
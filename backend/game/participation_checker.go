@@ -0,0 +1,71 @@
+package game
+
+import (
+	"context"
+)
+
+// ParticipationChecker wraps around a StatePersister to determine whether a player
+// is still a participant in any game, so that a player.StateCollection can block the
+// deletion of such players, and with whom the player shares games, so that it can
+// stop players from choosing nearly the same colors as each other. It does not depend on a player collection, so it can be
+// created before the player collection which in turn has to be given to the game
+// StateCollection.
+type ParticipationChecker struct {
+	statePersister StatePersister
+}
+
+// NewParticipationChecker creates a new ParticipationChecker around the given
+// StatePersister.
+func NewParticipationChecker(statePersister StatePersister) *ParticipationChecker {
+	return &ParticipationChecker{
+		statePersister: statePersister,
+	}
+}
+
+// IsParticipantInAnyGame returns true if any game in the persister has the given
+// player in its list of participants, whether or not the game is finished, as the
+// views of finished games still show the participants. Games which the player has
+// left do not count.
+func (participationChecker *ParticipationChecker) IsParticipantInAnyGame(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	gamesWithPlayer, errorFromRead :=
+		participationChecker.statePersister.ReadAllWithPlayer(
+			executionContext,
+			playerIdentifier)
+
+	if errorFromRead != nil {
+		return false, errorFromRead
+	}
+
+	return len(gamesWithPlayer) > 0, nil
+}
+
+// FellowParticipants returns the identifiers of the other participants in the games
+// which have the given player in their list of participants, each only once.
+func (participationChecker *ParticipationChecker) FellowParticipants(
+	executionContext context.Context,
+	playerIdentifier string) ([]string, error) {
+	gamesWithPlayer, errorFromRead :=
+		participationChecker.statePersister.ReadAllWithPlayer(
+			executionContext,
+			playerIdentifier)
+
+	if errorFromRead != nil {
+		return nil, errorFromRead
+	}
+
+	fellowIdentifiers := make([]string, 0)
+	alreadyFound := map[string]bool{playerIdentifier: true}
+
+	for _, gameWithPlayer := range gamesWithPlayer {
+		for _, participantIdentifier := range gameWithPlayer.PlayerNames() {
+			if !alreadyFound[participantIdentifier] {
+				alreadyFound[participantIdentifier] = true
+				fellowIdentifiers = append(fellowIdentifiers, participantIdentifier)
+			}
+		}
+	}
+
+	return fellowIdentifiers, nil
+}
@@ -0,0 +1,139 @@
+package game_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+func TestIsParticipantInAnyGame(unitTest *testing.T) {
+	testCases := []struct {
+		testName           string
+		gamesWithPlayer    []game.ReadonlyState
+		errorFromPersister error
+		expectedResult     bool
+		expectedError      bool
+	}{
+		{
+			testName:           "error from persister",
+			gamesWithPlayer:    nil,
+			errorFromPersister: fmt.Errorf("Expected error for test"),
+			expectedResult:     false,
+			expectedError:      true,
+		},
+		{
+			testName:           "no games",
+			gamesWithPlayer:    []game.ReadonlyState{},
+			errorFromPersister: nil,
+			expectedResult:     false,
+			expectedError:      false,
+		},
+		{
+			testName:           "one game",
+			gamesWithPlayer:    []game.ReadonlyState{NewMockGameState(unitTest)},
+			errorFromPersister: nil,
+			expectedResult:     true,
+			expectedError:      false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockPersister :=
+				NewMockGamePersister(
+					unitTest,
+					fmt.Errorf("Only ReadAllWithPlayer(...) should be called"))
+			mockPersister.TestErrorForReadAllWithPlayer = nil
+			mockPersister.ReturnForReadAllWithPlayer = testCase.gamesWithPlayer
+			mockPersister.ReturnForNontestError = testCase.errorFromPersister
+
+			participationChecker := game.NewParticipationChecker(mockPersister)
+
+			actualResult, actualError :=
+				participationChecker.IsParticipantInAnyGame(
+					context.Background(),
+					"Test Player")
+
+			if (actualResult != testCase.expectedResult) ||
+				((actualError != nil) != testCase.expectedError) {
+				unitTest.Fatalf(
+					"IsParticipantInAnyGame(...) returned %v, %v - expected %v and error: %v",
+					actualResult,
+					actualError,
+					testCase.expectedResult,
+					testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestFellowParticipants(unitTest *testing.T) {
+	playerIdentifier := "Test Player"
+
+	firstGame := NewMockGameState(unitTest)
+	firstGame.ReturnForPlayerNames = []string{"Fellow One", playerIdentifier, "Fellow Two"}
+	secondGame := NewMockGameState(unitTest)
+	secondGame.ReturnForPlayerNames = []string{playerIdentifier, "Fellow Two", "Fellow Three"}
+
+	testCases := []struct {
+		testName           string
+		gamesWithPlayer    []game.ReadonlyState
+		errorFromPersister error
+		expectedResult     []string
+		expectedError      bool
+	}{
+		{
+			testName:           "error from persister",
+			gamesWithPlayer:    nil,
+			errorFromPersister: fmt.Errorf("Expected error for test"),
+			expectedResult:     nil,
+			expectedError:      true,
+		},
+		{
+			testName:           "no games",
+			gamesWithPlayer:    []game.ReadonlyState{},
+			errorFromPersister: nil,
+			expectedResult:     []string{},
+			expectedError:      false,
+		},
+		{
+			testName:           "two games sharing a fellow",
+			gamesWithPlayer:    []game.ReadonlyState{firstGame, secondGame},
+			errorFromPersister: nil,
+			expectedResult:     []string{"Fellow One", "Fellow Two", "Fellow Three"},
+			expectedError:      false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockPersister :=
+				NewMockGamePersister(
+					unitTest,
+					fmt.Errorf("Only ReadAllWithPlayer(...) should be called"))
+			mockPersister.TestErrorForReadAllWithPlayer = nil
+			mockPersister.ReturnForReadAllWithPlayer = testCase.gamesWithPlayer
+			mockPersister.ReturnForNontestError = testCase.errorFromPersister
+
+			participationChecker := game.NewParticipationChecker(mockPersister)
+
+			actualResult, actualError :=
+				participationChecker.FellowParticipants(
+					context.Background(),
+					playerIdentifier)
+
+			if !reflect.DeepEqual(actualResult, testCase.expectedResult) ||
+				((actualError != nil) != testCase.expectedError) {
+				unitTest.Fatalf(
+					"FellowParticipants(...) returned %v, %v - expected %v and error: %v",
+					actualResult,
+					actualError,
+					testCase.expectedResult,
+					testCase.expectedError)
+			}
+		})
+	}
+}
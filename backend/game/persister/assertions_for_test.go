@@ -149,6 +149,15 @@ func assertGameStateAsExpected(
 			actualGame.CreationTime())
 	}
 
+	if actualGame.Version() != expectedGame.Version {
+		unitTest.Fatalf(
+			testIdentifier+"/actual\n  %+v\ndid not match expected\n  %+v\nin version - expected %v, actual %v",
+			actualGame,
+			expectedGame,
+			expectedGame.Version,
+			actualGame.Version())
+	}
+
 	if actualGame.Turn() != expectedGame.Turn {
 		unitTest.Fatalf(
 			testIdentifier+"/actual\n  %+v\ndid not match expected\n  %+v\nin turn - expected %v, actual %v",
@@ -64,6 +64,14 @@ func (mockClient *mockLimitedClient) AllMatching(
 	return mockClient.IteratorToReturn
 }
 
+func (mockClient *mockLimitedClient) AllMatchingInOrder(
+	executionContext context.Context,
+	filtersAndValues []cloud.FilterAndValue,
+	orderExpressions []string,
+	maximumNumber int) cloud.LimitedIterator {
+	return mockClient.IteratorToReturn
+}
+
 func (mockClient *mockLimitedClient) Get(
 	executionContext context.Context,
 	nameForKey string,
@@ -84,6 +92,12 @@ func (mockClient *mockLimitedClient) Delete(
 	return mockClient.ErrorToReturn
 }
 
+func (mockClient *mockLimitedClient) RunInTransaction(
+	executionContext context.Context,
+	transactionFunction func(cloud.LimitedTransaction) error) error {
+	return mockClient.ErrorToReturn
+}
+
 type mockClientProvider struct {
 	ClientToReturn *mockLimitedClient
 	ErrorToReturn  error
@@ -137,13 +151,13 @@ func TestPropagateErrorFromClientProvider(unitTest *testing.T) {
 	}
 
 	errorFromAddGameRequest :=
-		cloudDatastorePersister.AddGame(executionContext, gameName, 0, nil, nil, nil, nil)
+		cloudDatastorePersister.AddGame(executionContext, gameName, "", 0, nil, nil, nil, nil)
 
 	if errorFromAddGameRequest == nil {
 		unitTest.Fatalf(
 			"Successfully created Cloud Datastore persister %+v from"+
 				" invalid client provider, and got got nil error from"+
-				" .AddGame(%v, %v, 0, nil, nil, nil, nil)",
+				" .AddGame(%v, %v, \"\", 0, nil, nil, nil, nil)",
 			cloudDatastorePersister,
 			executionContext,
 			gameName)
@@ -236,11 +250,11 @@ func TestAddGamePropagatesIteratorError(unitTest *testing.T) {
 
 	gameName := "does not matter"
 	errorFromAddGame :=
-		cloudDatastorePersister.AddGame(nil, gameName, 0, nil, nil, nil, nil)
+		cloudDatastorePersister.AddGame(nil, gameName, "", 0, nil, nil, nil, nil)
 
 	if errorFromAddGame == nil {
 		unitTest.Fatalf(
-			"AddGame(nil, %v, 0, nil, nil, nil, nil) produced nil error",
+			"AddGame(nil, %v, \"\", 0, nil, nil, nil, nil) produced nil error",
 			gameName)
 	}
 }
@@ -206,7 +206,7 @@ func (gameState *DeserializedState) EnactTurnByDiscardingAndReplacing(
 
 	discardedCard, errorFromTakingCard :=
 		gameState.takeCardFromHandReplacingIfPossible(
-			actingPlayer.Name(),
+			actingPlayer.Identifier(),
 			indexInHand,
 			knowledgeOfDrawnCard)
 
@@ -227,6 +227,22 @@ func (gameState *DeserializedState) EnactTurnByDiscardingAndReplacing(
 	gameState.NumberOfMistakesMadeSoFar += numberOfMistakesMadeToAdd
 	gameState.incrementTurnNumbers(deckAlreadyEmptyAtStartOfTurn)
 
+	// A card which goes into the discard pile without a mistake was discarded
+	// deliberately.
+	if numberOfMistakesMadeToAdd > 0 {
+		gameState.NumberOfMistakesInTurnOrder =
+			gameState.addToCountForPlayer(
+				gameState.NumberOfMistakesInTurnOrder,
+				actingPlayer.Identifier(),
+				numberOfMistakesMadeToAdd)
+	} else {
+		gameState.NumberOfDiscardsInTurnOrder =
+			gameState.addToCountForPlayer(
+				gameState.NumberOfDiscardsInTurnOrder,
+				actingPlayer.Identifier(),
+				1)
+	}
+
 	gameState.recordActionMessage(
 		actingPlayer,
 		actionMessage)
@@ -257,7 +273,7 @@ func (gameState *DeserializedState) EnactTurnByPlayingAndReplacing(
 
 	playedCard, errorFromTakingCard :=
 		gameState.takeCardFromHandReplacingIfPossible(
-			actingPlayer.Name(),
+			actingPlayer.Identifier(),
 			indexInHand,
 			knowledgeOfDrawnCard)
 
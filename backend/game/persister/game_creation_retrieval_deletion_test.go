@@ -2,6 +2,7 @@ package persister_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/benoleary/ilutulestikud/backend/game"
@@ -147,6 +148,7 @@ func TestRejectAddGameWithNoName(unitTest *testing.T) {
 				statePersister.GamePersister.AddGame(
 					context.Background(),
 					"",
+					threePlayersWithNilHands[0].PlayerName,
 					logLengthForTest,
 					nil,
 					defaultTestRuleset,
@@ -211,6 +213,7 @@ func TestRejectAddGameWithExistingName(unitTest *testing.T) {
 					statePersister.GamePersister.AddGame(
 						context.Background(),
 						gameName,
+						twoPlayersWithNilHands[0].PlayerName,
 						logLengthForTest,
 						nil,
 						defaultTestRuleset,
@@ -245,6 +248,7 @@ func TestRejectAddGameWithExistingName(unitTest *testing.T) {
 					statePersister.GamePersister.AddGame(
 						context.Background(),
 						gameName,
+						threePlayersWithNilHands[0].PlayerName,
 						logLengthForTest,
 						nil,
 						defaultTestRuleset,
@@ -294,6 +298,198 @@ func TestRejectAddGameWithExistingName(unitTest *testing.T) {
 	}
 }
 
+func TestNormalizeGameNameAndRejectNameDifferingByCase(unitTest *testing.T) {
+	givenName := " " + testGameNamePrefix + "Cafe\u0301   game "
+	normalizedName := testGameNamePrefix + "Caf\u00e9 game"
+	statePersisters := preparePersisters(unitTest, []string{normalizedName})
+
+	twoPlayersWithNilHands :=
+		[]game.PlayerNameWithHand{
+			game.PlayerNameWithHand{
+				PlayerName:  defaultTestPlayers[0],
+				InitialHand: nil,
+			},
+			game.PlayerNameWithHand{
+				PlayerName:  defaultTestPlayers[1],
+				InitialHand: nil,
+			},
+		}
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Normalize game name/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.GamePersister.AddGame(
+					context.Background(),
+					givenName,
+					twoPlayersWithNilHands[0].PlayerName,
+					logLengthForTest,
+					nil,
+					defaultTestRuleset,
+					twoPlayersWithNilHands,
+					nil)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"AddGame(%q, ...) produced an error: %v",
+					givenName,
+					errorFromAdd)
+			}
+
+			addedState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/ReadAndWriteGame(normalized name)",
+					unitTest,
+					normalizedName,
+					statePersister.GamePersister)
+
+			if addedState.Name() != normalizedName {
+				unitTest.Fatalf(
+					"AddGame(%q, ...) stored game with name %q, expected %q",
+					givenName,
+					addedState.Name(),
+					normalizedName)
+			}
+
+			invalidNames := []string{
+				strings.ToLower(normalizedName),
+				testGameNamePrefix + "Bell\u0007",
+			}
+
+			for _, invalidName := range invalidNames {
+				errorFromInvalidAdd :=
+					statePersister.GamePersister.AddGame(
+						context.Background(),
+						invalidName,
+						twoPlayersWithNilHands[0].PlayerName,
+						logLengthForTest,
+						nil,
+						defaultTestRuleset,
+						twoPlayersWithNilHands,
+						nil)
+
+				if errorFromInvalidAdd == nil {
+					unitTest.Fatalf(
+						"AddGame(%q, ...) did not produce an error",
+						invalidName)
+				}
+			}
+
+			errorFromDelete :=
+				statePersister.GamePersister.Delete(context.Background(), normalizedName)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%q) produced an error: %v",
+					normalizedName,
+					errorFromDelete)
+			}
+		})
+	}
+}
+
+func TestFindGameByNameAsGivenWhenAdded(unitTest *testing.T) {
+	givenName := testGameNamePrefix + "Cafe\u0301 round trip "
+	normalizedName := testGameNamePrefix + "Caf\u00e9 round trip"
+	statePersisters := preparePersisters(unitTest, []string{normalizedName})
+
+	twoPlayersWithNilHands :=
+		[]game.PlayerNameWithHand{
+			game.PlayerNameWithHand{
+				PlayerName:  defaultTestPlayers[0],
+				InitialHand: nil,
+			},
+			game.PlayerNameWithHand{
+				PlayerName:  defaultTestPlayers[1],
+				InitialHand: nil,
+			},
+		}
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Find game by name as given/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.GamePersister.AddGame(
+					context.Background(),
+					givenName,
+					twoPlayersWithNilHands[0].PlayerName,
+					logLengthForTest,
+					nil,
+					defaultTestRuleset,
+					twoPlayersWithNilHands,
+					nil)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"AddGame(%q, ...) produced an error: %v",
+					givenName,
+					errorFromAdd)
+			}
+
+			foundGame, errorFromGet :=
+				statePersister.GamePersister.ReadAndWriteGame(
+					context.Background(),
+					givenName)
+
+			if errorFromGet != nil {
+				unitTest.Fatalf(
+					"ReadAndWriteGame(%q) produced an error: %v",
+					givenName,
+					errorFromGet)
+			}
+
+			if foundGame.Read().Name() != normalizedName {
+				unitTest.Fatalf(
+					"ReadAndWriteGame(%q) found game with name %q, expected %q",
+					givenName,
+					foundGame.Read().Name(),
+					normalizedName)
+			}
+
+			errorFromLeave :=
+				statePersister.GamePersister.RemoveGameFromListForPlayer(
+					context.Background(),
+					givenName,
+					defaultTestPlayers[0])
+
+			if errorFromLeave != nil {
+				unitTest.Fatalf(
+					"RemoveGameFromListForPlayer(%q, %v) produced an error: %v",
+					givenName,
+					defaultTestPlayers[0],
+					errorFromLeave)
+			}
+
+			errorFromDelete :=
+				statePersister.GamePersister.Delete(context.Background(), givenName)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%q) produced an error: %v",
+					givenName,
+					errorFromDelete)
+			}
+
+			deletedGame, errorFromGetDeleted :=
+				statePersister.GamePersister.ReadAndWriteGame(
+					context.Background(),
+					normalizedName)
+
+			if errorFromGetDeleted == nil {
+				unitTest.Fatalf(
+					"ReadAndWriteGame(%q) after Delete(%q) produced state %v and nil error",
+					normalizedName,
+					givenName,
+					deletedGame)
+			}
+		})
+	}
+}
+
 func TestAddGamesThenLeaveGamesThenDeleteGames(unitTest *testing.T) {
 	statePersisters := preparePersisters(unitTest, creationRetrievalDeletionTestGameNames)
 	leavingPlayer := defaultTestPlayers[0]
@@ -338,6 +534,7 @@ func TestAddGamesThenLeaveGamesThenDeleteGames(unitTest *testing.T) {
 				statePersister.GamePersister.AddGame(
 					context.Background(),
 					firstGameName,
+					twoPlayersWithNilHands[0].PlayerName,
 					logLengthForTest,
 					nil,
 					defaultTestRuleset,
@@ -401,6 +598,7 @@ func TestAddGamesThenLeaveGamesThenDeleteGames(unitTest *testing.T) {
 				statePersister.GamePersister.AddGame(
 					context.Background(),
 					secondGameName,
+					threePlayersWithNilHands[0].PlayerName,
 					logLengthForTest,
 					nil,
 					defaultTestRuleset,
@@ -445,6 +643,7 @@ func TestAddGamesThenLeaveGamesThenDeleteGames(unitTest *testing.T) {
 				statePersister.GamePersister.AddGame(
 					context.Background(),
 					thirdGameName,
+					threePlayersWithNilHands[0].PlayerName,
 					logLengthForTest,
 					nil,
 					defaultTestRuleset,
@@ -751,6 +950,7 @@ func TestAddGamesThenLeaveGamesThenDeleteGames(unitTest *testing.T) {
 				statePersister.GamePersister.AddGame(
 					context.Background(),
 					firstGameName,
+					twoPlayersWithNilHands[0].PlayerName,
 					logLengthForTest,
 					nil,
 					defaultTestRuleset,
@@ -43,7 +43,7 @@ func TestPlayedCardsDeserializedCorrectly(unitTest *testing.T) {
 
 	numberOfExpectedPlayedCards := len(expectedPlayedCards)
 	serializablePart :=
-		persister.NewSerializableState("deserializing played cards test", 0, nil, testRuleset, nil, nil)
+		persister.NewSerializableState("deserializing played cards test", "", 0, nil, testRuleset, nil, nil)
 	serializablePart.PlayedCards = expectedPlayedCards
 
 	deserializedState :=
@@ -119,7 +119,7 @@ func TestDiscardedCardsDeserializedCorrectly(unitTest *testing.T) {
 
 	numberOfExpectedDiscardedCards := len(expectedDiscardedCards)
 	serializablePart :=
-		persister.NewSerializableState("deserializing discarded cards test", 0, nil, testRuleset, nil, nil)
+		persister.NewSerializableState("deserializing discarded cards test", "", 0, nil, testRuleset, nil, nil)
 	serializablePart.DiscardedCards = expectedDiscardedCards
 
 	deserializedState :=
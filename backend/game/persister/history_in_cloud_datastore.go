@@ -0,0 +1,235 @@
+package persister
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+// CloudDatastoreHistoryKeyKind denotes the kind for the entities which will
+// store the records of finished games in the Google Cloud Datastore.
+const CloudDatastoreHistoryKeyKind = "FinishedGame"
+
+// inCloudDatastoreHistoryPersister stores the records of finished games in
+// Google Cloud Datastore, keyed by the names of the games along with the
+// times at which they were created, as a name may be used again once the
+// game with that name has been deleted.
+type inCloudDatastoreHistoryPersister struct {
+	clientProvider  cloud.DatastoreClientProvider
+	datastoreClient cloud.LimitedClient
+}
+
+// NewHistoryInCloudDatastore creates a history persister.
+func NewHistoryInCloudDatastore(
+	clientProvider cloud.DatastoreClientProvider) game.HistoryPersister {
+	return &inCloudDatastoreHistoryPersister{
+		clientProvider:  clientProvider,
+		datastoreClient: nil,
+	}
+}
+
+// rankableFinishedGame is the form in which a record of a finished game is stored in
+// the Cloud Datastore. The record is embedded so that its fields are stored as
+// properties at the top level, alongside the elapsed time and whether the record is
+// counted for each list of a leaderboard, which are only stored so that queries for
+// leaderboards can filter and order by them.
+type rankableFinishedGame struct {
+	game.FinishedGame
+	ElapsedNanoseconds              int64
+	IsCountedForHighestScores       bool
+	IsCountedForFastestPerfectGames bool
+}
+
+// newRankableFinishedGame creates the form of the given record for storing.
+func newRankableFinishedGame(finishedGame game.FinishedGame) rankableFinishedGame {
+	return rankableFinishedGame{
+		FinishedGame:                    finishedGame,
+		ElapsedNanoseconds:              finishedGame.ElapsedTime().Nanoseconds(),
+		IsCountedForHighestScores:       game.IsCountedForHighestScores(finishedGame),
+		IsCountedForFastestPerfectGames: game.IsCountedForFastestPerfectGames(finishedGame),
+	}
+}
+
+// AddFinishedGame stores the given record of a finished game.
+func (historyPersister *inCloudDatastoreHistoryPersister) AddFinishedGame(
+	executionContext context.Context,
+	finishedGame game.FinishedGame) error {
+	initializedClient, errorFromAcquiral :=
+		historyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	recordKey :=
+		fmt.Sprintf(
+			"%v@%v",
+			finishedGame.GameName,
+			finishedGame.TimeOfCreation.UnixNano())
+
+	recordForStoring := newRankableFinishedGame(finishedGame)
+
+	return initializedClient.Put(
+		executionContext,
+		recordKey,
+		&recordForStoring)
+}
+
+// ReadFinishedGamesWithPlayer returns the records of all the finished games which
+// had the given player as a participant.
+func (historyPersister *inCloudDatastoreHistoryPersister) ReadFinishedGamesWithPlayer(
+	executionContext context.Context,
+	playerName string) ([]game.FinishedGame, error) {
+	initializedClient, errorFromAcquiral :=
+		historyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return nil, errorFromAcquiral
+	}
+
+	// As for games, the equality filter on an array property selects the
+	// entity if any of the elements match the sought value.
+	resultIterator :=
+		initializedClient.AllMatching(
+			executionContext,
+			"PlayerNames =",
+			playerName)
+
+	gamesWithPlayer := make([]game.FinishedGame, 0)
+
+	for {
+		var storedRecord rankableFinishedGame
+		errorFromNext := resultIterator.DeserializeNext(&storedRecord)
+
+		if resultIterator.IsDone(errorFromNext) {
+			return gamesWithPlayer, nil
+		}
+
+		if errorFromNext != nil {
+			return nil, errorFromNext
+		}
+
+		gamesWithPlayer = append(gamesWithPlayer, storedRecord.FinishedGame)
+	}
+}
+
+// ReadHighestScoringGames returns at most the given number of records of the finished
+// games which match the given criteria and are counted for the highest scores, ranked
+// by score, then by fewer turns, then by less time elapsed, then by earlier finish.
+func (historyPersister *inCloudDatastoreHistoryPersister) ReadHighestScoringGames(
+	executionContext context.Context,
+	gameCriteria game.FinishedGameCriteria,
+	maximumNumberOfGames int) ([]game.FinishedGame, error) {
+	return historyPersister.readRankedGames(
+		executionContext,
+		gameCriteria,
+		"IsCountedForHighestScores",
+		[]string{"-Score", "NumberOfTurnsTaken", "ElapsedNanoseconds", "TimeOfFinish"},
+		maximumNumberOfGames)
+}
+
+// ReadFastestPerfectGames returns at most the given number of records of the perfect
+// games which match the given criteria, ranked by less time elapsed, then by fewer
+// turns, then by earlier finish.
+func (historyPersister *inCloudDatastoreHistoryPersister) ReadFastestPerfectGames(
+	executionContext context.Context,
+	gameCriteria game.FinishedGameCriteria,
+	maximumNumberOfGames int) ([]game.FinishedGame, error) {
+	return historyPersister.readRankedGames(
+		executionContext,
+		gameCriteria,
+		"IsCountedForFastestPerfectGames",
+		[]string{"ElapsedNanoseconds", "NumberOfTurnsTaken", "TimeOfFinish"},
+		maximumNumberOfGames)
+}
+
+// readRankedGames returns at most the given number of records of the finished games
+// with the given ruleset and number of players for which the given boolean property is
+// true, in the order given by the order expressions. The Cloud Datastore only allows a
+// range on the time of finish if the results are ordered by the time of finish first,
+// so a range of time is instead checked against each record as it is read in ranked
+// order, without a limit on the query, and the reading stops as soon as enough records
+// have been found. Without a range of time, the query itself is limited. Each list
+// needs a composite index on the ruleset identifier, the number of players, the
+// boolean property, and the properties in the order expressions.
+func (historyPersister *inCloudDatastoreHistoryPersister) readRankedGames(
+	executionContext context.Context,
+	gameCriteria game.FinishedGameCriteria,
+	countingProperty string,
+	orderExpressions []string,
+	maximumNumberOfGames int) ([]game.FinishedGame, error) {
+	initializedClient, errorFromAcquiral :=
+		historyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return nil, errorFromAcquiral
+	}
+
+	filtersAndValues := []cloud.FilterAndValue{
+		cloud.FilterAndValue{
+			FilterExpression: "RulesetIdentifier =",
+			ValueToMatch:     gameCriteria.RulesetIdentifier,
+		},
+		cloud.FilterAndValue{
+			FilterExpression: "NumberOfPlayers =",
+			ValueToMatch:     gameCriteria.NumberOfPlayers,
+		},
+		cloud.FilterAndValue{
+			FilterExpression: countingProperty + " =",
+			ValueToMatch:     true,
+		},
+	}
+
+	// The Cloud Datastore treats a negative limit as no limit at all.
+	limitOfQuery := maximumNumberOfGames
+	if !gameCriteria.FinishedFrom.IsZero() || !gameCriteria.FinishedBefore.IsZero() {
+		limitOfQuery = -1
+	}
+
+	resultIterator :=
+		initializedClient.AllMatchingInOrder(
+			executionContext,
+			filtersAndValues,
+			orderExpressions,
+			limitOfQuery)
+
+	rankedGames := make([]game.FinishedGame, 0)
+
+	for len(rankedGames) < maximumNumberOfGames {
+		var storedRecord rankableFinishedGame
+		errorFromNext := resultIterator.DeserializeNext(&storedRecord)
+
+		if resultIterator.IsDone(errorFromNext) {
+			break
+		}
+
+		if errorFromNext != nil {
+			return nil, errorFromNext
+		}
+
+		if gameCriteria.Matches(storedRecord.FinishedGame) {
+			rankedGames = append(rankedGames, storedRecord.FinishedGame)
+		}
+	}
+
+	return rankedGames, nil
+}
+
+// acquireClient returns the connection to the Cloud Datastore,
+// initializing it if it has not already been initialized.
+func (historyPersister *inCloudDatastoreHistoryPersister) acquireClient(
+	executionContext context.Context) (cloud.LimitedClient, error) {
+	if historyPersister.datastoreClient == nil {
+		cloudDatastoreClient, errorFromCloudDatastore :=
+			historyPersister.clientProvider.NewClient(executionContext)
+		if errorFromCloudDatastore != nil {
+			return nil, errorFromCloudDatastore
+		}
+
+		historyPersister.datastoreClient = cloudDatastoreClient
+	}
+
+	return historyPersister.datastoreClient, nil
+}
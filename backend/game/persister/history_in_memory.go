@@ -0,0 +1,91 @@
+package persister
+
+import (
+	"context"
+	"sync"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+// inMemoryHistoryPersister stores the records of finished games in the order in
+// which they were added. It ignores all context structs passed to its functions.
+type inMemoryHistoryPersister struct {
+	mutualExclusion sync.Mutex
+	finishedGames   []game.FinishedGame
+}
+
+// NewHistoryInMemory creates a history persister around a slice of records of
+// finished games.
+func NewHistoryInMemory() game.HistoryPersister {
+	return &inMemoryHistoryPersister{
+		mutualExclusion: sync.Mutex{},
+		finishedGames:   make([]game.FinishedGame, 0),
+	}
+}
+
+// AddFinishedGame appends the given record to the slice of records. The context
+// is ignored.
+func (historyPersister *inMemoryHistoryPersister) AddFinishedGame(
+	executionContext context.Context,
+	finishedGame game.FinishedGame) error {
+	historyPersister.mutualExclusion.Lock()
+	defer historyPersister.mutualExclusion.Unlock()
+
+	historyPersister.finishedGames =
+		append(historyPersister.finishedGames, finishedGame)
+
+	return nil
+}
+
+// ReadFinishedGamesWithPlayer returns the records of all the finished games which
+// had the given player as a participant, in the order in which they were added. The
+// context is ignored.
+func (historyPersister *inMemoryHistoryPersister) ReadFinishedGamesWithPlayer(
+	executionContext context.Context,
+	playerName string) ([]game.FinishedGame, error) {
+	historyPersister.mutualExclusion.Lock()
+	defer historyPersister.mutualExclusion.Unlock()
+
+	gamesWithPlayer := make([]game.FinishedGame, 0)
+
+	for _, finishedGame := range historyPersister.finishedGames {
+		if _, isParticipant := finishedGame.Participant(playerName); isParticipant {
+			gamesWithPlayer = append(gamesWithPlayer, finishedGame)
+		}
+	}
+
+	return gamesWithPlayer, nil
+}
+
+// ReadHighestScoringGames returns at most the given number of records of the finished
+// games which match the given criteria and are counted for the highest scores, ranked
+// by score. Every record is in memory anyway, so they are all checked. The context is
+// ignored.
+func (historyPersister *inMemoryHistoryPersister) ReadHighestScoringGames(
+	executionContext context.Context,
+	gameCriteria game.FinishedGameCriteria,
+	maximumNumberOfGames int) ([]game.FinishedGame, error) {
+	historyPersister.mutualExclusion.Lock()
+	defer historyPersister.mutualExclusion.Unlock()
+
+	return game.HighestScoringGames(
+		historyPersister.finishedGames,
+		gameCriteria,
+		maximumNumberOfGames), nil
+}
+
+// ReadFastestPerfectGames returns at most the given number of records of the perfect
+// games which match the given criteria, ranked by speed. Every record is in memory
+// anyway, so they are all checked. The context is ignored.
+func (historyPersister *inMemoryHistoryPersister) ReadFastestPerfectGames(
+	executionContext context.Context,
+	gameCriteria game.FinishedGameCriteria,
+	maximumNumberOfGames int) ([]game.FinishedGame, error) {
+	historyPersister.mutualExclusion.Lock()
+	defer historyPersister.mutualExclusion.Unlock()
+
+	return game.FastestPerfectGames(
+		historyPersister.finishedGames,
+		gameCriteria,
+		maximumNumberOfGames), nil
+}
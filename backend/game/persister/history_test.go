@@ -0,0 +1,325 @@
+package persister_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/persister"
+)
+
+type historyPersisterAndDescription struct {
+	HistoryPersister     game.HistoryPersister
+	PersisterDescription string
+}
+
+func prepareHistoryPersisters() []historyPersisterAndDescription {
+	historyDatastoreClientProvider :=
+		cloud.NewIlutulestikudDatastoreClientProvider(persister.CloudDatastoreHistoryKeyKind)
+
+	return []historyPersisterAndDescription{
+		historyPersisterAndDescription{
+			HistoryPersister:     persister.NewHistoryInMemory(),
+			PersisterDescription: "in-memory persister",
+		},
+		historyPersisterAndDescription{
+			HistoryPersister:     persister.NewHistoryInCloudDatastore(historyDatastoreClientProvider),
+			PersisterDescription: "in-Cloud-Datastore persister",
+		},
+	}
+}
+
+func TestReadFinishedGamesWithPlayer(unitTest *testing.T) {
+	firstPlayer := testGameNamePrefix + "first player with history"
+	secondPlayer := testGameNamePrefix + "second player with history"
+	playerWithoutHistory := testGameNamePrefix + "player without history"
+	reusedGameName := testGameNamePrefix + "finished game"
+	otherGameName := testGameNamePrefix + "other finished game"
+
+	for _, historyPersister := range prepareHistoryPersisters() {
+		testIdentifier := "History of games/" + historyPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			testPersister := historyPersister.HistoryPersister
+			creationTime := time.Now()
+
+			// The same name is used twice, as a game may be deleted and then a new
+			// game with the same name may be created, and both should be kept.
+			finishedGames := []game.FinishedGame{
+				newFinishedGameForTest(reusedGameName, creationTime, firstPlayer),
+				newFinishedGameForTest(
+					reusedGameName,
+					creationTime.Add(time.Hour),
+					firstPlayer,
+					secondPlayer),
+				newFinishedGameForTest(otherGameName, creationTime, secondPlayer),
+			}
+
+			for _, finishedGame := range finishedGames {
+				errorFromAdd := testPersister.AddFinishedGame(executionContext, finishedGame)
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf(
+						"AddFinishedGame(%v) produced unexpected error %v",
+						finishedGame,
+						errorFromAdd)
+				}
+			}
+
+			expectedNumbers := map[string]int{
+				firstPlayer:          2,
+				secondPlayer:         2,
+				playerWithoutHistory: 0,
+			}
+
+			for playerName, expectedNumber := range expectedNumbers {
+				gamesWithPlayer, errorFromRead :=
+					testPersister.ReadFinishedGamesWithPlayer(executionContext, playerName)
+
+				if (errorFromRead != nil) || (len(gamesWithPlayer) != expectedNumber) {
+					unitTest.Fatalf(
+						"ReadFinishedGamesWithPlayer(%v) produced %v, %v, expected %v games",
+						playerName,
+						gamesWithPlayer,
+						errorFromRead,
+						expectedNumber)
+				}
+
+				for _, gameWithPlayer := range gamesWithPlayer {
+					if _, isParticipant := gameWithPlayer.Participant(playerName); !isParticipant {
+						unitTest.Fatalf(
+							"ReadFinishedGamesWithPlayer(%v) produced %v without the player",
+							playerName,
+							gameWithPlayer)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReadRankedFinishedGamesMatchingCriteria(unitTest *testing.T) {
+	firstPlayer := testGameNamePrefix + "first player for criteria"
+	secondPlayer := testGameNamePrefix + "second player for criteria"
+	thirdPlayer := testGameNamePrefix + "third player for criteria"
+	rulesetIdentifier := game.NewStandardWithoutRainbow().BackendIdentifier()
+
+	for _, historyPersister := range prepareHistoryPersisters() {
+		testIdentifier := "Criteria for games/" + historyPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			testPersister := historyPersister.HistoryPersister
+
+			// Each record finishes a minute after its creation, and the range of time
+			// starts at the earliest finish, so that records from earlier tests against
+			// a persistent store are not matched.
+			startTime := time.Now()
+
+			lateGame :=
+				newFinishedGameForTest(
+					testGameNamePrefix+"late",
+					startTime.Add(time.Hour),
+					firstPlayer,
+					secondPlayer)
+			lateGame.Score = 20
+
+			perfectGame :=
+				newFinishedGameForTest(
+					testGameNamePrefix+"perfect",
+					startTime.Add(2*time.Hour),
+					firstPlayer,
+					secondPlayer)
+			perfectGame.Score = perfectGame.MaximumScore
+
+			overBecauseOfMistakes :=
+				newFinishedGameForTest(
+					testGameNamePrefix+"mistakes",
+					startTime,
+					firstPlayer,
+					secondPlayer)
+			overBecauseOfMistakes.Score = 0
+			overBecauseOfMistakes.IsOverBecauseOfMistakes = true
+
+			finishedGames := []game.FinishedGame{
+				newFinishedGameForTest(
+					testGameNamePrefix+"early",
+					startTime,
+					firstPlayer,
+					secondPlayer),
+				lateGame,
+				perfectGame,
+				overBecauseOfMistakes,
+				newFinishedGameForTest(
+					testGameNamePrefix+"three players",
+					startTime,
+					firstPlayer,
+					secondPlayer,
+					thirdPlayer),
+			}
+
+			for _, finishedGame := range finishedGames {
+				errorFromAdd := testPersister.AddFinishedGame(executionContext, finishedGame)
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf(
+						"AddFinishedGame(%v) produced unexpected error %v",
+						finishedGame,
+						errorFromAdd)
+				}
+			}
+
+			earliestFinish := startTime.Add(time.Minute)
+
+			testCases := []struct {
+				testName                string
+				gameCriteria            game.FinishedGameCriteria
+				maximumNumberOfGames    int
+				expectedHighestScores   []string
+				expectedFastestPerfects []string
+			}{
+				{
+					testName: "Two players with no end",
+					gameCriteria: game.FinishedGameCriteria{
+						RulesetIdentifier: rulesetIdentifier,
+						NumberOfPlayers:   2,
+						FinishedFrom:      earliestFinish,
+					},
+					maximumNumberOfGames: 10,
+					expectedHighestScores: []string{
+						testGameNamePrefix + "perfect",
+						testGameNamePrefix + "late",
+						testGameNamePrefix + "early",
+					},
+					expectedFastestPerfects: []string{testGameNamePrefix + "perfect"},
+				},
+				{
+					testName: "Two players limited to two",
+					gameCriteria: game.FinishedGameCriteria{
+						RulesetIdentifier: rulesetIdentifier,
+						NumberOfPlayers:   2,
+						FinishedFrom:      earliestFinish,
+					},
+					maximumNumberOfGames: 2,
+					expectedHighestScores: []string{
+						testGameNamePrefix + "perfect",
+						testGameNamePrefix + "late",
+					},
+					expectedFastestPerfects: []string{testGameNamePrefix + "perfect"},
+				},
+				{
+					testName: "Two players before late game",
+					gameCriteria: game.FinishedGameCriteria{
+						RulesetIdentifier: rulesetIdentifier,
+						NumberOfPlayers:   2,
+						FinishedFrom:      earliestFinish,
+						FinishedBefore:    earliestFinish.Add(time.Hour),
+					},
+					maximumNumberOfGames:    10,
+					expectedHighestScores:   []string{testGameNamePrefix + "early"},
+					expectedFastestPerfects: []string{},
+				},
+				{
+					testName: "Three players",
+					gameCriteria: game.FinishedGameCriteria{
+						RulesetIdentifier: rulesetIdentifier,
+						NumberOfPlayers:   3,
+						FinishedFrom:      earliestFinish,
+					},
+					maximumNumberOfGames:    10,
+					expectedHighestScores:   []string{testGameNamePrefix + "three players"},
+					expectedFastestPerfects: []string{},
+				},
+				{
+					testName: "Other ruleset",
+					gameCriteria: game.FinishedGameCriteria{
+						RulesetIdentifier: rulesetIdentifier + 1,
+						NumberOfPlayers:   2,
+						FinishedFrom:      earliestFinish,
+					},
+					maximumNumberOfGames:    10,
+					expectedHighestScores:   []string{},
+					expectedFastestPerfects: []string{},
+				},
+			}
+
+			for _, testCase := range testCases {
+				unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+					highestScoringGames, errorFromHighestScores :=
+						testPersister.ReadHighestScoringGames(
+							executionContext,
+							testCase.gameCriteria,
+							testCase.maximumNumberOfGames)
+
+					assertFinishedGameNames(
+						unitTest,
+						"ReadHighestScoringGames(...)",
+						highestScoringGames,
+						errorFromHighestScores,
+						testCase.expectedHighestScores)
+
+					fastestPerfectGames, errorFromFastestPerfects :=
+						testPersister.ReadFastestPerfectGames(
+							executionContext,
+							testCase.gameCriteria,
+							testCase.maximumNumberOfGames)
+
+					assertFinishedGameNames(
+						unitTest,
+						"ReadFastestPerfectGames(...)",
+						fastestPerfectGames,
+						errorFromFastestPerfects,
+						testCase.expectedFastestPerfects)
+				})
+			}
+		})
+	}
+}
+
+func assertFinishedGameNames(
+	unitTest *testing.T,
+	testIdentifier string,
+	actualGames []game.FinishedGame,
+	errorFromRead error,
+	expectedGameNames []string) {
+	if errorFromRead != nil {
+		unitTest.Fatalf("%v produced unexpected error %v", testIdentifier, errorFromRead)
+	}
+
+	actualGameNames := make([]string, len(actualGames))
+	for gameIndex, actualGame := range actualGames {
+		actualGameNames[gameIndex] = actualGame.GameName
+	}
+
+	assertStringSlicesMatch(testIdentifier, unitTest, expectedGameNames, actualGameNames)
+}
+
+func newFinishedGameForTest(
+	gameName string,
+	creationTime time.Time,
+	playerNames ...string) game.FinishedGame {
+	participantRecords := make([]game.FinishedGameParticipant, len(playerNames))
+
+	for playerIndex, playerName := range playerNames {
+		participantRecords[playerIndex] =
+			game.FinishedGameParticipant{
+				PlayerName:         playerName,
+				NumberOfTurnsTaken: 1,
+			}
+	}
+
+	return game.FinishedGame{
+		GameName:          gameName,
+		RulesetIdentifier: game.NewStandardWithoutRainbow().BackendIdentifier(),
+		NumberOfPlayers:   len(playerNames),
+		PlayerNames:       playerNames,
+		Participants:      participantRecords,
+		Score:             1,
+		MaximumScore:      25,
+		TimeOfCreation:    creationTime,
+		TimeOfFinish:      creationTime.Add(time.Minute),
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/naming"
 	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
@@ -25,15 +26,26 @@ type inCloudDatastorePersister struct {
 	randomNumberGenerator *rand.Rand
 	clientProvider        cloud.DatastoreClientProvider
 	datastoreClient       cloud.LimitedClient
+	nameRules             naming.Rules
 }
 
-// NewInCloudDatastore creates a game state persister.
+// NewInCloudDatastore creates a game state persister which applies the
+// default naming rules.
 func NewInCloudDatastore(
 	clientProvider cloud.DatastoreClientProvider) game.StatePersister {
+	return NewInCloudDatastoreWithNameRules(clientProvider, naming.DefaultRules())
+}
+
+// NewInCloudDatastoreWithNameRules creates a game state persister which
+// applies the given naming rules.
+func NewInCloudDatastoreWithNameRules(
+	clientProvider cloud.DatastoreClientProvider,
+	nameRules naming.Rules) game.StatePersister {
 	return &inCloudDatastorePersister{
 		randomNumberGenerator: rand.New(rand.NewSource(time.Now().Unix())),
 		clientProvider:        clientProvider,
 		datastoreClient:       nil,
+		nameRules:             nameRules,
 	}
 }
 
@@ -114,13 +126,18 @@ func (gamePersister *inCloudDatastorePersister) ReadAllWithPlayer(
 }
 
 // AddGame adds an element to the collection which is a new object implementing
-// the ReadAndWriteState interface from the given arguments, and returns the
-// identifier of the newly-created game, along with an error which of course is
-// nil if there was no problem. It returns an error if a game with the given name
-// already exists.
+// the ReadAndWriteState interface from the given arguments, created by the given
+// player. The game is keyed by its name normalized according to the naming rules,
+// and the key of the name according to the naming rules is stored with it. It
+// returns an error if the name does not follow the naming rules, if a game with
+// the same name according to the naming rules already exists, or if the Cloud
+// Datastore API returns an error, and otherwise returns nil. Games stored before
+// keys of names were introduced have no key, so their names are compared exactly
+// with the normalized name.
 func (gamePersister *inCloudDatastorePersister) AddGame(
 	executionContext context.Context,
 	gameName string,
+	creatorName string,
 	chatLogLength int,
 	initialActionLog []message.FromPlayer,
 	gameRuleset game.Ruleset,
@@ -130,6 +147,14 @@ func (gamePersister *inCloudDatastorePersister) AddGame(
 		return fmt.Errorf("Game must have a name")
 	}
 
+	normalizedName, errorFromName :=
+		gamePersister.nameRules.Normalize("Game name", gameName)
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	nameKey := gamePersister.nameRules.UniquenessKey(normalizedName)
+
 	initializedClient, errorFromAcquiral :=
 		gamePersister.acquireClient(executionContext)
 
@@ -137,38 +162,71 @@ func (gamePersister *inCloudDatastorePersister) AddGame(
 		return errorFromAcquiral
 	}
 
-	isAlreadyInDatastore, errorFromCheck :=
-		cloud.DoesNameExist(
+	existingName, isTaken, errorFromCheck :=
+		gamePersister.nameWithKey(
 			executionContext,
 			initializedClient,
-			gameName)
+			normalizedName,
+			nameKey)
 
 	if errorFromCheck != nil {
 		return errorFromCheck
 	}
 
-	if isAlreadyInDatastore {
-		return fmt.Errorf("Game with name %v already exists", gameName)
+	if isTaken {
+		return game.ErrNameTaken.Errorf("Game with name %v already exists", existingName)
 	}
 
 	serializableState :=
 		NewSerializableState(
-			gameName,
+			normalizedName,
+			creatorName,
 			chatLogLength,
 			initialActionLog,
 			gameRuleset,
 			playersInTurnOrderWithInitialHands,
 			initialDeck)
 
+	serializableState.GameNameKey = nameKey
+
 	errorFromPut :=
 		initializedClient.Put(
 			executionContext,
-			gameName,
+			normalizedName,
 			&serializableState)
 
 	return errorFromPut
 }
 
+// IsNameTaken returns true if there is already a game with the same name as the
+// given name according to the naming rules, or an error if the given name does
+// not follow the naming rules or if the Cloud Datastore API returns an error.
+func (gamePersister *inCloudDatastorePersister) IsNameTaken(
+	executionContext context.Context,
+	gameName string) (bool, error) {
+	normalizedName, errorFromName :=
+		gamePersister.nameRules.Normalize("Game name", gameName)
+	if errorFromName != nil {
+		return false, errorFromName
+	}
+
+	initializedClient, errorFromAcquiral :=
+		gamePersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return false, errorFromAcquiral
+	}
+
+	_, isTaken, errorFromCheck :=
+		gamePersister.nameWithKey(
+			executionContext,
+			initializedClient,
+			normalizedName,
+			gamePersister.nameRules.UniquenessKey(normalizedName))
+
+	return isTaken, errorFromCheck
+}
+
 // RemoveGameFromListForPlayer removes the given player from the given game
 // in the sense that the game will no longer show up in the result of
 // ReadAllWithPlayer(playerName). It returns an error if the player is not a
@@ -190,8 +248,9 @@ func (gamePersister *inCloudDatastorePersister) RemoveGameFromListForPlayer(
 		playerName)
 }
 
-// Delete deletes the given game from the collection. It returns an error
-// if the Cloud Datastore API returns an error.
+// Delete deletes the given game, found by its name normalized according to the
+// naming rules, from the collection. It returns an error if the Cloud Datastore
+// API returns an error.
 func (gamePersister *inCloudDatastorePersister) Delete(
 	executionContext context.Context,
 	gameName string) error {
@@ -204,14 +263,17 @@ func (gamePersister *inCloudDatastorePersister) Delete(
 
 	return initializedClient.Delete(
 		executionContext,
-		gameName)
+		gamePersister.nameRules.LookupForm(gameName))
 }
 
 // GetInCloudDatastoreState returns a pointer to an inCloudDatastoreState
-// struct de-serialized from the Google Cloud Datastore with the given name.
+// struct de-serialized from the Google Cloud Datastore with the given name,
+// normalized according to the naming rules as it was when the game was stored.
 func (gamePersister *inCloudDatastorePersister) GetInCloudDatastoreState(
 	executionContext context.Context,
 	gameName string) (*inCloudDatastoreState, error) {
+	storedName := gamePersister.nameRules.LookupForm(gameName)
+
 	initializedClient, errorFromAcquiral :=
 		gamePersister.acquireClient(executionContext)
 
@@ -224,19 +286,71 @@ func (gamePersister *inCloudDatastorePersister) GetInCloudDatastoreState(
 	errorFromGet :=
 		initializedClient.Get(
 			executionContext,
-			gameName,
+			storedName,
 			&serializablePart)
 
 	if errorFromGet != nil {
+		// The client does not expose the error for a missing entity, so we check
+		// separately whether the game exists, but only when the Get has failed.
+		isStored, errorFromCheck :=
+			cloud.DoesNameExist(executionContext, initializedClient, storedName)
+
+		if (errorFromCheck == nil) && !isStored {
+			return nil, game.ErrUnknownGame.Errorf("Game %v does not exist", storedName)
+		}
+
 		return nil, errorFromGet
 	}
 
 	return newInCloudDatastoreState(
 		initializedClient,
-		gameName,
+		storedName,
 		serializablePart)
 }
 
+// nameWithKey returns the name of a game which is stored under the given normalized
+// name or whose name has the given key according to the naming rules, and true, or
+// an empty string and false if there is no such game. Games stored before keys of
+// names were introduced have no key, so they are only found by their exact names.
+func (gamePersister *inCloudDatastorePersister) nameWithKey(
+	executionContext context.Context,
+	initializedClient cloud.LimitedClient,
+	normalizedName string,
+	nameKey string) (string, bool, error) {
+	isAlreadyInDatastore, errorFromCheck :=
+		cloud.DoesNameExist(
+			executionContext,
+			initializedClient,
+			normalizedName)
+
+	if errorFromCheck != nil {
+		return "", false, errorFromCheck
+	}
+
+	if isAlreadyInDatastore {
+		return normalizedName, true, nil
+	}
+
+	gamesWithKey :=
+		initializedClient.AllMatching(
+			executionContext,
+			"GameNameKey =",
+			nameKey)
+
+	var gameWithKey SerializableState
+	errorFromNext := gamesWithKey.DeserializeNext(&gameWithKey)
+
+	if gamesWithKey.IsDone(errorFromNext) {
+		return "", false, nil
+	}
+
+	if errorFromNext != nil {
+		return "", false, errorFromNext
+	}
+
+	return gameWithKey.GameName, true, nil
+}
+
 // acquireClient returns the connection to the Cloud Datastore,
 // initializing it if it has not already been initialized.
 func (gamePersister *inCloudDatastorePersister) acquireClient(
@@ -297,17 +411,18 @@ func (gameState *inCloudDatastoreState) Read() game.ReadonlyState {
 	return gameState
 }
 
-// RecordChatMessage records a chat message from the given player.
+// RecordChatMessage records a chat message from the given player. The message
+// is added to the game as it is currently stored, so that it cannot overwrite a
+// turn taken since this state was read.
 func (gameState *inCloudDatastoreState) RecordChatMessage(
 	executionContext context.Context,
 	actingPlayer player.ReadonlyState,
 	chatMessage string) error {
-	gameState.mutualExclusion.Lock()
-	defer gameState.mutualExclusion.Unlock()
-
-	return gameState.uploadSerializablePartIfNoError(
+	return gameState.updateInTransaction(
 		executionContext,
-		gameState.SerializableState.RecordChatMessage(actingPlayer, chatMessage))
+		func(storedState *DeserializedState) error {
+			return storedState.SerializableState.RecordChatMessage(actingPlayer, chatMessage)
+		})
 }
 
 // EnactTurnByDiscardingAndReplacing increments the turn number and moves the
@@ -318,27 +433,30 @@ func (gameState *inCloudDatastoreState) RecordChatMessage(
 // and any sequence index is possible). If there is no card to draw from the
 // deck, it increments the number of turns taken with an empty deck of
 // replacing the card in the hand. It also adds the given numbers to the
-// counts of available hints and mistakes made respectively.
+// counts of available hints and mistakes made respectively. It returns an
+// error without changing anything if the stored game is no longer at the
+// expected turn.
 func (gameState *inCloudDatastoreState) EnactTurnByDiscardingAndReplacing(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	indexInHand int,
 	knowledgeOfDrawnCard card.Inferred,
 	numberOfReadyHintsToAdd int,
 	numberOfMistakesMadeToAdd int) error {
-	gameState.mutualExclusion.Lock()
-	defer gameState.mutualExclusion.Unlock()
-
-	return gameState.uploadSerializablePartIfNoError(
+	return gameState.enactTurnInTransaction(
 		executionContext,
-		gameState.DeserializedState.EnactTurnByDiscardingAndReplacing(
-			actionMessage,
-			actingPlayer,
-			indexInHand,
-			knowledgeOfDrawnCard,
-			numberOfReadyHintsToAdd,
-			numberOfMistakesMadeToAdd))
+		expectedTurn,
+		func(storedState *DeserializedState) error {
+			return storedState.EnactTurnByDiscardingAndReplacing(
+				actionMessage,
+				actingPlayer,
+				indexInHand,
+				knowledgeOfDrawnCard,
+				numberOfReadyHintsToAdd,
+				numberOfMistakesMadeToAdd)
+		})
 }
 
 // EnactTurnByPlayingAndReplacing increments the turn number and moves the card
@@ -350,49 +468,54 @@ func (gameState *inCloudDatastoreState) EnactTurnByDiscardingAndReplacing(
 // it increments the number of turns taken with an empty deck of replacing the
 // card in the hand. It also adds the given number of hints to the count of ready
 // hints available (such as when playing the end of sequence gives a bonus hint).
+// It returns an error without changing anything if the stored game is no longer
+// at the expected turn.
 func (gameState *inCloudDatastoreState) EnactTurnByPlayingAndReplacing(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	indexInHand int,
 	knowledgeOfDrawnCard card.Inferred,
 	numberOfReadyHintsToAdd int) error {
-	gameState.mutualExclusion.Lock()
-	defer gameState.mutualExclusion.Unlock()
-
-	return gameState.uploadSerializablePartIfNoError(
+	return gameState.enactTurnInTransaction(
 		executionContext,
-		gameState.DeserializedState.EnactTurnByPlayingAndReplacing(
-			actionMessage,
-			actingPlayer,
-			indexInHand,
-			knowledgeOfDrawnCard,
-			numberOfReadyHintsToAdd))
+		expectedTurn,
+		func(storedState *DeserializedState) error {
+			return storedState.EnactTurnByPlayingAndReplacing(
+				actionMessage,
+				actingPlayer,
+				indexInHand,
+				knowledgeOfDrawnCard,
+				numberOfReadyHintsToAdd)
+		})
 }
 
 // EnactTurnByUpdatingHandWithHint increments the turn number and replaces the
 // given player's inferred hand with the given inferred hand, while also
 // decrementing the number of available hints appropriately. If the deck is
 // empty, this function also increments the number of turns taken with an empty
-// deck.
+// deck. It returns an error without changing anything if the stored game is no
+// longer at the expected turn.
 func (gameState *inCloudDatastoreState) EnactTurnByUpdatingHandWithHint(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	receivingPlayerName string,
 	updatedReceiverKnowledgeOfOwnHand []card.Inferred,
 	numberOfReadyHintsToSubtract int) error {
-	gameState.mutualExclusion.Lock()
-	defer gameState.mutualExclusion.Unlock()
-
-	return gameState.uploadSerializablePartIfNoError(
+	return gameState.enactTurnInTransaction(
 		executionContext,
-		gameState.DeserializedState.EnactTurnByUpdatingHandWithHint(
-			actionMessage,
-			actingPlayer,
-			receivingPlayerName,
-			updatedReceiverKnowledgeOfOwnHand,
-			numberOfReadyHintsToSubtract))
+		expectedTurn,
+		func(storedState *DeserializedState) error {
+			return storedState.EnactTurnByUpdatingHandWithHint(
+				actionMessage,
+				actingPlayer,
+				receivingPlayerName,
+				updatedReceiverKnowledgeOfOwnHand,
+				numberOfReadyHintsToSubtract)
+		})
 }
 
 // RemovePlayerFromParticipantList marks the player as no longer being a
@@ -409,14 +532,66 @@ func (gameState *inCloudDatastoreState) RemovePlayerFromParticipantList(
 	return gameState.uploadSerializablePart(executionContext)
 }
 
-func (gameState *inCloudDatastoreState) uploadSerializablePartIfNoError(
+// enactTurnInTransaction enacts the turn with the given function on the game as it
+// is currently stored, if it is at the expected turn, as a single transaction, so
+// that two turns based on the same turn cannot both be written even if they are
+// enacted by different instances of the backend.
+func (gameState *inCloudDatastoreState) enactTurnInTransaction(
+	executionContext context.Context,
+	expectedTurn int,
+	enactTurn func(*DeserializedState) error) error {
+	return gameState.updateInTransaction(
+		executionContext,
+		func(storedState *DeserializedState) error {
+			errorFromTurn := game.ErrorIfTurnIsNot(storedState, expectedTurn)
+			if errorFromTurn != nil {
+				return errorFromTurn
+			}
+
+			return enactTurn(storedState)
+		})
+}
+
+// updateInTransaction reads the game as it is currently stored, updates it with the
+// given function, and writes it back, all in a single transaction. If the transaction
+// succeeds, the local state is replaced by the updated state.
+func (gameState *inCloudDatastoreState) updateInTransaction(
 	executionContext context.Context,
-	errorFromUpdatingSerializablePart error) error {
-	if errorFromUpdatingSerializablePart != nil {
-		return errorFromUpdatingSerializablePart
+	updateState func(*DeserializedState) error) error {
+	gameState.mutualExclusion.Lock()
+	defer gameState.mutualExclusion.Unlock()
+
+	var updatedState DeserializedState
+
+	errorFromTransaction :=
+		gameState.datastoreClient.RunInTransaction(
+			executionContext,
+			func(gameTransaction cloud.LimitedTransaction) error {
+				storedPart := SerializableState{}
+				errorFromGet := gameTransaction.Get(gameState.keyName, &storedPart)
+				if errorFromGet != nil {
+					return errorFromGet
+				}
+
+				// The transaction may be retried, so the state is created afresh from
+				// what is stored every time.
+				updatedState =
+					CreateDeserializedState(storedPart, gameState.deserializedRuleset)
+
+				errorFromUpdate := updateState(&updatedState)
+				if errorFromUpdate != nil {
+					return errorFromUpdate
+				}
+
+				return gameTransaction.Put(gameState.keyName, &updatedState.SerializableState)
+			})
+
+	if errorFromTransaction != nil {
+		return errorFromTransaction
 	}
 
-	return gameState.uploadSerializablePart(executionContext)
+	gameState.DeserializedState = updatedState
+	return nil
 }
 
 func (gameState *inCloudDatastoreState) uploadSerializablePart(
@@ -10,6 +10,7 @@ import (
 	"github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/naming"
 	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
@@ -19,14 +20,23 @@ import (
 type inMemoryPersister struct {
 	mutualExclusion       sync.Mutex
 	randomNumberGenerator *rand.Rand
+	nameRules             naming.Rules
 	gameStates            map[string]*inMemoryState
 }
 
-// NewInMemory creates a game state persister around a map of games.
+// NewInMemory creates a game state persister around a map of games which
+// applies the default naming rules.
 func NewInMemory() game.StatePersister {
+	return NewInMemoryWithNameRules(naming.DefaultRules())
+}
+
+// NewInMemoryWithNameRules creates a game state persister around a map of
+// games which applies the given naming rules.
+func NewInMemoryWithNameRules(nameRules naming.Rules) game.StatePersister {
 	return &inMemoryPersister{
 		mutualExclusion:       sync.Mutex{},
 		randomNumberGenerator: rand.New(rand.NewSource(time.Now().Unix())),
+		nameRules:             nameRules,
 		gameStates:            make(map[string]*inMemoryState, 1),
 	}
 }
@@ -64,13 +74,15 @@ func (gamePersister *inMemoryPersister) ReadAllWithPlayer(
 }
 
 // AddGame adds an element to the collection which is a new object implementing
-// the ReadAndWriteState interface from the given arguments, and returns the
-// identifier of the newly-created game, along with an error which of course is
-// nil if there was no problem. It returns an error if a game with the given name
-// already exists. The context is ignored.
+// the ReadAndWriteState interface from the given arguments, created by the given
+// player. The game is stored under its name normalized according to the naming
+// rules. It returns an error if the name does not follow the naming rules, or if
+// a game with the same name according to the naming rules already exists, and
+// otherwise returns nil. The context is ignored.
 func (gamePersister *inMemoryPersister) AddGame(
 	executionContext context.Context,
 	gameName string,
+	creatorName string,
 	chatLogLength int,
 	initialActionLog []message.FromPlayer,
 	gameRuleset game.Ruleset,
@@ -80,14 +92,25 @@ func (gamePersister *inMemoryPersister) AddGame(
 		return fmt.Errorf("Game must have a name")
 	}
 
-	_, gameExists := gamePersister.gameStates[gameName]
+	normalizedName, errorFromName :=
+		gamePersister.nameRules.Normalize("Game name", gameName)
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	nameKey := gamePersister.nameRules.UniquenessKey(normalizedName)
 
-	if gameExists {
-		return fmt.Errorf("Game %v already exists", gameName)
+	gamePersister.mutualExclusion.Lock()
+	defer gamePersister.mutualExclusion.Unlock()
+
+	existingName, isTaken := gamePersister.nameWithKey(nameKey)
+	if isTaken {
+		return game.ErrNameTaken.Errorf("Game %v already exists", existingName)
 	}
 
 	serializableState :=
-		NewSerializableState(gameName,
+		NewSerializableState(normalizedName,
+			creatorName,
 			chatLogLength,
 			initialActionLog,
 			gameRuleset,
@@ -99,13 +122,32 @@ func (gamePersister *inMemoryPersister) AddGame(
 		DeserializedState: CreateDeserializedState(serializableState, gameRuleset),
 	}
 
-	gamePersister.mutualExclusion.Lock()
-	gamePersister.gameStates[gameName] = newGame
-	gamePersister.mutualExclusion.Unlock()
+	gamePersister.gameStates[normalizedName] = newGame
 
 	return nil
 }
 
+// IsNameTaken returns true if there is already a game with the same name as the
+// given name according to the naming rules, or an error if the given name does
+// not follow the naming rules. The context is ignored.
+func (gamePersister *inMemoryPersister) IsNameTaken(
+	executionContext context.Context,
+	gameName string) (bool, error) {
+	normalizedName, errorFromName :=
+		gamePersister.nameRules.Normalize("Game name", gameName)
+	if errorFromName != nil {
+		return false, errorFromName
+	}
+
+	gamePersister.mutualExclusion.Lock()
+	defer gamePersister.mutualExclusion.Unlock()
+
+	_, isTaken :=
+		gamePersister.nameWithKey(gamePersister.nameRules.UniquenessKey(normalizedName))
+
+	return isTaken, nil
+}
+
 // RemoveGameFromListForPlayer removes the given player from the given game
 // in the sense that the game will no longer show up in the result of
 // ReadAllWithPlayer(playerName). It returns an error if the player is not a
@@ -124,25 +166,27 @@ func (gamePersister *inMemoryPersister) RemoveGameFromListForPlayer(
 	return gameToUpdate.RemovePlayerFromParticipantList(playerName)
 }
 
-// Delete deletes the given game from the collection. It returns no error.
-// The context is ignored.
+// Delete deletes the given game, found by its name normalized according to the
+// naming rules, from the collection. It returns no error. The context is ignored.
 func (gamePersister *inMemoryPersister) Delete(
 	executionContext context.Context,
 	gameName string) error {
 	gamePersister.mutualExclusion.Lock()
-	delete(gamePersister.gameStates, gameName)
+	delete(gamePersister.gameStates, gamePersister.nameRules.LookupForm(gameName))
 	gamePersister.mutualExclusion.Unlock()
 
 	return nil
 }
 
-// GetInMemoryState returns a pointer to an inMemoryState struct with the given name.
+// GetInMemoryState returns a pointer to an inMemoryState struct with the given name,
+// normalized according to the naming rules as it was when the game was stored.
 func (gamePersister *inMemoryPersister) GetInMemoryState(
 	gameName string) (*inMemoryState, error) {
-	gameState, gameExists := gamePersister.gameStates[gameName]
+	gameState, gameExists :=
+		gamePersister.gameStates[gamePersister.nameRules.LookupForm(gameName)]
 
 	if !gameExists {
-		return nil, fmt.Errorf("Game %v does not exist", gameName)
+		return nil, game.ErrUnknownGame.Errorf("Game %v does not exist", gameName)
 	}
 
 	return gameState, nil
@@ -176,10 +220,12 @@ func (gameState *inMemoryState) RecordChatMessage(
 // and any sequence index is possible). If there is no card to draw from the
 // deck, it increments the number of turns taken with an empty deck of
 // replacing the card in the hand. It also adds the given numbers to the
-// counts of available hints and mistakes made respectively. The context is
-// ignored.
+// counts of available hints and mistakes made respectively. It returns an
+// error without changing anything if the game is no longer at the expected
+// turn. The context is ignored.
 func (gameState *inMemoryState) EnactTurnByDiscardingAndReplacing(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	indexInHand int,
@@ -189,6 +235,11 @@ func (gameState *inMemoryState) EnactTurnByDiscardingAndReplacing(
 	gameState.mutualExclusion.Lock()
 	defer gameState.mutualExclusion.Unlock()
 
+	errorFromTurn := game.ErrorIfTurnIsNot(gameState, expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
 	return gameState.DeserializedState.EnactTurnByDiscardingAndReplacing(
 		actionMessage,
 		actingPlayer,
@@ -207,9 +258,11 @@ func (gameState *inMemoryState) EnactTurnByDiscardingAndReplacing(
 // it increments the number of turns taken with an empty deck of replacing the
 // card in the hand. It also adds the given number of hints to the count of ready
 // hints available (such as when playing the end of sequence gives a bonus hint).
-// The context is ignored.
+// It returns an error without changing anything if the game is no longer at the
+// expected turn. The context is ignored.
 func (gameState *inMemoryState) EnactTurnByPlayingAndReplacing(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	indexInHand int,
@@ -218,6 +271,11 @@ func (gameState *inMemoryState) EnactTurnByPlayingAndReplacing(
 	gameState.mutualExclusion.Lock()
 	defer gameState.mutualExclusion.Unlock()
 
+	errorFromTurn := game.ErrorIfTurnIsNot(gameState, expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
 	return gameState.DeserializedState.EnactTurnByPlayingAndReplacing(
 		actionMessage,
 		actingPlayer,
@@ -230,9 +288,11 @@ func (gameState *inMemoryState) EnactTurnByPlayingAndReplacing(
 // given player's inferred hand with the given inferred hand, while also
 // decrementing the number of available hints appropriately. If the deck is
 // empty, this function also increments the number of turns taken with an empty
-// deck. The context is ignored.
+// deck. It returns an error without changing anything if the game is no longer at
+// the expected turn. The context is ignored.
 func (gameState *inMemoryState) EnactTurnByUpdatingHandWithHint(
 	executionContext context.Context,
+	expectedTurn int,
 	actionMessage string,
 	actingPlayer player.ReadonlyState,
 	receivingPlayerName string,
@@ -241,6 +301,11 @@ func (gameState *inMemoryState) EnactTurnByUpdatingHandWithHint(
 	gameState.mutualExclusion.Lock()
 	defer gameState.mutualExclusion.Unlock()
 
+	errorFromTurn := game.ErrorIfTurnIsNot(gameState, expectedTurn)
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
 	return gameState.DeserializedState.EnactTurnByUpdatingHandWithHint(
 		actionMessage,
 		actingPlayer,
@@ -248,3 +313,16 @@ func (gameState *inMemoryState) EnactTurnByUpdatingHandWithHint(
 		updatedReceiverKnowledgeOfOwnHand,
 		numberOfReadyHintsToSubtract)
 }
+
+// nameWithKey returns the name of the game whose name has the given key according
+// to the naming rules, and true, or an empty string and false if there is no such
+// game. The caller must hold the lock.
+func (gamePersister *inMemoryPersister) nameWithKey(nameKey string) (string, bool) {
+	for existingName := range gamePersister.gameStates {
+		if gamePersister.nameRules.UniquenessKey(existingName) == nameKey {
+			return existingName, true
+		}
+	}
+
+	return "", false
+}
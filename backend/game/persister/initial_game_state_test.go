@@ -93,6 +93,13 @@ func TestSetUpInitialMetadataCorrectly(unitTest *testing.T) {
 					1)
 			}
 
+			if readonlyState.Version() != 1 {
+				unitTest.Fatalf(
+					"Version() %v was not expected %v",
+					readonlyState.Version(),
+					1)
+			}
+
 			if readonlyState.NumberOfReadyHints() != defaultTestRuleset.MaximumNumberOfHints() {
 				unitTest.Fatalf(
 					"NumberOfReadyHints() %v was not expected %v",
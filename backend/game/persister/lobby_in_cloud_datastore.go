@@ -0,0 +1,284 @@
+package persister
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/naming"
+)
+
+// CloudDatastoreLobbyKeyKind denotes the kind for the entities which will
+// store lobbies in the Google Cloud Datastore.
+const CloudDatastoreLobbyKeyKind = "Lobby"
+
+// inCloudDatastoreLobbyPersister stores lobbies of games which have not yet
+// been dealt in Google Cloud Datastore, keyed by their game names.
+type inCloudDatastoreLobbyPersister struct {
+	clientProvider  cloud.DatastoreClientProvider
+	datastoreClient cloud.LimitedClient
+	nameRules       naming.Rules
+}
+
+// NewLobbyInCloudDatastore creates a lobby persister which applies the
+// default naming rules.
+func NewLobbyInCloudDatastore(
+	clientProvider cloud.DatastoreClientProvider) game.LobbyPersister {
+	return NewLobbyInCloudDatastoreWithNameRules(clientProvider, naming.DefaultRules())
+}
+
+// NewLobbyInCloudDatastoreWithNameRules creates a lobby persister which
+// applies the given naming rules.
+func NewLobbyInCloudDatastoreWithNameRules(
+	clientProvider cloud.DatastoreClientProvider,
+	nameRules naming.Rules) game.LobbyPersister {
+	return &inCloudDatastoreLobbyPersister{
+		clientProvider:  clientProvider,
+		datastoreClient: nil,
+		nameRules:       nameRules,
+	}
+}
+
+// ReadLobby returns the lobby for the given game name, normalized according to
+// the naming rules, or an error if it does not exist.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) ReadLobby(
+	executionContext context.Context,
+	gameName string) (game.Lobby, error) {
+	initializedClient, errorFromAcquiral :=
+		lobbyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return game.Lobby{}, errorFromAcquiral
+	}
+
+	retrievedLobby := game.Lobby{}
+
+	errorFromGet :=
+		initializedClient.Get(
+			executionContext,
+			lobbyPersister.nameRules.LookupForm(gameName),
+			&retrievedLobby)
+
+	return retrievedLobby, errorFromGet
+}
+
+// ReadAllLobbies returns all the lobbies in the collection, in no particular
+// order.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) ReadAllLobbies(
+	executionContext context.Context) ([]game.Lobby, error) {
+	initializedClient, errorFromAcquiral :=
+		lobbyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return nil, errorFromAcquiral
+	}
+
+	resultIterator := initializedClient.AllOfKind(executionContext)
+
+	allLobbies := []game.Lobby{}
+
+	for {
+		var retrievedLobby game.Lobby
+		errorFromNext := resultIterator.DeserializeNext(&retrievedLobby)
+
+		if resultIterator.IsDone(errorFromNext) {
+			break
+		}
+
+		if errorFromNext != nil {
+			return nil, errorFromNext
+		}
+
+		allLobbies = append(allLobbies, retrievedLobby)
+	}
+
+	return allLobbies, nil
+}
+
+// AddLobby adds the given lobby to the collection keyed by the name of its
+// game normalized according to the naming rules, or returns an error if the
+// name does not follow the naming rules or if there is already a lobby for a
+// game with the same name according to the naming rules. There are only ever a
+// few lobbies waiting for players, so all of them are compared with the new one.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) AddLobby(
+	executionContext context.Context,
+	newLobby game.Lobby) error {
+	if newLobby.GameName == "" {
+		return fmt.Errorf("Game must have a name")
+	}
+
+	normalizedName, errorFromName :=
+		lobbyPersister.nameRules.Normalize("Game name", newLobby.GameName)
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	nameKey := lobbyPersister.nameRules.UniquenessKey(normalizedName)
+
+	initializedClient, errorFromAcquiral :=
+		lobbyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	isAlreadyInDatastore, errorFromCheck :=
+		cloud.DoesNameExist(
+			executionContext,
+			initializedClient,
+			normalizedName)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	if isAlreadyInDatastore {
+		return game.ErrNameTaken.Errorf("Lobby for game %v already exists", normalizedName)
+	}
+
+	existingLobbies, errorFromReadAll := lobbyPersister.ReadAllLobbies(executionContext)
+
+	if errorFromReadAll != nil {
+		return errorFromReadAll
+	}
+
+	for _, existingLobby := range existingLobbies {
+		if lobbyPersister.nameRules.UniquenessKey(existingLobby.GameName) == nameKey {
+			return game.ErrNameTaken.Errorf(
+				"Lobby for game %v already exists",
+				existingLobby.GameName)
+		}
+	}
+
+	newLobby.GameName = normalizedName
+
+	return initializedClient.Put(
+		executionContext,
+		normalizedName,
+		&newLobby)
+}
+
+// AddPlayerToLobby gives the next open seat in the lobby for the given game to
+// the given player, returning an error if the lobby does not exist or if the
+// player could not be seated. The lobby is read and written within a single
+// transaction so that players joining at the same time do not overwrite each
+// other's seats.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) AddPlayerToLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	return lobbyPersister.updateLobbyInTransaction(
+		executionContext,
+		gameName,
+		func(lobbyToUpdate *game.Lobby) error {
+			return lobbyToUpdate.SeatPlayer(playerName)
+		})
+}
+
+// RemovePlayerFromLobby frees the seat of the given player in the lobby for the
+// given game, returning an error if the lobby does not exist or if the player
+// does not have a seat in it. As with AddPlayerToLobby, the lobby is read and
+// written within a single transaction.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) RemovePlayerFromLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	return lobbyPersister.updateLobbyInTransaction(
+		executionContext,
+		gameName,
+		func(lobbyToUpdate *game.Lobby) error {
+			return lobbyToUpdate.UnseatPlayer(playerName)
+		})
+}
+
+// DeleteLobby deletes the lobby for the given game, found by its name normalized
+// according to the naming rules, from the collection. It returns an error if there
+// is no lobby for the game or if the Cloud Datastore API returns an error.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) DeleteLobby(
+	executionContext context.Context,
+	gameName string) error {
+	storedName := lobbyPersister.nameRules.LookupForm(gameName)
+
+	initializedClient, errorFromAcquiral :=
+		lobbyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	isInDatastore, errorFromCheck :=
+		cloud.DoesNameExist(
+			executionContext,
+			initializedClient,
+			storedName)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	if !isInDatastore {
+		return fmt.Errorf("No lobby for game %v exists to delete", storedName)
+	}
+
+	return initializedClient.Delete(
+		executionContext,
+		storedName)
+}
+
+// updateLobbyInTransaction reads the lobby for the given game, found by its name
+// normalized according to the naming rules, applies the given update to it, and
+// writes it back, all within a single transaction, returning an error if the
+// lobby does not exist, if the update fails, or if the Cloud Datastore API
+// returns an error.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) updateLobbyInTransaction(
+	executionContext context.Context,
+	gameName string,
+	updateLobby func(lobbyToUpdate *game.Lobby) error) error {
+	initializedClient, errorFromAcquiral :=
+		lobbyPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	storedName := lobbyPersister.nameRules.LookupForm(gameName)
+
+	return initializedClient.RunInTransaction(
+		executionContext,
+		func(lobbyTransaction cloud.LimitedTransaction) error {
+			// The transaction may be retried, so the lobby is read afresh from
+			// what is stored every time.
+			lobbyToUpdate := game.Lobby{}
+			errorFromGet := lobbyTransaction.Get(storedName, &lobbyToUpdate)
+
+			if errorFromGet != nil {
+				return errorFromGet
+			}
+
+			errorFromUpdate := updateLobby(&lobbyToUpdate)
+
+			if errorFromUpdate != nil {
+				return errorFromUpdate
+			}
+
+			return lobbyTransaction.Put(storedName, &lobbyToUpdate)
+		})
+}
+
+// acquireClient returns the connection to the Cloud Datastore,
+// initializing it if it has not already been initialized.
+func (lobbyPersister *inCloudDatastoreLobbyPersister) acquireClient(
+	executionContext context.Context) (cloud.LimitedClient, error) {
+	if lobbyPersister.datastoreClient == nil {
+		cloudDatastoreClient, errorFromCloudDatastore :=
+			lobbyPersister.clientProvider.NewClient(executionContext)
+		if errorFromCloudDatastore != nil {
+			return nil, errorFromCloudDatastore
+		}
+
+		lobbyPersister.datastoreClient = cloudDatastoreClient
+	}
+
+	return lobbyPersister.datastoreClient, nil
+}
@@ -0,0 +1,200 @@
+package persister
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/naming"
+)
+
+// inMemoryLobbyPersister stores lobbies of games which have not yet been
+// dealt, mapped to by their game names. It ignores all context structs
+// passed to its functions.
+type inMemoryLobbyPersister struct {
+	mutualExclusion sync.Mutex
+	nameRules       naming.Rules
+	lobbies         map[string]game.Lobby
+}
+
+// NewLobbyInMemory creates a lobby persister around a map of lobbies which
+// applies the default naming rules.
+func NewLobbyInMemory() game.LobbyPersister {
+	return NewLobbyInMemoryWithNameRules(naming.DefaultRules())
+}
+
+// NewLobbyInMemoryWithNameRules creates a lobby persister around a map of
+// lobbies which applies the given naming rules.
+func NewLobbyInMemoryWithNameRules(nameRules naming.Rules) game.LobbyPersister {
+	return &inMemoryLobbyPersister{
+		mutualExclusion: sync.Mutex{},
+		nameRules:       nameRules,
+		lobbies:         make(map[string]game.Lobby, 1),
+	}
+}
+
+// ReadLobby returns the lobby for the given game name, or an error if it does
+// not exist. The context is ignored.
+func (lobbyPersister *inMemoryLobbyPersister) ReadLobby(
+	executionContext context.Context,
+	gameName string) (game.Lobby, error) {
+	lobbyPersister.mutualExclusion.Lock()
+	defer lobbyPersister.mutualExclusion.Unlock()
+
+	return lobbyPersister.getLobbyCopy(gameName)
+}
+
+// ReadAllLobbies returns all the lobbies in the collection, in no particular
+// order. The context is ignored.
+func (lobbyPersister *inMemoryLobbyPersister) ReadAllLobbies(
+	executionContext context.Context) ([]game.Lobby, error) {
+	lobbyPersister.mutualExclusion.Lock()
+	defer lobbyPersister.mutualExclusion.Unlock()
+
+	allLobbies := make([]game.Lobby, 0, len(lobbyPersister.lobbies))
+
+	for gameName := range lobbyPersister.lobbies {
+		lobbyCopy, _ := lobbyPersister.getLobbyCopy(gameName)
+		allLobbies = append(allLobbies, lobbyCopy)
+	}
+
+	return allLobbies, nil
+}
+
+// AddLobby adds the given lobby to the collection under the name of its game
+// normalized according to the naming rules, or returns an error if the name
+// does not follow the naming rules or if there is already a lobby for a game
+// with the same name according to the naming rules. The context is ignored.
+func (lobbyPersister *inMemoryLobbyPersister) AddLobby(
+	executionContext context.Context,
+	newLobby game.Lobby) error {
+	if newLobby.GameName == "" {
+		return fmt.Errorf("Game must have a name")
+	}
+
+	normalizedName, errorFromName :=
+		lobbyPersister.nameRules.Normalize("Game name", newLobby.GameName)
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	nameKey := lobbyPersister.nameRules.UniquenessKey(normalizedName)
+
+	lobbyPersister.mutualExclusion.Lock()
+	defer lobbyPersister.mutualExclusion.Unlock()
+
+	for existingName := range lobbyPersister.lobbies {
+		if lobbyPersister.nameRules.UniquenessKey(existingName) == nameKey {
+			return game.ErrNameTaken.Errorf("Lobby for game %v already exists", existingName)
+		}
+	}
+
+	newLobby.GameName = normalizedName
+
+	lobbyPersister.lobbies[newLobby.GameName] = copyOfLobby(newLobby)
+
+	return nil
+}
+
+// AddPlayerToLobby gives the next open seat in the lobby for the given game to
+// the given player, returning an error if the lobby does not exist or if the
+// player could not be seated. The context is ignored.
+func (lobbyPersister *inMemoryLobbyPersister) AddPlayerToLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	lobbyPersister.mutualExclusion.Lock()
+	defer lobbyPersister.mutualExclusion.Unlock()
+
+	lobbyToUpdate, errorFromGet := lobbyPersister.getLobbyCopy(gameName)
+
+	if errorFromGet != nil {
+		return errorFromGet
+	}
+
+	errorFromSeating := lobbyToUpdate.SeatPlayer(playerName)
+
+	if errorFromSeating != nil {
+		return errorFromSeating
+	}
+
+	lobbyPersister.lobbies[lobbyToUpdate.GameName] = lobbyToUpdate
+
+	return nil
+}
+
+// RemovePlayerFromLobby frees the seat of the given player in the lobby for the
+// given game, returning an error if the lobby does not exist or if the player
+// does not have a seat in it. The context is ignored.
+func (lobbyPersister *inMemoryLobbyPersister) RemovePlayerFromLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	lobbyPersister.mutualExclusion.Lock()
+	defer lobbyPersister.mutualExclusion.Unlock()
+
+	lobbyToUpdate, errorFromGet := lobbyPersister.getLobbyCopy(gameName)
+
+	if errorFromGet != nil {
+		return errorFromGet
+	}
+
+	errorFromUnseating := lobbyToUpdate.UnseatPlayer(playerName)
+
+	if errorFromUnseating != nil {
+		return errorFromUnseating
+	}
+
+	lobbyPersister.lobbies[lobbyToUpdate.GameName] = lobbyToUpdate
+
+	return nil
+}
+
+// DeleteLobby deletes the lobby for the given game, found by its name normalized
+// according to the naming rules, from the collection, or returns an error if it
+// does not exist. The context is ignored.
+func (lobbyPersister *inMemoryLobbyPersister) DeleteLobby(
+	executionContext context.Context,
+	gameName string) error {
+	lobbyPersister.mutualExclusion.Lock()
+	defer lobbyPersister.mutualExclusion.Unlock()
+
+	storedName := lobbyPersister.nameRules.LookupForm(gameName)
+	_, lobbyExists := lobbyPersister.lobbies[storedName]
+
+	if !lobbyExists {
+		return fmt.Errorf("No lobby for game %v exists to delete", storedName)
+	}
+
+	delete(lobbyPersister.lobbies, storedName)
+
+	return nil
+}
+
+// getLobbyCopy returns a copy of the lobby for the given game, found by its name
+// normalized according to the naming rules, which does not share its slice of
+// seated players with the stored lobby, so that the caller can modify the copy
+// freely. It assumes that the caller holds the lock.
+func (lobbyPersister *inMemoryLobbyPersister) getLobbyCopy(
+	gameName string) (game.Lobby, error) {
+	storedLobby, lobbyExists :=
+		lobbyPersister.lobbies[lobbyPersister.nameRules.LookupForm(gameName)]
+
+	if !lobbyExists {
+		return game.Lobby{}, fmt.Errorf("No lobby for game %v exists", gameName)
+	}
+
+	return copyOfLobby(storedLobby), nil
+}
+
+// copyOfLobby returns a copy of the given lobby with its own slice of seated
+// players.
+func copyOfLobby(originalLobby game.Lobby) game.Lobby {
+	lobbyCopy := originalLobby
+	lobbyCopy.SeatedPlayerNames =
+		make([]string, len(originalLobby.SeatedPlayerNames))
+	copy(lobbyCopy.SeatedPlayerNames, originalLobby.SeatedPlayerNames)
+
+	return lobbyCopy
+}
@@ -0,0 +1,283 @@
+package persister_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/persister"
+)
+
+var lobbyTestGameNames = []string{
+	testGameNamePrefix + "first lobby",
+	testGameNamePrefix + "second lobby",
+	testGameNamePrefix + "Caf\u00e9 lobby",
+}
+
+type lobbyPersisterAndDescription struct {
+	LobbyPersister       game.LobbyPersister
+	PersisterDescription string
+}
+
+func prepareLobbyPersisters(unitTest *testing.T) []lobbyPersisterAndDescription {
+	lobbyDatastoreClientProvider :=
+		cloud.NewIlutulestikudDatastoreClientProvider(persister.CloudDatastoreLobbyKeyKind)
+
+	persistersAndDescriptions := []lobbyPersisterAndDescription{
+		lobbyPersisterAndDescription{
+			LobbyPersister:       persister.NewLobbyInMemory(),
+			PersisterDescription: "in-memory persister",
+		},
+		lobbyPersisterAndDescription{
+			LobbyPersister:       persister.NewLobbyInCloudDatastore(lobbyDatastoreClientProvider),
+			PersisterDescription: "in-Cloud-Datastore persister",
+		},
+	}
+
+	for _, lobbyPersister := range persistersAndDescriptions {
+		for _, gameName := range lobbyTestGameNames {
+			errorFromDeletionOfExisting :=
+				lobbyPersister.LobbyPersister.DeleteLobby(context.Background(), gameName)
+			unitTest.Logf(
+				"Error from persister %v deleting lobby %v when setting up"+
+					" (to ensure that it does not exist before the test) was %v",
+				lobbyPersister.PersisterDescription,
+				gameName,
+				errorFromDeletionOfExisting)
+		}
+	}
+
+	return persistersAndDescriptions
+}
+
+func TestReturnErrorWhenLobbyDoesNotExist(unitTest *testing.T) {
+	for _, lobbyPersister := range prepareLobbyPersisters(unitTest) {
+		testIdentifier := "ReadLobby(unknown lobby)/" + lobbyPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			readLobby, errorFromRead :=
+				lobbyPersister.LobbyPersister.ReadLobby(
+					context.Background(),
+					lobbyTestGameNames[0])
+
+			if errorFromRead == nil {
+				unitTest.Fatalf(
+					"ReadLobby(%v) did not produce expected error, instead produced %v",
+					lobbyTestGameNames[0],
+					readLobby)
+			}
+
+			errorFromJoin :=
+				lobbyPersister.LobbyPersister.AddPlayerToLobby(
+					context.Background(),
+					lobbyTestGameNames[0],
+					defaultTestPlayers[0])
+
+			if errorFromJoin == nil {
+				unitTest.Fatalf(
+					"AddPlayerToLobby(%v, %v) did not produce expected error",
+					lobbyTestGameNames[0],
+					defaultTestPlayers[0])
+			}
+
+			errorFromDelete :=
+				lobbyPersister.LobbyPersister.DeleteLobby(
+					context.Background(),
+					lobbyTestGameNames[0])
+
+			if errorFromDelete == nil {
+				unitTest.Fatalf(
+					"DeleteLobby(%v) did not produce expected error",
+					lobbyTestGameNames[0])
+			}
+		})
+	}
+}
+
+func TestAddLobbiesThenSeatAndUnseatPlayersThenDeleteLobbies(unitTest *testing.T) {
+	for _, lobbyPersister := range prepareLobbyPersisters(unitTest) {
+		testIdentifier := "Lobby lifecycle/" + lobbyPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			testPersister := lobbyPersister.LobbyPersister
+
+			for _, gameName := range lobbyTestGameNames {
+				errorFromAdd :=
+					testPersister.AddLobby(
+						executionContext,
+						game.NewLobby(gameName, defaultTestRuleset, 3, defaultTestPlayers[0]))
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf("AddLobby(%v) produced unexpected error %v", gameName, errorFromAdd)
+				}
+			}
+
+			errorFromDuplicateAdd :=
+				testPersister.AddLobby(
+					executionContext,
+					game.NewLobby(lobbyTestGameNames[0], defaultTestRuleset, 2, defaultTestPlayers[1]))
+
+			if errorFromDuplicateAdd == nil {
+				unitTest.Fatalf("AddLobby(%v) again did not produce expected error", lobbyTestGameNames[0])
+			}
+
+			errorFromJoin :=
+				testPersister.AddPlayerToLobby(
+					executionContext,
+					lobbyTestGameNames[0],
+					defaultTestPlayers[1])
+
+			if errorFromJoin != nil {
+				unitTest.Fatalf("AddPlayerToLobby(...) produced unexpected error %v", errorFromJoin)
+			}
+
+			errorFromRepeatedJoin :=
+				testPersister.AddPlayerToLobby(
+					executionContext,
+					lobbyTestGameNames[0],
+					defaultTestPlayers[1])
+
+			if errorFromRepeatedJoin == nil {
+				unitTest.Fatalf("AddPlayerToLobby(...) for seated player did not produce expected error")
+			}
+
+			errorFromLeave :=
+				testPersister.RemovePlayerFromLobby(
+					executionContext,
+					lobbyTestGameNames[1],
+					defaultTestPlayers[0])
+
+			if errorFromLeave != nil {
+				unitTest.Fatalf("RemovePlayerFromLobby(...) produced unexpected error %v", errorFromLeave)
+			}
+
+			firstLobby, errorFromFirstRead :=
+				testPersister.ReadLobby(executionContext, lobbyTestGameNames[0])
+
+			if errorFromFirstRead != nil {
+				unitTest.Fatalf("ReadLobby(...) produced unexpected error %v", errorFromFirstRead)
+			}
+
+			assertStringSlicesMatch(
+				testIdentifier+"/first lobby",
+				unitTest,
+				[]string{defaultTestPlayers[0], defaultTestPlayers[1]},
+				firstLobby.SeatedPlayerNames)
+
+			secondLobby, errorFromSecondRead :=
+				testPersister.ReadLobby(executionContext, lobbyTestGameNames[1])
+
+			if errorFromSecondRead != nil {
+				unitTest.Fatalf("ReadLobby(...) produced unexpected error %v", errorFromSecondRead)
+			}
+
+			if len(secondLobby.SeatedPlayerNames) != 0 {
+				unitTest.Fatalf(
+					"Second lobby had seated players %v, expected none",
+					secondLobby.SeatedPlayerNames)
+			}
+
+			for _, gameName := range lobbyTestGameNames {
+				errorFromDelete := testPersister.DeleteLobby(executionContext, gameName)
+
+				if errorFromDelete != nil {
+					unitTest.Fatalf("DeleteLobby(%v) produced unexpected error %v", gameName, errorFromDelete)
+				}
+			}
+
+			allLobbies, errorFromReadAll := testPersister.ReadAllLobbies(executionContext)
+
+			if errorFromReadAll != nil {
+				unitTest.Fatalf("ReadAllLobbies() produced unexpected error %v", errorFromReadAll)
+			}
+
+			for _, remainingLobby := range allLobbies {
+				for _, gameName := range lobbyTestGameNames {
+					if remainingLobby.GameName == gameName {
+						unitTest.Fatalf("Lobby %v still found after deletion", gameName)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindLobbyByNameAsGivenWhenAdded(unitTest *testing.T) {
+	givenName := " " + testGameNamePrefix + "Cafe\u0301 lobby"
+	normalizedName := lobbyTestGameNames[2]
+
+	for _, lobbyPersister := range prepareLobbyPersisters(unitTest) {
+		testIdentifier := "Find lobby by name as given/" + lobbyPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			testPersister := lobbyPersister.LobbyPersister
+
+			errorFromAdd :=
+				testPersister.AddLobby(
+					executionContext,
+					game.NewLobby(givenName, defaultTestRuleset, 3, defaultTestPlayers[0]))
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddLobby(%q) produced unexpected error %v", givenName, errorFromAdd)
+			}
+
+			errorFromJoin :=
+				testPersister.AddPlayerToLobby(executionContext, givenName, defaultTestPlayers[1])
+
+			if errorFromJoin != nil {
+				unitTest.Fatalf(
+					"AddPlayerToLobby(%q, ...) produced unexpected error %v",
+					givenName,
+					errorFromJoin)
+			}
+
+			errorFromLeave :=
+				testPersister.RemovePlayerFromLobby(executionContext, givenName, defaultTestPlayers[0])
+
+			if errorFromLeave != nil {
+				unitTest.Fatalf(
+					"RemovePlayerFromLobby(%q, ...) produced unexpected error %v",
+					givenName,
+					errorFromLeave)
+			}
+
+			foundLobby, errorFromRead := testPersister.ReadLobby(executionContext, givenName)
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("ReadLobby(%q) produced unexpected error %v", givenName, errorFromRead)
+			}
+
+			if foundLobby.GameName != normalizedName {
+				unitTest.Fatalf(
+					"ReadLobby(%q) found lobby for %q, expected %q",
+					givenName,
+					foundLobby.GameName,
+					normalizedName)
+			}
+
+			assertStringSlicesMatch(
+				testIdentifier+"/seated players",
+				unitTest,
+				[]string{defaultTestPlayers[1]},
+				foundLobby.SeatedPlayerNames)
+
+			errorFromDelete := testPersister.DeleteLobby(executionContext, givenName)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf("DeleteLobby(%q) produced unexpected error %v", givenName, errorFromDelete)
+			}
+
+			_, errorFromReadDeleted := testPersister.ReadLobby(executionContext, normalizedName)
+
+			if errorFromReadDeleted == nil {
+				unitTest.Fatalf(
+					"ReadLobby(%q) after DeleteLobby(%q) did not produce expected error",
+					normalizedName,
+					givenName)
+			}
+		})
+	}
+}
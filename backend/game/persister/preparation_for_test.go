@@ -10,6 +10,7 @@ import (
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
 	"github.com/benoleary/ilutulestikud/backend/game/persister"
+	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
 const testGameNamePrefix = "TOUGH_NO_GAME_CAN_HAVE_A_NAME_WHICH_STARTS_LIKE_THIS:"
@@ -29,6 +30,10 @@ type mockPlayerState struct {
 	mockColor string
 }
 
+func (mockState *mockPlayerState) Identifier() string {
+	return mockState.mockName
+}
+
 func (mockState *mockPlayerState) Name() string {
 	return mockState.mockName
 }
@@ -37,6 +42,18 @@ func (mockState *mockPlayerState) Color() string {
 	return mockState.mockColor
 }
 
+func (mockState *mockPlayerState) Role() string {
+	return "player"
+}
+
+func (mockState *mockPlayerState) Profile() player.Profile {
+	return player.Profile{}
+}
+
+func (mockState *mockPlayerState) Contacts() player.Contacts {
+	return player.Contacts{}
+}
+
 var defaultTestPlayers []string = []string{
 	"Player One",
 	"Player Two",
@@ -317,6 +334,7 @@ func prepareGameStates(
 			statePersister.GamePersister.AddGame(
 				context.Background(),
 				singleInteractionTestGameName,
+				playersInTurnOrderWithInitialHands[0].PlayerName,
 				logLengthForTest,
 				initialActionLog,
 				gameRuleset,
@@ -353,6 +371,7 @@ type expectedState struct {
 	CreationTime            time.Time
 	ChatLog                 []message.FromPlayer
 	ActionLog               []message.FromPlayer
+	Version                 int
 	Turn                    int
 	TurnsTakenWithEmptyDeck int
 	Score                   int
@@ -427,6 +446,7 @@ func prepareExpected(
 		ActionLog:    copyLog(unitTest, pristineState.ActionLog()),
 		Turn:         pristineState.Turn(),
 		TurnsTakenWithEmptyDeck: pristineState.TurnsTakenWithEmptyDeck(),
+		Version:                 pristineState.Version(),
 		NumberOfReadyHints:      pristineState.NumberOfReadyHints(),
 		NumberOfMistakesMade:    pristineState.NumberOfMistakesMade(),
 		DeckSize:                pristineState.DeckSize(),
@@ -28,19 +28,31 @@ type InferredCardFromFlattenedIndices struct {
 // order in which they were discarded, the played cards are simply the cards
 // in the order in which they were played, and player hands are the hands of
 // the players in the same order as the players appear in the list of
-// participant names in turn order.
+// participant names in turn order, as are the numbers of discards and
+// mistakes made by each player. The key of the name is the form of the
+// name which persisters may store to ensure that names are unique according
+// to their naming rules, and it is empty for games stored before it was
+// introduced, and similarly the numbers of discards and mistakes made by
+// each player are empty for games stored before they were introduced. The
+// version starts at 1 and is incremented by every turn and chat message, and
+// it is 0 for games stored before it was introduced until they next change.
 type SerializableState struct {
 	GameName                          string
+	GameNameKey                       string
+	NameOfCreator                     string
 	RulesetIdentifier                 int
 	TimeOfCreation                    time.Time
 	ParticipantNamesInTurnOrder       []string
 	ParticipantsWhoHaveLeft           []string
 	ChatMessageLog                    []message.FromPlayer
 	ActionMessageLog                  []message.FromPlayer
+	StateVersion                      int
 	TurnNumber                        int
 	NumberOfTurnsTakenWithEmptyDeck   int
 	NumberOfHintsAvailable            int
 	NumberOfMistakesMadeSoFar         int
+	NumberOfDiscardsInTurnOrder       []int
+	NumberOfMistakesInTurnOrder       []int
 	UndrawnDeck                       []card.Defined
 	PlayedCards                       []card.Defined
 	DiscardedCards                    []card.Defined
@@ -55,6 +67,7 @@ type SerializableState struct {
 // given shuffled deck.
 func NewSerializableState(
 	gameName string,
+	creatorName string,
 	chatLogLength int,
 	initialActionLog []message.FromPlayer,
 	gameRuleset game.Ruleset,
@@ -118,16 +131,20 @@ func NewSerializableState(
 	// the ruleset and counting, but that is a lot of effort for very little gain.
 	return SerializableState{
 		GameName:                          gameName,
+		NameOfCreator:                     creatorName,
 		RulesetIdentifier:                 gameRuleset.BackendIdentifier(),
 		TimeOfCreation:                    time.Now(),
 		ParticipantNamesInTurnOrder:       participantNamesInTurnOrder,
 		ParticipantsWhoHaveLeft:           []string{},
 		ChatMessageLog:                    initialChatLog,
 		ActionMessageLog:                  initialActionLog,
+		StateVersion:                      1,
 		TurnNumber:                        1,
 		NumberOfTurnsTakenWithEmptyDeck:   0,
 		NumberOfHintsAvailable:            gameRuleset.MaximumNumberOfHints(),
 		NumberOfMistakesMadeSoFar:         0,
+		NumberOfDiscardsInTurnOrder:       make([]int, numberOfParticipants),
+		NumberOfMistakesInTurnOrder:       make([]int, numberOfParticipants),
 		UndrawnDeck:                       shuffledDeck,
 		PlayedCards:                       []card.Defined{},
 		DiscardedCards:                    []card.Defined{},
@@ -149,6 +166,18 @@ func (serializableState *SerializableState) PlayerNames() []string {
 	return serializableState.ParticipantNamesInTurnOrder
 }
 
+// CreatorName returns the name of the player who created the game. Games stored
+// before creators were recorded have no creator name, so the first player in their
+// turn order is returned for them, as that player was taken to be the creator then.
+func (serializableState *SerializableState) CreatorName() string {
+	if (serializableState.NameOfCreator == "") &&
+		(len(serializableState.ParticipantNamesInTurnOrder) > 0) {
+		return serializableState.ParticipantNamesInTurnOrder[0]
+	}
+
+	return serializableState.NameOfCreator
+}
+
 // CreationTime returns the value of the private time object describing the time at
 // which the state was created.
 func (serializableState *SerializableState) CreationTime() time.Time {
@@ -165,6 +194,11 @@ func (serializableState *SerializableState) ActionLog() []message.FromPlayer {
 	return serializableState.ActionMessageLog
 }
 
+// Version returns the number which is incremented by every change to the state.
+func (serializableState *SerializableState) Version() int {
+	return serializableState.StateVersion
+}
+
 // Turn returns the value of the private turnNumber int.
 func (serializableState *SerializableState) Turn() int {
 	return serializableState.TurnNumber
@@ -188,6 +222,24 @@ func (serializableState *SerializableState) NumberOfMistakesMade() int {
 	return serializableState.NumberOfMistakesMadeSoFar
 }
 
+// NumberOfDiscardsByPlayer returns the number of cards which the given player has
+// discarded deliberately, not counting cards played incorrectly.
+func (serializableState *SerializableState) NumberOfDiscardsByPlayer(
+	playerName string) int {
+	return serializableState.countForPlayer(
+		serializableState.NumberOfDiscardsInTurnOrder,
+		playerName)
+}
+
+// NumberOfMistakesByPlayer returns the number of cards which the given player has
+// played incorrectly.
+func (serializableState *SerializableState) NumberOfMistakesByPlayer(
+	playerName string) int {
+	return serializableState.countForPlayer(
+		serializableState.NumberOfMistakesInTurnOrder,
+		playerName)
+}
+
 // DeckSize returns the number of cards left to draw from the deck.
 func (serializableState *SerializableState) DeckSize() int {
 	return len(serializableState.UndrawnDeck)
@@ -202,6 +254,7 @@ func (serializableState *SerializableState) RecordChatMessage(
 		actingPlayer.Name(),
 		actingPlayer.Color(),
 		chatMessage)
+	serializableState.StateVersion++
 	return nil
 }
 
@@ -268,12 +321,50 @@ func (serializableState *SerializableState) RemovePlayerFromParticipantList(
 func (serializableState *SerializableState) incrementTurnNumbers(
 	deckAlreadyEmptyAtStartOfTurn bool) {
 	serializableState.TurnNumber++
+	serializableState.StateVersion++
 
 	if deckAlreadyEmptyAtStartOfTurn {
 		serializableState.NumberOfTurnsTakenWithEmptyDeck++
 	}
 }
 
+// countForPlayer returns the element of the given counts at the index of the given
+// player in the turn order, or 0 if there is no such element, as is the case for
+// games stored before the counts were introduced.
+func (serializableState *SerializableState) countForPlayer(
+	countsInTurnOrder []int,
+	playerName string) int {
+	for playerIndex, participantName := range serializableState.ParticipantNamesInTurnOrder {
+		if (participantName == playerName) && (playerIndex < len(countsInTurnOrder)) {
+			return countsInTurnOrder[playerIndex]
+		}
+	}
+
+	return 0
+}
+
+// addToCountForPlayer returns the given counts with the given amount added to the
+// element at the index of the given player in the turn order, first extending the
+// counts to cover every player if necessary.
+func (serializableState *SerializableState) addToCountForPlayer(
+	countsInTurnOrder []int,
+	playerName string,
+	amountToAdd int) []int {
+	numberOfParticipants := len(serializableState.ParticipantNamesInTurnOrder)
+
+	for len(countsInTurnOrder) < numberOfParticipants {
+		countsInTurnOrder = append(countsInTurnOrder, 0)
+	}
+
+	for playerIndex, participantName := range serializableState.ParticipantNamesInTurnOrder {
+		if participantName == playerName {
+			countsInTurnOrder[playerIndex] += amountToAdd
+		}
+	}
+
+	return countsInTurnOrder
+}
+
 func (serializableState *SerializableState) recordActionMessage(
 	actingPlayer player.ReadonlyState,
 	actionMessage string) {
@@ -0,0 +1,122 @@
+package persister
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+// CloudDatastoreSeriesKeyKind denotes the kind for the entities which will
+// store series of games in the Google Cloud Datastore.
+const CloudDatastoreSeriesKeyKind = "Series"
+
+// inCloudDatastoreSeriesPersister stores series of games in Google Cloud
+// Datastore, keyed by the names of the series.
+type inCloudDatastoreSeriesPersister struct {
+	clientProvider  cloud.DatastoreClientProvider
+	datastoreClient cloud.LimitedClient
+}
+
+// NewSeriesInCloudDatastore creates a series persister.
+func NewSeriesInCloudDatastore(
+	clientProvider cloud.DatastoreClientProvider) game.SeriesPersister {
+	return &inCloudDatastoreSeriesPersister{
+		clientProvider:  clientProvider,
+		datastoreClient: nil,
+	}
+}
+
+// ReadSeriesContainingGame returns the series which contains the given game
+// along with true, or an empty series along with false if the game is not
+// part of any series.
+func (seriesPersister *inCloudDatastoreSeriesPersister) ReadSeriesContainingGame(
+	executionContext context.Context,
+	gameName string) (game.Series, bool, error) {
+	initializedClient, errorFromAcquiral :=
+		seriesPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return game.Series{}, false, errorFromAcquiral
+	}
+
+	// As for games, the equality filter on an array property selects the
+	// entity if any of the elements match the sought value.
+	resultIterator :=
+		initializedClient.AllMatching(
+			executionContext,
+			"GameNamesInOrder =",
+			gameName)
+
+	var matchedSeries game.Series
+	errorFromNext := resultIterator.DeserializeNext(&matchedSeries)
+
+	if resultIterator.IsDone(errorFromNext) {
+		return game.Series{}, false, nil
+	}
+
+	if errorFromNext != nil {
+		return game.Series{}, false, errorFromNext
+	}
+
+	return matchedSeries, true, nil
+}
+
+// AppendGameToSeries adds the new game to the end of the series which has the
+// previous game as its last game, creating a new series if the previous game is
+// not yet part of any series.
+func (seriesPersister *inCloudDatastoreSeriesPersister) AppendGameToSeries(
+	executionContext context.Context,
+	previousGameName string,
+	newGameName string) error {
+	_, newGameIsInSeries, errorFromNewGame :=
+		seriesPersister.ReadSeriesContainingGame(executionContext, newGameName)
+
+	if errorFromNewGame != nil {
+		return errorFromNewGame
+	}
+
+	if newGameIsInSeries {
+		return fmt.Errorf("Game %v is already part of a series", newGameName)
+	}
+
+	seriesToUpdate, previousGameIsInSeries, errorFromPreviousGame :=
+		seriesPersister.ReadSeriesContainingGame(executionContext, previousGameName)
+
+	if errorFromPreviousGame != nil {
+		return errorFromPreviousGame
+	}
+
+	if !previousGameIsInSeries {
+		seriesToUpdate = game.NewSeries([]string{previousGameName})
+	}
+
+	errorFromAppend := seriesToUpdate.AppendGame(previousGameName, newGameName)
+
+	if errorFromAppend != nil {
+		return errorFromAppend
+	}
+
+	return seriesPersister.datastoreClient.Put(
+		executionContext,
+		seriesToUpdate.SeriesName,
+		&seriesToUpdate)
+}
+
+// acquireClient returns the connection to the Cloud Datastore,
+// initializing it if it has not already been initialized.
+func (seriesPersister *inCloudDatastoreSeriesPersister) acquireClient(
+	executionContext context.Context) (cloud.LimitedClient, error) {
+	if seriesPersister.datastoreClient == nil {
+		cloudDatastoreClient, errorFromCloudDatastore :=
+			seriesPersister.clientProvider.NewClient(executionContext)
+		if errorFromCloudDatastore != nil {
+			return nil, errorFromCloudDatastore
+		}
+
+		seriesPersister.datastoreClient = cloudDatastoreClient
+	}
+
+	return seriesPersister.datastoreClient, nil
+}
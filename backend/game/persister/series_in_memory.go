@@ -0,0 +1,92 @@
+package persister
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+// inMemorySeriesPersister stores series of games mapped to by their names,
+// along with a map from the name of each game in a series to the name of
+// the series. It ignores all context structs passed to its functions.
+type inMemorySeriesPersister struct {
+	mutualExclusion    sync.Mutex
+	seriesByName       map[string]game.Series
+	seriesNameForGames map[string]string
+}
+
+// NewSeriesInMemory creates a series persister around a map of series.
+func NewSeriesInMemory() game.SeriesPersister {
+	return &inMemorySeriesPersister{
+		mutualExclusion:    sync.Mutex{},
+		seriesByName:       make(map[string]game.Series, 1),
+		seriesNameForGames: make(map[string]string, 1),
+	}
+}
+
+// ReadSeriesContainingGame returns the series which contains the given game
+// along with true, or an empty series along with false if the game is not
+// part of any series. The context is ignored.
+func (seriesPersister *inMemorySeriesPersister) ReadSeriesContainingGame(
+	executionContext context.Context,
+	gameName string) (game.Series, bool, error) {
+	seriesPersister.mutualExclusion.Lock()
+	defer seriesPersister.mutualExclusion.Unlock()
+
+	seriesName, isInSeries := seriesPersister.seriesNameForGames[gameName]
+
+	if !isInSeries {
+		return game.Series{}, false, nil
+	}
+
+	storedSeries := seriesPersister.seriesByName[seriesName]
+	seriesCopy := storedSeries
+	seriesCopy.GameNamesInOrder = make([]string, len(storedSeries.GameNamesInOrder))
+	copy(seriesCopy.GameNamesInOrder, storedSeries.GameNamesInOrder)
+
+	return seriesCopy, true, nil
+}
+
+// AppendGameToSeries adds the new game to the end of the series which has the
+// previous game as its last game, creating a new series if the previous game is
+// not yet part of any series. The context is ignored.
+func (seriesPersister *inMemorySeriesPersister) AppendGameToSeries(
+	executionContext context.Context,
+	previousGameName string,
+	newGameName string) error {
+	seriesPersister.mutualExclusion.Lock()
+	defer seriesPersister.mutualExclusion.Unlock()
+
+	existingSeriesName, newGameIsInSeries :=
+		seriesPersister.seriesNameForGames[newGameName]
+
+	if newGameIsInSeries {
+		return fmt.Errorf(
+			"Game %v is already part of series %v",
+			newGameName,
+			existingSeriesName)
+	}
+
+	seriesName, previousGameIsInSeries :=
+		seriesPersister.seriesNameForGames[previousGameName]
+
+	seriesToUpdate := game.NewSeries([]string{previousGameName})
+
+	if previousGameIsInSeries {
+		seriesToUpdate = seriesPersister.seriesByName[seriesName]
+	}
+
+	errorFromAppend := seriesToUpdate.AppendGame(previousGameName, newGameName)
+
+	if errorFromAppend != nil {
+		return errorFromAppend
+	}
+
+	seriesPersister.seriesByName[seriesToUpdate.SeriesName] = seriesToUpdate
+	seriesPersister.seriesNameForGames[previousGameName] = seriesToUpdate.SeriesName
+	seriesPersister.seriesNameForGames[newGameName] = seriesToUpdate.SeriesName
+
+	return nil
+}
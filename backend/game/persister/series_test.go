@@ -0,0 +1,105 @@
+package persister_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/persister"
+)
+
+type seriesPersisterAndDescription struct {
+	SeriesPersister      game.SeriesPersister
+	PersisterDescription string
+}
+
+func prepareSeriesPersisters() []seriesPersisterAndDescription {
+	seriesDatastoreClientProvider :=
+		cloud.NewIlutulestikudDatastoreClientProvider(persister.CloudDatastoreSeriesKeyKind)
+
+	return []seriesPersisterAndDescription{
+		seriesPersisterAndDescription{
+			SeriesPersister:      persister.NewSeriesInMemory(),
+			PersisterDescription: "in-memory persister",
+		},
+		seriesPersisterAndDescription{
+			SeriesPersister:      persister.NewSeriesInCloudDatastore(seriesDatastoreClientProvider),
+			PersisterDescription: "in-Cloud-Datastore persister",
+		},
+	}
+}
+
+func TestAppendGamesToSeries(unitTest *testing.T) {
+	firstGame := testGameNamePrefix + "first game in series"
+	secondGame := testGameNamePrefix + "second game in series"
+	thirdGame := testGameNamePrefix + "third game in series"
+	unrelatedGame := testGameNamePrefix + "game not in series"
+
+	for _, seriesPersister := range prepareSeriesPersisters() {
+		testIdentifier := "Series of games/" + seriesPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			testPersister := seriesPersister.SeriesPersister
+
+			_, isInSeries, errorFromRead :=
+				testPersister.ReadSeriesContainingGame(executionContext, unrelatedGame)
+
+			if errorFromRead != nil || isInSeries {
+				unitTest.Fatalf(
+					"ReadSeriesContainingGame(%v) gave %v, %v, expected not in series",
+					unrelatedGame,
+					isInSeries,
+					errorFromRead)
+			}
+
+			errorFromFirstAppend :=
+				testPersister.AppendGameToSeries(executionContext, firstGame, secondGame)
+
+			if errorFromFirstAppend != nil {
+				unitTest.Fatalf("AppendGameToSeries(...) produced unexpected error %v", errorFromFirstAppend)
+			}
+
+			errorFromBranchingAppend :=
+				testPersister.AppendGameToSeries(executionContext, firstGame, thirdGame)
+
+			if errorFromBranchingAppend == nil {
+				unitTest.Fatalf("AppendGameToSeries(...) after non-last game did not produce expected error")
+			}
+
+			errorFromSecondAppend :=
+				testPersister.AppendGameToSeries(executionContext, secondGame, thirdGame)
+
+			if errorFromSecondAppend != nil {
+				unitTest.Fatalf("AppendGameToSeries(...) produced unexpected error %v", errorFromSecondAppend)
+			}
+
+			for _, gameInSeries := range []string{firstGame, secondGame, thirdGame} {
+				readSeries, isInSeries, errorFromRead :=
+					testPersister.ReadSeriesContainingGame(executionContext, gameInSeries)
+
+				if errorFromRead != nil || !isInSeries {
+					unitTest.Fatalf(
+						"ReadSeriesContainingGame(%v) gave %v, %v, expected series",
+						gameInSeries,
+						isInSeries,
+						errorFromRead)
+				}
+
+				if readSeries.SeriesName != firstGame {
+					unitTest.Fatalf(
+						"Series %v should have been named after %v",
+						readSeries,
+						firstGame)
+				}
+
+				assertStringSlicesMatch(
+					testIdentifier+"/"+gameInSeries,
+					unitTest,
+					[]string{firstGame, secondGame, thirdGame},
+					readSeries.GameNamesInOrder)
+			}
+		})
+	}
+}
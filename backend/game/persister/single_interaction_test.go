@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
 )
@@ -169,8 +170,9 @@ func TestRecordAndRetrieveSingleChatMessage(unitTest *testing.T) {
 					testStartTime,
 					time.Now())
 
-				// There should have been no other changes.
+				// There should have been no other changes than the new version.
 				pristineState.ChatLog = gameAndDescription.GameState.Read().ChatLog()
+				pristineState.Version += 1
 				assertGameStateAsExpectedLocallyAndRetrieved(
 					testIdentifier,
 					unitTest,
@@ -241,6 +243,7 @@ func TestErrorFromActionsInvalidlyTakingCardFromHand(unitTest *testing.T) {
 				errorFromDiscardingCard :=
 					gameAndDescription.GameState.EnactTurnByDiscardingAndReplacing(
 						context.Background(),
+						gameAndDescription.GameState.Read().Turn(),
 						actionMessage,
 						testPlayer,
 						testCase.indexInHand,
@@ -271,6 +274,7 @@ func TestErrorFromActionsInvalidlyTakingCardFromHand(unitTest *testing.T) {
 				errorFromPlayingCard :=
 					gameAndDescription.GameState.EnactTurnByPlayingAndReplacing(
 						context.Background(),
+						gameAndDescription.GameState.Read().Turn(),
 						actionMessage,
 						testPlayer,
 						testCase.indexInHand,
@@ -300,6 +304,120 @@ func TestErrorFromActionsInvalidlyTakingCardFromHand(unitTest *testing.T) {
 	}
 }
 
+func TestErrorFromTurnsBasedOnStaleTurn(unitTest *testing.T) {
+	initialDeck := []card.Defined{}
+
+	actionMessage := "action message"
+	numberOfHintsToAdd := 1
+	numberOfMistakesToAdd := 0
+	numberOfHintsToSubtract := 1
+	knowledgeOfNewCard := testReplacementInferred
+
+	actingPlayer := &mockPlayerState{
+		threePlayersWithHands[0].PlayerName,
+		defaultTestColor,
+	}
+
+	receivingPlayerName := threePlayersWithHands[1].PlayerName
+	handSize := len(threePlayersWithHands[1].InitialHand)
+
+	// It is not important to make valid inferred cards for this test.
+	updatedInferredHand := make([]card.Inferred, handSize)
+
+	gamesAndDescriptions :=
+		prepareGameStates(
+			unitTest,
+			defaultTestRuleset,
+			threePlayersWithHands,
+			initialDeck,
+			initialActionLogForDefaultThreePlayers)
+
+	// The games are on their first turn, and an expected turn of zero is not checked, so
+	// the stale turns are all turns which the game has not yet reached.
+	testCases := []struct {
+		testName           string
+		differenceToActual int
+		enactTurn          func(game.ReadAndWriteState, int) error
+	}{
+		{
+			testName:           "discard based on next turn",
+			differenceToActual: 1,
+			enactTurn: func(gameState game.ReadAndWriteState, expectedTurn int) error {
+				return gameState.EnactTurnByDiscardingAndReplacing(
+					context.Background(),
+					expectedTurn,
+					actionMessage,
+					actingPlayer,
+					0,
+					knowledgeOfNewCard,
+					numberOfHintsToAdd,
+					numberOfMistakesToAdd)
+			},
+		},
+		{
+			testName:           "play based on turn after next",
+			differenceToActual: 2,
+			enactTurn: func(gameState game.ReadAndWriteState, expectedTurn int) error {
+				return gameState.EnactTurnByPlayingAndReplacing(
+					context.Background(),
+					expectedTurn,
+					actionMessage,
+					actingPlayer,
+					0,
+					knowledgeOfNewCard,
+					numberOfHintsToAdd)
+			},
+		},
+		{
+			testName:           "hint based on later turn",
+			differenceToActual: 3,
+			enactTurn: func(gameState game.ReadAndWriteState, expectedTurn int) error {
+				return gameState.EnactTurnByUpdatingHandWithHint(
+					context.Background(),
+					expectedTurn,
+					actionMessage,
+					actingPlayer,
+					receivingPlayerName,
+					updatedInferredHand,
+					numberOfHintsToSubtract)
+			},
+		},
+	}
+
+	for _, gameAndDescription := range gamesAndDescriptions {
+		for _, testCase := range testCases {
+			testIdentifier :=
+				"stale turn/" +
+					gameAndDescription.PersisterDescription +
+					"/" + testCase.testName
+
+			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+				pristineState := prepareExpected(unitTest, gameAndDescription.GameState.Read())
+				staleTurn :=
+					gameAndDescription.GameState.Read().Turn() + testCase.differenceToActual
+
+				errorFromTurn :=
+					testCase.enactTurn(gameAndDescription.GameState, staleTurn)
+
+				_, isTurnConflict := errorFromTurn.(*game.TurnConflictError)
+				if !isTurnConflict {
+					unitTest.Fatalf(
+						"turn with expected turn %v produced error %v rather than a turn conflict",
+						staleTurn,
+						errorFromTurn)
+				}
+
+				// There should have been no visible side-effects at all.
+				assertGameStateAsExpectedLocallyAndRetrieved(
+					testIdentifier,
+					unitTest,
+					gameAndDescription,
+					pristineState)
+			})
+		}
+	}
+}
+
 func TestValidDiscardOfCardWhenDeckNotYetEmpty(unitTest *testing.T) {
 	expectedReplacementCard :=
 		card.Defined{
@@ -377,6 +495,7 @@ func TestValidDiscardOfCardWhenDeckNotYetEmpty(unitTest *testing.T) {
 					errorFromDiscardingCard :=
 						gameAndDescription.GameState.EnactTurnByDiscardingAndReplacing(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							testPlayer,
 							indexInHand,
@@ -405,6 +524,7 @@ func TestValidDiscardOfCardWhenDeckNotYetEmpty(unitTest *testing.T) {
 					pristineState.NumberOfReadyHints += numberOfHintsToAdd
 					pristineState.NumberOfMistakesMade += numberOfMistakesToAdd
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.VisibleCardInHand[playerName][indexInHand] = expectedReplacementCard
 					pristineState.InferredCardInHand[playerName][indexInHand] = knowledgeOfNewCard
 					pristineState.NumberOfDiscardedCards[expectedDiscardedCard.Defined] = 1
@@ -488,6 +608,7 @@ func TestValidDiscardOfCardWhichEmptiesDeck(unitTest *testing.T) {
 					errorFromDiscardingCard :=
 						gameAndDescription.GameState.EnactTurnByDiscardingAndReplacing(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							testPlayer,
 							indexInHand,
@@ -516,6 +637,7 @@ func TestValidDiscardOfCardWhichEmptiesDeck(unitTest *testing.T) {
 					pristineState.NumberOfReadyHints += numberOfHintsToAdd
 					pristineState.NumberOfMistakesMade += numberOfMistakesToAdd
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.VisibleCardInHand[playerName][indexInHand] = expectedReplacementCard
 					pristineState.InferredCardInHand[playerName][indexInHand] = knowledgeOfNewCard
 					pristineState.NumberOfDiscardedCards[expectedDiscardedCard.Defined] = 1
@@ -589,6 +711,7 @@ func TestValidDiscardOfCardWhenDeckAlreadyEmpty(unitTest *testing.T) {
 					errorFromDiscardingCard :=
 						gameAndDescription.GameState.EnactTurnByDiscardingAndReplacing(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							testPlayer,
 							indexInHand,
@@ -617,6 +740,7 @@ func TestValidDiscardOfCardWhenDeckAlreadyEmpty(unitTest *testing.T) {
 					pristineState.NumberOfReadyHints += numberOfHintsToAdd
 					pristineState.NumberOfMistakesMade += numberOfMistakesToAdd
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.TurnsTakenWithEmptyDeck += 1
 					pristineVisibleHand := pristineState.VisibleCardInHand[playerName]
 					pristineState.VisibleCardInHand[playerName] =
@@ -712,6 +836,7 @@ func TestValidPlayOfCardWhenDeckNotYetEmpty(unitTest *testing.T) {
 					errorFromPlayingCard :=
 						gameAndDescription.GameState.EnactTurnByPlayingAndReplacing(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							testPlayer,
 							indexInHand,
@@ -737,6 +862,7 @@ func TestValidPlayOfCardWhenDeckNotYetEmpty(unitTest *testing.T) {
 					pristineState.DeckSize = initialDeckSize - 1
 					pristineState.NumberOfReadyHints += numberOfHintsToAdd
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.VisibleCardInHand[playerName][indexInHand] = expectedReplacementCard
 					pristineState.InferredCardInHand[playerName][indexInHand] = knowledgeOfNewCard
 					pristineState.PlayedForColor[expectedPlayedCard.ColorSuit] =
@@ -820,6 +946,7 @@ func TestValidPlayOfCardWhichEmptiesDeck(unitTest *testing.T) {
 					errorFromPlayingCard :=
 						gameAndDescription.GameState.EnactTurnByPlayingAndReplacing(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							testPlayer,
 							indexInHand,
@@ -845,6 +972,7 @@ func TestValidPlayOfCardWhichEmptiesDeck(unitTest *testing.T) {
 					pristineState.DeckSize = initialDeckSize - 1
 					pristineState.NumberOfReadyHints += numberOfHintsToAdd
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.VisibleCardInHand[playerName][indexInHand] = expectedReplacementCard
 					pristineState.InferredCardInHand[playerName][indexInHand] = knowledgeOfNewCard
 					pristineState.PlayedForColor[expectedPlayedCard.ColorSuit] =
@@ -918,6 +1046,7 @@ func TestValidPlayOfCardWhenDeckAlreadyEmpty(unitTest *testing.T) {
 					errorFromPlayingCard :=
 						gameAndDescription.GameState.EnactTurnByPlayingAndReplacing(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							testPlayer,
 							indexInHand,
@@ -943,6 +1072,7 @@ func TestValidPlayOfCardWhenDeckAlreadyEmpty(unitTest *testing.T) {
 					pristineState.DeckSize = initialDeckSize
 					pristineState.NumberOfReadyHints += numberOfHintsToAdd
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.TurnsTakenWithEmptyDeck += 1
 					pristineVisibleHand := pristineState.VisibleCardInHand[playerName]
 					pristineState.VisibleCardInHand[playerName] =
@@ -1002,6 +1132,7 @@ func TestErrorFromHintToInvalidPlayer(unitTest *testing.T) {
 			errorFromHint :=
 				gameAndDescription.GameState.EnactTurnByUpdatingHandWithHint(
 					context.Background(),
+					gameAndDescription.GameState.Read().Turn(),
 					actionMessage,
 					actingPlayer,
 					receivingPlayerName,
@@ -1070,6 +1201,7 @@ func TestErrorFromHintWithTooSmallInferredHand(unitTest *testing.T) {
 			errorFromHint :=
 				gameAndDescription.GameState.EnactTurnByUpdatingHandWithHint(
 					context.Background(),
+					gameAndDescription.GameState.Read().Turn(),
 					actionMessage,
 					actingPlayer,
 					receivingPlayerName,
@@ -1138,6 +1270,7 @@ func TestErrorFromHintWithTooLargeInferredHand(unitTest *testing.T) {
 			errorFromHint :=
 				gameAndDescription.GameState.EnactTurnByUpdatingHandWithHint(
 					context.Background(),
+					gameAndDescription.GameState.Read().Turn(),
 					actionMessage,
 					actingPlayer,
 					receivingPlayerName,
@@ -1240,6 +1373,7 @@ func TestValidHintWhenDeckAlreadyEmpty(unitTest *testing.T) {
 					errorFromHint :=
 						gameAndDescription.GameState.EnactTurnByUpdatingHandWithHint(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							actingPlayer,
 							receivingPlayerName,
@@ -1265,6 +1399,7 @@ func TestValidHintWhenDeckAlreadyEmpty(unitTest *testing.T) {
 					pristineState.DeckSize = initialDeckSize
 					pristineState.NumberOfReadyHints -= numberOfHintsToSubtract
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.TurnsTakenWithEmptyDeck += 1
 					pristineState.InferredCardInHand[receivingPlayerName] = updatedInferredHand
 					assertGameStateAsExpectedLocallyAndRetrieved(
@@ -1367,6 +1502,7 @@ func TestValidHintWhenDeckNotYetEmpty(unitTest *testing.T) {
 					errorFromHint :=
 						gameAndDescription.GameState.EnactTurnByUpdatingHandWithHint(
 							context.Background(),
+							gameAndDescription.GameState.Read().Turn(),
 							actionMessage,
 							actingPlayer,
 							receivingPlayerName,
@@ -1392,6 +1528,7 @@ func TestValidHintWhenDeckNotYetEmpty(unitTest *testing.T) {
 					pristineState.DeckSize = initialDeckSize
 					pristineState.NumberOfReadyHints -= numberOfHintsToSubtract
 					pristineState.Turn += 1
+					pristineState.Version += 1
 					pristineState.InferredCardInHand[receivingPlayerName] = updatedInferredHand
 					assertGameStateAsExpectedLocallyAndRetrieved(
 						testIdentifier,
@@ -1464,3 +1601,106 @@ func assertLogWithSingleMessageIsCorrect(
 		}
 	}
 }
+
+func TestDiscardsAndMistakesCountedForActingPlayer(unitTest *testing.T) {
+	discardingPlayer := threePlayersWithHands[0].PlayerName
+	mistakenPlayer := threePlayersWithHands[1].PlayerName
+	idlePlayer := threePlayersWithHands[2].PlayerName
+
+	initialDeck :=
+		[]card.Defined{
+			card.Defined{
+				ColorSuit:     "a",
+				SequenceIndex: 3,
+			},
+			card.Defined{
+				ColorSuit:     "b",
+				SequenceIndex: 2,
+			},
+		}
+
+	gamesAndDescriptions :=
+		prepareGameStates(
+			unitTest,
+			defaultTestRuleset,
+			threePlayersWithHands,
+			initialDeck,
+			initialActionLogForDefaultThreePlayers)
+
+	for _, gameAndDescription := range gamesAndDescriptions {
+		testIdentifier :=
+			"discards and mistakes counted for acting player/" +
+				gameAndDescription.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromDiscard :=
+				gameAndDescription.GameState.EnactTurnByDiscardingAndReplacing(
+					context.Background(),
+					gameAndDescription.GameState.Read().Turn(),
+					"discards",
+					&mockPlayerState{discardingPlayer, defaultTestColor},
+					0,
+					testReplacementInferred,
+					1,
+					0)
+
+			if errorFromDiscard != nil {
+				unitTest.Fatalf(
+					"EnactTurnByDiscardingAndReplacing(...) for discard produced error %v",
+					errorFromDiscard)
+			}
+
+			errorFromMistake :=
+				gameAndDescription.GameState.EnactTurnByDiscardingAndReplacing(
+					context.Background(),
+					gameAndDescription.GameState.Read().Turn(),
+					"makes a mistake",
+					&mockPlayerState{mistakenPlayer, defaultTestColor},
+					0,
+					testReplacementInferred,
+					0,
+					1)
+
+			if errorFromMistake != nil {
+				unitTest.Fatalf(
+					"EnactTurnByDiscardingAndReplacing(...) for mistake produced error %v",
+					errorFromMistake)
+			}
+
+			retrievedState, errorFromRetrieval :=
+				gameAndDescription.GamePersister.ReadAndWriteGame(
+					context.Background(),
+					gameAndDescription.GameState.Read().Name())
+
+			if errorFromRetrieval != nil {
+				unitTest.Fatalf(
+					"Unable to retrieve game state: %v",
+					errorFromRetrieval)
+			}
+
+			expectedDiscardsAndMistakes := map[string][]int{
+				discardingPlayer: []int{1, 0},
+				mistakenPlayer:   []int{0, 1},
+				idlePlayer:       []int{0, 0},
+			}
+
+			for _, actualState := range []game.ReadonlyState{
+				gameAndDescription.GameState.Read(),
+				retrievedState.Read(),
+			} {
+				for playerName, expectedCounts := range expectedDiscardsAndMistakes {
+					actualCounts := []int{
+						actualState.NumberOfDiscardsByPlayer(playerName),
+						actualState.NumberOfMistakesByPlayer(playerName),
+					}
+
+					assertIntSlicesMatch(
+						testIdentifier+"/"+playerName,
+						unitTest,
+						expectedCounts,
+						actualCounts)
+				}
+			}
+		})
+	}
+}
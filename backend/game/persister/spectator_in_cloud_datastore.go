@@ -0,0 +1,227 @@
+package persister
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+)
+
+// CloudDatastoreSpectatorKeyKind denotes the kind for the entities which will
+// store spectator galleries in the Google Cloud Datastore.
+const CloudDatastoreSpectatorKeyKind = "Spectators"
+
+// serializableGallery holds the information of a spectator gallery in a form which
+// the Cloud Datastore can store: the snapshots contain nested slices, which the
+// Datastore does not support, so they are stored as JSON strings instead, and are
+// not indexed because they are never used for queries.
+type serializableGallery struct {
+	GameName               string
+	SpectatingIsAllowed    bool
+	DelayInTurns           int
+	ChatMessageLog         []message.FromPlayer `datastore:",noindex"`
+	DelayedSnapshotsAsJSON []string             `datastore:",noindex"`
+}
+
+// inCloudDatastoreSpectatorPersister stores spectator galleries in Google Cloud
+// Datastore, keyed by the names of their games.
+type inCloudDatastoreSpectatorPersister struct {
+	clientProvider  cloud.DatastoreClientProvider
+	datastoreClient cloud.LimitedClient
+}
+
+// NewSpectatorInCloudDatastore creates a spectator persister.
+func NewSpectatorInCloudDatastore(
+	clientProvider cloud.DatastoreClientProvider) game.SpectatorPersister {
+	return &inCloudDatastoreSpectatorPersister{
+		clientProvider:  clientProvider,
+		datastoreClient: nil,
+	}
+}
+
+// ReadGallery returns the gallery for the given game, or a closed gallery if there
+// is none.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) ReadGallery(
+	executionContext context.Context,
+	gameName string) (game.SpectatorGallery, error) {
+	initializedClient, errorFromAcquiral :=
+		spectatorPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return game.SpectatorGallery{}, errorFromAcquiral
+	}
+
+	isStored, errorFromCheck :=
+		cloud.DoesNameExist(executionContext, initializedClient, gameName)
+
+	if errorFromCheck != nil {
+		return game.SpectatorGallery{}, errorFromCheck
+	}
+
+	if !isStored {
+		return game.NewClosedGallery(gameName), nil
+	}
+
+	var storedGallery serializableGallery
+	errorFromGet :=
+		initializedClient.Get(executionContext, gameName, &storedGallery)
+
+	if errorFromGet != nil {
+		return game.SpectatorGallery{}, errorFromGet
+	}
+
+	delayedSnapshots :=
+		make([]game.NeutralSnapshot, len(storedGallery.DelayedSnapshotsAsJSON))
+
+	for snapshotIndex, snapshotAsJSON := range storedGallery.DelayedSnapshotsAsJSON {
+		errorFromUnmarshal :=
+			json.Unmarshal([]byte(snapshotAsJSON), &delayedSnapshots[snapshotIndex])
+
+		if errorFromUnmarshal != nil {
+			return game.SpectatorGallery{}, errorFromUnmarshal
+		}
+	}
+
+	chatMessageLog := storedGallery.ChatMessageLog
+	if chatMessageLog == nil {
+		chatMessageLog = []message.FromPlayer{}
+	}
+
+	return game.SpectatorGallery{
+		GameName:            storedGallery.GameName,
+		SpectatingIsAllowed: storedGallery.SpectatingIsAllowed,
+		DelayInTurns:        storedGallery.DelayInTurns,
+		ChatMessageLog:      chatMessageLog,
+		DelayedSnapshots:    delayedSnapshots,
+	}, nil
+}
+
+// SetSpectating sets whether the given game may be spectated and with how much
+// delay.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) SetSpectating(
+	executionContext context.Context,
+	gameName string,
+	isAllowed bool,
+	delayInTurns int) error {
+	galleryToUpdate, errorFromRead :=
+		spectatorPersister.ReadGallery(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	errorFromSet := galleryToUpdate.SetSpectating(isAllowed, delayInTurns)
+
+	if errorFromSet != nil {
+		return errorFromSet
+	}
+
+	return spectatorPersister.writeGallery(executionContext, galleryToUpdate)
+}
+
+// AddChatMessage appends the given message to the spectator chat log of the given
+// game.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) AddChatMessage(
+	executionContext context.Context,
+	gameName string,
+	chatMessage message.FromPlayer,
+	chatLogLength int) error {
+	galleryToUpdate, errorFromRead :=
+		spectatorPersister.ReadGallery(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	galleryToUpdate.AddChatMessage(chatMessage, chatLogLength)
+
+	return spectatorPersister.writeGallery(executionContext, galleryToUpdate)
+}
+
+// AddSnapshot stores the given snapshot in the gallery of the given game.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) AddSnapshot(
+	executionContext context.Context,
+	gameName string,
+	newSnapshot game.NeutralSnapshot) error {
+	galleryToUpdate, errorFromRead :=
+		spectatorPersister.ReadGallery(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	galleryToUpdate.AddSnapshot(newSnapshot)
+
+	return spectatorPersister.writeGallery(executionContext, galleryToUpdate)
+}
+
+// DeleteGallery deletes the gallery of the given game, if there is one.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) DeleteGallery(
+	executionContext context.Context,
+	gameName string) error {
+	initializedClient, errorFromAcquiral :=
+		spectatorPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	isStored, errorFromCheck :=
+		cloud.DoesNameExist(executionContext, initializedClient, gameName)
+
+	if errorFromCheck != nil || !isStored {
+		return errorFromCheck
+	}
+
+	return initializedClient.Delete(executionContext, gameName)
+}
+
+// writeGallery converts the given gallery into its serializable form and puts it
+// into the Cloud Datastore.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) writeGallery(
+	executionContext context.Context,
+	galleryToWrite game.SpectatorGallery) error {
+	snapshotsAsJSON := make([]string, len(galleryToWrite.DelayedSnapshots))
+
+	for snapshotIndex, delayedSnapshot := range galleryToWrite.DelayedSnapshots {
+		snapshotBytes, errorFromMarshal := json.Marshal(delayedSnapshot)
+
+		if errorFromMarshal != nil {
+			return errorFromMarshal
+		}
+
+		snapshotsAsJSON[snapshotIndex] = string(snapshotBytes)
+	}
+
+	galleryToPut := serializableGallery{
+		GameName:               galleryToWrite.GameName,
+		SpectatingIsAllowed:    galleryToWrite.SpectatingIsAllowed,
+		DelayInTurns:           galleryToWrite.DelayInTurns,
+		ChatMessageLog:         galleryToWrite.ChatMessageLog,
+		DelayedSnapshotsAsJSON: snapshotsAsJSON,
+	}
+
+	return spectatorPersister.datastoreClient.Put(
+		executionContext,
+		galleryToWrite.GameName,
+		&galleryToPut)
+}
+
+// acquireClient returns the connection to the Cloud Datastore,
+// initializing it if it has not already been initialized.
+func (spectatorPersister *inCloudDatastoreSpectatorPersister) acquireClient(
+	executionContext context.Context) (cloud.LimitedClient, error) {
+	if spectatorPersister.datastoreClient == nil {
+		cloudDatastoreClient, errorFromCloudDatastore :=
+			spectatorPersister.clientProvider.NewClient(executionContext)
+		if errorFromCloudDatastore != nil {
+			return nil, errorFromCloudDatastore
+		}
+
+		spectatorPersister.datastoreClient = cloudDatastoreClient
+	}
+
+	return spectatorPersister.datastoreClient, nil
+}
@@ -0,0 +1,126 @@
+package persister
+
+import (
+	"context"
+	"sync"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+)
+
+// inMemorySpectatorPersister stores spectator galleries mapped to by the names of
+// their games. It ignores all context structs passed to its functions.
+type inMemorySpectatorPersister struct {
+	mutualExclusion sync.Mutex
+	galleriesByGame map[string]game.SpectatorGallery
+}
+
+// NewSpectatorInMemory creates a spectator persister around a map of galleries.
+func NewSpectatorInMemory() game.SpectatorPersister {
+	return &inMemorySpectatorPersister{
+		mutualExclusion: sync.Mutex{},
+		galleriesByGame: make(map[string]game.SpectatorGallery, 1),
+	}
+}
+
+// ReadGallery returns a copy of the gallery for the given game, or a closed gallery
+// if there is none. The context is ignored.
+func (spectatorPersister *inMemorySpectatorPersister) ReadGallery(
+	executionContext context.Context,
+	gameName string) (game.SpectatorGallery, error) {
+	spectatorPersister.mutualExclusion.Lock()
+	defer spectatorPersister.mutualExclusion.Unlock()
+
+	return spectatorPersister.copyOfGallery(gameName), nil
+}
+
+// SetSpectating sets whether the given game may be spectated and with how much
+// delay. The context is ignored.
+func (spectatorPersister *inMemorySpectatorPersister) SetSpectating(
+	executionContext context.Context,
+	gameName string,
+	isAllowed bool,
+	delayInTurns int) error {
+	spectatorPersister.mutualExclusion.Lock()
+	defer spectatorPersister.mutualExclusion.Unlock()
+
+	galleryToUpdate := spectatorPersister.copyOfGallery(gameName)
+
+	errorFromSet := galleryToUpdate.SetSpectating(isAllowed, delayInTurns)
+
+	if errorFromSet != nil {
+		return errorFromSet
+	}
+
+	spectatorPersister.galleriesByGame[gameName] = galleryToUpdate
+
+	return nil
+}
+
+// AddChatMessage appends the given message to the spectator chat log of the given
+// game. The context is ignored.
+func (spectatorPersister *inMemorySpectatorPersister) AddChatMessage(
+	executionContext context.Context,
+	gameName string,
+	chatMessage message.FromPlayer,
+	chatLogLength int) error {
+	spectatorPersister.mutualExclusion.Lock()
+	defer spectatorPersister.mutualExclusion.Unlock()
+
+	galleryToUpdate := spectatorPersister.copyOfGallery(gameName)
+	galleryToUpdate.AddChatMessage(chatMessage, chatLogLength)
+	spectatorPersister.galleriesByGame[gameName] = galleryToUpdate
+
+	return nil
+}
+
+// AddSnapshot stores the given snapshot in the gallery of the given game. The
+// context is ignored.
+func (spectatorPersister *inMemorySpectatorPersister) AddSnapshot(
+	executionContext context.Context,
+	gameName string,
+	newSnapshot game.NeutralSnapshot) error {
+	spectatorPersister.mutualExclusion.Lock()
+	defer spectatorPersister.mutualExclusion.Unlock()
+
+	galleryToUpdate := spectatorPersister.copyOfGallery(gameName)
+	galleryToUpdate.AddSnapshot(newSnapshot)
+	spectatorPersister.galleriesByGame[gameName] = galleryToUpdate
+
+	return nil
+}
+
+// DeleteGallery removes the gallery of the given game from the map. The context is
+// ignored.
+func (spectatorPersister *inMemorySpectatorPersister) DeleteGallery(
+	executionContext context.Context,
+	gameName string) error {
+	spectatorPersister.mutualExclusion.Lock()
+	defer spectatorPersister.mutualExclusion.Unlock()
+
+	delete(spectatorPersister.galleriesByGame, gameName)
+
+	return nil
+}
+
+// copyOfGallery returns a copy of the gallery for the given game, with its own
+// slices, so that changes to the copy do not affect the stored gallery. It should
+// only be called while the mutex is locked.
+func (spectatorPersister *inMemorySpectatorPersister) copyOfGallery(
+	gameName string) game.SpectatorGallery {
+	storedGallery, hasGallery := spectatorPersister.galleriesByGame[gameName]
+
+	if !hasGallery {
+		return game.NewClosedGallery(gameName)
+	}
+
+	galleryCopy := storedGallery
+	galleryCopy.ChatMessageLog =
+		make([]message.FromPlayer, len(storedGallery.ChatMessageLog))
+	copy(galleryCopy.ChatMessageLog, storedGallery.ChatMessageLog)
+	galleryCopy.DelayedSnapshots =
+		make([]game.NeutralSnapshot, len(storedGallery.DelayedSnapshots))
+	copy(galleryCopy.DelayedSnapshots, storedGallery.DelayedSnapshots)
+
+	return galleryCopy
+}
@@ -0,0 +1,135 @@
+package persister_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/game/persister"
+)
+
+type spectatorPersisterAndDescription struct {
+	SpectatorPersister   game.SpectatorPersister
+	PersisterDescription string
+}
+
+func prepareSpectatorPersisters() []spectatorPersisterAndDescription {
+	spectatorDatastoreClientProvider :=
+		cloud.NewIlutulestikudDatastoreClientProvider(persister.CloudDatastoreSpectatorKeyKind)
+
+	return []spectatorPersisterAndDescription{
+		spectatorPersisterAndDescription{
+			SpectatorPersister:   persister.NewSpectatorInMemory(),
+			PersisterDescription: "in-memory persister",
+		},
+		spectatorPersisterAndDescription{
+			SpectatorPersister:   persister.NewSpectatorInCloudDatastore(spectatorDatastoreClientProvider),
+			PersisterDescription: "in-Cloud-Datastore persister",
+		},
+	}
+}
+
+func TestSpectatorGalleryRoundTrip(unitTest *testing.T) {
+	gameName := testGameNamePrefix + "spectated game"
+	chatLogLength := 2
+
+	for _, spectatorPersister := range prepareSpectatorPersisters() {
+		testIdentifier := "Spectator gallery/" + spectatorPersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			testPersister := spectatorPersister.SpectatorPersister
+
+			initialGallery, errorFromInitialRead :=
+				testPersister.ReadGallery(executionContext, gameName)
+
+			if errorFromInitialRead != nil || initialGallery.SpectatingIsAllowed {
+				unitTest.Fatalf(
+					"ReadGallery(...) before any settings gave %v, %v, expected closed gallery",
+					initialGallery,
+					errorFromInitialRead)
+			}
+
+			errorFromSet :=
+				testPersister.SetSpectating(executionContext, gameName, true, 1)
+
+			if errorFromSet != nil {
+				unitTest.Fatalf("SetSpectating(...) produced unexpected error %v", errorFromSet)
+			}
+
+			for _, messageText := range []string{"first", "second", "third"} {
+				errorFromChat :=
+					testPersister.AddChatMessage(
+						executionContext,
+						gameName,
+						message.NewFromPlayer("spectator", "red", messageText),
+						chatLogLength)
+
+				if errorFromChat != nil {
+					unitTest.Fatalf("AddChatMessage(...) produced unexpected error %v", errorFromChat)
+				}
+			}
+
+			for turnNumber := 1; turnNumber <= 4; turnNumber++ {
+				errorFromSnapshot :=
+					testPersister.AddSnapshot(
+						executionContext,
+						gameName,
+						game.NeutralSnapshot{GameName: gameName, Turn: turnNumber})
+
+				if errorFromSnapshot != nil {
+					unitTest.Fatalf("AddSnapshot(...) produced unexpected error %v", errorFromSnapshot)
+				}
+			}
+
+			readGallery, errorFromRead :=
+				testPersister.ReadGallery(executionContext, gameName)
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("ReadGallery(...) produced unexpected error %v", errorFromRead)
+			}
+
+			if !readGallery.SpectatingIsAllowed || readGallery.DelayInTurns != 1 {
+				unitTest.Fatalf("ReadGallery(...) gave unexpected settings %v", readGallery)
+			}
+
+			actualMessages := make([]string, len(readGallery.ChatMessageLog))
+			for messageIndex, chatMessage := range readGallery.ChatMessageLog {
+				actualMessages[messageIndex] = chatMessage.MessageText
+			}
+
+			assertStringSlicesMatch(
+				testIdentifier+"/chat log",
+				unitTest,
+				[]string{"second", "third"},
+				actualMessages)
+
+			delayedSnapshot, isFound := readGallery.DelayedSnapshot(4)
+
+			if !isFound || delayedSnapshot.Turn != 3 {
+				unitTest.Fatalf(
+					"DelayedSnapshot(4) gave %v, %v, expected snapshot of turn 3",
+					delayedSnapshot,
+					isFound)
+			}
+
+			errorFromDelete := testPersister.DeleteGallery(executionContext, gameName)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf("DeleteGallery(...) produced unexpected error %v", errorFromDelete)
+			}
+
+			deletedGallery, errorFromFinalRead :=
+				testPersister.ReadGallery(executionContext, gameName)
+
+			if errorFromFinalRead != nil || deletedGallery.SpectatingIsAllowed {
+				unitTest.Fatalf(
+					"ReadGallery(...) after delete gave %v, %v, expected closed gallery",
+					deletedGallery,
+					errorFromFinalRead)
+			}
+		})
+	}
+}
@@ -72,7 +72,7 @@ func ViewOnStateForPlayer(
 	// If the view is still nil, then the player was not a participant.
 	if playerView == nil {
 		notFoundError :=
-			fmt.Errorf(
+			ErrNotParticipant.Errorf(
 				"No player with name %v is a participant in game %v",
 				nameOfPlayer,
 				stateOfGame.Name())
@@ -141,6 +141,11 @@ func (playerView *PlayerView) Turn() int {
 	return playerView.gameState.Turn()
 }
 
+// Version just wraps around the read-only game state's Version function.
+func (playerView *PlayerView) Version() int {
+	return playerView.gameState.Version()
+}
+
 // Score derives the score from the cards in the played area.
 func (playerView *PlayerView) Score() int {
 	if IsOverBecauseOfMistakes(playerView.gameState) {
@@ -256,6 +261,18 @@ func (playerView *PlayerView) KnowledgeOfOwnHand(
 	return playerView.gameState.InferredHand(holdingPlayer)
 }
 
+// ParticipantDisplayName returns the current display name of the participant with the
+// given identifier, falling back to the identifier if the participant is not known.
+func (playerView *PlayerView) ParticipantDisplayName(
+	participantIdentifier string) string {
+	participantState, isParticipant := playerView.playerStates[participantIdentifier]
+	if !isParticipant {
+		return participantIdentifier
+	}
+
+	return participantState.Name()
+}
+
 func createViewWithoutPlayerMap(
 	stateOfGame ReadonlyState,
 	numberOfPlayers int,
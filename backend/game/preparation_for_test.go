@@ -1,6 +1,7 @@
 package game_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -22,7 +23,15 @@ func prepareCollection(
 	mockGamePersister :=
 		NewMockGamePersister(unitTest, fmt.Errorf("initial error for every function"))
 	mockPlayerProvider := NewMockPlayerProvider(initialPlayers)
-	mockCollection := game.NewCollection(mockGamePersister, logLengthForTest, mockPlayerProvider)
+	mockCollection := game.NewCollection(
+		mockGamePersister,
+		persister.NewLobbyInMemory(),
+		persister.NewSeriesInMemory(),
+		persister.NewSpectatorInMemory(),
+		persister.NewHistoryInMemory(),
+		logLengthForTest,
+		mockPlayerProvider,
+		mockPlayerProvider)
 	return mockCollection, mockGamePersister, mockPlayerProvider
 }
 
@@ -53,6 +62,10 @@ func descriptionOfRuleset(unitTest *testing.T, rulesetIdentifier int) string {
 
 type persisterAndDescription struct {
 	GamePersister        game.StatePersister
+	LobbyPersister       game.LobbyPersister
+	SeriesPersister      game.SeriesPersister
+	SpectatorPersister   game.SpectatorPersister
+	HistoryPersister     game.HistoryPersister
 	PersisterDescription string
 }
 
@@ -67,6 +80,10 @@ func prepareCollections(unitTest *testing.T) []collectionAndDescription {
 	statePersisters := []persisterAndDescription{
 		persisterAndDescription{
 			GamePersister:        persister.NewInMemory(),
+			LobbyPersister:       persister.NewLobbyInMemory(),
+			SeriesPersister:      persister.NewSeriesInMemory(),
+			SpectatorPersister:   persister.NewSpectatorInMemory(),
+			HistoryPersister:     persister.NewHistoryInMemory(),
 			PersisterDescription: "in-memory persister",
 		},
 	}
@@ -80,7 +97,12 @@ func prepareCollections(unitTest *testing.T) []collectionAndDescription {
 		stateCollection :=
 			game.NewCollection(
 				gamePersister.GamePersister,
+				gamePersister.LobbyPersister,
+				gamePersister.SeriesPersister,
+				gamePersister.SpectatorPersister,
+				gamePersister.HistoryPersister,
 				logLengthForTest,
+				mockProvider,
 				mockProvider)
 		stateCollections[persisterIndex] = collectionAndDescription{
 			GameCollection:        stateCollection,
@@ -90,3 +112,20 @@ func prepareCollections(unitTest *testing.T) []collectionAndDescription {
 
 	return stateCollections
 }
+
+// currentTurn returns the turn of the given game as the given player sees it, so that
+// tests can take turns based on the current turn.
+func currentTurn(
+	unitTest *testing.T,
+	gameCollection *game.StateCollection,
+	gameName string,
+	playerName string) int {
+	gameView, errorFromView :=
+		gameCollection.ViewState(context.Background(), gameName, playerName)
+
+	if errorFromView != nil {
+		unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromView)
+	}
+
+	return gameView.Turn()
+}
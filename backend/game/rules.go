@@ -1,8 +1,6 @@
 package game
 
 import (
-	"fmt"
-
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 )
 
@@ -47,7 +45,7 @@ func RulesetFromIdentifier(rulesetIdentifier int) (Ruleset, error) {
 	case WithRainbowAsCompoundIdentifier:
 		return NewRainbowAsCompoundSuit(), nil
 	default:
-		return nil, fmt.Errorf("Ruleset identifier %v not recognized", rulesetIdentifier)
+		return nil, ErrUnknownRuleset.Errorf("Ruleset identifier %v not recognized", rulesetIdentifier)
 	}
 }
 
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// Series encapsulates a sequence of games where each game after the first is a
+// rematch of the game before it, with the same participants and ruleset. The
+// series is named after its first game. It has to be an exported struct with
+// only exported data members so that it serializes easily.
+type Series struct {
+	SeriesName       string
+	GameNamesInOrder []string
+	TimeOfCreation   time.Time
+}
+
+// NewSeries creates a new series with the given games in the given order, named
+// after the first game.
+func NewSeries(gameNamesInOrder []string) Series {
+	seriesName := ""
+	if len(gameNamesInOrder) > 0 {
+		seriesName = gameNamesInOrder[0]
+	}
+
+	copiedNames := make([]string, len(gameNamesInOrder))
+	copy(copiedNames, gameNamesInOrder)
+
+	return Series{
+		SeriesName:       seriesName,
+		GameNamesInOrder: copiedNames,
+		TimeOfCreation:   time.Now(),
+	}
+}
+
+// LastGameName returns the name of the most recent game in the series.
+func (series *Series) LastGameName() string {
+	numberOfGames := len(series.GameNamesInOrder)
+	if numberOfGames == 0 {
+		return ""
+	}
+
+	return series.GameNamesInOrder[numberOfGames-1]
+}
+
+// AppendGame adds the new game to the end of the series, or returns an error if
+// the previous game is not the last game of the series.
+func (series *Series) AppendGame(previousGameName string, newGameName string) error {
+	lastGameName := series.LastGameName()
+	if lastGameName != previousGameName {
+		return fmt.Errorf(
+			"Game %v already has a rematch in series %v, which has %v as its latest game",
+			previousGameName,
+			series.SeriesName,
+			lastGameName)
+	}
+
+	series.GameNamesInOrder = append(series.GameNamesInOrder, newGameName)
+
+	return nil
+}
+
+// NameForRematch returns the name for the next game in the series.
+func (series *Series) NameForRematch() string {
+	return fmt.Sprintf(
+		"%v (game %v)",
+		series.SeriesName,
+		len(series.GameNamesInOrder)+1)
+}
@@ -0,0 +1,316 @@
+package game_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+// prepareFinishedGame creates a game for the given players with only enough
+// cards to deal the initial hands, and then has each player play a card, so
+// that the game is finished because every player has had a turn with an
+// empty deck.
+func prepareFinishedGame(
+	unitTest *testing.T,
+	gameCollection *game.StateCollection,
+	gameName string,
+	playerNames []string) {
+	handSize := testRuleset.NumberOfCardsInPlayerHand(len(playerNames))
+	initialDeck := testRuleset.CopyOfFullCardset()[:handSize*len(playerNames)]
+
+	errorFromAdd :=
+		gameCollection.AddNewWithGivenDeck(
+			context.Background(),
+			gameName,
+			testRuleset,
+			playerNames,
+			initialDeck)
+
+	if errorFromAdd != nil {
+		unitTest.Fatalf("AddNewWithGivenDeck(...) produced unexpected error %v", errorFromAdd)
+	}
+
+	for _, playerName := range playerNames {
+		actionExecutor, errorFromExecutor :=
+			gameCollection.ExecuteAction(context.Background(), gameName, playerName)
+
+		if errorFromExecutor != nil {
+			unitTest.Fatalf("ExecuteAction(...) produced unexpected error %v", errorFromExecutor)
+		}
+
+		errorFromPlay :=
+			actionExecutor.TakeTurnByPlaying(
+				context.Background(),
+				currentTurn(unitTest, gameCollection, gameName, playerName),
+				0)
+
+		if errorFromPlay != nil {
+			unitTest.Fatalf("TakeTurnByPlaying(...) produced unexpected error %v", errorFromPlay)
+		}
+	}
+}
+
+func TestRejectRematchOfUnfinishedGame(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					playerNamesAvailableInTest[:3])
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			rematchName, errorFromRematch :=
+				gameCollection.Rematch(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[0])
+
+			if errorFromRematch == nil {
+				unitTest.Fatalf(
+					"Rematch(...) of unfinished game did not produce expected error, instead created %v",
+					rematchName)
+			}
+		})
+	}
+}
+
+func TestRejectRematchByNonParticipant(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+
+			prepareFinishedGame(
+				unitTest,
+				gameCollection,
+				gameName,
+				playerNamesAvailableInTest[:2])
+
+			rematchName, errorFromRematch :=
+				gameCollection.Rematch(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[2])
+
+			if errorFromRematch == nil {
+				unitTest.Fatalf(
+					"Rematch(...) by non-participant did not produce expected error, instead created %v",
+					rematchName)
+			}
+		})
+	}
+}
+
+func TestRematchKeepsCreatorOfOriginalGame(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			firstGame := "Test game"
+			originalPlayers := playerNamesAvailableInTest[:3]
+
+			prepareFinishedGame(unitTest, gameCollection, firstGame, originalPlayers)
+
+			secondGame, errorFromRematch :=
+				gameCollection.Rematch(context.Background(), firstGame, originalPlayers[2])
+
+			if errorFromRematch != nil {
+				unitTest.Fatalf("Rematch(...) produced unexpected error %v", errorFromRematch)
+			}
+
+			// The turn order of the rematch is rotated, so its first player is not its
+			// creator.
+			actualCreator, errorFromCreator :=
+				gameCollection.Creator(context.Background(), secondGame)
+
+			if (errorFromCreator != nil) || (actualCreator != originalPlayers[0]) {
+				unitTest.Fatalf(
+					"Creator(%v) returned %v, %v - expected %v",
+					secondGame,
+					actualCreator,
+					errorFromCreator,
+					originalPlayers[0])
+			}
+
+			errorFromFirstPlayer :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					secondGame,
+					originalPlayers[1],
+					true,
+					0)
+
+			if errorFromFirstPlayer == nil {
+				unitTest.Fatalf(
+					"SetSpectating(...) by first player %v of rematch who is not its creator"+
+						" did not produce expected error",
+					originalPlayers[1])
+			}
+
+			errorFromCreatorSetting :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					secondGame,
+					originalPlayers[0],
+					true,
+					0)
+
+			if errorFromCreatorSetting != nil {
+				unitTest.Fatalf(
+					"SetSpectating(...) by creator produced unexpected error %v",
+					errorFromCreatorSetting)
+			}
+		})
+	}
+}
+
+func TestRematchesFormSeries(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			firstGame := "Test game"
+			originalPlayers := playerNamesAvailableInTest[:3]
+
+			prepareFinishedGame(unitTest, gameCollection, firstGame, originalPlayers)
+
+			secondGame, errorFromFirstRematch :=
+				gameCollection.Rematch(context.Background(), firstGame, originalPlayers[1])
+
+			if errorFromFirstRematch != nil {
+				unitTest.Fatalf("Rematch(...) produced unexpected error %v", errorFromFirstRematch)
+			}
+
+			_, errorFromRepeatedRematch :=
+				gameCollection.Rematch(context.Background(), firstGame, originalPlayers[2])
+
+			if errorFromRepeatedRematch == nil {
+				unitTest.Fatalf("Second Rematch(...) of same game did not produce expected error")
+			}
+
+			secondView, errorFromSecondView :=
+				gameCollection.ViewState(context.Background(), secondGame, originalPlayers[0])
+
+			if errorFromSecondView != nil {
+				unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromSecondView)
+			}
+
+			expectedRotatedPlayers := []string{
+				originalPlayers[1],
+				originalPlayers[2],
+				originalPlayers[0],
+			}
+
+			actualPlayers, _, _ := secondView.CurrentTurnOrder()
+
+			if !reflect.DeepEqual(actualPlayers, expectedRotatedPlayers) {
+				unitTest.Fatalf(
+					"Rematch had players %v, expected %v",
+					actualPlayers,
+					expectedRotatedPlayers)
+			}
+
+			// We make the rematch finished by deleting it and replacing it with a
+			// finished game of the same name and turn order.
+			errorFromDelete := gameCollection.Delete(context.Background(), secondGame)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf("Delete(...) produced unexpected error %v", errorFromDelete)
+			}
+
+			prepareFinishedGame(unitTest, gameCollection, secondGame, expectedRotatedPlayers)
+
+			thirdGame, errorFromSecondRematch :=
+				gameCollection.Rematch(context.Background(), secondGame, originalPlayers[0])
+
+			if errorFromSecondRematch != nil {
+				unitTest.Fatalf("Rematch(...) produced unexpected error %v", errorFromSecondRematch)
+			}
+
+			for _, gameInSeries := range []string{firstGame, secondGame, thirdGame} {
+				seriesViews, errorFromSeries :=
+					gameCollection.ViewSeries(
+						context.Background(),
+						gameInSeries,
+						originalPlayers[2])
+
+				if errorFromSeries != nil {
+					unitTest.Fatalf(
+						"ViewSeries(%v, ...) produced unexpected error %v",
+						gameInSeries,
+						errorFromSeries)
+				}
+
+				actualGameNames := make([]string, len(seriesViews))
+				for viewIndex, seriesView := range seriesViews {
+					actualGameNames[viewIndex] = seriesView.GameName()
+				}
+
+				expectedGameNames := []string{firstGame, secondGame, thirdGame}
+
+				if !reflect.DeepEqual(actualGameNames, expectedGameNames) {
+					unitTest.Fatalf(
+						"ViewSeries(%v, ...) gave games %v, expected %v",
+						gameInSeries,
+						actualGameNames,
+						expectedGameNames)
+				}
+			}
+		})
+	}
+}
+
+func TestViewSeriesOfSingleGame(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					playerNamesAvailableInTest[:2])
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			seriesViews, errorFromSeries :=
+				gameCollection.ViewSeries(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[1])
+
+			if errorFromSeries != nil {
+				unitTest.Fatalf("ViewSeries(...) produced unexpected error %v", errorFromSeries)
+			}
+
+			if len(seriesViews) != 1 || seriesViews[0].GameName() != gameName {
+				unitTest.Fatalf(
+					"ViewSeries(...) gave %v, expected just view of %v",
+					seriesViews,
+					gameName)
+			}
+
+			_, errorFromNonParticipant :=
+				gameCollection.ViewSeries(
+					context.Background(),
+					gameName,
+					playerNamesAvailableInTest[2])
+
+			if errorFromNonParticipant == nil {
+				unitTest.Fatalf("ViewSeries(...) by non-participant did not produce expected error")
+			}
+		})
+	}
+}
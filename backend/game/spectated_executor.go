@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"log"
+)
+
+// executorRecordingForSpectators wraps around an executor so that a snapshot of the
+// game is stored for spectators after every turn which is successfully taken, so
+// that spectators can be shown the game with a delay, and so that the game is stored
+// in the history of finished games by the turn which finishes it. It also notifies the
+// subscribers to the changes of the game after every successful action.
+type executorRecordingForSpectators struct {
+	wrappedExecutor ExecutorForPlayer
+	gameCollection  *StateCollection
+	gameName        string
+}
+
+// RecordChatMessage calls the function of the wrapped executor and then notifies the
+// subscribers to the changes of the game if the message was recorded. Nothing is
+// recorded for spectators, as the chat log of the participants is not shown to them.
+func (spectatedExecutor *executorRecordingForSpectators) RecordChatMessage(
+	executionContext context.Context,
+	chatMessage string) error {
+	errorFromChat :=
+		spectatedExecutor.wrappedExecutor.RecordChatMessage(
+			executionContext,
+			chatMessage)
+
+	if errorFromChat != nil {
+		return errorFromChat
+	}
+
+	spectatedExecutor.gameCollection.changeNotifier.notify(spectatedExecutor.gameName)
+
+	return nil
+}
+
+// TakeTurnByDiscarding calls the function of the wrapped executor and then records
+// the new state for spectators if the turn was successfully taken.
+func (spectatedExecutor *executorRecordingForSpectators) TakeTurnByDiscarding(
+	executionContext context.Context,
+	expectedTurn int,
+	indexInHand int) error {
+	return spectatedExecutor.recordIfSuccessful(
+		executionContext,
+		spectatedExecutor.wrappedExecutor.TakeTurnByDiscarding(
+			executionContext,
+			expectedTurn,
+			indexInHand))
+}
+
+// TakeTurnByPlaying calls the function of the wrapped executor and then records the
+// new state for spectators if the turn was successfully taken.
+func (spectatedExecutor *executorRecordingForSpectators) TakeTurnByPlaying(
+	executionContext context.Context,
+	expectedTurn int,
+	indexInHand int) error {
+	return spectatedExecutor.recordIfSuccessful(
+		executionContext,
+		spectatedExecutor.wrappedExecutor.TakeTurnByPlaying(
+			executionContext,
+			expectedTurn,
+			indexInHand))
+}
+
+// TakeTurnByHintingColor calls the function of the wrapped executor and then records
+// the new state for spectators if the turn was successfully taken.
+func (spectatedExecutor *executorRecordingForSpectators) TakeTurnByHintingColor(
+	executionContext context.Context,
+	expectedTurn int,
+	receivingPlayer string,
+	hintedColor string) error {
+	return spectatedExecutor.recordIfSuccessful(
+		executionContext,
+		spectatedExecutor.wrappedExecutor.TakeTurnByHintingColor(
+			executionContext,
+			expectedTurn,
+			receivingPlayer,
+			hintedColor))
+}
+
+// TakeTurnByHintingIndex calls the function of the wrapped executor and then records
+// the new state for spectators if the turn was successfully taken.
+func (spectatedExecutor *executorRecordingForSpectators) TakeTurnByHintingIndex(
+	executionContext context.Context,
+	expectedTurn int,
+	receivingPlayer string,
+	hintedIndex int) error {
+	return spectatedExecutor.recordIfSuccessful(
+		executionContext,
+		spectatedExecutor.wrappedExecutor.TakeTurnByHintingIndex(
+			executionContext,
+			expectedTurn,
+			receivingPlayer,
+			hintedIndex))
+}
+
+// recordIfSuccessful returns the given error from taking a turn if it is not nil, and
+// otherwise notifies the subscribers to the changes of the game, records the new state
+// of the game for spectators, and records the game in the history of finished games if
+// the turn finished it. The turn has already been stored by then, so a failure to
+// record it for spectators or in the history is only logged, as returning an error
+// would lead the player to try again to take a turn which was actually taken.
+func (spectatedExecutor *executorRecordingForSpectators) recordIfSuccessful(
+	executionContext context.Context,
+	errorFromTurn error) error {
+	if errorFromTurn != nil {
+		return errorFromTurn
+	}
+
+	spectatedExecutor.gameCollection.changeNotifier.notify(spectatedExecutor.gameName)
+
+	errorFromSnapshot :=
+		spectatedExecutor.gameCollection.recordSnapshotAfterTurn(
+			executionContext,
+			spectatedExecutor.gameName)
+
+	if errorFromSnapshot != nil {
+		log.Printf(
+			"Turn was taken in game %v but could not be recorded for spectators: %v",
+			spectatedExecutor.gameName,
+			errorFromSnapshot)
+	}
+
+	errorFromHistory :=
+		spectatedExecutor.gameCollection.recordHistoryIfFinished(
+			executionContext,
+			spectatedExecutor.gameName)
+
+	if errorFromHistory != nil {
+		log.Printf(
+			"Turn was taken in game %v but could not be recorded in the history: %v",
+			spectatedExecutor.gameName,
+			errorFromHistory)
+	}
+
+	return nil
+}
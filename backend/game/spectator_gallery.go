@@ -0,0 +1,115 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+)
+
+// SpectatorGallery encapsulates what is needed for non-participants to spectate a
+// game: whether the creator of the game allows spectating at all, how many turns
+// the neutral view (where all hands are visible) should lag behind the actual game,
+// the snapshots of the game from which the delayed neutral view is taken, and the
+// chat log for the spectators, which is separate from the chat log of the players.
+// It has to be an exported struct with only exported data members so that it
+// serializes easily.
+type SpectatorGallery struct {
+	GameName            string
+	SpectatingIsAllowed bool
+	DelayInTurns        int
+	ChatMessageLog      []message.FromPlayer
+	DelayedSnapshots    []NeutralSnapshot
+}
+
+// NewClosedGallery creates a gallery for the given game which does not allow
+// spectating.
+func NewClosedGallery(gameName string) SpectatorGallery {
+	return SpectatorGallery{
+		GameName:            gameName,
+		SpectatingIsAllowed: false,
+		DelayInTurns:        0,
+		ChatMessageLog:      []message.FromPlayer{},
+		DelayedSnapshots:    []NeutralSnapshot{},
+	}
+}
+
+// SetSpectating sets whether spectating is allowed and how many turns the neutral
+// view should lag behind the game, or returns an error if the delay is negative.
+// Any stored snapshots are discarded if spectating is not allowed or if there is
+// no delay.
+func (gallery *SpectatorGallery) SetSpectating(isAllowed bool, delayInTurns int) error {
+	if delayInTurns < 0 {
+		return fmt.Errorf("Delay for spectators cannot be negative (was %v)", delayInTurns)
+	}
+
+	gallery.SpectatingIsAllowed = isAllowed
+	gallery.DelayInTurns = delayInTurns
+
+	if !isAllowed || (delayInTurns == 0) {
+		gallery.DelayedSnapshots = []NeutralSnapshot{}
+	}
+
+	return nil
+}
+
+// AddChatMessage appends the given message to the chat log of the spectators,
+// dropping the oldest messages so that there are no more than the given number.
+func (gallery *SpectatorGallery) AddChatMessage(
+	chatMessage message.FromPlayer,
+	chatLogLength int) {
+	gallery.ChatMessageLog = append(gallery.ChatMessageLog, chatMessage)
+
+	numberOfMessages := len(gallery.ChatMessageLog)
+	if numberOfMessages > chatLogLength {
+		gallery.ChatMessageLog =
+			gallery.ChatMessageLog[numberOfMessages-chatLogLength:]
+	}
+}
+
+// AddSnapshot stores the given snapshot, replacing any snapshot from the same turn,
+// and drops any snapshots which are too old to be needed for the delay.
+func (gallery *SpectatorGallery) AddSnapshot(newSnapshot NeutralSnapshot) {
+	oldestTurnToKeep := newSnapshot.Turn - gallery.DelayInTurns
+	keptSnapshots := make([]NeutralSnapshot, 0, gallery.DelayInTurns+1)
+
+	// We keep the newest snapshot which is at least as old as the delay requires,
+	// so that there is always a snapshot to show once the game has gone on for
+	// long enough.
+	newestTooOldIndex := -1
+	for snapshotIndex, storedSnapshot := range gallery.DelayedSnapshots {
+		if storedSnapshot.Turn < oldestTurnToKeep {
+			newestTooOldIndex = snapshotIndex
+		}
+	}
+
+	for snapshotIndex, storedSnapshot := range gallery.DelayedSnapshots {
+		isNeeded :=
+			(snapshotIndex == newestTooOldIndex) ||
+				(storedSnapshot.Turn >= oldestTurnToKeep)
+
+		if isNeeded && (storedSnapshot.Turn != newSnapshot.Turn) {
+			keptSnapshots = append(keptSnapshots, storedSnapshot)
+		}
+	}
+
+	gallery.DelayedSnapshots = append(keptSnapshots, newSnapshot)
+}
+
+// DelayedSnapshot returns the newest stored snapshot which lags behind the given
+// current turn by at least the delay of the gallery, along with true, or an empty
+// snapshot along with false if there is no such snapshot.
+func (gallery *SpectatorGallery) DelayedSnapshot(currentTurn int) (NeutralSnapshot, bool) {
+	latestAllowedTurn := currentTurn - gallery.DelayInTurns
+	foundSnapshot := NeutralSnapshot{}
+	isFound := false
+
+	for _, storedSnapshot := range gallery.DelayedSnapshots {
+		if (storedSnapshot.Turn <= latestAllowedTurn) &&
+			(!isFound || (storedSnapshot.Turn > foundSnapshot.Turn)) {
+			foundSnapshot = storedSnapshot
+			isFound = true
+		}
+	}
+
+	return foundSnapshot, isFound
+}
@@ -0,0 +1,436 @@
+package game_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/card"
+	"github.com/benoleary/ilutulestikud/backend/game/persister"
+)
+
+func TestSpectatingNeedsPermissionFromCreator(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			participantNames := playerNamesAvailableInTest[:2]
+			spectatorName := playerNamesAvailableInTest[2]
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					participantNames)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			_, errorFromClosedView :=
+				gameCollection.ViewAsSpectator(context.Background(), gameName, spectatorName)
+
+			if errorFromClosedView == nil {
+				unitTest.Fatalf("ViewAsSpectator(...) before spectating was allowed did not produce expected error")
+			}
+
+			errorFromNonCreator :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					gameName,
+					participantNames[1],
+					true,
+					0)
+
+			if errorFromNonCreator == nil {
+				unitTest.Fatalf("SetSpectating(...) by non-creator did not produce expected error")
+			}
+
+			errorFromNegativeDelay :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					gameName,
+					participantNames[0],
+					true,
+					-1)
+
+			if errorFromNegativeDelay == nil {
+				unitTest.Fatalf("SetSpectating(...) with negative delay did not produce expected error")
+			}
+
+			errorFromSet :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					gameName,
+					participantNames[0],
+					true,
+					0)
+
+			if errorFromSet != nil {
+				unitTest.Fatalf("SetSpectating(...) produced unexpected error %v", errorFromSet)
+			}
+
+			neutralSnapshot, errorFromView :=
+				gameCollection.ViewAsSpectator(context.Background(), gameName, spectatorName)
+
+			if errorFromView != nil {
+				unitTest.Fatalf("ViewAsSpectator(...) produced unexpected error %v", errorFromView)
+			}
+
+			if len(neutralSnapshot.HandsInTurnOrder) != len(participantNames) {
+				unitTest.Fatalf(
+					"ViewAsSpectator(...) gave hands %v, expected one for each of %v",
+					neutralSnapshot.HandsInTurnOrder,
+					participantNames)
+			}
+
+			for playerIndex, spectatedHand := range neutralSnapshot.HandsInTurnOrder {
+				if spectatedHand.PlayerName != participantNames[playerIndex] {
+					unitTest.Fatalf(
+						"ViewAsSpectator(...) gave hand of %v at index %v, expected %v",
+						spectatedHand.PlayerName,
+						playerIndex,
+						participantNames[playerIndex])
+				}
+			}
+
+			_, errorFromParticipant :=
+				gameCollection.ViewAsSpectator(context.Background(), gameName, participantNames[1])
+
+			if errorFromParticipant == nil {
+				unitTest.Fatalf("ViewAsSpectator(...) by participant did not produce expected error")
+			}
+
+			_, errorFromUnregistered :=
+				gameCollection.ViewAsSpectator(context.Background(), gameName, "Not A Registered Player")
+
+			if errorFromUnregistered == nil {
+				unitTest.Fatalf("ViewAsSpectator(...) by unregistered player did not produce expected error")
+			}
+
+			seatView, errorFromSeatView :=
+				gameCollection.ViewAsSpectatorFromSeat(
+					context.Background(),
+					gameName,
+					spectatorName,
+					participantNames[1])
+
+			if errorFromSeatView != nil {
+				unitTest.Fatalf("ViewAsSpectatorFromSeat(...) produced unexpected error %v", errorFromSeatView)
+			}
+
+			_, _, errorFromOwnHand := seatView.VisibleHand(participantNames[1])
+
+			if errorFromOwnHand == nil {
+				unitTest.Fatalf("View from seat of participant allowed the participant's own hand to be seen")
+			}
+
+			_, errorFromNonParticipantSeat :=
+				gameCollection.ViewAsSpectatorFromSeat(
+					context.Background(),
+					gameName,
+					spectatorName,
+					playerNamesAvailableInTest[3])
+
+			if errorFromNonParticipantSeat == nil {
+				unitTest.Fatalf("ViewAsSpectatorFromSeat(...) for non-participant did not produce expected error")
+			}
+		})
+	}
+}
+
+func TestSpectatorViewIsDelayed(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			participantNames := playerNamesAvailableInTest[:2]
+			spectatorName := playerNamesAvailableInTest[2]
+			delayInTurns := 2
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					participantNames)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			errorFromSet :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					gameName,
+					participantNames[0],
+					true,
+					delayInTurns)
+
+			if errorFromSet != nil {
+				unitTest.Fatalf("SetSpectating(...) produced unexpected error %v", errorFromSet)
+			}
+
+			initialView, errorFromInitialView :=
+				gameCollection.ViewState(context.Background(), gameName, participantNames[1])
+
+			if errorFromInitialView != nil {
+				unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromInitialView)
+			}
+
+			initialHandOfCreator, _, errorFromInitialHand :=
+				initialView.VisibleHand(participantNames[0])
+
+			if errorFromInitialHand != nil {
+				unitTest.Fatalf("VisibleHand(...) produced unexpected error %v", errorFromInitialHand)
+			}
+
+			// The game has not yet progressed far enough to show anything with the delay.
+			_, errorFromEarlyView :=
+				gameCollection.ViewAsSpectator(context.Background(), gameName, spectatorName)
+
+			if errorFromEarlyView == nil {
+				unitTest.Fatalf("ViewAsSpectator(...) before enough turns did not produce expected error")
+			}
+
+			// Following the game from a seat would show the current hands of the other
+			// participants without the delay.
+			for _, participantName := range participantNames {
+				_, errorFromSeatView :=
+					gameCollection.ViewAsSpectatorFromSeat(
+						context.Background(),
+						gameName,
+						spectatorName,
+						participantName)
+
+				if errorFromSeatView == nil {
+					unitTest.Fatalf(
+						"ViewAsSpectatorFromSeat(..., %v) with delay did not produce expected error",
+						participantName)
+				}
+			}
+
+			for turnIndex := 0; turnIndex < delayInTurns; turnIndex++ {
+				actingPlayer := participantNames[turnIndex%len(participantNames)]
+				actionExecutor, errorFromExecutor :=
+					gameCollection.ExecuteAction(context.Background(), gameName, actingPlayer)
+
+				if errorFromExecutor != nil {
+					unitTest.Fatalf("ExecuteAction(...) produced unexpected error %v", errorFromExecutor)
+				}
+
+				errorFromDiscard :=
+					actionExecutor.TakeTurnByDiscarding(
+						context.Background(),
+						currentTurn(unitTest, gameCollection, gameName, actingPlayer),
+						0)
+
+				if errorFromDiscard != nil {
+					unitTest.Fatalf("TakeTurnByDiscarding(...) produced unexpected error %v", errorFromDiscard)
+				}
+			}
+
+			delayedSnapshot, errorFromDelayedView :=
+				gameCollection.ViewAsSpectator(context.Background(), gameName, spectatorName)
+
+			if errorFromDelayedView != nil {
+				unitTest.Fatalf("ViewAsSpectator(...) produced unexpected error %v", errorFromDelayedView)
+			}
+
+			if delayedSnapshot.Turn != 1 {
+				unitTest.Fatalf(
+					"ViewAsSpectator(...) showed turn %v, expected 1",
+					delayedSnapshot.Turn)
+			}
+
+			if len(delayedSnapshot.ActionLog) != len(initialView.ActionLog()) {
+				unitTest.Fatalf(
+					"ViewAsSpectator(...) showed action log %v, expected %v",
+					delayedSnapshot.ActionLog,
+					initialView.ActionLog())
+			}
+
+			creatorHand := delayedSnapshot.HandsInTurnOrder[0]
+			spectatedCards := make([]card.Defined, len(creatorHand.HandCards))
+			for cardIndex, cardInHand := range creatorHand.HandCards {
+				spectatedCards[cardIndex] = cardInHand.Defined
+			}
+
+			if creatorHand.PlayerName != participantNames[0] ||
+				!reflect.DeepEqual(spectatedCards, initialHandOfCreator) {
+				unitTest.Fatalf(
+					"ViewAsSpectator(...) showed hand %v, expected initial hand %v of %v",
+					creatorHand,
+					initialHandOfCreator,
+					participantNames[0])
+			}
+		})
+	}
+}
+
+func TestTurnIsTakenEvenIfSnapshotForSpectatorsFails(unitTest *testing.T) {
+	mockProvider := NewMockPlayerProvider(playerNamesAvailableInTest)
+	snapshotPersister :=
+		&failingSnapshotPersister{SpectatorPersister: persister.NewSpectatorInMemory()}
+	gameCollection :=
+		game.NewCollection(
+			persister.NewInMemory(),
+			persister.NewLobbyInMemory(),
+			persister.NewSeriesInMemory(),
+			snapshotPersister,
+			persister.NewHistoryInMemory(),
+			logLengthForTest,
+			mockProvider,
+			mockProvider)
+	gameName := "Test game"
+	participantNames := playerNamesAvailableInTest[:2]
+
+	errorFromAdd :=
+		gameCollection.AddNew(
+			context.Background(),
+			gameName,
+			testRuleset,
+			participantNames)
+
+	if errorFromAdd != nil {
+		unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+	}
+
+	// Setting a delay records an initial snapshot, which fails, but the delay is set.
+	_ =
+		gameCollection.SetSpectating(
+			context.Background(),
+			gameName,
+			participantNames[0],
+			true,
+			1)
+
+	turnBeforeDiscard :=
+		currentTurn(unitTest, gameCollection, gameName, participantNames[0])
+
+	actionExecutor, errorFromExecutor :=
+		gameCollection.ExecuteAction(context.Background(), gameName, participantNames[0])
+
+	if errorFromExecutor != nil {
+		unitTest.Fatalf("ExecuteAction(...) produced unexpected error %v", errorFromExecutor)
+	}
+
+	numberOfSnapshotsBeforeTurn := len(snapshotPersister.ArgumentsForAddSnapshot)
+
+	errorFromDiscard :=
+		actionExecutor.TakeTurnByDiscarding(context.Background(), turnBeforeDiscard, 0)
+
+	if errorFromDiscard != nil {
+		unitTest.Fatalf(
+			"TakeTurnByDiscarding(...) produced error %v although the turn was taken",
+			errorFromDiscard)
+	}
+
+	if len(snapshotPersister.ArgumentsForAddSnapshot) != numberOfSnapshotsBeforeTurn+1 {
+		unitTest.Fatalf(
+			"TakeTurnByDiscarding(...) tried to add snapshots for %v, expected one more than %v",
+			snapshotPersister.ArgumentsForAddSnapshot,
+			numberOfSnapshotsBeforeTurn)
+	}
+
+	turnAfterDiscard :=
+		currentTurn(unitTest, gameCollection, gameName, participantNames[1])
+
+	if turnAfterDiscard != turnBeforeDiscard+1 {
+		unitTest.Fatalf(
+			"Game is at turn %v after discarding, expected %v",
+			turnAfterDiscard,
+			turnBeforeDiscard+1)
+	}
+}
+
+func TestSpectatorChatIsSeparate(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			participantNames := playerNamesAvailableInTest[:2]
+			spectatorName := playerNamesAvailableInTest[2]
+			chatMessage := "Nice play!"
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					participantNames)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			errorFromClosedChat :=
+				gameCollection.RecordSpectatorChatMessage(
+					context.Background(),
+					gameName,
+					spectatorName,
+					chatMessage)
+
+			if errorFromClosedChat == nil {
+				unitTest.Fatalf("RecordSpectatorChatMessage(...) before spectating was allowed did not produce expected error")
+			}
+
+			errorFromSet :=
+				gameCollection.SetSpectating(
+					context.Background(),
+					gameName,
+					participantNames[0],
+					true,
+					0)
+
+			if errorFromSet != nil {
+				unitTest.Fatalf("SetSpectating(...) produced unexpected error %v", errorFromSet)
+			}
+
+			errorFromChat :=
+				gameCollection.RecordSpectatorChatMessage(
+					context.Background(),
+					gameName,
+					spectatorName,
+					chatMessage)
+
+			if errorFromChat != nil {
+				unitTest.Fatalf("RecordSpectatorChatMessage(...) produced unexpected error %v", errorFromChat)
+			}
+
+			spectatorChatLog, errorFromLog :=
+				gameCollection.SpectatorChatLog(context.Background(), gameName, spectatorName)
+
+			if errorFromLog != nil {
+				unitTest.Fatalf("SpectatorChatLog(...) produced unexpected error %v", errorFromLog)
+			}
+
+			if len(spectatorChatLog) != 1 ||
+				spectatorChatLog[0].PlayerName != spectatorName ||
+				spectatorChatLog[0].MessageText != chatMessage {
+				unitTest.Fatalf(
+					"SpectatorChatLog(...) gave %v, expected single message %v from %v",
+					spectatorChatLog,
+					chatMessage,
+					spectatorName)
+			}
+
+			participantView, errorFromView :=
+				gameCollection.ViewState(context.Background(), gameName, participantNames[0])
+
+			if errorFromView != nil {
+				unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromView)
+			}
+
+			for _, participantMessage := range participantView.ChatLog() {
+				if participantMessage.PlayerName == spectatorName {
+					unitTest.Fatalf(
+						"Chat log of participants %v contains message from spectator",
+						participantView.ChatLog())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benoleary/ilutulestikud/backend/game/card"
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/player"
+)
+
+// SpectatedHand is a struct to keep the hand of a player, with both the actual
+// cards and the knowledge which the player has about them, along with the
+// identifier, display name, and chat color of the player. The display name is
+// empty for snapshots which were stored before players had display names
+// separate from their identifiers.
+type SpectatedHand struct {
+	PlayerName        string
+	PlayerDisplayName string
+	PlayerColor       string
+	HandCards         []card.InHand
+}
+
+// NeutralSnapshot encapsulates the information about a game at a particular turn
+// which a spectator who is not sitting in the seat of any participant may see,
+// which includes the hands of all the players. It has to be an exported struct
+// with only exported data members so that it can be stored to show to spectators
+// later with a delay.
+type NeutralSnapshot struct {
+	GameName                string
+	RulesetIdentifier       int
+	Turn                    int
+	GameIsFinished          bool
+	Score                   int
+	NumberOfReadyHints      int
+	NumberOfMistakesMade    int
+	DeckSize                int
+	TurnsTakenWithEmptyDeck int
+	ActionLog               []message.FromPlayer
+	PlayedCards             [][]card.Defined
+	DiscardedCards          []card.Defined
+	HandsInTurnOrder        []SpectatedHand
+}
+
+// NeutralSnapshotOfState creates a NeutralSnapshot of the given game state as it
+// is at the moment, with the hands of the players in the order of their next turns.
+// It returns an error if any of the participants cannot be found by the given
+// player provider.
+func NeutralSnapshotOfState(
+	creationContext context.Context,
+	stateOfGame ReadonlyState,
+	playerProvider ReadonlyPlayerProvider) (NeutralSnapshot, error) {
+	participantsInGame := stateOfGame.PlayerNames()
+	numberOfPlayers := len(participantsInGame)
+
+	// A view for a player with an empty name can see the hands of every
+	// participant.
+	neutralView :=
+		createViewWithoutPlayerMap(
+			stateOfGame,
+			numberOfPlayers,
+			participantsInGame,
+			"")
+
+	playerStates := make(map[string]player.ReadonlyState, numberOfPlayers)
+
+	for _, participantName := range participantsInGame {
+		participantState, errorFromGet :=
+			playerProvider.Get(creationContext, participantName)
+
+		if errorFromGet != nil {
+			return NeutralSnapshot{}, fmt.Errorf(
+				"Error when retrieving participant %v of game %v",
+				participantName,
+				stateOfGame.Name())
+		}
+
+		playerStates[participantName] = participantState
+	}
+
+	neutralView.playerStates = playerStates
+
+	playersInTurnOrder, _, _ := neutralView.CurrentTurnOrder()
+	handsInTurnOrder := make([]SpectatedHand, numberOfPlayers)
+
+	for playerIndex, playerName := range playersInTurnOrder {
+		visibleHand, playerColor, errorFromVisibleHand :=
+			neutralView.VisibleHand(playerName)
+
+		if errorFromVisibleHand != nil {
+			return NeutralSnapshot{}, errorFromVisibleHand
+		}
+
+		inferredHand, errorFromInferredHand :=
+			neutralView.KnowledgeOfOwnHand(playerName)
+
+		if errorFromInferredHand != nil {
+			return NeutralSnapshot{}, errorFromInferredHand
+		}
+
+		handCards := make([]card.InHand, len(visibleHand))
+
+		for cardIndex, visibleCard := range visibleHand {
+			handCards[cardIndex] = card.InHand{Defined: visibleCard}
+
+			if cardIndex < len(inferredHand) {
+				handCards[cardIndex].Inferred = inferredHand[cardIndex]
+			}
+		}
+
+		handsInTurnOrder[playerIndex] = SpectatedHand{
+			PlayerName:        playerName,
+			PlayerDisplayName: neutralView.ParticipantDisplayName(playerName),
+			PlayerColor:       playerColor,
+			HandCards:         handCards,
+		}
+	}
+
+	return NeutralSnapshot{
+		GameName:                stateOfGame.Name(),
+		RulesetIdentifier:       stateOfGame.Ruleset().BackendIdentifier(),
+		Turn:                    stateOfGame.Turn(),
+		GameIsFinished:          neutralView.GameIsFinished(),
+		Score:                   neutralView.Score(),
+		NumberOfReadyHints:      stateOfGame.NumberOfReadyHints(),
+		NumberOfMistakesMade:    stateOfGame.NumberOfMistakesMade(),
+		DeckSize:                stateOfGame.DeckSize(),
+		TurnsTakenWithEmptyDeck: stateOfGame.TurnsTakenWithEmptyDeck(),
+		ActionLog:               stateOfGame.ActionLog(),
+		PlayedCards:             neutralView.PlayedCards(),
+		DiscardedCards:          neutralView.DiscardedCards(),
+		HandsInTurnOrder:        handsInTurnOrder,
+	}, nil
+}
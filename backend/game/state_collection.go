@@ -12,21 +12,41 @@ import (
 // StateCollection wraps around a game.StatePersister to encapsulate logic acting on
 // the functions of the interface.
 type StateCollection struct {
-	statePersister StatePersister
-	chatLogLength  int
-	playerProvider ReadonlyPlayerProvider
+	statePersister     StatePersister
+	lobbyPersister     LobbyPersister
+	seriesPersister    SeriesPersister
+	spectatorPersister SpectatorPersister
+	historyPersister   HistoryPersister
+	chatLogLength      int
+	playerProvider     ReadonlyPlayerProvider
+	colorSeparator     ChatColorSeparator
+	changeNotifier     *changeNotifier
 }
 
-// NewCollection creates a new StateCollection around the given StatePersister and list
-// of rulesets.
+// NewCollection creates a new StateCollection around the given StatePersister,
+// LobbyPersister, SeriesPersister, SpectatorPersister, and HistoryPersister, with
+// no subscriptions to the changes of any game. The given ChatColorSeparator is used
+// to show the participants of each game in chat colors which can be told apart. It
+// may be nil, in which case the participants are shown in their own colors.
 func NewCollection(
 	statePersister StatePersister,
+	lobbyPersister LobbyPersister,
+	seriesPersister SeriesPersister,
+	spectatorPersister SpectatorPersister,
+	historyPersister HistoryPersister,
 	chatLogLength int,
-	playerProvider ReadonlyPlayerProvider) *StateCollection {
+	playerProvider ReadonlyPlayerProvider,
+	colorSeparator ChatColorSeparator) *StateCollection {
 	return &StateCollection{
-		statePersister: statePersister,
-		chatLogLength:  chatLogLength,
-		playerProvider: playerProvider,
+		statePersister:     statePersister,
+		lobbyPersister:     lobbyPersister,
+		seriesPersister:    seriesPersister,
+		spectatorPersister: spectatorPersister,
+		historyPersister:   historyPersister,
+		chatLogLength:      chatLogLength,
+		playerProvider:     playerProvider,
+		colorSeparator:     colorSeparator,
+		changeNotifier:     newChangeNotifier(),
 	}
 }
 
@@ -43,87 +63,1137 @@ func (gameCollection *StateCollection) ViewState(
 	if errorFromGet != nil {
 		gameDoesNotExistError :=
 			fmt.Errorf(
-				"Could not find game %v (%v), cannot be viewed by player %v",
+				"Could not find game %v (%w), cannot be viewed by player %v",
 				gameName,
 				errorFromGet,
 				playerName)
 		return nil, gameDoesNotExistError
 	}
 
-	return ViewOnStateForPlayer(
+	return gameCollection.viewOnStateForPlayer(
 		executionContext,
 		gameState.Read(),
-		gameCollection.playerProvider,
 		playerName)
 }
 
-// ViewAllWithPlayer wraps every read-only state given by the persister for the given player
-// in a view. It returns an error if there is an error in creating any of the player views.
-// The views are ordered by creation timestamp, oldest first.
-func (gameCollection *StateCollection) ViewAllWithPlayer(
+// ViewAllWithPlayer wraps every read-only state given by the persister for the given player
+// in a view. It returns an error if there is an error in creating any of the player views.
+// The views are ordered by creation timestamp, oldest first.
+func (gameCollection *StateCollection) ViewAllWithPlayer(
+	executionContext context.Context,
+	playerName string) ([]ViewForPlayer, error) {
+	gameStates, errorFromReadAll :=
+		gameCollection.statePersister.ReadAllWithPlayer(executionContext, playerName)
+	if errorFromReadAll != nil {
+		return nil, errorFromReadAll
+	}
+
+	numberOfGames := len(gameStates)
+
+	sort.Sort(ByCreationTime(gameStates))
+
+	playerViews := make([]ViewForPlayer, numberOfGames)
+
+	for gameIndex := 0; gameIndex < numberOfGames; gameIndex++ {
+		playerView, participantError :=
+			gameCollection.viewOnStateForPlayer(
+				executionContext,
+				gameStates[gameIndex],
+				playerName)
+
+		if participantError != nil {
+			overallError :=
+				fmt.Errorf(
+					"When trying to wrap views around read-only game states, encountered error %v",
+					participantError)
+			return nil, overallError
+		}
+
+		playerViews[gameIndex] = playerView
+	}
+
+	return playerViews, nil
+}
+
+// AddNew prepares a new shuffled deck using a random seed taken from the given
+// collection, and uses it to create a new game in the given collection from the
+// given definition, created by the first of the given players. It returns an error
+// if a game with the given name already exists, or if the definition includes
+// invalid players.
+func (gameCollection *StateCollection) AddNew(
+	executionContext context.Context,
+	gameName string,
+	gameRuleset Ruleset,
+	playerNames []string) error {
+	return gameCollection.addNewForCreator(
+		executionContext,
+		gameName,
+		firstPlayerAsCreator(playerNames),
+		gameRuleset,
+		playerNames)
+}
+
+// AddNewWithGivenDeck creates a new game in the given collection from the given
+// definition and the given deck, created by the first of the given players. It
+// returns an error if a game with the given name already exists, or if the
+// definition includes invalid players.
+func (gameCollection *StateCollection) AddNewWithGivenDeck(
+	executionContext context.Context,
+	gameName string,
+	gameRuleset Ruleset,
+	playerNames []string,
+	initialDeck []card.Defined) error {
+	return gameCollection.addNewWithGivenDeckForCreator(
+		executionContext,
+		gameName,
+		firstPlayerAsCreator(playerNames),
+		gameRuleset,
+		playerNames,
+		initialDeck)
+}
+
+// ExecuteAction finds the given game and wraps it in an executor for the given
+// player, or returns an error.
+func (gameCollection *StateCollection) ExecuteAction(
+	executionContext context.Context,
+	gameName string,
+	playerName string) (ExecutorForPlayer, error) {
+	_, playerIdentificationError :=
+		gameCollection.playerProvider.Get(executionContext, playerName)
+
+	if playerIdentificationError != nil {
+		return nil, playerIdentificationError
+	}
+
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet != nil {
+		errorWrappingErrorFromGet :=
+			fmt.Errorf(
+				"Could not find game %v (%w), cannot execute action for player %v",
+				gameName,
+				errorFromGet,
+				playerName)
+
+		return nil, errorWrappingErrorFromGet
+	}
+
+	// The acting player is fetched again so that the messages which record the
+	// actions are in the color in which the player is shown in this game.
+	providerForGame, errorFromColors :=
+		gameCollection.providerForGame(executionContext, gameState.Read().PlayerNames())
+
+	if errorFromColors != nil {
+		return nil, errorFromColors
+	}
+
+	actingPlayer, playerIdentificationError :=
+		providerForGame.Get(executionContext, playerName)
+
+	if playerIdentificationError != nil {
+		return nil, playerIdentificationError
+	}
+
+	actionExecutor, errorFromExecutor :=
+		ExecutorOfActionsForPlayer(executionContext, gameState, actingPlayer)
+
+	if errorFromExecutor != nil {
+		return nil, errorFromExecutor
+	}
+
+	return &executorRecordingForSpectators{
+		wrappedExecutor: actionExecutor,
+		gameCollection:  gameCollection,
+		gameName:        gameState.Read().Name(),
+	}, nil
+}
+
+// SubscribeToChanges returns a channel which receives a notice whenever the given game
+// is changed through an executor from this collection or is deleted, along with the
+// function which ends the subscription, which should always be called once the notices
+// are no longer wanted. The channel holds at most one notice which has not yet been
+// received, so several changes in quick succession may arrive as a single notice. The
+// subscription is to the name under which the game is stored, so that it matches the
+// notices however the name was typed. It does not fail if the game does not exist, as
+// a subscriber which reads the game after every notice finds out then.
+func (gameCollection *StateCollection) SubscribeToChanges(
+	executionContext context.Context,
+	gameName string) (<-chan ChangeNotice, func()) {
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet == nil {
+		gameName = gameState.Read().Name()
+	}
+
+	return gameCollection.changeNotifier.subscribe(gameName)
+}
+
+// RemoveGameFromListForPlayer calls the RemoveGameFromListForPlayer of the
+// internal persistence store.
+func (gameCollection *StateCollection) RemoveGameFromListForPlayer(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	return gameCollection.statePersister.RemoveGameFromListForPlayer(
+		executionContext,
+		gameName,
+		playerName)
+}
+
+// Delete calls the Delete of the internal persistence store, notifies the subscribers
+// to the changes of the game, and then removes the spectator gallery of the game. The
+// subscribers and the gallery are found by the name under which the game was stored,
+// if the game could be read before it was deleted.
+func (gameCollection *StateCollection) Delete(
+	executionContext context.Context,
+	gameName string) error {
+	storedName := gameName
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet == nil {
+		storedName = gameState.Read().Name()
+	}
+
+	errorFromDelete :=
+		gameCollection.statePersister.Delete(executionContext, gameName)
+
+	if errorFromDelete != nil {
+		return errorFromDelete
+	}
+
+	gameCollection.changeNotifier.notify(storedName)
+
+	return gameCollection.spectatorPersister.DeleteGallery(executionContext, storedName)
+}
+
+// Creator returns the name of the player who created the given game, such as the
+// host of the lobby from which it was dealt, or an error if the game does not exist.
+// The creator of a rematch is the creator of the game of which it is a rematch, even
+// though the turn order of the rematch is rotated.
+func (gameCollection *StateCollection) Creator(
+	executionContext context.Context,
+	gameName string) (string, error) {
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet != nil {
+		return "", fmt.Errorf(
+			"Could not find game %v (%w), cannot determine its creator",
+			gameName,
+			errorFromGet)
+	}
+
+	return gameState.Read().CreatorName(), nil
+}
+
+// Rematch creates a new game with the same participants, ruleset, and creator as the
+// given finished game, with the turn order rotated so that the player who went second
+// in the given game goes first in the new game, and links the new game to the given
+// game as the next game in their series. It returns the name of the new game, or an
+// error if the given player is not a participant of the given game, if the given
+// game is not yet finished, or if there is already a rematch of the given game.
+func (gameCollection *StateCollection) Rematch(
+	executionContext context.Context,
+	gameName string,
+	playerName string) (string, error) {
+	finishedGame, errorFromView :=
+		gameCollection.ViewState(executionContext, gameName, playerName)
+
+	if errorFromView != nil {
+		return "", errorFromView
+	}
+
+	// The series and the game are found by the name under which the game is stored,
+	// which may differ from the given name in spacing or Unicode form.
+	storedName := finishedGame.GameName()
+
+	if !finishedGame.GameIsFinished() {
+		return "", ErrGameNotFinished.Errorf("Game %v is not yet finished", storedName)
+	}
+
+	existingSeries, isInSeries, errorFromSeries :=
+		gameCollection.seriesPersister.ReadSeriesContainingGame(
+			executionContext,
+			storedName)
+
+	if errorFromSeries != nil {
+		return "", errorFromSeries
+	}
+
+	if !isInSeries {
+		existingSeries = NewSeries([]string{storedName})
+	}
+
+	if existingSeries.LastGameName() != storedName {
+		return "", fmt.Errorf(
+			"Game %v already has a rematch in series %v",
+			storedName,
+			existingSeries.SeriesName)
+	}
+
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, storedName)
+
+	if errorFromGet != nil {
+		return "", errorFromGet
+	}
+
+	previousPlayers := gameState.Read().PlayerNames()
+	numberOfPlayers := len(previousPlayers)
+	rotatedPlayers := make([]string, numberOfPlayers)
+
+	for playerIndex := 0; playerIndex < numberOfPlayers; playerIndex++ {
+		rotatedPlayers[playerIndex] =
+			previousPlayers[(playerIndex+1)%numberOfPlayers]
+	}
+
+	rematchName := existingSeries.NameForRematch()
+
+	errorFromAdd :=
+		gameCollection.addNewForCreator(
+			executionContext,
+			rematchName,
+			gameState.Read().CreatorName(),
+			gameState.Read().Ruleset(),
+			rotatedPlayers)
+
+	if errorFromAdd != nil {
+		return "", errorFromAdd
+	}
+
+	errorFromAppend :=
+		gameCollection.seriesPersister.AppendGameToSeries(
+			executionContext,
+			storedName,
+			rematchName)
+
+	if errorFromAppend != nil {
+		return "", errorFromAppend
+	}
+
+	return rematchName, nil
+}
+
+// ViewSeries returns views for the given player on all the games in the series which
+// contains the given game, in the order in which they were played. If the given game
+// is not part of a series, it is treated as a series of just itself. Games of the
+// series which have since been deleted are skipped. It returns an error if the given
+// player is not a participant of the games.
+func (gameCollection *StateCollection) ViewSeries(
+	executionContext context.Context,
+	gameName string,
+	playerName string) ([]ViewForPlayer, error) {
+	// The series is found by the name under which the given game is stored, which may
+	// differ from the given name in spacing or Unicode form.
+	givenGame, errorFromGivenGame :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGivenGame != nil {
+		return nil, errorFromGivenGame
+	}
+
+	storedName := givenGame.Read().Name()
+
+	gameSeries, isInSeries, errorFromSeries :=
+		gameCollection.seriesPersister.ReadSeriesContainingGame(
+			executionContext,
+			storedName)
+
+	if errorFromSeries != nil {
+		return nil, errorFromSeries
+	}
+
+	if !isInSeries {
+		gameSeries = NewSeries([]string{storedName})
+	}
+
+	playerViews := make([]ViewForPlayer, 0, len(gameSeries.GameNamesInOrder))
+
+	for _, gameInSeries := range gameSeries.GameNamesInOrder {
+		gameState, errorFromGet :=
+			gameCollection.statePersister.ReadAndWriteGame(executionContext, gameInSeries)
+
+		if errorFromGet != nil {
+			if gameInSeries == storedName {
+				return nil, errorFromGet
+			}
+
+			continue
+		}
+
+		playerView, errorFromView :=
+			gameCollection.viewOnStateForPlayer(
+				executionContext,
+				gameState.Read(),
+				playerName)
+
+		if errorFromView != nil {
+			return nil, errorFromView
+		}
+
+		playerViews = append(playerViews, playerView)
+	}
+
+	return playerViews, nil
+}
+
+// StatisticsForPlayer returns the statistics of the given player over all the finished
+// games which have been recorded with the player as a participant, counting the games
+// which the player still has in progress as played but not finished. Games which were
+// deleted before they finished are not counted. It returns an error if the player is
+// not registered.
+func (gameCollection *StateCollection) StatisticsForPlayer(
+	executionContext context.Context,
+	playerName string) (PlayerStatistics, error) {
+	_, errorFromPlayer :=
+		gameCollection.playerProvider.Get(executionContext, playerName)
+
+	if errorFromPlayer != nil {
+		return PlayerStatistics{}, errorFromPlayer
+	}
+
+	finishedGames, errorFromHistory :=
+		gameCollection.historyPersister.ReadFinishedGamesWithPlayer(
+			executionContext,
+			playerName)
+
+	if errorFromHistory != nil {
+		return PlayerStatistics{}, errorFromHistory
+	}
+
+	gameStates, errorFromReadAll :=
+		gameCollection.statePersister.ReadAllWithPlayer(executionContext, playerName)
+
+	if errorFromReadAll != nil {
+		return PlayerStatistics{}, errorFromReadAll
+	}
+
+	// The finished games which the player has not yet left are already counted
+	// through their records.
+	numberOfGamesInProgress := 0
+
+	for _, gameState := range gameStates {
+		if !IsFinished(gameState) {
+			numberOfGamesInProgress++
+		}
+	}
+
+	return StatisticsForPlayer(playerName, finishedGames, numberOfGamesInProgress), nil
+}
+
+// Leaderboard returns the leaderboard of the finished games which match the given
+// criteria, with at most the given number of entries in each list, where a number which
+// is not positive means the default number, and numbers above the maximum are reduced to
+// the maximum. Only games which were played until they finished are ever recorded, so
+// games which were left unfinished or deleted before finishing are never ranked. The
+// display names of the players are filled in from the current states of the players,
+// with any player who is no longer registered shown by identifier. It returns an error
+// if the ruleset does not exist, if the number of players is not allowed for the ruleset,
+// or if the range of time is empty.
+func (gameCollection *StateCollection) Leaderboard(
+	executionContext context.Context,
+	gameCriteria FinishedGameCriteria,
+	maximumNumberOfEntries int) (Leaderboard, error) {
+	gameRuleset, errorFromRuleset :=
+		RulesetFromIdentifier(gameCriteria.RulesetIdentifier)
+
+	if errorFromRuleset != nil {
+		return Leaderboard{}, errorFromRuleset
+	}
+
+	if (gameCriteria.NumberOfPlayers < gameRuleset.MinimumNumberOfPlayers()) ||
+		(gameCriteria.NumberOfPlayers > gameRuleset.MaximumNumberOfPlayers()) {
+		return Leaderboard{}, fmt.Errorf(
+			"Ruleset %v does not allow games with %v players",
+			gameRuleset.FrontendDescription(),
+			gameCriteria.NumberOfPlayers)
+	}
+
+	if !gameCriteria.FinishedFrom.IsZero() &&
+		!gameCriteria.FinishedBefore.IsZero() &&
+		!gameCriteria.FinishedFrom.Before(gameCriteria.FinishedBefore) {
+		return Leaderboard{}, fmt.Errorf(
+			"Range of time from %v to before %v is empty",
+			gameCriteria.FinishedFrom,
+			gameCriteria.FinishedBefore)
+	}
+
+	if maximumNumberOfEntries <= 0 {
+		maximumNumberOfEntries = defaultNumberOfLeaderboardEntries
+	} else if maximumNumberOfEntries > maximumNumberOfLeaderboardEntries {
+		maximumNumberOfEntries = maximumNumberOfLeaderboardEntries
+	}
+
+	highestScoringGames, errorFromHighestScores :=
+		gameCollection.historyPersister.ReadHighestScoringGames(
+			executionContext,
+			gameCriteria,
+			maximumNumberOfEntries)
+
+	if errorFromHighestScores != nil {
+		return Leaderboard{}, errorFromHighestScores
+	}
+
+	fastestPerfectGames, errorFromFastestPerfects :=
+		gameCollection.historyPersister.ReadFastestPerfectGames(
+			executionContext,
+			gameCriteria,
+			maximumNumberOfEntries)
+
+	if errorFromFastestPerfects != nil {
+		return Leaderboard{}, errorFromFastestPerfects
+	}
+
+	gameLeaderboard :=
+		NewLeaderboard(gameCriteria, highestScoringGames, fastestPerfectGames)
+
+	gameCollection.fillDisplayNames(executionContext, gameLeaderboard.HighestScores)
+	gameCollection.fillDisplayNames(executionContext, gameLeaderboard.FastestPerfectGames)
+
+	return gameLeaderboard, nil
+}
+
+// AllLobbies returns all the lobbies of games which have not yet been dealt and
+// which any player may join (so excluding lobbies which are by invitation only),
+// ordered by creation timestamp, oldest first.
+func (gameCollection *StateCollection) AllLobbies(
+	executionContext context.Context) ([]Lobby, error) {
+	allLobbies, errorFromReadAll :=
+		gameCollection.lobbyPersister.ReadAllLobbies(executionContext)
+	if errorFromReadAll != nil {
+		return nil, errorFromReadAll
+	}
+
+	openLobbies := make([]Lobby, 0, len(allLobbies))
+
+	for _, pendingLobby := range allLobbies {
+		if !pendingLobby.IsByInvitationOnly() {
+			openLobbies = append(openLobbies, pendingLobby)
+		}
+	}
+
+	sort.Sort(ByLobbyCreationTime(openLobbies))
+
+	return openLobbies, nil
+}
+
+// AddLobby creates a lobby for a game with the given name and ruleset, with the
+// given number of seats, with the given host already taking the first seat. It
+// returns an error if the number of seats is not allowed by the ruleset, if the
+// host is not a registered player, if the name does not follow the naming rules,
+// or if a game or lobby with the same name according to those rules already
+// exists.
+func (gameCollection *StateCollection) AddLobby(
+	executionContext context.Context,
+	gameName string,
+	gameRuleset Ruleset,
+	numberOfSeats int,
+	hostName string) error {
+	if gameName == "" {
+		return fmt.Errorf("Game must have a name")
+	}
+
+	if numberOfSeats < gameRuleset.MinimumNumberOfPlayers() {
+		return fmt.Errorf(
+			"Game must have at least %v players",
+			gameRuleset.MinimumNumberOfPlayers())
+	}
+
+	if numberOfSeats > gameRuleset.MaximumNumberOfPlayers() {
+		return fmt.Errorf(
+			"Game must have no more than %v players",
+			gameRuleset.MaximumNumberOfPlayers())
+	}
+
+	_, errorFromHost :=
+		gameCollection.playerProvider.Get(executionContext, hostName)
+
+	if errorFromHost != nil {
+		return errorFromHost
+	}
+
+	errorFromName := gameCollection.errorIfGameNameIsTaken(executionContext, gameName)
+
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	return gameCollection.lobbyPersister.AddLobby(
+		executionContext,
+		NewLobby(gameName, gameRuleset, numberOfSeats, hostName))
+}
+
+// JoinLobby gives the given player an open seat in the lobby for the given game.
+// It returns an error if the player is not registered, or if the lobby does not
+// exist or has no open seat for the player. Joining a lobby which is by invitation
+// only counts as accepting the invitation, so that the game is dealt as AcceptInvitation
+// would deal it once the last invited player has joined.
+func (gameCollection *StateCollection) JoinLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	_, errorFromPlayer :=
+		gameCollection.playerProvider.Get(executionContext, playerName)
+
+	if errorFromPlayer != nil {
+		return errorFromPlayer
+	}
+
+	lobbyToJoin, errorFromRead :=
+		gameCollection.lobbyPersister.ReadLobby(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	if lobbyToJoin.IsByInvitationOnly() {
+		return gameCollection.AcceptInvitation(executionContext, gameName, playerName)
+	}
+
+	return gameCollection.lobbyPersister.AddPlayerToLobby(
+		executionContext,
+		gameName,
+		playerName)
+}
+
+// LeaveLobby frees the seat of the given player in the lobby for the given game.
+// If the player is the host, the whole lobby is closed.
+func (gameCollection *StateCollection) LeaveLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	lobbyToLeave, errorFromRead :=
+		gameCollection.lobbyPersister.ReadLobby(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	if lobbyToLeave.HostName == playerName {
+		return gameCollection.lobbyPersister.DeleteLobby(executionContext, gameName)
+	}
+
+	return gameCollection.lobbyPersister.RemovePlayerFromLobby(
+		executionContext,
+		gameName,
+		playerName)
+}
+
+// StartGameFromLobby deals a new game for the players seated in the lobby for the
+// given game and then removes the lobby. It returns an error if the given player is
+// not the host of the lobby or if there are still open seats.
+func (gameCollection *StateCollection) StartGameFromLobby(
+	executionContext context.Context,
+	gameName string,
+	hostName string) error {
+	lobbyToStart, errorFromRead :=
+		gameCollection.lobbyPersister.ReadLobby(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	if lobbyToStart.HostName != hostName {
+		return fmt.Errorf(
+			"Only the host %v can start game %v",
+			lobbyToStart.HostName,
+			gameName)
+	}
+
+	return gameCollection.dealGameFromLobby(executionContext, lobbyToStart)
+}
+
+// InviteToNew creates an invitation for each of the given players to a new game with
+// the given name and ruleset, with the first player as the host who is considered to
+// have already accepted. The game is dealt when all the invited players have accepted,
+// with the players in the given order. As the participants are already fixed, it
+// checks that they can be shown in chat colors which can be told apart, without
+// changing the colors of any player. It returns an error if the list of players is
+// not valid for the ruleset, if the name does not follow the naming rules, if a game
+// or lobby with the same name according to those rules already exists, or if no
+// colors can be found to tell the players apart.
+func (gameCollection *StateCollection) InviteToNew(
+	executionContext context.Context,
+	gameName string,
+	gameRuleset Ruleset,
+	playerNames []string) error {
+	if gameName == "" {
+		return fmt.Errorf("Game must have a name")
+	}
+
+	errorFromPlayerCheck :=
+		gameCollection.checkPlayerNames(executionContext, playerNames, gameRuleset)
+
+	if errorFromPlayerCheck != nil {
+		return errorFromPlayerCheck
+	}
+
+	errorFromName := gameCollection.errorIfGameNameIsTaken(executionContext, gameName)
+
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	_, errorFromColors :=
+		gameCollection.providerForGame(executionContext, playerNames)
+
+	if errorFromColors != nil {
+		return errorFromColors
+	}
+
+	return gameCollection.lobbyPersister.AddLobby(
+		executionContext,
+		NewInvitationLobby(gameName, gameRuleset, playerNames))
+}
+
+// ViewInvitationsForPlayer returns the lobbies of all the games to which the given
+// player has been invited but has not yet accepted the invitation, ordered by
+// creation timestamp, oldest first.
+func (gameCollection *StateCollection) ViewInvitationsForPlayer(
+	executionContext context.Context,
+	playerName string) ([]Lobby, error) {
+	allLobbies, errorFromReadAll :=
+		gameCollection.lobbyPersister.ReadAllLobbies(executionContext)
+	if errorFromReadAll != nil {
+		return nil, errorFromReadAll
+	}
+
+	invitationLobbies := make([]Lobby, 0)
+
+	for _, pendingLobby := range allLobbies {
+		if pendingLobby.HasInvitedPlayer(playerName) &&
+			!pendingLobby.HasSeatedPlayer(playerName) {
+			invitationLobbies = append(invitationLobbies, pendingLobby)
+		}
+	}
+
+	sort.Sort(ByLobbyCreationTime(invitationLobbies))
+
+	return invitationLobbies, nil
+}
+
+// AcceptInvitation records that the given player accepts the invitation to the given
+// game, and deals the game if that means that all the invited players have accepted.
+func (gameCollection *StateCollection) AcceptInvitation(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	invitationLobby, errorFromRead :=
+		gameCollection.lobbyPersister.ReadLobby(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	if !invitationLobby.IsByInvitationOnly() {
+		return fmt.Errorf("Game %v does not require invitations", gameName)
+	}
+
+	errorFromSeating :=
+		gameCollection.lobbyPersister.AddPlayerToLobby(
+			executionContext,
+			gameName,
+			playerName)
+
+	if errorFromSeating != nil {
+		return errorFromSeating
+	}
+
+	updatedLobby, errorFromUpdatedRead :=
+		gameCollection.lobbyPersister.ReadLobby(executionContext, gameName)
+
+	if errorFromUpdatedRead != nil {
+		return errorFromUpdatedRead
+	}
+
+	if updatedLobby.NumberOfOpenSeats() > 0 {
+		return nil
+	}
+
+	return gameCollection.dealGameFromLobby(executionContext, updatedLobby)
+}
+
+// DeclineInvitation records that the given player declines the invitation to the
+// given game, which means that the game can never start with all the invited players,
+// so the invitations for all the other players are withdrawn too.
+func (gameCollection *StateCollection) DeclineInvitation(
 	executionContext context.Context,
-	playerName string) ([]ViewForPlayer, error) {
-	gameStates, errorFromReadAll :=
-		gameCollection.statePersister.ReadAllWithPlayer(executionContext, playerName)
-	if errorFromReadAll != nil {
-		return nil, errorFromReadAll
+	gameName string,
+	playerName string) error {
+	invitationLobby, errorFromRead :=
+		gameCollection.lobbyPersister.ReadLobby(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
 	}
 
-	numberOfGames := len(gameStates)
+	if !invitationLobby.HasInvitedPlayer(playerName) {
+		return ErrNotInvited.Errorf(
+			"Player %v has not been invited to game %v",
+			playerName,
+			gameName)
+	}
 
-	sort.Sort(ByCreationTime(gameStates))
+	return gameCollection.lobbyPersister.DeleteLobby(executionContext, gameName)
+}
 
-	playerViews := make([]ViewForPlayer, numberOfGames)
+// SetSpectating sets whether players who are not participants of the given game may
+// spectate it, and how many turns the neutral view (where every hand is visible)
+// should lag behind the game so that spectators cannot easily pass information to the
+// participants. Only the creator of the game may change these settings. It returns
+// an error if the game does not exist, if the given player is not the creator, or if
+// the delay is negative.
+func (gameCollection *StateCollection) SetSpectating(
+	executionContext context.Context,
+	gameName string,
+	playerName string,
+	isAllowed bool,
+	delayInTurns int) error {
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
 
-	for gameIndex := 0; gameIndex < numberOfGames; gameIndex++ {
-		playerView, participantError :=
-			ViewOnStateForPlayer(
-				executionContext,
-				gameStates[gameIndex],
-				gameCollection.playerProvider,
-				playerName)
+	if errorFromGet != nil {
+		return fmt.Errorf(
+			"Could not find game %v (%w), cannot set spectating",
+			gameName,
+			errorFromGet)
+	}
 
-		if participantError != nil {
-			overallError :=
-				fmt.Errorf(
-					"When trying to wrap views around read-only game states, encountered error %v",
-					participantError)
-			return nil, overallError
+	readState := gameState.Read()
+
+	if readState.CreatorName() != playerName {
+		return fmt.Errorf(
+			"Only the creator of game %v may set whether it can be spectated, not %v",
+			gameName,
+			playerName)
+	}
+
+	if delayInTurns < 0 {
+		return fmt.Errorf("Delay for spectators cannot be negative (was %v)", delayInTurns)
+	}
+
+	errorFromSet :=
+		gameCollection.spectatorPersister.SetSpectating(
+			executionContext,
+			readState.Name(),
+			isAllowed,
+			delayInTurns)
+
+	if errorFromSet != nil {
+		return errorFromSet
+	}
+
+	// The delayed view needs a starting point, so we store the state at the moment
+	// that spectating is allowed.
+	if isAllowed && (delayInTurns > 0) {
+		return gameCollection.recordSnapshot(executionContext, readState)
+	}
+
+	return nil
+}
+
+// ViewAsSpectator returns the neutral view of the given game, where the hands of all
+// the participants are visible, for the given spectator. If the creator of the game
+// set a delay for spectators, the view is of the game as it was that many turns ago,
+// unless the game is finished, in which case there is nothing left to hide. It returns
+// an error if the spectator is not a registered player, is a participant in the game,
+// or if the game does not allow spectating.
+func (gameCollection *StateCollection) ViewAsSpectator(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string) (NeutralSnapshot, error) {
+	gameState, spectatorGallery, errorFromCheck :=
+		gameCollection.stateAndGalleryIfSpectatorAllowed(
+			executionContext,
+			gameName,
+			spectatorName)
+
+	if errorFromCheck != nil {
+		return NeutralSnapshot{}, errorFromCheck
+	}
+
+	if (spectatorGallery.DelayInTurns == 0) || IsFinished(gameState) {
+		return gameCollection.neutralSnapshotOfState(executionContext, gameState)
+	}
+
+	delayedSnapshot, isFound := spectatorGallery.DelayedSnapshot(gameState.Turn())
+
+	if !isFound {
+		return NeutralSnapshot{}, fmt.Errorf(
+			"Game %v has not progressed far enough to be shown with a delay of %v turns",
+			gameName,
+			spectatorGallery.DelayInTurns)
+	}
+
+	return delayedSnapshot, nil
+}
+
+// ViewAsSpectatorFromSeat returns a view of the given game as seen by the given
+// participant, for the given spectator to follow the game from the point of view of
+// that participant. A single seat shows no more than its participant sees, but a
+// spectator could switch between seats to see every hand as it is now, so seats may
+// not be viewed while the creator of the game has set a delay for spectators, unless
+// the game is finished. It returns an error if the spectator may not spectate the
+// game, if there is a delay, or if the given participant is not a participant in the
+// game.
+func (gameCollection *StateCollection) ViewAsSpectatorFromSeat(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string,
+	participantName string) (ViewForPlayer, error) {
+	gameState, spectatorGallery, errorFromCheck :=
+		gameCollection.stateAndGalleryIfSpectatorAllowed(
+			executionContext,
+			gameName,
+			spectatorName)
+
+	if errorFromCheck != nil {
+		return nil, errorFromCheck
+	}
+
+	if (spectatorGallery.DelayInTurns > 0) && !IsFinished(gameState) {
+		return nil, ErrSpectatingNotAllowed.Errorf(
+			"Game %v shows spectators the game with a delay of %v turns,"+
+				" so it cannot be followed from the seat of a participant",
+			gameName,
+			spectatorGallery.DelayInTurns)
+	}
+
+	return gameCollection.viewOnStateForPlayer(
+		executionContext,
+		gameState,
+		participantName)
+}
+
+// RecordSpectatorChatMessage records the given chat message from the given spectator
+// in the chat log for spectators of the given game, which is separate from the chat
+// log of the participants. It returns an error if the spectator may not spectate the
+// game.
+func (gameCollection *StateCollection) RecordSpectatorChatMessage(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string,
+	chatMessage string) error {
+	gameState, _, errorFromCheck :=
+		gameCollection.stateAndGalleryIfSpectatorAllowed(
+			executionContext,
+			gameName,
+			spectatorName)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	spectatorState, errorFromGet :=
+		gameCollection.playerProvider.Get(executionContext, spectatorName)
+
+	if errorFromGet != nil {
+		return errorFromGet
+	}
+
+	return gameCollection.spectatorPersister.AddChatMessage(
+		executionContext,
+		gameState.Name(),
+		message.NewFromPlayer(spectatorState.Name(), spectatorState.Color(), chatMessage),
+		gameCollection.chatLogLength)
+}
+
+// SpectatorChatLog returns the chat log for spectators of the given game, or an error
+// if the given spectator may not spectate the game.
+func (gameCollection *StateCollection) SpectatorChatLog(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string) ([]message.FromPlayer, error) {
+	_, spectatorGallery, errorFromCheck :=
+		gameCollection.stateAndGalleryIfSpectatorAllowed(
+			executionContext,
+			gameName,
+			spectatorName)
+
+	if errorFromCheck != nil {
+		return nil, errorFromCheck
+	}
+
+	return spectatorGallery.ChatMessageLog, nil
+}
+
+// stateAndGalleryIfSpectatorAllowed returns the read-only state of the given game and
+// its spectator gallery if the given spectator is a registered player who is not a
+// participant in the game, and the game allows spectating, and otherwise returns an
+// error.
+func (gameCollection *StateCollection) stateAndGalleryIfSpectatorAllowed(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string) (ReadonlyState, SpectatorGallery, error) {
+	_, errorFromPlayer :=
+		gameCollection.playerProvider.Get(executionContext, spectatorName)
+
+	if errorFromPlayer != nil {
+		return nil, SpectatorGallery{}, errorFromPlayer
+	}
+
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet != nil {
+		return nil, SpectatorGallery{}, fmt.Errorf(
+			"Could not find game %v (%w), cannot be spectated by %v",
+			gameName,
+			errorFromGet,
+			spectatorName)
+	}
+
+	readState := gameState.Read()
+
+	for _, participantName := range readState.PlayerNames() {
+		if participantName == spectatorName {
+			return nil, SpectatorGallery{}, ErrSpectatingNotAllowed.Errorf(
+				"Player %v is a participant in game %v and so cannot spectate it",
+				spectatorName,
+				gameName)
 		}
+	}
 
-		playerViews[gameIndex] = playerView
+	spectatorGallery, errorFromRead :=
+		gameCollection.spectatorPersister.ReadGallery(executionContext, readState.Name())
+
+	if errorFromRead != nil {
+		return nil, SpectatorGallery{}, errorFromRead
 	}
 
-	return playerViews, nil
+	if !spectatorGallery.SpectatingIsAllowed {
+		return nil, SpectatorGallery{}, ErrSpectatingNotAllowed.Errorf(
+			"Game %v does not allow spectators",
+			gameName)
+	}
+
+	return readState, spectatorGallery, nil
 }
 
-// AddNew prepares a new shuffled deck using a random seed taken from the given
-// collection, and uses it to create a new game in the given collection from the
-// given definition. It returns an error if a game with the given name already
-// exists, or if the definition includes invalid players.
-func (gameCollection *StateCollection) AddNew(
+// recordSnapshotAfterTurn stores a snapshot of the given game for delayed viewing by
+// spectators if the game allows spectating with a delay.
+func (gameCollection *StateCollection) recordSnapshotAfterTurn(
+	executionContext context.Context,
+	gameName string) error {
+	spectatorGallery, errorFromRead :=
+		gameCollection.spectatorPersister.ReadGallery(executionContext, gameName)
+
+	if errorFromRead != nil {
+		return errorFromRead
+	}
+
+	if !spectatorGallery.SpectatingIsAllowed || (spectatorGallery.DelayInTurns == 0) {
+		return nil
+	}
+
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet != nil {
+		return errorFromGet
+	}
+
+	return gameCollection.recordSnapshot(executionContext, gameState.Read())
+}
+
+// fillDisplayNames sets the display names of the players of the given leaderboard
+// entries from the current states of the players, falling back on the identifier of any
+// player who cannot be found.
+func (gameCollection *StateCollection) fillDisplayNames(
+	executionContext context.Context,
+	leaderboardEntries []LeaderboardEntry) {
+	for _, leaderboardEntry := range leaderboardEntries {
+		for playerIndex, playerName := range leaderboardEntry.PlayerNames {
+			leaderboardEntry.PlayerDisplayNames[playerIndex] = playerName
+
+			playerState, errorFromPlayer :=
+				gameCollection.playerProvider.Get(executionContext, playerName)
+
+			if errorFromPlayer == nil {
+				leaderboardEntry.PlayerDisplayNames[playerIndex] = playerState.Name()
+			}
+		}
+	}
+}
+
+// recordHistoryIfFinished stores the record of the given game in the history of
+// finished games if the game is now finished. It should only be called after a turn
+// has been taken, as only one turn can finish a game, so that the game is recorded
+// only once.
+func (gameCollection *StateCollection) recordHistoryIfFinished(
+	executionContext context.Context,
+	gameName string) error {
+	gameState, errorFromGet :=
+		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+
+	if errorFromGet != nil {
+		return errorFromGet
+	}
+
+	readState := gameState.Read()
+
+	if !IsFinished(readState) {
+		return nil
+	}
+
+	return gameCollection.historyPersister.AddFinishedGame(
+		executionContext,
+		NewFinishedGame(readState))
+}
+
+// recordSnapshot stores a neutral snapshot of the given game state for delayed
+// viewing by spectators.
+func (gameCollection *StateCollection) recordSnapshot(
+	executionContext context.Context,
+	gameState ReadonlyState) error {
+	neutralSnapshot, errorFromSnapshot :=
+		gameCollection.neutralSnapshotOfState(executionContext, gameState)
+
+	if errorFromSnapshot != nil {
+		return errorFromSnapshot
+	}
+
+	return gameCollection.spectatorPersister.AddSnapshot(
+		executionContext,
+		gameState.Name(),
+		neutralSnapshot)
+}
+
+// addNewForCreator prepares a new shuffled deck using a random seed taken from the
+// given collection, and uses it to create a new game in the given collection from the
+// given definition, recording the given player as its creator.
+func (gameCollection *StateCollection) addNewForCreator(
 	executionContext context.Context,
 	gameName string,
+	creatorName string,
 	gameRuleset Ruleset,
 	playerNames []string) error {
 	initialDeck := gameRuleset.CopyOfFullCardset()
 
 	card.ShuffleInPlace(initialDeck, gameCollection.statePersister.RandomSeed())
 
-	return gameCollection.AddNewWithGivenDeck(
+	return gameCollection.addNewWithGivenDeckForCreator(
 		executionContext,
 		gameName,
+		creatorName,
 		gameRuleset,
 		playerNames,
 		initialDeck)
 }
 
-// AddNewWithGivenDeck creates a new game in the given collection from the given
-// definition and the given deck. It returns an error if a game with the given name
-// already exists, or if the definition includes invalid players.
-func (gameCollection *StateCollection) AddNewWithGivenDeck(
+// addNewWithGivenDeckForCreator creates a new game in the given collection from the
+// given definition and the given deck, recording the given player as its creator. The
+// initial action log shows the players in the colors in which they are shown in the
+// game. It returns an error if a game with the given name already exists, if the
+// definition includes invalid players, or if no colors can be found to tell the
+// players apart.
+func (gameCollection *StateCollection) addNewWithGivenDeckForCreator(
 	executionContext context.Context,
 	gameName string,
+	creatorName string,
 	gameRuleset Ruleset,
 	playerNames []string,
 	initialDeck []card.Defined) error {
@@ -131,9 +1201,17 @@ func (gameCollection *StateCollection) AddNewWithGivenDeck(
 		return fmt.Errorf("Game must have a name")
 	}
 
+	providerForGame, errorFromColors :=
+		gameCollection.providerForGame(executionContext, playerNames)
+
+	if errorFromColors != nil {
+		return errorFromColors
+	}
+
 	namesWithHands, initialDeck, initialActionLog, errorFromHands :=
-		gameCollection.createPlayerHands(
+		createPlayerHands(
 			executionContext,
+			providerForGame,
 			playerNames,
 			gameRuleset,
 			initialDeck)
@@ -145,6 +1223,7 @@ func (gameCollection *StateCollection) AddNewWithGivenDeck(
 	return gameCollection.statePersister.AddGame(
 		executionContext,
 		gameName,
+		creatorName,
 		gameCollection.chatLogLength,
 		initialActionLog,
 		gameRuleset,
@@ -152,61 +1231,179 @@ func (gameCollection *StateCollection) AddNewWithGivenDeck(
 		initialDeck)
 }
 
-// ExecuteAction finds the given game and wraps it in an executor for the given
-// player, or returns an error.
-func (gameCollection *StateCollection) ExecuteAction(
+// dealGameFromLobby deals a new game for the players seated in the given lobby, in
+// the turn order given by the lobby and with the host of the lobby as its creator,
+// and then removes the lobby. It returns an error if there are still open seats.
+func (gameCollection *StateCollection) dealGameFromLobby(
 	executionContext context.Context,
-	gameName string,
-	playerName string) (ExecutorForPlayer, error) {
-	actingPlayer, playerIdentificationError :=
-		gameCollection.playerProvider.Get(executionContext, playerName)
+	lobbyToStart Lobby) error {
+	if lobbyToStart.NumberOfOpenSeats() > 0 {
+		return fmt.Errorf(
+			"Game %v still has %v open seats",
+			lobbyToStart.GameName,
+			lobbyToStart.NumberOfOpenSeats())
+	}
 
-	if playerIdentificationError != nil {
-		return nil, playerIdentificationError
+	gameRuleset, errorFromRuleset :=
+		RulesetFromIdentifier(lobbyToStart.RulesetIdentifier)
+
+	if errorFromRuleset != nil {
+		return errorFromRuleset
 	}
 
-	gameState, errorFromGet :=
-		gameCollection.statePersister.ReadAndWriteGame(executionContext, gameName)
+	errorFromAdd :=
+		gameCollection.addNewForCreator(
+			executionContext,
+			lobbyToStart.GameName,
+			lobbyToStart.HostName,
+			gameRuleset,
+			lobbyToStart.PlayerNamesInTurnOrder())
 
-	if errorFromGet != nil {
-		errorWrappingErrorFromGet :=
-			fmt.Errorf(
-				"Could not find game %v (%v), cannot execute action for player %v",
-				gameName,
-				errorFromGet,
+	if errorFromAdd != nil {
+		return errorFromAdd
+	}
+
+	return gameCollection.lobbyPersister.DeleteLobby(
+		executionContext,
+		lobbyToStart.GameName)
+}
+
+// errorIfGameNameIsTaken returns an error if the given name does not follow the
+// naming rules for games or if a game with the same name according to those rules
+// already exists, so that a lobby is never created for a game which could not be
+// dealt. Lobbies with the same name are rejected by the lobby persister itself.
+func (gameCollection *StateCollection) errorIfGameNameIsTaken(
+	executionContext context.Context,
+	gameName string) error {
+	isTaken, errorFromCheck :=
+		gameCollection.statePersister.IsNameTaken(executionContext, gameName)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	if isTaken {
+		return ErrNameTaken.Errorf("Game %v already exists", gameName)
+	}
+
+	return nil
+}
+
+// checkPlayerNames returns an error if the number of players is not allowed by the
+// ruleset, if any player is not registered, or if any player appears more than once.
+func (gameCollection *StateCollection) checkPlayerNames(
+	executionContext context.Context,
+	playerNames []string,
+	gameRuleset Ruleset) error {
+	numberOfPlayers := len(playerNames)
+
+	if numberOfPlayers < gameRuleset.MinimumNumberOfPlayers() {
+		return fmt.Errorf(
+			"Game must have at least %v players",
+			gameRuleset.MinimumNumberOfPlayers())
+	}
+
+	if numberOfPlayers > gameRuleset.MaximumNumberOfPlayers() {
+		return fmt.Errorf(
+			"Game must have no more than %v players",
+			gameRuleset.MaximumNumberOfPlayers())
+	}
+
+	uniquePlayerNames := make(map[string]bool, numberOfPlayers)
+
+	for _, playerName := range playerNames {
+		_, errorFromPlayerProvider :=
+			gameCollection.playerProvider.Get(executionContext, playerName)
+
+		if errorFromPlayerProvider != nil {
+			return errorFromPlayerProvider
+		}
+
+		if uniquePlayerNames[playerName] {
+			return fmt.Errorf(
+				"Player with name %v appears more than once in the list of players",
 				playerName)
+		}
 
-		return nil, errorWrappingErrorFromGet
+		uniquePlayerNames[playerName] = true
 	}
 
-	return ExecutorOfActionsForPlayer(executionContext, gameState, actingPlayer)
+	return nil
 }
 
-// RemoveGameFromListForPlayer calls the RemoveGameFromListForPlayer of the
-// internal persistence store.
-func (gameCollection *StateCollection) RemoveGameFromListForPlayer(
+// providerForGame returns a player provider which gives the given participants of a
+// game with the chat colors in which they are shown in that game, as worked out by the
+// color separator, or the player provider of the collection if there is no color
+// separator. It returns an error if the colors cannot be worked out.
+func (gameCollection *StateCollection) providerForGame(
 	executionContext context.Context,
-	gameName string,
-	playerName string) error {
-	return gameCollection.statePersister.RemoveGameFromListForPlayer(
-		executionContext,
-		gameName,
-		playerName)
+	playerNames []string) (ReadonlyPlayerProvider, error) {
+	if gameCollection.colorSeparator == nil {
+		return gameCollection.playerProvider, nil
+	}
+
+	colorsInGame, errorFromColors :=
+		gameCollection.colorSeparator.SeparatedChatColors(executionContext, playerNames)
+
+	if errorFromColors != nil {
+		return nil, errorFromColors
+	}
+
+	return newProviderWithColorsInGame(
+		gameCollection.playerProvider,
+		playerNames,
+		colorsInGame), nil
 }
 
-// Delete calls the Delete of the internal persistence store.
-func (gameCollection *StateCollection) Delete(
+// viewOnStateForPlayer creates a view of the given game for the given player, with the
+// participants shown in their colors for the game.
+func (gameCollection *StateCollection) viewOnStateForPlayer(
 	executionContext context.Context,
-	gameName string) error {
-	return gameCollection.statePersister.Delete(executionContext, gameName)
+	gameState ReadonlyState,
+	playerName string) (ViewForPlayer, error) {
+	providerForGame, errorFromColors :=
+		gameCollection.providerForGame(executionContext, gameState.PlayerNames())
+
+	if errorFromColors != nil {
+		return nil, errorFromColors
+	}
+
+	return ViewOnStateForPlayer(executionContext, gameState, providerForGame, playerName)
+}
+
+// neutralSnapshotOfState creates a neutral snapshot of the given game, with the
+// participants shown in their colors for the game.
+func (gameCollection *StateCollection) neutralSnapshotOfState(
+	executionContext context.Context,
+	gameState ReadonlyState) (NeutralSnapshot, error) {
+	providerForGame, errorFromColors :=
+		gameCollection.providerForGame(executionContext, gameState.PlayerNames())
+
+	if errorFromColors != nil {
+		return NeutralSnapshot{}, errorFromColors
+	}
+
+	return NeutralSnapshotOfState(executionContext, gameState, providerForGame)
+}
+
+// firstPlayerAsCreator returns the first of the given players, who is taken to be
+// the creator of a game created directly from a list of players, or an empty name if
+// the list is empty, so that the check of the list can report the problem.
+func firstPlayerAsCreator(playerNames []string) string {
+	if len(playerNames) == 0 {
+		return ""
+	}
+
+	return playerNames[0]
 }
 
 // createPlayerHands deals out each player's hand (a full hand per player rather
 // than one card each time to each player) and then returns a list of player names
 // paired with their initial hands, the remaining deck, the initial action log, and
-// a possible error.
-func (gameCollection *StateCollection) createPlayerHands(
+// a possible error. The players are found through the given provider.
+func createPlayerHands(
 	executionContext context.Context,
+	playerProvider ReadonlyPlayerProvider,
 	playerNames []string,
 	gameRuleset Ruleset,
 	initialDeck []card.Defined) (
@@ -252,7 +1449,7 @@ func (gameCollection *StateCollection) createPlayerHands(
 		playerName := playerNames[playerIndex]
 
 		playerState, errorFromPlayerProvider :=
-			gameCollection.playerProvider.Get(executionContext, playerName)
+			playerProvider.Get(executionContext, playerName)
 
 		if errorFromPlayerProvider != nil {
 			return nil, nil, nil, errorFromPlayerProvider
@@ -291,7 +1488,7 @@ func (gameCollection *StateCollection) createPlayerHands(
 
 		actionLog[playerIndex] =
 			message.NewFromPlayer(
-				playerName,
+				playerState.Name(),
 				playerState.Color(),
 				"receieved initial hand")
 
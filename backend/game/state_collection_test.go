@@ -3,6 +3,7 @@ package game_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -708,6 +709,328 @@ func TestAddNewWithDefaultShuffle(unitTest *testing.T) {
 	}
 }
 
+func TestSeparatedChatColorsCheckedBeforeAddingNew(unitTest *testing.T) {
+	gameParticipants :=
+		[]string{
+			playerNamesAvailableInTest[2],
+			playerNamesAvailableInTest[0],
+			playerNamesAvailableInTest[1],
+		}
+
+	testCases := []struct {
+		testName            string
+		errorFromSeparation error
+	}{
+		{
+			testName:            "Colors separated",
+			errorFromSeparation: nil,
+		},
+		{
+			testName:            "Colors cannot be separated",
+			errorFromSeparation: fmt.Errorf("expected error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName+"/AddNew", func(unitTest *testing.T) {
+			gameCollection, mockGamePersister, mockPlayerProvider :=
+				prepareCollection(unitTest, playerNamesAvailableInTest)
+
+			mockGamePersister.TestErrorForAddGame = nil
+			mockGamePersister.TestErrorForRandomSeed = nil
+			mockPlayerProvider.ErrorForSeparatedChatColors = testCase.errorFromSeparation
+
+			errorFromAddNew :=
+				gameCollection.AddNew(
+					context.Background(),
+					"Test game",
+					testRuleset,
+					gameParticipants)
+
+			assertChatColorsSeparatedBeforeAdding(
+				unitTest,
+				"AddNew(...)",
+				testCase.errorFromSeparation,
+				errorFromAddNew,
+				gameParticipants,
+				mockPlayerProvider,
+				len(mockGamePersister.ArgumentsForAddGame))
+		})
+
+		unitTest.Run(testCase.testName+"/InviteToNew", func(unitTest *testing.T) {
+			gameCollection, mockGamePersister, mockPlayerProvider :=
+				prepareCollection(unitTest, playerNamesAvailableInTest)
+
+			mockGamePersister.TestErrorForIsNameTaken = nil
+			mockPlayerProvider.ErrorForSeparatedChatColors = testCase.errorFromSeparation
+
+			errorFromInvite :=
+				gameCollection.InviteToNew(
+					context.Background(),
+					"Test game",
+					testRuleset,
+					gameParticipants)
+
+			openLobbies, errorFromOpenLobbies := gameCollection.AllLobbies(context.Background())
+
+			if errorFromOpenLobbies != nil {
+				unitTest.Fatalf("AllLobbies() produced unexpected error %v", errorFromOpenLobbies)
+			}
+
+			invitationsForInvitee, errorFromInvitations :=
+				gameCollection.ViewInvitationsForPlayer(context.Background(), gameParticipants[1])
+
+			if errorFromInvitations != nil {
+				unitTest.Fatalf(
+					"ViewInvitationsForPlayer(...) produced unexpected error %v",
+					errorFromInvitations)
+			}
+
+			assertChatColorsSeparatedBeforeAdding(
+				unitTest,
+				"InviteToNew(...)",
+				testCase.errorFromSeparation,
+				errorFromInvite,
+				gameParticipants,
+				mockPlayerProvider,
+				len(openLobbies)+len(invitationsForInvitee))
+		})
+	}
+}
+
+func TestParticipantsShownInColorsForGameWithoutChangingTheirColors(unitTest *testing.T) {
+	gameName := "Test game"
+	gameParticipants := playerNamesAvailableInTest[:2]
+	colorInGame := "color in game"
+	gameCollection, mockGamePersister, mockPlayerProvider :=
+		prepareCollection(unitTest, playerNamesAvailableInTest)
+
+	mockPlayerProvider.MockColorsInGame[gameParticipants[1]] = colorInGame
+
+	mockGamePersister.TestErrorForReadAndWriteGame = nil
+	mockGamePersister.ReturnForReadAndWriteGame =
+		NewMockGameState(unitTest)
+	mockGame := mockGamePersister.ReturnForReadAndWriteGame.(*mockGameState)
+	mockGame.ReturnForName = gameName
+	mockGame.ReturnForPlayerNames = gameParticipants
+	mockGame.ReturnForRuleset = testRuleset
+
+	gameView, errorFromView :=
+		gameCollection.ViewState(context.Background(), gameName, gameParticipants[0])
+
+	if errorFromView != nil {
+		unitTest.Fatalf("ViewState(...) produced unexpected error %v", errorFromView)
+	}
+
+	_, shownColor, errorFromHand := gameView.VisibleHand(gameParticipants[1])
+
+	if (errorFromHand != nil) || (shownColor != colorInGame) {
+		unitTest.Fatalf(
+			"VisibleHand(%v) produced color %v and error %v, expected color %v",
+			gameParticipants[1],
+			shownColor,
+			errorFromHand,
+			colorInGame)
+	}
+
+	actionExecutor, errorFromExecutor :=
+		gameCollection.ExecuteAction(context.Background(), gameName, gameParticipants[1])
+
+	if errorFromExecutor != nil {
+		unitTest.Fatalf("ExecuteAction(...) produced unexpected error %v", errorFromExecutor)
+	}
+
+	mockGame.TestErrorForRecordChatMessage = nil
+	errorFromChat := actionExecutor.RecordChatMessage(context.Background(), "Hello")
+
+	if errorFromChat != nil {
+		unitTest.Fatalf("RecordChatMessage(...) produced unexpected error %v", errorFromChat)
+	}
+
+	recordedChats := mockGame.ArgumentsFromRecordChatMessage
+
+	if (len(recordedChats) != 1) || (recordedChats[0].ColorString != colorInGame) {
+		unitTest.Fatalf(
+			"RecordChatMessage(...) recorded %+v, expected one message in color %v",
+			recordedChats,
+			colorInGame)
+	}
+
+	if mockPlayerProvider.MockPlayers[gameParticipants[1]].MockColor != mockChatColor {
+		unitTest.Fatalf(
+			"Color of player %v was changed to %v",
+			gameParticipants[1],
+			mockPlayerProvider.MockPlayers[gameParticipants[1]].MockColor)
+	}
+}
+
+func TestGameFoundByNameAsTypedWhenCreated(unitTest *testing.T) {
+	givenName := " Cafe\u0301 game "
+	storedName := "Caf\u00e9 game"
+	participantNames := playerNamesAvailableInTest[:2]
+	spectatorName := playerNamesAvailableInTest[2]
+
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			gameCollection := collectionAndDescription.GameCollection
+
+			errorFromAdd :=
+				gameCollection.AddNew(executionContext, givenName, testRuleset, participantNames)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(%q, ...) produced unexpected error %v", givenName, errorFromAdd)
+			}
+
+			gameView, errorFromView :=
+				gameCollection.ViewState(executionContext, givenName, participantNames[0])
+
+			if errorFromView != nil {
+				unitTest.Fatalf("ViewState(%q, ...) produced unexpected error %v", givenName, errorFromView)
+			}
+
+			if gameView.GameName() != storedName {
+				unitTest.Fatalf(
+					"ViewState(%q, ...) gave view of game %q, expected %q",
+					givenName,
+					gameView.GameName(),
+					storedName)
+			}
+
+			changeNotices, unsubscribe :=
+				gameCollection.SubscribeToChanges(executionContext, givenName)
+			defer unsubscribe()
+
+			actionExecutor, errorFromExecutor :=
+				gameCollection.ExecuteAction(executionContext, givenName, participantNames[0])
+
+			if errorFromExecutor != nil {
+				unitTest.Fatalf("ExecuteAction(%q, ...) produced unexpected error %v", givenName, errorFromExecutor)
+			}
+
+			errorFromChat := actionExecutor.RecordChatMessage(executionContext, "Hello")
+
+			if errorFromChat != nil {
+				unitTest.Fatalf("RecordChatMessage(...) produced unexpected error %v", errorFromChat)
+			}
+
+			assertNoticeWaiting(unitTest, "subscriber by name as typed", changeNotices, storedName)
+
+			errorFromSpectating :=
+				gameCollection.SetSpectating(
+					executionContext,
+					givenName,
+					participantNames[0],
+					true,
+					0)
+
+			if errorFromSpectating != nil {
+				unitTest.Fatalf(
+					"SetSpectating(%q, ...) produced unexpected error %v",
+					givenName,
+					errorFromSpectating)
+			}
+
+			errorFromSpectatorChat :=
+				gameCollection.RecordSpectatorChatMessage(
+					executionContext,
+					givenName,
+					spectatorName,
+					"Hello from the gallery")
+
+			if errorFromSpectatorChat != nil {
+				unitTest.Fatalf(
+					"RecordSpectatorChatMessage(%q, ...) produced unexpected error %v",
+					givenName,
+					errorFromSpectatorChat)
+			}
+
+			spectatorChat, errorFromSpectatorLog :=
+				gameCollection.SpectatorChatLog(executionContext, storedName, spectatorName)
+
+			if (errorFromSpectatorLog != nil) || (len(spectatorChat) != 1) {
+				unitTest.Fatalf(
+					"SpectatorChatLog(%q, ...) produced %v and error %v, expected one message",
+					storedName,
+					spectatorChat,
+					errorFromSpectatorLog)
+			}
+
+			seriesViews, errorFromSeries :=
+				gameCollection.ViewSeries(executionContext, givenName, participantNames[0])
+
+			if (errorFromSeries != nil) || (len(seriesViews) != 1) {
+				unitTest.Fatalf(
+					"ViewSeries(%q, ...) produced %v and error %v, expected one view",
+					givenName,
+					seriesViews,
+					errorFromSeries)
+			}
+
+			errorFromDelete := gameCollection.Delete(executionContext, givenName)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf("Delete(%q) produced unexpected error %v", givenName, errorFromDelete)
+			}
+
+			assertNoticeWaiting(unitTest, "subscriber after delete", changeNotices, storedName)
+
+			_, errorFromDeletedView :=
+				gameCollection.ViewState(executionContext, storedName, participantNames[0])
+
+			if errorFromDeletedView == nil {
+				unitTest.Fatalf(
+					"ViewState(%q, ...) after Delete(%q) did not produce expected error",
+					storedName,
+					givenName)
+			}
+		})
+	}
+}
+
+func assertChatColorsSeparatedBeforeAdding(
+	unitTest *testing.T,
+	testIdentifier string,
+	errorFromSeparation error,
+	errorFromAdding error,
+	gameParticipants []string,
+	mockPlayerProvider *mockPlayerProvider,
+	numberOfAddedGames int) {
+	separatedPlayers := mockPlayerProvider.ArgumentsForSeparatedChatColors
+
+	if (len(separatedPlayers) != 1) ||
+		(strings.Join(separatedPlayers[0], ",") != strings.Join(gameParticipants, ",")) {
+		unitTest.Fatalf(
+			testIdentifier+" worked out chat colors of %v, expected once for %v in turn order",
+			separatedPlayers,
+			gameParticipants)
+	}
+
+	if errorFromSeparation == nil {
+		if errorFromAdding != nil {
+			unitTest.Fatalf(testIdentifier+" produced unexpected error %v", errorFromAdding)
+		}
+
+		if numberOfAddedGames != 1 {
+			unitTest.Fatalf(
+				testIdentifier+" added %v games or invitations, expected 1",
+				numberOfAddedGames)
+		}
+
+		return
+	}
+
+	if errorFromAdding == nil {
+		unitTest.Fatalf("%v did not produce expected error", testIdentifier)
+	}
+
+	if numberOfAddedGames != 0 {
+		unitTest.Fatalf(
+			testIdentifier+" added %v games or invitations despite error, expected none",
+			numberOfAddedGames)
+	}
+}
+
 func TestExecutorErrorWhenPersisterGivesError(unitTest *testing.T) {
 	gameName := "Test game"
 	playerName := playerNamesAvailableInTest[0]
@@ -918,6 +1241,12 @@ func TestReturnErrorFromPersisterDelete(unitTest *testing.T) {
 		prepareCollection(unitTest, playerNamesAvailableInTest)
 	gamePersister.TestErrorForDelete = nil
 
+	// The game is read before it is deleted to find the name under which it is stored.
+	gamePersister.TestErrorForReadAndWriteGame = nil
+	mockGame := NewMockGameState(unitTest)
+	mockGame.ReturnForName = "mock game"
+	gamePersister.ReturnForReadAndWriteGame = mockGame
+
 	testCases := []struct {
 		testName      string
 		expectedError error
@@ -950,3 +1279,43 @@ func TestReturnErrorFromPersisterDelete(unitTest *testing.T) {
 		})
 	}
 }
+
+func TestCreatorIsFirstPlayer(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			gameName := "Test game"
+			gamePlayers := playerNamesAvailableInTest[1:4]
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					gameName,
+					testRuleset,
+					gamePlayers)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			actualCreator, errorFromCreator :=
+				gameCollection.Creator(context.Background(), gameName)
+
+			if (errorFromCreator != nil) || (actualCreator != gamePlayers[0]) {
+				unitTest.Fatalf(
+					"Creator(%v) returned %v, %v - expected %v",
+					gameName,
+					actualCreator,
+					errorFromCreator,
+					gamePlayers[0])
+			}
+
+			_, errorFromUnknownGame :=
+				gameCollection.Creator(context.Background(), "Unknown game")
+
+			if errorFromUnknownGame == nil {
+				unitTest.Fatalf("Creator(unknown game) did not produce expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package game
+
+import (
+	"sort"
+)
+
+// StatisticsForRulesetAndPlayerCount holds the statistics of a player over the
+// finished games with a particular ruleset and number of players.
+type StatisticsForRulesetAndPlayerCount struct {
+	RulesetIdentifier     int
+	NumberOfPlayers       int
+	NumberOfGamesFinished int
+	NumberOfPerfectGames  int
+	AverageScore          float64
+	BestScore             int
+	MaximumScore          int
+}
+
+// PlayerStatistics holds the statistics of a player over all the finished games in
+// which the player was a participant. The rates of discards and mistakes are per turn
+// taken by the player. A streak is a sequence of consecutive finished games, ordered
+// by the time at which they finished, which did not end because of too many mistakes.
+type PlayerStatistics struct {
+	PlayerName                               string
+	NumberOfGamesPlayed                      int
+	NumberOfGamesFinished                    int
+	NumberOfTurnsTaken                       int
+	NumberOfDiscards                         int
+	NumberOfMistakes                         int
+	DiscardRate                              float64
+	MistakeRate                              float64
+	CurrentStreakWithoutGameOverFromMistakes int
+	LongestStreakWithoutGameOverFromMistakes int
+	ByRulesetAndPlayerCount                  []StatisticsForRulesetAndPlayerCount
+}
+
+// StatisticsForPlayer derives the statistics of the given player from the given
+// records of finished games, ignoring any records which do not have the player as
+// a participant. The number of games played includes the given number of games in
+// progress as well as the finished games. The statistics for each combination of
+// ruleset and number of players are ordered by ruleset identifier and then by
+// number of players.
+func StatisticsForPlayer(
+	playerName string,
+	finishedGames []FinishedGame,
+	numberOfGamesInProgress int) PlayerStatistics {
+	gamesWithPlayer := make([]FinishedGame, 0, len(finishedGames))
+
+	for _, finishedGame := range finishedGames {
+		if _, isParticipant := finishedGame.Participant(playerName); isParticipant {
+			gamesWithPlayer = append(gamesWithPlayer, finishedGame)
+		}
+	}
+
+	sort.Sort(ByFinishTime(gamesWithPlayer))
+
+	playerStatistics := PlayerStatistics{
+		PlayerName:            playerName,
+		NumberOfGamesPlayed:   len(gamesWithPlayer) + numberOfGamesInProgress,
+		NumberOfGamesFinished: len(gamesWithPlayer),
+	}
+
+	// The statistics for each combination are accumulated with the total score in
+	// place of the average, which is only calculated once all the games are counted.
+	indicesOfCombinations := make(map[rulesetAndPlayerCount]int, 0)
+	totalScores := make([]int, 0)
+	byCombination := make([]StatisticsForRulesetAndPlayerCount, 0)
+	currentStreak := 0
+
+	for _, finishedGame := range gamesWithPlayer {
+		participantRecord, _ := finishedGame.Participant(playerName)
+		playerStatistics.NumberOfTurnsTaken += participantRecord.NumberOfTurnsTaken
+		playerStatistics.NumberOfDiscards += participantRecord.NumberOfDiscards
+		playerStatistics.NumberOfMistakes += participantRecord.NumberOfMistakes
+
+		if finishedGame.IsOverBecauseOfMistakes {
+			currentStreak = 0
+		} else {
+			currentStreak++
+		}
+
+		if currentStreak > playerStatistics.LongestStreakWithoutGameOverFromMistakes {
+			playerStatistics.LongestStreakWithoutGameOverFromMistakes = currentStreak
+		}
+
+		combinationKey := rulesetAndPlayerCount{
+			rulesetIdentifier: finishedGame.RulesetIdentifier,
+			numberOfPlayers:   len(finishedGame.PlayerNames),
+		}
+
+		combinationIndex, isAlreadyCounted := indicesOfCombinations[combinationKey]
+		if !isAlreadyCounted {
+			combinationIndex = len(byCombination)
+			indicesOfCombinations[combinationKey] = combinationIndex
+			totalScores = append(totalScores, 0)
+			byCombination =
+				append(byCombination, StatisticsForRulesetAndPlayerCount{
+					RulesetIdentifier: combinationKey.rulesetIdentifier,
+					NumberOfPlayers:   combinationKey.numberOfPlayers,
+					MaximumScore:      finishedGame.MaximumScore,
+				})
+		}
+
+		statisticsForCombination := &byCombination[combinationIndex]
+		statisticsForCombination.NumberOfGamesFinished++
+		totalScores[combinationIndex] += finishedGame.Score
+
+		if finishedGame.IsPerfect() {
+			statisticsForCombination.NumberOfPerfectGames++
+		}
+
+		if finishedGame.Score > statisticsForCombination.BestScore {
+			statisticsForCombination.BestScore = finishedGame.Score
+		}
+	}
+
+	playerStatistics.CurrentStreakWithoutGameOverFromMistakes = currentStreak
+
+	if playerStatistics.NumberOfTurnsTaken > 0 {
+		numberOfTurns := float64(playerStatistics.NumberOfTurnsTaken)
+		playerStatistics.DiscardRate = float64(playerStatistics.NumberOfDiscards) / numberOfTurns
+		playerStatistics.MistakeRate = float64(playerStatistics.NumberOfMistakes) / numberOfTurns
+	}
+
+	for combinationIndex := range byCombination {
+		byCombination[combinationIndex].AverageScore =
+			float64(totalScores[combinationIndex]) /
+				float64(byCombination[combinationIndex].NumberOfGamesFinished)
+	}
+
+	playerStatistics.ByRulesetAndPlayerCount = byCombination
+
+	sort.Slice(playerStatistics.ByRulesetAndPlayerCount, func(firstIndex int, secondIndex int) bool {
+		firstStatistics := playerStatistics.ByRulesetAndPlayerCount[firstIndex]
+		secondStatistics := playerStatistics.ByRulesetAndPlayerCount[secondIndex]
+
+		if firstStatistics.RulesetIdentifier != secondStatistics.RulesetIdentifier {
+			return firstStatistics.RulesetIdentifier < secondStatistics.RulesetIdentifier
+		}
+
+		return firstStatistics.NumberOfPlayers < secondStatistics.NumberOfPlayers
+	})
+
+	return playerStatistics
+}
+
+// rulesetAndPlayerCount is the combination of ruleset and number of players by which
+// the statistics of a player are grouped.
+type rulesetAndPlayerCount struct {
+	rulesetIdentifier int
+	numberOfPlayers   int
+}
+
+// ByFinishTime implements sort interface for []FinishedGame based on the time at
+// which each game finished.
+type ByFinishTime []FinishedGame
+
+// Len implements part of the sort interface for ByFinishTime.
+func (byFinishTime ByFinishTime) Len() int {
+	return len(byFinishTime)
+}
+
+// Swap implements part of the sort interface for ByFinishTime.
+func (byFinishTime ByFinishTime) Swap(firstIndex int, secondIndex int) {
+	byFinishTime[firstIndex], byFinishTime[secondIndex] =
+		byFinishTime[secondIndex], byFinishTime[firstIndex]
+}
+
+// Less implements part of the sort interface for ByFinishTime.
+func (byFinishTime ByFinishTime) Less(firstIndex int, secondIndex int) bool {
+	return byFinishTime[firstIndex].TimeOfFinish.Before(
+		byFinishTime[secondIndex].TimeOfFinish)
+}
@@ -0,0 +1,212 @@
+package game_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+)
+
+func TestMaximumScoreCountsEachDistinctCardOnce(unitTest *testing.T) {
+	// The standard ruleset has 5 color suits each with cards numbered 1 to 5,
+	// with several copies of some cards, but every card is worth 1 point.
+	expectedMaximum := 5 * 5
+
+	actualMaximum := game.MaximumScore(game.NewStandardWithoutRainbow())
+
+	if actualMaximum != expectedMaximum {
+		unitTest.Fatalf(
+			"MaximumScore(standard without rainbow) produced %v, expected %v",
+			actualMaximum,
+			expectedMaximum)
+	}
+}
+
+func TestStatisticsFromFinishedGames(unitTest *testing.T) {
+	playerName := "A"
+	startTime := time.Now()
+
+	// The records are given out of order, so that the streaks can only be correct
+	// if the records are ordered by the time at which the games finished.
+	finishedGames := []game.FinishedGame{
+		game.FinishedGame{
+			GameName:          "third",
+			RulesetIdentifier: 1,
+			PlayerNames:       []string{"B", playerName},
+			Participants: []game.FinishedGameParticipant{
+				game.FinishedGameParticipant{PlayerName: "B", NumberOfTurnsTaken: 20},
+				game.FinishedGameParticipant{
+					PlayerName:         playerName,
+					NumberOfTurnsTaken: 20,
+					NumberOfDiscards:   6,
+					NumberOfMistakes:   1,
+				},
+			},
+			Score:        25,
+			MaximumScore: 25,
+			TimeOfFinish: startTime.Add(3 * time.Hour),
+		},
+		game.FinishedGame{
+			GameName:          "first",
+			RulesetIdentifier: 1,
+			PlayerNames:       []string{playerName, "B"},
+			Participants: []game.FinishedGameParticipant{
+				game.FinishedGameParticipant{
+					PlayerName:         playerName,
+					NumberOfTurnsTaken: 10,
+					NumberOfDiscards:   2,
+					NumberOfMistakes:   2,
+				},
+				game.FinishedGameParticipant{PlayerName: "B", NumberOfTurnsTaken: 10},
+			},
+			Score:        15,
+			MaximumScore: 25,
+			TimeOfFinish: startTime.Add(1 * time.Hour),
+		},
+		game.FinishedGame{
+			GameName:          "second",
+			RulesetIdentifier: 1,
+			PlayerNames:       []string{playerName, "B", "C"},
+			Participants: []game.FinishedGameParticipant{
+				game.FinishedGameParticipant{
+					PlayerName:         playerName,
+					NumberOfTurnsTaken: 6,
+					NumberOfDiscards:   0,
+					NumberOfMistakes:   2,
+				},
+				game.FinishedGameParticipant{PlayerName: "B", NumberOfTurnsTaken: 5},
+				game.FinishedGameParticipant{PlayerName: "C", NumberOfTurnsTaken: 5},
+			},
+			Score:                   0,
+			MaximumScore:            25,
+			IsOverBecauseOfMistakes: true,
+			TimeOfFinish:            startTime.Add(2 * time.Hour),
+		},
+		game.FinishedGame{
+			GameName:          "without player",
+			RulesetIdentifier: 1,
+			PlayerNames:       []string{"B", "C"},
+			Participants: []game.FinishedGameParticipant{
+				game.FinishedGameParticipant{PlayerName: "B", NumberOfTurnsTaken: 1},
+				game.FinishedGameParticipant{PlayerName: "C", NumberOfTurnsTaken: 1},
+			},
+			Score:        1,
+			MaximumScore: 25,
+			TimeOfFinish: startTime,
+		},
+	}
+
+	expectedStatistics := game.PlayerStatistics{
+		PlayerName:                               playerName,
+		NumberOfGamesPlayed:                      5,
+		NumberOfGamesFinished:                    3,
+		NumberOfTurnsTaken:                       36,
+		NumberOfDiscards:                         8,
+		NumberOfMistakes:                         5,
+		DiscardRate:                              8.0 / 36.0,
+		MistakeRate:                              5.0 / 36.0,
+		CurrentStreakWithoutGameOverFromMistakes: 1,
+		LongestStreakWithoutGameOverFromMistakes: 1,
+		ByRulesetAndPlayerCount: []game.StatisticsForRulesetAndPlayerCount{
+			game.StatisticsForRulesetAndPlayerCount{
+				RulesetIdentifier:     1,
+				NumberOfPlayers:       2,
+				NumberOfGamesFinished: 2,
+				NumberOfPerfectGames:  1,
+				AverageScore:          20.0,
+				BestScore:             25,
+				MaximumScore:          25,
+			},
+			game.StatisticsForRulesetAndPlayerCount{
+				RulesetIdentifier:     1,
+				NumberOfPlayers:       3,
+				NumberOfGamesFinished: 1,
+				NumberOfPerfectGames:  0,
+				AverageScore:          0.0,
+				BestScore:             0,
+				MaximumScore:          25,
+			},
+		},
+	}
+
+	actualStatistics := game.StatisticsForPlayer(playerName, finishedGames, 2)
+
+	if !reflect.DeepEqual(actualStatistics, expectedStatistics) {
+		unitTest.Fatalf(
+			"StatisticsForPlayer(...) produced %+v, expected %+v",
+			actualStatistics,
+			expectedStatistics)
+	}
+}
+
+func TestRejectStatisticsForUnregisteredPlayer(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			playerStatistics, errorFromStatistics :=
+				collectionAndDescription.GameCollection.StatisticsForPlayer(
+					context.Background(),
+					"not a registered player")
+
+			if errorFromStatistics == nil {
+				unitTest.Fatalf(
+					"StatisticsForPlayer(...) did not produce expected error, instead gave %+v",
+					playerStatistics)
+			}
+		})
+	}
+}
+
+func TestFinishedGameIsRecordedForStatistics(unitTest *testing.T) {
+	for _, collectionAndDescription := range prepareCollections(unitTest) {
+		unitTest.Run(collectionAndDescription.CollectionDescription, func(unitTest *testing.T) {
+			gameCollection := collectionAndDescription.GameCollection
+			playerNames := playerNamesAvailableInTest[:2]
+
+			prepareFinishedGame(unitTest, gameCollection, "Finished game", playerNames)
+
+			errorFromAdd :=
+				gameCollection.AddNew(
+					context.Background(),
+					"Unfinished game",
+					testRuleset,
+					playerNames)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf("AddNew(...) produced unexpected error %v", errorFromAdd)
+			}
+
+			playerStatistics, errorFromStatistics :=
+				gameCollection.StatisticsForPlayer(context.Background(), playerNames[0])
+
+			if errorFromStatistics != nil {
+				unitTest.Fatalf(
+					"StatisticsForPlayer(...) produced unexpected error %v",
+					errorFromStatistics)
+			}
+
+			// Each player played a single card in the finished game, so there were no
+			// discards.
+			if (playerStatistics.NumberOfGamesPlayed != 2) ||
+				(playerStatistics.NumberOfGamesFinished != 1) ||
+				(playerStatistics.NumberOfTurnsTaken != 1) ||
+				(playerStatistics.NumberOfDiscards != 0) ||
+				(len(playerStatistics.ByRulesetAndPlayerCount) != 1) {
+				unitTest.Fatalf(
+					"StatisticsForPlayer(...) produced unexpected %+v",
+					playerStatistics)
+			}
+
+			statisticsForCombination := playerStatistics.ByRulesetAndPlayerCount[0]
+
+			if (statisticsForCombination.RulesetIdentifier != testRuleset.BackendIdentifier()) ||
+				(statisticsForCombination.NumberOfPlayers != len(playerNames)) ||
+				(statisticsForCombination.MaximumScore != game.MaximumScore(testRuleset)) {
+				unitTest.Fatalf(
+					"StatisticsForPlayer(...) produced unexpected statistics for combination %+v",
+					statisticsForCombination)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+// TurnNotGiven is the expected turn of an action which does not say which turn the
+// acting player saw, such as an action from a client which predates the check, so
+// that the action is not checked against the current turn. Turns start at 1.
+const TurnNotGiven = 0
+
+// TurnConflictError is returned when a turn is based on a turn of a game which is no
+// longer the current turn, as happens when another request has taken a turn since
+// the acting player saw the game. Chat messages do not change the turn, so they never
+// cause a conflict.
+type TurnConflictError struct {
+	message string
+}
+
+// Error returns the message of the error.
+func (turnConflictError *TurnConflictError) Error() string {
+	return turnConflictError.message
+}
+
+// ErrorCode returns the code which identifies turn conflicts to clients.
+func (turnConflictError *TurnConflictError) ErrorCode() string {
+	return failure.CodeTurnConflict
+}
+
+// NewTurnConflictError creates a TurnConflictError describing that the given game is
+// at the given actual turn rather than the given expected turn.
+func NewTurnConflictError(
+	gameName string,
+	expectedTurn int,
+	actualTurn int) error {
+	errorMessage :=
+		fmt.Sprintf(
+			"Game %v has moved on from turn %v to turn %v, so the turn was not taken",
+			gameName,
+			expectedTurn,
+			actualTurn)
+
+	return &TurnConflictError{message: errorMessage}
+}
+
+// ErrorIfTurnIsNot returns a TurnConflictError if the given state is not at the given
+// turn, and nil otherwise, or if the expected turn is TurnNotGiven.
+func ErrorIfTurnIsNot(gameState ReadonlyState, expectedTurn int) error {
+	if (expectedTurn == TurnNotGiven) || (gameState.Turn() == expectedTurn) {
+		return nil
+	}
+
+	return NewTurnConflictError(gameState.Name(), expectedTurn, gameState.Turn())
+}
@@ -0,0 +1,141 @@
+package naming
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rules holds what is allowed in the names of players and games, and whether names
+// which differ only by case count as the same name. The player and game persisters
+// each hold a set of rules and apply it to every name which they store, so that the
+// same name is treated the same way whichever persister is used.
+type Rules struct {
+	// MinimumLength is the smallest number of characters allowed in a name, after it
+	// has been normalized.
+	MinimumLength int
+
+	// MaximumLength is the largest number of characters allowed in a name, after it
+	// has been normalized.
+	MaximumLength int
+
+	// AllowedCharacters are the ranges of characters allowed in a name. Spaces are
+	// always allowed between other characters.
+	AllowedCharacters []*unicode.RangeTable
+
+	// IsCaseSensitive is false if names which differ only by case should be treated as
+	// the same name, so that "Ann" and "ann" cannot both be taken.
+	IsCaseSensitive bool
+}
+
+// DefaultRules returns rules which allow names of up to 128 letters, marks, digits,
+// punctuation characters, symbols, and spaces, and which treat names which differ only
+// by case as the same name.
+func DefaultRules() Rules {
+	return Rules{
+		MinimumLength: 1,
+		MaximumLength: 128,
+		AllowedCharacters: []*unicode.RangeTable{
+			unicode.L,
+			unicode.M,
+			unicode.N,
+			unicode.P,
+			unicode.S,
+		},
+		IsCaseSensitive: false,
+	}
+}
+
+// Normalize returns the given name in the form in which it should be stored, or an
+// error if the name does not follow the rules, described by the given description of
+// what sort of name it is, such as "Player name". The name is put into Unicode
+// normalization form C, so that the same text typed on different devices gives the
+// same name, and surrounding whitespace is removed, and any run of whitespace within
+// the name is replaced by a single space.
+func (nameRules Rules) Normalize(
+	nameDescription string,
+	givenName string) (string, error) {
+	if !utf8.ValidString(givenName) {
+		return "", fmt.Errorf("%v is not valid UTF-8", nameDescription)
+	}
+
+	normalizedName :=
+		strings.Join(strings.Fields(norm.NFC.String(givenName)), " ")
+
+	nameLength := utf8.RuneCountInString(normalizedName)
+
+	if nameLength < nameRules.MinimumLength {
+		return "", fmt.Errorf(
+			"%v %q must have at least %v characters, not counting surrounding spaces",
+			nameDescription,
+			givenName,
+			nameRules.MinimumLength)
+	}
+
+	if nameLength > nameRules.MaximumLength {
+		return "", fmt.Errorf(
+			"%v %q must have at most %v characters",
+			nameDescription,
+			givenName,
+			nameRules.MaximumLength)
+	}
+
+	for _, nameCharacter := range normalizedName {
+		if (nameCharacter != ' ') &&
+			!unicode.IsOneOf(nameRules.AllowedCharacters, nameCharacter) {
+			return "", fmt.Errorf(
+				"%v %q must not contain the character %U",
+				nameDescription,
+				givenName,
+				nameCharacter)
+		}
+	}
+
+	return normalizedName, nil
+}
+
+// LookupForm returns the form of the given name under which anything named by it would
+// have been stored, which is the name normalized according to the rules, so that a name
+// typed with different spacing or in a different Unicode form finds what is stored
+// under the normalized name. A name which does not follow the rules is returned as it
+// was given, as nothing can have been stored under it, so looking it up finds nothing.
+func (nameRules Rules) LookupForm(givenName string) string {
+	normalizedName, errorFromName := nameRules.Normalize("Name", givenName)
+	if errorFromName != nil {
+		return givenName
+	}
+
+	return normalizedName
+}
+
+// UniquenessKey returns the form of the given normalized name which is compared with
+// the keys of other names to check that no name is taken twice. If the rules are not
+// case-sensitive, every character is replaced by the same character of its Unicode
+// case-folding orbit, so that names which differ only by case have the same key. This
+// is simple case folding, so for example "ß" and "ss" are still different.
+func (nameRules Rules) UniquenessKey(normalizedName string) string {
+	if nameRules.IsCaseSensitive {
+		return normalizedName
+	}
+
+	return strings.Map(foldedCharacter, normalizedName)
+}
+
+// foldedCharacter returns the character with the lowest code point among the
+// characters which are equal to the given character under simple case folding.
+func foldedCharacter(givenCharacter rune) rune {
+	lowestCharacter := givenCharacter
+	equivalentCharacter := unicode.SimpleFold(givenCharacter)
+	for equivalentCharacter != givenCharacter {
+		if equivalentCharacter < lowestCharacter {
+			lowestCharacter = equivalentCharacter
+		}
+
+		equivalentCharacter = unicode.SimpleFold(equivalentCharacter)
+	}
+
+	return lowestCharacter
+}
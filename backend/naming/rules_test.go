@@ -0,0 +1,210 @@
+package naming_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/benoleary/ilutulestikud/backend/naming"
+)
+
+func TestNormalizeValidNames(unitTest *testing.T) {
+	testCases := []struct {
+		testName        string
+		givenName       string
+		expectedName    string
+		expectedKey     string
+		isCaseSensitive bool
+	}{
+		{
+			testName:     "already normalized",
+			givenName:    "Ann",
+			expectedName: "Ann",
+			expectedKey:  "ANN",
+		},
+		{
+			testName:     "surrounding and repeated whitespace",
+			givenName:    " \tAnn  the Bold \n",
+			expectedName: "Ann the Bold",
+			expectedKey:  "ANN THE BOLD",
+		},
+		{
+			testName:     "decomposed accent",
+			givenName:    "Zoe\u0301",
+			expectedName: "Zo\u00e9",
+			expectedKey:  "ZO\u00c9",
+		},
+		{
+			testName:     "case differs",
+			givenName:    "aNN",
+			expectedName: "aNN",
+			expectedKey:  "ANN",
+		},
+		{
+			testName:     "non-Latin script",
+			givenName:    "Ilutulestik \u03a3\u03bf\u03c6\u03af\u03b1",
+			expectedName: "Ilutulestik \u03a3\u03bf\u03c6\u03af\u03b1",
+			expectedKey:  "ILUTULESTIK \u03a3\u039f\u03a6\u038a\u0391",
+		},
+		{
+			testName:        "case-sensitive",
+			givenName:       "aNN",
+			expectedName:    "aNN",
+			expectedKey:     "aNN",
+			isCaseSensitive: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			nameRules := naming.DefaultRules()
+			nameRules.IsCaseSensitive = testCase.isCaseSensitive
+
+			actualName, errorFromNormalize :=
+				nameRules.Normalize("Test name", testCase.givenName)
+
+			if (errorFromNormalize != nil) || (actualName != testCase.expectedName) {
+				unitTest.Fatalf(
+					"Normalize(%q) produced %q, %v - expected %q",
+					testCase.givenName,
+					actualName,
+					errorFromNormalize,
+					testCase.expectedName)
+			}
+
+			actualKey := nameRules.UniquenessKey(actualName)
+			if actualKey != testCase.expectedKey {
+				unitTest.Fatalf(
+					"UniquenessKey(%q) produced %q - expected %q",
+					actualName,
+					actualKey,
+					testCase.expectedKey)
+			}
+		})
+	}
+}
+
+func TestSameKeyForNamesDifferingOnlyByCaseOrForm(unitTest *testing.T) {
+	nameRules := naming.DefaultRules()
+	equivalentNames := []string{"\u00c9lise", "e\u0301lise", "\u00c9LISE", " \u00e9lise "}
+
+	var firstKey string
+	for nameIndex, givenName := range equivalentNames {
+		normalizedName, errorFromNormalize := nameRules.Normalize("Test name", givenName)
+		if errorFromNormalize != nil {
+			unitTest.Fatalf(
+				"Normalize(%q) produced error %v",
+				givenName,
+				errorFromNormalize)
+		}
+
+		nameKey := nameRules.UniquenessKey(normalizedName)
+		if nameIndex == 0 {
+			firstKey = nameKey
+		} else if nameKey != firstKey {
+			unitTest.Fatalf(
+				"UniquenessKey(%q) produced %q, expected %q as for %q",
+				normalizedName,
+				nameKey,
+				firstKey,
+				equivalentNames[0])
+		}
+	}
+}
+
+func TestLookupFormIsNormalizedNameOrNameAsGiven(unitTest *testing.T) {
+	nameRules := naming.DefaultRules()
+
+	testCases := []struct {
+		givenName          string
+		expectedLookupForm string
+	}{
+		{
+			givenName:          " Cafe\u0301   game ",
+			expectedLookupForm: "Caf\u00e9 game",
+		},
+		{
+			givenName:          "Test\u0007game ",
+			expectedLookupForm: "Test\u0007game ",
+		},
+	}
+
+	for _, testCase := range testCases {
+		lookupForm := nameRules.LookupForm(testCase.givenName)
+		if lookupForm != testCase.expectedLookupForm {
+			unitTest.Fatalf(
+				"LookupForm(%q) produced %q, expected %q",
+				testCase.givenName,
+				lookupForm,
+				testCase.expectedLookupForm)
+		}
+	}
+}
+
+func TestRejectInvalidNames(unitTest *testing.T) {
+	lettersOnly := naming.DefaultRules()
+	lettersOnly.AllowedCharacters = []*unicode.RangeTable{unicode.L}
+
+	testCases := []struct {
+		testName  string
+		givenName string
+		nameRules naming.Rules
+	}{
+		{
+			testName:  "empty",
+			givenName: "",
+			nameRules: naming.DefaultRules(),
+		},
+		{
+			testName:  "only whitespace",
+			givenName: " \t ",
+			nameRules: naming.DefaultRules(),
+		},
+		{
+			testName:  "too long",
+			givenName: strings.Repeat("a", naming.DefaultRules().MaximumLength+1),
+			nameRules: naming.DefaultRules(),
+		},
+		{
+			testName:  "control character",
+			givenName: "Ann\u0007",
+			nameRules: naming.DefaultRules(),
+		},
+		{
+			testName:  "format character",
+			givenName: "An\u200bn",
+			nameRules: naming.DefaultRules(),
+		},
+		{
+			testName:  "invalid UTF-8",
+			givenName: "Ann\xff",
+			nameRules: naming.DefaultRules(),
+		},
+		{
+			testName:  "digit not allowed",
+			givenName: "Ann2",
+			nameRules: lettersOnly,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			actualName, errorFromNormalize :=
+				testCase.nameRules.Normalize("Test name", testCase.givenName)
+
+			if errorFromNormalize == nil {
+				unitTest.Fatalf(
+					"Normalize(%q) produced %q rather than an error",
+					testCase.givenName,
+					actualName)
+			}
+
+			if !strings.HasPrefix(errorFromNormalize.Error(), "Test name") {
+				unitTest.Fatalf(
+					"Normalize(%q) produced error %q which does not describe the name",
+					testCase.givenName,
+					errorFromNormalize)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinimumContrastRatio is the lowest contrast ratio, as defined by the Web Content
+// Accessibility Guidelines, which a chosen chat color must have against the background
+// of the chat. It is the ratio required for normal text at level AA.
+const MinimumContrastRatio = 4.5
+
+// nearDuplicateDistance is the distance in the "redmean" approximation of perceived
+// color difference below which two chat colors are considered to be too similar to
+// tell apart easily.
+const nearDuplicateDistance = 100.0
+
+// namedColorsAsHex maps the names of the colors in the default palette to their
+// values as #RRGGBB, so that players with those colors can be compared with players
+// with arbitrary colors.
+var namedColorsAsHex = map[string]string{
+	"pink":   "#ffc0cb",
+	"red":    "#ff0000",
+	"orange": "#ffa500",
+	"yellow": "#ffff00",
+	"green":  "#008000",
+	"blue":   "#0000ff",
+	"purple": "#800080",
+	"white":  "#ffffff",
+	"black":  "#000000",
+}
+
+// rgbColor holds the red, green, and blue components of a color, each from 0 to 255.
+type rgbColor struct {
+	red   float64
+	green float64
+	blue  float64
+}
+
+// IsHexColor returns true if the given string is a color in the form #RRGGBB with
+// hexadecimal digits of either case.
+func IsHexColor(colorText string) bool {
+	_, errorFromParse := parseHexColor(colorText)
+	return errorFromParse == nil
+}
+
+// NormalizeHexColor returns the given color in the form #rrggbb with lower-case
+// hexadecimal digits, so that the same color is always stored the same way.
+func NormalizeHexColor(colorText string) string {
+	return strings.ToLower(colorText)
+}
+
+// ContrastRatio returns the contrast ratio between the given colors as defined by the
+// Web Content Accessibility Guidelines, which ranges from 1 for identical colors to
+// 21 for black against white. Each color may be in the form #RRGGBB or be one of the
+// names of the colors of the default palette.
+func ContrastRatio(firstColor string, secondColor string) (float64, error) {
+	firstRgb, errorFromFirst := rgbForColor(firstColor)
+	if errorFromFirst != nil {
+		return 0.0, errorFromFirst
+	}
+
+	secondRgb, errorFromSecond := rgbForColor(secondColor)
+	if errorFromSecond != nil {
+		return 0.0, errorFromSecond
+	}
+
+	lighterLuminance := firstRgb.relativeLuminance()
+	darkerLuminance := secondRgb.relativeLuminance()
+
+	if darkerLuminance > lighterLuminance {
+		lighterLuminance, darkerLuminance = darkerLuminance, lighterLuminance
+	}
+
+	return (lighterLuminance + 0.05) / (darkerLuminance + 0.05), nil
+}
+
+// AreNearDuplicates returns true if the given colors are too similar to tell apart
+// easily in a chat log. Colors which cannot be interpreted are never considered to be
+// near-duplicates of anything other than exactly the same string.
+func AreNearDuplicates(firstColor string, secondColor string) bool {
+	if strings.EqualFold(firstColor, secondColor) {
+		return true
+	}
+
+	firstRgb, errorFromFirst := rgbForColor(firstColor)
+	secondRgb, errorFromSecond := rgbForColor(secondColor)
+
+	if (errorFromFirst != nil) || (errorFromSecond != nil) {
+		return false
+	}
+
+	return firstRgb.distanceTo(secondRgb) < nearDuplicateDistance
+}
+
+// isNearDuplicateOfAny returns true if the given color is nearly the same as any of
+// the given other colors.
+func isNearDuplicateOfAny(chatColor string, otherColors []string) bool {
+	for _, otherColor := range otherColors {
+		if AreNearDuplicates(chatColor, otherColor) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rgbForColor returns the components of the given color, which may be in the form
+// #RRGGBB or be one of the names of the colors of the default palette.
+func rgbForColor(colorText string) (rgbColor, error) {
+	hexForName, isKnownName := namedColorsAsHex[strings.ToLower(colorText)]
+	if isKnownName {
+		return parseHexColor(hexForName)
+	}
+
+	return parseHexColor(colorText)
+}
+
+// parseHexColor returns the components of the given color, which must be in the form
+// #RRGGBB.
+func parseHexColor(colorText string) (rgbColor, error) {
+	if (len(colorText) != 7) || (colorText[0] != '#') {
+		return rgbColor{}, fmt.Errorf("Color %v is not of the form #RRGGBB", colorText)
+	}
+
+	colorComponents := make([]float64, 3)
+	for componentIndex := range colorComponents {
+		digitStart := 1 + (2 * componentIndex)
+		componentValue, errorFromParse :=
+			strconv.ParseUint(colorText[digitStart:digitStart+2], 16, 8)
+		if errorFromParse != nil {
+			return rgbColor{}, fmt.Errorf("Color %v is not of the form #RRGGBB", colorText)
+		}
+
+		colorComponents[componentIndex] = float64(componentValue)
+	}
+
+	parsedColor :=
+		rgbColor{
+			red:   colorComponents[0],
+			green: colorComponents[1],
+			blue:  colorComponents[2],
+		}
+
+	return parsedColor, nil
+}
+
+// relativeLuminance returns the relative luminance of the color as defined by the Web
+// Content Accessibility Guidelines.
+func (colorComponents rgbColor) relativeLuminance() float64 {
+	return (0.2126 * linearizedComponent(colorComponents.red)) +
+		(0.7152 * linearizedComponent(colorComponents.green)) +
+		(0.0722 * linearizedComponent(colorComponents.blue))
+}
+
+// distanceTo returns the "redmean" approximation of the perceived difference between
+// the colors, which weights the differences of the components according to how red
+// the colors are. It ranges from 0 for identical colors to about 765 for black and
+// white.
+func (colorComponents rgbColor) distanceTo(otherColor rgbColor) float64 {
+	redMean := (colorComponents.red + otherColor.red) / 2.0
+	redDifference := colorComponents.red - otherColor.red
+	greenDifference := colorComponents.green - otherColor.green
+	blueDifference := colorComponents.blue - otherColor.blue
+
+	return math.Sqrt(
+		((2.0 + (redMean / 256.0)) * redDifference * redDifference) +
+			(4.0 * greenDifference * greenDifference) +
+			((2.0 + ((255.0 - redMean) / 256.0)) * blueDifference * blueDifference))
+}
+
+// linearizedComponent converts a component of a color in the sRGB color space to its
+// linear value from 0 to 1.
+func linearizedComponent(componentValue float64) float64 {
+	scaledValue := componentValue / 255.0
+	if scaledValue <= 0.03928 {
+		return scaledValue / 12.92
+	}
+
+	return math.Pow((scaledValue+0.055)/1.055, 2.4)
+}
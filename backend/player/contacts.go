@@ -0,0 +1,71 @@
+package player
+
+// Group is a named set of players which a player can invite to a game together, so
+// that the same players do not have to be chosen one by one for every game.
+type Group struct {
+	Name              string
+	MemberIdentifiers []string
+}
+
+// Contacts holds the friends and the groups of a player, referring to the other
+// players by their identifiers so that renaming a player does not break them. The
+// owner of the contacts is never among the friends or the members of a group, as the
+// owner is always the host of a game created from a group. The zero value is valid
+// and means that the player has no friends and no groups.
+type Contacts struct {
+	FriendIdentifiers []string
+	Groups            []Group
+}
+
+// IsFriend returns true if the given player is among the friends.
+func (playerContacts Contacts) IsFriend(playerIdentifier string) bool {
+	for _, friendIdentifier := range playerContacts.FriendIdentifiers {
+		if friendIdentifier == playerIdentifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GroupWithName returns the group with the given name and true, or an empty group and
+// false if there is no group with that name.
+func (playerContacts Contacts) GroupWithName(groupName string) (Group, bool) {
+	for _, playerGroup := range playerContacts.Groups {
+		if playerGroup.Name == groupName {
+			return playerGroup, true
+		}
+	}
+
+	return Group{}, false
+}
+
+// DeepCopy returns a copy of the contacts which does not share any slices.
+func (playerContacts Contacts) DeepCopy() Contacts {
+	copiedContacts := Contacts{}
+
+	if playerContacts.FriendIdentifiers != nil {
+		copiedContacts.FriendIdentifiers =
+			copyIdentifiers(playerContacts.FriendIdentifiers)
+	}
+
+	if playerContacts.Groups != nil {
+		copiedContacts.Groups = make([]Group, len(playerContacts.Groups))
+		for groupIndex, playerGroup := range playerContacts.Groups {
+			copiedContacts.Groups[groupIndex] =
+				Group{
+					Name:              playerGroup.Name,
+					MemberIdentifiers: copyIdentifiers(playerGroup.MemberIdentifiers),
+				}
+		}
+	}
+
+	return copiedContacts
+}
+
+// copyIdentifiers returns a copy of the given slice of identifiers.
+func copyIdentifiers(identifiersToCopy []string) []string {
+	copiedIdentifiers := make([]string, len(identifiersToCopy))
+	copy(copiedIdentifiers, identifiersToCopy)
+	return copiedIdentifiers
+}
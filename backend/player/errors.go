@@ -0,0 +1,28 @@
+package player
+
+import (
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+// These are the kinds of error from this package and its persisters which clients
+// may want to handle in particular ways. Errors of these kinds are created by calling
+// Errorf on them, so that they can be recognized with errors.Is.
+var (
+	// ErrUnknownPlayer is returned when there is no player with the requested name
+	// or identifier.
+	ErrUnknownPlayer = failure.NewSentinel(failure.CodeUnknownPlayer, "Player is not registered")
+
+	// ErrDeletedPlayer is returned when the requested player has been deleted.
+	ErrDeletedPlayer = failure.NewSentinel(failure.CodeDeletedPlayer, "Player has been deleted")
+
+	// ErrNameTaken is returned when a player is registered or renamed with the name of
+	// another player.
+	ErrNameTaken = failure.NewSentinel(failure.CodeNameTaken, "Player already exists")
+
+	// ErrIncorrectCredentials is returned when a player name and password do not match.
+	ErrIncorrectCredentials = failure.NewSentinel(failure.CodeIncorrectCredentials, "Incorrect player name or password")
+
+	// ErrPlayerInGame is returned when a player who is still in a game is deleted
+	// without leaving a tombstone.
+	ErrPlayerInGame = failure.NewSentinel(failure.CodePlayerInGame, "Player is a participant in a game")
+)
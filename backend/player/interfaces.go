@@ -2,22 +2,104 @@ package player
 
 import "context"
 
+// RoleAdministrator is the role of players who may manage the games and players of
+// other players, such as deleting them.
+const RoleAdministrator = "admin"
+
+// RolePlayer is the role of normal players, who may only manage their own games and
+// their own registration.
+const RolePlayer = "player"
+
+// RoleDeleted is the role of tombstones left in place of deleted players, so that
+// the games in which they participated can still show their names and colors.
+// Tombstones cannot log in and are not listed among the registered players.
+const RoleDeleted = "deleted"
+
+// DeletionPolicy determines what a StateCollection does when a player is deleted.
+type DeletionPolicy int
+
+const (
+	// DeletionLeavesTombstone means that deleted players are replaced by tombstones
+	// with the same identifier, name, and color, and the role RoleDeleted. The name of
+	// a deleted player remains taken.
+	DeletionLeavesTombstone DeletionPolicy = iota
+
+	// DeletionBlockedWhileInGames means that players cannot be deleted while they are
+	// participants in any game, and are otherwise removed completely.
+	DeletionBlockedWhileInGames
+)
+
+// ParticipationChecker defines what a struct should do to allow a StateCollection to
+// determine whether a player can be deleted without breaking games, and which other
+// players share games with a player.
+type ParticipationChecker interface {
+	// IsParticipantInAnyGame should return true if the given player is a participant
+	// in any game.
+	IsParticipantInAnyGame(
+		executionContext context.Context,
+		playerIdentifier string) (bool, error)
+
+	// FellowParticipants should return the identifiers of the players other than the
+	// given player who are participants in any game with the given player, each only
+	// once, in no particular order.
+	FellowParticipants(
+		executionContext context.Context,
+		playerIdentifier string) ([]string, error)
+}
+
 // ReadonlyState defines the interface for structs which should encapsulate the state
 // of a player which can be read but not written.
 type ReadonlyState interface {
-	// Name should return the name of the player as known to the games.
+	// Identifier should return the identifier of the player, which never changes and
+	// which is how games refer to the player.
+	Identifier() string
+
+	// Name should return the name of the player as displayed to other players, which
+	// may be changed.
 	Name() string
 
 	// Color should return the color that the player uses for chat messages.
 	Color() string
+
+	// Role should return the role of the player, which is RoleAdministrator, RolePlayer,
+	// or RoleDeleted.
+	Role() string
+
+	// Profile should return the preferences of the player.
+	Profile() Profile
+
+	// Contacts should return the friends and groups of the player.
+	Contacts() Contacts
 }
 
 // ReadAndWriteState provides a simple implementation of the ReadonlyState interface
 // which is used by several persisters as a simple struct to emit as an instance of
-// ReadonlyState.
+// ReadonlyState. It also holds the salted hash of the password of the player, which
+// is deliberately not part of the ReadonlyState interface. The key of the name is the
+// form of the name which persisters compare to ensure that names are unique according
+// to their naming rules, and is empty for players stored before it was introduced.
 type ReadAndWriteState struct {
-	PlayerName string
-	ChatColor  string
+	PlayerIdentifier string
+	PlayerName       string
+	PlayerNameKey    string
+	ChatColor        string
+	PlayerRole       string
+	PasswordHash     string   `datastore:",noindex"`
+	PlayerProfile    Profile  `datastore:",noindex"`
+	PlayerContacts   Contacts `datastore:",noindex"`
+}
+
+// Identifier implents one of the requirements for the ReadonlyState interface.
+// Players which were persisted before identifiers were introduced have no stored
+// identifier, and were known to the games by their names, so their names are their
+// identifiers. Persisters store the identifier explicitly whenever they write such a
+// player, so that renaming the player does not change the identifier.
+func (readAndWriteState *ReadAndWriteState) Identifier() string {
+	if readAndWriteState.PlayerIdentifier == "" {
+		return readAndWriteState.PlayerName
+	}
+
+	return readAndWriteState.PlayerIdentifier
 }
 
 // Name implents one of the requirements for the ReadonlyState interface.
@@ -30,8 +112,64 @@ func (readAndWriteState *ReadAndWriteState) Color() string {
 	return readAndWriteState.ChatColor
 }
 
+// Role implents one of the requirements for the ReadonlyState interface. Players
+// which were persisted before roles were introduced have no stored role, and are
+// treated as normal players.
+func (readAndWriteState *ReadAndWriteState) Role() string {
+	if readAndWriteState.PlayerRole == "" {
+		return RolePlayer
+	}
+
+	return readAndWriteState.PlayerRole
+}
+
+// Profile implents one of the requirements for the ReadonlyState interface. Players
+// which were persisted before profiles were introduced have the zero profile.
+func (readAndWriteState *ReadAndWriteState) Profile() Profile {
+	return readAndWriteState.PlayerProfile
+}
+
+// Contacts implents one of the requirements for the ReadonlyState interface. Players
+// which were persisted before contacts were introduced have no friends and no groups.
+func (readAndWriteState *ReadAndWriteState) Contacts() Contacts {
+	return readAndWriteState.PlayerContacts
+}
+
+// NameOrder determines the order in which players are listed by their names. Names are
+// compared byte by byte in their UTF-8 encoding, so that every persister orders them
+// the same way.
+type NameOrder int
+
+const (
+	// NameAscending lists players from the lowest name to the highest.
+	NameAscending NameOrder = iota
+
+	// NameDescending lists players from the highest name to the lowest.
+	NameDescending
+)
+
+// SearchCriteria determines which players a StatePersister should return from a search.
+type SearchCriteria struct {
+	// NamePrefix is the string with which the names of the returned players must start.
+	// An empty prefix matches every player.
+	NamePrefix string
+
+	// Order is the order in which the players should be returned.
+	Order NameOrder
+
+	// AfterName is the name after which the returned players should start in the given
+	// order, not including the player with this name. If it is empty, the players start
+	// from the first in the given order.
+	AfterName string
+
+	// MaximumNumber is the largest number of players which should be returned.
+	MaximumNumber int
+}
+
 // StatePersister defines the interface for structs which should be able to create
-// objects implementing the ReadOnly interface out of player names with colors.
+// objects implementing the ReadOnly interface out of player identifiers and names with
+// colors. Players are keyed by their identifiers, but names also have to be unique so
+// that players can log in with their names.
 type StatePersister interface {
 	// All should return a slice of all the State instances in the persistence store.
 	// The order is not mandated, and may even change with repeated calls to the same
@@ -40,23 +178,76 @@ type StatePersister interface {
 	// consistently.
 	All(executionContext context.Context) ([]ReadonlyState, error)
 
-	// Get should return the read-only state corresponding to the given player name if it
-	// exists already along with an error which of course should be nil if there was no
-	// problem. If the player does not exist, a non-nil error should be returned along with
-	// nil for the read-only state.
-	Get(executionContext context.Context, playerName string) (ReadonlyState, error)
+	// Search should return a slice of at most the given maximum number of State
+	// instances which match the given criteria, in the given order. It should not need
+	// to read every player in the persistence store to do so.
+	Search(executionContext context.Context, searchCriteria SearchCriteria) ([]ReadonlyState, error)
+
+	// Get should return the read-only state corresponding to the given player identifier
+	// if it exists already along with an error which of course should be nil if there was
+	// no problem. If the player does not exist, a non-nil error should be returned along
+	// with nil for the read-only state.
+	Get(executionContext context.Context, playerIdentifier string) (ReadonlyState, error)
+
+	// IdentifierForName should return the identifier of the player with the given name,
+	// or an error if no player has the name. Names are compared as the naming rules of the
+	// persister compare them, so a player can log in as "ann " if registered as "Ann" and
+	// the rules are not case-sensitive.
+	IdentifierForName(executionContext context.Context, playerName string) (string, error)
 
 	// Add should add an element to the persistence store which is a new object
-	// implementing the ReadonlyState interface with information given by the arguments. If
+	// implementing the ReadonlyState interface with information given by the arguments,
+	// storing the given salted hash of the password of the player along with it. If
 	// there was no problem, the returned error should be nil. It should return an error if
-	// the player already exists.
-	Add(executionContext context.Context, playerName string, chatColor string) error
+	// the name does not follow the naming rules of the persister, or if a player with the
+	// identifier or with the same name according to those rules already exists. The name
+	// should be stored in the normalized form given by the naming rules.
+	Add(
+		executionContext context.Context,
+		playerIdentifier string,
+		playerName string,
+		chatColor string,
+		playerRole string,
+		passwordHash string) error
+
+	// PasswordHash should return the salted hash of the password of the given player,
+	// or an error if the player is not registered.
+	PasswordHash(executionContext context.Context, playerIdentifier string) (string, error)
+
+	// UpdateName should update the given player to have the given name. This should be
+	// thread-safe. It should return an error if there was a problem, including if the
+	// player is not registered, if the name does not follow the naming rules of the
+	// persister, or if another player already has the same name according to those rules.
+	// The name should be stored in the normalized form given by the naming rules.
+	UpdateName(executionContext context.Context, playerIdentifier string, playerName string) error
 
 	// UpdateColor should update the given player to have the given chat color.
 	// This should be thread-safe. It should return an error if there was a problem,
 	// including if the player is not registered.
-	UpdateColor(executionContext context.Context, playerName string, chatColor string) error
+	UpdateColor(executionContext context.Context, playerIdentifier string, chatColor string) error
+
+	// UpdateRole should update the given player to have the given role. If the role is
+	// RoleDeleted, it should also clear the password hash of the player in the same
+	// write, as a tombstone can never log in. This should be thread-safe. It should
+	// return an error if there was a problem, including if the player is not registered.
+	UpdateRole(executionContext context.Context, playerIdentifier string, playerRole string) error
+
+	// UpdateProfile should replace the profile of the given player with the given profile.
+	// This should be thread-safe. It should return an error if there was a problem,
+	// including if the player is not registered.
+	UpdateProfile(
+		executionContext context.Context,
+		playerIdentifier string,
+		playerProfile Profile) error
+
+	// UpdateContacts should replace the friends and groups of the given player with the
+	// given contacts. This should be thread-safe. It should return an error if there was
+	// a problem, including if the player is not registered.
+	UpdateContacts(
+		executionContext context.Context,
+		playerIdentifier string,
+		playerContacts Contacts) error
 
 	// Delete should delete the given player from the persistence store.
-	Delete(executionContext context.Context, playerName string) error
+	Delete(executionContext context.Context, playerIdentifier string) error
 }
@@ -0,0 +1,113 @@
+package player
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// passwordHashScheme identifies the scheme used to derive the hashes, so that a
+// different scheme could be introduced later without breaking stored hashes.
+const passwordHashScheme = "pbkdf2-sha256"
+
+// passwordHashIterations is the number of iterations of PBKDF2 used when hashing a
+// new password. Stored hashes record their own number of iterations.
+const passwordHashIterations = 100000
+
+// passwordSaltLength is the number of random bytes used as salt for each password.
+const passwordSaltLength = 16
+
+// passwordHashSeparator separates the parts of a stored password hash.
+const passwordHashSeparator = "$"
+
+// HashPassword returns a salted hash of the given password which is suitable for
+// storing, in the form scheme$iterations$salt$hash with the salt and hash encoded
+// in base 64. It uses PBKDF2 with HMAC-SHA256 and a random salt, so hashing the same
+// password twice gives different results.
+func HashPassword(plainPassword string) (string, error) {
+	if plainPassword == "" {
+		return "", fmt.Errorf("Password cannot be empty")
+	}
+
+	passwordSalt := make([]byte, passwordSaltLength)
+	_, errorFromRandom := rand.Read(passwordSalt)
+
+	if errorFromRandom != nil {
+		return "", errorFromRandom
+	}
+
+	derivedKey :=
+		derivePasswordKey([]byte(plainPassword), passwordSalt, passwordHashIterations)
+
+	return strings.Join(
+		[]string{
+			passwordHashScheme,
+			strconv.Itoa(passwordHashIterations),
+			base64.RawStdEncoding.EncodeToString(passwordSalt),
+			base64.RawStdEncoding.EncodeToString(derivedKey),
+		},
+		passwordHashSeparator), nil
+}
+
+// IsPasswordCorrect returns true if the given password matches the given stored
+// hash, and false otherwise, including if the hash is malformed.
+func IsPasswordCorrect(plainPassword string, passwordHash string) bool {
+	hashParts := strings.Split(passwordHash, passwordHashSeparator)
+
+	if (len(hashParts) != 4) || (hashParts[0] != passwordHashScheme) {
+		return false
+	}
+
+	numberOfIterations, errorFromIterations := strconv.Atoi(hashParts[1])
+	passwordSalt, errorFromSalt := base64.RawStdEncoding.DecodeString(hashParts[2])
+	storedKey, errorFromKey := base64.RawStdEncoding.DecodeString(hashParts[3])
+
+	if (errorFromIterations != nil) ||
+		(numberOfIterations < 1) ||
+		(errorFromSalt != nil) ||
+		(errorFromKey != nil) {
+		return false
+	}
+
+	derivedKey :=
+		derivePasswordKey([]byte(plainPassword), passwordSalt, numberOfIterations)
+
+	return hmac.Equal(derivedKey, storedKey)
+}
+
+// derivePasswordKey implements PBKDF2 (RFC 8018) with HMAC-SHA256 for a single block
+// of output, which is all that is needed for a 32-byte key, so that no dependency
+// outside the standard library is needed.
+func derivePasswordKey(
+	plainPassword []byte,
+	passwordSalt []byte,
+	numberOfIterations int) []byte {
+	pseudorandomFunction := hmac.New(sha256.New, plainPassword)
+
+	blockIndex := make([]byte, 4)
+	binary.BigEndian.PutUint32(blockIndex, 1)
+
+	pseudorandomFunction.Write(passwordSalt)
+	pseudorandomFunction.Write(blockIndex)
+	iteratedBlock := pseudorandomFunction.Sum(nil)
+
+	derivedKey := make([]byte, len(iteratedBlock))
+	copy(derivedKey, iteratedBlock)
+
+	for iterationCount := 1; iterationCount < numberOfIterations; iterationCount++ {
+		pseudorandomFunction.Reset()
+		pseudorandomFunction.Write(iteratedBlock)
+		iteratedBlock = pseudorandomFunction.Sum(iteratedBlock[:0])
+
+		for byteIndex := range derivedKey {
+			derivedKey[byteIndex] ^= iteratedBlock[byteIndex]
+		}
+	}
+
+	return derivedKey
+}
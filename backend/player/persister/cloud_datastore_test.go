@@ -53,6 +53,14 @@ func (mockClient *mockLimitedClient) AllMatching(
 	return mockClient.IteratorToReturn
 }
 
+func (mockClient *mockLimitedClient) AllMatchingInOrder(
+	executionContext context.Context,
+	filtersAndValues []cloud.FilterAndValue,
+	orderExpressions []string,
+	maximumNumber int) cloud.LimitedIterator {
+	return mockClient.IteratorToReturn
+}
+
 func (mockClient *mockLimitedClient) Get(
 	executionContext context.Context,
 	nameForKey string,
@@ -73,6 +81,12 @@ func (mockClient *mockLimitedClient) Delete(
 	return mockClient.ErrorToReturn
 }
 
+func (mockClient *mockLimitedClient) RunInTransaction(
+	executionContext context.Context,
+	transactionFunction func(cloud.LimitedTransaction) error) error {
+	return mockClient.ErrorToReturn
+}
+
 type mockClientProvider struct {
 	ClientToReturn *mockLimitedClient
 	ErrorToReturn  error
@@ -98,7 +112,7 @@ func TestReturnErrorFromInvalidProjectIdentifier(unitTest *testing.T) {
 	playerName := "Should Not Matter"
 	playerColor := "Should not matter"
 	errorFromAdd :=
-		cloudDatastorePersister.Add(executionContext, playerName, playerColor)
+		cloudDatastorePersister.Add(executionContext, playerName, playerName, playerColor, "", "")
 
 	if errorFromAdd == nil {
 		unitTest.Fatalf(
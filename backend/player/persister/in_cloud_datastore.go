@@ -3,8 +3,10 @@ package persister
 import (
 	"context"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/benoleary/ilutulestikud/backend/cloud"
+	"github.com/benoleary/ilutulestikud/backend/naming"
 	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
@@ -18,72 +20,246 @@ const CloudDatastoreKeyKind = "Player"
 type inCloudDatastorePersister struct {
 	clientProvider  cloud.DatastoreClientProvider
 	datastoreClient cloud.LimitedClient
+	nameRules       naming.Rules
 }
 
-// NewInCloudDatastore creates a game state persister.
+// NewInCloudDatastore creates a game state persister which applies the
+// default naming rules.
 func NewInCloudDatastore(
 	clientProvider cloud.DatastoreClientProvider) player.StatePersister {
-	return NewInCloudDatastoreWithGivenLimitedClient(clientProvider, nil)
+	return NewInCloudDatastoreWithNameRules(clientProvider, naming.DefaultRules())
+}
+
+// NewInCloudDatastoreWithNameRules creates a game state persister which
+// applies the given naming rules.
+func NewInCloudDatastoreWithNameRules(
+	clientProvider cloud.DatastoreClientProvider,
+	nameRules naming.Rules) player.StatePersister {
+	return &inCloudDatastorePersister{
+		clientProvider:  clientProvider,
+		datastoreClient: nil,
+		nameRules:       nameRules,
+	}
 }
 
 // NewInCloudDatastoreWithGivenLimitedClient creates a game state
-// persister using a given LimitedClient implementation.
+// persister using a given LimitedClient implementation, which applies
+// the default naming rules.
 func NewInCloudDatastoreWithGivenLimitedClient(
 	clientProvider cloud.DatastoreClientProvider,
 	datastoreClient cloud.LimitedClient) player.StatePersister {
 	return &inCloudDatastorePersister{
 		clientProvider:  clientProvider,
 		datastoreClient: datastoreClient,
+		nameRules:       naming.DefaultRules(),
 	}
 }
 
-// Add inserts the given name and color as a row in the database.
+// Add inserts the given identifier, name, color, role, and password hash as an
+// entity in the datastore, keyed by the identifier. It returns an error if the name
+// does not follow the naming rules, or if a player with the identifier or the same
+// name according to the naming rules already exists.
 func (playerPersister *inCloudDatastorePersister) Add(
 	executionContext context.Context,
+	playerIdentifier string,
 	playerName string,
-	chatColor string) error {
+	chatColor string,
+	playerRole string,
+	passwordHash string) error {
+	normalizedName, nameKey, errorFromNameCheck :=
+		playerPersister.normalizedNameUnlessTaken(
+			executionContext,
+			playerIdentifier,
+			playerName)
+
+	if errorFromNameCheck != nil {
+		return errorFromNameCheck
+	}
+
 	return playerPersister.insertOrOverwrite(
 		executionContext,
-		playerName,
-		chatColor,
+		player.ReadAndWriteState{
+			PlayerIdentifier: playerIdentifier,
+			PlayerName:       normalizedName,
+			PlayerNameKey:    nameKey,
+			ChatColor:        chatColor,
+			PlayerRole:       playerRole,
+			PasswordHash:     passwordHash,
+		},
 		false)
 }
 
-// UpdateColor updates the given player to have the given chat color. It
-// relies on the PostgreSQL driver to ensure thread safety.
+// UpdateName updates the given player to have the given name, unless the name does
+// not follow the naming rules or another player already has the same name according
+// to the naming rules, keeping the rest of the stored entity unchanged.
+func (playerPersister *inCloudDatastorePersister) UpdateName(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) error {
+	existingState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return player.ErrUnknownPlayer.Errorf("Player with identifier %v does not exist", playerIdentifier)
+	}
+
+	normalizedName, nameKey, errorFromNameCheck :=
+		playerPersister.normalizedNameUnlessTaken(
+			executionContext,
+			existingState.Identifier(),
+			playerName)
+
+	if errorFromNameCheck != nil {
+		return errorFromNameCheck
+	}
+
+	if (existingState.PlayerName == normalizedName) &&
+		(existingState.PlayerNameKey == nameKey) {
+		return nil
+	}
+
+	existingState.PlayerName = normalizedName
+	existingState.PlayerNameKey = nameKey
+
+	return playerPersister.insertOrOverwrite(
+		executionContext,
+		existingState,
+		true)
+}
+
+// UpdateColor updates the given player to have the given chat color, keeping
+// the rest of the stored entity unchanged.
 func (playerPersister *inCloudDatastorePersister) UpdateColor(
 	executionContext context.Context,
-	playerName string,
+	playerIdentifier string,
 	chatColor string) error {
+	existingState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return player.ErrUnknownPlayer.Errorf("Player with identifier %v does not exist", playerIdentifier)
+	}
+
+	existingState.ChatColor = chatColor
+
 	return playerPersister.insertOrOverwrite(
 		executionContext,
-		playerName,
-		chatColor,
+		existingState,
 		true)
 }
 
-// Get returns the ReadOnly corresponding to the given player name if it exists.
-func (playerPersister *inCloudDatastorePersister) Get(
+// UpdateRole updates the given player to have the given role, keeping the
+// rest of the stored entity unchanged, except that the password hash of a
+// tombstone is cleared.
+func (playerPersister *inCloudDatastorePersister) UpdateRole(
 	executionContext context.Context,
-	playerName string) (player.ReadonlyState, error) {
-	initializedClient, errorFromAcquiral :=
-		playerPersister.acquireClientIfValidName(executionContext, playerName)
+	playerIdentifier string,
+	playerRole string) error {
+	existingState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return player.ErrUnknownPlayer.Errorf("Player with identifier %v does not exist", playerIdentifier)
+	}
 
-	if errorFromAcquiral != nil {
-		return nil, errorFromAcquiral
+	existingState.PlayerRole = playerRole
+	if playerRole == player.RoleDeleted {
+		existingState.PasswordHash = ""
 	}
 
-	serializableState := player.ReadAndWriteState{}
+	return playerPersister.insertOrOverwrite(
+		executionContext,
+		existingState,
+		true)
+}
 
-	errorFromGet :=
-		initializedClient.Get(
-			executionContext,
-			playerName,
-			&serializableState)
+// UpdateProfile replaces the profile of the given player with the given profile,
+// keeping the rest of the stored entity unchanged.
+func (playerPersister *inCloudDatastorePersister) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile player.Profile) error {
+	existingState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return player.ErrUnknownPlayer.Errorf("Player with identifier %v does not exist", playerIdentifier)
+	}
+
+	existingState.PlayerProfile = playerProfile
+
+	return playerPersister.insertOrOverwrite(
+		executionContext,
+		existingState,
+		true)
+}
+
+// UpdateContacts replaces the friends and groups of the given player with the given
+// contacts, keeping the rest of the stored entity unchanged.
+func (playerPersister *inCloudDatastorePersister) UpdateContacts(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerContacts player.Contacts) error {
+	existingState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return player.ErrUnknownPlayer.Errorf("Player with identifier %v does not exist", playerIdentifier)
+	}
+
+	existingState.PlayerContacts = playerContacts
+
+	return playerPersister.insertOrOverwrite(
+		executionContext,
+		existingState,
+		true)
+}
+
+// Get returns the ReadOnly corresponding to the given player identifier if it exists.
+func (playerPersister *inCloudDatastorePersister) Get(
+	executionContext context.Context,
+	playerIdentifier string) (player.ReadonlyState, error) {
+	serializableState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
 
 	return &serializableState, errorFromGet
 }
 
+// IdentifierForName returns the identifier of the player with the same name as the
+// given name according to the naming rules, or an error if there is no such player.
+func (playerPersister *inCloudDatastorePersister) IdentifierForName(
+	executionContext context.Context,
+	playerName string) (string, error) {
+	errorForUnknownName := player.ErrUnknownPlayer.Errorf("No player with name %v is registered", playerName)
+
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return "", errorForUnknownName
+	}
+
+	matchingIdentifiers, errorFromQuery :=
+		playerPersister.identifiersWithName(
+			executionContext,
+			normalizedName,
+			playerPersister.nameRules.UniquenessKey(normalizedName))
+
+	if errorFromQuery != nil {
+		return "", errorFromQuery
+	}
+
+	if len(matchingIdentifiers) == 0 {
+		return "", errorForUnknownName
+	}
+
+	return matchingIdentifiers[0], nil
+}
+
+// PasswordHash returns the password hash of the given player if the player exists.
+func (playerPersister *inCloudDatastorePersister) PasswordHash(
+	executionContext context.Context,
+	playerIdentifier string) (string, error) {
+	serializableState, errorFromGet :=
+		playerPersister.getSerializable(executionContext, playerIdentifier)
+
+	return serializableState.PasswordHash, errorFromGet
+}
+
 // All returns a slice of all the players in the collection as ReadonlyState
 // instances, ordered as given by the database.
 func (playerPersister *inCloudDatastorePersister) All(
@@ -99,6 +275,72 @@ func (playerPersister *inCloudDatastorePersister) All(
 	// of the player type.
 	resultIterator := initializedClient.AllOfKind(executionContext)
 
+	return readAndWriteStatesFromIterator(resultIterator)
+}
+
+// Search returns a slice of at most the given maximum number of players whose names
+// start with the given prefix, sorted by name, starting after the given name. The
+// prefix is turned into a range of names, as the Cloud Datastore orders strings by the
+// bytes of their UTF-8 encoding, so that the query only reads the players which are
+// returned.
+func (playerPersister *inCloudDatastorePersister) Search(
+	executionContext context.Context,
+	searchCriteria player.SearchCriteria) ([]player.ReadonlyState, error) {
+	initializedClient, errorFromAcquiral :=
+		playerPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return nil, errorFromAcquiral
+	}
+
+	filtersAndValues := []cloud.FilterAndValue{}
+
+	if searchCriteria.NamePrefix != "" {
+		// Every name which starts with the prefix is lower than the prefix followed by
+		// the highest valid character.
+		filtersAndValues =
+			append(
+				filtersAndValues,
+				cloud.FilterAndValue{
+					FilterExpression: "PlayerName >=",
+					ValueToMatch:     searchCriteria.NamePrefix,
+				},
+				cloud.FilterAndValue{
+					FilterExpression: "PlayerName <",
+					ValueToMatch:     searchCriteria.NamePrefix + string(utf8.MaxRune),
+				})
+	}
+
+	orderExpression := "PlayerName"
+	filterForAfter := "PlayerName >"
+	if searchCriteria.Order == player.NameDescending {
+		orderExpression = "-PlayerName"
+		filterForAfter = "PlayerName <"
+	}
+
+	if searchCriteria.AfterName != "" {
+		filtersAndValues =
+			append(
+				filtersAndValues,
+				cloud.FilterAndValue{
+					FilterExpression: filterForAfter,
+					ValueToMatch:     searchCriteria.AfterName,
+				})
+	}
+
+	resultIterator :=
+		initializedClient.AllMatchingInOrder(
+			executionContext,
+			filtersAndValues,
+			[]string{orderExpression},
+			searchCriteria.MaximumNumber)
+
+	return readAndWriteStatesFromIterator(resultIterator)
+}
+
+// readAndWriteStatesFromIterator reads every player from the given iterator.
+func readAndWriteStatesFromIterator(
+	resultIterator cloud.LimitedIterator) ([]player.ReadonlyState, error) {
 	playerStates := []player.ReadonlyState{}
 
 	for {
@@ -119,11 +361,11 @@ func (playerPersister *inCloudDatastorePersister) All(
 	return playerStates, nil
 }
 
-// Delete deletes the given game from the collection. It returns an error
+// Delete deletes the given player from the collection. It returns an error
 // if the Cloud Datastore API returns an error.
 func (playerPersister *inCloudDatastorePersister) Delete(
 	executionContext context.Context,
-	playerName string) error {
+	playerIdentifier string) error {
 	initializedClient, errorFromAcquiral :=
 		playerPersister.acquireClient(executionContext)
 
@@ -133,7 +375,7 @@ func (playerPersister *inCloudDatastorePersister) Delete(
 
 	return initializedClient.Delete(
 		executionContext,
-		playerName)
+		playerIdentifier)
 }
 
 // acquireClient returns the connection to the Cloud Datastore,
@@ -163,13 +405,124 @@ func (playerPersister *inCloudDatastorePersister) acquireClientIfValidName(
 	return playerPersister.acquireClient(executionContext)
 }
 
+// normalizedNameUnlessTaken returns the given name normalized according to the naming
+// rules along with its key, or an error if the name does not follow the rules, or if a
+// player other than the given player already has the same name according to the rules,
+// or if there was a problem checking.
+func (playerPersister *inCloudDatastorePersister) normalizedNameUnlessTaken(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) (string, string, error) {
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return "", "", errorFromName
+	}
+
+	nameKey := playerPersister.nameRules.UniquenessKey(normalizedName)
+
+	matchingIdentifiers, errorFromQuery :=
+		playerPersister.identifiersWithName(executionContext, normalizedName, nameKey)
+
+	if errorFromQuery != nil {
+		return "", "", errorFromQuery
+	}
+
+	for _, matchingIdentifier := range matchingIdentifiers {
+		if matchingIdentifier != playerIdentifier {
+			return "", "", player.ErrNameTaken.Errorf("Player %v already exists", normalizedName)
+		}
+	}
+
+	return normalizedName, nameKey, nil
+}
+
+// identifiersWithName returns the identifiers of the players whose names have the
+// given key, along with those of the players stored before keys were introduced whose
+// names are exactly the given normalized name.
+func (playerPersister *inCloudDatastorePersister) identifiersWithName(
+	executionContext context.Context,
+	normalizedName string,
+	nameKey string) ([]string, error) {
+	initializedClient, errorFromAcquiral :=
+		playerPersister.acquireClientIfValidName(executionContext, normalizedName)
+
+	if errorFromAcquiral != nil {
+		return nil, errorFromAcquiral
+	}
+
+	matchingIdentifiers := make([]string, 0)
+
+	for _, filterAndValue := range [][]string{
+		[]string{"PlayerNameKey =", nameKey},
+		[]string{"PlayerName =", normalizedName},
+	} {
+		resultIterator :=
+			initializedClient.AllMatching(
+				executionContext,
+				filterAndValue[0],
+				filterAndValue[1])
+
+		matchingStates, errorFromIterator :=
+			readAndWriteStatesFromIterator(resultIterator)
+
+		if errorFromIterator != nil {
+			return nil, errorFromIterator
+		}
+
+		for _, matchingState := range matchingStates {
+			matchingIdentifiers = append(matchingIdentifiers, matchingState.Identifier())
+		}
+	}
+
+	return matchingIdentifiers, nil
+}
+
+// getSerializable retrieves the entity keyed by the given identifier. Players which
+// were stored before identifiers were introduced are keyed by their names, which are
+// therefore their identifiers, and have no stored identifier, so the identifier is set
+// from the key so that it is stored explicitly if the player is written again.
+func (playerPersister *inCloudDatastorePersister) getSerializable(
+	executionContext context.Context,
+	playerIdentifier string) (player.ReadAndWriteState, error) {
+	if playerIdentifier == "" {
+		return player.ReadAndWriteState{}, fmt.Errorf("Player must have an identifier")
+	}
+
+	initializedClient, errorFromAcquiral :=
+		playerPersister.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return player.ReadAndWriteState{}, errorFromAcquiral
+	}
+
+	serializableState := player.ReadAndWriteState{}
+
+	errorFromGet :=
+		initializedClient.Get(
+			executionContext,
+			playerIdentifier,
+			&serializableState)
+
+	serializableState.PlayerIdentifier = playerIdentifier
+
+	return serializableState, errorFromGet
+}
+
 func (playerPersister *inCloudDatastorePersister) insertOrOverwrite(
 	executionContext context.Context,
-	playerName string,
-	chatColor string,
+	serializableState player.ReadAndWriteState,
 	isUpdate bool) error {
+	playerIdentifier := serializableState.PlayerIdentifier
+
+	if playerIdentifier == "" {
+		return fmt.Errorf("Player must have an identifier")
+	}
+
 	initializedClient, errorFromAcquiral :=
-		playerPersister.acquireClientIfValidName(executionContext, playerName)
+		playerPersister.acquireClientIfValidName(
+			executionContext,
+			serializableState.PlayerName)
 
 	if errorFromAcquiral != nil {
 		return errorFromAcquiral
@@ -179,28 +532,22 @@ func (playerPersister *inCloudDatastorePersister) insertOrOverwrite(
 		cloud.DoesNameExist(
 			executionContext,
 			initializedClient,
-			playerName)
+			playerIdentifier)
 
 	if errorFromCheck != nil {
 		return errorFromCheck
 	}
 
 	if isAlreadyInDatastore && !isUpdate {
-		return fmt.Errorf("Player with name %v already exists", playerName)
+		return fmt.Errorf("Player with identifier %v already exists", playerIdentifier)
 	}
 
 	if !isAlreadyInDatastore && isUpdate {
-		return fmt.Errorf("Player with name %v does not exist", playerName)
+		return player.ErrUnknownPlayer.Errorf("Player with identifier %v does not exist", playerIdentifier)
 	}
 
-	serializableState :=
-		player.ReadAndWriteState{
-			PlayerName: playerName,
-			ChatColor:  chatColor,
-		}
-
 	return initializedClient.Put(
 		executionContext,
-		playerName,
+		playerIdentifier,
 		&serializableState)
 }
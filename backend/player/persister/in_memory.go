@@ -3,51 +3,128 @@ package persister
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/benoleary/ilutulestikud/backend/naming"
 	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
 // inMemoryPersister stores inMemoryState objects as instances of the
 // implementation of the ReadonlyState interface. The players are
-// mapped to by their names.
+// mapped to by their identifiers. There is nothing to migrate when
+// identifiers are introduced, as nothing is kept between restarts.
 type inMemoryPersister struct {
 	mutualExclusion sync.Mutex
+	nameRules       naming.Rules
 	playerStates    map[string]*player.ReadAndWriteState
 }
 
 // NewInMemory creates a player state persister around a map of players
 // created from the given initial player names, with colors according to
-// the available chat colors.
+// the available chat colors, which applies the default naming rules.
 func NewInMemory() player.StatePersister {
+	return NewInMemoryWithNameRules(naming.DefaultRules())
+}
+
+// NewInMemoryWithNameRules creates a player state persister around a map
+// of players which applies the given naming rules.
+func NewInMemoryWithNameRules(nameRules naming.Rules) player.StatePersister {
 	return &inMemoryPersister{
 		mutualExclusion: sync.Mutex{},
+		nameRules:       nameRules,
 		playerStates:    make(map[string]*player.ReadAndWriteState, 0),
 	}
 }
 
-// Add creates a new inMemoryState object with the given name and given
-// color, and adds a reference to it into the collection. It returns an
-// error if the player already exists. The context is ignored.
+// Add creates a new inMemoryState object with the given identifier, name,
+// color, role, and password hash, and adds a reference to it into the
+// collection. It returns an error if the name does not follow the naming
+// rules, or if a player with the identifier or the same name according to
+// the naming rules already exists. The context is ignored.
 func (playerPersister *inMemoryPersister) Add(
 	executionContext context.Context,
+	playerIdentifier string,
 	playerName string,
-	chatColor string) error {
-	_, playerExists := playerPersister.playerStates[playerName]
+	chatColor string,
+	playerRole string,
+	passwordHash string) error {
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	nameKey := playerPersister.nameRules.UniquenessKey(normalizedName)
+
+	playerPersister.mutualExclusion.Lock()
+	defer playerPersister.mutualExclusion.Unlock()
+
+	_, playerExists := playerPersister.playerStates[playerIdentifier]
 
 	if playerExists {
-		return fmt.Errorf("Player %v already exists", playerName)
+		return fmt.Errorf("Player with identifier %v already exists", playerIdentifier)
 	}
 
-	playerPersister.mutualExclusion.Lock()
+	if playerPersister.identifierForNameKey(nameKey) != "" {
+		return player.ErrNameTaken.Errorf("Player %v already exists", normalizedName)
+	}
 
-	playerPersister.playerStates[playerName] =
+	playerPersister.playerStates[playerIdentifier] =
 		&player.ReadAndWriteState{
-			PlayerName: playerName,
-			ChatColor:  chatColor,
+			PlayerIdentifier: playerIdentifier,
+			PlayerName:       normalizedName,
+			PlayerNameKey:    nameKey,
+			ChatColor:        chatColor,
+			PlayerRole:       playerRole,
+			PasswordHash:     passwordHash,
 		}
 
-	playerPersister.mutualExclusion.Unlock()
+	return nil
+}
+
+// UpdateName updates the given player to have the given name, unless the
+// name does not follow the naming rules or another player already has the
+// same name according to the naming rules. It uses a mutex to ensure thread
+// safety. The context is ignored.
+func (playerPersister *inMemoryPersister) UpdateName(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) error {
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return errorFromName
+	}
+
+	nameKey := playerPersister.nameRules.UniquenessKey(normalizedName)
+
+	playerPersister.mutualExclusion.Lock()
+	defer playerPersister.mutualExclusion.Unlock()
+
+	playerToUpdate, playerExists :=
+		playerPersister.playerStates[playerIdentifier]
+
+	if !playerExists {
+		return player.ErrUnknownPlayer.Errorf(
+			"No player with identifier %v is registered",
+			playerIdentifier)
+	}
+
+	if playerToUpdate.PlayerName == normalizedName {
+		return nil
+	}
+
+	// A player may change only the case of their own name even if the naming rules
+	// are not case-sensitive.
+	identifierWithName := playerPersister.identifierForNameKey(nameKey)
+	if (identifierWithName != "") && (identifierWithName != playerIdentifier) {
+		return player.ErrNameTaken.Errorf("Player %v already exists", normalizedName)
+	}
+
+	playerToUpdate.PlayerName = normalizedName
+	playerToUpdate.PlayerNameKey = nameKey
 
 	return nil
 }
@@ -56,15 +133,15 @@ func (playerPersister *inMemoryPersister) Add(
 // uses a mutex to ensure thread safety. The context is ignored.
 func (playerPersister *inMemoryPersister) UpdateColor(
 	executionContext context.Context,
-	playerName string,
+	playerIdentifier string,
 	chatColor string) error {
 	playerToUpdate, playerExists :=
-		playerPersister.playerStates[playerName]
+		playerPersister.playerStates[playerIdentifier]
 
 	if !playerExists {
-		return fmt.Errorf(
-			"No player with name %v is registered",
-			playerName)
+		return player.ErrUnknownPlayer.Errorf(
+			"No player with identifier %v is registered",
+			playerIdentifier)
 	}
 
 	playerPersister.mutualExclusion.Lock()
@@ -74,25 +151,140 @@ func (playerPersister *inMemoryPersister) UpdateColor(
 	return nil
 }
 
+// UpdateRole updates the given player to have the given role. It uses a
+// mutex to ensure thread safety. The context is ignored.
+func (playerPersister *inMemoryPersister) UpdateRole(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerRole string) error {
+	playerToUpdate, playerExists :=
+		playerPersister.playerStates[playerIdentifier]
+
+	if !playerExists {
+		return player.ErrUnknownPlayer.Errorf(
+			"No player with identifier %v is registered",
+			playerIdentifier)
+	}
+
+	playerPersister.mutualExclusion.Lock()
+	playerToUpdate.PlayerRole = playerRole
+	if playerRole == player.RoleDeleted {
+		playerToUpdate.PasswordHash = ""
+	}
+	playerPersister.mutualExclusion.Unlock()
+
+	return nil
+}
+
+// UpdateProfile replaces the profile of the given player with a copy of the
+// given profile. It uses a mutex to ensure thread safety. The context is
+// ignored.
+func (playerPersister *inMemoryPersister) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile player.Profile) error {
+	playerPersister.mutualExclusion.Lock()
+	defer playerPersister.mutualExclusion.Unlock()
+
+	playerToUpdate, playerExists :=
+		playerPersister.playerStates[playerIdentifier]
+
+	if !playerExists {
+		return player.ErrUnknownPlayer.Errorf(
+			"No player with identifier %v is registered",
+			playerIdentifier)
+	}
+
+	playerToUpdate.PlayerProfile = playerProfile.DeepCopy()
+
+	return nil
+}
+
+// UpdateContacts replaces the friends and groups of the given player with a copy of
+// the given contacts. It uses a mutex to ensure thread safety. The context is
+// ignored.
+func (playerPersister *inMemoryPersister) UpdateContacts(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerContacts player.Contacts) error {
+	playerPersister.mutualExclusion.Lock()
+	defer playerPersister.mutualExclusion.Unlock()
+
+	playerToUpdate, playerExists :=
+		playerPersister.playerStates[playerIdentifier]
+
+	if !playerExists {
+		return player.ErrUnknownPlayer.Errorf(
+			"No player with identifier %v is registered",
+			playerIdentifier)
+	}
+
+	playerToUpdate.PlayerContacts = playerContacts.DeepCopy()
+
+	return nil
+}
+
 // Get returns the ReadOnly corresponding to the given player identifier if
 // it exists already along with an error which is nil if there was no problem.
 // If the player does not exist, a non-nil error is returned along with a nil
 // ReadonlyState. The context is ignored.
 func (playerPersister *inMemoryPersister) Get(
 	executionContext context.Context,
-	playerName string) (player.ReadonlyState, error) {
-	playerState, playerExists := playerPersister.playerStates[playerName]
+	playerIdentifier string) (player.ReadonlyState, error) {
+	playerState, playerExists := playerPersister.playerStates[playerIdentifier]
 	if !playerExists {
 		errorToReturn :=
-			fmt.Errorf(
-				"No player with name %v is registered",
-				playerName)
+			player.ErrUnknownPlayer.Errorf(
+				"No player with identifier %v is registered",
+				playerIdentifier)
 		return nil, errorToReturn
 	}
 
 	return playerState, nil
 }
 
+// IdentifierForName returns the identifier of the player with the same name
+// as the given name according to the naming rules, or an error if there is
+// no such player. The context is ignored.
+func (playerPersister *inMemoryPersister) IdentifierForName(
+	executionContext context.Context,
+	playerName string) (string, error) {
+	errorForUnknownName := player.ErrUnknownPlayer.Errorf("No player with name %v is registered", playerName)
+
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return "", errorForUnknownName
+	}
+
+	playerPersister.mutualExclusion.Lock()
+	defer playerPersister.mutualExclusion.Unlock()
+
+	playerIdentifier :=
+		playerPersister.identifierForNameKey(
+			playerPersister.nameRules.UniquenessKey(normalizedName))
+	if playerIdentifier == "" {
+		return "", errorForUnknownName
+	}
+
+	return playerIdentifier, nil
+}
+
+// PasswordHash returns the password hash of the given player, or an error if
+// the player does not exist. The context is ignored.
+func (playerPersister *inMemoryPersister) PasswordHash(
+	executionContext context.Context,
+	playerIdentifier string) (string, error) {
+	playerState, playerExists := playerPersister.playerStates[playerIdentifier]
+	if !playerExists {
+		return "", player.ErrUnknownPlayer.Errorf(
+			"No player with identifier %v is registered",
+			playerIdentifier)
+	}
+
+	return playerState.PasswordHash, nil
+}
+
 // All returns a slice of all the players in the collection as ReadonlyState
 // instances, ordered in the random way the iteration over the entries of a
 // Golang map normally is. The context is ignored.
@@ -107,13 +299,86 @@ func (playerPersister *inMemoryPersister) All(
 	return playerList, nil
 }
 
+// Search returns a slice of at most the given maximum number of players whose names
+// start with the given prefix, sorted by name, starting after the given name. It has to
+// look at every player, as nothing is indexed, but this is no problem for the number of
+// players which can fit in memory. The context is ignored.
+func (playerPersister *inMemoryPersister) Search(
+	executionContext context.Context,
+	searchCriteria player.SearchCriteria) ([]player.ReadonlyState, error) {
+	playerPersister.mutualExclusion.Lock()
+	defer playerPersister.mutualExclusion.Unlock()
+
+	isDescending := searchCriteria.Order == player.NameDescending
+	matchingPlayers := make([]*player.ReadAndWriteState, 0)
+
+	for _, playerState := range playerPersister.playerStates {
+		playerName := playerState.PlayerName
+		if !strings.HasPrefix(playerName, searchCriteria.NamePrefix) {
+			continue
+		}
+
+		if searchCriteria.AfterName != "" {
+			isAfter := playerName > searchCriteria.AfterName
+			if isDescending {
+				isAfter = playerName < searchCriteria.AfterName
+			}
+
+			if !isAfter {
+				continue
+			}
+		}
+
+		matchingPlayers = append(matchingPlayers, playerState)
+	}
+
+	sort.Slice(matchingPlayers, func(firstIndex int, secondIndex int) bool {
+		isFirstLower :=
+			matchingPlayers[firstIndex].PlayerName < matchingPlayers[secondIndex].PlayerName
+		if isDescending {
+			return !isFirstLower
+		}
+
+		return isFirstLower
+	})
+
+	numberToReturn := len(matchingPlayers)
+	if numberToReturn > searchCriteria.MaximumNumber {
+		numberToReturn = searchCriteria.MaximumNumber
+	}
+
+	if numberToReturn < 0 {
+		numberToReturn = 0
+	}
+
+	playerList := make([]player.ReadonlyState, 0, numberToReturn)
+	for _, matchingPlayer := range matchingPlayers[:numberToReturn] {
+		playerList = append(playerList, matchingPlayer)
+	}
+
+	return playerList, nil
+}
+
 // Delete deletes the given player from the collection. It returns no error.
 // The context is ignored.
 func (playerPersister *inMemoryPersister) Delete(
 	executionContext context.Context,
-	playerName string) error {
+	playerIdentifier string) error {
 	playerPersister.mutualExclusion.Lock()
-	delete(playerPersister.playerStates, playerName)
+	delete(playerPersister.playerStates, playerIdentifier)
 	playerPersister.mutualExclusion.Unlock()
 	return nil
 }
+
+// identifierForNameKey returns the identifier of the player whose name has the given
+// key according to the naming rules, or an empty string if there is no such player. It
+// does not lock the mutex, so the caller should.
+func (playerPersister *inMemoryPersister) identifierForNameKey(nameKey string) string {
+	for playerIdentifier, playerState := range playerPersister.playerStates {
+		if playerState.PlayerNameKey == nameKey {
+			return playerIdentifier
+		}
+	}
+
+	return ""
+}
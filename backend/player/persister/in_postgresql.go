@@ -3,8 +3,12 @@ package persister
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/benoleary/ilutulestikud/backend/naming"
 	"github.com/benoleary/ilutulestikud/backend/player"
 
 	// We need the side-effects of importing this library, but do not use it directly.
@@ -70,33 +74,67 @@ func (wrappingExecutor *wrappingLimitedExecutor) ExecuteStatement(
 type inPostgresqlPersister struct {
 	connectionArguments  string
 	connectionToDatabase LimitedExecutor
+	nameRules            naming.Rules
 }
 
 // NewInPostgresql creates a player state persister which connects to a
-// PostgreSQL database by the given connection string.
+// PostgreSQL database by the given connection string, and which applies
+// the default naming rules.
 func NewInPostgresql(connectionArguments string) player.StatePersister {
-	return NewInPostgresqlWithGivenLimitedExecutor(
+	return NewInPostgresqlWithNameRules(
 		connectionArguments,
-		nil)
+		naming.DefaultRules())
+}
+
+// NewInPostgresqlWithNameRules creates a player state persister which
+// connects to a PostgreSQL database by the given connection string, and
+// which applies the given naming rules.
+func NewInPostgresqlWithNameRules(
+	connectionArguments string,
+	nameRules naming.Rules) player.StatePersister {
+	return &inPostgresqlPersister{
+		connectionArguments:  connectionArguments,
+		connectionToDatabase: nil,
+		nameRules:            nameRules,
+	}
 }
 
 // NewInPostgresqlWithGivenLimitedExecutor creates a player state persister
 // which connects to a PostgreSQL database by the given connection string,
-// initialized with the given LimitedExecutor.
+// initialized with the given LimitedExecutor, and which applies the default
+// naming rules.
 func NewInPostgresqlWithGivenLimitedExecutor(
 	connectionArguments string,
 	connectionToDatabase LimitedExecutor) player.StatePersister {
 	return &inPostgresqlPersister{
 		connectionArguments:  connectionArguments,
 		connectionToDatabase: connectionToDatabase,
+		nameRules:            naming.DefaultRules(),
 	}
 }
 
-// Add inserts the given name and color as a row in the database.
+// Add inserts the given identifier, name, color, role, and password hash as a row in
+// the database, with the name normalized according to the naming rules. It checks that
+// no other player has the same name according to the naming rules so that it can give
+// a clear error, but it is the database which ensures that the identifier, the name,
+// and the key of the name are unique even if two players are added at the same time.
 func (playerPersister *inPostgresqlPersister) Add(
 	executionContext context.Context,
+	playerIdentifier string,
 	playerName string,
-	chatColor string) error {
+	chatColor string,
+	playerRole string,
+	passwordHash string) error {
+	normalizedName, nameKey, errorFromName :=
+		playerPersister.normalizedNameUnlessTaken(
+			executionContext,
+			playerIdentifier,
+			playerName)
+
+	if errorFromName != nil {
+		return errorFromName
+	}
+
 	initializedExecutor, errorFromAcquiral :=
 		playerPersister.acquireExecutor(executionContext)
 
@@ -104,23 +142,41 @@ func (playerPersister *inPostgresqlPersister) Add(
 		return errorFromAcquiral
 	}
 
-	playerCreationStatement := "INSERT INTO player (name, color) VALUES ($1, $2)"
+	playerCreationStatement :=
+		"INSERT INTO player (identifier, name, name_key, color, role, password_hash)" +
+			" VALUES ($1, $2, $3, $4, $5, $6)"
 	_, errorFromExecution :=
 		initializedExecutor.ExecuteStatement(
 			executionContext,
 			playerCreationStatement,
-			playerName,
-			chatColor)
+			playerIdentifier,
+			normalizedName,
+			nameKey,
+			chatColor,
+			playerRole,
+			passwordHash)
 
 	return errorFromExecution
 }
 
-// UpdateColor updates the given player to have the given chat color. It
-// relies on the PostgreSQL driver to ensure thread safety.
-func (playerPersister *inPostgresqlPersister) UpdateColor(
+// UpdateName updates the given player to have the given name, normalized according
+// to the naming rules. It relies on the PostgreSQL driver to ensure thread safety, and
+// on the database to ensure that no other player already has the name or its key,
+// though it checks first so that it can give a clear error.
+func (playerPersister *inPostgresqlPersister) UpdateName(
 	executionContext context.Context,
-	playerName string,
-	chatColor string) error {
+	playerIdentifier string,
+	playerName string) error {
+	normalizedName, nameKey, errorFromName :=
+		playerPersister.normalizedNameUnlessTaken(
+			executionContext,
+			playerIdentifier,
+			playerName)
+
+	if errorFromName != nil {
+		return errorFromName
+	}
+
 	initializedExecutor, errorFromAcquiral :=
 		playerPersister.acquireExecutor(executionContext)
 
@@ -128,24 +184,95 @@ func (playerPersister *inPostgresqlPersister) UpdateColor(
 		return errorFromAcquiral
 	}
 
-	playerUpdateStatement := "UPDATE player SET color = $1 WHERE name = $2"
 	resultFromExecution, errorFromExecution :=
 		initializedExecutor.ExecuteStatement(
 			executionContext,
-			playerUpdateStatement,
-			chatColor,
-			playerName)
+			"UPDATE player SET name = $1, name_key = $2 WHERE identifier = $3",
+			normalizedName,
+			nameKey,
+			playerIdentifier)
 
 	return errorUnlessExactlyOneRowAffected(
-		playerName,
+		playerIdentifier,
 		resultFromExecution,
 		errorFromExecution)
 }
 
-// Get returns the ReadOnly corresponding to the given player name if it exists.
+// UpdateColor updates the given player to have the given chat color. It
+// relies on the PostgreSQL driver to ensure thread safety.
+func (playerPersister *inPostgresqlPersister) UpdateColor(
+	executionContext context.Context,
+	playerIdentifier string,
+	chatColor string) error {
+	return playerPersister.updateColumn(
+		executionContext,
+		"UPDATE player SET color = $1 WHERE identifier = $2",
+		playerIdentifier,
+		chatColor)
+}
+
+// UpdateRole updates the given player to have the given role, clearing the
+// password hash in the same statement if the player becomes a tombstone. It
+// relies on the PostgreSQL driver to ensure thread safety.
+func (playerPersister *inPostgresqlPersister) UpdateRole(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerRole string) error {
+	roleUpdateStatement := "UPDATE player SET role = $1 WHERE identifier = $2"
+	if playerRole == player.RoleDeleted {
+		roleUpdateStatement =
+			"UPDATE player SET role = $1, password_hash = '' WHERE identifier = $2"
+	}
+
+	return playerPersister.updateColumn(
+		executionContext,
+		roleUpdateStatement,
+		playerIdentifier,
+		playerRole)
+}
+
+// UpdateProfile replaces the profile of the given player with the given profile,
+// which is stored as JSON so that new preferences do not need new columns. It relies
+// on the PostgreSQL driver to ensure thread safety.
+func (playerPersister *inPostgresqlPersister) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile player.Profile) error {
+	profileAsJson, errorFromMarshal := json.Marshal(playerProfile)
+	if errorFromMarshal != nil {
+		return errorFromMarshal
+	}
+
+	return playerPersister.updateColumn(
+		executionContext,
+		"UPDATE player SET profile = $1 WHERE identifier = $2",
+		playerIdentifier,
+		string(profileAsJson))
+}
+
+// UpdateContacts replaces the friends and groups of the given player with the given
+// contacts, which are stored as JSON in the same way as the profile. It relies on the
+// PostgreSQL driver to ensure thread safety.
+func (playerPersister *inPostgresqlPersister) UpdateContacts(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerContacts player.Contacts) error {
+	contactsAsJson, errorFromMarshal := json.Marshal(playerContacts)
+	if errorFromMarshal != nil {
+		return errorFromMarshal
+	}
+
+	return playerPersister.updateColumn(
+		executionContext,
+		"UPDATE player SET contacts = $1 WHERE identifier = $2",
+		playerIdentifier,
+		string(contactsAsJson))
+}
+
+// Get returns the ReadOnly corresponding to the given player identifier if it exists.
 func (playerPersister *inPostgresqlPersister) Get(
 	executionContext context.Context,
-	playerName string) (player.ReadonlyState, error) {
+	playerIdentifier string) (player.ReadonlyState, error) {
 	initializedExecutor, errorFromAcquiral :=
 		playerPersister.acquireExecutor(executionContext)
 
@@ -154,12 +281,12 @@ func (playerPersister *inPostgresqlPersister) Get(
 	}
 
 	playerSelectStatement :=
-		"SELECT color FROM player WHERE name = $1"
+		"SELECT name, color, role, profile, contacts FROM player WHERE identifier = $1"
 	playerRows, errorFromExecution :=
 		initializedExecutor.ExecuteQuery(
 			executionContext,
 			playerSelectStatement,
-			playerName)
+			playerIdentifier)
 	if errorFromExecution != nil {
 		return nil, errorFromExecution
 	}
@@ -169,35 +296,89 @@ func (playerPersister *inPostgresqlPersister) Get(
 	hasAtLeastOnePlayer := playerRows.Next()
 	if !hasAtLeastOnePlayer {
 		errorToReturn :=
-			fmt.Errorf(
-				"No player with name %v is registered",
-				playerName)
+			player.ErrUnknownPlayer.Errorf(
+				"No player with identifier %v is registered",
+				playerIdentifier)
 		return nil, errorToReturn
 	}
 
 	playerState :=
 		player.ReadAndWriteState{
-			PlayerName: playerName,
-			ChatColor:  "error: not read in from DB correctly",
+			PlayerIdentifier: playerIdentifier,
+			PlayerName:       "error: not read in from DB correctly",
+			ChatColor:        "error: not read in from DB correctly",
 		}
 
-	errorFromScan := playerRows.Scan(&playerState.ChatColor)
+	// Players created before roles were introduced have NULL as their role, which
+	// cannot be scanned directly into a string, and similarly for profiles and contacts.
+	var playerRole sql.NullString
+	var playerProfile sql.NullString
+	var playerContacts sql.NullString
+	errorFromScan :=
+		playerRows.Scan(
+			&playerState.PlayerName,
+			&playerState.ChatColor,
+			&playerRole,
+			&playerProfile,
+			&playerContacts)
 	if errorFromScan != nil {
 		return nil, errorFromScan
 	}
 
+	playerState.PlayerRole = playerRole.String
+
+	errorFromJson := setFromJsonColumns(&playerState, playerProfile, playerContacts)
+	if errorFromJson != nil {
+		return nil, errorFromJson
+	}
+
 	hasMoreThanOnePlayer := playerRows.Next()
 	if hasMoreThanOnePlayer {
 		errorToReturn :=
 			fmt.Errorf(
-				"Player with name %v is registered more than once",
-				playerName)
+				"Player with identifier %v is registered more than once",
+				playerIdentifier)
 		return nil, errorToReturn
 	}
 
 	return &playerState, playerRows.Err()
 }
 
+// IdentifierForName returns the identifier of the player with the same name as the
+// given name according to the naming rules, or an error if there is no such player.
+// Players created before keys of names were introduced have no key, so their names are
+// compared exactly with the normalized name.
+func (playerPersister *inPostgresqlPersister) IdentifierForName(
+	executionContext context.Context,
+	playerName string) (string, error) {
+	messageIfNotFound := fmt.Sprintf("No player with name %v is registered", playerName)
+
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return "", errors.New(messageIfNotFound)
+	}
+
+	return playerPersister.selectSingleString(
+		executionContext,
+		"SELECT identifier FROM player"+
+			" WHERE name_key = $1 OR (name_key IS NULL AND name = $2)",
+		messageIfNotFound,
+		playerPersister.nameRules.UniquenessKey(normalizedName),
+		normalizedName)
+}
+
+// PasswordHash returns the password hash of the given player if the player exists.
+func (playerPersister *inPostgresqlPersister) PasswordHash(
+	executionContext context.Context,
+	playerIdentifier string) (string, error) {
+	return playerPersister.selectSingleString(
+		executionContext,
+		"SELECT password_hash FROM player WHERE identifier = $1",
+		fmt.Sprintf("No player with identifier %v is registered", playerIdentifier),
+		playerIdentifier)
+}
+
 // All returns a slice of all the players in the collection as ReadonlyState
 // instances, ordered as given by the database.
 func (playerPersister *inPostgresqlPersister) All(
@@ -209,7 +390,8 @@ func (playerPersister *inPostgresqlPersister) All(
 		return nil, errorFromAcquiral
 	}
 
-	playerSelectStatement := "SELECT name, color FROM player"
+	playerSelectStatement :=
+		"SELECT identifier, name, color, role, profile, contacts FROM player"
 	playerRows, errorFromExecution :=
 		initializedExecutor.ExecuteQuery(
 			executionContext,
@@ -220,15 +402,100 @@ func (playerPersister *inPostgresqlPersister) All(
 
 	defer playerRows.Close()
 
+	return readAndWriteStatesFromRows(playerRows)
+}
+
+// Search returns a slice of at most the given maximum number of players whose names
+// start with the given prefix, sorted by name, starting after the given name. Names
+// are compared with the "C" collation, which compares the bytes of the UTF-8 encoding
+// as the other persisters do, and which lets the database use the index on the names
+// for both the prefix and the ordering, so that it does not read every player.
+func (playerPersister *inPostgresqlPersister) Search(
+	executionContext context.Context,
+	searchCriteria player.SearchCriteria) ([]player.ReadonlyState, error) {
+	initializedExecutor, errorFromAcquiral :=
+		playerPersister.acquireExecutor(executionContext)
+
+	if errorFromAcquiral != nil {
+		return nil, errorFromAcquiral
+	}
+
+	// The characters which are special to LIKE have to be escaped in the prefix.
+	prefixEscaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	argumentsForStatement := []interface{}{
+		prefixEscaper.Replace(searchCriteria.NamePrefix) + "%",
+	}
+
+	playerSelectStatement :=
+		"SELECT identifier, name, color, role, profile, contacts FROM player" +
+			` WHERE name COLLATE "C" LIKE $1`
+
+	comparisonForAfter := ">"
+	directionForOrder := "ASC"
+	if searchCriteria.Order == player.NameDescending {
+		comparisonForAfter = "<"
+		directionForOrder = "DESC"
+	}
+
+	if searchCriteria.AfterName != "" {
+		argumentsForStatement = append(argumentsForStatement, searchCriteria.AfterName)
+		playerSelectStatement +=
+			fmt.Sprintf(
+				` AND name COLLATE "C" %v $%v`,
+				comparisonForAfter,
+				len(argumentsForStatement))
+	}
+
+	argumentsForStatement = append(argumentsForStatement, searchCriteria.MaximumNumber)
+	playerSelectStatement +=
+		fmt.Sprintf(
+			` ORDER BY name COLLATE "C" %v LIMIT $%v`,
+			directionForOrder,
+			len(argumentsForStatement))
+
+	playerRows, errorFromExecution :=
+		initializedExecutor.ExecuteQuery(
+			executionContext,
+			playerSelectStatement,
+			argumentsForStatement...)
+	if errorFromExecution != nil {
+		return nil, errorFromExecution
+	}
+
+	defer playerRows.Close()
+
+	return readAndWriteStatesFromRows(playerRows)
+}
+
+// readAndWriteStatesFromRows reads the players from the given rows, which should have
+// the columns identifier, name, color, role, profile, and contacts, in that order.
+func readAndWriteStatesFromRows(playerRows RowsAsResult) ([]player.ReadonlyState, error) {
 	allStates := []player.ReadonlyState{}
 
 	for playerRows.Next() {
 		playerState := player.ReadAndWriteState{}
-		errorFromScan := playerRows.Scan(&playerState.PlayerName, &playerState.ChatColor)
+		var playerRole sql.NullString
+		var playerProfile sql.NullString
+		var playerContacts sql.NullString
+		errorFromScan :=
+			playerRows.Scan(
+				&playerState.PlayerIdentifier,
+				&playerState.PlayerName,
+				&playerState.ChatColor,
+				&playerRole,
+				&playerProfile,
+				&playerContacts)
 		if errorFromScan != nil {
 			return nil, errorFromScan
 		}
 
+		playerState.PlayerRole = playerRole.String
+
+		errorFromJson := setFromJsonColumns(&playerState, playerProfile, playerContacts)
+		if errorFromJson != nil {
+			return nil, errorFromJson
+		}
+
 		allStates = append(allStates, &playerState)
 	}
 
@@ -239,7 +506,7 @@ func (playerPersister *inPostgresqlPersister) All(
 // if the player does not exist before the deletion attempt.
 func (playerPersister *inPostgresqlPersister) Delete(
 	executionContext context.Context,
-	playerName string) error {
+	playerIdentifier string) error {
 	initializedExecutor, errorFromAcquiral :=
 		playerPersister.acquireExecutor(executionContext)
 
@@ -247,19 +514,157 @@ func (playerPersister *inPostgresqlPersister) Delete(
 		return errorFromAcquiral
 	}
 
-	playerDeletionStatement := "DELETE FROM player WHERE name = $1"
+	playerDeletionStatement := "DELETE FROM player WHERE identifier = $1"
 	resultFromExecution, errorFromExecution :=
 		initializedExecutor.ExecuteStatement(
 			executionContext,
 			playerDeletionStatement,
-			playerName)
+			playerIdentifier)
+
+	return errorUnlessExactlyOneRowAffected(
+		playerIdentifier,
+		resultFromExecution,
+		errorFromExecution)
+}
+
+// updateColumn executes the given statement, which should set a single column to the
+// value given as its first argument for the player with the identifier given as its
+// second argument, and returns an error unless exactly one row was updated.
+func (playerPersister *inPostgresqlPersister) updateColumn(
+	executionContext context.Context,
+	playerUpdateStatement string,
+	playerIdentifier string,
+	updatedValue string) error {
+	initializedExecutor, errorFromAcquiral :=
+		playerPersister.acquireExecutor(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	resultFromExecution, errorFromExecution :=
+		initializedExecutor.ExecuteStatement(
+			executionContext,
+			playerUpdateStatement,
+			updatedValue,
+			playerIdentifier)
 
 	return errorUnlessExactlyOneRowAffected(
-		playerName,
+		playerIdentifier,
 		resultFromExecution,
 		errorFromExecution)
 }
 
+// setFromJsonColumns sets the profile and the contacts of the given player from the
+// given JSON, leaving the zero values if the JSON is NULL, as it is for players created
+// before profiles or contacts were introduced.
+func setFromJsonColumns(
+	playerState *player.ReadAndWriteState,
+	profileAsJson sql.NullString,
+	contactsAsJson sql.NullString) error {
+	errorFromProfile := unmarshalUnlessNull(profileAsJson, &playerState.PlayerProfile)
+	if errorFromProfile != nil {
+		return errorFromProfile
+	}
+
+	return unmarshalUnlessNull(contactsAsJson, &playerState.PlayerContacts)
+}
+
+// unmarshalUnlessNull unmarshals the given JSON into the given value unless the JSON
+// is NULL or empty, in which case it leaves the value unchanged.
+func unmarshalUnlessNull(valueAsJson sql.NullString, unmarshalledValue interface{}) error {
+	if !valueAsJson.Valid || (valueAsJson.String == "") {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(valueAsJson.String), unmarshalledValue)
+}
+
+// selectSingleString executes the given query, which should select a single column
+// of a single row matching the given arguments, and returns the value, or an error
+// with the given message if there is no matching row. A NULL value is returned as an
+// empty string, as players created before passwords were introduced have NULL as
+// their hash.
+func (playerPersister *inPostgresqlPersister) selectSingleString(
+	executionContext context.Context,
+	selectStatement string,
+	messageIfNotFound string,
+	argumentsForStatement ...interface{}) (string, error) {
+	initializedExecutor, errorFromAcquiral :=
+		playerPersister.acquireExecutor(executionContext)
+
+	if errorFromAcquiral != nil {
+		return "", errorFromAcquiral
+	}
+
+	selectedRows, errorFromExecution :=
+		initializedExecutor.ExecuteQuery(
+			executionContext,
+			selectStatement,
+			argumentsForStatement...)
+	if errorFromExecution != nil {
+		return "", errorFromExecution
+	}
+
+	defer selectedRows.Close()
+
+	if !selectedRows.Next() {
+		return "", errors.New(messageIfNotFound)
+	}
+
+	var selectedValue sql.NullString
+	errorFromScan := selectedRows.Scan(&selectedValue)
+	if errorFromScan != nil {
+		return "", errorFromScan
+	}
+
+	return selectedValue.String, selectedRows.Err()
+}
+
+// normalizedNameUnlessTaken returns the given name normalized according to the naming
+// rules along with its key, or an error if the name does not follow the rules, or if a
+// player other than the given player already has the same name according to the rules,
+// or if there was a problem checking.
+func (playerPersister *inPostgresqlPersister) normalizedNameUnlessTaken(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) (string, string, error) {
+	normalizedName, errorFromName :=
+		playerPersister.nameRules.Normalize("Player name", playerName)
+	if errorFromName != nil {
+		return "", "", errorFromName
+	}
+
+	nameKey := playerPersister.nameRules.UniquenessKey(normalizedName)
+
+	initializedExecutor, errorFromAcquiral :=
+		playerPersister.acquireExecutor(executionContext)
+
+	if errorFromAcquiral != nil {
+		return "", "", errorFromAcquiral
+	}
+
+	matchingRows, errorFromExecution :=
+		initializedExecutor.ExecuteQuery(
+			executionContext,
+			"SELECT identifier FROM player"+
+				" WHERE (name_key = $1 OR name = $2) AND identifier <> $3",
+			nameKey,
+			normalizedName,
+			playerIdentifier)
+	if errorFromExecution != nil {
+		return "", "", errorFromExecution
+	}
+
+	defer matchingRows.Close()
+
+	if matchingRows.Next() {
+		return "", "", player.ErrNameTaken.Errorf("Player %v already exists", normalizedName)
+	}
+
+	return normalizedName, nameKey, matchingRows.Err()
+}
+
 // acquireExecutor returns the connection to the PostgreSQL database,
 // initializing it if it has not already been initialized.
 func (playerPersister *inPostgresqlPersister) acquireExecutor(
@@ -299,23 +704,67 @@ func (playerPersister *inPostgresqlPersister) initializeExecutor(
 	// dialect of SQL.
 	tableCreationStatement :=
 		`CREATE TABLE IF NOT EXISTS player (
-			name VARCHAR(255) PRIMARY KEY NOT NULL UNIQUE,
-			color VARCHAR(255)
+			identifier VARCHAR(255) PRIMARY KEY NOT NULL UNIQUE,
+			name VARCHAR(255) NOT NULL UNIQUE,
+			name_key VARCHAR(255) UNIQUE,
+			color VARCHAR(255),
+			role VARCHAR(255),
+			password_hash VARCHAR(255),
+			profile TEXT,
+			contacts TEXT
 		)`
 
+	// Tables which were created before passwords, roles, identifiers, profiles,
+	// contacts, and keys of names were introduced need the columns for them to be
+	// added. The keys of existing names are left NULL rather than being computed in
+	// SQL, as existing names which differ only by case would then break the uniqueness
+	// of the keys. Such players get a key when they are next renamed.
+	columnAdditionStatement :=
+		`ALTER TABLE player
+			ADD COLUMN IF NOT EXISTS role VARCHAR(255),
+			ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255),
+			ADD COLUMN IF NOT EXISTS identifier VARCHAR(255) UNIQUE,
+			ADD COLUMN IF NOT EXISTS profile TEXT,
+			ADD COLUMN IF NOT EXISTS contacts TEXT,
+			ADD COLUMN IF NOT EXISTS name_key VARCHAR(255) UNIQUE`
+
+	// Games refer to players who were created before identifiers were introduced by
+	// their names, so those players take their names as their identifiers.
+	identifierMigrationStatement :=
+		"UPDATE player SET identifier = name WHERE identifier IS NULL"
+
+	// Searches compare names with the "C" collation, so they need an index with the
+	// same collation, which also serves searches by prefix.
+	nameIndexCreationStatement :=
+		`CREATE INDEX IF NOT EXISTS player_name_c_collation
+			ON player (name COLLATE "C")`
+
 	playerPersister.connectionToDatabase =
 		&wrappingLimitedExecutor{wrappedInterface: postgresqlDatabase}
 
-	_, errorFromExecution :=
-		playerPersister.connectionToDatabase.ExecuteStatement(
-			executionContext,
-			tableCreationStatement)
+	setupStatements := []string{
+		tableCreationStatement,
+		columnAdditionStatement,
+		identifierMigrationStatement,
+		nameIndexCreationStatement,
+	}
 
-	return errorFromExecution
+	for _, setupStatement := range setupStatements {
+		_, errorFromExecution :=
+			playerPersister.connectionToDatabase.ExecuteStatement(
+				executionContext,
+				setupStatement)
+
+		if errorFromExecution != nil {
+			return errorFromExecution
+		}
+	}
+
+	return nil
 }
 
 func errorUnlessExactlyOneRowAffected(
-	playerName string,
+	playerIdentifier string,
 	resultFromExecution MetadataAsResult,
 	errorFromExecution error) error {
 	if errorFromExecution != nil {
@@ -330,7 +779,7 @@ func errorUnlessExactlyOneRowAffected(
 	if numberOfRowsAffected != 1 {
 		return fmt.Errorf(
 			"Expected to affect 1 row (for player %v), instead affected %v rows",
-			playerName,
+			playerIdentifier,
 			numberOfRowsAffected)
 	}
 
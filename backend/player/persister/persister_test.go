@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/benoleary/ilutulestikud/backend/cloud"
@@ -14,6 +16,7 @@ import (
 
 const testPrefix = "Tough_no_player_name_can_start_like_this_"
 const invalidName = testPrefix + "Not A. Participant"
+const testPasswordHash = "pbkdf2-sha256$1$c2FsdA$aGFzaA"
 
 var colorsAvailableInTest []string = defaults.AvailableColors()
 var defaultTestPlayerNames []string = []string{
@@ -22,6 +25,12 @@ var defaultTestPlayerNames []string = []string{
 	testPrefix + "Player Three",
 }
 
+// identifierForName gives a distinct identifier for each test player, so that the
+// tests check that the persisters do not confuse identifiers with names.
+func identifierForName(playerName string) string {
+	return playerName + " identifier"
+}
+
 func mapStringsToTrue(stringsToMap []string) map[string]bool {
 	stringMap := make(map[string]bool, 0)
 	for _, stringToMap := range stringsToMap {
@@ -103,6 +112,28 @@ func TestReturnErrorWhenPlayerNotFoundWithGet(unitTest *testing.T) {
 	}
 }
 
+func TestReturnErrorWhenPlayerNotFoundWithPasswordHash(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+
+	for _, statePersister := range statePersisters {
+		testIdentifier := "PasswordHash(unknown player)/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			passwordHash, errorFromPasswordHash :=
+				statePersister.PlayerPersister.PasswordHash(
+					context.Background(),
+					invalidName)
+
+			if errorFromPasswordHash == nil {
+				unitTest.Fatalf(
+					"PasswordHash(unknown player name %v) did not return an error, did return %v",
+					invalidName,
+					passwordHash)
+			}
+		})
+	}
+}
+
 func TestRejectAddPlayerWithExistingName(unitTest *testing.T) {
 	statePersisters := preparePersisters(unitTest)
 
@@ -113,7 +144,9 @@ func TestRejectAddPlayerWithExistingName(unitTest *testing.T) {
 
 			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
 				errorFromDeletionOfExisting :=
-					statePersister.PlayerPersister.Delete(context.Background(), playerName)
+					statePersister.PlayerPersister.Delete(
+						context.Background(),
+						identifierForName(playerName))
 				unitTest.Logf(
 					"Error from persister %v deleting %v when setting up"+
 						" (to ensure that it does not exist before the test) was %v",
@@ -124,8 +157,11 @@ func TestRejectAddPlayerWithExistingName(unitTest *testing.T) {
 				errorFromInitialAdd :=
 					statePersister.PlayerPersister.Add(
 						context.Background(),
+						identifierForName(playerName),
 						playerName,
-						colorsAvailableInTest[0])
+						colorsAvailableInTest[0],
+						player.RolePlayer,
+						testPasswordHash)
 
 				if errorFromInitialAdd != nil {
 					unitTest.Fatalf(
@@ -152,8 +188,11 @@ func TestRejectAddPlayerWithExistingName(unitTest *testing.T) {
 				errorFromSecondAdd :=
 					statePersister.PlayerPersister.Add(
 						context.Background(),
+						identifierForName(playerName),
 						playerName,
-						colorsAvailableInTest[1])
+						colorsAvailableInTest[1],
+						player.RolePlayer,
+						testPasswordHash)
 
 				// We check that the persister still produces valid states.
 				assertPlayerNamesAreCorrectAndGetIsConsistentWithAll(
@@ -170,6 +209,22 @@ func TestRejectAddPlayerWithExistingName(unitTest *testing.T) {
 						colorsAvailableInTest[1])
 				}
 
+				errorFromAddWithOtherIdentifier :=
+					statePersister.PlayerPersister.Add(
+						context.Background(),
+						identifierForName(invalidName),
+						playerName,
+						colorsAvailableInTest[1],
+						player.RolePlayer,
+						testPasswordHash)
+
+				if errorFromAddWithOtherIdentifier == nil {
+					unitTest.Fatalf(
+						"Add(%v, %v, ...) with existing name did not produce an error",
+						identifierForName(invalidName),
+						playerName)
+				}
+
 				// We check that the player is unchanged.
 				existingStateAfterAddWithNewColor :=
 					getStateAndAssertNoError(
@@ -192,7 +247,7 @@ func TestRejectAddPlayerWithExistingName(unitTest *testing.T) {
 				errorFromDelete :=
 					statePersister.PlayerPersister.Delete(
 						context.Background(),
-						playerName)
+						identifierForName(playerName))
 				if errorFromDelete != nil {
 					unitTest.Fatalf(
 						"Delete(%v) produced error %v",
@@ -239,7 +294,9 @@ func TestAddPlayerWithValidColorAndTestGet(unitTest *testing.T) {
 
 			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
 				errorFromDeletionOfExisting :=
-					statePersister.PlayerPersister.Delete(context.Background(), testCase.playerName)
+					statePersister.PlayerPersister.Delete(
+						context.Background(),
+						identifierForName(testCase.playerName))
 				unitTest.Logf(
 					"Error from persister %v deleting %v when setting up"+
 						" (to ensure that it does not exist before the test) was %v",
@@ -250,8 +307,11 @@ func TestAddPlayerWithValidColorAndTestGet(unitTest *testing.T) {
 				errorFromAdd :=
 					statePersister.PlayerPersister.Add(
 						context.Background(),
+						identifierForName(testCase.playerName),
 						testCase.playerName,
-						chatColor)
+						chatColor,
+						player.RolePlayer,
+						testPasswordHash)
 
 				if errorFromAdd != nil {
 					unitTest.Fatalf(
@@ -290,7 +350,7 @@ func TestAddPlayerWithValidColorAndTestGet(unitTest *testing.T) {
 				errorFromDelete :=
 					statePersister.PlayerPersister.Delete(
 						context.Background(),
-						testCase.playerName)
+						identifierForName(testCase.playerName))
 				if errorFromDelete != nil {
 					unitTest.Fatalf(
 						"Delete(%v) produced error %v",
@@ -317,7 +377,9 @@ func TestAddNewPlayersThenDeleteThem(unitTest *testing.T) {
 
 		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
 			errorFromDeletionOfExistingFirst :=
-				statePersister.PlayerPersister.Delete(context.Background(), firstPlayer)
+				statePersister.PlayerPersister.Delete(
+					context.Background(),
+					identifierForName(firstPlayer))
 			unitTest.Logf(
 				"Error from persister %v deleting %v when setting up"+
 					" (to ensure that it does not exist before the test) was %v",
@@ -326,7 +388,9 @@ func TestAddNewPlayersThenDeleteThem(unitTest *testing.T) {
 				errorFromDeletionOfExistingFirst)
 
 			errorFromDeletionOfExistingSecond :=
-				statePersister.PlayerPersister.Delete(context.Background(), secondPlayer)
+				statePersister.PlayerPersister.Delete(
+					context.Background(),
+					identifierForName(secondPlayer))
 			unitTest.Logf(
 				"Error from persister %v deleting %v when setting up"+
 					" (to ensure that it does not exist before the test) was %v",
@@ -347,8 +411,11 @@ func TestAddNewPlayersThenDeleteThem(unitTest *testing.T) {
 			errorFromFirstAdd :=
 				statePersister.PlayerPersister.Add(
 					context.Background(),
+					identifierForName(firstPlayer),
 					firstPlayer,
-					firstColor)
+					firstColor,
+					player.RolePlayer,
+					testPasswordHash)
 			if errorFromFirstAdd != nil {
 				unitTest.Fatalf(
 					"Add(%v, %v) produced an error %v",
@@ -401,8 +468,11 @@ func TestAddNewPlayersThenDeleteThem(unitTest *testing.T) {
 			errorFromSecondAdd :=
 				statePersister.PlayerPersister.Add(
 					context.Background(),
+					identifierForName(secondPlayer),
 					secondPlayer,
-					secondColor)
+					secondColor,
+					player.RolePlayer,
+					testPasswordHash)
 
 			if errorFromSecondAdd != nil {
 				unitTest.Fatalf(
@@ -472,7 +542,7 @@ func TestAddNewPlayersThenDeleteThem(unitTest *testing.T) {
 			errorFromFirstDelete :=
 				statePersister.PlayerPersister.Delete(
 					context.Background(),
-					firstPlayer)
+					identifierForName(firstPlayer))
 
 			if errorFromFirstDelete != nil {
 				unitTest.Fatalf(
@@ -515,7 +585,7 @@ func TestAddNewPlayersThenDeleteThem(unitTest *testing.T) {
 			errorFromSecondDelete :=
 				statePersister.PlayerPersister.Delete(
 					context.Background(),
-					secondPlayer)
+					identifierForName(secondPlayer))
 
 			if errorFromSecondDelete != nil {
 				unitTest.Fatalf(
@@ -606,8 +676,11 @@ func TestUpdateAllPlayersToNewColor(unitTest *testing.T) {
 				errorFromAdd :=
 					statePersister.PlayerPersister.Add(
 						context.Background(),
+						identifierForName(playerName),
 						playerName,
-						initialColor)
+						initialColor,
+						player.RolePlayer,
+						testPasswordHash)
 
 				if errorFromAdd != nil {
 					unitTest.Fatalf(
@@ -622,7 +695,7 @@ func TestUpdateAllPlayersToNewColor(unitTest *testing.T) {
 				errorFromUpdateColor :=
 					statePersister.PlayerPersister.UpdateColor(
 						context.Background(),
-						playerName,
+						identifierForName(playerName),
 						newColor)
 
 				if errorFromUpdateColor != nil {
@@ -657,6 +730,23 @@ func TestUpdateAllPlayersToNewColor(unitTest *testing.T) {
 						playerName,
 						updatedState)
 				}
+
+				// We check that the password hash was not lost in the update.
+				passwordHash, errorFromPasswordHash :=
+					statePersister.PlayerPersister.PasswordHash(
+						context.Background(),
+						identifierForName(playerName))
+
+				if (errorFromPasswordHash != nil) || (passwordHash != testPasswordHash) {
+					unitTest.Fatalf(
+						"UpdateColor(%v, %v) then PasswordHash(%v) produced %v, %v - expected %v",
+						playerName,
+						newColor,
+						playerName,
+						passwordHash,
+						errorFromPasswordHash,
+						testPasswordHash)
+				}
 			}
 
 			// Finally, we have to clean up the added test players.
@@ -664,7 +754,7 @@ func TestUpdateAllPlayersToNewColor(unitTest *testing.T) {
 				errorFromDelete :=
 					statePersister.PlayerPersister.Delete(
 						context.Background(),
-						playerName)
+						identifierForName(playerName))
 
 				if errorFromDelete != nil {
 					unitTest.Fatalf(
@@ -677,6 +767,792 @@ func TestUpdateAllPlayersToNewColor(unitTest *testing.T) {
 	}
 }
 
+func TestUpdatePlayerToAdministrator(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	playerName := defaultTestPlayerNames[0]
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Update player to administrator/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.PlayerPersister.Add(
+					context.Background(),
+					identifierForName(playerName),
+					playerName,
+					colorsAvailableInTest[0],
+					player.RolePlayer,
+					testPasswordHash)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"Add(%v, ...) produced an error %v",
+					playerName,
+					errorFromAdd)
+			}
+
+			initialState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(added player)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if initialState.Role() != player.RolePlayer {
+				unitTest.Fatalf(
+					"Add(%v, ...) then Get(%v) produced state with role %v, expected %v",
+					playerName,
+					playerName,
+					initialState.Role(),
+					player.RolePlayer)
+			}
+
+			errorFromUpdateRole :=
+				statePersister.PlayerPersister.UpdateRole(
+					context.Background(),
+					identifierForName(playerName),
+					player.RoleAdministrator)
+
+			if errorFromUpdateRole != nil {
+				unitTest.Fatalf(
+					"UpdateRole(%v, %v) produced an error: %v",
+					playerName,
+					player.RoleAdministrator,
+					errorFromUpdateRole)
+			}
+
+			updatedState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(updated player)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if (updatedState.Role() != player.RoleAdministrator) ||
+				(updatedState.Color() != colorsAvailableInTest[0]) {
+				unitTest.Fatalf(
+					"UpdateRole(%v, %v) then Get(%v) produced state %v",
+					playerName,
+					player.RoleAdministrator,
+					playerName,
+					updatedState)
+			}
+
+			errorFromDelete :=
+				statePersister.PlayerPersister.Delete(
+					context.Background(),
+					identifierForName(playerName))
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%v) produced error %v",
+					playerName,
+					errorFromDelete)
+			}
+		})
+	}
+}
+
+func TestUpdatePlayerToTombstoneClearsPasswordHash(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	playerName := defaultTestPlayerNames[0]
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Update player to tombstone/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.PlayerPersister.Add(
+					context.Background(),
+					identifierForName(playerName),
+					playerName,
+					colorsAvailableInTest[0],
+					player.RolePlayer,
+					testPasswordHash)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"Add(%v, ...) produced an error %v",
+					playerName,
+					errorFromAdd)
+			}
+
+			errorFromUpdateRole :=
+				statePersister.PlayerPersister.UpdateRole(
+					context.Background(),
+					identifierForName(playerName),
+					player.RoleDeleted)
+
+			if errorFromUpdateRole != nil {
+				unitTest.Fatalf(
+					"UpdateRole(%v, %v) produced an error: %v",
+					playerName,
+					player.RoleDeleted,
+					errorFromUpdateRole)
+			}
+
+			updatedState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(tombstone)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if (updatedState.Role() != player.RoleDeleted) ||
+				(updatedState.Color() != colorsAvailableInTest[0]) {
+				unitTest.Fatalf(
+					"UpdateRole(%v, %v) then Get(%v) produced state %v",
+					playerName,
+					player.RoleDeleted,
+					playerName,
+					updatedState)
+			}
+
+			passwordHash, errorFromHash :=
+				statePersister.PlayerPersister.PasswordHash(
+					context.Background(),
+					identifierForName(playerName))
+
+			if (errorFromHash != nil) || (passwordHash != "") {
+				unitTest.Fatalf(
+					"UpdateRole(%v, %v) then PasswordHash(%v) produced %v, %v - expected empty hash",
+					playerName,
+					player.RoleDeleted,
+					playerName,
+					passwordHash,
+					errorFromHash)
+			}
+
+			errorFromDelete :=
+				statePersister.PlayerPersister.Delete(
+					context.Background(),
+					identifierForName(playerName))
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%v) produced error %v",
+					playerName,
+					errorFromDelete)
+			}
+		})
+	}
+}
+
+func TestUpdatePlayerProfile(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	playerName := defaultTestPlayerNames[0]
+	updatedProfile := player.Profile{
+		PreferredRulesets:    []int{3, 1},
+		PreferredHintDisplay: player.HintDisplaySymbols,
+		Timezone:             "UTC",
+		Notifications: player.NotificationPreferences{
+			OnTurn:        true,
+			OnInvitation:  false,
+			OnChatMessage: true,
+		},
+	}
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Update player profile/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.PlayerPersister.Add(
+					context.Background(),
+					identifierForName(playerName),
+					playerName,
+					colorsAvailableInTest[0],
+					player.RolePlayer,
+					testPasswordHash)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"Add(%v, ...) produced an error %v",
+					playerName,
+					errorFromAdd)
+			}
+
+			initialState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(added player)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if !reflect.DeepEqual(initialState.Profile(), player.Profile{}) {
+				unitTest.Fatalf(
+					"Add(%v, ...) then Get(%v) produced state with profile %+v, expected zero profile",
+					playerName,
+					playerName,
+					initialState.Profile())
+			}
+
+			errorFromUpdateProfile :=
+				statePersister.PlayerPersister.UpdateProfile(
+					context.Background(),
+					identifierForName(playerName),
+					updatedProfile)
+
+			if errorFromUpdateProfile != nil {
+				unitTest.Fatalf(
+					"UpdateProfile(%v, %+v) produced an error: %v",
+					playerName,
+					updatedProfile,
+					errorFromUpdateProfile)
+			}
+
+			updatedState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(updated player)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if !reflect.DeepEqual(updatedState.Profile(), updatedProfile) ||
+				(updatedState.Role() != player.RolePlayer) ||
+				(updatedState.Color() != colorsAvailableInTest[0]) {
+				unitTest.Fatalf(
+					"UpdateProfile(%v, %+v) then Get(%v) produced state %+v",
+					playerName,
+					updatedProfile,
+					playerName,
+					updatedState)
+			}
+
+			errorFromInvalidUpdate :=
+				statePersister.PlayerPersister.UpdateProfile(
+					context.Background(),
+					identifierForName(invalidName),
+					updatedProfile)
+
+			if errorFromInvalidUpdate == nil {
+				unitTest.Fatalf(
+					"UpdateProfile(%v, %+v) produced no error",
+					invalidName,
+					updatedProfile)
+			}
+
+			errorFromDelete :=
+				statePersister.PlayerPersister.Delete(
+					context.Background(),
+					identifierForName(playerName))
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%v) produced error %v",
+					playerName,
+					errorFromDelete)
+			}
+		})
+	}
+}
+
+func TestUpdatePlayerContacts(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	playerName := defaultTestPlayerNames[0]
+	updatedContacts := player.Contacts{
+		FriendIdentifiers: []string{
+			identifierForName(defaultTestPlayerNames[2]),
+			identifierForName(defaultTestPlayerNames[1]),
+		},
+		Groups: []player.Group{
+			player.Group{
+				Name: "Tuesday",
+				MemberIdentifiers: []string{
+					identifierForName(defaultTestPlayerNames[1]),
+					identifierForName(defaultTestPlayerNames[2]),
+				},
+			},
+			player.Group{
+				Name:              "Weekend",
+				MemberIdentifiers: []string{identifierForName(defaultTestPlayerNames[2])},
+			},
+		},
+	}
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Update player contacts/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.PlayerPersister.Add(
+					context.Background(),
+					identifierForName(playerName),
+					playerName,
+					colorsAvailableInTest[0],
+					player.RolePlayer,
+					testPasswordHash)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"Add(%v, ...) produced an error %v",
+					playerName,
+					errorFromAdd)
+			}
+
+			initialState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(added player)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if (len(initialState.Contacts().FriendIdentifiers) != 0) ||
+				(len(initialState.Contacts().Groups) != 0) {
+				unitTest.Fatalf(
+					"Add(%v, ...) then Get(%v) produced state with contacts %+v, expected none",
+					playerName,
+					playerName,
+					initialState.Contacts())
+			}
+
+			errorFromUpdateContacts :=
+				statePersister.PlayerPersister.UpdateContacts(
+					context.Background(),
+					identifierForName(playerName),
+					updatedContacts)
+
+			if errorFromUpdateContacts != nil {
+				unitTest.Fatalf(
+					"UpdateContacts(%v, %+v) produced an error: %v",
+					playerName,
+					updatedContacts,
+					errorFromUpdateContacts)
+			}
+
+			updatedState :=
+				getStateAndAssertNoError(
+					testIdentifier+"/Get(updated player)",
+					unitTest,
+					playerName,
+					statePersister.PlayerPersister)
+
+			if !reflect.DeepEqual(updatedState.Contacts(), updatedContacts) ||
+				(updatedState.Role() != player.RolePlayer) ||
+				(updatedState.Color() != colorsAvailableInTest[0]) {
+				unitTest.Fatalf(
+					"UpdateContacts(%v, %+v) then Get(%v) produced state %+v",
+					playerName,
+					updatedContacts,
+					playerName,
+					updatedState)
+			}
+
+			errorFromInvalidUpdate :=
+				statePersister.PlayerPersister.UpdateContacts(
+					context.Background(),
+					identifierForName(invalidName),
+					updatedContacts)
+
+			if errorFromInvalidUpdate == nil {
+				unitTest.Fatalf(
+					"UpdateContacts(%v, %+v) produced no error",
+					invalidName,
+					updatedContacts)
+			}
+
+			errorFromDelete :=
+				statePersister.PlayerPersister.Delete(
+					context.Background(),
+					identifierForName(playerName))
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%v) produced error %v",
+					playerName,
+					errorFromDelete)
+			}
+		})
+	}
+}
+
+func TestRenamePlayerKeepingIdentifier(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	originalName := defaultTestPlayerNames[0]
+	otherName := defaultTestPlayerNames[1]
+	newName := testPrefix + "Renamed Player"
+	playerIdentifier := identifierForName(originalName)
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Rename player/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			for _, playerName := range []string{originalName, otherName} {
+				errorFromAdd :=
+					statePersister.PlayerPersister.Add(
+						context.Background(),
+						identifierForName(playerName),
+						playerName,
+						colorsAvailableInTest[0],
+						player.RolePlayer,
+						testPasswordHash)
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf(
+						"Add(%v, ...) produced an error %v",
+						playerName,
+						errorFromAdd)
+				}
+			}
+
+			errorFromTakenName :=
+				statePersister.PlayerPersister.UpdateName(
+					context.Background(),
+					playerIdentifier,
+					otherName)
+
+			if errorFromTakenName == nil {
+				unitTest.Fatalf(
+					"UpdateName(%v, %v) did not produce an error for a name already taken",
+					playerIdentifier,
+					otherName)
+			}
+
+			errorFromUpdateName :=
+				statePersister.PlayerPersister.UpdateName(
+					context.Background(),
+					playerIdentifier,
+					newName)
+
+			if errorFromUpdateName != nil {
+				unitTest.Fatalf(
+					"UpdateName(%v, %v) produced an error: %v",
+					playerIdentifier,
+					newName,
+					errorFromUpdateName)
+			}
+
+			renamedState, errorFromGet :=
+				statePersister.PlayerPersister.Get(
+					context.Background(),
+					playerIdentifier)
+
+			if (errorFromGet != nil) ||
+				(renamedState.Identifier() != playerIdentifier) ||
+				(renamedState.Name() != newName) ||
+				(renamedState.Color() != colorsAvailableInTest[0]) {
+				unitTest.Fatalf(
+					"UpdateName(%v, %v) then Get(%v) produced state %+v and error %v",
+					playerIdentifier,
+					newName,
+					playerIdentifier,
+					renamedState,
+					errorFromGet)
+			}
+
+			identifierFromNewName, errorFromNewName :=
+				statePersister.PlayerPersister.IdentifierForName(
+					context.Background(),
+					newName)
+
+			if (errorFromNewName != nil) || (identifierFromNewName != playerIdentifier) {
+				unitTest.Fatalf(
+					"IdentifierForName(%v) after renaming produced %v, %v - expected %v",
+					newName,
+					identifierFromNewName,
+					errorFromNewName,
+					playerIdentifier)
+			}
+
+			identifierFromOldName, errorFromOldName :=
+				statePersister.PlayerPersister.IdentifierForName(
+					context.Background(),
+					originalName)
+
+			if errorFromOldName == nil {
+				unitTest.Fatalf(
+					"IdentifierForName(%v) after renaming produced %v rather than an error",
+					originalName,
+					identifierFromOldName)
+			}
+
+			passwordHash, errorFromPasswordHash :=
+				statePersister.PlayerPersister.PasswordHash(
+					context.Background(),
+					playerIdentifier)
+
+			if (errorFromPasswordHash != nil) || (passwordHash != testPasswordHash) {
+				unitTest.Fatalf(
+					"UpdateName(%v, %v) then PasswordHash(%v) produced %v, %v - expected %v",
+					playerIdentifier,
+					newName,
+					playerIdentifier,
+					passwordHash,
+					errorFromPasswordHash,
+					testPasswordHash)
+			}
+
+			for _, playerName := range []string{originalName, otherName} {
+				errorFromDelete :=
+					statePersister.PlayerPersister.Delete(
+						context.Background(),
+						identifierForName(playerName))
+
+				if errorFromDelete != nil {
+					unitTest.Fatalf(
+						"Delete(%v) produced error %v",
+						identifierForName(playerName),
+						errorFromDelete)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeNamesAndTreatNamesDifferingByCaseAsSame(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	givenName := "  " + testPrefix + "Zoe\u0301  the   Bold "
+	normalizedName := testPrefix + "Zo\u00e9 the Bold"
+	nameInOtherCase := strings.ToUpper(normalizedName)
+	playerIdentifier := identifierForName(normalizedName)
+	otherIdentifier := identifierForName(defaultTestPlayerNames[0])
+
+	for _, statePersister := range statePersisters {
+		testIdentifier :=
+			"Normalize names/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			errorFromAdd :=
+				statePersister.PlayerPersister.Add(
+					context.Background(),
+					playerIdentifier,
+					givenName,
+					colorsAvailableInTest[0],
+					player.RolePlayer,
+					testPasswordHash)
+
+			if errorFromAdd != nil {
+				unitTest.Fatalf(
+					"Add(%q, ...) produced an error %v",
+					givenName,
+					errorFromAdd)
+			}
+
+			addedState, errorFromGet :=
+				statePersister.PlayerPersister.Get(context.Background(), playerIdentifier)
+
+			if (errorFromGet != nil) || (addedState.Name() != normalizedName) {
+				unitTest.Fatalf(
+					"Get(%v) after Add(%q, ...) produced %+v, %v - expected name %q",
+					playerIdentifier,
+					givenName,
+					addedState,
+					errorFromGet,
+					normalizedName)
+			}
+
+			identifierFromOtherCase, errorFromOtherCase :=
+				statePersister.PlayerPersister.IdentifierForName(
+					context.Background(),
+					nameInOtherCase)
+
+			if (errorFromOtherCase != nil) || (identifierFromOtherCase != playerIdentifier) {
+				unitTest.Fatalf(
+					"IdentifierForName(%q) produced %v, %v - expected %v",
+					nameInOtherCase,
+					identifierFromOtherCase,
+					errorFromOtherCase,
+					playerIdentifier)
+			}
+
+			invalidNames := []string{nameInOtherCase, testPrefix + "Bell\u0007", " "}
+			for _, invalidName := range invalidNames {
+				errorFromInvalidAdd :=
+					statePersister.PlayerPersister.Add(
+						context.Background(),
+						otherIdentifier,
+						invalidName,
+						colorsAvailableInTest[1],
+						player.RolePlayer,
+						testPasswordHash)
+
+				if errorFromInvalidAdd == nil {
+					unitTest.Fatalf(
+						"Add(%q, ...) did not produce an error",
+						invalidName)
+				}
+			}
+
+			// The player may change only the case of their own name.
+			errorFromUpdateName :=
+				statePersister.PlayerPersister.UpdateName(
+					context.Background(),
+					playerIdentifier,
+					nameInOtherCase)
+
+			if errorFromUpdateName != nil {
+				unitTest.Fatalf(
+					"UpdateName(%v, %q) produced an error %v",
+					playerIdentifier,
+					nameInOtherCase,
+					errorFromUpdateName)
+			}
+
+			renamedState, errorFromGetAfterRename :=
+				statePersister.PlayerPersister.Get(context.Background(), playerIdentifier)
+
+			if (errorFromGetAfterRename != nil) || (renamedState.Name() != nameInOtherCase) {
+				unitTest.Fatalf(
+					"UpdateName(%v, %q) then Get(...) produced %+v, %v",
+					playerIdentifier,
+					nameInOtherCase,
+					renamedState,
+					errorFromGetAfterRename)
+			}
+
+			errorFromDelete :=
+				statePersister.PlayerPersister.Delete(context.Background(), playerIdentifier)
+
+			if errorFromDelete != nil {
+				unitTest.Fatalf(
+					"Delete(%v) produced error %v",
+					playerIdentifier,
+					errorFromDelete)
+			}
+		})
+	}
+}
+
+func TestSearchPlayersByPrefixWithOrderAndLimit(unitTest *testing.T) {
+	statePersisters := preparePersisters(unitTest)
+	searchPrefix := testPrefix + "Search "
+	matchingNames := []string{
+		searchPrefix + "Anna",
+		searchPrefix + "Bert",
+		searchPrefix + "Carl",
+	}
+	nonmatchingName := testPrefix + "Other Dora"
+	namesToAdd := append([]string{nonmatchingName}, matchingNames...)
+
+	testCases := []struct {
+		testName      string
+		searchSuffix  string
+		nameOrder     player.NameOrder
+		afterName     string
+		maximumNumber int
+		expectedNames []string
+	}{
+		{
+			testName:      "All ascending",
+			nameOrder:     player.NameAscending,
+			maximumNumber: 10,
+			expectedNames: matchingNames,
+		},
+		{
+			testName:      "All descending",
+			nameOrder:     player.NameDescending,
+			maximumNumber: 10,
+			expectedNames: []string{matchingNames[2], matchingNames[1], matchingNames[0]},
+		},
+		{
+			testName:      "Limited ascending",
+			nameOrder:     player.NameAscending,
+			maximumNumber: 2,
+			expectedNames: matchingNames[:2],
+		},
+		{
+			testName:      "After name ascending",
+			nameOrder:     player.NameAscending,
+			afterName:     matchingNames[0],
+			maximumNumber: 10,
+			expectedNames: matchingNames[1:],
+		},
+		{
+			testName:      "After name descending",
+			nameOrder:     player.NameDescending,
+			afterName:     matchingNames[1],
+			maximumNumber: 10,
+			expectedNames: matchingNames[:1],
+		},
+		{
+			testName:      "Longer prefix",
+			searchSuffix:  "B",
+			nameOrder:     player.NameAscending,
+			maximumNumber: 10,
+			expectedNames: matchingNames[1:2],
+		},
+		{
+			testName:      "No match",
+			searchSuffix:  "Z",
+			nameOrder:     player.NameAscending,
+			maximumNumber: 10,
+			expectedNames: []string{},
+		},
+	}
+
+	for _, statePersister := range statePersisters {
+		testIdentifier := "Search players/" + statePersister.PersisterDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			for _, nameToAdd := range namesToAdd {
+				errorFromAdd :=
+					statePersister.PlayerPersister.Add(
+						context.Background(),
+						identifierForName(nameToAdd),
+						nameToAdd,
+						colorsAvailableInTest[0],
+						player.RolePlayer,
+						testPasswordHash)
+
+				if errorFromAdd != nil {
+					unitTest.Fatalf(
+						"Add(%v, ...) produced an error %v",
+						nameToAdd,
+						errorFromAdd)
+				}
+			}
+
+			for _, testCase := range testCases {
+				searchCriteria :=
+					player.SearchCriteria{
+						NamePrefix:    searchPrefix + testCase.searchSuffix,
+						Order:         testCase.nameOrder,
+						AfterName:     testCase.afterName,
+						MaximumNumber: testCase.maximumNumber,
+					}
+
+				foundPlayers, errorFromSearch :=
+					statePersister.PlayerPersister.Search(context.Background(), searchCriteria)
+
+				foundNames := make([]string, 0, len(foundPlayers))
+				for _, foundPlayer := range foundPlayers {
+					foundNames = append(foundNames, foundPlayer.Name())
+				}
+
+				if (errorFromSearch != nil) ||
+					!reflect.DeepEqual(foundNames, testCase.expectedNames) {
+					unitTest.Errorf(
+						"%v: Search(%+v) produced %v, %v - expected %v",
+						testCase.testName,
+						searchCriteria,
+						foundNames,
+						errorFromSearch,
+						testCase.expectedNames)
+				}
+			}
+
+			for _, nameToDelete := range namesToAdd {
+				errorFromDelete :=
+					statePersister.PlayerPersister.Delete(
+						context.Background(),
+						identifierForName(nameToDelete))
+
+				if errorFromDelete != nil {
+					unitTest.Fatalf(
+						"Delete(%v) produced error %v",
+						nameToDelete,
+						errorFromDelete)
+				}
+			}
+		})
+	}
+}
+
 func assertPlayerNamesAreCorrectAndGetIsConsistentWithAll(
 	testIdentifier string,
 	unitTest *testing.T,
@@ -724,25 +1600,26 @@ func assertPlayerNamesAreCorrectAndGetIsConsistentWithAll(
 	// Now we check that Get(...) is consistent with each player from All().
 	for _, stateFromAll := range statesFromAll {
 		// At this point we can be sure that there are no nils in statesFromAll.
-		nameFromAll := stateFromAll.Name()
+		identifierFromAll := stateFromAll.Identifier()
 		stateFromGet, errorFromGet :=
 			playerPersister.Get(
 				context.Background(),
-				nameFromAll)
+				identifierFromAll)
 		if errorFromGet != nil {
 			unitTest.Fatalf(
 				testIdentifier+"/Get(%v) produced error %v",
-				nameFromAll,
+				identifierFromAll,
 				errorFromGet)
 		}
 
 		if stateFromGet == nil {
 			unitTest.Fatalf(
 				testIdentifier+"/nil state from Get(%v)",
-				nameFromAll)
+				identifierFromAll)
 		}
 
-		if (stateFromGet.Name() != nameFromAll) ||
+		if (stateFromGet.Identifier() != identifierFromAll) ||
+			(stateFromGet.Name() != stateFromAll.Name()) ||
 			(stateFromGet.Color() != stateFromAll.Color()) {
 			unitTest.Fatalf(
 				testIdentifier+"/State from Get(...) %v did not match state from All() %v",
@@ -760,7 +1637,7 @@ func getStateAndAssertNoError(
 	playerState, errorGettingState :=
 		playerPersister.Get(
 			context.Background(),
-			playerName)
+			identifierForName(playerName))
 	if errorGettingState != nil {
 		unitTest.Fatalf(
 			testIdentifier+"/Get(%v) produced an error %v",
@@ -774,10 +1651,11 @@ func getStateAndAssertNoError(
 			playerName)
 	}
 
-	if playerState.Name() != playerName {
+	if (playerState.Identifier() != identifierForName(playerName)) ||
+		(playerState.Name() != playerName) {
 		unitTest.Fatalf(
-			testIdentifier+"/Get(%v) produced player with different name %v",
-			playerName,
+			testIdentifier+"/Get(%v) produced player with different identifier or name %v",
+			identifierForName(playerName),
 			playerState)
 	}
 
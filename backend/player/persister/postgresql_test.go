@@ -71,7 +71,7 @@ func TestReturnErrorFromInvalidConnectionString(unitTest *testing.T) {
 	playerName := "Should Not Matter"
 	playerColor := "should not matter"
 	errorFromAddRequest :=
-		postgresqlPersister.Add(executionContext, playerName, playerColor)
+		postgresqlPersister.Add(executionContext, playerName, playerName, playerColor, "", "")
 
 	if errorFromAddRequest == nil {
 		unitTest.Fatalf(
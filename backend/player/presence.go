@@ -0,0 +1,142 @@
+package player
+
+import (
+	"sync"
+	"time"
+)
+
+// PresenceOnline is the presence status of players who have sent a heartbeat recently
+// and were active when they sent it.
+const PresenceOnline = "online"
+
+// PresenceIdle is the presence status of players who reported that they were inactive
+// in their last heartbeat, or whose last heartbeat is no longer recent.
+const PresenceIdle = "idle"
+
+// PresenceOffline is the presence status of players who have not sent a heartbeat for
+// long enough that they are assumed to have left.
+const PresenceOffline = "offline"
+
+// presenceIdleAfter is how long after their last heartbeat players are shown as idle
+// even if they were active when they sent it. The frontend should send heartbeats more
+// often than this.
+const presenceIdleAfter = time.Minute
+
+// presenceOfflineAfter is how long after their last heartbeat players are shown as
+// offline, and their heartbeats are forgotten.
+const presenceOfflineAfter = 3 * time.Minute
+
+// Presence holds whether a player is online, idle, or offline, along with the name of
+// the game which the player has open, which is empty if the player has no game open or
+// is offline.
+type Presence struct {
+	Status         string
+	ViewedGameName string
+}
+
+// IsViewingGame returns true if the player has the game with the given name open.
+func (playerPresence Presence) IsViewingGame(gameName string) bool {
+	return (gameName != "") && (playerPresence.ViewedGameName == gameName)
+}
+
+// heartbeatRecord holds what a player reported in their latest heartbeat.
+type heartbeatRecord struct {
+	receivedAt     time.Time
+	viewedGameName string
+	isIdle         bool
+}
+
+// PresenceTracker keeps the latest heartbeat of each player in memory, as presence
+// changes too often to be worth persisting and is meaningless after a restart anyway.
+// Heartbeats expire by themselves: they are compared with the current time whenever a
+// presence is read, and expired heartbeats are removed while recording new ones, so
+// nothing outside the tracker has to clean it up. As the heartbeats are held by a single
+// server instance, a deployment with several instances should route the heartbeats and
+// reads of presence of a player to the same instance. The times are given explicitly so
+// that the tracker does not depend on the clock of the machine.
+type PresenceTracker struct {
+	idleAfter         time.Duration
+	offlineAfter      time.Duration
+	mutualExclusion   sync.Mutex
+	latestHeartbeats  map[string]heartbeatRecord
+	timeOfLastPruning time.Time
+}
+
+// NewPresenceTracker creates a new PresenceTracker which shows players as idle once the
+// first given duration has passed since their last heartbeat, and as offline once the
+// second given duration has passed.
+func NewPresenceTracker(
+	idleAfter time.Duration,
+	offlineAfter time.Duration) *PresenceTracker {
+	return &PresenceTracker{
+		idleAfter:        idleAfter,
+		offlineAfter:     offlineAfter,
+		latestHeartbeats: make(map[string]heartbeatRecord, 0),
+	}
+}
+
+// RecordHeartbeat records that the given player was present at the given time, with
+// the given game open, which may be empty, and whether the player reported being
+// inactive. It also forgets expired heartbeats, at most once per expiry period so that
+// frequent heartbeats do not each have to look at every player.
+func (presenceTracker *PresenceTracker) RecordHeartbeat(
+	playerIdentifier string,
+	viewedGameName string,
+	isIdle bool,
+	receivedAt time.Time) {
+	presenceTracker.mutualExclusion.Lock()
+	defer presenceTracker.mutualExclusion.Unlock()
+
+	presenceTracker.latestHeartbeats[playerIdentifier] =
+		heartbeatRecord{
+			receivedAt:     receivedAt,
+			viewedGameName: viewedGameName,
+			isIdle:         isIdle,
+		}
+
+	if receivedAt.Sub(presenceTracker.timeOfLastPruning) < presenceTracker.offlineAfter {
+		return
+	}
+
+	for trackedPlayer, latestHeartbeat := range presenceTracker.latestHeartbeats {
+		if presenceTracker.hasExpired(latestHeartbeat, receivedAt) {
+			delete(presenceTracker.latestHeartbeats, trackedPlayer)
+		}
+	}
+
+	presenceTracker.timeOfLastPruning = receivedAt
+}
+
+// Presence returns the presence of the given player at the given time.
+func (presenceTracker *PresenceTracker) Presence(
+	playerIdentifier string,
+	currentTime time.Time) Presence {
+	presenceTracker.mutualExclusion.Lock()
+	defer presenceTracker.mutualExclusion.Unlock()
+
+	latestHeartbeat, hasHeartbeat :=
+		presenceTracker.latestHeartbeats[playerIdentifier]
+
+	if !hasHeartbeat || presenceTracker.hasExpired(latestHeartbeat, currentTime) {
+		return Presence{Status: PresenceOffline}
+	}
+
+	presenceStatus := PresenceOnline
+	if latestHeartbeat.isIdle ||
+		(currentTime.Sub(latestHeartbeat.receivedAt) >= presenceTracker.idleAfter) {
+		presenceStatus = PresenceIdle
+	}
+
+	return Presence{
+		Status:         presenceStatus,
+		ViewedGameName: latestHeartbeat.viewedGameName,
+	}
+}
+
+// hasExpired returns true if the given heartbeat is too old at the given time to show
+// the player as anything other than offline.
+func (presenceTracker *PresenceTracker) hasExpired(
+	latestHeartbeat heartbeatRecord,
+	currentTime time.Time) bool {
+	return currentTime.Sub(latestHeartbeat.receivedAt) >= presenceTracker.offlineAfter
+}
@@ -0,0 +1,155 @@
+package player_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/player"
+)
+
+func TestPresenceFromHeartbeats(unitTest *testing.T) {
+	idleAfter := time.Minute
+	offlineAfter := 3 * time.Minute
+	startTime := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName         string
+		heartbeatIsIdle  bool
+		viewedGameName   string
+		timeSinceLast    time.Duration
+		expectedPresence player.Presence
+	}{
+		{
+			testName:         "active and recent",
+			viewedGameName:   "test game",
+			timeSinceLast:    idleAfter - time.Second,
+			expectedPresence: player.Presence{Status: player.PresenceOnline, ViewedGameName: "test game"},
+		},
+		{
+			testName:         "reported idle",
+			heartbeatIsIdle:  true,
+			viewedGameName:   "test game",
+			timeSinceLast:    time.Second,
+			expectedPresence: player.Presence{Status: player.PresenceIdle, ViewedGameName: "test game"},
+		},
+		{
+			testName:         "active but not recent",
+			timeSinceLast:    idleAfter,
+			expectedPresence: player.Presence{Status: player.PresenceIdle},
+		},
+		{
+			testName:         "expired",
+			viewedGameName:   "test game",
+			timeSinceLast:    offlineAfter,
+			expectedPresence: player.Presence{Status: player.PresenceOffline},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			presenceTracker := player.NewPresenceTracker(idleAfter, offlineAfter)
+			presenceTracker.RecordHeartbeat(
+				"present player",
+				testCase.viewedGameName,
+				testCase.heartbeatIsIdle,
+				startTime)
+
+			actualPresence :=
+				presenceTracker.Presence(
+					"present player",
+					startTime.Add(testCase.timeSinceLast))
+
+			if actualPresence != testCase.expectedPresence {
+				unitTest.Fatalf(
+					"Presence(...) returned %+v, expected %+v",
+					actualPresence,
+					testCase.expectedPresence)
+			}
+
+			unknownPresence := presenceTracker.Presence("unknown player", startTime)
+			if unknownPresence != (player.Presence{Status: player.PresenceOffline}) {
+				unitTest.Fatalf(
+					"Presence(unknown player) returned %+v, expected offline",
+					unknownPresence)
+			}
+		})
+	}
+}
+
+func TestPresenceReturnsAfterExpiry(unitTest *testing.T) {
+	presenceTracker := player.NewPresenceTracker(time.Minute, 3*time.Minute)
+	startTime := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	numberOfPlayers := 5
+	for playerIndex := 0; playerIndex < numberOfPlayers; playerIndex++ {
+		presenceTracker.RecordHeartbeat(
+			fmt.Sprintf("player %v", playerIndex),
+			"",
+			false,
+			startTime)
+	}
+
+	// This heartbeat is late enough for the tracker to forget the expired heartbeats.
+	laterTime := startTime.Add(time.Hour)
+	presenceTracker.RecordHeartbeat("player 0", "later game", false, laterTime)
+
+	for playerIndex := 1; playerIndex < numberOfPlayers; playerIndex++ {
+		playerIdentifier := fmt.Sprintf("player %v", playerIndex)
+		actualPresence := presenceTracker.Presence(playerIdentifier, laterTime)
+		if actualPresence.Status != player.PresenceOffline {
+			unitTest.Fatalf(
+				"Presence(%v) returned %+v, expected offline",
+				playerIdentifier,
+				actualPresence)
+		}
+	}
+
+	returningPresence := presenceTracker.Presence("player 0", laterTime)
+	expectedPresence :=
+		player.Presence{Status: player.PresenceOnline, ViewedGameName: "later game"}
+	if returningPresence != expectedPresence {
+		unitTest.Fatalf(
+			"Presence(player 0) returned %+v, expected %+v",
+			returningPresence,
+			expectedPresence)
+	}
+}
+
+func TestCollectionRecordsHeartbeats(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("No function should be called"))
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	initialPresence :=
+		stateCollection.Presence(context.Background(), "present player")
+	if initialPresence.Status != player.PresenceOffline {
+		unitTest.Fatalf(
+			"Presence(...) before any heartbeat returned %+v, expected offline",
+			initialPresence)
+	}
+
+	stateCollection.RecordHeartbeat(
+		context.Background(),
+		"present player",
+		"test game",
+		false)
+
+	actualPresence :=
+		stateCollection.Presence(context.Background(), "present player")
+	expectedPresence :=
+		player.Presence{Status: player.PresenceOnline, ViewedGameName: "test game"}
+	if actualPresence != expectedPresence {
+		unitTest.Fatalf(
+			"Presence(...) after heartbeat returned %+v, expected %+v",
+			actualPresence,
+			expectedPresence)
+	}
+}
@@ -0,0 +1,86 @@
+package player
+
+import (
+	"fmt"
+	"time"
+)
+
+// HintDisplayText is the preferred hint display of players who want the hints about
+// their cards to be written out in words.
+const HintDisplayText = "text"
+
+// HintDisplaySymbols is the preferred hint display of players who want the hints
+// about their cards to be shown as colored symbols.
+const HintDisplaySymbols = "symbols"
+
+// NotificationPreferences holds the choices of a player about which events should
+// produce notifications.
+type NotificationPreferences struct {
+	OnTurn        bool
+	OnInvitation  bool
+	OnChatMessage bool
+}
+
+// Profile holds the preferences of a player which are stored on the server so that
+// they follow the player from device to device. The zero value is a valid profile
+// which leaves every choice to the frontend. It is a plain struct so that new
+// preferences can be added as new fields without changing the persister interface,
+// and players stored before a field existed just get the zero value for it.
+type Profile struct {
+	// PreferredRulesets holds the identifiers of the rulesets which the player would
+	// like to be offered first, in order of preference.
+	PreferredRulesets []int
+
+	// PreferredHintDisplay is HintDisplayText, HintDisplaySymbols, or empty if the
+	// player has no preference.
+	PreferredHintDisplay string
+
+	// Timezone is the name of a location in the IANA Time Zone database, such as
+	// "Europe/Tallinn", or empty if the player has no preference.
+	Timezone string
+
+	// Notifications holds the choices of the player about notifications.
+	Notifications NotificationPreferences
+}
+
+// ValidateProfile returns an error if the given profile has a hint display which is
+// not known or a timezone which cannot be found. The ruleset identifiers are not
+// checked, as only the game package knows which rulesets exist, and a preference for
+// a ruleset which has been removed does no harm.
+func ValidateProfile(playerProfile Profile) error {
+	hintDisplay := playerProfile.PreferredHintDisplay
+	if (hintDisplay != "") &&
+		(hintDisplay != HintDisplayText) &&
+		(hintDisplay != HintDisplaySymbols) {
+		return fmt.Errorf(
+			"Hint display %v is not valid, must be %v, %v, or empty",
+			hintDisplay,
+			HintDisplayText,
+			HintDisplaySymbols)
+	}
+
+	if playerProfile.Timezone != "" {
+		_, errorFromLocation := time.LoadLocation(playerProfile.Timezone)
+		if errorFromLocation != nil {
+			return fmt.Errorf(
+				"Timezone %v is not valid: %v",
+				playerProfile.Timezone,
+				errorFromLocation)
+		}
+	}
+
+	return nil
+}
+
+// DeepCopy returns a copy of the profile which does not share the slice of preferred
+// rulesets.
+func (playerProfile Profile) DeepCopy() Profile {
+	copiedProfile := playerProfile
+
+	if playerProfile.PreferredRulesets != nil {
+		copiedProfile.PreferredRulesets = make([]int, len(playerProfile.PreferredRulesets))
+		copy(copiedProfile.PreferredRulesets, playerProfile.PreferredRulesets)
+	}
+
+	return copiedProfile
+}
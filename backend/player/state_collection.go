@@ -2,67 +2,210 @@ package player
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
 )
 
+// identifierLength is the number of random bytes which make up the identifier of a
+// new player.
+const identifierLength = 16
+
+// defaultPageSize is the number of players in a page of search results if no page
+// size is requested.
+const defaultPageSize = 20
+
+// maximumPageSize is the largest number of players in a page of search results, so
+// that no request can make the collection read every player.
+const maximumPageSize = 100
+
+// SearchResult holds a page of players found by a search, along with the cursor which
+// should be given to get the next page, which is empty if there are no more players.
+type SearchResult struct {
+	Players    []ReadonlyState
+	NextCursor string
+}
+
 // StateCollection wraps around a player.StatePersister to encapsulate logic acting on
 // the functions of the interface. It also has the responsibility of maintaining the
-// list of chat colors which are available to players, and providing default colors if
-// player definitions do not contain specific colors.
+// list of chat colors which are suggested to players, providing default colors if
+// player definitions do not contain specific colors, and checking that other colors
+// can be read against the background of the chat. It also knows which players are
+// configured to be administrators regardless of the roles which are persisted, what
+// should happen when a player is deleted, and which players are present.
 type StateCollection struct {
-	statePersister StatePersister
-	chatColorSlice []string
-	chatColorMap   map[string]bool
-	numberOfColors int
+	statePersister           StatePersister
+	chatColorSlice           []string
+	chatColorMap             map[string]bool
+	numberOfColors           int
+	chatBackgroundColor      string
+	administratorIdentifiers map[string]bool
+	deletionPolicy           DeletionPolicy
+	participationChecker     ParticipationChecker
+	presenceTracker          *PresenceTracker
 }
 
 // NewCollection creates a new StateCollection around the given StatePersister and list
-// of chat colors, giving default colors to the initial players. It returns nil and an
-// error if given no chat colors.
+// of suggested chat colors, giving default colors to the initial players. Players may
+// also choose any color of the form #RRGGBB which has enough contrast against the given
+// background color of the chat. The players with the given administrator identifiers
+// always have the administrator role, so that there is always a way to manage the other
+// players, even before any role has been persisted. Identifiers are used rather than
+// names because anyone could register with a name which has not yet been taken, or
+// rename themselves to it. Deleted players are handled according to the given policy.
+// The given participation checker is needed for DeletionBlockedWhileInGames, and is
+// also used to stop players from choosing colors which are nearly the same as those of
+// the players with whom they share games. It may be nil if the policy does not need it,
+// in which case chosen colors are not compared with those of other players.
 func NewCollection(
 	statePersister StatePersister,
-	availableColors []string) *StateCollection {
+	availableColors []string,
+	chatBackgroundColor string,
+	administratorIdentifiers []string,
+	deletionPolicy DeletionPolicy,
+	participationChecker ParticipationChecker) *StateCollection {
 	// We keep a map of colors to validity to both remove duplicate colors and
 	// to make it easy to check if a color is valid when updating players.
+	// The unique colors keep the order in which they were given, so that the same
+	// color is always picked first when a player has to be shown in another color.
 	colorMap := make(map[string]bool, 0)
+	uniqueColors := make([]string, 0)
 	for _, chatColor := range availableColors {
+		if !colorMap[chatColor] {
+			uniqueColors = append(uniqueColors, chatColor)
+		}
+
 		colorMap[chatColor] = true
 	}
 
-	uniqueColors := make([]string, 0)
-
-	for uniqueColor, isColorAvailable := range colorMap {
-		if isColorAvailable {
-			uniqueColors = append(uniqueColors, uniqueColor)
-		}
+	administratorMap := make(map[string]bool, len(administratorIdentifiers))
+	for _, administratorIdentifier := range administratorIdentifiers {
+		administratorMap[administratorIdentifier] = true
 	}
 
 	newCollection :=
 		&StateCollection{
-			statePersister: statePersister,
-			chatColorSlice: uniqueColors,
-			chatColorMap:   colorMap,
-			numberOfColors: len(uniqueColors),
+			statePersister:           statePersister,
+			chatColorSlice:           uniqueColors,
+			chatColorMap:             colorMap,
+			numberOfColors:           len(uniqueColors),
+			chatBackgroundColor:      chatBackgroundColor,
+			administratorIdentifiers: administratorMap,
+			deletionPolicy:           deletionPolicy,
+			participationChecker:     participationChecker,
+			presenceTracker:          NewPresenceTracker(presenceIdleAfter, presenceOfflineAfter),
 		}
 
 	return newCollection
 }
 
-// All just wraps around the All function of the internal persistence store.
+// All wraps around the All function of the internal persistence store, leaving out
+// the tombstones of deleted players.
 func (stateCollection *StateCollection) All(
 	executionContext context.Context) ([]ReadonlyState, error) {
-	return stateCollection.statePersister.All(executionContext)
+	allPlayers, errorFromAll := stateCollection.statePersister.All(executionContext)
+	if errorFromAll != nil {
+		return nil, errorFromAll
+	}
+
+	registeredPlayers := make([]ReadonlyState, 0, len(allPlayers))
+	for _, playerState := range allPlayers {
+		if playerState.Role() != RoleDeleted {
+			registeredPlayers = append(registeredPlayers, playerState)
+		}
+	}
+
+	return registeredPlayers, nil
+}
+
+// Search returns a page of at most the given number of players whose names start with
+// the given prefix, in the given order, starting after the player encoded in the given
+// cursor, or from the first player if the cursor is empty. A page size which is not
+// positive gives the default page size, and the page size is limited so that no search
+// reads every player. Tombstones of deleted players are left out, so the persister may
+// be asked for more players than the page size.
+func (stateCollection *StateCollection) Search(
+	executionContext context.Context,
+	namePrefix string,
+	nameOrder NameOrder,
+	pageCursor string,
+	pageSize int) (SearchResult, error) {
+	if (nameOrder != NameAscending) && (nameOrder != NameDescending) {
+		return SearchResult{}, fmt.Errorf("Name order %v is not valid", nameOrder)
+	}
+
+	afterName, errorFromCursor := nameFromCursor(pageCursor)
+	if errorFromCursor != nil {
+		return SearchResult{}, errorFromCursor
+	}
+
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	} else if pageSize > maximumPageSize {
+		pageSize = maximumPageSize
+	}
+
+	// We look for one more player than fits in the page so that we know whether there
+	// is another page.
+	numberToFind := pageSize + 1
+	foundPlayers := make([]ReadonlyState, 0, numberToFind)
+
+	for len(foundPlayers) < numberToFind {
+		numberToRequest := numberToFind - len(foundPlayers)
+		searchCriteria :=
+			SearchCriteria{
+				NamePrefix:    namePrefix,
+				Order:         nameOrder,
+				AfterName:     afterName,
+				MaximumNumber: numberToRequest,
+			}
+
+		matchingPlayers, errorFromSearch :=
+			stateCollection.statePersister.Search(executionContext, searchCriteria)
+		if errorFromSearch != nil {
+			return SearchResult{}, errorFromSearch
+		}
+
+		for _, matchingPlayer := range matchingPlayers {
+			if matchingPlayer.Role() != RoleDeleted {
+				foundPlayers = append(foundPlayers, matchingPlayer)
+			}
+		}
+
+		if len(matchingPlayers) < numberToRequest {
+			break
+		}
+
+		afterName = matchingPlayers[len(matchingPlayers)-1].Name()
+	}
+
+	if len(foundPlayers) <= pageSize {
+		return SearchResult{Players: foundPlayers}, nil
+	}
+
+	pageOfPlayers := foundPlayers[:pageSize]
+	searchResult :=
+		SearchResult{
+			Players:    pageOfPlayers,
+			NextCursor: cursorFromName(pageOfPlayers[pageSize-1].Name()),
+		}
+
+	return searchResult, nil
 }
 
-// Get just wraps around the Get function of the internal persistence store.
+// Get just wraps around the Get function of the internal persistence store. It also
+// returns the tombstones of deleted players, so that games can still show them.
 func (stateCollection *StateCollection) Get(
 	executionContext context.Context,
-	playerName string) (ReadonlyState, error) {
-	return stateCollection.statePersister.Get(executionContext, playerName)
+	playerIdentifier string) (ReadonlyState, error) {
+	return stateCollection.statePersister.Get(executionContext, playerIdentifier)
 }
 
 // AvailableChatColors returns a deep copy of state persistence store's chat
-// color slice, and ignores the context.
+// color slice, which is the palette suggested to players, and ignores the context.
 func (stateCollection *StateCollection) AvailableChatColors(
 	executionContext context.Context) []string {
 	numberOfColors := len(stateCollection.chatColorSlice)
@@ -72,63 +215,733 @@ func (stateCollection *StateCollection) AvailableChatColors(
 	return deepCopy
 }
 
-// Add ensures that the player definition has a chat color before calling
-// the Add function of the internal persistence store.
+// Add ensures that the player definition has a valid chat color and a password before
+// calling the Add function of the internal persistence store with a new random
+// identifier and a salted hash of the password. Players who do not choose a color are
+// given one of the suggested colors according to their random identifiers, so that the
+// colors are spread out without having to read every player. New players have the
+// player role. It returns the identifier of the new player.
 func (stateCollection *StateCollection) Add(
 	executionContext context.Context,
 	playerName string,
-	chatColor string) error {
+	chatColor string,
+	plainPassword string) (string, error) {
 	if playerName == "" {
-		return fmt.Errorf("Player must have a name")
+		return "", fmt.Errorf("Player must have a name")
+	}
+
+	if plainPassword == "" {
+		return "", fmt.Errorf("Player must have a password")
+	}
+
+	playerIdentifier, errorFromIdentifier := newPlayerIdentifier()
+	if errorFromIdentifier != nil {
+		return "", errorFromIdentifier
 	}
 
 	if chatColor == "" {
-		allPlayers, errorFromAll :=
-			stateCollection.statePersister.All(executionContext)
-		if errorFromAll != nil {
-			return errorFromAll
+		chatColor = stateCollection.defaultColorFor(playerIdentifier)
+	} else {
+		validColor, errorFromColor := stateCollection.validChatColor(chatColor)
+		if errorFromColor != nil {
+			return "", errorFromColor
 		}
 
-		playerCount := len(allPlayers)
-		colorIndex := playerCount % stateCollection.numberOfColors
-		chatColor = stateCollection.chatColorSlice[colorIndex]
-	} else if !stateCollection.chatColorMap[chatColor] {
-		return fmt.Errorf(
-			"Chat color %v is not in list of valid colors %v",
+		chatColor = validColor
+	}
+
+	passwordHash, errorFromHash := HashPassword(plainPassword)
+	if errorFromHash != nil {
+		return "", errorFromHash
+	}
+
+	errorFromAdd :=
+		stateCollection.statePersister.Add(
+			executionContext,
+			playerIdentifier,
+			playerName,
 			chatColor,
-			stateCollection.chatColorSlice)
+			RolePlayer,
+			passwordHash)
+
+	if errorFromAdd != nil {
+		return "", errorFromAdd
+	}
+
+	return playerIdentifier, nil
+}
+
+// Authenticate returns the identifier of the player with the given name if the given
+// password is correct for that player and the player has not been deleted, and
+// otherwise returns an error which does not reveal whether the player exists.
+func (stateCollection *StateCollection) Authenticate(
+	executionContext context.Context,
+	playerName string,
+	plainPassword string) (string, error) {
+	errorFromAuthentication := ErrIncorrectCredentials
+
+	playerIdentifier, errorFromName :=
+		stateCollection.statePersister.IdentifierForName(executionContext, playerName)
+
+	if errorFromName != nil {
+		return "", errorFromAuthentication
+	}
+
+	passwordHash, errorFromGet :=
+		stateCollection.statePersister.PasswordHash(executionContext, playerIdentifier)
+
+	if (errorFromGet != nil) || !IsPasswordCorrect(plainPassword, passwordHash) {
+		return "", errorFromAuthentication
+	}
+
+	playerState, errorFromState :=
+		stateCollection.statePersister.Get(executionContext, playerIdentifier)
+
+	if (errorFromState != nil) || (playerState.Role() == RoleDeleted) {
+		return "", errorFromAuthentication
+	}
+
+	return playerIdentifier, nil
+}
+
+// Rename checks that the new name is not empty and that the player has not been
+// deleted then calls the UpdateName function of the internal persistence store. The
+// identifier of the player does not change, so the games of the player are not
+// affected.
+func (stateCollection *StateCollection) Rename(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) error {
+	if playerName == "" {
+		return fmt.Errorf("Player must have a name")
 	}
 
-	return stateCollection.statePersister.Add(
+	errorFromPlayer :=
+		stateCollection.errorUnlessActivePlayer(executionContext, playerIdentifier)
+	if errorFromPlayer != nil {
+		return errorFromPlayer
+	}
+
+	return stateCollection.statePersister.UpdateName(
 		executionContext,
-		playerName,
-		chatColor)
+		playerIdentifier,
+		playerName)
 }
 
-// UpdateColor checks the validity of the color then calls the UpdateColor
-// function of the internal persistence store.
+// UpdateColor checks the validity of the color, that the player has not been deleted,
+// and that the color is not nearly the same as the color of any player who shares a
+// game with the given player, then calls the UpdateColor function of the internal
+// persistence store.
 func (stateCollection *StateCollection) UpdateColor(
 	executionContext context.Context,
-	playerName string,
+	playerIdentifier string,
 	chatColor string) error {
-	if !stateCollection.chatColorMap[chatColor] {
-		return fmt.Errorf(
-			"Chat color %v is not in list of valid colors %v",
-			chatColor,
-			stateCollection.chatColorSlice)
+	validColor, errorFromColor := stateCollection.validChatColor(chatColor)
+	if errorFromColor != nil {
+		return errorFromColor
+	}
+
+	errorFromPlayer :=
+		stateCollection.errorUnlessActivePlayer(executionContext, playerIdentifier)
+	if errorFromPlayer != nil {
+		return errorFromPlayer
+	}
+
+	errorFromFellows :=
+		stateCollection.errorIfNearDuplicateOfFellowParticipant(
+			executionContext,
+			playerIdentifier,
+			validColor)
+	if errorFromFellows != nil {
+		return errorFromFellows
 	}
 
 	return stateCollection.statePersister.UpdateColor(
 		executionContext,
-		playerName,
-		chatColor)
+		playerIdentifier,
+		validColor)
+}
+
+// SeparatedChatColors returns the chat colors in which the given players are shown in
+// a game which they share, in the same order as the players, so that the colors can be
+// read against the background of the chat and can be told apart. Each player keeps
+// their own color unless it does not have enough contrast against the background or
+// is nearly the same as the color of a player earlier in the list, in which case the
+// player is shown in the first of the suggested colors which is not nearly the same as
+// the color of any of the other given players. The saved colors of the players are
+// not changed. It returns an error if any player is not registered or if there is no
+// such color for a player who needs one.
+func (stateCollection *StateCollection) SeparatedChatColors(
+	executionContext context.Context,
+	playerIdentifiers []string) ([]string, error) {
+	participantColors := make([]string, len(playerIdentifiers))
+	for participantIndex, playerIdentifier := range playerIdentifiers {
+		playerState, errorFromGet :=
+			stateCollection.statePersister.Get(executionContext, playerIdentifier)
+		if errorFromGet != nil {
+			return nil, errorFromGet
+		}
+
+		participantColors[participantIndex] = playerState.Color()
+	}
+
+	for participantIndex, playerIdentifier := range playerIdentifiers {
+		currentColor := participantColors[participantIndex]
+		_, errorFromColor := stateCollection.validChatColor(currentColor)
+
+		if (errorFromColor == nil) &&
+			!isNearDuplicateOfAny(currentColor, participantColors[:participantIndex]) {
+			continue
+		}
+
+		otherColors := make([]string, 0, len(participantColors)-1)
+		otherColors = append(otherColors, participantColors[:participantIndex]...)
+		otherColors = append(otherColors, participantColors[participantIndex+1:]...)
+
+		replacementColor, errorFromReplacement :=
+			stateCollection.paletteColorApartFrom(playerIdentifier, otherColors)
+		if errorFromReplacement != nil {
+			return nil, errorFromReplacement
+		}
+
+		participantColors[participantIndex] = replacementColor
+	}
+
+	return participantColors, nil
+}
+
+// UpdateRole checks the validity of the role and that the player has not been deleted,
+// as a tombstone must not be brought back as an active player, then calls the
+// UpdateRole function of the internal persistence store.
+func (stateCollection *StateCollection) UpdateRole(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerRole string) error {
+	if (playerRole != RoleAdministrator) && (playerRole != RolePlayer) {
+		return fmt.Errorf(
+			"Role %v is not valid, must be %v or %v",
+			playerRole,
+			RoleAdministrator,
+			RolePlayer)
+	}
+
+	errorFromPlayer :=
+		stateCollection.errorUnlessActivePlayer(executionContext, playerIdentifier)
+	if errorFromPlayer != nil {
+		return errorFromPlayer
+	}
+
+	return stateCollection.statePersister.UpdateRole(
+		executionContext,
+		playerIdentifier,
+		playerRole)
+}
+
+// UpdateProfile checks the validity of the profile and that the player has not been
+// deleted then calls the UpdateProfile function of the internal persistence store.
+func (stateCollection *StateCollection) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile Profile) error {
+	errorFromValidation := ValidateProfile(playerProfile)
+	if errorFromValidation != nil {
+		return errorFromValidation
+	}
+
+	errorFromPlayer :=
+		stateCollection.errorUnlessActivePlayer(executionContext, playerIdentifier)
+	if errorFromPlayer != nil {
+		return errorFromPlayer
+	}
+
+	return stateCollection.statePersister.UpdateProfile(
+		executionContext,
+		playerIdentifier,
+		playerProfile)
+}
+
+// Contacts returns a copy of the friends and groups of the given player.
+func (stateCollection *StateCollection) Contacts(
+	executionContext context.Context,
+	playerIdentifier string) (Contacts, error) {
+	playerState, errorFromGet :=
+		stateCollection.statePersister.Get(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return Contacts{}, errorFromGet
+	}
+
+	return playerState.Contacts().DeepCopy(), nil
+}
+
+// Friends returns the states of the friends of the given player, in the order in which
+// they were added. Friends who have been deleted since they were added are left out,
+// whether they left tombstones or were removed completely, as there is no need to
+// remove them from the contacts of every other player when they are deleted.
+func (stateCollection *StateCollection) Friends(
+	executionContext context.Context,
+	playerIdentifier string) ([]ReadonlyState, error) {
+	playerContacts, errorFromContacts :=
+		stateCollection.Contacts(executionContext, playerIdentifier)
+	if errorFromContacts != nil {
+		return nil, errorFromContacts
+	}
+
+	friendStates := make([]ReadonlyState, 0, len(playerContacts.FriendIdentifiers))
+
+	for _, friendIdentifier := range playerContacts.FriendIdentifiers {
+		friendState, errorFromGet :=
+			stateCollection.statePersister.Get(executionContext, friendIdentifier)
+		if (errorFromGet != nil) || (friendState.Role() == RoleDeleted) {
+			continue
+		}
+
+		friendStates = append(friendStates, friendState)
+	}
+
+	return friendStates, nil
+}
+
+// AddFriend adds the given friend to the friends of the given player, unless the
+// friend is already among them. Friendship is one-way, so the friend does not have to
+// agree, in the same way that anyone can be invited to a game.
+func (stateCollection *StateCollection) AddFriend(
+	executionContext context.Context,
+	playerIdentifier string,
+	friendIdentifier string) error {
+	if friendIdentifier == playerIdentifier {
+		return fmt.Errorf("Player %v cannot be their own friend", playerIdentifier)
+	}
+
+	errorFromFriend :=
+		stateCollection.errorUnlessActivePlayer(executionContext, friendIdentifier)
+	if errorFromFriend != nil {
+		return errorFromFriend
+	}
+
+	playerContacts, errorFromContacts :=
+		stateCollection.Contacts(executionContext, playerIdentifier)
+	if errorFromContacts != nil {
+		return errorFromContacts
+	}
+
+	if playerContacts.IsFriend(friendIdentifier) {
+		return nil
+	}
+
+	playerContacts.FriendIdentifiers =
+		append(playerContacts.FriendIdentifiers, friendIdentifier)
+
+	return stateCollection.statePersister.UpdateContacts(
+		executionContext,
+		playerIdentifier,
+		playerContacts)
+}
+
+// RemoveFriend removes the given friend from the friends of the given player. It does
+// not remove the friend from any groups of the player.
+func (stateCollection *StateCollection) RemoveFriend(
+	executionContext context.Context,
+	playerIdentifier string,
+	friendIdentifier string) error {
+	playerContacts, errorFromContacts :=
+		stateCollection.Contacts(executionContext, playerIdentifier)
+	if errorFromContacts != nil {
+		return errorFromContacts
+	}
+
+	if !playerContacts.IsFriend(friendIdentifier) {
+		return fmt.Errorf(
+			"Player %v is not a friend of player %v",
+			friendIdentifier,
+			playerIdentifier)
+	}
+
+	remainingFriends := make([]string, 0, len(playerContacts.FriendIdentifiers)-1)
+	for _, existingFriend := range playerContacts.FriendIdentifiers {
+		if existingFriend != friendIdentifier {
+			remainingFriends = append(remainingFriends, existingFriend)
+		}
+	}
+
+	playerContacts.FriendIdentifiers = remainingFriends
+
+	return stateCollection.statePersister.UpdateContacts(
+		executionContext,
+		playerIdentifier,
+		playerContacts)
+}
+
+// SaveGroup checks that the group has a name and at least one member, that every
+// member is a registered player who has not been deleted, and that the owner is not
+// among the members, as the owner is always the host of a game created from the group.
+// It then replaces the group of the given player with the same name, or adds a new
+// group if there is none with that name. The members do not have to be friends.
+func (stateCollection *StateCollection) SaveGroup(
+	executionContext context.Context,
+	playerIdentifier string,
+	groupName string,
+	memberIdentifiers []string) error {
+	if groupName == "" {
+		return fmt.Errorf("Group must have a name")
+	}
+
+	if len(memberIdentifiers) == 0 {
+		return fmt.Errorf("Group %v must have at least one member", groupName)
+	}
+
+	existingMembers := make(map[string]bool, len(memberIdentifiers))
+	for _, memberIdentifier := range memberIdentifiers {
+		if memberIdentifier == playerIdentifier {
+			return fmt.Errorf(
+				"Group %v cannot include its owner %v, who is always the host",
+				groupName,
+				playerIdentifier)
+		}
+
+		if existingMembers[memberIdentifier] {
+			return fmt.Errorf(
+				"Group %v includes player %v more than once",
+				groupName,
+				memberIdentifier)
+		}
+
+		existingMembers[memberIdentifier] = true
+
+		errorFromMember :=
+			stateCollection.errorUnlessActivePlayer(executionContext, memberIdentifier)
+		if errorFromMember != nil {
+			return errorFromMember
+		}
+	}
+
+	playerContacts, errorFromContacts :=
+		stateCollection.Contacts(executionContext, playerIdentifier)
+	if errorFromContacts != nil {
+		return errorFromContacts
+	}
+
+	savedGroup :=
+		Group{
+			Name:              groupName,
+			MemberIdentifiers: copyIdentifiers(memberIdentifiers),
+		}
+
+	isReplacement := false
+	for groupIndex, existingGroup := range playerContacts.Groups {
+		if existingGroup.Name == groupName {
+			playerContacts.Groups[groupIndex] = savedGroup
+			isReplacement = true
+		}
+	}
+
+	if !isReplacement {
+		playerContacts.Groups = append(playerContacts.Groups, savedGroup)
+	}
+
+	return stateCollection.statePersister.UpdateContacts(
+		executionContext,
+		playerIdentifier,
+		playerContacts)
+}
+
+// DeleteGroup removes the group with the given name from the groups of the given
+// player.
+func (stateCollection *StateCollection) DeleteGroup(
+	executionContext context.Context,
+	playerIdentifier string,
+	groupName string) error {
+	playerContacts, errorFromContacts :=
+		stateCollection.Contacts(executionContext, playerIdentifier)
+	if errorFromContacts != nil {
+		return errorFromContacts
+	}
+
+	_, hasGroup := playerContacts.GroupWithName(groupName)
+	if !hasGroup {
+		return fmt.Errorf("Player %v has no group %v", playerIdentifier, groupName)
+	}
+
+	remainingGroups := make([]Group, 0, len(playerContacts.Groups)-1)
+	for _, existingGroup := range playerContacts.Groups {
+		if existingGroup.Name != groupName {
+			remainingGroups = append(remainingGroups, existingGroup)
+		}
+	}
+
+	playerContacts.Groups = remainingGroups
+
+	return stateCollection.statePersister.UpdateContacts(
+		executionContext,
+		playerIdentifier,
+		playerContacts)
+}
+
+// RecordHeartbeat records that the given player is present now, with the given game
+// open, which may be empty, and whether the player is inactive. Heartbeats are only
+// kept in memory, so the context is ignored.
+func (stateCollection *StateCollection) RecordHeartbeat(
+	executionContext context.Context,
+	playerIdentifier string,
+	viewedGameName string,
+	isIdle bool) {
+	stateCollection.presenceTracker.RecordHeartbeat(
+		playerIdentifier,
+		viewedGameName,
+		isIdle,
+		time.Now())
 }
 
-// Delete calls the Delete of the internal persistence store.
+// Presence returns whether the given player is online, idle, or offline now, and which
+// game the player has open. The context is ignored.
+func (stateCollection *StateCollection) Presence(
+	executionContext context.Context,
+	playerIdentifier string) Presence {
+	return stateCollection.presenceTracker.Presence(playerIdentifier, time.Now())
+}
+
+// IsAdministrator returns true if the given player is configured to be an
+// administrator or has the administrator role in the internal persistence store.
+func (stateCollection *StateCollection) IsAdministrator(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	if stateCollection.administratorIdentifiers[playerIdentifier] {
+		return true, nil
+	}
+
+	playerState, errorFromGet :=
+		stateCollection.statePersister.Get(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return false, errorFromGet
+	}
+
+	return playerState.Role() == RoleAdministrator, nil
+}
+
+// IsDeleted returns true if the given player has been replaced by a tombstone or has
+// been removed from the internal persistence store entirely, so that session tokens
+// issued to the player before the deletion are no longer accepted.
+func (stateCollection *StateCollection) IsDeleted(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	playerState, errorFromGet :=
+		stateCollection.statePersister.Get(executionContext, playerIdentifier)
+	if errors.Is(errorFromGet, ErrUnknownPlayer) {
+		return true, nil
+	}
+
+	if errorFromGet != nil {
+		return false, errorFromGet
+	}
+
+	return playerState.Role() == RoleDeleted, nil
+}
+
+// Delete either replaces the given player with a tombstone or calls the Delete of
+// the internal persistence store if the player is not a participant in any game,
+// according to the deletion policy of the collection.
 func (stateCollection *StateCollection) Delete(
 	executionContext context.Context,
-	playerName string) error {
+	playerIdentifier string) error {
+	if stateCollection.deletionPolicy == DeletionLeavesTombstone {
+		return stateCollection.statePersister.UpdateRole(
+			executionContext,
+			playerIdentifier,
+			RoleDeleted)
+	}
+
+	if stateCollection.participationChecker == nil {
+		return fmt.Errorf(
+			"Cannot check whether player %v is a participant in any game",
+			playerIdentifier)
+	}
+
+	isParticipant, errorFromCheck :=
+		stateCollection.participationChecker.IsParticipantInAnyGame(
+			executionContext,
+			playerIdentifier)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	if isParticipant {
+		return ErrPlayerInGame.Errorf(
+			"Player %v cannot be deleted while a participant in any game",
+			playerIdentifier)
+	}
+
 	return stateCollection.statePersister.Delete(
 		executionContext,
-		playerName)
+		playerIdentifier)
+}
+
+// validChatColor returns the given color if it is in the suggested palette, or the
+// normalized form of the given color if it is of the form #RRGGBB and has enough
+// contrast against the background of the chat, and otherwise returns an error. The
+// colors of the palette are always accepted, as players may already have them.
+func (stateCollection *StateCollection) validChatColor(chatColor string) (string, error) {
+	if stateCollection.chatColorMap[chatColor] {
+		return chatColor, nil
+	}
+
+	if !IsHexColor(chatColor) {
+		return "", fmt.Errorf(
+			"Chat color %v is neither of the form #RRGGBB nor in list of suggested colors %v",
+			chatColor,
+			stateCollection.chatColorSlice)
+	}
+
+	contrastRatio, errorFromContrast :=
+		ContrastRatio(chatColor, stateCollection.chatBackgroundColor)
+	if errorFromContrast != nil {
+		return "", errorFromContrast
+	}
+
+	if contrastRatio < MinimumContrastRatio {
+		return "", fmt.Errorf(
+			"Chat color %v has contrast ratio %.2f against background %v, must be at least %v",
+			chatColor,
+			contrastRatio,
+			stateCollection.chatBackgroundColor,
+			MinimumContrastRatio)
+	}
+
+	return NormalizeHexColor(chatColor), nil
+}
+
+// errorIfNearDuplicateOfFellowParticipant returns an error if the given color is
+// nearly the same as the color of any player who shares a game with the given player.
+// It does nothing if there is no participation checker.
+func (stateCollection *StateCollection) errorIfNearDuplicateOfFellowParticipant(
+	executionContext context.Context,
+	playerIdentifier string,
+	chatColor string) error {
+	fellowStates, errorFromFellows :=
+		stateCollection.fellowParticipantStates(executionContext, playerIdentifier)
+	if errorFromFellows != nil {
+		return errorFromFellows
+	}
+
+	for _, fellowState := range fellowStates {
+		if AreNearDuplicates(chatColor, fellowState.Color()) {
+			return fmt.Errorf(
+				"Chat color %v is too similar to color %v of %v, who shares a game with the player",
+				chatColor,
+				fellowState.Color(),
+				fellowState.Name())
+		}
+	}
+
+	return nil
+}
+
+// paletteColorApartFrom returns the first of the suggested colors which is not nearly
+// the same as any of the given colors of the players who share a game with the given
+// player, or an error if there is no such color.
+func (stateCollection *StateCollection) paletteColorApartFrom(
+	playerIdentifier string,
+	otherColors []string) (string, error) {
+	for _, paletteColor := range stateCollection.chatColorSlice {
+		if !isNearDuplicateOfAny(paletteColor, otherColors) {
+			return paletteColor, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"No suggested chat color can be told apart from the colors %v of the players"+
+			" who would share a game with player %v",
+		otherColors,
+		playerIdentifier)
+}
+
+// fellowParticipantStates returns the states of the players who share a game with the
+// given player, or nothing if there is no participation checker.
+func (stateCollection *StateCollection) fellowParticipantStates(
+	executionContext context.Context,
+	playerIdentifier string) ([]ReadonlyState, error) {
+	if stateCollection.participationChecker == nil {
+		return nil, nil
+	}
+
+	fellowIdentifiers, errorFromFellows :=
+		stateCollection.participationChecker.FellowParticipants(
+			executionContext,
+			playerIdentifier)
+	if errorFromFellows != nil {
+		return nil, errorFromFellows
+	}
+
+	fellowStates := make([]ReadonlyState, 0, len(fellowIdentifiers))
+	for _, fellowIdentifier := range fellowIdentifiers {
+		fellowState, errorFromGet :=
+			stateCollection.statePersister.Get(executionContext, fellowIdentifier)
+
+		if errorFromGet != nil {
+			return nil, errorFromGet
+		}
+
+		fellowStates = append(fellowStates, fellowState)
+	}
+
+	return fellowStates, nil
+}
+
+// errorUnlessActivePlayer returns an error if the given player is not registered or
+// has been deleted and left a tombstone.
+func (stateCollection *StateCollection) errorUnlessActivePlayer(
+	executionContext context.Context,
+	playerIdentifier string) error {
+	playerState, errorFromGet :=
+		stateCollection.statePersister.Get(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		return errorFromGet
+	}
+
+	if playerState.Role() == RoleDeleted {
+		return ErrDeletedPlayer.Errorf("Player %v has been deleted", playerIdentifier)
+	}
+
+	return nil
+}
+
+// defaultColorFor returns one of the suggested colors chosen by the characters of the
+// given identifier, which are random for new players.
+func (stateCollection *StateCollection) defaultColorFor(playerIdentifier string) string {
+	identifierSum := 0
+	for _, identifierByte := range []byte(playerIdentifier) {
+		identifierSum += int(identifierByte)
+	}
+
+	return stateCollection.chatColorSlice[identifierSum%stateCollection.numberOfColors]
+}
+
+// cursorFromName encodes the given name as a cursor for the page of search results
+// which starts after the player with the name. The encoding keeps the cursor opaque to
+// the frontend and safe to put in a URI.
+func cursorFromName(playerName string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(playerName))
+}
+
+// nameFromCursor decodes the name encoded in the given cursor by cursorFromName, or
+// returns an empty string for an empty cursor.
+func nameFromCursor(pageCursor string) (string, error) {
+	decodedBytes, errorFromDecoding := base64.RawURLEncoding.DecodeString(pageCursor)
+	if errorFromDecoding != nil {
+		return "", fmt.Errorf("Cursor %v is not valid", pageCursor)
+	}
+
+	return string(decodedBytes), nil
+}
+
+// newPlayerIdentifier returns a random string of hexadecimal digits to identify a new
+// player.
+func newPlayerIdentifier() (string, error) {
+	randomBytes := make([]byte, identifierLength)
+	_, errorFromRandom := rand.Read(randomBytes)
+
+	if errorFromRandom != nil {
+		return "", fmt.Errorf("Could not generate identifier for player: %v", errorFromRandom)
+	}
+
+	return hex.EncodeToString(randomBytes), nil
 }
@@ -2,15 +2,21 @@ package player_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/benoleary/ilutulestikud/backend/defaults"
 	"github.com/benoleary/ilutulestikud/backend/player"
+	player_persister "github.com/benoleary/ilutulestikud/backend/player/persister"
 )
 
 var colorsAvailableInTest []string = defaults.AvailableColors()
 var defaultTestPlayerNames []string = []string{"Player One", "Player Two", "Player Three", "Player Four"}
+var testPassword string = "test password"
+var testAdministratorIdentifier string = "test administrator identifier"
+var testBackgroundColor string = defaults.ChatBackgroundColor()
 
 func mapStringsToTrue(stringsToMap []string) map[string]bool {
 	stringMap := make(map[string]bool, 0)
@@ -22,32 +28,60 @@ func mapStringsToTrue(stringsToMap []string) map[string]bool {
 }
 
 type mockPersister struct {
-	testReference           *testing.T
-	ReturnForAll            []player.ReadonlyState
-	ReturnForGet            player.ReadonlyState
-	ReturnForAdd            error
-	ReturnForNontestError   error
-	TestErrorForAll         error
-	TestErrorForGet         error
-	TestErrorForAdd         error
-	TestErrorForUpdateColor error
-	TestErrorForDelete      error
-	ArgumentsForAdd         []player.ReadAndWriteState
+	testReference              *testing.T
+	ReturnForAll               []player.ReadonlyState
+	ReturnsForSearch           [][]player.ReadonlyState
+	ReturnForGet               player.ReadonlyState
+	ReturnsForGetByIdentifier  map[string]player.ReadonlyState
+	ReturnForAdd               error
+	ReturnForPasswordHash      string
+	ReturnForIdentifier        string
+	ReturnForNontestError      error
+	TestErrorForAll            error
+	TestErrorForSearch         error
+	TestErrorForGet            error
+	TestErrorForAdd            error
+	TestErrorForUpdateName     error
+	TestErrorForUpdateColor    error
+	TestErrorForUpdateRole     error
+	TestErrorForUpdateProfile  error
+	TestErrorForUpdateContacts error
+	TestErrorForDelete         error
+	ArgumentsForSearch         []player.SearchCriteria
+	ArgumentsForAdd            []player.ReadAndWriteState
+	ArgumentsForUpdateColor    []player.ReadAndWriteState
+	ArgumentsForUpdateRole     []player.ReadAndWriteState
+	ArgumentsForUpdateProfile  []player.ReadAndWriteState
+	ArgumentsForUpdateContacts []player.ReadAndWriteState
+	ArgumentsForDelete         []string
 }
 
 func NewMockPersister(testReference *testing.T, testError error) *mockPersister {
 	return &mockPersister{
-		testReference:           testReference,
-		ReturnForAll:            nil,
-		ReturnForGet:            nil,
-		ReturnForAdd:            nil,
-		ReturnForNontestError:   nil,
-		TestErrorForAll:         testError,
-		TestErrorForGet:         testError,
-		TestErrorForAdd:         testError,
-		TestErrorForUpdateColor: testError,
-		TestErrorForDelete:      testError,
-		ArgumentsForAdd:         make([]player.ReadAndWriteState, 0),
+		testReference:              testReference,
+		ReturnForAll:               nil,
+		ReturnForGet:               nil,
+		ReturnForAdd:               nil,
+		ReturnForPasswordHash:      "",
+		ReturnForIdentifier:        "",
+		ReturnForNontestError:      nil,
+		TestErrorForAll:            testError,
+		TestErrorForSearch:         testError,
+		TestErrorForGet:            testError,
+		TestErrorForAdd:            testError,
+		TestErrorForUpdateName:     testError,
+		TestErrorForUpdateColor:    testError,
+		TestErrorForUpdateRole:     testError,
+		TestErrorForUpdateProfile:  testError,
+		TestErrorForUpdateContacts: testError,
+		TestErrorForDelete:         testError,
+		ArgumentsForSearch:         make([]player.SearchCriteria, 0),
+		ArgumentsForAdd:            make([]player.ReadAndWriteState, 0),
+		ArgumentsForUpdateColor:    make([]player.ReadAndWriteState, 0),
+		ArgumentsForUpdateRole:     make([]player.ReadAndWriteState, 0),
+		ArgumentsForUpdateProfile:  make([]player.ReadAndWriteState, 0),
+		ArgumentsForUpdateContacts: make([]player.ReadAndWriteState, 0),
+		ArgumentsForDelete:         make([]string, 0),
 	}
 }
 
@@ -62,26 +96,72 @@ func (mockImplementation *mockPersister) All(
 	return mockImplementation.ReturnForAll, mockImplementation.ReturnForNontestError
 }
 
+// Search returns the first of the remaining slices of ReturnsForSearch, or an empty
+// slice if there are none left.
+func (mockImplementation *mockPersister) Search(
+	executionContext context.Context,
+	searchCriteria player.SearchCriteria) ([]player.ReadonlyState, error) {
+	if mockImplementation.TestErrorForSearch != nil {
+		mockImplementation.testReference.Errorf(
+			"Search(%+v): %v",
+			searchCriteria,
+			mockImplementation.TestErrorForSearch)
+	}
+
+	mockImplementation.ArgumentsForSearch =
+		append(mockImplementation.ArgumentsForSearch, searchCriteria)
+
+	if len(mockImplementation.ReturnsForSearch) == 0 {
+		return []player.ReadonlyState{}, mockImplementation.ReturnForNontestError
+	}
+
+	returnForSearch := mockImplementation.ReturnsForSearch[0]
+	mockImplementation.ReturnsForSearch = mockImplementation.ReturnsForSearch[1:]
+
+	return returnForSearch, mockImplementation.ReturnForNontestError
+}
+
 func (mockImplementation *mockPersister) Get(
 	executionContext context.Context,
-	playerName string) (player.ReadonlyState, error) {
+	playerIdentifier string) (player.ReadonlyState, error) {
 	if mockImplementation.TestErrorForGet != nil {
 		mockImplementation.testReference.Errorf(
 			"Get(%v): %v",
-			playerName,
+			playerIdentifier,
 			mockImplementation.TestErrorForGet)
 	}
 
+	// If states are given by identifier, players without a state are not registered.
+	if mockImplementation.ReturnsForGetByIdentifier != nil {
+		stateForIdentifier, isRegistered :=
+			mockImplementation.ReturnsForGetByIdentifier[playerIdentifier]
+		if !isRegistered {
+			return nil, fmt.Errorf("No player with identifier %v is registered", playerIdentifier)
+		}
+
+		return stateForIdentifier, mockImplementation.ReturnForNontestError
+	}
+
 	return mockImplementation.ReturnForGet, mockImplementation.ReturnForNontestError
 }
 
+func (mockImplementation *mockPersister) IdentifierForName(
+	executionContext context.Context,
+	playerName string) (string, error) {
+	return mockImplementation.ReturnForIdentifier, mockImplementation.ReturnForNontestError
+}
+
 func (mockImplementation *mockPersister) Add(
 	executionContext context.Context,
+	playerIdentifier string,
 	playerName string,
-	chatColor string) error {
+	chatColor string,
+	playerRole string,
+	passwordHash string) error {
 	if mockImplementation.TestErrorForAdd != nil {
 		mockImplementation.testReference.Errorf(
-			"Add(%v, %v): %v",
+			"Add(%v, %v, %v): %v",
+			playerIdentifier,
 			playerName,
 			chatColor,
 			mockImplementation.TestErrorForAdd)
@@ -89,8 +169,11 @@ func (mockImplementation *mockPersister) Add(
 
 	argumentAsPlayer :=
 		player.ReadAndWriteState{
-			PlayerName: playerName,
-			ChatColor:  chatColor,
+			PlayerIdentifier: playerIdentifier,
+			PlayerName:       playerName,
+			ChatColor:        chatColor,
+			PlayerRole:       playerRole,
+			PasswordHash:     passwordHash,
 		}
 
 	mockImplementation.ArgumentsForAdd =
@@ -99,34 +182,157 @@ func (mockImplementation *mockPersister) Add(
 	return mockImplementation.ReturnForAdd
 }
 
+func (mockImplementation *mockPersister) PasswordHash(
+	executionContext context.Context,
+	playerIdentifier string) (string, error) {
+	return mockImplementation.ReturnForPasswordHash, mockImplementation.ReturnForNontestError
+}
+
+func (mockImplementation *mockPersister) UpdateName(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) error {
+	if mockImplementation.TestErrorForUpdateName != nil {
+		mockImplementation.testReference.Errorf(
+			"UpdateName(%v, %v): %v",
+			playerIdentifier,
+			playerName,
+			mockImplementation.TestErrorForUpdateName)
+	}
+
+	return mockImplementation.ReturnForNontestError
+}
+
 func (mockImplementation *mockPersister) UpdateColor(
 	executionContext context.Context,
-	playerName string,
+	playerIdentifier string,
 	chatColor string) error {
 	if mockImplementation.TestErrorForUpdateColor != nil {
 		mockImplementation.testReference.Errorf(
 			"UpdateColor(%v, %v): %v",
-			playerName,
+			playerIdentifier,
 			chatColor,
 			mockImplementation.TestErrorForUpdateColor)
 	}
 
+	argumentAsPlayer :=
+		player.ReadAndWriteState{
+			PlayerIdentifier: playerIdentifier,
+			ChatColor:        chatColor,
+		}
+
+	mockImplementation.ArgumentsForUpdateColor =
+		append(mockImplementation.ArgumentsForUpdateColor, argumentAsPlayer)
+
+	return mockImplementation.ReturnForNontestError
+}
+
+func (mockImplementation *mockPersister) UpdateRole(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerRole string) error {
+	if mockImplementation.TestErrorForUpdateRole != nil {
+		mockImplementation.testReference.Errorf(
+			"UpdateRole(%v, %v): %v",
+			playerIdentifier,
+			playerRole,
+			mockImplementation.TestErrorForUpdateRole)
+	}
+
+	argumentAsPlayer :=
+		player.ReadAndWriteState{
+			PlayerIdentifier: playerIdentifier,
+			PlayerRole:       playerRole,
+		}
+
+	mockImplementation.ArgumentsForUpdateRole =
+		append(mockImplementation.ArgumentsForUpdateRole, argumentAsPlayer)
+
+	return mockImplementation.ReturnForNontestError
+}
+
+func (mockImplementation *mockPersister) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile player.Profile) error {
+	if mockImplementation.TestErrorForUpdateProfile != nil {
+		mockImplementation.testReference.Errorf(
+			"UpdateProfile(%v, %+v): %v",
+			playerIdentifier,
+			playerProfile,
+			mockImplementation.TestErrorForUpdateProfile)
+	}
+
+	argumentAsPlayer :=
+		player.ReadAndWriteState{
+			PlayerIdentifier: playerIdentifier,
+			PlayerProfile:    playerProfile,
+		}
+
+	mockImplementation.ArgumentsForUpdateProfile =
+		append(mockImplementation.ArgumentsForUpdateProfile, argumentAsPlayer)
+
+	return mockImplementation.ReturnForNontestError
+}
+
+func (mockImplementation *mockPersister) UpdateContacts(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerContacts player.Contacts) error {
+	if mockImplementation.TestErrorForUpdateContacts != nil {
+		mockImplementation.testReference.Errorf(
+			"UpdateContacts(%v, %+v): %v",
+			playerIdentifier,
+			playerContacts,
+			mockImplementation.TestErrorForUpdateContacts)
+	}
+
+	argumentAsPlayer :=
+		player.ReadAndWriteState{
+			PlayerIdentifier: playerIdentifier,
+			PlayerContacts:   playerContacts,
+		}
+
+	mockImplementation.ArgumentsForUpdateContacts =
+		append(mockImplementation.ArgumentsForUpdateContacts, argumentAsPlayer)
+
 	return mockImplementation.ReturnForNontestError
 }
 
 func (mockImplementation *mockPersister) Delete(
 	executionContext context.Context,
-	playerName string) error {
+	playerIdentifier string) error {
 	if mockImplementation.TestErrorForDelete != nil {
 		mockImplementation.testReference.Errorf(
 			"Delete(%v): %v",
-			playerName,
+			playerIdentifier,
 			mockImplementation.TestErrorForDelete)
 	}
 
+	mockImplementation.ArgumentsForDelete =
+		append(mockImplementation.ArgumentsForDelete, playerIdentifier)
+
 	return mockImplementation.ReturnForNontestError
 }
 
+type mockParticipationChecker struct {
+	ReturnForIsParticipantInAnyGame bool
+	ReturnForFellowParticipants     []string
+	ErrorToReturn                   error
+}
+
+func (mockChecker *mockParticipationChecker) IsParticipantInAnyGame(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	return mockChecker.ReturnForIsParticipantInAnyGame, mockChecker.ErrorToReturn
+}
+
+func (mockChecker *mockParticipationChecker) FellowParticipants(
+	executionContext context.Context,
+	playerIdentifier string) ([]string, error) {
+	return mockChecker.ReturnForFellowParticipants, mockChecker.ErrorToReturn
+}
+
 func prepareCollection(
 	unitTest *testing.T,
 	initialPlayerNames []string,
@@ -163,15 +369,21 @@ func prepareCollectionWithoutAdjustingMock(
 	availableColors []string,
 	mockImplementation *mockPersister) (*player.StateCollection, map[string]bool) {
 	stateCollection :=
-		player.NewCollection(mockImplementation, colorsAvailableInTest)
+		player.NewCollection(
+			mockImplementation,
+			colorsAvailableInTest,
+			testBackgroundColor,
+			[]string{testAdministratorIdentifier},
+			player.DeletionBlockedWhileInGames,
+			&mockParticipationChecker{})
 
 	numberOfColors := len(availableColors)
 
 	for playerCount, initialPlayerName := range initialPlayerNames {
 		colorToAdd :=
 			availableColors[playerCount%numberOfColors]
-		errorFromAdd :=
-			stateCollection.Add(context.Background(), initialPlayerName, colorToAdd)
+		_, errorFromAdd :=
+			stateCollection.Add(context.Background(), initialPlayerName, colorToAdd, testPassword)
 
 		if errorFromAdd != nil {
 			unitTest.Fatalf(
@@ -395,8 +607,8 @@ func TestRejectAddWithEmptyPlayerName(unitTest *testing.T) {
 			colorsAvailableInTest,
 			mockImplementation)
 
-	actualError :=
-		stateCollection.Add(context.Background(), "", colorsAvailableInTest[0])
+	_, actualError :=
+		stateCollection.Add(context.Background(), "", colorsAvailableInTest[0], testPassword)
 
 	if actualError == nil {
 		unitTest.Fatalf(
@@ -405,6 +617,156 @@ func TestRejectAddWithEmptyPlayerName(unitTest *testing.T) {
 	}
 }
 
+func TestRejectAddWithEmptyPassword(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("No functions should be called"))
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	_, actualError :=
+		stateCollection.Add(context.Background(), "Mock Player", colorsAvailableInTest[0], "")
+
+	if actualError == nil {
+		unitTest.Fatalf("No error from Add(player name, chat color, empty password)")
+	}
+}
+
+func TestAddStoresHashRatherThanPassword(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("Only Add(...) and All() should be called"))
+	mockImplementation.TestErrorForAdd = nil
+	mockImplementation.TestErrorForAll = nil
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	_, errorFromAdd :=
+		stateCollection.Add(context.Background(), "Mock Player", "", testPassword)
+
+	if errorFromAdd != nil {
+		unitTest.Fatalf("Add(...) produced unexpected error %v", errorFromAdd)
+	}
+
+	if len(mockImplementation.ArgumentsForAdd) != 1 {
+		unitTest.Fatalf(
+			"Add(...) did not call the persister's add once, but with %v",
+			mockImplementation.ArgumentsForAdd)
+	}
+
+	storedHash := mockImplementation.ArgumentsForAdd[0].PasswordHash
+	if (storedHash == testPassword) || !player.IsPasswordCorrect(testPassword, storedHash) {
+		unitTest.Fatalf(
+			"Add(...) stored %v which is not a valid hash of the password %v",
+			storedHash,
+			testPassword)
+	}
+}
+
+func TestAuthenticate(unitTest *testing.T) {
+	validHash, errorFromHash := player.HashPassword(testPassword)
+	if errorFromHash != nil {
+		unitTest.Fatalf("HashPassword(...) produced unexpected error %v", errorFromHash)
+	}
+
+	testCases := []struct {
+		testName             string
+		storedHash           string
+		storedRole           string
+		errorFromPersister   error
+		givenPassword        string
+		shouldProduceAnError bool
+	}{
+		{
+			testName:             "correct password",
+			storedHash:           validHash,
+			errorFromPersister:   nil,
+			givenPassword:        testPassword,
+			shouldProduceAnError: false,
+		},
+		{
+			testName:             "deleted player with correct password",
+			storedHash:           validHash,
+			storedRole:           player.RoleDeleted,
+			errorFromPersister:   nil,
+			givenPassword:        testPassword,
+			shouldProduceAnError: true,
+		},
+		{
+			testName:             "incorrect password",
+			storedHash:           validHash,
+			errorFromPersister:   nil,
+			givenPassword:        testPassword + " but wrong",
+			shouldProduceAnError: true,
+		},
+		{
+			testName:             "player without password",
+			storedHash:           "",
+			errorFromPersister:   nil,
+			givenPassword:        "",
+			shouldProduceAnError: true,
+		},
+		{
+			testName:             "unknown player",
+			storedHash:           "",
+			errorFromPersister:   fmt.Errorf("expected error"),
+			givenPassword:        testPassword,
+			shouldProduceAnError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation := NewMockPersister(unitTest, nil)
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			mockImplementation.ReturnForPasswordHash = testCase.storedHash
+			mockImplementation.ReturnForGet =
+				&player.ReadAndWriteState{
+					PlayerIdentifier: "mock identifier",
+					PlayerName:       "Mock Player",
+					PlayerRole:       testCase.storedRole,
+				}
+			mockImplementation.ReturnForIdentifier = "mock identifier"
+			mockImplementation.ReturnForNontestError = testCase.errorFromPersister
+
+			actualIdentifier, actualError :=
+				stateCollection.Authenticate(
+					context.Background(),
+					"Mock Player",
+					testCase.givenPassword)
+
+			if (actualError != nil) != testCase.shouldProduceAnError {
+				unitTest.Fatalf(
+					"Authenticate(...) returned error %v - expected an error: %v",
+					actualError,
+					testCase.shouldProduceAnError)
+			}
+
+			if (actualError == nil) && (actualIdentifier != "mock identifier") {
+				unitTest.Fatalf(
+					"Authenticate(...) returned identifier %v - expected %v",
+					actualIdentifier,
+					"mock identifier")
+			}
+		})
+	}
+}
+
 func TestRejectAddWithInvalidColor(unitTest *testing.T) {
 	mockImplementation :=
 		NewMockPersister(unitTest, fmt.Errorf("No functions should be called"))
@@ -426,8 +788,8 @@ func TestRejectAddWithInvalidColor(unitTest *testing.T) {
 			validColors)
 	}
 
-	actualError :=
-		stateCollection.Add(context.Background(), playerName, invalidColor)
+	_, actualError :=
+		stateCollection.Add(context.Background(), playerName, invalidColor, testPassword)
 
 	if actualError == nil {
 		unitTest.Fatalf(
@@ -440,32 +802,22 @@ func TestRejectAddWithInvalidColor(unitTest *testing.T) {
 func TestReturnErrorFromPersisterAdd(unitTest *testing.T) {
 	testCases := []struct {
 		testName             string
-		expectedErrorFromAll error
 		expectedErrorFromAdd error
 	}{
-		{
-			testName:             "error from All()",
-			expectedErrorFromAll: fmt.Errorf("expected error from All()"),
-			expectedErrorFromAdd: nil,
-		},
 		{
 			testName:             "error from Add(...)",
-			expectedErrorFromAll: nil,
 			expectedErrorFromAdd: fmt.Errorf("expected error from Add(...)"),
 		},
 		{
 			testName:             "Nil error",
-			expectedErrorFromAll: nil,
 			expectedErrorFromAdd: nil,
 		},
 	}
 
 	for _, testCase := range testCases {
 		mockImplementation :=
-			NewMockPersister(unitTest, fmt.Errorf("Only Add(...) and All() should be called"))
+			NewMockPersister(unitTest, fmt.Errorf("Only Add(...) should be called"))
 		mockImplementation.TestErrorForAdd = nil
-		mockImplementation.TestErrorForAll = nil
-		mockImplementation.ReturnForNontestError = testCase.expectedErrorFromAll
 		mockImplementation.ReturnForAdd = testCase.expectedErrorFromAdd
 
 		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
@@ -482,21 +834,10 @@ func TestReturnErrorFromPersisterAdd(unitTest *testing.T) {
 			// from Add(...) if required.
 			chatColor := ""
 
-			actualError :=
-				stateCollection.Add(context.Background(), playerName, chatColor)
-
-			if (testCase.expectedErrorFromAll != nil) &&
-				(actualError != testCase.expectedErrorFromAll) {
-				unitTest.Errorf(
-					"Add(player name %v, chat color %v) returned error %v - expected %v",
-					playerName,
-					chatColor,
-					actualError,
-					testCase.expectedErrorFromAll)
-			}
+			_, actualError :=
+				stateCollection.Add(context.Background(), playerName, chatColor, testPassword)
 
-			if (testCase.expectedErrorFromAdd != nil) &&
-				(actualError != testCase.expectedErrorFromAdd) {
+			if actualError != testCase.expectedErrorFromAdd {
 				unitTest.Errorf(
 					"Add(player name %v, chat color %v) returned error %v - expected %v",
 					playerName,
@@ -510,9 +851,8 @@ func TestReturnErrorFromPersisterAdd(unitTest *testing.T) {
 
 func TestAddPlayerWithNoColorGetsValidColor(unitTest *testing.T) {
 	mockImplementation :=
-		NewMockPersister(unitTest, fmt.Errorf("Only Add(...) and All() should be called"))
+		NewMockPersister(unitTest, fmt.Errorf("Only Add(...) should be called"))
 	mockImplementation.TestErrorForAdd = nil
-	mockImplementation.TestErrorForAll = nil
 
 	stateCollection, validColors :=
 		prepareCollection(
@@ -523,7 +863,7 @@ func TestAddPlayerWithNoColorGetsValidColor(unitTest *testing.T) {
 
 	playerName := "Mock Player"
 
-	errorFromAdd := stateCollection.Add(context.Background(), playerName, "")
+	_, errorFromAdd := stateCollection.Add(context.Background(), playerName, "", testPassword)
 
 	if errorFromAdd != nil {
 		unitTest.Fatalf(
@@ -597,7 +937,9 @@ func TestReturnErrorFromPersisterUpdateColor(unitTest *testing.T) {
 
 	for _, testCase := range testCases {
 		mockImplementation :=
-			NewMockPersister(unitTest, fmt.Errorf("Only UpdateColor(...) should be called"))
+			NewMockPersister(unitTest, fmt.Errorf("Only Get(...) and UpdateColor(...) should be called"))
+		mockImplementation.TestErrorForGet = nil
+		mockImplementation.ReturnForGet = &player.ReadAndWriteState{PlayerRole: player.RolePlayer}
 		mockImplementation.TestErrorForUpdateColor = nil
 		mockImplementation.ReturnForNontestError = testCase.expectedError
 
@@ -834,3 +1176,1618 @@ func getStateAndAssertNoError(
 
 	return playerState
 }
+
+func TestAddGivesDistinctIdentifiersAndPlayerRole(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("Only Add(...) and All() should be called"))
+	mockImplementation.TestErrorForAdd = nil
+	mockImplementation.TestErrorForAll = nil
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	// Even a player who registers with the same name as the identifier of a configured
+	// administrator does not get the administrator role.
+	addedNames := []string{testAdministratorIdentifier, "Mock Player"}
+	returnedIdentifiers := make([]string, len(addedNames))
+
+	for addIndex, playerName := range addedNames {
+		playerIdentifier, errorFromAdd :=
+			stateCollection.Add(context.Background(), playerName, "", testPassword)
+
+		if errorFromAdd != nil {
+			unitTest.Fatalf("Add(%v, ...) produced unexpected error %v", playerName, errorFromAdd)
+		}
+
+		returnedIdentifiers[addIndex] = playerIdentifier
+	}
+
+	if returnedIdentifiers[0] == returnedIdentifiers[1] {
+		unitTest.Fatalf(
+			"Add(...) returned the same identifier %v for different players",
+			returnedIdentifiers[0])
+	}
+
+	for addIndex, returnedIdentifier := range returnedIdentifiers {
+		argumentsForAdd := mockImplementation.ArgumentsForAdd[addIndex]
+		if (returnedIdentifier == "") ||
+			(returnedIdentifier == argumentsForAdd.PlayerName) ||
+			(argumentsForAdd.PlayerIdentifier != returnedIdentifier) ||
+			(argumentsForAdd.PlayerRole != player.RolePlayer) {
+			unitTest.Fatalf(
+				"Add(%v, ...) returned identifier %v and stored %+v, expected a new"+
+					" identifier and role %v",
+				addedNames[addIndex],
+				returnedIdentifier,
+				argumentsForAdd,
+				player.RolePlayer)
+		}
+	}
+}
+
+func TestRename(unitTest *testing.T) {
+	testCases := []struct {
+		testName           string
+		newName            string
+		errorFromPersister error
+		expectedError      bool
+	}{
+		{
+			testName:           "empty name",
+			newName:            "",
+			errorFromPersister: nil,
+			expectedError:      true,
+		},
+		{
+			testName:           "error from persister",
+			newName:            "New Name",
+			errorFromPersister: fmt.Errorf("expected error"),
+			expectedError:      true,
+		},
+		{
+			testName:           "valid new name",
+			newName:            "New Name",
+			errorFromPersister: nil,
+			expectedError:      false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(unitTest, fmt.Errorf("Only Get(...) and UpdateName(...) should be called"))
+			mockImplementation.TestErrorForGet = nil
+			mockImplementation.ReturnForGet = &player.ReadAndWriteState{PlayerRole: player.RolePlayer}
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			if testCase.newName != "" {
+				mockImplementation.TestErrorForUpdateName = nil
+			}
+
+			mockImplementation.ReturnForNontestError = testCase.errorFromPersister
+
+			actualError :=
+				stateCollection.Rename(
+					context.Background(),
+					"mock identifier",
+					testCase.newName)
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"Rename(mock identifier, %v) returned error %v - expected an error: %v",
+					testCase.newName,
+					actualError,
+					testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestIsAdministrator(unitTest *testing.T) {
+	testCases := []struct {
+		testName           string
+		playerIdentifier   string
+		persistedRole      string
+		errorFromPersister error
+		expectedResult     bool
+		expectedError      bool
+	}{
+		{
+			testName:           "configured administrator without stored role",
+			playerIdentifier:   testAdministratorIdentifier,
+			persistedRole:      "",
+			errorFromPersister: nil,
+			expectedResult:     true,
+			expectedError:      false,
+		},
+		{
+			testName:           "stored administrator",
+			playerIdentifier:   "mock identifier",
+			persistedRole:      player.RoleAdministrator,
+			errorFromPersister: nil,
+			expectedResult:     true,
+			expectedError:      false,
+		},
+		{
+			testName:           "stored player",
+			playerIdentifier:   "mock identifier",
+			persistedRole:      player.RolePlayer,
+			errorFromPersister: nil,
+			expectedResult:     false,
+			expectedError:      false,
+		},
+		{
+			testName:           "player persisted before roles",
+			playerIdentifier:   "mock identifier",
+			persistedRole:      "",
+			errorFromPersister: nil,
+			expectedResult:     false,
+			expectedError:      false,
+		},
+		{
+			testName:           "unknown player",
+			playerIdentifier:   "mock identifier",
+			persistedRole:      "",
+			errorFromPersister: fmt.Errorf("expected error"),
+			expectedResult:     false,
+			expectedError:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation := NewMockPersister(unitTest, nil)
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			mockImplementation.ReturnForGet =
+				&player.ReadAndWriteState{
+					PlayerName: testCase.playerIdentifier,
+					PlayerRole: testCase.persistedRole,
+				}
+			mockImplementation.ReturnForNontestError = testCase.errorFromPersister
+
+			actualResult, actualError :=
+				stateCollection.IsAdministrator(context.Background(), testCase.playerIdentifier)
+
+			if (actualResult != testCase.expectedResult) ||
+				((actualError != nil) != testCase.expectedError) {
+				unitTest.Fatalf(
+					"IsAdministrator(%v) returned %v, %v - expected %v and error: %v",
+					testCase.playerIdentifier,
+					actualResult,
+					actualError,
+					testCase.expectedResult,
+					testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestRejectUpdateWithInvalidRole(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("No functions should be called"))
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	actualError :=
+		stateCollection.UpdateRole(context.Background(), "Mock Player", "superuser")
+
+	if actualError == nil {
+		unitTest.Fatalf("No error from UpdateRole(player name, invalid role)")
+	}
+}
+
+func TestAllLeavesOutTombstones(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("Only All() should be called"))
+	mockImplementation.TestErrorForAll = nil
+	mockImplementation.ReturnForAll = []player.ReadonlyState{
+		&player.ReadAndWriteState{
+			PlayerIdentifier: "first identifier",
+			PlayerName:       "Mock Player One",
+			PlayerRole:       player.RolePlayer,
+		},
+		&player.ReadAndWriteState{
+			PlayerIdentifier: "deleted identifier",
+			PlayerName:       "Deleted Player",
+			PlayerRole:       player.RoleDeleted,
+		},
+		&player.ReadAndWriteState{
+			PlayerIdentifier: "persisted before roles",
+			PlayerName:       "Mock Player Two",
+		},
+	}
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	actualReturnFromAll, errorFromAll :=
+		stateCollection.All(context.Background())
+
+	if (errorFromAll != nil) ||
+		(len(actualReturnFromAll) != 2) ||
+		(actualReturnFromAll[0].Identifier() != "first identifier") ||
+		(actualReturnFromAll[1].Identifier() != "persisted before roles") {
+		unitTest.Fatalf(
+			"All() returned %+v, %v - expected all but the deleted player from %+v",
+			actualReturnFromAll,
+			errorFromAll,
+			mockImplementation.ReturnForAll)
+	}
+}
+
+func TestRejectUpdatesToDeletedPlayer(unitTest *testing.T) {
+	executionContext := context.Background()
+	playerIdentifier := "deleted identifier"
+	playerPersister := player_persister.NewInMemory()
+
+	errorFromAdd :=
+		playerPersister.Add(
+			executionContext,
+			playerIdentifier,
+			"Deleted Player",
+			colorsAvailableInTest[0],
+			player.RolePlayer,
+			"irrelevant hash")
+	if errorFromAdd != nil {
+		unitTest.Fatalf("Add(%v, ...) produced unexpected error %v", playerIdentifier, errorFromAdd)
+	}
+
+	stateCollection :=
+		player.NewCollection(
+			playerPersister,
+			colorsAvailableInTest,
+			testBackgroundColor,
+			nil,
+			player.DeletionLeavesTombstone,
+			&mockParticipationChecker{})
+
+	errorFromDelete := stateCollection.Delete(executionContext, playerIdentifier)
+	if errorFromDelete != nil {
+		unitTest.Fatalf("Delete(%v) produced unexpected error %v", playerIdentifier, errorFromDelete)
+	}
+
+	testCases := []struct {
+		testName     string
+		updatePlayer func() error
+	}{
+		{
+			testName: "rename",
+			updatePlayer: func() error {
+				return stateCollection.Rename(executionContext, playerIdentifier, "New Name")
+			},
+		},
+		{
+			testName: "update color",
+			updatePlayer: func() error {
+				return stateCollection.UpdateColor(
+					executionContext,
+					playerIdentifier,
+					colorsAvailableInTest[1])
+			},
+		},
+		{
+			testName: "update role",
+			updatePlayer: func() error {
+				return stateCollection.UpdateRole(
+					executionContext,
+					playerIdentifier,
+					player.RoleAdministrator)
+			},
+		},
+		{
+			testName: "update profile",
+			updatePlayer: func() error {
+				return stateCollection.UpdateProfile(
+					executionContext,
+					playerIdentifier,
+					player.Profile{})
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			errorFromUpdate := testCase.updatePlayer()
+
+			if !errors.Is(errorFromUpdate, player.ErrDeletedPlayer) {
+				unitTest.Fatalf(
+					"%v of deleted player produced error %v - expected %v",
+					testCase.testName,
+					errorFromUpdate,
+					player.ErrDeletedPlayer)
+			}
+		})
+	}
+
+	tombstoneState, errorFromGet := playerPersister.Get(executionContext, playerIdentifier)
+	if errorFromGet != nil {
+		unitTest.Fatalf("Get(%v) produced unexpected error %v", playerIdentifier, errorFromGet)
+	}
+
+	if (tombstoneState.Role() != player.RoleDeleted) ||
+		(tombstoneState.Name() != "Deleted Player") ||
+		(tombstoneState.Color() != colorsAvailableInTest[0]) {
+		unitTest.Fatalf("Tombstone was changed by rejected updates to %+v", tombstoneState)
+	}
+
+	passwordHash, errorFromHash := playerPersister.PasswordHash(executionContext, playerIdentifier)
+	if (errorFromHash != nil) || (passwordHash != "") {
+		unitTest.Fatalf(
+			"PasswordHash(%v) of tombstone produced %v, %v - expected empty hash",
+			playerIdentifier,
+			passwordHash,
+			errorFromHash)
+	}
+}
+
+func TestDeleteAccordingToPolicy(unitTest *testing.T) {
+	playerIdentifier := "mock identifier"
+
+	testCases := []struct {
+		testName               string
+		deletionPolicy         player.DeletionPolicy
+		participationChecker   player.ParticipationChecker
+		expectedError          bool
+		expectedRoleUpdates    []player.ReadAndWriteState
+		expectedDeletedPlayers []string
+	}{
+		{
+			testName:             "tombstone without checker",
+			deletionPolicy:       player.DeletionLeavesTombstone,
+			participationChecker: nil,
+			expectedError:        false,
+			expectedRoleUpdates: []player.ReadAndWriteState{
+				player.ReadAndWriteState{
+					PlayerIdentifier: playerIdentifier,
+					PlayerRole:       player.RoleDeleted,
+				},
+			},
+			expectedDeletedPlayers: []string{},
+		},
+		{
+			testName:               "blocked without checker",
+			deletionPolicy:         player.DeletionBlockedWhileInGames,
+			participationChecker:   nil,
+			expectedError:          true,
+			expectedRoleUpdates:    []player.ReadAndWriteState{},
+			expectedDeletedPlayers: []string{},
+		},
+		{
+			testName:       "blocked with error from checker",
+			deletionPolicy: player.DeletionBlockedWhileInGames,
+			participationChecker: &mockParticipationChecker{
+				ReturnForIsParticipantInAnyGame: false,
+				ErrorToReturn:                   fmt.Errorf("expected error"),
+			},
+			expectedError:          true,
+			expectedRoleUpdates:    []player.ReadAndWriteState{},
+			expectedDeletedPlayers: []string{},
+		},
+		{
+			testName:       "blocked for participant",
+			deletionPolicy: player.DeletionBlockedWhileInGames,
+			participationChecker: &mockParticipationChecker{
+				ReturnForIsParticipantInAnyGame: true,
+				ErrorToReturn:                   nil,
+			},
+			expectedError:          true,
+			expectedRoleUpdates:    []player.ReadAndWriteState{},
+			expectedDeletedPlayers: []string{},
+		},
+		{
+			testName:       "not blocked for non-participant",
+			deletionPolicy: player.DeletionBlockedWhileInGames,
+			participationChecker: &mockParticipationChecker{
+				ReturnForIsParticipantInAnyGame: false,
+				ErrorToReturn:                   nil,
+			},
+			expectedError:          false,
+			expectedRoleUpdates:    []player.ReadAndWriteState{},
+			expectedDeletedPlayers: []string{playerIdentifier},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(
+					unitTest,
+					fmt.Errorf("Only UpdateRole(...) or Delete(...) should be called"))
+			mockImplementation.TestErrorForUpdateRole = nil
+			mockImplementation.TestErrorForDelete = nil
+
+			stateCollection :=
+				player.NewCollection(
+					mockImplementation,
+					colorsAvailableInTest,
+					testBackgroundColor,
+					nil,
+					testCase.deletionPolicy,
+					testCase.participationChecker)
+
+			actualError := stateCollection.Delete(context.Background(), playerIdentifier)
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"Delete(%v) returned error %v - expected an error: %v",
+					playerIdentifier,
+					actualError,
+					testCase.expectedError)
+			}
+
+			if !reflect.DeepEqual(
+				mockImplementation.ArgumentsForUpdateRole,
+				testCase.expectedRoleUpdates) ||
+				!reflect.DeepEqual(
+					mockImplementation.ArgumentsForDelete,
+					testCase.expectedDeletedPlayers) {
+				unitTest.Fatalf(
+					"Delete(%v) updated roles %+v and deleted %v - expected %+v and %v",
+					playerIdentifier,
+					mockImplementation.ArgumentsForUpdateRole,
+					mockImplementation.ArgumentsForDelete,
+					testCase.expectedRoleUpdates,
+					testCase.expectedDeletedPlayers)
+			}
+		})
+	}
+}
+
+func TestUpdateProfile(unitTest *testing.T) {
+	playerIdentifier := "mock identifier"
+
+	testCases := []struct {
+		testName      string
+		playerProfile player.Profile
+		expectedError bool
+	}{
+		{
+			testName:      "zero profile",
+			playerProfile: player.Profile{},
+			expectedError: false,
+		},
+		{
+			testName: "full profile",
+			playerProfile: player.Profile{
+				PreferredRulesets:    []int{2, 1},
+				PreferredHintDisplay: player.HintDisplaySymbols,
+				Timezone:             "UTC",
+				Notifications: player.NotificationPreferences{
+					OnTurn:        true,
+					OnInvitation:  true,
+					OnChatMessage: false,
+				},
+			},
+			expectedError: false,
+		},
+		{
+			testName: "invalid hint display",
+			playerProfile: player.Profile{
+				PreferredHintDisplay: "interpretive dance",
+			},
+			expectedError: true,
+		},
+		{
+			testName: "invalid timezone",
+			playerProfile: player.Profile{
+				Timezone: "Not/A_Real_Place",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(unitTest, fmt.Errorf("Only Get(...) and UpdateProfile(...) should be called"))
+			if !testCase.expectedError {
+				mockImplementation.TestErrorForGet = nil
+				mockImplementation.ReturnForGet = &player.ReadAndWriteState{PlayerRole: player.RolePlayer}
+				mockImplementation.TestErrorForUpdateProfile = nil
+			}
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			actualError :=
+				stateCollection.UpdateProfile(
+					context.Background(),
+					playerIdentifier,
+					testCase.playerProfile)
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"UpdateProfile(%v, %+v) returned error %v - expected an error: %v",
+					playerIdentifier,
+					testCase.playerProfile,
+					actualError,
+					testCase.expectedError)
+			}
+
+			if testCase.expectedError {
+				return
+			}
+
+			expectedArguments := []player.ReadAndWriteState{
+				player.ReadAndWriteState{
+					PlayerIdentifier: playerIdentifier,
+					PlayerProfile:    testCase.playerProfile,
+				},
+			}
+
+			if !reflect.DeepEqual(mockImplementation.ArgumentsForUpdateProfile, expectedArguments) {
+				unitTest.Fatalf(
+					"UpdateProfile(%v, %+v) passed %+v to persister",
+					playerIdentifier,
+					testCase.playerProfile,
+					mockImplementation.ArgumentsForUpdateProfile)
+			}
+		})
+	}
+}
+
+func TestAddWithChosenColor(unitTest *testing.T) {
+	testCases := []struct {
+		testName      string
+		chosenColor   string
+		expectedColor string
+		expectedError bool
+	}{
+		{
+			testName:      "suggested color with low contrast",
+			chosenColor:   "blue",
+			expectedColor: "blue",
+			expectedError: false,
+		},
+		{
+			testName:      "hex color with enough contrast",
+			chosenColor:   "#C0FFEE",
+			expectedColor: "#c0ffee",
+			expectedError: false,
+		},
+		{
+			testName:      "hex color with too little contrast",
+			chosenColor:   "#303030",
+			expectedColor: "",
+			expectedError: true,
+		},
+		{
+			testName:      "too few digits",
+			chosenColor:   "#FFF",
+			expectedColor: "",
+			expectedError: true,
+		},
+		{
+			testName:      "not hexadecimal",
+			chosenColor:   "#GGGGGG",
+			expectedColor: "",
+			expectedError: true,
+		},
+		{
+			testName:      "unknown name",
+			chosenColor:   "chartreuse",
+			expectedColor: "",
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(unitTest, fmt.Errorf("Only Add(...) should be called"))
+			if !testCase.expectedError {
+				mockImplementation.TestErrorForAdd = nil
+			}
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			_, actualError :=
+				stateCollection.Add(
+					context.Background(),
+					"Mock Player",
+					testCase.chosenColor,
+					testPassword)
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"Add(..., %v, ...) returned error %v - expected an error: %v",
+					testCase.chosenColor,
+					actualError,
+					testCase.expectedError)
+			}
+
+			if testCase.expectedError {
+				return
+			}
+
+			if (len(mockImplementation.ArgumentsForAdd) != 1) ||
+				(mockImplementation.ArgumentsForAdd[0].ChatColor != testCase.expectedColor) {
+				unitTest.Fatalf(
+					"Add(..., %v, ...) passed %+v to persister - expected color %v",
+					testCase.chosenColor,
+					mockImplementation.ArgumentsForAdd,
+					testCase.expectedColor)
+			}
+		})
+	}
+}
+
+func TestUpdateColorAvoidsNearDuplicatesOfFellowParticipants(unitTest *testing.T) {
+	playerIdentifier := "mock identifier"
+	fellowIdentifier := "fellow identifier"
+	fellowState :=
+		&player.ReadAndWriteState{
+			PlayerIdentifier: fellowIdentifier,
+			PlayerName:       "Fellow Player",
+			ChatColor:        "#ff0000",
+		}
+
+	testCases := []struct {
+		testName          string
+		chosenColor       string
+		fellowIdentifiers []string
+		errorFromChecker  error
+		expectedError     bool
+	}{
+		{
+			testName:          "no fellow participants",
+			chosenColor:       "#ff1010",
+			fellowIdentifiers: []string{},
+			errorFromChecker:  nil,
+			expectedError:     false,
+		},
+		{
+			testName:          "error from checker",
+			chosenColor:       "#00ffff",
+			fellowIdentifiers: nil,
+			errorFromChecker:  fmt.Errorf("expected error"),
+			expectedError:     true,
+		},
+		{
+			testName:          "same color as fellow",
+			chosenColor:       "#FF0000",
+			fellowIdentifiers: []string{fellowIdentifier},
+			errorFromChecker:  nil,
+			expectedError:     true,
+		},
+		{
+			testName:          "near-duplicate of fellow",
+			chosenColor:       "#ff1010",
+			fellowIdentifiers: []string{fellowIdentifier},
+			errorFromChecker:  nil,
+			expectedError:     true,
+		},
+		{
+			testName:          "suggested near-duplicate of fellow",
+			chosenColor:       "red",
+			fellowIdentifiers: []string{fellowIdentifier},
+			errorFromChecker:  nil,
+			expectedError:     true,
+		},
+		{
+			testName:          "distinct from fellow",
+			chosenColor:       "#00ffff",
+			fellowIdentifiers: []string{fellowIdentifier},
+			errorFromChecker:  nil,
+			expectedError:     false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(
+					unitTest,
+					fmt.Errorf("Only Get(...) or UpdateColor(...) should be called"))
+			mockImplementation.TestErrorForGet = nil
+			mockImplementation.ReturnForGet = fellowState
+			if !testCase.expectedError {
+				mockImplementation.TestErrorForUpdateColor = nil
+			}
+
+			stateCollection :=
+				player.NewCollection(
+					mockImplementation,
+					colorsAvailableInTest,
+					testBackgroundColor,
+					nil,
+					player.DeletionLeavesTombstone,
+					&mockParticipationChecker{
+						ReturnForFellowParticipants: testCase.fellowIdentifiers,
+						ErrorToReturn:               testCase.errorFromChecker,
+					})
+
+			actualError :=
+				stateCollection.UpdateColor(
+					context.Background(),
+					playerIdentifier,
+					testCase.chosenColor)
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"UpdateColor(%v, %v) returned error %v - expected an error: %v",
+					playerIdentifier,
+					testCase.chosenColor,
+					actualError,
+					testCase.expectedError)
+			}
+
+			expectedUpdates := []player.ReadAndWriteState{}
+			if !testCase.expectedError {
+				expectedUpdates = append(
+					expectedUpdates,
+					player.ReadAndWriteState{
+						PlayerIdentifier: playerIdentifier,
+						ChatColor:        player.NormalizeHexColor(testCase.chosenColor),
+					})
+			}
+
+			if !reflect.DeepEqual(mockImplementation.ArgumentsForUpdateColor, expectedUpdates) {
+				unitTest.Fatalf(
+					"UpdateColor(%v, %v) passed %+v to persister - expected %+v",
+					playerIdentifier,
+					testCase.chosenColor,
+					mockImplementation.ArgumentsForUpdateColor,
+					expectedUpdates)
+			}
+		})
+	}
+}
+
+func TestSeparatedChatColorsOfParticipants(unitTest *testing.T) {
+	participantIdentifiers := []string{"first identifier", "second identifier", "third identifier"}
+
+	testCases := []struct {
+		testName          string
+		availableColors   []string
+		participantColors []string
+		expectedError     bool
+		expectedKept      []bool
+	}{
+		{
+			testName:          "distinct colors",
+			availableColors:   colorsAvailableInTest,
+			participantColors: []string{"#ff0000", "#00ffff", "yellow"},
+			expectedKept:      []bool{true, true, true},
+		},
+		{
+			testName:          "same suggested color",
+			availableColors:   colorsAvailableInTest,
+			participantColors: []string{"red", "blue", "red"},
+			expectedKept:      []bool{true, true, false},
+		},
+		{
+			testName:          "near-duplicate colors",
+			availableColors:   colorsAvailableInTest,
+			participantColors: []string{"#ff0000", "#ff1010", "#00ffff"},
+			expectedKept:      []bool{true, false, true},
+		},
+		{
+			testName:          "color without contrast against background",
+			availableColors:   colorsAvailableInTest,
+			participantColors: []string{"#101010", "#00ffff", "yellow"},
+			expectedKept:      []bool{false, true, true},
+		},
+		{
+			testName:          "replacement avoids other participants",
+			availableColors:   []string{"red", "blue", "green"},
+			participantColors: []string{"red", "red", "blue"},
+			expectedKept:      []bool{true, false, true},
+		},
+		{
+			testName:          "no suggested color can be told apart",
+			availableColors:   []string{"red", "white"},
+			participantColors: []string{"red", "white", "red"},
+			expectedError:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			executionContext := context.Background()
+			playerPersister := player_persister.NewInMemory()
+
+			// The players are added directly to the persister so that they can have colors
+			// which the collection would not accept.
+			for participantIndex, participantIdentifier := range participantIdentifiers {
+				errorFromAdd :=
+					playerPersister.Add(
+						executionContext,
+						participantIdentifier,
+						"Name of "+participantIdentifier,
+						testCase.participantColors[participantIndex],
+						player.RolePlayer,
+						"irrelevant hash")
+				if errorFromAdd != nil {
+					unitTest.Fatalf(
+						"Add(%v, ...) produced unexpected error %v",
+						participantIdentifier,
+						errorFromAdd)
+				}
+			}
+
+			stateCollection :=
+				player.NewCollection(
+					playerPersister,
+					testCase.availableColors,
+					testBackgroundColor,
+					nil,
+					player.DeletionLeavesTombstone,
+					&mockParticipationChecker{})
+
+			separatedColors, errorFromSeparation :=
+				stateCollection.SeparatedChatColors(executionContext, participantIdentifiers)
+
+			if testCase.expectedError {
+				if errorFromSeparation == nil {
+					unitTest.Fatalf(
+						"SeparatedChatColors(...) did not produce expected error, produced %v",
+						separatedColors)
+				}
+
+				return
+			}
+
+			if errorFromSeparation != nil {
+				unitTest.Fatalf(
+					"SeparatedChatColors(...) produced unexpected error %v",
+					errorFromSeparation)
+			}
+
+			if len(separatedColors) != len(participantIdentifiers) {
+				unitTest.Fatalf(
+					"SeparatedChatColors(%v) produced %v - expected one color per player",
+					participantIdentifiers,
+					separatedColors)
+			}
+
+			for participantIndex, separatedColor := range separatedColors {
+				originalColor := testCase.participantColors[participantIndex]
+				isKept := separatedColor == originalColor
+				if isKept != testCase.expectedKept[participantIndex] {
+					unitTest.Fatalf(
+						"participant %v with color %v is shown in color %v - expected kept: %v",
+						participantIndex,
+						originalColor,
+						separatedColor,
+						testCase.expectedKept[participantIndex])
+				}
+
+				if !isKept && !mapStringsToTrue(testCase.availableColors)[separatedColor] {
+					unitTest.Fatalf(
+						"participant %v was shown in color %v which is not in suggested colors %v",
+						participantIndex,
+						separatedColor,
+						testCase.availableColors)
+				}
+
+				for otherIndex := 0; otherIndex < participantIndex; otherIndex++ {
+					if player.AreNearDuplicates(separatedColor, separatedColors[otherIndex]) {
+						unitTest.Fatalf(
+							"participants %v and %v have colors %v and %v which are too similar",
+							otherIndex,
+							participantIndex,
+							separatedColors[otherIndex],
+							separatedColor)
+					}
+				}
+
+				// The color which was saved for the player should not have been changed.
+				participantState, errorFromGet :=
+					playerPersister.Get(executionContext, participantIdentifiers[participantIndex])
+				if errorFromGet != nil {
+					unitTest.Fatalf(
+						"Get(%v) produced unexpected error %v",
+						participantIdentifiers[participantIndex],
+						errorFromGet)
+				}
+
+				if participantState.Color() != originalColor {
+					unitTest.Fatalf(
+						"participant %v had saved color %v changed to %v",
+						participantIndex,
+						originalColor,
+						participantState.Color())
+				}
+			}
+		})
+	}
+}
+
+func TestSearchPagesThroughPlayersAndLeavesOutTombstones(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("Only Search(...) should be called"))
+	mockImplementation.TestErrorForSearch = nil
+
+	// The page size is 2 so the collection asks for 3 players, but gets a tombstone
+	// among them, so has to ask again for 1 more player after the last name it saw.
+	mockImplementation.ReturnsForSearch = [][]player.ReadonlyState{
+		[]player.ReadonlyState{
+			&player.ReadAndWriteState{PlayerName: "Bob A", PlayerRole: player.RolePlayer},
+			&player.ReadAndWriteState{PlayerName: "Bob B", PlayerRole: player.RoleDeleted},
+			&player.ReadAndWriteState{PlayerName: "Bob C", PlayerRole: player.RolePlayer},
+		},
+		[]player.ReadonlyState{
+			&player.ReadAndWriteState{PlayerName: "Bob D", PlayerRole: player.RolePlayer},
+		},
+	}
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	firstPage, errorFromFirstSearch :=
+		stateCollection.Search(context.Background(), "Bob", player.NameAscending, "", 2)
+
+	if (errorFromFirstSearch != nil) ||
+		(len(firstPage.Players) != 2) ||
+		(firstPage.Players[0].Name() != "Bob A") ||
+		(firstPage.Players[1].Name() != "Bob C") ||
+		(firstPage.NextCursor == "") {
+		unitTest.Fatalf(
+			"Search(...) returned %+v, %v - expected Bob A and Bob C with a cursor",
+			firstPage,
+			errorFromFirstSearch)
+	}
+
+	expectedCriteria := []player.SearchCriteria{
+		player.SearchCriteria{
+			NamePrefix:    "Bob",
+			Order:         player.NameAscending,
+			AfterName:     "",
+			MaximumNumber: 3,
+		},
+		player.SearchCriteria{
+			NamePrefix:    "Bob",
+			Order:         player.NameAscending,
+			AfterName:     "Bob C",
+			MaximumNumber: 1,
+		},
+	}
+
+	if !reflect.DeepEqual(mockImplementation.ArgumentsForSearch, expectedCriteria) {
+		unitTest.Fatalf(
+			"Search(...) asked the persister for %+v - expected %+v",
+			mockImplementation.ArgumentsForSearch,
+			expectedCriteria)
+	}
+
+	mockImplementation.ArgumentsForSearch = nil
+	mockImplementation.ReturnsForSearch = [][]player.ReadonlyState{
+		[]player.ReadonlyState{
+			&player.ReadAndWriteState{PlayerName: "Bob D", PlayerRole: player.RolePlayer},
+		},
+	}
+
+	secondPage, errorFromSecondSearch :=
+		stateCollection.Search(
+			context.Background(),
+			"Bob",
+			player.NameAscending,
+			firstPage.NextCursor,
+			2)
+
+	if (errorFromSecondSearch != nil) ||
+		(len(secondPage.Players) != 1) ||
+		(secondPage.Players[0].Name() != "Bob D") ||
+		(secondPage.NextCursor != "") {
+		unitTest.Fatalf(
+			"Search(...) with cursor returned %+v, %v - expected only Bob D with no cursor",
+			secondPage,
+			errorFromSecondSearch)
+	}
+
+	if (len(mockImplementation.ArgumentsForSearch) != 1) ||
+		(mockImplementation.ArgumentsForSearch[0].AfterName != "Bob C") {
+		unitTest.Fatalf(
+			"Search(...) with cursor asked the persister for %+v - expected players after Bob C",
+			mockImplementation.ArgumentsForSearch)
+	}
+}
+
+func TestSearchLimitsPageSize(unitTest *testing.T) {
+	testCases := []struct {
+		testName               string
+		requestedPageSize      int
+		expectedNumberToSearch int
+	}{
+		{
+			testName:               "Zero gives default",
+			requestedPageSize:      0,
+			expectedNumberToSearch: 21,
+		},
+		{
+			testName:               "Negative gives default",
+			requestedPageSize:      -5,
+			expectedNumberToSearch: 21,
+		},
+		{
+			testName:               "Within limit",
+			requestedPageSize:      7,
+			expectedNumberToSearch: 8,
+		},
+		{
+			testName:               "Above limit gives maximum",
+			requestedPageSize:      1000,
+			expectedNumberToSearch: 101,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(unitTest, fmt.Errorf("Only Search(...) should be called"))
+			mockImplementation.TestErrorForSearch = nil
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			searchResult, errorFromSearch :=
+				stateCollection.Search(
+					context.Background(),
+					"",
+					player.NameDescending,
+					"",
+					testCase.requestedPageSize)
+
+			if (errorFromSearch != nil) ||
+				(len(searchResult.Players) != 0) ||
+				(searchResult.NextCursor != "") {
+				unitTest.Fatalf(
+					"Search(...) returned %+v, %v - expected empty result and no error",
+					searchResult,
+					errorFromSearch)
+			}
+
+			if (len(mockImplementation.ArgumentsForSearch) != 1) ||
+				(mockImplementation.ArgumentsForSearch[0].MaximumNumber !=
+					testCase.expectedNumberToSearch) ||
+				(mockImplementation.ArgumentsForSearch[0].Order != player.NameDescending) {
+				unitTest.Fatalf(
+					"Search(...) asked the persister for %+v - expected one search for %v",
+					mockImplementation.ArgumentsForSearch,
+					testCase.expectedNumberToSearch)
+			}
+		})
+	}
+}
+
+func TestRejectSearchWithInvalidOrderOrCursor(unitTest *testing.T) {
+	testCases := []struct {
+		testName   string
+		nameOrder  player.NameOrder
+		pageCursor string
+	}{
+		{
+			testName:   "Invalid order",
+			nameOrder:  player.NameOrder(7),
+			pageCursor: "",
+		},
+		{
+			testName:   "Invalid cursor",
+			nameOrder:  player.NameAscending,
+			pageCursor: "not base64!",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(unitTest, fmt.Errorf("No function should be called"))
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			_, errorFromSearch :=
+				stateCollection.Search(
+					context.Background(),
+					"",
+					testCase.nameOrder,
+					testCase.pageCursor,
+					5)
+
+			if errorFromSearch == nil {
+				unitTest.Fatalf(
+					"Search(order %v, cursor %v) did not return an error",
+					testCase.nameOrder,
+					testCase.pageCursor)
+			}
+		})
+	}
+}
+
+func TestReturnErrorFromPersisterSearch(unitTest *testing.T) {
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("Only Search(...) should be called"))
+	mockImplementation.TestErrorForSearch = nil
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	expectedError := fmt.Errorf("expected error")
+	mockImplementation.ReturnForNontestError = expectedError
+
+	_, actualError :=
+		stateCollection.Search(context.Background(), "", player.NameAscending, "", 5)
+
+	if actualError != expectedError {
+		unitTest.Fatalf(
+			"Search(...) returned error %v - expected %v",
+			actualError,
+			expectedError)
+	}
+}
+
+func TestAddAndRemoveFriend(unitTest *testing.T) {
+	playerIdentifier := "mock identifier"
+	friendIdentifier := "friend identifier"
+	otherFriendIdentifier := "other friend identifier"
+
+	testCases := []struct {
+		testName          string
+		functionToTest    string
+		existingFriends   []string
+		argumentFriend    string
+		expectedError     bool
+		expectedFriends   []string
+		expectedToPersist bool
+	}{
+		{
+			testName:          "add new friend",
+			functionToTest:    "AddFriend",
+			existingFriends:   []string{otherFriendIdentifier},
+			argumentFriend:    friendIdentifier,
+			expectedFriends:   []string{otherFriendIdentifier, friendIdentifier},
+			expectedToPersist: true,
+		},
+		{
+			testName:        "add existing friend",
+			functionToTest:  "AddFriend",
+			existingFriends: []string{friendIdentifier},
+			argumentFriend:  friendIdentifier,
+		},
+		{
+			testName:       "add self",
+			functionToTest: "AddFriend",
+			argumentFriend: playerIdentifier,
+			expectedError:  true,
+		},
+		{
+			testName:       "add unknown player",
+			functionToTest: "AddFriend",
+			argumentFriend: "unknown identifier",
+			expectedError:  true,
+		},
+		{
+			testName:       "add deleted player",
+			functionToTest: "AddFriend",
+			argumentFriend: "deleted identifier",
+			expectedError:  true,
+		},
+		{
+			testName:          "remove friend",
+			functionToTest:    "RemoveFriend",
+			existingFriends:   []string{friendIdentifier, otherFriendIdentifier},
+			argumentFriend:    friendIdentifier,
+			expectedFriends:   []string{otherFriendIdentifier},
+			expectedToPersist: true,
+		},
+		{
+			testName:        "remove player who is not a friend",
+			functionToTest:  "RemoveFriend",
+			existingFriends: []string{otherFriendIdentifier},
+			argumentFriend:  friendIdentifier,
+			expectedError:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(
+					unitTest,
+					fmt.Errorf("Only Get(...) and UpdateContacts(...) should be called"))
+			mockImplementation.TestErrorForGet = nil
+			mockImplementation.TestErrorForUpdateContacts = nil
+
+			existingState :=
+				&player.ReadAndWriteState{
+					PlayerIdentifier: playerIdentifier,
+					PlayerContacts: player.Contacts{
+						FriendIdentifiers: testCase.existingFriends,
+					},
+				}
+
+			mockImplementation.ReturnsForGetByIdentifier =
+				map[string]player.ReadonlyState{
+					playerIdentifier: existingState,
+					friendIdentifier: &player.ReadAndWriteState{
+						PlayerIdentifier: friendIdentifier,
+					},
+					otherFriendIdentifier: &player.ReadAndWriteState{
+						PlayerIdentifier: otherFriendIdentifier,
+					},
+					"deleted identifier": &player.ReadAndWriteState{
+						PlayerIdentifier: "deleted identifier",
+						PlayerRole:       player.RoleDeleted,
+					},
+				}
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			var actualError error
+			if testCase.functionToTest == "AddFriend" {
+				actualError =
+					stateCollection.AddFriend(
+						context.Background(),
+						playerIdentifier,
+						testCase.argumentFriend)
+			} else {
+				actualError =
+					stateCollection.RemoveFriend(
+						context.Background(),
+						playerIdentifier,
+						testCase.argumentFriend)
+			}
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"%v(%v, %v) returned error %v - expected error: %v",
+					testCase.functionToTest,
+					playerIdentifier,
+					testCase.argumentFriend,
+					actualError,
+					testCase.expectedError)
+			}
+
+			if !testCase.expectedToPersist {
+				if len(mockImplementation.ArgumentsForUpdateContacts) != 0 {
+					unitTest.Fatalf(
+						"%v(...) unexpectedly updated contacts %+v",
+						testCase.functionToTest,
+						mockImplementation.ArgumentsForUpdateContacts)
+				}
+
+				return
+			}
+
+			if (len(mockImplementation.ArgumentsForUpdateContacts) != 1) ||
+				(mockImplementation.ArgumentsForUpdateContacts[0].PlayerIdentifier !=
+					playerIdentifier) ||
+				!reflect.DeepEqual(
+					mockImplementation.ArgumentsForUpdateContacts[0].PlayerContacts.FriendIdentifiers,
+					testCase.expectedFriends) {
+				unitTest.Fatalf(
+					"%v(...) updated contacts %+v - expected friends %v",
+					testCase.functionToTest,
+					mockImplementation.ArgumentsForUpdateContacts,
+					testCase.expectedFriends)
+			}
+
+			// The state read from the persister must not have been changed in place.
+			if !reflect.DeepEqual(
+				existingState.PlayerContacts.FriendIdentifiers,
+				testCase.existingFriends) {
+				unitTest.Fatalf(
+					"%v(...) changed the friends read from the persister to %v",
+					testCase.functionToTest,
+					existingState.PlayerContacts.FriendIdentifiers)
+			}
+		})
+	}
+}
+
+func TestFriendsLeavesOutDeletedPlayers(unitTest *testing.T) {
+	playerIdentifier := "mock identifier"
+	mockImplementation :=
+		NewMockPersister(unitTest, fmt.Errorf("Only Get(...) should be called"))
+	mockImplementation.TestErrorForGet = nil
+	mockImplementation.ReturnsForGetByIdentifier =
+		map[string]player.ReadonlyState{
+			playerIdentifier: &player.ReadAndWriteState{
+				PlayerIdentifier: playerIdentifier,
+				PlayerContacts: player.Contacts{
+					FriendIdentifiers: []string{
+						"second friend",
+						"tombstone",
+						"removed completely",
+						"first friend",
+					},
+				},
+			},
+			"first friend": &player.ReadAndWriteState{
+				PlayerIdentifier: "first friend",
+			},
+			"second friend": &player.ReadAndWriteState{
+				PlayerIdentifier: "second friend",
+			},
+			"tombstone": &player.ReadAndWriteState{
+				PlayerIdentifier: "tombstone",
+				PlayerRole:       player.RoleDeleted,
+			},
+		}
+
+	stateCollection, _ :=
+		prepareCollection(
+			unitTest,
+			nil,
+			colorsAvailableInTest,
+			mockImplementation)
+
+	actualFriends, errorFromFriends :=
+		stateCollection.Friends(context.Background(), playerIdentifier)
+
+	if (errorFromFriends != nil) ||
+		(len(actualFriends) != 2) ||
+		(actualFriends[0].Identifier() != "second friend") ||
+		(actualFriends[1].Identifier() != "first friend") {
+		unitTest.Fatalf(
+			"Friends(%v) returned %+v, %v - expected second and first friend",
+			playerIdentifier,
+			actualFriends,
+			errorFromFriends)
+	}
+
+	_, errorForUnknown :=
+		stateCollection.Friends(context.Background(), "unknown identifier")
+
+	if errorForUnknown == nil {
+		unitTest.Fatalf("Friends(unknown identifier) did not return an error")
+	}
+}
+
+func TestSaveAndDeleteGroup(unitTest *testing.T) {
+	playerIdentifier := "mock identifier"
+	existingGroups := []player.Group{
+		player.Group{
+			Name:              "Tuesday",
+			MemberIdentifiers: []string{"first member"},
+		},
+		player.Group{
+			Name:              "Weekend",
+			MemberIdentifiers: []string{"second member"},
+		},
+	}
+
+	testCases := []struct {
+		testName          string
+		functionToTest    string
+		groupName         string
+		memberIdentifiers []string
+		expectedError     bool
+		expectedGroups    []player.Group
+	}{
+		{
+			testName:          "save new group",
+			functionToTest:    "SaveGroup",
+			groupName:         "Friday",
+			memberIdentifiers: []string{"second member", "first member"},
+			expectedGroups: []player.Group{
+				existingGroups[0],
+				existingGroups[1],
+				player.Group{
+					Name:              "Friday",
+					MemberIdentifiers: []string{"second member", "first member"},
+				},
+			},
+		},
+		{
+			testName:          "replace existing group",
+			functionToTest:    "SaveGroup",
+			groupName:         "Tuesday",
+			memberIdentifiers: []string{"second member"},
+			expectedGroups: []player.Group{
+				player.Group{
+					Name:              "Tuesday",
+					MemberIdentifiers: []string{"second member"},
+				},
+				existingGroups[1],
+			},
+		},
+		{
+			testName:          "save group without name",
+			functionToTest:    "SaveGroup",
+			groupName:         "",
+			memberIdentifiers: []string{"first member"},
+			expectedError:     true,
+		},
+		{
+			testName:          "save group without members",
+			functionToTest:    "SaveGroup",
+			groupName:         "Friday",
+			memberIdentifiers: []string{},
+			expectedError:     true,
+		},
+		{
+			testName:          "save group with owner as member",
+			functionToTest:    "SaveGroup",
+			groupName:         "Friday",
+			memberIdentifiers: []string{"first member", playerIdentifier},
+			expectedError:     true,
+		},
+		{
+			testName:          "save group with repeated member",
+			functionToTest:    "SaveGroup",
+			groupName:         "Friday",
+			memberIdentifiers: []string{"first member", "first member"},
+			expectedError:     true,
+		},
+		{
+			testName:          "save group with unknown member",
+			functionToTest:    "SaveGroup",
+			groupName:         "Friday",
+			memberIdentifiers: []string{"first member", "unknown member"},
+			expectedError:     true,
+		},
+		{
+			testName:          "save group with deleted member",
+			functionToTest:    "SaveGroup",
+			groupName:         "Friday",
+			memberIdentifiers: []string{"deleted member"},
+			expectedError:     true,
+		},
+		{
+			testName:       "delete group",
+			functionToTest: "DeleteGroup",
+			groupName:      "Tuesday",
+			expectedGroups: existingGroups[1:],
+		},
+		{
+			testName:       "delete unknown group",
+			functionToTest: "DeleteGroup",
+			groupName:      "Friday",
+			expectedError:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			mockImplementation :=
+				NewMockPersister(
+					unitTest,
+					fmt.Errorf("Only Get(...) and UpdateContacts(...) should be called"))
+			mockImplementation.TestErrorForGet = nil
+			mockImplementation.TestErrorForUpdateContacts = nil
+
+			existingState :=
+				&player.ReadAndWriteState{
+					PlayerIdentifier: playerIdentifier,
+					PlayerContacts:   player.Contacts{Groups: existingGroups},
+				}
+
+			mockImplementation.ReturnsForGetByIdentifier =
+				map[string]player.ReadonlyState{
+					playerIdentifier: existingState,
+					"first member": &player.ReadAndWriteState{
+						PlayerIdentifier: "first member",
+					},
+					"second member": &player.ReadAndWriteState{
+						PlayerIdentifier: "second member",
+					},
+					"deleted member": &player.ReadAndWriteState{
+						PlayerIdentifier: "deleted member",
+						PlayerRole:       player.RoleDeleted,
+					},
+				}
+
+			stateCollection, _ :=
+				prepareCollection(
+					unitTest,
+					nil,
+					colorsAvailableInTest,
+					mockImplementation)
+
+			var actualError error
+			if testCase.functionToTest == "SaveGroup" {
+				actualError =
+					stateCollection.SaveGroup(
+						context.Background(),
+						playerIdentifier,
+						testCase.groupName,
+						testCase.memberIdentifiers)
+			} else {
+				actualError =
+					stateCollection.DeleteGroup(
+						context.Background(),
+						playerIdentifier,
+						testCase.groupName)
+			}
+
+			if (actualError != nil) != testCase.expectedError {
+				unitTest.Fatalf(
+					"%v(%v, %v, %v) returned error %v - expected error: %v",
+					testCase.functionToTest,
+					playerIdentifier,
+					testCase.groupName,
+					testCase.memberIdentifiers,
+					actualError,
+					testCase.expectedError)
+			}
+
+			if testCase.expectedError {
+				if len(mockImplementation.ArgumentsForUpdateContacts) != 0 {
+					unitTest.Fatalf(
+						"%v(...) unexpectedly updated contacts %+v",
+						testCase.functionToTest,
+						mockImplementation.ArgumentsForUpdateContacts)
+				}
+
+				return
+			}
+
+			if (len(mockImplementation.ArgumentsForUpdateContacts) != 1) ||
+				!reflect.DeepEqual(
+					mockImplementation.ArgumentsForUpdateContacts[0].PlayerContacts.Groups,
+					testCase.expectedGroups) {
+				unitTest.Fatalf(
+					"%v(...) updated contacts %+v - expected groups %+v",
+					testCase.functionToTest,
+					mockImplementation.ArgumentsForUpdateContacts,
+					testCase.expectedGroups)
+			}
+
+			if existingState.PlayerContacts.Groups[0].Name != "Tuesday" ||
+				existingState.PlayerContacts.Groups[0].MemberIdentifiers[0] != "first member" {
+				unitTest.Fatalf(
+					"%v(...) changed the groups read from the persister to %+v",
+					testCase.functionToTest,
+					existingState.PlayerContacts.Groups)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/server"
+)
+
+func TestAllowedOriginsForCrossOriginRequests(unitTest *testing.T) {
+	testCases := []struct {
+		testName              string
+		allowedOrigins        []string
+		requestOrigin         string
+		expectedAllowedOrigin string
+		expectedVary          string
+	}{
+		{
+			testName:              "No allowed origins",
+			allowedOrigins:        nil,
+			requestOrigin:         "http://localhost:4233",
+			expectedAllowedOrigin: "",
+			expectedVary:          "",
+		},
+		{
+			testName:              "Only origin",
+			allowedOrigins:        []string{"http://localhost:4233"},
+			requestOrigin:         "http://localhost:4233",
+			expectedAllowedOrigin: "http://localhost:4233",
+			expectedVary:          "Origin",
+		},
+		{
+			testName:              "Second of several origins",
+			allowedOrigins:        []string{"http://localhost:4233", "https://example.com"},
+			requestOrigin:         "https://example.com",
+			expectedAllowedOrigin: "https://example.com",
+			expectedVary:          "Origin",
+		},
+		{
+			testName:              "Origin not allowed",
+			allowedOrigins:        []string{"http://localhost:4233", "https://example.com"},
+			requestOrigin:         "https://example.org",
+			expectedAllowedOrigin: "",
+			expectedVary:          "Origin",
+		},
+		{
+			testName:              "Request without origin",
+			allowedOrigins:        []string{"http://localhost:4233"},
+			requestOrigin:         "",
+			expectedAllowedOrigin: "",
+			expectedVary:          "Origin",
+		},
+		{
+			testName:              "Any origin",
+			allowedOrigins:        []string{"*"},
+			requestOrigin:         "https://example.org",
+			expectedAllowedOrigin: "*",
+			expectedVary:          "Origin",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			serverState :=
+				server.NewWithGivenHandlers(
+					mockContextProvider,
+					testCase.allowedOrigins,
+					"test",
+					nil,
+					nil,
+					nil,
+					ErrorEndpointHandler(unitTest),
+					ErrorEndpointHandler(unitTest),
+					nil,
+					nil)
+
+			httpRequest := httptest.NewRequest(http.MethodGet, "/backend/version", nil)
+			if testCase.requestOrigin != "" {
+				httpRequest.Header.Set("Origin", testCase.requestOrigin)
+			}
+
+			responseRecorder := httptest.NewRecorder()
+			serverState.HandleBackend(responseRecorder, httpRequest)
+
+			if responseRecorder.Code != http.StatusOK {
+				unitTest.Fatalf(
+					"returned wrong status %v instead of expected %v",
+					responseRecorder.Code,
+					http.StatusOK)
+			}
+
+			actualAllowedOrigin := responseRecorder.Header().Get("Access-Control-Allow-Origin")
+			if actualAllowedOrigin != testCase.expectedAllowedOrigin {
+				unitTest.Fatalf(
+					"returned allowed origin %q instead of expected %q",
+					actualAllowedOrigin,
+					testCase.expectedAllowedOrigin)
+			}
+
+			actualVary := responseRecorder.Header().Get("Vary")
+			if actualVary != testCase.expectedVary {
+				unitTest.Fatalf(
+					"returned Vary %q instead of expected %q",
+					actualVary,
+					testCase.expectedVary)
+			}
+		})
+	}
+}
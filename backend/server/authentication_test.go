@@ -0,0 +1,338 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/defaults"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	game_persister "github.com/benoleary/ilutulestikud/backend/game/persister"
+	"github.com/benoleary/ilutulestikud/backend/player"
+	player_persister "github.com/benoleary/ilutulestikud/backend/player/persister"
+	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestRejectInvalidAuthorizationBeforeCallingHandler(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+	otherSigner :=
+		authentication.NewTokenSigner([]byte("other signing key"), time.Hour)
+
+	tokenFromOtherSigner, errorFromIssue := otherSigner.IssueToken("Test Player")
+	if errorFromIssue != nil {
+		unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+	}
+
+	testCases := []struct {
+		testName            string
+		authorizationHeader string
+	}{
+		{
+			testName:            "not a bearer token",
+			authorizationHeader: "Basic dGVzdDp0ZXN0",
+		},
+		{
+			testName:            "malformed token",
+			authorizationHeader: "Bearer not.a-token",
+		},
+		{
+			testName:            "token signed with other key",
+			authorizationHeader: "Bearer " + tokenFromOtherSigner,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			httpRequest :=
+				httptest.NewRequest(
+					http.MethodGet,
+					"/backend/player",
+					nil)
+			httpRequest.Header.Set("Authorization", testCase.authorizationHeader)
+
+			serverState :=
+				server.NewWithGivenHandlers(
+					mockContextProvider,
+					[]string{"irrelevant to tests"},
+					"test",
+					nil,
+					tokenSigner,
+					nil,
+					ErrorEndpointHandler(unitTest),
+					ErrorEndpointHandler(unitTest),
+					nil,
+					nil)
+
+			responseRecorder := httptest.NewRecorder()
+			serverState.HandleBackend(responseRecorder, httpRequest)
+
+			if responseRecorder.Code != http.StatusUnauthorized {
+				unitTest.Errorf(
+					"%v: returned wrong status %v instead of expected %v",
+					testCase.testName,
+					responseRecorder.Code,
+					http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestPassAuthenticatedPlayerToHandler(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+
+	expectedPlayer := "Test Player"
+	sessionToken, errorFromIssue := tokenSigner.IssueToken(expectedPlayer)
+	if errorFromIssue != nil {
+		unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+	}
+
+	testHandler := ErrorEndpointHandler(unitTest)
+	testHandler.TestErrorForGet = nil
+	testHandler.ReturnInterface = "success"
+	testHandler.ReturnCode = http.StatusOK
+
+	httpRequest :=
+		httptest.NewRequest(
+			http.MethodGet,
+			"/backend/player",
+			nil)
+	httpRequest.Header.Set("Authorization", "Bearer "+sessionToken)
+
+	serverState :=
+		server.NewWithGivenHandlers(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			nil,
+			tokenSigner,
+			nil,
+			testHandler,
+			ErrorEndpointHandler(unitTest),
+			nil,
+			nil)
+
+	responseRecorder := httptest.NewRecorder()
+	serverState.HandleBackend(responseRecorder, httpRequest)
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	actualPlayer, isAuthenticated :=
+		authentication.AuthenticatedPlayer(testHandler.ReceivedContext)
+
+	if !isAuthenticated || (actualPlayer != expectedPlayer) {
+		unitTest.Fatalf(
+			"handler received context with player %v (authenticated: %v), expected %v",
+			actualPlayer,
+			isAuthenticated,
+			expectedPlayer)
+	}
+}
+
+// newStateWithInMemoryCollections creates a server.State around player and game
+// collections with in-memory persisters, so that requests are authorized against
+// the actual players and games, and returns it with the collections.
+func newStateWithInMemoryCollections(
+	tokenSigner *authentication.TokenSigner) (
+	*server.State,
+	*player.StateCollection,
+	*game.StateCollection) {
+	gamePersister := game_persister.NewInMemory()
+	playerCollection :=
+		player.NewCollection(
+			player_persister.NewInMemory(),
+			defaults.AvailableColors(),
+			defaults.ChatBackgroundColor(),
+			nil,
+			player.DeletionLeavesTombstone,
+			game.NewParticipationChecker(gamePersister))
+	gameCollection :=
+		game.NewCollection(
+			gamePersister,
+			game_persister.NewLobbyInMemory(),
+			game_persister.NewSeriesInMemory(),
+			game_persister.NewSpectatorInMemory(),
+			game_persister.NewHistoryInMemory(),
+			8,
+			playerCollection,
+			playerCollection)
+
+	serverState :=
+		server.New(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			&parsing.NoOperationTranslator{},
+			tokenSigner,
+			nil,
+			playerCollection,
+			gameCollection)
+
+	return serverState, playerCollection, gameCollection
+}
+
+func TestRejectSessionTokenOfDeletedPlayer(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+
+	serverState, playerCollection, _ := newStateWithInMemoryCollections(tokenSigner)
+
+	playerIdentifier, errorFromAdd :=
+		playerCollection.Add(context.Background(), "Test Player", "", "test password")
+	if errorFromAdd != nil {
+		unitTest.Fatalf("Add(...) produced unexpected error %v", errorFromAdd)
+	}
+
+	sessionToken, errorFromIssue := tokenSigner.IssueToken(playerIdentifier)
+	if errorFromIssue != nil {
+		unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+	}
+
+	statusOfRequest := func() int {
+		httpRequest :=
+			httptest.NewRequest(
+				http.MethodGet,
+				"/backend/player/registered-players",
+				nil)
+		httpRequest.Header.Set("Authorization", "Bearer "+sessionToken)
+
+		responseRecorder := httptest.NewRecorder()
+		serverState.HandleBackend(responseRecorder, httpRequest)
+
+		return responseRecorder.Code
+	}
+
+	statusBeforeDeletion := statusOfRequest()
+	if statusBeforeDeletion != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v before deletion instead of expected %v",
+			statusBeforeDeletion,
+			http.StatusOK)
+	}
+
+	errorFromDelete := playerCollection.Delete(context.Background(), playerIdentifier)
+	if errorFromDelete != nil {
+		unitTest.Fatalf("Delete(...) produced unexpected error %v", errorFromDelete)
+	}
+
+	statusAfterDeletion := statusOfRequest()
+	if statusAfterDeletion != http.StatusUnauthorized {
+		unitTest.Fatalf(
+			"returned wrong status %v after deletion instead of expected %v",
+			statusAfterDeletion,
+			http.StatusUnauthorized)
+	}
+}
+
+func TestDeleteRematchAsHostOfOriginalGame(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+
+	serverState, playerCollection, gameCollection :=
+		newStateWithInMemoryCollections(tokenSigner)
+
+	executionContext := context.Background()
+	playerIdentifiers := make([]string, 0, 2)
+	sessionTokens := make([]string, 0, 2)
+	for _, playerName := range []string{"Host Player", "Guest Player"} {
+		playerIdentifier, errorFromAdd :=
+			playerCollection.Add(executionContext, playerName, "", "test password")
+		if errorFromAdd != nil {
+			unitTest.Fatalf("Add(%v, ...) produced unexpected error %v", playerName, errorFromAdd)
+		}
+
+		sessionToken, errorFromIssue := tokenSigner.IssueToken(playerIdentifier)
+		if errorFromIssue != nil {
+			unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+		}
+
+		playerIdentifiers = append(playerIdentifiers, playerIdentifier)
+		sessionTokens = append(sessionTokens, sessionToken)
+	}
+
+	// The deck only has the cards for the initial hands, so the game is finished once
+	// each player has played a card.
+	originalGame := "Test game"
+	testRuleset := game.NewStandardWithoutRainbow()
+	handSize := testRuleset.NumberOfCardsInPlayerHand(len(playerIdentifiers))
+	initialDeck := testRuleset.CopyOfFullCardset()[:handSize*len(playerIdentifiers)]
+
+	errorFromAddGame :=
+		gameCollection.AddNewWithGivenDeck(
+			executionContext,
+			originalGame,
+			testRuleset,
+			playerIdentifiers,
+			initialDeck)
+	if errorFromAddGame != nil {
+		unitTest.Fatalf("AddNewWithGivenDeck(...) produced unexpected error %v", errorFromAddGame)
+	}
+
+	for _, playerIdentifier := range playerIdentifiers {
+		actionExecutor, errorFromExecutor :=
+			gameCollection.ExecuteAction(executionContext, originalGame, playerIdentifier)
+		if errorFromExecutor != nil {
+			unitTest.Fatalf("ExecuteAction(...) produced unexpected error %v", errorFromExecutor)
+		}
+
+		errorFromPlay :=
+			actionExecutor.TakeTurnByPlaying(executionContext, game.TurnNotGiven, 0)
+		if errorFromPlay != nil {
+			unitTest.Fatalf("TakeTurnByPlaying(...) produced unexpected error %v", errorFromPlay)
+		}
+	}
+
+	// The guest goes first in the rematch, but the host of the original game is still
+	// the creator of the rematch.
+	rematchName, errorFromRematch :=
+		gameCollection.Rematch(executionContext, originalGame, playerIdentifiers[1])
+	if errorFromRematch != nil {
+		unitTest.Fatalf("Rematch(...) produced unexpected error %v", errorFromRematch)
+	}
+
+	statusOfDeletion := func(sessionToken string) int {
+		httpRequest :=
+			httptest.NewRequest(
+				http.MethodPost,
+				"/backend/game/delete-game",
+				strings.NewReader(`{"GameName":"`+rematchName+`"}`))
+		httpRequest.Header.Set("Authorization", "Bearer "+sessionToken)
+
+		responseRecorder := httptest.NewRecorder()
+		serverState.HandleBackend(responseRecorder, httpRequest)
+
+		return responseRecorder.Code
+	}
+
+	statusForGuest := statusOfDeletion(sessionTokens[1])
+	if statusForGuest != http.StatusForbidden {
+		unitTest.Fatalf(
+			"returned wrong status %v for deletion by guest instead of expected %v",
+			statusForGuest,
+			http.StatusForbidden)
+	}
+
+	statusForHost := statusOfDeletion(sessionTokens[0])
+	if statusForHost != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v for deletion by host instead of expected %v",
+			statusForHost,
+			http.StatusOK)
+	}
+
+	_, errorFromCreator := gameCollection.Creator(executionContext, rematchName)
+	if errorFromCreator == nil {
+		unitTest.Fatalf("Creator(%v) did not produce expected error after deletion", rematchName)
+	}
+}
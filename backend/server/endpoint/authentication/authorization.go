@@ -0,0 +1,264 @@
+package authentication
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+// contextKey is a private type so that the value stored in a context by this package
+// cannot collide with values stored by any other package.
+type contextKey int
+
+// authenticatedPlayerKey is the key for the name of the authenticated player.
+const authenticatedPlayerKey contextKey = 0
+
+// NotAuthenticatedError is returned when a request which has to be made on behalf of
+// a player has no valid session token.
+type NotAuthenticatedError struct {
+	message string
+}
+
+// Error returns the message of the error.
+func (notAuthenticatedError *NotAuthenticatedError) Error() string {
+	return notAuthenticatedError.message
+}
+
+// ErrorCode returns the code which identifies missing authentication to clients.
+func (notAuthenticatedError *NotAuthenticatedError) ErrorCode() string {
+	return failure.CodeNotAuthenticated
+}
+
+// ForbiddenError is returned when the authenticated player is not allowed to make a
+// request.
+type ForbiddenError struct {
+	message string
+}
+
+// Error returns the message of the error.
+func (forbiddenError *ForbiddenError) Error() string {
+	return forbiddenError.message
+}
+
+// ErrorCode returns the code which identifies forbidden requests to clients.
+func (forbiddenError *ForbiddenError) ErrorCode() string {
+	return failure.CodeForbidden
+}
+
+// NewForbiddenError creates a ForbiddenError with the given message.
+func NewForbiddenError(errorMessage string) error {
+	return &ForbiddenError{message: errorMessage}
+}
+
+// ContextWithAuthenticatedPlayer returns a context derived from the given context
+// which carries the identifier of the player whose session token came with the request.
+func ContextWithAuthenticatedPlayer(
+	requestContext context.Context,
+	playerIdentifier string) context.Context {
+	return context.WithValue(requestContext, authenticatedPlayerKey, playerIdentifier)
+}
+
+// AuthenticatedPlayer returns the identifier of the player stored in the given context
+// along with true, or an empty string along with false if there is none.
+func AuthenticatedPlayer(requestContext context.Context) (string, bool) {
+	playerIdentifier, hasPlayer := requestContext.Value(authenticatedPlayerKey).(string)
+
+	return playerIdentifier, hasPlayer && (playerIdentifier != "")
+}
+
+// StatusForError returns the HTTP status code which corresponds to the given error
+// from authorization: 401 if the request was not authenticated, 403 if the request
+// was authenticated but not allowed, and 400 for any other error.
+func StatusForError(errorFromAuthorization error) int {
+	switch errorFromAuthorization.(type) {
+	case *NotAuthenticatedError:
+		return http.StatusUnauthorized
+	case *ForbiddenError:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// RoleChecker defines the interface for something which can determine whether a
+// player has the role of administrator and whether a player has been deleted.
+type RoleChecker interface {
+	IsAdministrator(requestContext context.Context, playerIdentifier string) (bool, error)
+	IsDeleted(requestContext context.Context, playerIdentifier string) (bool, error)
+}
+
+// ContextAuthorizer checks requests against the player stored in the context of the
+// request. A ContextAuthorizer without a RoleChecker treats nobody as an administrator
+// and nobody as deleted.
+type ContextAuthorizer struct {
+	roleChecker RoleChecker
+}
+
+// NewContextAuthorizer creates a ContextAuthorizer which uses the given checker to
+// determine whether the authenticated player is an administrator and whether the
+// authenticated player has been deleted.
+func NewContextAuthorizer(roleChecker RoleChecker) *ContextAuthorizer {
+	return &ContextAuthorizer{
+		roleChecker: roleChecker,
+	}
+}
+
+// AuthorizeSession returns nil if the given player may still act with a session token,
+// and otherwise returns a NotAuthenticatedError, as the session tokens of a player who
+// has been deleted must not be accepted even if they have not yet expired.
+func (contextAuthorizer *ContextAuthorizer) AuthorizeSession(
+	requestContext context.Context,
+	playerIdentifier string) error {
+	if contextAuthorizer.roleChecker == nil {
+		return nil
+	}
+
+	isDeleted, errorFromCheck :=
+		contextAuthorizer.roleChecker.IsDeleted(requestContext, playerIdentifier)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	if isDeleted {
+		return &NotAuthenticatedError{
+			message: fmt.Sprintf("Player %v has been deleted", playerIdentifier),
+		}
+	}
+
+	return nil
+}
+
+// AuthorizeActingPlayer returns nil if the given context carries the given player as
+// the authenticated player, and otherwise returns a NotAuthenticatedError if there is
+// no authenticated player or if the authenticated player has been deleted, or a
+// ForbiddenError if the authenticated player is someone else.
+func (contextAuthorizer *ContextAuthorizer) AuthorizeActingPlayer(
+	requestContext context.Context,
+	playerIdentifier string) error {
+	authenticatedPlayer, errorFromAuthentication :=
+		contextAuthorizer.requireSession(requestContext)
+
+	if errorFromAuthentication != nil {
+		return errorFromAuthentication
+	}
+
+	if authenticatedPlayer != playerIdentifier {
+		return NewForbiddenError(
+			fmt.Sprintf(
+				"Player %v cannot act on behalf of player %v",
+				authenticatedPlayer,
+				playerIdentifier))
+	}
+
+	return nil
+}
+
+// AuthorizeActingPlayerOrAdministrator returns nil if the given context carries either
+// the given player or an administrator as the authenticated player, and otherwise
+// returns a NotAuthenticatedError if there is no authenticated player or if the
+// authenticated player has been deleted, or a ForbiddenError if the authenticated
+// player is someone else without the role of administrator.
+func (contextAuthorizer *ContextAuthorizer) AuthorizeActingPlayerOrAdministrator(
+	requestContext context.Context,
+	playerIdentifier string) error {
+	authenticatedPlayer, errorFromAuthentication :=
+		contextAuthorizer.requireSession(requestContext)
+
+	if errorFromAuthentication != nil {
+		return errorFromAuthentication
+	}
+
+	if authenticatedPlayer == playerIdentifier {
+		return nil
+	}
+
+	return contextAuthorizer.requireAdministrator(
+		requestContext,
+		authenticatedPlayer,
+		fmt.Sprintf(
+			"Player %v is neither player %v nor an administrator",
+			authenticatedPlayer,
+			playerIdentifier))
+}
+
+// AuthorizeAdministrator returns nil if the given context carries an administrator as
+// the authenticated player, and otherwise returns a NotAuthenticatedError if there is
+// no authenticated player or if the authenticated player has been deleted, or a
+// ForbiddenError if the authenticated player is not an administrator.
+func (contextAuthorizer *ContextAuthorizer) AuthorizeAdministrator(
+	requestContext context.Context) error {
+	authenticatedPlayer, errorFromAuthentication :=
+		contextAuthorizer.requireSession(requestContext)
+
+	if errorFromAuthentication != nil {
+		return errorFromAuthentication
+	}
+
+	return contextAuthorizer.requireAdministrator(
+		requestContext,
+		authenticatedPlayer,
+		fmt.Sprintf("Player %v is not an administrator", authenticatedPlayer))
+}
+
+// RequireAuthentication returns the identifier of the authenticated player of the given
+// context, or a NotAuthenticatedError if there is none.
+func RequireAuthentication(requestContext context.Context) (string, error) {
+	authenticatedPlayer, isAuthenticated := AuthenticatedPlayer(requestContext)
+
+	if !isAuthenticated {
+		return "", &NotAuthenticatedError{
+			message: "Request must be authenticated with a session token",
+		}
+	}
+
+	return authenticatedPlayer, nil
+}
+
+// requireSession returns the identifier of the authenticated player of the given
+// context, or a NotAuthenticatedError if there is none or if that player has been
+// deleted.
+func (contextAuthorizer *ContextAuthorizer) requireSession(
+	requestContext context.Context) (string, error) {
+	authenticatedPlayer, errorFromAuthentication := RequireAuthentication(requestContext)
+
+	if errorFromAuthentication != nil {
+		return "", errorFromAuthentication
+	}
+
+	errorFromSession :=
+		contextAuthorizer.AuthorizeSession(requestContext, authenticatedPlayer)
+
+	if errorFromSession != nil {
+		return "", errorFromSession
+	}
+
+	return authenticatedPlayer, nil
+}
+
+// requireAdministrator returns nil if the given player is an administrator, and
+// otherwise a ForbiddenError with the given message, or the error from checking the
+// role of the player.
+func (contextAuthorizer *ContextAuthorizer) requireAdministrator(
+	requestContext context.Context,
+	playerIdentifier string,
+	messageIfForbidden string) error {
+	if contextAuthorizer.roleChecker == nil {
+		return NewForbiddenError(messageIfForbidden)
+	}
+
+	isAdministrator, errorFromCheck :=
+		contextAuthorizer.roleChecker.IsAdministrator(requestContext, playerIdentifier)
+
+	if errorFromCheck != nil {
+		return errorFromCheck
+	}
+
+	if !isAdministrator {
+		return NewForbiddenError(messageIfForbidden)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package authentication
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tokenSegmentSeparator separates the encoded player identifier, the expiry time, and the
+// signature in a session token. It cannot appear in any of the segments because they
+// are encoded in URL-safe base 64 or as decimal digits.
+const tokenSegmentSeparator = "."
+
+// TokenSigner issues session tokens for players who have logged in, and verifies
+// the tokens which come back with requests. A token contains the identifier of the player
+// and the time at which it expires, signed with HMAC-SHA256 so that it cannot be
+// forged or altered without the signing key.
+type TokenSigner struct {
+	signingKey    []byte
+	tokenLifetime time.Duration
+}
+
+// NewTokenSigner creates a TokenSigner which signs tokens with the given key, with
+// each token being valid for the given duration after it was issued.
+func NewTokenSigner(signingKey []byte, tokenLifetime time.Duration) *TokenSigner {
+	keyCopy := make([]byte, len(signingKey))
+	copy(keyCopy, signingKey)
+
+	return &TokenSigner{
+		signingKey:    keyCopy,
+		tokenLifetime: tokenLifetime,
+	}
+}
+
+// RandomSigningKey returns a new random key of 32 bytes which is suitable for a
+// TokenSigner. Tokens signed with such a key become invalid once the server is
+// restarted, as the new server will have a different key.
+func RandomSigningKey() ([]byte, error) {
+	signingKey := make([]byte, 32)
+	_, errorFromRandom := rand.Read(signingKey)
+
+	if errorFromRandom != nil {
+		return nil, errorFromRandom
+	}
+
+	return signingKey, nil
+}
+
+// IssueToken returns a signed session token for the given player.
+func (tokenSigner *TokenSigner) IssueToken(playerIdentifier string) (string, error) {
+	if playerIdentifier == "" {
+		return "", fmt.Errorf("Cannot issue session token without player identifier")
+	}
+
+	expiryTime := time.Now().Add(tokenSigner.tokenLifetime).Unix()
+
+	unsignedToken :=
+		base64.RawURLEncoding.EncodeToString([]byte(playerIdentifier)) +
+			tokenSegmentSeparator +
+			strconv.FormatInt(expiryTime, 10)
+
+	return unsignedToken +
+		tokenSegmentSeparator +
+		tokenSigner.signatureFor(unsignedToken), nil
+}
+
+// PlayerFromToken returns the identifier of the player for whom the given token was
+// issued, or an error if the token is malformed, has an invalid signature, or has
+// expired.
+func (tokenSigner *TokenSigner) PlayerFromToken(sessionToken string) (string, error) {
+	tokenSegments := strings.Split(sessionToken, tokenSegmentSeparator)
+
+	if len(tokenSegments) != 3 {
+		return "", fmt.Errorf("Session token is malformed")
+	}
+
+	unsignedToken := tokenSegments[0] + tokenSegmentSeparator + tokenSegments[1]
+	expectedSignature := tokenSigner.signatureFor(unsignedToken)
+
+	if !hmac.Equal([]byte(expectedSignature), []byte(tokenSegments[2])) {
+		return "", fmt.Errorf("Session token has invalid signature")
+	}
+
+	expiryTime, errorFromParse := strconv.ParseInt(tokenSegments[1], 10, 64)
+
+	if errorFromParse != nil {
+		return "", fmt.Errorf("Session token has malformed expiry time")
+	}
+
+	if time.Now().Unix() > expiryTime {
+		return "", fmt.Errorf("Session token has expired")
+	}
+
+	playerIdentifierBytes, errorFromDecode :=
+		base64.RawURLEncoding.DecodeString(tokenSegments[0])
+
+	if errorFromDecode != nil {
+		return "", fmt.Errorf("Session token has malformed player identifier")
+	}
+
+	return string(playerIdentifierBytes), nil
+}
+
+// signatureFor returns the HMAC-SHA256 signature of the given string, encoded in
+// URL-safe base 64.
+func (tokenSigner *TokenSigner) signatureFor(unsignedToken string) string {
+	messageAuthenticator := hmac.New(sha256.New, tokenSigner.signingKey)
+	messageAuthenticator.Write([]byte(unsignedToken))
+
+	return base64.RawURLEncoding.EncodeToString(messageAuthenticator.Sum(nil))
+}
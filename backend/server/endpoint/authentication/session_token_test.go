@@ -0,0 +1,372 @@
+package authentication_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+)
+
+func TestIssuedTokenIdentifiesPlayer(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+
+	// The name deliberately contains the separator of the segments of a token.
+	expectedPlayer := "Player. With. Dots"
+
+	sessionToken, errorFromIssue := tokenSigner.IssueToken(expectedPlayer)
+	if errorFromIssue != nil {
+		unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+	}
+
+	actualPlayer, errorFromVerification := tokenSigner.PlayerFromToken(sessionToken)
+	if errorFromVerification != nil {
+		unitTest.Fatalf(
+			"PlayerFromToken(%v) produced unexpected error %v",
+			sessionToken,
+			errorFromVerification)
+	}
+
+	if actualPlayer != expectedPlayer {
+		unitTest.Fatalf(
+			"PlayerFromToken(%v) gave player %v, expected %v",
+			sessionToken,
+			actualPlayer,
+			expectedPlayer)
+	}
+}
+
+func TestRejectInvalidTokens(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+	otherSigner :=
+		authentication.NewTokenSigner([]byte("other signing key"), time.Hour)
+	expiredSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), -time.Minute)
+
+	validToken, _ := tokenSigner.IssueToken("Test Player")
+	tokenFromOtherSigner, _ := otherSigner.IssueToken("Test Player")
+	expiredToken, _ := expiredSigner.IssueToken("Test Player")
+	otherPlayerToken, _ := tokenSigner.IssueToken("Other Player")
+
+	validSegments := strings.Split(validToken, ".")
+	otherPlayerSegments := strings.Split(otherPlayerToken, ".")
+
+	testCases := []struct {
+		testName     string
+		sessionToken string
+	}{
+		{
+			testName:     "empty token",
+			sessionToken: "",
+		},
+		{
+			testName:     "too few segments",
+			sessionToken: validSegments[0] + "." + validSegments[1],
+		},
+		{
+			testName:     "signed with other key",
+			sessionToken: tokenFromOtherSigner,
+		},
+		{
+			testName:     "expired",
+			sessionToken: expiredToken,
+		},
+		{
+			testName: "player name swapped",
+			sessionToken: otherPlayerSegments[0] + "." +
+				validSegments[1] + "." +
+				validSegments[2],
+		},
+		{
+			testName: "expiry extended",
+			sessionToken: validSegments[0] + "." +
+				validSegments[1] + "0." +
+				validSegments[2],
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			actualPlayer, errorFromVerification :=
+				tokenSigner.PlayerFromToken(testCase.sessionToken)
+
+			if errorFromVerification == nil {
+				unitTest.Fatalf(
+					"PlayerFromToken(%v) did not produce expected error, instead gave player %v",
+					testCase.sessionToken,
+					actualPlayer)
+			}
+		})
+	}
+}
+
+func TestAuthorizeActingPlayer(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		requestContext context.Context
+		expectedStatus int
+	}{
+		{
+			testName:       "no authenticated player",
+			requestContext: context.Background(),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testName: "other player",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				"Other Player"),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	contextAuthorizer := &authentication.ContextAuthorizer{}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			errorFromAuthorization :=
+				contextAuthorizer.AuthorizeActingPlayer(testCase.requestContext, "Test Player")
+
+			if errorFromAuthorization == nil {
+				unitTest.Fatalf("AuthorizeActingPlayer(...) did not produce expected error")
+			}
+
+			actualStatus := authentication.StatusForError(errorFromAuthorization)
+			if actualStatus != testCase.expectedStatus {
+				unitTest.Fatalf(
+					"StatusForError(%v) gave %v, expected %v",
+					errorFromAuthorization,
+					actualStatus,
+					testCase.expectedStatus)
+			}
+		})
+	}
+
+	errorForSamePlayer :=
+		contextAuthorizer.AuthorizeActingPlayer(
+			authentication.ContextWithAuthenticatedPlayer(context.Background(), "Test Player"),
+			"Test Player")
+
+	if errorForSamePlayer != nil {
+		unitTest.Fatalf(
+			"AuthorizeActingPlayer(...) for same player produced unexpected error %v",
+			errorForSamePlayer)
+	}
+
+	if authentication.StatusForError(errors.New("other error")) != http.StatusBadRequest {
+		unitTest.Fatalf("StatusForError(...) for generic error was not %v", http.StatusBadRequest)
+	}
+}
+
+// mockRoleChecker treats only the players in its sets as administrators or as deleted,
+// or returns its error if it has one.
+type mockRoleChecker struct {
+	administratorNames map[string]bool
+	deletedNames       map[string]bool
+	ErrorToReturn      error
+}
+
+// IsAdministrator returns whether the given player is in the set of administrators.
+func (mockChecker *mockRoleChecker) IsAdministrator(
+	requestContext context.Context,
+	playerName string) (bool, error) {
+	if mockChecker.ErrorToReturn != nil {
+		return false, mockChecker.ErrorToReturn
+	}
+
+	return mockChecker.administratorNames[playerName], nil
+}
+
+// IsDeleted returns whether the given player is in the set of deleted players.
+func (mockChecker *mockRoleChecker) IsDeleted(
+	requestContext context.Context,
+	playerName string) (bool, error) {
+	if mockChecker.ErrorToReturn != nil {
+		return false, mockChecker.ErrorToReturn
+	}
+
+	return mockChecker.deletedNames[playerName], nil
+}
+
+func TestAuthorizeActingPlayerOrAdministrator(unitTest *testing.T) {
+	administratorChecker :=
+		&mockRoleChecker{
+			administratorNames: map[string]bool{"Test Administrator": true},
+		}
+
+	testCases := []struct {
+		testName            string
+		contextAuthorizer   *authentication.ContextAuthorizer
+		authenticatedPlayer string
+		expectedStatus      int
+		expectAuthorization bool
+		administratorOnly   bool
+	}{
+		{
+			testName:          "no authenticated player",
+			contextAuthorizer: authentication.NewContextAuthorizer(administratorChecker),
+			expectedStatus:    http.StatusUnauthorized,
+		},
+		{
+			testName:            "same player",
+			contextAuthorizer:   authentication.NewContextAuthorizer(administratorChecker),
+			authenticatedPlayer: "Test Player",
+			expectAuthorization: true,
+		},
+		{
+			testName:            "other player",
+			contextAuthorizer:   authentication.NewContextAuthorizer(administratorChecker),
+			authenticatedPlayer: "Other Player",
+			expectedStatus:      http.StatusForbidden,
+		},
+		{
+			testName:            "administrator",
+			contextAuthorizer:   authentication.NewContextAuthorizer(administratorChecker),
+			authenticatedPlayer: "Test Administrator",
+			expectAuthorization: true,
+		},
+		{
+			testName:            "administrator without checker",
+			contextAuthorizer:   &authentication.ContextAuthorizer{},
+			authenticatedPlayer: "Test Administrator",
+			expectedStatus:      http.StatusForbidden,
+		},
+		{
+			testName: "error from checker",
+			contextAuthorizer: authentication.NewContextAuthorizer(
+				&mockRoleChecker{ErrorToReturn: errors.New("expected error")}),
+			authenticatedPlayer: "Other Player",
+			expectedStatus:      http.StatusBadRequest,
+		},
+		{
+			testName:            "same player but administrator required",
+			contextAuthorizer:   authentication.NewContextAuthorizer(administratorChecker),
+			authenticatedPlayer: "Test Player",
+			expectedStatus:      http.StatusForbidden,
+			administratorOnly:   true,
+		},
+		{
+			testName:            "administrator required",
+			contextAuthorizer:   authentication.NewContextAuthorizer(administratorChecker),
+			authenticatedPlayer: "Test Administrator",
+			expectAuthorization: true,
+			administratorOnly:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			requestContext := context.Background()
+			if testCase.authenticatedPlayer != "" {
+				requestContext =
+					authentication.ContextWithAuthenticatedPlayer(
+						requestContext,
+						testCase.authenticatedPlayer)
+			}
+
+			var errorFromAuthorization error
+			if testCase.administratorOnly {
+				errorFromAuthorization =
+					testCase.contextAuthorizer.AuthorizeAdministrator(requestContext)
+			} else {
+				errorFromAuthorization =
+					testCase.contextAuthorizer.AuthorizeActingPlayerOrAdministrator(
+						requestContext,
+						"Test Player")
+			}
+
+			if testCase.expectAuthorization {
+				if errorFromAuthorization != nil {
+					unitTest.Fatalf(
+						"authorization produced unexpected error %v",
+						errorFromAuthorization)
+				}
+
+				return
+			}
+
+			if errorFromAuthorization == nil {
+				unitTest.Fatalf("authorization did not produce expected error")
+			}
+
+			actualStatus := authentication.StatusForError(errorFromAuthorization)
+			if actualStatus != testCase.expectedStatus {
+				unitTest.Fatalf(
+					"StatusForError(%v) gave %v, expected %v",
+					errorFromAuthorization,
+					actualStatus,
+					testCase.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestRejectDeletedPlayer(unitTest *testing.T) {
+	contextAuthorizer :=
+		authentication.NewContextAuthorizer(
+			&mockRoleChecker{
+				administratorNames: map[string]bool{"Deleted Player": true},
+				deletedNames:       map[string]bool{"Deleted Player": true},
+			})
+
+	requestContext :=
+		authentication.ContextWithAuthenticatedPlayer(
+			context.Background(),
+			"Deleted Player")
+
+	testCases := []struct {
+		testName  string
+		authorize func() error
+	}{
+		{
+			testName: "session",
+			authorize: func() error {
+				return contextAuthorizer.AuthorizeSession(requestContext, "Deleted Player")
+			},
+		},
+		{
+			testName: "acting player",
+			authorize: func() error {
+				return contextAuthorizer.AuthorizeActingPlayer(requestContext, "Deleted Player")
+			},
+		},
+		{
+			testName: "acting player or administrator",
+			authorize: func() error {
+				return contextAuthorizer.AuthorizeActingPlayerOrAdministrator(
+					requestContext,
+					"Deleted Player")
+			},
+		},
+		{
+			testName: "administrator",
+			authorize: func() error {
+				return contextAuthorizer.AuthorizeAdministrator(requestContext)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			errorFromAuthorization := testCase.authorize()
+
+			if errorFromAuthorization == nil {
+				unitTest.Fatalf("authorization of deleted player did not produce expected error")
+			}
+
+			actualStatus := authentication.StatusForError(errorFromAuthorization)
+			if actualStatus != http.StatusUnauthorized {
+				unitTest.Fatalf(
+					"StatusForError(%v) gave %v, expected %v",
+					errorFromAuthorization,
+					actualStatus,
+					http.StatusUnauthorized)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// newGameCollectionAndHandlerWithContextAuthorizer prepares a mock game collection
+// and uses it to prepare a handler which checks the acting player against the
+// player stored in the context of the request.
+func newGameCollectionAndHandlerWithContextAuthorizer() (
+	*mockGameCollection, *game_endpoint.Handler) {
+	mockCollection := &mockGameCollection{}
+
+	handlerForGame :=
+		game_endpoint.New(
+			mockCollection,
+			segmentTranslatorForTest(),
+			&authentication.ContextAuthorizer{},
+			&mockContactsProvider{},
+			&mockPresenceProvider{})
+
+	return mockCollection, handlerForGame
+}
+
+// mockAdministratorChecker treats only the player with the given name as an
+// administrator.
+type mockAdministratorChecker struct {
+	administratorName string
+}
+
+// IsAdministrator gets mocked.
+func (administratorChecker *mockAdministratorChecker) IsAdministrator(
+	requestContext context.Context,
+	playerName string) (bool, error) {
+	return playerName == administratorChecker.administratorName, nil
+}
+
+// IsDeleted gets mocked, treating nobody as deleted.
+func (administratorChecker *mockAdministratorChecker) IsDeleted(
+	requestContext context.Context,
+	playerName string) (bool, error) {
+	return false, nil
+}
+
+type contextAndExpectedCode struct {
+	contextDescription   string
+	requestContext       context.Context
+	expectedResponseCode int
+}
+
+func rejectingContexts() []contextAndExpectedCode {
+	return []contextAndExpectedCode{
+		contextAndExpectedCode{
+			contextDescription:   "no session",
+			requestContext:       context.Background(),
+			expectedResponseCode: http.StatusUnauthorized,
+		},
+		contextAndExpectedCode{
+			contextDescription: "session of other player",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				testPlayers[1]),
+			expectedResponseCode: http.StatusForbidden,
+		},
+	}
+}
+
+func TestRejectGetWithoutAuthenticationOfPlayer(unitTest *testing.T) {
+	gameSegment := segmentTranslatorForTest().ToSegment("test game")
+	playerSegment := segmentTranslatorForTest().ToSegment(testPlayers[0])
+
+	testCases := [][]string{
+		[]string{"all-games-with-player", playerSegment},
+		[]string{"pending-invitations-for-player", playerSegment},
+		[]string{"pending-lobbies-with-friends", playerSegment},
+		[]string{"game-as-seen-by-player", gameSegment, playerSegment},
+		[]string{"series-as-seen-by-player", gameSegment, playerSegment},
+		[]string{"game-as-seen-by-spectator", gameSegment, playerSegment},
+	}
+
+	for _, testSegments := range testCases {
+		for _, rejectingContext := range rejectingContexts() {
+			testIdentifier :=
+				"Reject GET " + testSegments[0] + "/" + rejectingContext.contextDescription
+
+			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+				mockCollection, testHandler := newGameCollectionAndHandlerWithContextAuthorizer()
+
+				_, responseCode :=
+					testHandler.HandleGet(rejectingContext.requestContext, testSegments)
+
+				if responseCode != rejectingContext.expectedResponseCode {
+					unitTest.Fatalf(
+						testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+						rejectingContext.expectedResponseCode,
+						responseCode)
+				}
+
+				assertNoFunctionWasCalled(
+					unitTest,
+					mockCollection.FunctionsAndArgumentsReceived,
+					testIdentifier)
+			})
+		}
+	}
+}
+
+func TestRejectPostWithoutAuthenticationOfPlayer(unitTest *testing.T) {
+	playerInGame :=
+		parsing.PlayerInGameIndication{
+			GameName:   "test game",
+			PlayerName: testPlayers[0],
+		}
+
+	testCases := []struct {
+		segment    string
+		bodyObject interface{}
+	}{
+		{
+			segment: "create-new-game",
+			bodyObject: parsing.GameDefinition{
+				GameName:    "test game",
+				PlayerNames: testPlayers,
+			},
+		},
+		{
+			segment: "create-new-lobby",
+			bodyObject: parsing.LobbyDefinition{
+				GameName: "test game",
+				HostName: testPlayers[0],
+			},
+		},
+		{segment: "accept-invitation", bodyObject: playerInGame},
+		{segment: "join-lobby", bodyObject: playerInGame},
+		{segment: "start-game-from-lobby", bodyObject: playerInGame},
+		{segment: "rematch", bodyObject: playerInGame},
+		{segment: "leave-game", bodyObject: playerInGame},
+		{
+			segment: "record-chat-message",
+			bodyObject: parsing.PlayerChatMessage{
+				PlayerInGameIndication: playerInGame,
+				ChatMessage:            "Hello",
+			},
+		},
+		{
+			segment: "take-turn-by-discarding",
+			bodyObject: parsing.PlayerCardIndication{
+				PlayerInGameIndication: playerInGame,
+				CardIndex:              0,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, rejectingContext := range rejectingContexts() {
+			testIdentifier :=
+				"Reject POST " + testCase.segment + "/" + rejectingContext.contextDescription
+
+			unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+				mockCollection, testHandler := newGameCollectionAndHandlerWithContextAuthorizer()
+
+				bodyDecoder :=
+					DecoderAroundInterface(unitTest, testIdentifier, testCase.bodyObject)
+
+				_, responseCode :=
+					testHandler.HandlePost(
+						rejectingContext.requestContext,
+						bodyDecoder,
+						[]string{testCase.segment})
+
+				if responseCode != rejectingContext.expectedResponseCode {
+					unitTest.Fatalf(
+						testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+						rejectingContext.expectedResponseCode,
+						responseCode)
+				}
+
+				assertNoFunctionWasCalled(
+					unitTest,
+					mockCollection.FunctionsAndArgumentsReceived,
+					testIdentifier)
+			})
+		}
+	}
+}
+
+func TestAcceptPostWithAuthenticationOfPlayer(unitTest *testing.T) {
+	testIdentifier := "POST join-lobby with session of player"
+	mockCollection, testHandler := newGameCollectionAndHandlerWithContextAuthorizer()
+
+	bodyObject :=
+		parsing.PlayerInGameIndication{
+			GameName:   "test game",
+			PlayerName: testPlayers[0],
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				testPlayers[0]),
+			bodyDecoder,
+			[]string{"join-lobby"})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName: "JoinLobby",
+			FunctionArgument: stringPair{
+				first:  bodyObject.GameName,
+				second: bodyObject.PlayerName,
+			},
+		},
+		testIdentifier)
+}
+
+func TestRejectDeleteGameIfCreatorNotFound(unitTest *testing.T) {
+	testIdentifier := "Reject POST delete-game if creator not found"
+	mockCollection, testHandler := newGameCollectionAndHandlerWithContextAuthorizer()
+	mockCollection.ErrorForCreator = errors.New("expected error")
+
+	bodyObject :=
+		parsing.GameDefinition{
+			GameName: "test game",
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				testPlayers[0]),
+			bodyDecoder,
+			[]string{"delete-game"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "Creator",
+			FunctionArgument: bodyObject.GameName,
+		},
+		testIdentifier)
+}
+
+func TestAuthorizeDeleteGameForCreatorOrAdministrator(unitTest *testing.T) {
+	gameName := "test game"
+	gameCreator := testPlayers[0]
+	administratorName := "Test Administrator"
+
+	creatorRecord :=
+		functionNameAndArgument{
+			FunctionName:     "Creator",
+			FunctionArgument: gameName,
+		}
+
+	deleteRecord :=
+		functionNameAndArgument{
+			FunctionName:     "Delete",
+			FunctionArgument: gameName,
+		}
+
+	testCases := []struct {
+		contextDescription   string
+		requestContext       context.Context
+		expectedResponseCode int
+		expectedRecords      []functionNameAndArgument
+	}{
+		{
+			contextDescription:   "no session",
+			requestContext:       context.Background(),
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedRecords:      []functionNameAndArgument{creatorRecord},
+		},
+		{
+			contextDescription: "session of other player",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				testPlayers[1]),
+			expectedResponseCode: http.StatusForbidden,
+			expectedRecords:      []functionNameAndArgument{creatorRecord},
+		},
+		{
+			contextDescription: "session of creator",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				gameCreator),
+			expectedResponseCode: http.StatusOK,
+			expectedRecords:      []functionNameAndArgument{creatorRecord, deleteRecord},
+		},
+		{
+			contextDescription: "session of administrator",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				administratorName),
+			expectedResponseCode: http.StatusOK,
+			expectedRecords:      []functionNameAndArgument{creatorRecord, deleteRecord},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST delete-game/" + testCase.contextDescription
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockGameCollection{}
+			mockCollection.ReturnForCreator = gameCreator
+
+			testHandler :=
+				game_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					authentication.NewContextAuthorizer(
+						&mockAdministratorChecker{administratorName: administratorName}),
+					&mockContactsProvider{},
+					&mockPresenceProvider{})
+
+			bodyObject :=
+				parsing.GameDefinition{
+					GameName: gameName,
+				}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					testCase.requestContext,
+					bodyDecoder,
+					[]string{"delete-game"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedRecords,
+				testIdentifier)
+		})
+	}
+}
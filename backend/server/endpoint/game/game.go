@@ -7,11 +7,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
 )
 
@@ -22,15 +24,24 @@ import (
 type Handler struct {
 	stateCollection   StateCollection
 	segmentTranslator parsing.SegmentTranslator
+	playerAuthorizer  PlayerAuthorizer
+	contactsProvider  ContactsProvider
+	presenceProvider  PresenceProvider
 }
 
 // New returns a pointer to a new Handler.
 func New(
 	collectionOfStates StateCollection,
-	translatorForSegments parsing.SegmentTranslator) *Handler {
+	translatorForSegments parsing.SegmentTranslator,
+	authorizerForPlayers PlayerAuthorizer,
+	providerOfContacts ContactsProvider,
+	providerOfPresence PresenceProvider) *Handler {
 	return &Handler{
 		stateCollection:   collectionOfStates,
 		segmentTranslator: translatorForSegments,
+		playerAuthorizer:  authorizerForPlayers,
+		contactsProvider:  providerOfContacts,
+		presenceProvider:  providerOfPresence,
 	}
 }
 
@@ -48,8 +59,41 @@ func (handler *Handler) HandleGet(
 		return handler.writeAvailableRulesets(requestContext)
 	case "all-games-with-player":
 		return handler.writeTurnSummariesForPlayer(requestContext, relevantSegments[1:])
+	case "pending-invitations-for-player":
+		return handler.writeInvitationsForPlayer(requestContext, relevantSegments[1:])
 	case "game-as-seen-by-player":
 		return handler.writeGameForPlayer(requestContext, relevantSegments[1:])
+	case "series-as-seen-by-player":
+		return handler.writeSeriesForPlayer(requestContext, relevantSegments[1:])
+	case "pending-lobbies":
+		return handler.writePendingLobbies(requestContext)
+	case "pending-lobbies-with-friends":
+		return handler.writePendingLobbiesWithFriends(requestContext, relevantSegments[1:])
+	case "game-as-seen-by-spectator":
+		return handler.writeGameForSpectator(requestContext, relevantSegments[1:])
+	case "player-statistics":
+		return handler.writePlayerStatistics(requestContext, relevantSegments[1:])
+	default:
+		return "URI segment " + relevantSegments[0] + " not valid", http.StatusNotFound
+	}
+}
+
+// HandleStream parses an HTTP GET request for a stream of updates and sends each
+// update through the given function until the context is done or there is an error,
+// returning the body for the request along with the HTTP response code once there
+// are no more updates.
+// This implements github.com/benoleary/ilutulestikud/server.httpEventStreamHandler.
+func (handler *Handler) HandleStream(
+	requestContext context.Context,
+	relevantSegments []string,
+	sendUpdate func(interface{}) error) (interface{}, int) {
+	if len(relevantSegments) < 1 {
+		return "Not enough segments in URI to determine what to do", http.StatusBadRequest
+	}
+
+	switch relevantSegments[0] {
+	case "game-as-seen-by-player":
+		return handler.streamGameForPlayer(requestContext, relevantSegments[1:], sendUpdate)
 	default:
 		return "URI segment " + relevantSegments[0] + " not valid", http.StatusNotFound
 	}
@@ -68,6 +112,18 @@ func (handler *Handler) HandlePost(
 	switch relevantSegments[0] {
 	case "create-new-game":
 		return handler.handleNewGame(requestContext, httpBodyDecoder)
+	case "accept-invitation":
+		return handler.handleAcceptInvitation(requestContext, httpBodyDecoder)
+	case "decline-invitation":
+		return handler.handleDeclineInvitation(requestContext, httpBodyDecoder)
+	case "create-new-lobby":
+		return handler.handleNewLobby(requestContext, httpBodyDecoder)
+	case "join-lobby":
+		return handler.handleJoinLobby(requestContext, httpBodyDecoder)
+	case "leave-lobby":
+		return handler.handleLeaveLobby(requestContext, httpBodyDecoder)
+	case "start-game-from-lobby":
+		return handler.handleStartGameFromLobby(requestContext, httpBodyDecoder)
 	case "record-chat-message":
 		return handler.handleRecordChatMessage(requestContext, httpBodyDecoder)
 	case "take-turn-by-discarding":
@@ -78,10 +134,18 @@ func (handler *Handler) HandlePost(
 		return handler.handleTakeTurnByHintingColor(requestContext, httpBodyDecoder)
 	case "take-turn-by-hinting-number":
 		return handler.handleTakeTurnByHintingNumber(requestContext, httpBodyDecoder)
+	case "rematch":
+		return handler.handleRematch(requestContext, httpBodyDecoder)
+	case "set-spectating":
+		return handler.handleSetSpectating(requestContext, httpBodyDecoder)
+	case "record-spectator-chat-message":
+		return handler.handleRecordSpectatorChatMessage(requestContext, httpBodyDecoder)
 	case "leave-game":
 		return handler.handleLeaveGame(requestContext, httpBodyDecoder)
 	case "delete-game":
 		return handler.handleDeleteGame(requestContext, httpBodyDecoder)
+	case "leaderboard":
+		return handler.writeLeaderboard(requestContext, httpBodyDecoder)
 	default:
 		return "URI segment " + relevantSegments[0] + " not valid", http.StatusNotFound
 	}
@@ -132,14 +196,21 @@ func (handler *Handler) writeTurnSummariesForPlayer(
 		handler.segmentTranslator.FromSegment(playerIdentifier)
 
 	if errorFromIdentification != nil {
-		return errorFromIdentification, http.StatusBadRequest
+		return errorFromIdentification,
+			parsing.StatusForError(errorFromIdentification, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
 	}
 
 	allGamesWithPlayer, errorFromView :=
 		handler.stateCollection.ViewAllWithPlayer(requestContext, playerName)
 
 	if errorFromView != nil {
-		return errorFromView, http.StatusBadRequest
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusBadRequest)
 	}
 
 	numberOfGamesWithPlayer := len(allGamesWithPlayer)
@@ -153,6 +224,7 @@ func (handler *Handler) writeTurnSummariesForPlayer(
 			GameIdentifier: handler.segmentTranslator.ToSegment(gameView.GameName()),
 			GameName:       gameView.GameName(),
 			IsPlayerTurn:   playerTurnIndex == 0,
+			Version:        gameView.Version(),
 		}
 	}
 
@@ -160,10 +232,77 @@ func (handler *Handler) writeTurnSummariesForPlayer(
 		TurnSummaries: turnSummaries,
 	}
 
+	// The versions of the games are part of the body, so the hash of the body is enough
+	// to identify it.
+	return parsing.NewTaggedBody(endpointObject, ""), http.StatusOK
+}
+
+// writeInvitationsForPlayer writes a JSON object into the HTTP response which has
+// the list of invitation summary objects as its "Invitations" attribute.
+func (handler *Handler) writeInvitationsForPlayer(
+	requestContext context.Context,
+	relevantSegments []string) (interface{}, int) {
+	if len(relevantSegments) < 1 {
+		return "Not enough segments in URI to determine player", http.StatusBadRequest
+	}
+
+	playerIdentifier := relevantSegments[0]
+
+	playerName, errorFromIdentification :=
+		handler.segmentTranslator.FromSegment(playerIdentifier)
+
+	if errorFromIdentification != nil {
+		return errorFromIdentification,
+			parsing.StatusForError(errorFromIdentification, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	invitationLobbies, errorFromView :=
+		handler.stateCollection.ViewInvitationsForPlayer(requestContext, playerName)
+
+	if errorFromView != nil {
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusBadRequest)
+	}
+
+	numberOfInvitations := len(invitationLobbies)
+
+	invitationSummaries := make([]parsing.InvitationSummary, numberOfInvitations)
+
+	for invitationIndex := 0; invitationIndex < numberOfInvitations; invitationIndex++ {
+		invitationLobby := invitationLobbies[invitationIndex]
+
+		rulesetDescription := ""
+		lobbyRuleset, errorFromRuleset :=
+			game.RulesetFromIdentifier(invitationLobby.RulesetIdentifier)
+		if errorFromRuleset == nil {
+			rulesetDescription = lobbyRuleset.FrontendDescription()
+		}
+
+		invitationSummaries[invitationIndex] = parsing.InvitationSummary{
+			GameIdentifier:      handler.segmentTranslator.ToSegment(invitationLobby.GameName),
+			GameName:            invitationLobby.GameName,
+			RulesetDescription:  rulesetDescription,
+			HostName:            invitationLobby.HostName,
+			InvitedPlayerNames:  invitationLobby.InvitedPlayerNames,
+			AcceptedPlayerNames: invitationLobby.SeatedPlayerNames,
+		}
+	}
+
+	endpointObject := parsing.InvitationList{
+		Invitations: invitationSummaries,
+	}
+
 	return endpointObject, http.StatusOK
 }
 
-// handleNewGame adds a new game to the map of game state objects.
+// handleNewGame invites the players of a new game, which will be added to the map of
+// game state objects once all the invited players have accepted. If the definition
+// names a group of the host, the members of the group are invited.
 func (handler *Handler) handleNewGame(
 	requestContext context.Context,
 	httpBodyDecoder *json.Decoder) (interface{}, int) {
@@ -174,21 +313,39 @@ func (handler *Handler) handleNewGame(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, gameDefinition.HostName())
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	gameRuleset, unknownRulesetError :=
 		game.RulesetFromIdentifier(gameDefinition.RulesetIdentifier)
 	if unknownRulesetError != nil {
-		return unknownRulesetError, http.StatusBadRequest
+		return unknownRulesetError,
+			parsing.StatusForError(unknownRulesetError, http.StatusBadRequest)
+	}
+
+	invitedPlayers := gameDefinition.PlayerNames
+	if gameDefinition.GroupName != "" {
+		playersFromGroup, errorFromGroup :=
+			handler.hostAndGroupMembers(requestContext, gameDefinition)
+		if errorFromGroup != nil {
+			return errorFromGroup, parsing.StatusForError(errorFromGroup, http.StatusBadRequest)
+		}
+
+		invitedPlayers = playersFromGroup
 	}
 
 	errorFromAdd :=
-		handler.stateCollection.AddNew(
+		handler.stateCollection.InviteToNew(
 			requestContext,
 			gameDefinition.GameName,
 			gameRuleset,
-			gameDefinition.PlayerNames)
+			invitedPlayers)
 
 	if errorFromAdd != nil {
-		return errorFromAdd, http.StatusBadRequest
+		return errorFromAdd, parsing.StatusForError(errorFromAdd, http.StatusBadRequest)
 	}
 
 	gameIdentifier := handler.segmentTranslator.ToSegment(gameDefinition.GameName)
@@ -203,6 +360,333 @@ func (handler *Handler) handleNewGame(
 	return "OK", http.StatusOK
 }
 
+// hostAndGroupMembers returns the host of the given game definition followed by the
+// members of the group of the host named by the definition.
+func (handler *Handler) hostAndGroupMembers(
+	requestContext context.Context,
+	gameDefinition parsing.GameDefinition) ([]string, error) {
+	if len(gameDefinition.PlayerNames) != 1 {
+		return nil, fmt.Errorf(
+			"A game created from group %v should list only the host, not %v",
+			gameDefinition.GroupName,
+			gameDefinition.PlayerNames)
+	}
+
+	hostName := gameDefinition.HostName()
+	hostContacts, errorFromContacts :=
+		handler.contactsProvider.Contacts(requestContext, hostName)
+	if errorFromContacts != nil {
+		return nil, errorFromContacts
+	}
+
+	hostGroup, hasGroup := hostContacts.GroupWithName(gameDefinition.GroupName)
+	if !hasGroup {
+		return nil, fmt.Errorf(
+			"Player %v has no group %v",
+			hostName,
+			gameDefinition.GroupName)
+	}
+
+	return append([]string{hostName}, hostGroup.MemberIdentifiers...), nil
+}
+
+// writePendingLobbies writes a JSON object into the HTTP response which has the list
+// of lobby summary objects as its "Lobbies" attribute.
+func (handler *Handler) writePendingLobbies(
+	requestContext context.Context) (interface{}, int) {
+	allLobbies, errorFromRead :=
+		handler.stateCollection.AllLobbies(requestContext)
+
+	if errorFromRead != nil {
+		return errorFromRead, parsing.StatusForError(errorFromRead, http.StatusInternalServerError)
+	}
+
+	return handler.lobbyListForEndpoint(allLobbies), http.StatusOK
+}
+
+// writePendingLobbiesWithFriends writes a JSON object into the HTTP response as
+// writePendingLobbies would, but only with the lobbies in which the host or a seated
+// player is a friend of the player given by the segment.
+func (handler *Handler) writePendingLobbiesWithFriends(
+	requestContext context.Context,
+	relevantSegments []string) (interface{}, int) {
+	if len(relevantSegments) < 1 {
+		return "Not enough segments in URI to determine player", http.StatusBadRequest
+	}
+
+	playerName, errorFromIdentification :=
+		handler.segmentTranslator.FromSegment(relevantSegments[0])
+
+	if errorFromIdentification != nil {
+		return errorFromIdentification,
+			parsing.StatusForError(errorFromIdentification, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	playerContacts, errorFromContacts :=
+		handler.contactsProvider.Contacts(requestContext, playerName)
+	if errorFromContacts != nil {
+		return errorFromContacts, parsing.StatusForError(errorFromContacts, http.StatusBadRequest)
+	}
+
+	allLobbies, errorFromRead :=
+		handler.stateCollection.AllLobbies(requestContext)
+
+	if errorFromRead != nil {
+		return errorFromRead, parsing.StatusForError(errorFromRead, http.StatusInternalServerError)
+	}
+
+	lobbiesWithFriends := make([]game.Lobby, 0, len(allLobbies))
+	for _, pendingLobby := range allLobbies {
+		hasFriend := playerContacts.IsFriend(pendingLobby.HostName)
+		for _, seatedPlayer := range pendingLobby.SeatedPlayerNames {
+			hasFriend = hasFriend || playerContacts.IsFriend(seatedPlayer)
+		}
+
+		if hasFriend {
+			lobbiesWithFriends = append(lobbiesWithFriends, pendingLobby)
+		}
+	}
+
+	return handler.lobbyListForEndpoint(lobbiesWithFriends), http.StatusOK
+}
+
+// lobbyListForEndpoint converts the given lobbies into the form which is sent to the
+// frontend.
+func (handler *Handler) lobbyListForEndpoint(allLobbies []game.Lobby) parsing.LobbyList {
+	numberOfLobbies := len(allLobbies)
+
+	lobbySummaries := make([]parsing.LobbySummary, numberOfLobbies)
+
+	for lobbyIndex := 0; lobbyIndex < numberOfLobbies; lobbyIndex++ {
+		pendingLobby := allLobbies[lobbyIndex]
+
+		rulesetDescription := ""
+		lobbyRuleset, errorFromRuleset :=
+			game.RulesetFromIdentifier(pendingLobby.RulesetIdentifier)
+		if errorFromRuleset == nil {
+			rulesetDescription = lobbyRuleset.FrontendDescription()
+		}
+
+		lobbySummaries[lobbyIndex] = parsing.LobbySummary{
+			GameIdentifier:     handler.segmentTranslator.ToSegment(pendingLobby.GameName),
+			GameName:           pendingLobby.GameName,
+			RulesetIdentifier:  pendingLobby.RulesetIdentifier,
+			RulesetDescription: rulesetDescription,
+			HostName:           pendingLobby.HostName,
+			NumberOfSeats:      pendingLobby.NumberOfSeats,
+			SeatedPlayerNames:  pendingLobby.SeatedPlayerNames,
+		}
+	}
+
+	return parsing.LobbyList{
+		Lobbies: lobbySummaries,
+	}
+}
+
+// handleNewLobby adds a new lobby with open seats for a game which is yet to be dealt.
+func (handler *Handler) handleNewLobby(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var lobbyDefinition parsing.LobbyDefinition
+
+	errorFromParse := httpBodyDecoder.Decode(&lobbyDefinition)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, lobbyDefinition.HostName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	gameIdentifier := handler.segmentTranslator.ToSegment(lobbyDefinition.GameName)
+
+	if strings.Contains(gameIdentifier, "/") {
+		errorMessage := fmt.Sprintf(
+			"Server set up with encoding which cannot convert %v to identifier with '/' in it",
+			lobbyDefinition.GameName)
+		return errorMessage, http.StatusBadRequest
+	}
+
+	gameRuleset, unknownRulesetError :=
+		game.RulesetFromIdentifier(lobbyDefinition.RulesetIdentifier)
+	if unknownRulesetError != nil {
+		return unknownRulesetError,
+			parsing.StatusForError(unknownRulesetError, http.StatusBadRequest)
+	}
+
+	errorFromAdd :=
+		handler.stateCollection.AddLobby(
+			requestContext,
+			lobbyDefinition.GameName,
+			gameRuleset,
+			lobbyDefinition.NumberOfSeats,
+			lobbyDefinition.HostName)
+
+	if errorFromAdd != nil {
+		return errorFromAdd, parsing.StatusForError(errorFromAdd, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// handleJoinLobby passes on the given game name and player name to the collection so
+// that the player takes an open seat in the lobby for the game.
+func (handler *Handler) handleJoinLobby(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var joiningInformation parsing.PlayerInGameIndication
+
+	errorFromParse := httpBodyDecoder.Decode(&joiningInformation)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, joiningInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromJoining :=
+		handler.stateCollection.JoinLobby(
+			requestContext,
+			joiningInformation.GameName,
+			joiningInformation.PlayerName)
+	if errorFromJoining != nil {
+		return errorFromJoining, parsing.StatusForError(errorFromJoining, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// handleLeaveLobby passes on the given game name and player name to the collection so
+// that the seat of the player in the lobby for the game is freed.
+func (handler *Handler) handleLeaveLobby(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var leavingInformation parsing.PlayerInGameIndication
+
+	errorFromParse := httpBodyDecoder.Decode(&leavingInformation)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, leavingInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromLeaving :=
+		handler.stateCollection.LeaveLobby(
+			requestContext,
+			leavingInformation.GameName,
+			leavingInformation.PlayerName)
+	if errorFromLeaving != nil {
+		return errorFromLeaving, parsing.StatusForError(errorFromLeaving, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// handleStartGameFromLobby passes on the given game name and player name to the
+// collection so that the game is dealt for the players seated in the lobby.
+func (handler *Handler) handleStartGameFromLobby(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var startingInformation parsing.PlayerInGameIndication
+
+	errorFromParse := httpBodyDecoder.Decode(&startingInformation)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, startingInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromStarting :=
+		handler.stateCollection.StartGameFromLobby(
+			requestContext,
+			startingInformation.GameName,
+			startingInformation.PlayerName)
+	if errorFromStarting != nil {
+		return errorFromStarting, parsing.StatusForError(errorFromStarting, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// handleAcceptInvitation passes on the given game name and player name to the
+// collection so that the player accepts the invitation to the game.
+func (handler *Handler) handleAcceptInvitation(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var acceptingInformation parsing.PlayerInGameIndication
+
+	errorFromParse := httpBodyDecoder.Decode(&acceptingInformation)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, acceptingInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromAccepting :=
+		handler.stateCollection.AcceptInvitation(
+			requestContext,
+			acceptingInformation.GameName,
+			acceptingInformation.PlayerName)
+	if errorFromAccepting != nil {
+		return errorFromAccepting, parsing.StatusForError(errorFromAccepting, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// handleDeclineInvitation passes on the given game name and player name to the
+// collection so that the player declines the invitation to the game.
+func (handler *Handler) handleDeclineInvitation(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var decliningInformation parsing.PlayerInGameIndication
+
+	errorFromParse := httpBodyDecoder.Decode(&decliningInformation)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, decliningInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromDeclining :=
+		handler.stateCollection.DeclineInvitation(
+			requestContext,
+			decliningInformation.GameName,
+			decliningInformation.PlayerName)
+	if errorFromDeclining != nil {
+		return errorFromDeclining, parsing.StatusForError(errorFromDeclining, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
 // writeGameForPlayer writes a JSON representation of the current state of the game
 // with the given name for the player with the given name.
 func (handler *Handler) writeGameForPlayer(
@@ -210,53 +694,80 @@ func (handler *Handler) writeGameForPlayer(
 	relevantSegments []string) (interface{}, int) {
 	gameName, playerName, errorFromParsing := handler.parseGameAndPlayer(relevantSegments)
 	if errorFromParsing != nil {
-		return errorFromParsing, http.StatusBadRequest
+		return errorFromParsing, parsing.StatusForError(errorFromParsing, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
 	}
 
 	gameView, errorFromView :=
 		handler.stateCollection.ViewState(requestContext, gameName, playerName)
 	if errorFromView != nil {
-		return errorFromView, http.StatusInternalServerError
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusInternalServerError)
 	}
 
-	handsBeforeThisPlayer, handsAfterThisPlayer, isViewingPlayerTurn, errorFromVisibleHands :=
-		handler.visibleHandsBeforeAndAfter(gameView)
-	if errorFromVisibleHands != nil {
-		return errorFromVisibleHands, http.StatusInternalServerError
+	endpointObject, errorFromConversion :=
+		handler.GameViewForFrontend(requestContext, gameView, playerName)
+	if errorFromConversion != nil {
+		return errorFromConversion,
+			parsing.StatusForError(errorFromConversion, http.StatusInternalServerError)
 	}
 
-	handOfThisPlayer, errorFromInferredHand :=
-		handler.playerKnowledgeOfHand(
-			gameView,
-			playerName)
-	if errorFromInferredHand != nil {
-		return errorFromInferredHand, http.StatusInternalServerError
+	// The presence and display names of the players can change without the version of
+	// the game changing, so the tag has to depend on the whole body, but starting it
+	// with the version makes it easy to see which state of the game it describes.
+	return parsing.NewTaggedBody(endpointObject, strconv.Itoa(gameView.Version())), http.StatusOK
+}
+
+// streamGameForPlayer sends the game given by the segments as seen by the given player,
+// as written by writeGameForPlayer, first straight away and then again after every
+// change to the game, so that the hidden cards of the player are still filtered out of
+// every update. It stops when the context is done, or when the game can no longer be
+// viewed, such as when it has been deleted, returning the reason.
+func (handler *Handler) streamGameForPlayer(
+	requestContext context.Context,
+	relevantSegments []string,
+	sendUpdate func(interface{}) error) (interface{}, int) {
+	gameName, playerName, errorFromParsing := handler.parseGameAndPlayer(relevantSegments)
+	if errorFromParsing != nil {
+		return errorFromParsing, parsing.StatusForError(errorFromParsing, http.StatusBadRequest)
 	}
 
-	gameIsFinished := gameView.GameIsFinished()
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
 
-	endpointObject :=
-		parsing.GameView{
-			ChatLog:                            handler.logForFrontend(gameView.ChatLog()),
-			ActionLog:                          handler.logForFrontend(gameView.ActionLog()),
-			GameIsFinished:                     gameIsFinished,
-			ScoreSoFar:                         gameView.Score(),
-			NumberOfReadyHints:                 gameView.NumberOfReadyHints(),
-			MaximumNumberOfHints:               gameView.MaximumNumberOfHints(),
-			HintColorSuits:                     gameView.ColorsAvailableAsHint(),
-			HintSequenceIndices:                gameView.IndicesAvailableAsHint(),
-			NumberOfMistakesMade:               gameView.NumberOfMistakesMade(),
-			NumberOfMistakesIndicatingGameOver: gameView.NumberOfMistakesIndicatingGameOver(),
-			NumberOfCardsLeftInDeck:            gameView.DeckSize(),
-			PlayedCards:                        playedCards(gameView.PlayedCards()),
-			DiscardedCards:                     cardsForFrontend(gameView.DiscardedCards()),
-			HandsBeforeThisPlayer:              handsBeforeThisPlayer,
-			HandOfThisPlayer:                   handOfThisPlayer,
-			HandsAfterThisPlayer:               handsAfterThisPlayer,
-			ThisPlayerCanTakeTurn:              !gameIsFinished && isViewingPlayerTurn,
+	// The subscription is made before the first view is written so that no change
+	// after the first view can be missed.
+	changeNotices, unsubscribe :=
+		handler.stateCollection.SubscribeToChanges(requestContext, gameName)
+	defer unsubscribe()
+
+	for {
+		endpointObject, httpStatus :=
+			handler.writeGameForPlayer(requestContext, relevantSegments)
+
+		if httpStatus != http.StatusOK {
+			return endpointObject, httpStatus
 		}
 
-	return endpointObject, http.StatusOK
+		errorFromSend := sendUpdate(endpointObject)
+		if errorFromSend != nil {
+			return errorFromSend,
+				parsing.StatusForError(errorFromSend, http.StatusInternalServerError)
+		}
+
+		select {
+		case <-requestContext.Done():
+			return nil, http.StatusOK
+		case <-changeNotices:
+		}
+	}
 }
 
 // handleRecordChatMessage passes on the given chat message to the relevant game.
@@ -270,6 +781,12 @@ func (handler *Handler) handleRecordChatMessage(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerChatMessage.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	actionExecutor, errorFromExecutor :=
 		handler.stateCollection.ExecuteAction(
 			requestContext,
@@ -277,7 +794,7 @@ func (handler *Handler) handleRecordChatMessage(
 			playerChatMessage.PlayerName)
 
 	if errorFromExecutor != nil {
-		return errorFromExecutor, http.StatusBadRequest
+		return errorFromExecutor, parsing.StatusForError(errorFromExecutor, http.StatusBadRequest)
 	}
 
 	errorFromRecordChatMessage :=
@@ -286,7 +803,8 @@ func (handler *Handler) handleRecordChatMessage(
 			playerChatMessage.ChatMessage)
 
 	if errorFromRecordChatMessage != nil {
-		return errorFromRecordChatMessage, http.StatusInternalServerError
+		return errorFromRecordChatMessage,
+			parsing.StatusForError(errorFromRecordChatMessage, http.StatusInternalServerError)
 	}
 
 	return "OK", http.StatusOK
@@ -304,6 +822,12 @@ func (handler *Handler) handleTakeTurnByDiscarding(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerCardIndication.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	actionExecutor, errorFromExecutor :=
 		handler.stateCollection.ExecuteAction(
 			requestContext,
@@ -311,16 +835,18 @@ func (handler *Handler) handleTakeTurnByDiscarding(
 			playerCardIndication.PlayerName)
 
 	if errorFromExecutor != nil {
-		return errorFromExecutor, http.StatusBadRequest
+		return errorFromExecutor, parsing.StatusForError(errorFromExecutor, http.StatusBadRequest)
 	}
 
 	errorFromTakeTurnByDiscarding :=
 		actionExecutor.TakeTurnByDiscarding(
 			requestContext,
+			playerCardIndication.ExpectedTurn,
 			playerCardIndication.CardIndex)
 
 	if errorFromTakeTurnByDiscarding != nil {
-		return errorFromTakeTurnByDiscarding, http.StatusBadRequest
+		return errorFromTakeTurnByDiscarding,
+			parsing.StatusForError(errorFromTakeTurnByDiscarding, http.StatusBadRequest)
 	}
 
 	return "OK", http.StatusOK
@@ -338,6 +864,12 @@ func (handler *Handler) handleTakeTurnByAttemptingToPlay(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerCardIndication.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	actionExecutor, errorFromExecutor :=
 		handler.stateCollection.ExecuteAction(
 			requestContext,
@@ -345,16 +877,18 @@ func (handler *Handler) handleTakeTurnByAttemptingToPlay(
 			playerCardIndication.PlayerName)
 
 	if errorFromExecutor != nil {
-		return errorFromExecutor, http.StatusBadRequest
+		return errorFromExecutor, parsing.StatusForError(errorFromExecutor, http.StatusBadRequest)
 	}
 
 	errorFromTakeTurnByPlaying :=
 		actionExecutor.TakeTurnByPlaying(
 			requestContext,
+			playerCardIndication.ExpectedTurn,
 			playerCardIndication.CardIndex)
 
 	if errorFromTakeTurnByPlaying != nil {
-		return errorFromTakeTurnByPlaying, http.StatusBadRequest
+		return errorFromTakeTurnByPlaying,
+			parsing.StatusForError(errorFromTakeTurnByPlaying, http.StatusBadRequest)
 	}
 
 	return "OK", http.StatusOK
@@ -372,6 +906,12 @@ func (handler *Handler) handleTakeTurnByHintingColor(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerColorHint.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	actionExecutor, errorFromExecutor :=
 		handler.stateCollection.ExecuteAction(
 			requestContext,
@@ -379,17 +919,19 @@ func (handler *Handler) handleTakeTurnByHintingColor(
 			playerColorHint.PlayerName)
 
 	if errorFromExecutor != nil {
-		return errorFromExecutor, http.StatusBadRequest
+		return errorFromExecutor, parsing.StatusForError(errorFromExecutor, http.StatusBadRequest)
 	}
 
 	errorFromTakeTurnByHinting :=
 		actionExecutor.TakeTurnByHintingColor(
 			requestContext,
+			playerColorHint.ExpectedTurn,
 			playerColorHint.ReceiverName,
 			playerColorHint.HintedColor)
 
 	if errorFromTakeTurnByHinting != nil {
-		return errorFromTakeTurnByHinting, http.StatusBadRequest
+		return errorFromTakeTurnByHinting,
+			parsing.StatusForError(errorFromTakeTurnByHinting, http.StatusBadRequest)
 	}
 
 	return "OK", http.StatusOK
@@ -407,6 +949,12 @@ func (handler *Handler) handleTakeTurnByHintingNumber(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerIndexHint.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	actionExecutor, errorFromExecutor :=
 		handler.stateCollection.ExecuteAction(
 			requestContext,
@@ -414,17 +962,482 @@ func (handler *Handler) handleTakeTurnByHintingNumber(
 			playerIndexHint.PlayerName)
 
 	if errorFromExecutor != nil {
-		return errorFromExecutor, http.StatusBadRequest
+		return errorFromExecutor, parsing.StatusForError(errorFromExecutor, http.StatusBadRequest)
 	}
 
 	errorFromTakeTurnByHinting :=
 		actionExecutor.TakeTurnByHintingIndex(
 			requestContext,
+			playerIndexHint.ExpectedTurn,
 			playerIndexHint.ReceiverName,
 			playerIndexHint.HintedNumber)
 
 	if errorFromTakeTurnByHinting != nil {
-		return errorFromTakeTurnByHinting, http.StatusBadRequest
+		return errorFromTakeTurnByHinting,
+			parsing.StatusForError(errorFromTakeTurnByHinting, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// writeSeriesForPlayer writes a JSON representation of the summaries of the games in
+// the series which contains the game with the given name for the player with the
+// given name.
+func (handler *Handler) writeSeriesForPlayer(
+	requestContext context.Context,
+	relevantSegments []string) (interface{}, int) {
+	gameName, playerName, errorFromParsing := handler.parseGameAndPlayer(relevantSegments)
+	if errorFromParsing != nil {
+		return errorFromParsing, parsing.StatusForError(errorFromParsing, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	gameViews, errorFromView :=
+		handler.stateCollection.ViewSeries(requestContext, gameName, playerName)
+	if errorFromView != nil {
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusBadRequest)
+	}
+
+	numberOfGames := len(gameViews)
+	gameSummaries := make([]parsing.SeriesGameSummary, numberOfGames)
+	numberOfFinishedGames := 0
+	totalScore := 0
+	totalFinishedScore := 0
+
+	for gameIndex := 0; gameIndex < numberOfGames; gameIndex++ {
+		gameView := gameViews[gameIndex]
+		gameIsFinished := gameView.GameIsFinished()
+		gameScore := gameView.Score()
+
+		totalScore += gameScore
+
+		if gameIsFinished {
+			numberOfFinishedGames++
+			totalFinishedScore += gameScore
+		}
+
+		gameSummaries[gameIndex] = parsing.SeriesGameSummary{
+			GameIdentifier: handler.segmentTranslator.ToSegment(gameView.GameName()),
+			GameName:       gameView.GameName(),
+			GameIsFinished: gameIsFinished,
+			ScoreSoFar:     gameScore,
+		}
+	}
+
+	endpointObject := parsing.SeriesView{
+		Games:                 gameSummaries,
+		NumberOfFinishedGames: numberOfFinishedGames,
+		TotalScore:            totalScore,
+		TotalFinishedScore:    totalFinishedScore,
+	}
+
+	return endpointObject, http.StatusOK
+}
+
+// writePlayerStatistics writes a JSON representation of the statistics of the player
+// given by the segment over all the finished games in which the player was a
+// participant. The statistics are not secret, so any player may see the statistics of
+// any other player.
+func (handler *Handler) writePlayerStatistics(
+	requestContext context.Context,
+	relevantSegments []string) (interface{}, int) {
+	if len(relevantSegments) < 1 {
+		return "Not enough segments in URI to determine player", http.StatusBadRequest
+	}
+
+	playerName, errorFromIdentification :=
+		handler.segmentTranslator.FromSegment(relevantSegments[0])
+
+	if errorFromIdentification != nil {
+		return errorFromIdentification,
+			parsing.StatusForError(errorFromIdentification, http.StatusBadRequest)
+	}
+
+	playerStatistics, errorFromStatistics :=
+		handler.stateCollection.StatisticsForPlayer(requestContext, playerName)
+
+	if errorFromStatistics != nil {
+		return errorFromStatistics,
+			parsing.StatusForError(errorFromStatistics, http.StatusBadRequest)
+	}
+
+	numberOfCombinations := len(playerStatistics.ByRulesetAndPlayerCount)
+	statisticsForCombinations :=
+		make([]parsing.StatisticsForRulesetAndPlayerCount, numberOfCombinations)
+
+	for combinationIndex := 0; combinationIndex < numberOfCombinations; combinationIndex++ {
+		statisticsForCombination :=
+			playerStatistics.ByRulesetAndPlayerCount[combinationIndex]
+
+		rulesetDescription := ""
+		combinationRuleset, errorFromRuleset :=
+			game.RulesetFromIdentifier(statisticsForCombination.RulesetIdentifier)
+		if errorFromRuleset == nil {
+			rulesetDescription = combinationRuleset.FrontendDescription()
+		}
+
+		statisticsForCombinations[combinationIndex] =
+			parsing.StatisticsForRulesetAndPlayerCount{
+				RulesetIdentifier:     statisticsForCombination.RulesetIdentifier,
+				RulesetDescription:    rulesetDescription,
+				NumberOfPlayers:       statisticsForCombination.NumberOfPlayers,
+				NumberOfGamesFinished: statisticsForCombination.NumberOfGamesFinished,
+				NumberOfPerfectGames:  statisticsForCombination.NumberOfPerfectGames,
+				AverageScore:          statisticsForCombination.AverageScore,
+				BestScore:             statisticsForCombination.BestScore,
+				MaximumScore:          statisticsForCombination.MaximumScore,
+			}
+	}
+
+	endpointObject := parsing.PlayerStatistics{
+		PlayerIdentifier:                         relevantSegments[0],
+		NumberOfGamesPlayed:                      playerStatistics.NumberOfGamesPlayed,
+		NumberOfGamesFinished:                    playerStatistics.NumberOfGamesFinished,
+		DiscardRate:                              playerStatistics.DiscardRate,
+		MistakeRate:                              playerStatistics.MistakeRate,
+		CurrentStreakWithoutGameOverFromMistakes: playerStatistics.CurrentStreakWithoutGameOverFromMistakes,
+		LongestStreakWithoutGameOverFromMistakes: playerStatistics.LongestStreakWithoutGameOverFromMistakes,
+		ByRulesetAndPlayerCount:                  statisticsForCombinations,
+	}
+
+	return endpointObject, http.StatusOK
+}
+
+// writeLeaderboard writes a JSON representation of the leaderboard for the ruleset,
+// number of players, and range of time given in the body of the request. Leaderboards
+// are not secret, so the request does not need to come from any particular player. The
+// request is a POST only because the criteria are given in a JSON body.
+func (handler *Handler) writeLeaderboard(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var leaderboardRequest parsing.LeaderboardRequest
+
+	errorFromParse := httpBodyDecoder.Decode(&leaderboardRequest)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	gameCriteria := game.FinishedGameCriteria{
+		RulesetIdentifier: leaderboardRequest.RulesetIdentifier,
+		NumberOfPlayers:   leaderboardRequest.NumberOfPlayers,
+		FinishedFrom:      leaderboardRequest.FinishedFrom,
+		FinishedBefore:    leaderboardRequest.FinishedBefore,
+	}
+
+	gameLeaderboard, errorFromLeaderboard :=
+		handler.stateCollection.Leaderboard(
+			requestContext,
+			gameCriteria,
+			leaderboardRequest.MaximumNumberOfEntries)
+
+	if errorFromLeaderboard != nil {
+		return errorFromLeaderboard,
+			parsing.StatusForError(errorFromLeaderboard, http.StatusBadRequest)
+	}
+
+	// The collection has already checked that the ruleset exists.
+	leaderboardRuleset, _ := game.RulesetFromIdentifier(gameCriteria.RulesetIdentifier)
+
+	endpointObject := parsing.Leaderboard{
+		RulesetIdentifier:   gameCriteria.RulesetIdentifier,
+		RulesetDescription:  leaderboardRuleset.FrontendDescription(),
+		NumberOfPlayers:     gameCriteria.NumberOfPlayers,
+		HighestScores:       leaderboardEntriesForBody(gameLeaderboard.HighestScores),
+		FastestPerfectGames: leaderboardEntriesForBody(gameLeaderboard.FastestPerfectGames),
+	}
+
+	return endpointObject, http.StatusOK
+}
+
+// leaderboardEntriesForBody converts the given leaderboard entries into the form which
+// is written into HTTP responses, keeping their order.
+func leaderboardEntriesForBody(
+	leaderboardEntries []game.LeaderboardEntry) []parsing.LeaderboardEntry {
+	entriesForBody := make([]parsing.LeaderboardEntry, len(leaderboardEntries))
+
+	for entryIndex, leaderboardEntry := range leaderboardEntries {
+		entriesForBody[entryIndex] = parsing.LeaderboardEntry{
+			GameName:           leaderboardEntry.GameName,
+			PlayerIdentifiers:  leaderboardEntry.PlayerNames,
+			PlayerNames:        leaderboardEntry.PlayerDisplayNames,
+			Score:              leaderboardEntry.Score,
+			NumberOfTurnsTaken: leaderboardEntry.NumberOfTurnsTaken,
+			ElapsedSeconds:     int64(leaderboardEntry.ElapsedTime.Seconds()),
+			TimeOfFinish:       leaderboardEntry.TimeOfFinish,
+		}
+	}
+
+	return entriesForBody
+}
+
+// handleRematch passes on the given game name and player name to the collection so
+// that a rematch of the game is created, and writes a turn summary of the new game
+// into the HTTP response.
+func (handler *Handler) handleRematch(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var rematchInformation parsing.PlayerInGameIndication
+
+	errorFromParse := httpBodyDecoder.Decode(&rematchInformation)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, rematchInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	rematchName, errorFromRematch :=
+		handler.stateCollection.Rematch(
+			requestContext,
+			rematchInformation.GameName,
+			rematchInformation.PlayerName)
+	if errorFromRematch != nil {
+		return errorFromRematch, parsing.StatusForError(errorFromRematch, http.StatusBadRequest)
+	}
+
+	rematchView, errorFromView :=
+		handler.stateCollection.ViewState(
+			requestContext,
+			rematchName,
+			rematchInformation.PlayerName)
+	if errorFromView != nil {
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusInternalServerError)
+	}
+
+	_, playerTurnIndex, _ := rematchView.CurrentTurnOrder()
+
+	endpointObject := parsing.TurnSummary{
+		GameIdentifier: handler.segmentTranslator.ToSegment(rematchName),
+		GameName:       rematchName,
+		IsPlayerTurn:   playerTurnIndex == 0,
+	}
+
+	return endpointObject, http.StatusOK
+}
+
+// writeGameForSpectator writes a JSON representation of the game with the given name
+// for the spectator with the given name. If a third segment gives the name of a
+// participant, the spectator sees the game from the seat of that participant, and
+// otherwise the spectator sees the hands of all the participants, possibly as they
+// were some turns ago. In both cases, the chat log is that of the spectators.
+func (handler *Handler) writeGameForSpectator(
+	requestContext context.Context,
+	relevantSegments []string) (interface{}, int) {
+	gameName, spectatorName, errorFromParsing := handler.parseGameAndPlayer(relevantSegments)
+	if errorFromParsing != nil {
+		return errorFromParsing, parsing.StatusForError(errorFromParsing, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, spectatorName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	spectatorChatLog, errorFromChatLog :=
+		handler.stateCollection.SpectatorChatLog(requestContext, gameName, spectatorName)
+	if errorFromChatLog != nil {
+		return errorFromChatLog, parsing.StatusForError(errorFromChatLog, http.StatusBadRequest)
+	}
+
+	if len(relevantSegments) > 2 {
+		participantName, errorFromIdentification :=
+			handler.segmentTranslator.FromSegment(relevantSegments[2])
+		if errorFromIdentification != nil {
+			return errorFromIdentification,
+				parsing.StatusForError(errorFromIdentification, http.StatusBadRequest)
+		}
+
+		return handler.writeGameForSpectatorFromSeat(
+			requestContext,
+			gameName,
+			spectatorName,
+			participantName,
+			spectatorChatLog)
+	}
+
+	neutralSnapshot, errorFromView :=
+		handler.stateCollection.ViewAsSpectator(requestContext, gameName, spectatorName)
+	if errorFromView != nil {
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusBadRequest)
+	}
+
+	maximumNumberOfHints := 0
+	numberOfMistakesIndicatingGameOver := 0
+	gameRuleset, errorFromRuleset :=
+		game.RulesetFromIdentifier(neutralSnapshot.RulesetIdentifier)
+	if errorFromRuleset == nil {
+		maximumNumberOfHints = gameRuleset.MaximumNumberOfHints()
+		numberOfMistakesIndicatingGameOver =
+			gameRuleset.NumberOfMistakesIndicatingGameOver()
+	}
+
+	numberOfPlayers := len(neutralSnapshot.HandsInTurnOrder)
+	handsInTurnOrder := make([]parsing.VisibleHand, numberOfPlayers)
+
+	for playerIndex, spectatedHand := range neutralSnapshot.HandsInTurnOrder {
+		numberOfCardsInHand := len(spectatedHand.HandCards)
+		handCards := make([]parsing.VisibleCard, numberOfCardsInHand)
+		knowledgeOfOwnHand := make([]parsing.CardFromBehind, numberOfCardsInHand)
+
+		for cardIndex, cardInHand := range spectatedHand.HandCards {
+			handCards[cardIndex] = parsing.VisibleCard{
+				ColorSuit:     cardInHand.ColorSuit,
+				SequenceIndex: cardInHand.SequenceIndex,
+			}
+
+			knowledgeOfOwnHand[cardIndex] = parsing.CardFromBehind{
+				PossibleColorSuits:      cardInHand.PossibleColors,
+				PossibleSequenceIndices: cardInHand.PossibleIndices,
+			}
+		}
+
+		// Snapshots taken before players had display names separate from their
+		// identifiers have no display name, but then the identifier was the name.
+		displayName := spectatedHand.PlayerDisplayName
+		if displayName == "" {
+			displayName = spectatedHand.PlayerName
+		}
+
+		// The presence is always current, even if the hands are from some turns ago.
+		handPresence :=
+			handler.presenceProvider.Presence(requestContext, spectatedHand.PlayerName)
+
+		handsInTurnOrder[playerIndex] = parsing.VisibleHand{
+			PlayerIdentifier:    spectatedHand.PlayerName,
+			PlayerName:          displayName,
+			PlayerColor:         spectatedHand.PlayerColor,
+			PlayerPresence:      handPresence.Status,
+			PlayerIsViewingGame: handPresence.IsViewingGame(gameName),
+			HandCards:           handCards,
+			KnowledgeOfOwnHand:  knowledgeOfOwnHand,
+			PlayerHasTakenLastTurn: (playerIndex +
+				neutralSnapshot.TurnsTakenWithEmptyDeck) >= numberOfPlayers,
+		}
+	}
+
+	endpointObject :=
+		parsing.SpectatorView{
+			ChatLog:                            handler.logForFrontend(spectatorChatLog),
+			ActionLog:                          handler.logForFrontend(neutralSnapshot.ActionLog),
+			TurnOfView:                         neutralSnapshot.Turn,
+			GameIsFinished:                     neutralSnapshot.GameIsFinished,
+			ScoreSoFar:                         neutralSnapshot.Score,
+			NumberOfReadyHints:                 neutralSnapshot.NumberOfReadyHints,
+			MaximumNumberOfHints:               maximumNumberOfHints,
+			NumberOfMistakesMade:               neutralSnapshot.NumberOfMistakesMade,
+			NumberOfMistakesIndicatingGameOver: numberOfMistakesIndicatingGameOver,
+			NumberOfCardsLeftInDeck:            neutralSnapshot.DeckSize,
+			PlayedCards:                        playedCards(neutralSnapshot.PlayedCards),
+			DiscardedCards:                     cardsForFrontend(neutralSnapshot.DiscardedCards),
+			HandsInTurnOrder:                   handsInTurnOrder,
+		}
+
+	return endpointObject, http.StatusOK
+}
+
+// writeGameForSpectatorFromSeat writes a JSON representation of the game with the
+// given name as seen by the given participant, for the given spectator, with the
+// given chat log of the spectators instead of the chat log of the participants.
+func (handler *Handler) writeGameForSpectatorFromSeat(
+	requestContext context.Context,
+	gameName string,
+	spectatorName string,
+	participantName string,
+	spectatorChatLog []message.FromPlayer) (interface{}, int) {
+	gameView, errorFromView :=
+		handler.stateCollection.ViewAsSpectatorFromSeat(
+			requestContext,
+			gameName,
+			spectatorName,
+			participantName)
+	if errorFromView != nil {
+		return errorFromView, parsing.StatusForError(errorFromView, http.StatusBadRequest)
+	}
+
+	endpointObject, errorFromConversion :=
+		handler.GameViewForFrontend(requestContext, gameView, participantName)
+	if errorFromConversion != nil {
+		return errorFromConversion,
+			parsing.StatusForError(errorFromConversion, http.StatusInternalServerError)
+	}
+
+	endpointObject.ChatLog = handler.logForFrontend(spectatorChatLog)
+
+	// The spectator can never take a turn, even when it is the turn of the player
+	// in whose seat the spectator is watching.
+	endpointObject.ThisPlayerCanTakeTurn = false
+
+	return endpointObject, http.StatusOK
+}
+
+// handleSetSpectating passes on the spectating settings for the relevant game.
+func (handler *Handler) handleSetSpectating(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var spectatingSettings parsing.SpectatingSettings
+
+	errorFromParse := httpBodyDecoder.Decode(&spectatingSettings)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, spectatingSettings.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromSet :=
+		handler.stateCollection.SetSpectating(
+			requestContext,
+			spectatingSettings.GameName,
+			spectatingSettings.PlayerName,
+			spectatingSettings.SpectatingIsAllowed,
+			spectatingSettings.DelayInTurns)
+
+	if errorFromSet != nil {
+		return errorFromSet, parsing.StatusForError(errorFromSet, http.StatusBadRequest)
+	}
+
+	return "OK", http.StatusOK
+}
+
+// handleRecordSpectatorChatMessage passes on the given chat message from a spectator
+// to the chat log of the spectators of the relevant game.
+func (handler *Handler) handleRecordSpectatorChatMessage(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var spectatorChatMessage parsing.PlayerChatMessage
+
+	errorFromParse := httpBodyDecoder.Decode(&spectatorChatMessage)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, spectatorChatMessage.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromRecord :=
+		handler.stateCollection.RecordSpectatorChatMessage(
+			requestContext,
+			spectatorChatMessage.GameName,
+			spectatorChatMessage.PlayerName,
+			spectatorChatMessage.ChatMessage)
+
+	if errorFromRecord != nil {
+		return errorFromRecord, parsing.StatusForError(errorFromRecord, http.StatusBadRequest)
 	}
 
 	return "OK", http.StatusOK
@@ -442,20 +1455,28 @@ func (handler *Handler) handleLeaveGame(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, leavingInformation.PlayerName)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	errorFromLeaving :=
 		handler.stateCollection.RemoveGameFromListForPlayer(
 			requestContext,
 			leavingInformation.GameName,
 			leavingInformation.PlayerName)
 	if errorFromLeaving != nil {
-		return errorFromLeaving, http.StatusInternalServerError
+		return errorFromLeaving,
+			parsing.StatusForError(errorFromLeaving, http.StatusInternalServerError)
 	}
 
 	return "OK", http.StatusOK
 }
 
 // handleDeleteGame passes on the given game name to the collection so that the game can
-// be deleted.
+// be deleted, as long as the request comes from the creator of the game or from an
+// administrator.
 func (handler *Handler) handleDeleteGame(
 	requestContext context.Context,
 	httpBodyDecoder *json.Decoder) (interface{}, int) {
@@ -466,15 +1487,79 @@ func (handler *Handler) handleDeleteGame(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	gameCreator, errorFromCreator :=
+		handler.stateCollection.Creator(requestContext, gameToDelete.GameName)
+	if errorFromCreator != nil {
+		return errorFromCreator, parsing.StatusForError(errorFromCreator, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayerOrAdministrator(
+			requestContext,
+			gameCreator)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	errorFromDeletion :=
 		handler.stateCollection.Delete(requestContext, gameToDelete.GameName)
 	if errorFromDeletion != nil {
-		return errorFromDeletion, http.StatusInternalServerError
+		return errorFromDeletion,
+			parsing.StatusForError(errorFromDeletion, http.StatusInternalServerError)
 	}
 
 	return "OK", http.StatusOK
 }
 
+// GameViewForFrontend converts the given view of a game into the form which is sent
+// to the frontend, for the given viewing player. It is exported so that the handler of
+// the second version of the API sends games in the same form.
+func (handler *Handler) GameViewForFrontend(
+	requestContext context.Context,
+	gameView game.ViewForPlayer,
+	playerName string) (parsing.GameView, error) {
+	handsBeforeThisPlayer, handsAfterThisPlayer, isViewingPlayerTurn, errorFromVisibleHands :=
+		handler.visibleHandsBeforeAndAfter(requestContext, gameView)
+	if errorFromVisibleHands != nil {
+		return parsing.GameView{}, errorFromVisibleHands
+	}
+
+	handOfThisPlayer, errorFromInferredHand :=
+		handler.playerKnowledgeOfHand(
+			gameView,
+			playerName)
+	if errorFromInferredHand != nil {
+		return parsing.GameView{}, errorFromInferredHand
+	}
+
+	gameIsFinished := gameView.GameIsFinished()
+
+	endpointObject :=
+		parsing.GameView{
+			Version:                            gameView.Version(),
+			Turn:                               gameView.Turn(),
+			ChatLog:                            handler.logForFrontend(gameView.ChatLog()),
+			ActionLog:                          handler.logForFrontend(gameView.ActionLog()),
+			GameIsFinished:                     gameIsFinished,
+			ScoreSoFar:                         gameView.Score(),
+			NumberOfReadyHints:                 gameView.NumberOfReadyHints(),
+			MaximumNumberOfHints:               gameView.MaximumNumberOfHints(),
+			HintColorSuits:                     gameView.ColorsAvailableAsHint(),
+			HintSequenceIndices:                gameView.IndicesAvailableAsHint(),
+			NumberOfMistakesMade:               gameView.NumberOfMistakesMade(),
+			NumberOfMistakesIndicatingGameOver: gameView.NumberOfMistakesIndicatingGameOver(),
+			NumberOfCardsLeftInDeck:            gameView.DeckSize(),
+			PlayedCards:                        playedCards(gameView.PlayedCards()),
+			DiscardedCards:                     cardsForFrontend(gameView.DiscardedCards()),
+			HandsBeforeThisPlayer:              handsBeforeThisPlayer,
+			HandOfThisPlayer:                   handOfThisPlayer,
+			HandsAfterThisPlayer:               handsAfterThisPlayer,
+			ThisPlayerCanTakeTurn:              !gameIsFinished && isViewingPlayerTurn,
+		}
+
+	return endpointObject, nil
+}
+
 func (handler *Handler) parseGameAndPlayer(
 	relevantSegments []string) (string, string, error) {
 	if len(relevantSegments) < 2 {
@@ -520,6 +1605,7 @@ func (handler *Handler) logForFrontend(
 }
 
 func (handler *Handler) visibleHandsBeforeAndAfter(
+	requestContext context.Context,
 	gameView game.ViewForPlayer) ([]parsing.VisibleHand, []parsing.VisibleHand, bool, error) {
 	playersInTurnOrder, playerIndexInTurnOrder, numberOfLastTurns :=
 		gameView.CurrentTurnOrder()
@@ -557,10 +1643,16 @@ func (handler *Handler) visibleHandsBeforeAndAfter(
 			playerHasTakenLastTurn :=
 				(playerIndex + numberOfLastTurns) >= numberOfPlayers
 
+			handPresence :=
+				handler.presenceProvider.Presence(requestContext, playerWithVisibleHand)
+
 			visibleHandForFrontend :=
 				parsing.VisibleHand{
-					PlayerName:             playerWithVisibleHand,
+					PlayerIdentifier:       playerWithVisibleHand,
+					PlayerName:             gameView.ParticipantDisplayName(playerWithVisibleHand),
 					PlayerColor:            playerChatColor,
+					PlayerPresence:         handPresence.Status,
+					PlayerIsViewingGame:    handPresence.IsViewingGame(gameView.GameName()),
 					HandCards:              handCards,
 					KnowledgeOfOwnHand:     knowledgeOfOwnHand,
 					PlayerHasTakenLastTurn: playerHasTakenLastTurn,
@@ -12,12 +12,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/benoleary/ilutulestikud/backend/defaults"
 	game_state "github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
+	player_state "github.com/benoleary/ilutulestikud/backend/player"
 	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
 )
@@ -54,11 +56,33 @@ func newGameCollectionAndHandlerForTranslator(
 	segmentTranslator parsing.SegmentTranslator) (*mockGameCollection, *game_endpoint.Handler) {
 	mockCollection := &mockGameCollection{}
 
-	handlerForGame := game_endpoint.New(mockCollection, segmentTranslator)
+	handlerForGame :=
+		game_endpoint.New(
+			mockCollection,
+			segmentTranslator,
+			&mockAuthorizer{},
+			&mockContactsProvider{},
+			&mockPresenceProvider{})
 
 	return mockCollection, handlerForGame
 }
 
+// bodyFromTagged returns the body wrapped by the given object, failing the test if
+// the object is not a parsing.TaggedBody with a non-empty tag.
+func bodyFromTagged(
+	unitTest *testing.T,
+	testIdentifier string,
+	returnedInterface interface{}) interface{} {
+	taggedBody, isTagged := returnedInterface.(parsing.TaggedBody)
+	if !isTagged || (taggedBody.EntityTag == "") {
+		unitTest.Fatalf(
+			testIdentifier+"/received %+v instead of expected parsing.TaggedBody with a tag",
+			returnedInterface)
+	}
+
+	return taggedBody.Body
+}
+
 func TestGetGameNilFutherSegmentSliceBadRequest(unitTest *testing.T) {
 	testIdentifier := "GET with nil segment slice after game"
 	mockCollection, testHandler := newGameCollectionAndHandler()
@@ -370,7 +394,7 @@ func TestGetAllGamesWithPlayerWhenEmptyList(unitTest *testing.T) {
 	}
 
 	responseTurnSummaryList, isInterfaceCorrect :=
-		returnedInterface.(parsing.TurnSummaryList)
+		bodyFromTagged(unitTest, testIdentifier, returnedInterface).(parsing.TurnSummaryList)
 
 	if !isInterfaceCorrect {
 		unitTest.Fatalf(
@@ -406,6 +430,7 @@ func TestGetAllGamesWithPlayerWhenThreeGames(unitTest *testing.T) {
 	firstTestView := NewMockView()
 	firstTestView.MockPlayers = testPlayers
 	firstTestView.MockPlayerTurnIndex = 0
+	firstTestView.MockVersion = 3
 
 	secondTestView := NewMockView()
 	secondTestView.MockPlayers =
@@ -414,6 +439,7 @@ func TestGetAllGamesWithPlayerWhenThreeGames(unitTest *testing.T) {
 			testPlayers[0],
 		}
 	secondTestView.MockPlayerTurnIndex = 1
+	secondTestView.MockVersion = 1
 
 	thirdTestView := NewMockView()
 	thirdTestView.MockPlayers =
@@ -422,6 +448,7 @@ func TestGetAllGamesWithPlayerWhenThreeGames(unitTest *testing.T) {
 			testPlayers[1],
 		}
 	thirdTestView.MockPlayerTurnIndex = 2
+	thirdTestView.MockVersion = 7
 
 	expectedViews :=
 		[]game_state.ViewForPlayer{
@@ -462,7 +489,7 @@ func TestGetAllGamesWithPlayerWhenThreeGames(unitTest *testing.T) {
 		testIdentifier)
 
 	responseTurnSummaryList, isInterfaceCorrect :=
-		returnedInterface.(parsing.TurnSummaryList)
+		bodyFromTagged(unitTest, testIdentifier, returnedInterface).(parsing.TurnSummaryList)
 
 	if !isInterfaceCorrect {
 		unitTest.Fatalf(
@@ -488,7 +515,8 @@ func TestGetAllGamesWithPlayerWhenThreeGames(unitTest *testing.T) {
 			_, expectedPlayerTurnIndex, _ := expectedView.CurrentTurnOrder()
 			if (actualTurnSummary.GameIdentifier == expectedIdentifier) &&
 				(actualTurnSummary.GameName == expectedView.GameName()) &&
-				(actualTurnSummary.IsPlayerTurn == (expectedPlayerTurnIndex == 0)) {
+				(actualTurnSummary.IsPlayerTurn == (expectedPlayerTurnIndex == 0)) &&
+				(actualTurnSummary.Version == expectedView.Version()) {
 				foundGame = true
 			}
 		}
@@ -749,6 +777,8 @@ func TestGetGameForPlayer(unitTest *testing.T) {
 		}
 	testView.MockChatLog = expectedChatLog
 	testView.MockPlayerTurnIndex = 1
+	testView.MockTurn = 2
+	testView.MockVersion = 5
 	testView.ReturnForVisibleHand =
 		[]card.Defined{
 			card.Defined{ColorSuit: "some color",
@@ -832,7 +862,8 @@ func TestGetGameForPlayer(unitTest *testing.T) {
 		},
 		testIdentifier)
 
-	responseGameView, isInterfaceCorrect := returnedInterface.(parsing.GameView)
+	responseGameView, isInterfaceCorrect :=
+		bodyFromTagged(unitTest, testIdentifier, returnedInterface).(parsing.GameView)
 
 	if !isInterfaceCorrect {
 		unitTest.Fatalf(
@@ -840,6 +871,23 @@ func TestGetGameForPlayer(unitTest *testing.T) {
 			returnedInterface)
 	}
 
+	entityTag := returnedInterface.(parsing.TaggedBody).EntityTag
+	if (responseGameView.Version != testView.MockVersion) ||
+		!strings.HasPrefix(entityTag, "5-") {
+		unitTest.Fatalf(
+			testIdentifier+"/version %v and tag %v did not match expected version %v",
+			responseGameView.Version,
+			entityTag,
+			testView.MockVersion)
+	}
+
+	if responseGameView.Turn != testView.MockTurn {
+		unitTest.Fatalf(
+			testIdentifier+"/turn %v did not match expected turn %v",
+			responseGameView.Turn,
+			testView.MockTurn)
+	}
+
 	numberOfExpectedMessages := len(expectedChatLog)
 
 	if len(responseGameView.ChatLog) != numberOfExpectedMessages {
@@ -925,6 +973,92 @@ func TestGetGameForPlayer(unitTest *testing.T) {
 	}
 }
 
+func TestGetGameForPlayerShowsPresenceOfOtherPlayers(unitTest *testing.T) {
+	testIdentifier := "GET game-as-seen-by-player with presence"
+	mockGameName := "Mock game"
+	playerName := testPlayers[0]
+
+	mockCollection := &mockGameCollection{}
+	presenceProvider :=
+		&mockPresenceProvider{
+			ReturnForPresence: map[string]player_state.Presence{
+				testPlayers[1]: player_state.Presence{
+					Status:         player_state.PresenceOnline,
+					ViewedGameName: mockGameName,
+				},
+				testPlayers[2]: player_state.Presence{
+					Status:         player_state.PresenceIdle,
+					ViewedGameName: "another game",
+				},
+			},
+		}
+
+	testHandler :=
+		game_endpoint.New(
+			mockCollection,
+			segmentTranslatorForTest(),
+			&mockAuthorizer{},
+			&mockContactsProvider{},
+			presenceProvider)
+
+	testView := NewMockView()
+	testView.MockGameName = mockGameName
+	testView.MockPlayers = []string{playerName, testPlayers[1], testPlayers[2], testPlayers[3]}
+	testView.MockPlayerTurnIndex = 0
+	mockCollection.ReturnForViewState = testView
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"game-as-seen-by-player",
+				segmentTranslatorForTest().ToSegment(mockGameName),
+				segmentTranslatorForTest().ToSegment(playerName),
+			})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	responseGameView, isInterfaceCorrect :=
+		bodyFromTagged(unitTest, testIdentifier, returnedInterface).(parsing.GameView)
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %+v instead of expected parsing.GameView",
+			returnedInterface)
+	}
+
+	expectedPresences := []struct {
+		playerPresence      string
+		playerIsViewingGame bool
+	}{
+		{playerPresence: player_state.PresenceOnline, playerIsViewingGame: true},
+		{playerPresence: player_state.PresenceIdle, playerIsViewingGame: false},
+		{playerPresence: player_state.PresenceOffline, playerIsViewingGame: false},
+	}
+
+	if len(responseGameView.HandsAfterThisPlayer) != len(expectedPresences) {
+		unitTest.Fatalf(
+			testIdentifier+"/game view %+v did not have %v hands after viewing player",
+			responseGameView,
+			len(expectedPresences))
+	}
+
+	for handIndex, expectedPresence := range expectedPresences {
+		actualHand := responseGameView.HandsAfterThisPlayer[handIndex]
+		if (actualHand.PlayerPresence != expectedPresence.playerPresence) ||
+			(actualHand.PlayerIsViewingGame != expectedPresence.playerIsViewingGame) {
+			unitTest.Fatalf(
+				testIdentifier+"/hand %+v did not have expected presence %+v",
+				actualHand,
+				expectedPresence)
+		}
+	}
+}
+
 func TestRejectInvalidNewGameWithMalformedRequest(unitTest *testing.T) {
 	testIdentifier := "Reject invalid POST create-new-game with malformed JSON body"
 	mockCollection, testHandler := newGameCollectionAndHandler()
@@ -1041,7 +1175,7 @@ func TestRejectNewGameIfCollectionRejectsIt(unitTest *testing.T) {
 		unitTest,
 		functionRecord,
 		functionNameAndArgument{
-			FunctionName:     "AddNew",
+			FunctionName:     "InviteToNew",
 			FunctionArgument: expectedFunctionArgument,
 		},
 		testIdentifier)
@@ -1101,7 +1235,7 @@ func TestRejectNewGameIfIdentifierIncludesSegmentDelimiter(unitTest *testing.T)
 		unitTest,
 		functionRecord,
 		functionNameAndArgument{
-			FunctionName:     "AddNew",
+			FunctionName:     "InviteToNew",
 			FunctionArgument: expectedFunctionArgument,
 		},
 		testIdentifier)
@@ -1160,12 +1294,122 @@ func TestAcceptValidNewGame(unitTest *testing.T) {
 		unitTest,
 		functionRecord,
 		functionNameAndArgument{
-			FunctionName:     "AddNew",
+			FunctionName:     "InviteToNew",
 			FunctionArgument: expectedFunctionArgument,
 		},
 		testIdentifier)
 }
 
+func TestNewGameFromGroup(unitTest *testing.T) {
+	hostContacts :=
+		player_state.Contacts{
+			Groups: []player_state.Group{
+				player_state.Group{
+					Name:              "Tuesday",
+					MemberIdentifiers: []string{"Player Two", "Player Three"},
+				},
+			},
+		}
+
+	testRuleset := game_state.NewStandardWithoutRainbow()
+
+	testCases := []struct {
+		testName                 string
+		playerNames              []string
+		groupName                string
+		errorFromContacts        error
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "more than the host listed",
+			playerNames:              []string{"Player One", "Player Four"},
+			groupName:                "Tuesday",
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:                 "error from contacts",
+			playerNames:              []string{"Player One"},
+			groupName:                "Tuesday",
+			errorFromContacts:        errors.New("expected error"),
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:                 "unknown group",
+			playerNames:              []string{"Player One"},
+			groupName:                "Weekend",
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:             "known group",
+			playerNames:          []string{"Player One"},
+			groupName:            "Tuesday",
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName: "InviteToNew",
+					FunctionArgument: mockGameDefinition{
+						GameName:           "test game",
+						RulesetDescription: testRuleset.FrontendDescription(),
+						FirstPlayerName:    "Player One",
+						SecondPlayerName:   "Player Two",
+						ThirdPlayerName:    "Player Three",
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST create-new-game from group/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockGameCollection{}
+			testHandler :=
+				game_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					&mockAuthorizer{},
+					&mockContactsProvider{
+						ReturnForContacts: hostContacts,
+						ErrorToReturn:     testCase.errorFromContacts,
+					},
+					&mockPresenceProvider{})
+
+			bodyObject :=
+				parsing.GameDefinition{
+					GameName:          "test game",
+					RulesetIdentifier: testRuleset.BackendIdentifier(),
+					PlayerNames:       testCase.playerNames,
+					GroupName:         testCase.groupName,
+				}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{"create-new-game"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+		})
+	}
+}
+
 func TestRejectInvalidLeaveGameWithMalformedRequest(unitTest *testing.T) {
 	testIdentifier := "Reject invalid POST leave-game with malformed JSON body"
 	mockCollection, testHandler := newGameCollectionAndHandler()
@@ -1353,18 +1597,21 @@ func TestRejectDeleteGameIfCollectionRejectsIt(unitTest *testing.T) {
 
 	expectedFunctionArgument := bodyObject.GameName
 
-	functionRecord :=
-		mockCollection.getFirstAndEnsureOnly(
-			unitTest,
-			testIdentifier)
-
-	assertFunctionRecordIsCorrect(
-		unitTest,
-		functionRecord,
+	expectedRecords := []functionNameAndArgument{
+		functionNameAndArgument{
+			FunctionName:     "Creator",
+			FunctionArgument: expectedFunctionArgument,
+		},
 		functionNameAndArgument{
 			FunctionName:     "Delete",
 			FunctionArgument: expectedFunctionArgument,
 		},
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		expectedRecords,
 		testIdentifier)
 }
 
@@ -1395,18 +1642,21 @@ func TestAcceptValidDeleteGame(unitTest *testing.T) {
 
 	expectedFunctionArgument := bodyObject.GameName
 
-	functionRecord :=
-		mockCollection.getFirstAndEnsureOnly(
-			unitTest,
-			testIdentifier)
-
-	assertFunctionRecordIsCorrect(
-		unitTest,
-		functionRecord,
+	expectedRecords := []functionNameAndArgument{
+		functionNameAndArgument{
+			FunctionName:     "Creator",
+			FunctionArgument: expectedFunctionArgument,
+		},
 		functionNameAndArgument{
 			FunctionName:     "Delete",
 			FunctionArgument: expectedFunctionArgument,
 		},
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		expectedRecords,
 		testIdentifier)
 }
 
@@ -1708,6 +1958,79 @@ func TestPropagateErrorFromDiscard(unitTest *testing.T) {
 		testIdentifier)
 }
 
+func TestConflictFromDiscardBasedOnStaleTurn(unitTest *testing.T) {
+	testIdentifier := "Conflict for POST take-turn-by-discarding based on stale turn"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockExecutor := &mockActionExecutor{}
+	mockExecutor.ErrorToReturn = game_state.NewTurnConflictError("Test game", 3, 4)
+	mockCollection.ReturnForExecuteAction = mockExecutor
+
+	bodyObject :=
+		parsing.PlayerCardIndication{
+			PlayerInGameIndication: parsing.PlayerInGameIndication{
+				GameName:   "Test game",
+				PlayerName: "A. Player Name",
+			},
+			CardIndex:    1,
+			ExpectedTurn: 3,
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"take-turn-by-discarding"})
+
+	if responseCode != http.StatusConflict {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusConflict,
+			responseCode)
+	}
+
+	if mockExecutor.ReceivedExpectedTurn != bodyObject.ExpectedTurn {
+		unitTest.Fatalf(
+			testIdentifier+"/executor received expected turn %v instead of %v.",
+			mockExecutor.ReceivedExpectedTurn,
+			bodyObject.ExpectedTurn)
+	}
+}
+
+func TestConflictFromDiscardOutOfTurn(unitTest *testing.T) {
+	testIdentifier := "Conflict for POST take-turn-by-discarding out of turn"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockExecutor := &mockActionExecutor{}
+	mockExecutor.ErrorToReturn =
+		game_state.ErrNotYourTurn.Errorf("Player %v is not the current player", "A. Player Name")
+	mockCollection.ReturnForExecuteAction = mockExecutor
+
+	bodyObject :=
+		parsing.PlayerCardIndication{
+			PlayerInGameIndication: parsing.PlayerInGameIndication{
+				GameName:   "Test game",
+				PlayerName: "A. Player Name",
+			},
+			CardIndex: 1,
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"take-turn-by-discarding"})
+
+	if responseCode != http.StatusConflict {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusConflict,
+			responseCode)
+	}
+}
+
 func TestAcceptValidDiscard(unitTest *testing.T) {
 	testIdentifier := "POST take-turn-by-discarding"
 	mockCollection, testHandler := newGameCollectionAndHandler()
@@ -2253,12 +2576,14 @@ func assertVisibleHandCorrect(
 	expectedPlayer string,
 	expectedCards []card.Defined,
 	expectedHasTakenLastTurn bool) {
-	if actualHand.PlayerName != expectedPlayer {
+	if (actualHand.PlayerIdentifier != expectedPlayer) ||
+		(actualHand.PlayerName != mockDisplayName(expectedPlayer)) {
 		unitTest.Fatalf(
 			testIdentifier+
-				"/actual hand %v did not have expected player name %v",
+				"/actual hand %v did not have expected player identifier %v and display name %v",
 			actualHand,
-			expectedPlayer)
+			expectedPlayer,
+			mockDisplayName(expectedPlayer))
 	}
 
 	assertVisibleCardSlicesCorrect(
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
 // StateCollection defines what a struct should do to allow a Handler from
@@ -36,13 +38,145 @@ type StateCollection interface {
 		gameName string,
 		playerName string) (game.ExecutorForPlayer, error)
 
-	// AddNew should add a new game to the collection based on the given arguments.
-	AddNew(
+	// InviteToNew should invite the given players to a new game based on the given
+	// arguments, with the first player as the host. The game should be added to the
+	// collection once all the invited players have accepted.
+	InviteToNew(
 		executionContext context.Context,
 		gameName string,
 		gameRuleset game.Ruleset,
 		playerNames []string) error
 
+	// ViewInvitationsForPlayer should return the lobbies of all the games to which
+	// the given player has been invited but has not yet accepted.
+	ViewInvitationsForPlayer(
+		executionContext context.Context,
+		playerName string) ([]game.Lobby, error)
+
+	// AcceptInvitation should record that the given player accepts the invitation
+	// to the given game, and should start the game if all the invited players have
+	// then accepted.
+	AcceptInvitation(
+		executionContext context.Context,
+		gameName string,
+		playerName string) error
+
+	// DeclineInvitation should record that the given player declines the invitation
+	// to the given game.
+	DeclineInvitation(
+		executionContext context.Context,
+		gameName string,
+		playerName string) error
+
+	// Rematch should create a new game with the same participants and ruleset as the
+	// given finished game, linked to it as the next game in their series, returning
+	// the name of the new game.
+	Rematch(
+		executionContext context.Context,
+		gameName string,
+		playerName string) (string, error)
+
+	// ViewSeries should return read-only views, as seen by the given player, on all the
+	// games in the series which contains the given game, in the order in which they were
+	// played.
+	ViewSeries(
+		executionContext context.Context,
+		gameName string,
+		playerName string) ([]game.ViewForPlayer, error)
+
+	// StatisticsForPlayer should return the statistics of the given player over all
+	// the finished games in which the player was a participant.
+	StatisticsForPlayer(
+		executionContext context.Context,
+		playerName string) (game.PlayerStatistics, error)
+
+	// SubscribeToChanges should return a channel which receives a notice whenever the
+	// given game changes, along with the function which ends the subscription.
+	SubscribeToChanges(
+		executionContext context.Context,
+		gameName string) (<-chan game.ChangeNotice, func())
+
+	// Leaderboard should return the leaderboard of the finished games which match the
+	// given criteria, with at most the given number of entries in each list.
+	Leaderboard(
+		executionContext context.Context,
+		gameCriteria game.FinishedGameCriteria,
+		maximumNumberOfEntries int) (game.Leaderboard, error)
+
+	// SetSpectating should set whether the given game may be spectated by players who
+	// are not participants, and by how many turns the view with all hands visible should
+	// lag behind the game. Only the creator of the game should be allowed to do this.
+	SetSpectating(
+		executionContext context.Context,
+		gameName string,
+		playerName string,
+		isAllowed bool,
+		delayInTurns int) error
+
+	// ViewAsSpectator should return the view of the given game, with all hands visible,
+	// for the given spectator, delayed as set by the creator of the game.
+	ViewAsSpectator(
+		executionContext context.Context,
+		gameName string,
+		spectatorName string) (game.NeutralSnapshot, error)
+
+	// ViewAsSpectatorFromSeat should return the view of the given game as seen by the
+	// given participant, for the given spectator, unless spectators are shown the game
+	// with a delay.
+	ViewAsSpectatorFromSeat(
+		executionContext context.Context,
+		gameName string,
+		spectatorName string,
+		participantName string) (game.ViewForPlayer, error)
+
+	// RecordSpectatorChatMessage should record the given chat message from the given
+	// spectator in the chat log for spectators of the given game.
+	RecordSpectatorChatMessage(
+		executionContext context.Context,
+		gameName string,
+		spectatorName string,
+		chatMessage string) error
+
+	// SpectatorChatLog should return the chat log for spectators of the given game.
+	SpectatorChatLog(
+		executionContext context.Context,
+		gameName string,
+		spectatorName string) ([]message.FromPlayer, error)
+
+	// AllLobbies should return all the lobbies of games which have not yet been dealt.
+	AllLobbies(executionContext context.Context) ([]game.Lobby, error)
+
+	// AddLobby should add a new lobby with the given number of seats, with the given
+	// host already seated, for a game with the given name and ruleset.
+	AddLobby(
+		executionContext context.Context,
+		gameName string,
+		gameRuleset game.Ruleset,
+		numberOfSeats int,
+		hostName string) error
+
+	// JoinLobby should give the given player an open seat in the lobby for the given
+	// game, or return an error if that is not possible. Joining a lobby which is by
+	// invitation only should count as accepting the invitation.
+	JoinLobby(
+		executionContext context.Context,
+		gameName string,
+		playerName string) error
+
+	// LeaveLobby should free the seat of the given player in the lobby for the given
+	// game.
+	LeaveLobby(
+		executionContext context.Context,
+		gameName string,
+		playerName string) error
+
+	// StartGameFromLobby should deal a new game for the players seated in the lobby
+	// for the given game, if the given player is the host and all seats are filled.
+	StartGameFromLobby(
+		executionContext context.Context,
+		gameName string,
+		hostName string) error
+
 	// RemoveGameFromListForPlayer should remove the given player from the given game in
 	// the sense that the game will no longer show up in the result of
 	// ReadAllWithPlayer(playerName). It should return an error if the player is not a
@@ -52,6 +186,39 @@ type StateCollection interface {
 		gameName string,
 		playerName string) error
 
+	// Creator should return the name of the player who created the given game.
+	Creator(executionContext context.Context, gameName string) (string, error)
+
 	// Delete should delete the given game from the collection.
 	Delete(executionContext context.Context, gameName string) error
 }
+
+// ContactsProvider defines what a struct should do to allow a Handler to find the
+// friends and groups of a player, so that games can be created from groups and lobbies
+// can be filtered down to those with friends.
+type ContactsProvider interface {
+	// Contacts should return the friends and groups of the given player.
+	Contacts(executionContext context.Context, playerIdentifier string) (player.Contacts, error)
+}
+
+// PresenceProvider defines what a struct should do to allow a Handler to show whether
+// the other players in a game are online and whether they have the game open.
+type PresenceProvider interface {
+	// Presence should return the presence of the given player.
+	Presence(executionContext context.Context, playerIdentifier string) player.Presence
+}
+
+// PlayerAuthorizer defines what a struct should do to allow a Handler to check that
+// a request is being made by the player on whose behalf it claims to act.
+type PlayerAuthorizer interface {
+	// AuthorizeActingPlayer should return nil if the request with the given context
+	// may act on behalf of the given player, and an error otherwise.
+	AuthorizeActingPlayer(requestContext context.Context, playerName string) error
+
+	// AuthorizeActingPlayerOrAdministrator should return nil if the request with the
+	// given context may act on behalf of the given player or is from an administrator,
+	// and an error otherwise.
+	AuthorizeActingPlayerOrAdministrator(
+		requestContext context.Context,
+		playerName string) error
+}
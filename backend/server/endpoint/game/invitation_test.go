@@ -0,0 +1,209 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestGetInvitationsForPlayerNoFurtherSegmentBadRequest(unitTest *testing.T) {
+	testIdentifier := "GET pending-invitations-for-player without further segment"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	_, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{"pending-invitations-for-player"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	assertNoFunctionWasCalled(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		testIdentifier)
+}
+
+func TestGetInvitationsForPlayerRejectedIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "GET pending-invitations-for-player rejected if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	mockPlayerName := "Mock MacMock"
+	mockPlayerIdentifier := segmentTranslatorForTest().ToSegment(mockPlayerName)
+
+	_, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{"pending-invitations-for-player", mockPlayerIdentifier})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "ViewInvitationsForPlayer",
+			FunctionArgument: mockPlayerName,
+		},
+		testIdentifier)
+}
+
+func TestGetInvitationsForPlayer(unitTest *testing.T) {
+	testIdentifier := "GET pending-invitations-for-player"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	testRuleset := game_state.NewStandardWithoutRainbow()
+	invitationLobby :=
+		game_state.NewInvitationLobby("test game", testRuleset, testPlayers[:3])
+	invitationLobby.SeatedPlayerNames =
+		append(invitationLobby.SeatedPlayerNames, testPlayers[2])
+
+	mockCollection.ReturnForInvitations = []game_state.Lobby{invitationLobby}
+
+	mockPlayerName := testPlayers[1]
+	mockPlayerIdentifier := segmentTranslatorForTest().ToSegment(mockPlayerName)
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{"pending-invitations-for-player", mockPlayerIdentifier})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	responseInvitationList, isInterfaceCorrect :=
+		returnedInterface.(parsing.InvitationList)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected parsing.InvitationList",
+			returnedInterface)
+	}
+
+	expectedInvitations := []parsing.InvitationSummary{
+		parsing.InvitationSummary{
+			GameIdentifier:      segmentTranslatorForTest().ToSegment(invitationLobby.GameName),
+			GameName:            invitationLobby.GameName,
+			RulesetDescription:  testRuleset.FrontendDescription(),
+			HostName:            testPlayers[0],
+			InvitedPlayerNames:  testPlayers[:3],
+			AcceptedPlayerNames: []string{testPlayers[0], testPlayers[2]},
+		},
+	}
+
+	if !reflect.DeepEqual(responseInvitationList.Invitations, expectedInvitations) {
+		unitTest.Fatalf(
+			testIdentifier+"/invitation summaries %v did not match expected %v",
+			responseInvitationList.Invitations,
+			expectedInvitations)
+	}
+}
+
+func TestInvitationResponsesPassOnCollectionResult(unitTest *testing.T) {
+	testCases := []struct {
+		testName             string
+		uriSegment           string
+		expectedFunctionName string
+		errorFromCollection  error
+		expectedResponseCode int
+	}{
+		{
+			testName:             "Accept rejected by collection",
+			uriSegment:           "accept-invitation",
+			expectedFunctionName: "AcceptInvitation",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Accept accepted by collection",
+			uriSegment:           "accept-invitation",
+			expectedFunctionName: "AcceptInvitation",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			testName:             "Decline rejected by collection",
+			uriSegment:           "decline-invitation",
+			expectedFunctionName: "DeclineInvitation",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Decline accepted by collection",
+			uriSegment:           "decline-invitation",
+			expectedFunctionName: "DeclineInvitation",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			testIdentifier := "POST " + testCase.uriSegment + "/" + testCase.testName
+			mockCollection, testHandler := newGameCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+
+			bodyObject :=
+				parsing.PlayerInGameIndication{
+					GameName:   "test game",
+					PlayerName: testPlayers[1],
+				}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{testCase.uriSegment})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			functionRecord :=
+				mockCollection.getFirstAndEnsureOnly(
+					unitTest,
+					testIdentifier)
+
+			assertFunctionRecordIsCorrect(
+				unitTest,
+				functionRecord,
+				functionNameAndArgument{
+					FunctionName: testCase.expectedFunctionName,
+					FunctionArgument: stringPair{
+						first:  bodyObject.GameName,
+						second: bodyObject.PlayerName,
+					},
+				},
+				testIdentifier)
+		})
+	}
+}
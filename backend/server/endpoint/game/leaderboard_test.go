@@ -0,0 +1,195 @@
+package game_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestRejectLeaderboardWithMalformedRequest(unitTest *testing.T) {
+	testIdentifier := "Reject POST leaderboard with malformed JSON body"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	bodyString := "{\"RulesetIdentifier\" :1, \"NumberOfPlayers\":}"
+
+	bodyDecoder :=
+		json.NewDecoder(bytes.NewReader(bytes.NewBufferString(bodyString).Bytes()))
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"leaderboard"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	assertNoFunctionWasCalled(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		testIdentifier)
+}
+
+func TestLeaderboardRejectedIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "POST leaderboard rejected if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	bodyObject := parsing.LeaderboardRequest{
+		RulesetIdentifier: -1,
+		NumberOfPlayers:   2,
+	}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"leaderboard"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName: "Leaderboard",
+			FunctionArgument: mockLeaderboardRequest{
+				GameCriteria: game_state.FinishedGameCriteria{
+					RulesetIdentifier: -1,
+					NumberOfPlayers:   2,
+				},
+			},
+		},
+		testIdentifier)
+}
+
+func TestLeaderboard(unitTest *testing.T) {
+	testIdentifier := "POST leaderboard"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	testRuleset := game_state.NewStandardWithoutRainbow()
+	finishedFrom := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+	finishedBefore := finishedFrom.AddDate(0, 1, 0)
+	timeOfFinish := finishedFrom.Add(48 * time.Hour)
+
+	gameCriteria := game_state.FinishedGameCriteria{
+		RulesetIdentifier: testRuleset.BackendIdentifier(),
+		NumberOfPlayers:   2,
+		FinishedFrom:      finishedFrom,
+		FinishedBefore:    finishedBefore,
+	}
+
+	perfectEntry := game_state.LeaderboardEntry{
+		GameName:           "perfect game",
+		PlayerNames:        []string{testPlayers[0], testPlayers[1]},
+		PlayerDisplayNames: []string{"First", "Second"},
+		Score:              25,
+		NumberOfTurnsTaken: 60,
+		ElapsedTime:        90 * time.Minute,
+		TimeOfFinish:       timeOfFinish,
+	}
+
+	mockCollection.ReturnForLeaderboard = game_state.Leaderboard{
+		GameCriteria:        gameCriteria,
+		HighestScores:       []game_state.LeaderboardEntry{perfectEntry},
+		FastestPerfectGames: []game_state.LeaderboardEntry{perfectEntry},
+	}
+
+	bodyObject := parsing.LeaderboardRequest{
+		RulesetIdentifier:      gameCriteria.RulesetIdentifier,
+		NumberOfPlayers:        gameCriteria.NumberOfPlayers,
+		FinishedFrom:           finishedFrom,
+		FinishedBefore:         finishedBefore,
+		MaximumNumberOfEntries: 5,
+	}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	returnedInterface, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"leaderboard"})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName: "Leaderboard",
+			FunctionArgument: mockLeaderboardRequest{
+				GameCriteria:           gameCriteria,
+				MaximumNumberOfEntries: 5,
+			},
+		},
+		testIdentifier)
+
+	responseLeaderboard, isInterfaceCorrect :=
+		returnedInterface.(parsing.Leaderboard)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected parsing.Leaderboard",
+			returnedInterface)
+	}
+
+	expectedEntry := parsing.LeaderboardEntry{
+		GameName:           "perfect game",
+		PlayerIdentifiers:  []string{testPlayers[0], testPlayers[1]},
+		PlayerNames:        []string{"First", "Second"},
+		Score:              25,
+		NumberOfTurnsTaken: 60,
+		ElapsedSeconds:     90 * 60,
+		TimeOfFinish:       timeOfFinish,
+	}
+
+	expectedLeaderboard := parsing.Leaderboard{
+		RulesetIdentifier:   testRuleset.BackendIdentifier(),
+		RulesetDescription:  testRuleset.FrontendDescription(),
+		NumberOfPlayers:     2,
+		HighestScores:       []parsing.LeaderboardEntry{expectedEntry},
+		FastestPerfectGames: []parsing.LeaderboardEntry{expectedEntry},
+	}
+
+	if !reflect.DeepEqual(responseLeaderboard, expectedLeaderboard) {
+		unitTest.Fatalf(
+			testIdentifier+"/leaderboard %+v did not match expected %+v",
+			responseLeaderboard,
+			expectedLeaderboard)
+	}
+}
@@ -0,0 +1,492 @@
+package game_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	player_state "github.com/benoleary/ilutulestikud/backend/player"
+	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestGetPendingLobbiesRejectedIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "GET pending-lobbies rejected if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	_, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{"pending-lobbies"})
+
+	if responseCode != http.StatusInternalServerError {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusInternalServerError,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "AllLobbies",
+			FunctionArgument: nil,
+		},
+		testIdentifier)
+}
+
+func TestGetPendingLobbies(unitTest *testing.T) {
+	testIdentifier := "GET pending-lobbies"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	testRuleset := game_state.NewStandardWithoutRainbow()
+	firstLobby := game_state.NewLobby("first game", testRuleset, 2, testPlayers[0])
+	secondLobby := game_state.NewLobby("second game", testRuleset, 3, testPlayers[1])
+	secondLobby.SeatedPlayerNames = append(secondLobby.SeatedPlayerNames, testPlayers[2])
+	secondLobby.TimeOfCreation = firstLobby.TimeOfCreation.Add(time.Second)
+
+	mockCollection.ReturnForAllLobbies = []game_state.Lobby{firstLobby, secondLobby}
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{"pending-lobbies"})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	responseLobbyList, isInterfaceCorrect :=
+		returnedInterface.(parsing.LobbyList)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected parsing.LobbyList",
+			returnedInterface)
+	}
+
+	expectedLobbySummaries := []parsing.LobbySummary{
+		parsing.LobbySummary{
+			GameIdentifier:     segmentTranslatorForTest().ToSegment(firstLobby.GameName),
+			GameName:           firstLobby.GameName,
+			RulesetIdentifier:  testRuleset.BackendIdentifier(),
+			RulesetDescription: testRuleset.FrontendDescription(),
+			HostName:           testPlayers[0],
+			NumberOfSeats:      2,
+			SeatedPlayerNames:  []string{testPlayers[0]},
+		},
+		parsing.LobbySummary{
+			GameIdentifier:     segmentTranslatorForTest().ToSegment(secondLobby.GameName),
+			GameName:           secondLobby.GameName,
+			RulesetIdentifier:  testRuleset.BackendIdentifier(),
+			RulesetDescription: testRuleset.FrontendDescription(),
+			HostName:           testPlayers[1],
+			NumberOfSeats:      3,
+			SeatedPlayerNames:  []string{testPlayers[1], testPlayers[2]},
+		},
+	}
+
+	if !reflect.DeepEqual(responseLobbyList.Lobbies, expectedLobbySummaries) {
+		unitTest.Fatalf(
+			testIdentifier+"/lobby summaries %v did not match expected %v",
+			responseLobbyList.Lobbies,
+			expectedLobbySummaries)
+	}
+}
+
+func TestGetPendingLobbiesWithFriends(unitTest *testing.T) {
+	playerSegment := segmentTranslatorForTest().ToSegment(testPlayers[0])
+	testRuleset := game_state.NewStandardWithoutRainbow()
+	lobbyWithFriendHosting :=
+		game_state.NewLobby("friend hosts", testRuleset, 2, testPlayers[1])
+	lobbyWithFriendSeated :=
+		game_state.NewLobby("friend seated", testRuleset, 3, testPlayers[2])
+	lobbyWithFriendSeated.SeatedPlayerNames =
+		append(lobbyWithFriendSeated.SeatedPlayerNames, testPlayers[1])
+	lobbyWithoutFriend :=
+		game_state.NewLobby("no friends", testRuleset, 2, testPlayers[3])
+
+	testCases := []struct {
+		testName              string
+		relevantSegments      []string
+		errorFromContacts     error
+		errorFromCollection   error
+		expectedResponseCode  int
+		expectedGameNames     []string
+		expectedCollectionUse bool
+	}{
+		{
+			testName:             "no player segment",
+			relevantSegments:     []string{"pending-lobbies-with-friends"},
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "error from contacts",
+			relevantSegments:     []string{"pending-lobbies-with-friends", playerSegment},
+			errorFromContacts:    errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:              "error from collection",
+			relevantSegments:      []string{"pending-lobbies-with-friends", playerSegment},
+			errorFromCollection:   errors.New("expected error"),
+			expectedResponseCode:  http.StatusInternalServerError,
+			expectedCollectionUse: true,
+		},
+		{
+			testName:              "only lobbies with friends",
+			relevantSegments:      []string{"pending-lobbies-with-friends", playerSegment},
+			expectedResponseCode:  http.StatusOK,
+			expectedGameNames:     []string{"friend hosts", "friend seated"},
+			expectedCollectionUse: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "GET pending-lobbies-with-friends/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockGameCollection{}
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+			mockCollection.ReturnForAllLobbies =
+				[]game_state.Lobby{
+					lobbyWithFriendHosting,
+					lobbyWithoutFriend,
+					lobbyWithFriendSeated,
+				}
+
+			contactsProvider :=
+				&mockContactsProvider{
+					ReturnForContacts: player_state.Contacts{
+						FriendIdentifiers: []string{testPlayers[1]},
+					},
+					ErrorToReturn: testCase.errorFromContacts,
+				}
+
+			testHandler :=
+				game_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					&mockAuthorizer{},
+					contactsProvider,
+					&mockPresenceProvider{})
+
+			returnedInterface, responseCode :=
+				testHandler.HandleGet(context.Background(), testCase.relevantSegments)
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			if testCase.expectedCollectionUse !=
+				(len(mockCollection.FunctionsAndArgumentsReceived) > 0) {
+				unitTest.Fatalf(
+					testIdentifier+"/mock collection recorded %v, expected use: %v.",
+					mockCollection.FunctionsAndArgumentsReceived,
+					testCase.expectedCollectionUse)
+			}
+
+			if responseCode != http.StatusOK {
+				return
+			}
+
+			responseLobbyList, isInterfaceCorrect :=
+				returnedInterface.(parsing.LobbyList)
+
+			if !isInterfaceCorrect {
+				unitTest.Fatalf(
+					testIdentifier+"/received %v instead of expected parsing.LobbyList",
+					returnedInterface)
+			}
+
+			actualGameNames := make([]string, 0, len(responseLobbyList.Lobbies))
+			for _, lobbySummary := range responseLobbyList.Lobbies {
+				actualGameNames = append(actualGameNames, lobbySummary.GameName)
+			}
+
+			if !reflect.DeepEqual(actualGameNames, testCase.expectedGameNames) {
+				unitTest.Fatalf(
+					testIdentifier+"/lobbies %v did not match expected %v",
+					actualGameNames,
+					testCase.expectedGameNames)
+			}
+		})
+	}
+}
+
+func TestRejectInvalidLobbyRequestsWithMalformedRequest(unitTest *testing.T) {
+	testCases := []struct {
+		testName   string
+		uriSegment string
+	}{
+		{
+			testName:   "create-new-lobby",
+			uriSegment: "create-new-lobby",
+		},
+		{
+			testName:   "join-lobby",
+			uriSegment: "join-lobby",
+		},
+		{
+			testName:   "leave-lobby",
+			uriSegment: "leave-lobby",
+		},
+		{
+			testName:   "start-game-from-lobby",
+			uriSegment: "start-game-from-lobby",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			testIdentifier :=
+				"Reject invalid POST " + testCase.uriSegment + " with malformed JSON body"
+			mockCollection, testHandler := newGameCollectionAndHandler()
+			mockCollection.ErrorToReturn = errors.New("expected error")
+
+			bodyString := "{\"GameName\" :\"Something\", \"PlayerName\":}"
+
+			bodyDecoder :=
+				json.NewDecoder(bytes.NewReader(bytes.NewBufferString(bodyString).Bytes()))
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{testCase.uriSegment})
+
+			if responseCode != http.StatusBadRequest {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					http.StatusBadRequest,
+					responseCode)
+			}
+
+			assertNoFunctionWasCalled(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testIdentifier)
+		})
+	}
+}
+
+func TestRejectNewLobbyWithInvalidRulesetIdentifier(unitTest *testing.T) {
+	testIdentifier := "Reject POST create-new-lobby with invalid ruleset identifier"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	bodyObject :=
+		parsing.LobbyDefinition{
+			GameName:          "test game",
+			RulesetIdentifier: -1,
+			NumberOfSeats:     2,
+			HostName:          testPlayers[0],
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"create-new-lobby"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	assertNoFunctionWasCalled(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		testIdentifier)
+}
+
+func TestNewLobbyPassesOnCollectionResult(unitTest *testing.T) {
+	testCases := []struct {
+		testName             string
+		errorFromCollection  error
+		expectedResponseCode int
+	}{
+		{
+			testName:             "Rejected by collection",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Accepted by collection",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			testIdentifier := "POST create-new-lobby/" + testCase.testName
+			mockCollection, testHandler := newGameCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+
+			testRuleset := game_state.NewStandardWithoutRainbow()
+
+			bodyObject :=
+				parsing.LobbyDefinition{
+					GameName:          "test game",
+					RulesetIdentifier: testRuleset.BackendIdentifier(),
+					NumberOfSeats:     3,
+					HostName:          testPlayers[0],
+				}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{"create-new-lobby"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			functionRecord :=
+				mockCollection.getFirstAndEnsureOnly(
+					unitTest,
+					testIdentifier)
+
+			assertFunctionRecordIsCorrect(
+				unitTest,
+				functionRecord,
+				functionNameAndArgument{
+					FunctionName: "AddLobby",
+					FunctionArgument: mockLobbyDefinition{
+						GameName:           bodyObject.GameName,
+						RulesetDescription: testRuleset.FrontendDescription(),
+						NumberOfSeats:      bodyObject.NumberOfSeats,
+						HostName:           bodyObject.HostName,
+					},
+				},
+				testIdentifier)
+		})
+	}
+}
+
+func TestLobbySeatRequestsPassOnCollectionResult(unitTest *testing.T) {
+	testCases := []struct {
+		testName             string
+		uriSegment           string
+		expectedFunctionName string
+		errorFromCollection  error
+		expectedResponseCode int
+	}{
+		{
+			testName:             "Join rejected by collection",
+			uriSegment:           "join-lobby",
+			expectedFunctionName: "JoinLobby",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Join accepted by collection",
+			uriSegment:           "join-lobby",
+			expectedFunctionName: "JoinLobby",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			testName:             "Leave rejected by collection",
+			uriSegment:           "leave-lobby",
+			expectedFunctionName: "LeaveLobby",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Leave accepted by collection",
+			uriSegment:           "leave-lobby",
+			expectedFunctionName: "LeaveLobby",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			testName:             "Start rejected by collection",
+			uriSegment:           "start-game-from-lobby",
+			expectedFunctionName: "StartGameFromLobby",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Start accepted by collection",
+			uriSegment:           "start-game-from-lobby",
+			expectedFunctionName: "StartGameFromLobby",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			testIdentifier := "POST " + testCase.uriSegment + "/" + testCase.testName
+			mockCollection, testHandler := newGameCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+
+			bodyObject :=
+				parsing.PlayerInGameIndication{
+					GameName:   "test game",
+					PlayerName: testPlayers[1],
+				}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{testCase.uriSegment})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			functionRecord :=
+				mockCollection.getFirstAndEnsureOnly(
+					unitTest,
+					testIdentifier)
+
+			assertFunctionRecordIsCorrect(
+				unitTest,
+				functionRecord,
+				functionNameAndArgument{
+					FunctionName: testCase.expectedFunctionName,
+					FunctionArgument: stringPair{
+						first:  bodyObject.GameName,
+						second: bodyObject.PlayerName,
+					},
+				},
+				testIdentifier)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/benoleary/ilutulestikud/backend/game"
 	"github.com/benoleary/ilutulestikud/backend/game/card"
 	"github.com/benoleary/ilutulestikud/backend/game/message"
+	"github.com/benoleary/ilutulestikud/backend/player"
 )
 
 // This file defines mock implementations of interfaces.
@@ -24,7 +25,10 @@ type mockViewForPlayer struct {
 	MockPlayers                   []string
 	MockChatLog                   []message.FromPlayer
 	MockPlayerTurnIndex           int
+	MockTurn                      int
+	MockVersion                   int
 	MockScore                     int
+	MockGameIsFinished            bool
 	ErrorForVisibleHand           error
 	ReturnForVisibleHand          []card.Defined
 	ErrorMapForKnowledgeOfOwnHand map[string]error
@@ -38,7 +42,9 @@ func NewMockView() *mockViewForPlayer {
 		MockPlayers:                   nil,
 		MockChatLog:                   nil,
 		MockPlayerTurnIndex:           -1,
+		MockTurn:                      -1,
 		MockScore:                     -1,
+		MockGameIsFinished:            false,
 		ErrorForVisibleHand:           nil,
 		ReturnForVisibleHand:          nil,
 		ErrorMapForKnowledgeOfOwnHand: make(map[string]error, 0),
@@ -69,7 +75,7 @@ func (mockView *mockViewForPlayer) ActionLog() []message.FromPlayer {
 
 // GameIsFinished gets mocked.
 func (mockView *mockViewForPlayer) GameIsFinished() bool {
-	return false
+	return mockView.MockGameIsFinished
 }
 
 // CurrentTurnOrder gets mocked.
@@ -79,7 +85,12 @@ func (mockView *mockViewForPlayer) CurrentTurnOrder() ([]string, int, int) {
 
 // Turn gets mocked.
 func (mockView *mockViewForPlayer) Turn() int {
-	return -1
+	return mockView.MockTurn
+}
+
+// Version gets mocked.
+func (mockView *mockViewForPlayer) Version() int {
+	return mockView.MockVersion
 }
 
 // Score gets mocked.
@@ -145,6 +156,18 @@ func (mockView *mockViewForPlayer) KnowledgeOfOwnHand(
 	return mockView.ReturnForKnowledgeOfOwnHand, errorToReturn
 }
 
+// ParticipantDisplayName gets mocked.
+func (mockView *mockViewForPlayer) ParticipantDisplayName(
+	participantIdentifier string) string {
+	return mockDisplayName(participantIdentifier)
+}
+
+// mockDisplayName gives a display name for the given identifier which is different from
+// the identifier, so that tests can check which is used.
+func mockDisplayName(participantIdentifier string) string {
+	return "Display name of " + participantIdentifier
+}
+
 // mockGameDefinition takes up to five players, not as an array so that
 // the default comparison works.
 type mockGameDefinition struct {
@@ -158,7 +181,8 @@ type mockGameDefinition struct {
 }
 
 type mockActionExecutor struct {
-	ErrorToReturn error
+	ErrorToReturn        error
+	ReceivedExpectedTurn int
 }
 
 // RecordChatMessage gets mocked.
@@ -171,30 +195,38 @@ func (mockExecutor *mockActionExecutor) RecordChatMessage(
 // TakeTurnByDiscarding gets mocked.
 func (mockExecutor *mockActionExecutor) TakeTurnByDiscarding(
 	executionContext context.Context,
+	expectedTurn int,
 	indexInHandToDiscard int) error {
+	mockExecutor.ReceivedExpectedTurn = expectedTurn
 	return mockExecutor.ErrorToReturn
 }
 
 // TakeTurnByPlaying gets mocked.
 func (mockExecutor *mockActionExecutor) TakeTurnByPlaying(
 	executionContext context.Context,
+	expectedTurn int,
 	indexInHandToDiscard int) error {
+	mockExecutor.ReceivedExpectedTurn = expectedTurn
 	return mockExecutor.ErrorToReturn
 }
 
 // TakeTurnByHintingColor gets mocked.
 func (mockExecutor *mockActionExecutor) TakeTurnByHintingColor(
 	executionContext context.Context,
+	expectedTurn int,
 	receivingPlayer string,
 	hintedColor string) error {
+	mockExecutor.ReceivedExpectedTurn = expectedTurn
 	return mockExecutor.ErrorToReturn
 }
 
 // TakeTurnByHintingIndex gets mocked.
 func (mockExecutor *mockActionExecutor) TakeTurnByHintingIndex(
 	executionContext context.Context,
+	expectedTurn int,
 	receivingPlayer string,
 	hintedIndex int) error {
+	mockExecutor.ReceivedExpectedTurn = expectedTurn
 	return mockExecutor.ErrorToReturn
 }
 
@@ -204,6 +236,17 @@ type mockGameCollection struct {
 	ReturnForViewAllWithPlayer    []game.ViewForPlayer
 	ReturnForViewState            game.ViewForPlayer
 	ReturnForExecuteAction        game.ExecutorForPlayer
+	ReturnForAllLobbies           []game.Lobby
+	ReturnForInvitations          []game.Lobby
+	ReturnForRematch              string
+	ReturnForViewSeries           []game.ViewForPlayer
+	ReturnForStatisticsForPlayer  game.PlayerStatistics
+	ReturnForLeaderboard          game.Leaderboard
+	ReturnForSubscribeToChanges   chan game.ChangeNotice
+	ReturnForViewAsSpectator      game.NeutralSnapshot
+	ReturnForSpectatorChatLog     []message.FromPlayer
+	ReturnForCreator              string
+	ErrorForCreator               error
 }
 
 func (mockCollection *mockGameCollection) recordFunctionAndArgument(
@@ -263,8 +306,8 @@ func (mockCollection *mockGameCollection) ExecuteAction(
 	return mockCollection.ReturnForExecuteAction, mockCollection.ErrorToReturn
 }
 
-// AddNew gets mocked.
-func (mockCollection *mockGameCollection) AddNew(
+// InviteToNew gets mocked.
+func (mockCollection *mockGameCollection) InviteToNew(
 	executionContext context.Context,
 	gameName string,
 	gameRuleset game.Ruleset,
@@ -296,11 +339,251 @@ func (mockCollection *mockGameCollection) AddNew(
 	}
 
 	mockCollection.recordFunctionAndArgument(
-		"AddNew",
+		"InviteToNew",
 		functionArgument)
 	return mockCollection.ErrorToReturn
 }
 
+// mockLobbyDefinition records the arguments given to AddLobby.
+type mockLobbyDefinition struct {
+	GameName           string
+	RulesetDescription string
+	NumberOfSeats      int
+	HostName           string
+}
+
+// AllLobbies gets mocked.
+func (mockCollection *mockGameCollection) AllLobbies(
+	executionContext context.Context) ([]game.Lobby, error) {
+	mockCollection.recordFunctionAndArgument(
+		"AllLobbies",
+		nil)
+	return mockCollection.ReturnForAllLobbies, mockCollection.ErrorToReturn
+}
+
+// AddLobby gets mocked.
+func (mockCollection *mockGameCollection) AddLobby(
+	executionContext context.Context,
+	gameName string,
+	gameRuleset game.Ruleset,
+	numberOfSeats int,
+	hostName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"AddLobby",
+		mockLobbyDefinition{
+			GameName:           gameName,
+			RulesetDescription: gameRuleset.FrontendDescription(),
+			NumberOfSeats:      numberOfSeats,
+			HostName:           hostName,
+		})
+	return mockCollection.ErrorToReturn
+}
+
+// JoinLobby gets mocked.
+func (mockCollection *mockGameCollection) JoinLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"JoinLobby",
+		stringPair{first: gameName, second: playerName})
+	return mockCollection.ErrorToReturn
+}
+
+// LeaveLobby gets mocked.
+func (mockCollection *mockGameCollection) LeaveLobby(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"LeaveLobby",
+		stringPair{first: gameName, second: playerName})
+	return mockCollection.ErrorToReturn
+}
+
+// StartGameFromLobby gets mocked.
+func (mockCollection *mockGameCollection) StartGameFromLobby(
+	executionContext context.Context,
+	gameName string,
+	hostName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"StartGameFromLobby",
+		stringPair{first: gameName, second: hostName})
+	return mockCollection.ErrorToReturn
+}
+
+// Rematch gets mocked.
+func (mockCollection *mockGameCollection) Rematch(
+	executionContext context.Context,
+	gameName string,
+	playerName string) (string, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Rematch",
+		stringPair{first: gameName, second: playerName})
+	return mockCollection.ReturnForRematch, mockCollection.ErrorToReturn
+}
+
+// ViewSeries gets mocked.
+func (mockCollection *mockGameCollection) ViewSeries(
+	executionContext context.Context,
+	gameName string,
+	playerName string) ([]game.ViewForPlayer, error) {
+	mockCollection.recordFunctionAndArgument(
+		"ViewSeries",
+		stringPair{first: gameName, second: playerName})
+	return mockCollection.ReturnForViewSeries, mockCollection.ErrorToReturn
+}
+
+// StatisticsForPlayer gets mocked.
+func (mockCollection *mockGameCollection) StatisticsForPlayer(
+	executionContext context.Context,
+	playerName string) (game.PlayerStatistics, error) {
+	mockCollection.recordFunctionAndArgument(
+		"StatisticsForPlayer",
+		playerName)
+	return mockCollection.ReturnForStatisticsForPlayer, mockCollection.ErrorToReturn
+}
+
+// SubscribeToChanges gets mocked, and the function which it returns is recorded as
+// UnsubscribeFromChanges when it is called.
+func (mockCollection *mockGameCollection) SubscribeToChanges(
+	executionContext context.Context,
+	gameName string) (<-chan game.ChangeNotice, func()) {
+	mockCollection.recordFunctionAndArgument(
+		"SubscribeToChanges",
+		gameName)
+
+	unsubscribe := func() {
+		mockCollection.recordFunctionAndArgument(
+			"UnsubscribeFromChanges",
+			gameName)
+	}
+
+	return mockCollection.ReturnForSubscribeToChanges, unsubscribe
+}
+
+type mockLeaderboardRequest struct {
+	GameCriteria           game.FinishedGameCriteria
+	MaximumNumberOfEntries int
+}
+
+// Leaderboard gets mocked.
+func (mockCollection *mockGameCollection) Leaderboard(
+	executionContext context.Context,
+	gameCriteria game.FinishedGameCriteria,
+	maximumNumberOfEntries int) (game.Leaderboard, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Leaderboard",
+		mockLeaderboardRequest{
+			GameCriteria:           gameCriteria,
+			MaximumNumberOfEntries: maximumNumberOfEntries,
+		})
+	return mockCollection.ReturnForLeaderboard, mockCollection.ErrorToReturn
+}
+
+type mockSpectatingSettings struct {
+	GameName            string
+	PlayerName          string
+	SpectatingIsAllowed bool
+	DelayInTurns        int
+}
+
+// SetSpectating gets mocked.
+func (mockCollection *mockGameCollection) SetSpectating(
+	executionContext context.Context,
+	gameName string,
+	playerName string,
+	isAllowed bool,
+	delayInTurns int) error {
+	mockCollection.recordFunctionAndArgument(
+		"SetSpectating",
+		mockSpectatingSettings{
+			GameName:            gameName,
+			PlayerName:          playerName,
+			SpectatingIsAllowed: isAllowed,
+			DelayInTurns:        delayInTurns,
+		})
+	return mockCollection.ErrorToReturn
+}
+
+// ViewAsSpectator gets mocked.
+func (mockCollection *mockGameCollection) ViewAsSpectator(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string) (game.NeutralSnapshot, error) {
+	mockCollection.recordFunctionAndArgument(
+		"ViewAsSpectator",
+		stringPair{first: gameName, second: spectatorName})
+	return mockCollection.ReturnForViewAsSpectator, mockCollection.ErrorToReturn
+}
+
+// ViewAsSpectatorFromSeat gets mocked.
+func (mockCollection *mockGameCollection) ViewAsSpectatorFromSeat(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string,
+	participantName string) (game.ViewForPlayer, error) {
+	mockCollection.recordFunctionAndArgument(
+		"ViewAsSpectatorFromSeat",
+		stringTriple{first: gameName, second: spectatorName, third: participantName})
+	return mockCollection.ReturnForViewState, mockCollection.ErrorToReturn
+}
+
+// RecordSpectatorChatMessage gets mocked.
+func (mockCollection *mockGameCollection) RecordSpectatorChatMessage(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string,
+	chatMessage string) error {
+	mockCollection.recordFunctionAndArgument(
+		"RecordSpectatorChatMessage",
+		stringTriple{first: gameName, second: spectatorName, third: chatMessage})
+	return mockCollection.ErrorToReturn
+}
+
+// SpectatorChatLog gets mocked.
+func (mockCollection *mockGameCollection) SpectatorChatLog(
+	executionContext context.Context,
+	gameName string,
+	spectatorName string) ([]message.FromPlayer, error) {
+	mockCollection.recordFunctionAndArgument(
+		"SpectatorChatLog",
+		stringPair{first: gameName, second: spectatorName})
+	return mockCollection.ReturnForSpectatorChatLog, mockCollection.ErrorToReturn
+}
+
+// ViewInvitationsForPlayer gets mocked.
+func (mockCollection *mockGameCollection) ViewInvitationsForPlayer(
+	executionContext context.Context,
+	playerName string) ([]game.Lobby, error) {
+	mockCollection.recordFunctionAndArgument(
+		"ViewInvitationsForPlayer",
+		playerName)
+	return mockCollection.ReturnForInvitations, mockCollection.ErrorToReturn
+}
+
+// AcceptInvitation gets mocked.
+func (mockCollection *mockGameCollection) AcceptInvitation(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"AcceptInvitation",
+		stringPair{first: gameName, second: playerName})
+	return mockCollection.ErrorToReturn
+}
+
+// DeclineInvitation gets mocked.
+func (mockCollection *mockGameCollection) DeclineInvitation(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"DeclineInvitation",
+		stringPair{first: gameName, second: playerName})
+	return mockCollection.ErrorToReturn
+}
+
 // RemoveGameFromListForPlayer gets mocked.
 func (mockCollection *mockGameCollection) RemoveGameFromListForPlayer(
 	executionContext context.Context,
@@ -312,6 +595,17 @@ func (mockCollection *mockGameCollection) RemoveGameFromListForPlayer(
 	return mockCollection.ErrorToReturn
 }
 
+// Creator gets mocked, using its own error so that tests of Delete can still
+// reach Delete.
+func (mockCollection *mockGameCollection) Creator(
+	executionContext context.Context,
+	gameName string) (string, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Creator",
+		gameName)
+	return mockCollection.ReturnForCreator, mockCollection.ErrorForCreator
+}
+
 // Delete gets mocked.
 func (mockCollection *mockGameCollection) Delete(
 	executionContext context.Context,
@@ -321,3 +615,53 @@ func (mockCollection *mockGameCollection) Delete(
 		gameName)
 	return mockCollection.ErrorToReturn
 }
+
+// mockContactsProvider returns the same contacts for every player.
+type mockContactsProvider struct {
+	ReturnForContacts player.Contacts
+	ErrorToReturn     error
+}
+
+// Contacts gets mocked.
+func (contactsProvider *mockContactsProvider) Contacts(
+	executionContext context.Context,
+	playerIdentifier string) (player.Contacts, error) {
+	return contactsProvider.ReturnForContacts, contactsProvider.ErrorToReturn
+}
+
+// mockPresenceProvider returns the presence mapped to each player, and offline for any
+// player not in the map.
+type mockPresenceProvider struct {
+	ReturnForPresence map[string]player.Presence
+}
+
+// Presence gets mocked.
+func (presenceProvider *mockPresenceProvider) Presence(
+	executionContext context.Context,
+	playerIdentifier string) player.Presence {
+	playerPresence, hasPresence := presenceProvider.ReturnForPresence[playerIdentifier]
+	if !hasPresence {
+		return player.Presence{Status: player.PresenceOffline}
+	}
+
+	return playerPresence
+}
+
+// mockAuthorizer allows every request unless it has an error to return.
+type mockAuthorizer struct {
+	ErrorToReturn error
+}
+
+// AuthorizeActingPlayer gets mocked.
+func (authorizer *mockAuthorizer) AuthorizeActingPlayer(
+	requestContext context.Context,
+	playerName string) error {
+	return authorizer.ErrorToReturn
+}
+
+// AuthorizeActingPlayerOrAdministrator gets mocked.
+func (authorizer *mockAuthorizer) AuthorizeActingPlayerOrAdministrator(
+	requestContext context.Context,
+	playerName string) error {
+	return authorizer.ErrorToReturn
+}
@@ -0,0 +1,233 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestGetSeriesForPlayerRejectedIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "GET series-as-seen-by-player rejected if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	gameName := "test game"
+	playerName := testPlayers[0]
+
+	_, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"series-as-seen-by-player",
+				segmentTranslatorForTest().ToSegment(gameName),
+				segmentTranslatorForTest().ToSegment(playerName),
+			})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "ViewSeries",
+			FunctionArgument: stringPair{first: gameName, second: playerName},
+		},
+		testIdentifier)
+}
+
+func TestGetSeriesForPlayer(unitTest *testing.T) {
+	testIdentifier := "GET series-as-seen-by-player"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	firstGame := NewMockView()
+	firstGame.MockGameName = "test game"
+	firstGame.MockScore = 17
+	firstGame.MockGameIsFinished = true
+
+	secondGame := NewMockView()
+	secondGame.MockGameName = "test game (game 2)"
+	secondGame.MockScore = 4
+	secondGame.MockGameIsFinished = false
+
+	mockCollection.ReturnForViewSeries = []game_state.ViewForPlayer{firstGame, secondGame}
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"series-as-seen-by-player",
+				segmentTranslatorForTest().ToSegment(secondGame.MockGameName),
+				segmentTranslatorForTest().ToSegment(testPlayers[0]),
+			})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	responseSeriesView, isInterfaceCorrect :=
+		returnedInterface.(parsing.SeriesView)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected parsing.SeriesView",
+			returnedInterface)
+	}
+
+	expectedSeriesView := parsing.SeriesView{
+		Games: []parsing.SeriesGameSummary{
+			parsing.SeriesGameSummary{
+				GameIdentifier: segmentTranslatorForTest().ToSegment(firstGame.MockGameName),
+				GameName:       firstGame.MockGameName,
+				GameIsFinished: true,
+				ScoreSoFar:     17,
+			},
+			parsing.SeriesGameSummary{
+				GameIdentifier: segmentTranslatorForTest().ToSegment(secondGame.MockGameName),
+				GameName:       secondGame.MockGameName,
+				GameIsFinished: false,
+				ScoreSoFar:     4,
+			},
+		},
+		NumberOfFinishedGames: 1,
+		TotalScore:            21,
+		TotalFinishedScore:    17,
+	}
+
+	if !reflect.DeepEqual(responseSeriesView, expectedSeriesView) {
+		unitTest.Fatalf(
+			testIdentifier+"/series view %v did not match expected %v",
+			responseSeriesView,
+			expectedSeriesView)
+	}
+}
+
+func TestRejectRematchIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "Reject POST rematch if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	bodyObject :=
+		parsing.PlayerInGameIndication{
+			GameName:   "test game",
+			PlayerName: testPlayers[0],
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"rematch"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName: "Rematch",
+			FunctionArgument: stringPair{
+				first:  bodyObject.GameName,
+				second: bodyObject.PlayerName,
+			},
+		},
+		testIdentifier)
+}
+
+func TestAcceptValidRematch(unitTest *testing.T) {
+	testIdentifier := "POST rematch"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	rematchName := "test game (game 2)"
+	rematchView := NewMockView()
+	rematchView.MockGameName = rematchName
+	rematchView.MockPlayers = []string{testPlayers[1], testPlayers[0]}
+	rematchView.MockPlayerTurnIndex = 1
+
+	mockCollection.ReturnForRematch = rematchName
+	mockCollection.ReturnForViewState = rematchView
+
+	bodyObject :=
+		parsing.PlayerInGameIndication{
+			GameName:   "test game",
+			PlayerName: testPlayers[0],
+		}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	returnedInterface, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"rematch"})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	expectedTurnSummary := parsing.TurnSummary{
+		GameIdentifier: segmentTranslatorForTest().ToSegment(rematchName),
+		GameName:       rematchName,
+		IsPlayerTurn:   false,
+	}
+
+	if returnedInterface != expectedTurnSummary {
+		unitTest.Fatalf(
+			testIdentifier+"/returned %v instead of expected %v",
+			returnedInterface,
+			expectedTurnSummary)
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		[]functionNameAndArgument{
+			functionNameAndArgument{
+				FunctionName: "Rematch",
+				FunctionArgument: stringPair{
+					first:  bodyObject.GameName,
+					second: bodyObject.PlayerName,
+				},
+			},
+			functionNameAndArgument{
+				FunctionName: "ViewState",
+				FunctionArgument: stringPair{
+					first:  rematchName,
+					second: bodyObject.PlayerName,
+				},
+			},
+		},
+		testIdentifier)
+}
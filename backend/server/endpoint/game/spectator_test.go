@@ -0,0 +1,480 @@
+package game_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/game/card"
+	"github.com/benoleary/ilutulestikud/backend/game/message"
+	player_state "github.com/benoleary/ilutulestikud/backend/player"
+	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestGetGameForSpectatorRejectedIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "GET game-as-seen-by-spectator rejected if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	gameName := "test game"
+	spectatorName := testPlayers[0]
+
+	_, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"game-as-seen-by-spectator",
+				segmentTranslatorForTest().ToSegment(gameName),
+				segmentTranslatorForTest().ToSegment(spectatorName),
+			})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "SpectatorChatLog",
+			FunctionArgument: stringPair{first: gameName, second: spectatorName},
+		},
+		testIdentifier)
+}
+
+func TestGetNeutralGameForSpectator(unitTest *testing.T) {
+	testIdentifier := "GET game-as-seen-by-spectator without seat"
+	mockCollection := &mockGameCollection{}
+	presenceProvider :=
+		&mockPresenceProvider{
+			ReturnForPresence: map[string]player_state.Presence{
+				testPlayers[1]: player_state.Presence{
+					Status:         player_state.PresenceOnline,
+					ViewedGameName: "test game",
+				},
+			},
+		}
+
+	testHandler :=
+		game_endpoint.New(
+			mockCollection,
+			segmentTranslatorForTest(),
+			&mockAuthorizer{},
+			&mockContactsProvider{},
+			presenceProvider)
+
+	gameName := "test game"
+	spectatorName := testPlayers[0]
+	spectatorChatLog := []message.FromPlayer{
+		message.NewFromPlayer(spectatorName, "some color", "Nice play!"),
+	}
+
+	firstHand := game_state.SpectatedHand{
+		PlayerName:        testPlayers[1],
+		PlayerDisplayName: "First Display Name",
+		PlayerColor:       "some color",
+		HandCards: []card.InHand{
+			card.InHand{
+				Defined: card.Defined{ColorSuit: "red", SequenceIndex: 1},
+				Inferred: card.Inferred{
+					PossibleColors:  []string{"red", "green"},
+					PossibleIndices: []int{1},
+				},
+			},
+		},
+	}
+
+	// The second hand is as it would have been stored before players had display names
+	// separate from their identifiers.
+	secondHand := game_state.SpectatedHand{
+		PlayerName:  testPlayers[2],
+		PlayerColor: "another color",
+		HandCards: []card.InHand{
+			card.InHand{
+				Defined: card.Defined{ColorSuit: "green", SequenceIndex: 3},
+				Inferred: card.Inferred{
+					PossibleColors:  []string{"green"},
+					PossibleIndices: []int{2, 3},
+				},
+			},
+		},
+	}
+
+	mockCollection.ReturnForSpectatorChatLog = spectatorChatLog
+	mockCollection.ReturnForViewAsSpectator = game_state.NeutralSnapshot{
+		GameName:                gameName,
+		RulesetIdentifier:       -1,
+		Turn:                    7,
+		Score:                   3,
+		NumberOfReadyHints:      2,
+		NumberOfMistakesMade:    1,
+		DeckSize:                20,
+		TurnsTakenWithEmptyDeck: 1,
+		ActionLog:               []message.FromPlayer{},
+		PlayedCards:             [][]card.Defined{},
+		DiscardedCards:          []card.Defined{},
+		HandsInTurnOrder:        []game_state.SpectatedHand{firstHand, secondHand},
+	}
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"game-as-seen-by-spectator",
+				segmentTranslatorForTest().ToSegment(gameName),
+				segmentTranslatorForTest().ToSegment(spectatorName),
+			})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		[]functionNameAndArgument{
+			functionNameAndArgument{
+				FunctionName:     "SpectatorChatLog",
+				FunctionArgument: stringPair{first: gameName, second: spectatorName},
+			},
+			functionNameAndArgument{
+				FunctionName:     "ViewAsSpectator",
+				FunctionArgument: stringPair{first: gameName, second: spectatorName},
+			},
+		},
+		testIdentifier)
+
+	responseView, isInterfaceCorrect := returnedInterface.(parsing.SpectatorView)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %+v instead of expected parsing.SpectatorView",
+			returnedInterface)
+	}
+
+	if len(responseView.ChatLog) != 1 ||
+		responseView.ChatLog[0].MessageText != spectatorChatLog[0].MessageText {
+		unitTest.Fatalf(
+			testIdentifier+"/spectator view had chat log %+v, expected %+v",
+			responseView.ChatLog,
+			spectatorChatLog)
+	}
+
+	if responseView.TurnOfView != 7 ||
+		responseView.ScoreSoFar != 3 ||
+		responseView.NumberOfCardsLeftInDeck != 20 {
+		unitTest.Fatalf(
+			testIdentifier+"/spectator view %+v did not match snapshot %+v",
+			responseView,
+			mockCollection.ReturnForViewAsSpectator)
+	}
+
+	expectedHands := []parsing.VisibleHand{
+		parsing.VisibleHand{
+			PlayerIdentifier:    firstHand.PlayerName,
+			PlayerName:          firstHand.PlayerDisplayName,
+			PlayerColor:         firstHand.PlayerColor,
+			PlayerPresence:      player_state.PresenceOnline,
+			PlayerIsViewingGame: true,
+			HandCards: []parsing.VisibleCard{
+				parsing.VisibleCard{ColorSuit: "red", SequenceIndex: 1},
+			},
+			KnowledgeOfOwnHand: []parsing.CardFromBehind{
+				parsing.CardFromBehind{
+					PossibleColorSuits:      []string{"red", "green"},
+					PossibleSequenceIndices: []int{1},
+				},
+			},
+			PlayerHasTakenLastTurn: false,
+		},
+		parsing.VisibleHand{
+			PlayerIdentifier: secondHand.PlayerName,
+			PlayerName:       secondHand.PlayerName,
+			PlayerColor:      secondHand.PlayerColor,
+			PlayerPresence:   player_state.PresenceOffline,
+			HandCards: []parsing.VisibleCard{
+				parsing.VisibleCard{ColorSuit: "green", SequenceIndex: 3},
+			},
+			KnowledgeOfOwnHand: []parsing.CardFromBehind{
+				parsing.CardFromBehind{
+					PossibleColorSuits:      []string{"green"},
+					PossibleSequenceIndices: []int{2, 3},
+				},
+			},
+			PlayerHasTakenLastTurn: true,
+		},
+	}
+
+	if !reflect.DeepEqual(responseView.HandsInTurnOrder, expectedHands) {
+		unitTest.Fatalf(
+			testIdentifier+"/spectator view had hands %+v, expected %+v",
+			responseView.HandsInTurnOrder,
+			expectedHands)
+	}
+}
+
+func TestGetGameForSpectatorFromSeat(unitTest *testing.T) {
+	testIdentifier := "GET game-as-seen-by-spectator from seat of participant"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	gameName := "test game"
+	spectatorName := testPlayers[0]
+	participantName := testPlayers[1]
+	spectatorChatLog := []message.FromPlayer{
+		message.NewFromPlayer(spectatorName, "some color", "Nice play!"),
+	}
+
+	testView := NewMockView()
+	testView.MockPlayers = []string{participantName, testPlayers[2]}
+	testView.MockPlayerTurnIndex = 0
+	testView.MockChatLog = []message.FromPlayer{
+		message.NewFromPlayer(participantName, "another color", "first message"),
+		message.NewFromPlayer(testPlayers[2], "another color", "second message"),
+	}
+
+	mockCollection.ReturnForSpectatorChatLog = spectatorChatLog
+	mockCollection.ReturnForViewState = testView
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"game-as-seen-by-spectator",
+				segmentTranslatorForTest().ToSegment(gameName),
+				segmentTranslatorForTest().ToSegment(spectatorName),
+				segmentTranslatorForTest().ToSegment(participantName),
+			})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		[]functionNameAndArgument{
+			functionNameAndArgument{
+				FunctionName:     "SpectatorChatLog",
+				FunctionArgument: stringPair{first: gameName, second: spectatorName},
+			},
+			functionNameAndArgument{
+				FunctionName: "ViewAsSpectatorFromSeat",
+				FunctionArgument: stringTriple{
+					first:  gameName,
+					second: spectatorName,
+					third:  participantName,
+				},
+			},
+		},
+		testIdentifier)
+
+	responseGameView, isInterfaceCorrect := returnedInterface.(parsing.GameView)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %+v instead of expected parsing.GameView",
+			returnedInterface)
+	}
+
+	if len(responseGameView.ChatLog) != 1 ||
+		responseGameView.ChatLog[0].PlayerName != spectatorName {
+		unitTest.Fatalf(
+			testIdentifier+"/game view had chat log %+v, expected only spectator chat %+v",
+			responseGameView.ChatLog,
+			spectatorChatLog)
+	}
+
+	if responseGameView.ThisPlayerCanTakeTurn {
+		unitTest.Fatal(
+			testIdentifier + "/game view for spectator allowed a turn to be taken")
+	}
+}
+
+func TestRejectInvalidSetSpectatingWithMalformedRequest(unitTest *testing.T) {
+	testIdentifier := "Reject invalid POST set-spectating with malformed JSON body"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	bodyString := "{\"PlayerName\" :\"Something\", \"DelayInTurns\":}"
+
+	bodyDecoder :=
+		json.NewDecoder(bytes.NewReader(bytes.NewBufferString(bodyString).Bytes()))
+
+	_, responseCode :=
+		testHandler.HandlePost(
+			context.Background(),
+			bodyDecoder,
+			[]string{"set-spectating"})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+
+				"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	assertNoFunctionWasCalled(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		testIdentifier)
+}
+
+func TestSetSpectating(unitTest *testing.T) {
+	testCases := []struct {
+		testName             string
+		errorFromCollection  error
+		expectedResponseCode int
+	}{
+		{
+			testName:             "Rejected by collection",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Accepted by collection",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST set-spectating/" + testCase.testName
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection, testHandler := newGameCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+
+			bodyObject := parsing.SpectatingSettings{
+				PlayerInGameIndication: parsing.PlayerInGameIndication{
+					GameName:   "test game",
+					PlayerName: testPlayers[0],
+				},
+				SpectatingIsAllowed: true,
+				DelayInTurns:        3,
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{"set-spectating"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			functionRecord :=
+				mockCollection.getFirstAndEnsureOnly(
+					unitTest,
+					testIdentifier)
+
+			assertFunctionRecordIsCorrect(
+				unitTest,
+				functionRecord,
+				functionNameAndArgument{
+					FunctionName: "SetSpectating",
+					FunctionArgument: mockSpectatingSettings{
+						GameName:            bodyObject.GameName,
+						PlayerName:          bodyObject.PlayerName,
+						SpectatingIsAllowed: bodyObject.SpectatingIsAllowed,
+						DelayInTurns:        bodyObject.DelayInTurns,
+					},
+				},
+				testIdentifier)
+		})
+	}
+}
+
+func TestRecordSpectatorChatMessage(unitTest *testing.T) {
+	testCases := []struct {
+		testName             string
+		errorFromCollection  error
+		expectedResponseCode int
+	}{
+		{
+			testName:             "Rejected by collection",
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			testName:             "Accepted by collection",
+			errorFromCollection:  nil,
+			expectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST record-spectator-chat-message/" + testCase.testName
+
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection, testHandler := newGameCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+
+			bodyObject := parsing.PlayerChatMessage{
+				PlayerInGameIndication: parsing.PlayerInGameIndication{
+					GameName:   "test game",
+					PlayerName: testPlayers[0],
+				},
+				ChatMessage: "Nice play!",
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{"record-spectator-chat-message"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			functionRecord :=
+				mockCollection.getFirstAndEnsureOnly(
+					unitTest,
+					testIdentifier)
+
+			assertFunctionRecordIsCorrect(
+				unitTest,
+				functionRecord,
+				functionNameAndArgument{
+					FunctionName: "RecordSpectatorChatMessage",
+					FunctionArgument: stringTriple{
+						first:  bodyObject.GameName,
+						second: bodyObject.PlayerName,
+						third:  bodyObject.ChatMessage,
+					},
+				},
+				testIdentifier)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestGetPlayerStatisticsRejectedIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "GET player-statistics rejected if collection rejects it"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	playerName := testPlayers[0]
+
+	_, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"player-statistics",
+				segmentTranslatorForTest().ToSegment(playerName),
+			})
+
+	if responseCode != http.StatusBadRequest {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusBadRequest,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "StatisticsForPlayer",
+			FunctionArgument: playerName,
+		},
+		testIdentifier)
+}
+
+func TestGetPlayerStatistics(unitTest *testing.T) {
+	testIdentifier := "GET player-statistics"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	playerName := testPlayers[1]
+	testRuleset := game_state.NewStandardWithoutRainbow()
+
+	mockCollection.ReturnForStatisticsForPlayer = game_state.PlayerStatistics{
+		PlayerName:                               playerName,
+		NumberOfGamesPlayed:                      4,
+		NumberOfGamesFinished:                    3,
+		NumberOfTurnsTaken:                       40,
+		NumberOfDiscards:                         10,
+		NumberOfMistakes:                         2,
+		DiscardRate:                              0.25,
+		MistakeRate:                              0.05,
+		CurrentStreakWithoutGameOverFromMistakes: 2,
+		LongestStreakWithoutGameOverFromMistakes: 3,
+		ByRulesetAndPlayerCount: []game_state.StatisticsForRulesetAndPlayerCount{
+			game_state.StatisticsForRulesetAndPlayerCount{
+				RulesetIdentifier:     testRuleset.BackendIdentifier(),
+				NumberOfPlayers:       3,
+				NumberOfGamesFinished: 3,
+				NumberOfPerfectGames:  1,
+				AverageScore:          20.5,
+				BestScore:             25,
+				MaximumScore:          25,
+			},
+		},
+	}
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(
+			context.Background(),
+			[]string{
+				"player-statistics",
+				segmentTranslatorForTest().ToSegment(playerName),
+			})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	responseStatistics, isInterfaceCorrect :=
+		returnedInterface.(parsing.PlayerStatistics)
+
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected parsing.PlayerStatistics",
+			returnedInterface)
+	}
+
+	expectedStatistics := parsing.PlayerStatistics{
+		PlayerIdentifier:                         segmentTranslatorForTest().ToSegment(playerName),
+		NumberOfGamesPlayed:                      4,
+		NumberOfGamesFinished:                    3,
+		DiscardRate:                              0.25,
+		MistakeRate:                              0.05,
+		CurrentStreakWithoutGameOverFromMistakes: 2,
+		LongestStreakWithoutGameOverFromMistakes: 3,
+		ByRulesetAndPlayerCount: []parsing.StatisticsForRulesetAndPlayerCount{
+			parsing.StatisticsForRulesetAndPlayerCount{
+				RulesetIdentifier:     testRuleset.BackendIdentifier(),
+				RulesetDescription:    testRuleset.FrontendDescription(),
+				NumberOfPlayers:       3,
+				NumberOfGamesFinished: 3,
+				NumberOfPerfectGames:  1,
+				AverageScore:          20.5,
+				BestScore:             25,
+				MaximumScore:          25,
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(responseStatistics, expectedStatistics) {
+		unitTest.Fatalf(
+			testIdentifier+"/statistics %+v did not match expected %+v",
+			responseStatistics,
+			expectedStatistics)
+	}
+}
@@ -0,0 +1,245 @@
+package game_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	game_state "github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// newMockViewForStream prepares a mock view which can be converted for the frontend
+// for any of the test players.
+func newMockViewForStream() *mockViewForPlayer {
+	mockView := NewMockView()
+	mockView.MockPlayers = testPlayers
+	mockView.MockPlayerTurnIndex = 0
+
+	return mockView
+}
+
+func TestStreamWithInvalidSegmentsBadRequest(unitTest *testing.T) {
+	mockGameIdentifier := segmentTranslatorForTest().ToSegment("Mock game")
+
+	testCases := []struct {
+		testName         string
+		relevantSegments []string
+		expectedCode     int
+	}{
+		{
+			testName:         "No segments",
+			relevantSegments: []string{},
+			expectedCode:     http.StatusBadRequest,
+		},
+		{
+			testName:         "Unknown stream",
+			relevantSegments: []string{"all-games-with-player", mockGameIdentifier},
+			expectedCode:     http.StatusNotFound,
+		},
+		{
+			testName:         "No player segment",
+			relevantSegments: []string{"game-as-seen-by-player", mockGameIdentifier},
+			expectedCode:     http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			testIdentifier := "Stream " + testCase.testName
+			mockCollection, testHandler := newGameCollectionAndHandler()
+
+			sendUpdate := func(endpointObject interface{}) error {
+				unitTest.Fatalf(testIdentifier+"/unexpectedly sent update %+v", endpointObject)
+				return nil
+			}
+
+			_, responseCode :=
+				testHandler.HandleStream(
+					context.Background(),
+					testCase.relevantSegments,
+					sendUpdate)
+
+			if responseCode != testCase.expectedCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedCode,
+					responseCode)
+			}
+
+			assertNoFunctionWasCalled(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testIdentifier)
+		})
+	}
+}
+
+func TestStreamGameForPlayerSendsViewAfterEveryChange(unitTest *testing.T) {
+	testIdentifier := "Stream game-as-seen-by-player"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	mockGameName := "Mock game"
+	mockPlayerName := testPlayers[0]
+	changeNotices := make(chan game_state.ChangeNotice, 1)
+	mockCollection.ReturnForSubscribeToChanges = changeNotices
+	mockCollection.ReturnForViewState = newMockViewForStream()
+
+	streamContext, cancelStream := context.WithCancel(context.Background())
+	defer cancelStream()
+
+	// The first update is the view straight away, and the second is the view after
+	// the change, after which the subscriber goes away.
+	sentUpdates := make([]interface{}, 0)
+	sendUpdate := func(endpointObject interface{}) error {
+		sentUpdates = append(sentUpdates, endpointObject)
+
+		if len(sentUpdates) == 1 {
+			changeNotices <- game_state.ChangeNotice{GameName: mockGameName}
+		} else {
+			cancelStream()
+		}
+
+		return nil
+	}
+
+	_, responseCode :=
+		testHandler.HandleStream(
+			streamContext,
+			[]string{
+				"game-as-seen-by-player",
+				segmentTranslatorForTest().ToSegment(mockGameName),
+				segmentTranslatorForTest().ToSegment(mockPlayerName),
+			},
+			sendUpdate)
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	if len(sentUpdates) != 2 {
+		unitTest.Fatalf(
+			testIdentifier+"/sent %v updates, expected 2: %+v",
+			len(sentUpdates),
+			sentUpdates)
+	}
+
+	for _, sentUpdate := range sentUpdates {
+		sentBody := bodyFromTagged(unitTest, testIdentifier, sentUpdate)
+		if _, isGameView := sentBody.(parsing.GameView); !isGameView {
+			unitTest.Fatalf(
+				testIdentifier+"/sent %+v instead of expected parsing.GameView",
+				sentUpdate)
+		}
+	}
+
+	viewRecord := functionNameAndArgument{
+		FunctionName:     "ViewState",
+		FunctionArgument: stringPair{first: mockGameName, second: mockPlayerName},
+	}
+
+	expectedRecords := []functionNameAndArgument{
+		functionNameAndArgument{
+			FunctionName:     "SubscribeToChanges",
+			FunctionArgument: mockGameName,
+		},
+		viewRecord,
+		viewRecord,
+		functionNameAndArgument{
+			FunctionName:     "UnsubscribeFromChanges",
+			FunctionArgument: mockGameName,
+		},
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		expectedRecords,
+		testIdentifier)
+}
+
+func TestStreamGameForPlayerEndsWhenViewFails(unitTest *testing.T) {
+	testIdentifier := "Stream game-as-seen-by-player ends when view fails"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	mockGameName := "Mock game"
+	mockPlayerName := testPlayers[0]
+	mockCollection.ReturnForSubscribeToChanges = make(chan game_state.ChangeNotice, 1)
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	sendUpdate := func(endpointObject interface{}) error {
+		unitTest.Fatalf(testIdentifier+"/unexpectedly sent update %+v", endpointObject)
+		return nil
+	}
+
+	_, responseCode :=
+		testHandler.HandleStream(
+			context.Background(),
+			[]string{
+				"game-as-seen-by-player",
+				segmentTranslatorForTest().ToSegment(mockGameName),
+				segmentTranslatorForTest().ToSegment(mockPlayerName),
+			},
+			sendUpdate)
+
+	if responseCode != http.StatusInternalServerError {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusInternalServerError,
+			responseCode)
+	}
+
+	expectedRecords := []functionNameAndArgument{
+		functionNameAndArgument{
+			FunctionName:     "SubscribeToChanges",
+			FunctionArgument: mockGameName,
+		},
+		functionNameAndArgument{
+			FunctionName:     "ViewState",
+			FunctionArgument: stringPair{first: mockGameName, second: mockPlayerName},
+		},
+		functionNameAndArgument{
+			FunctionName:     "UnsubscribeFromChanges",
+			FunctionArgument: mockGameName,
+		},
+	}
+
+	assertFunctionRecordsAreCorrect(
+		unitTest,
+		mockCollection.FunctionsAndArgumentsReceived,
+		expectedRecords,
+		testIdentifier)
+}
+
+func TestStreamGameForPlayerEndsWhenSendFails(unitTest *testing.T) {
+	testIdentifier := "Stream game-as-seen-by-player ends when send fails"
+	mockCollection, testHandler := newGameCollectionAndHandler()
+
+	mockCollection.ReturnForSubscribeToChanges = make(chan game_state.ChangeNotice, 1)
+	mockCollection.ReturnForViewState = newMockViewForStream()
+
+	sendUpdate := func(endpointObject interface{}) error {
+		return errors.New("expected error")
+	}
+
+	_, responseCode :=
+		testHandler.HandleStream(
+			context.Background(),
+			[]string{
+				"game-as-seen-by-player",
+				segmentTranslatorForTest().ToSegment("Mock game"),
+				segmentTranslatorForTest().ToSegment(testPlayers[0]),
+			},
+			sendUpdate)
+
+	if responseCode != http.StatusInternalServerError {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusInternalServerError,
+			responseCode)
+	}
+}
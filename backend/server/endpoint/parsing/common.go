@@ -3,10 +3,31 @@ package parsing
 // Types emitted and accepted by server.playerEndpointHandler:
 
 // PlayerState encapsulates the information from player.ReadonlyState suitable
-// for the frontend, including the identifier to ensure that it can be passed
-// as a URI segment when making a GET request.
+// for the frontend. PlayerIdentifier is the stable identifier of the player which
+// does not change when the player is renamed, and which is the key for requests
+// to update the player, and Identifier is the form of it which can be passed as a
+// URI segment when making a GET request. Name is the display name of the player.
+// The role is only read from requests to change the role of a player, and the
+// profile is only read from requests to change the profile of a player. Presence is
+// "online", "idle", or "offline", and is never read from requests.
 type PlayerState struct {
-	Identifier string
-	Name       string
-	Color      string
+	Identifier       string
+	PlayerIdentifier string
+	Name             string
+	Color            string
+	Role             string
+	Profile          PlayerProfile
+	Presence         string
+}
+
+// PlayerProfile encapsulates the preferences from player.Profile suitable for the
+// frontend. PreferredHintDisplay is "text", "symbols", or empty, and Timezone is the
+// name of a location in the IANA Time Zone database, or empty.
+type PlayerProfile struct {
+	PreferredRulesets    []int
+	PreferredHintDisplay string
+	Timezone             string
+	NotifyOnTurn         bool
+	NotifyOnInvitation   bool
+	NotifyOnChatMessage  bool
 }
@@ -0,0 +1,28 @@
+package parsing
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+)
+
+// NewTaggedBody wraps the given object with an entity tag made from the given prefix,
+// if it is not empty, and a hash of the JSON representation of the object, so that the
+// tag changes whenever the written body would change.
+func NewTaggedBody(endpointObject interface{}, tagPrefix string) TaggedBody {
+	bodyHash := fnv.New64a()
+
+	// Errors cannot arise from writing to a hash, and any error from encoding the object
+	// will also arise when the body is written, so it is ignored here.
+	json.NewEncoder(bodyHash).Encode(endpointObject)
+
+	entityTag := strconv.FormatUint(bodyHash.Sum64(), 16)
+	if tagPrefix != "" {
+		entityTag = tagPrefix + "-" + entityTag
+	}
+
+	return TaggedBody{
+		EntityTag: entityTag,
+		Body:      endpointObject,
+	}
+}
@@ -0,0 +1,54 @@
+package parsing
+
+import (
+	"net/http"
+
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+// httpStatusesForCodes maps the codes of errors to the HTTP statuses which should be
+// returned with them. Codes which are not in the map get the fallback status given to
+// StatusForError.
+var httpStatusesForCodes = map[string]int{
+	failure.CodeNotAuthenticated:     http.StatusUnauthorized,
+	failure.CodeIncorrectCredentials: http.StatusUnauthorized,
+	failure.CodeForbidden:            http.StatusForbidden,
+	failure.CodeNotParticipant:       http.StatusForbidden,
+	failure.CodeNotInvited:           http.StatusForbidden,
+	failure.CodeSpectatingNotAllowed: http.StatusForbidden,
+	failure.CodeUnknownPlayer:        http.StatusNotFound,
+	failure.CodeUnknownGame:          http.StatusNotFound,
+	failure.CodeDeletedPlayer:        http.StatusGone,
+	failure.CodeNameTaken:            http.StatusConflict,
+	failure.CodePlayerInGame:         http.StatusConflict,
+	failure.CodeNotYourTurn:          http.StatusConflict,
+	failure.CodeGameFinished:         http.StatusConflict,
+	failure.CodeGameNotFinished:      http.StatusConflict,
+	failure.CodeNoHintsLeft:          http.StatusConflict,
+	failure.CodeNoOpenSeats:          http.StatusConflict,
+	failure.CodeTurnConflict:         http.StatusConflict,
+	failure.CodeUnknownRuleset:       http.StatusBadRequest,
+	failure.CodeHintToSelf:           http.StatusBadRequest,
+	failure.CodeInvalidCardIndex:     http.StatusBadRequest,
+}
+
+// StatusForError returns the HTTP status which corresponds to the code of the given
+// error, or the given fallback status if the error has no code with a status of its
+// own.
+func StatusForError(errorToReport error, fallbackStatus int) int {
+	httpStatus, hasStatus := httpStatusesForCodes[failure.CodeOf(errorToReport)]
+	if !hasStatus {
+		return fallbackStatus
+	}
+
+	return httpStatus
+}
+
+// ErrorForBodyFrom wraps the message and code of the given error in the object which
+// the frontend recognizes as an error.
+func ErrorForBodyFrom(errorForBody error) *ErrorForBody {
+	return &ErrorForBody{
+		Error: errorForBody.Error(),
+		Code:  failure.CodeOf(errorForBody),
+	}
+}
@@ -0,0 +1,95 @@
+package parsing_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func TestStatusAndCodeForError(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		errorToReport  error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			testName:       "error without code",
+			errorToReport:  fmt.Errorf("Test error"),
+			expectedStatus: http.StatusTeapot,
+			expectedCode:   "unclassified",
+		},
+		{
+			testName:       "not your turn",
+			errorToReport:  game.ErrNotYourTurn.Errorf("Player %v is not the current player", "a"),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "not-your-turn",
+		},
+		{
+			testName:       "no hints left",
+			errorToReport:  game.ErrNoHintsLeft,
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "no-hints-left",
+		},
+		{
+			testName: "wrapped unknown game",
+			errorToReport: fmt.Errorf(
+				"Could not find game (%w)",
+				game.ErrUnknownGame.Errorf("Game %v does not exist", "a")),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "unknown-game",
+		},
+		{
+			testName:       "turn conflict",
+			errorToReport:  game.NewTurnConflictError("a", 1, 2),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "turn-conflict",
+		},
+		{
+			testName:       "unknown player",
+			errorToReport:  player.ErrUnknownPlayer.Errorf("No player %v", "a"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "unknown-player",
+		},
+		{
+			testName:       "name taken",
+			errorToReport:  player.ErrNameTaken.Errorf("Player %v already exists", "a"),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "name-taken",
+		},
+		{
+			testName:       "forbidden",
+			errorToReport:  authentication.NewForbiddenError("Not allowed"),
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "forbidden",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			actualStatus := parsing.StatusForError(testCase.errorToReport, http.StatusTeapot)
+			if actualStatus != testCase.expectedStatus {
+				unitTest.Fatalf(
+					"StatusForError(%v, ...) returned %v instead of %v",
+					testCase.errorToReport,
+					actualStatus,
+					testCase.expectedStatus)
+			}
+
+			errorForBody := parsing.ErrorForBodyFrom(testCase.errorToReport)
+			if (errorForBody.Code != testCase.expectedCode) ||
+				(errorForBody.Error != testCase.errorToReport.Error()) {
+				unitTest.Fatalf(
+					"ErrorForBodyFrom(%v) returned %+v, expected code %v",
+					testCase.errorToReport,
+					errorForBody,
+					testCase.expectedCode)
+			}
+		})
+	}
+}
@@ -1,12 +1,97 @@
 package parsing
 
+import (
+	"time"
+)
+
+// Types accepted by server.playerEndpointHandler:
+
+// NewPlayerDefinition encapsulates the necessary information to register a new player,
+// which is the state of the player along with the password which the player will use
+// to log in.
+type NewPlayerDefinition struct {
+	Name     string
+	Color    string
+	Password string
+}
+
+// PlayerCredentials is a struct to hold the name and password of a player logging in.
+type PlayerCredentials struct {
+	Name     string
+	Password string
+}
+
+// PlayerSearch holds the criteria for a page of players. SortOrder is "name" or an
+// empty string for ascending order of names, or "-name" for descending order. Cursor is
+// the NextCursor of the previous page, or empty for the first page. A PageSize which is
+// not positive gives the default page size.
+type PlayerSearch struct {
+	NamePrefix string
+	SortOrder  string
+	Cursor     string
+	PageSize   int
+}
+
+// Heartbeat is sent regularly by the frontend to show that the player given by
+// PlayerIdentifier is present. ViewedGameName is the name of the game which the player
+// has open, or empty, and IsIdle is true if the player has not interacted with the
+// frontend for a while, for example because it is in a hidden tab.
+type Heartbeat struct {
+	PlayerIdentifier string
+	ViewedGameName   string
+	IsIdle           bool
+}
+
+// FriendIndication is a struct to identify a player and another player whom the first
+// player adds to or removes from their friends.
+type FriendIndication struct {
+	PlayerIdentifier string
+	FriendIdentifier string
+}
+
+// GroupDefinition holds a named group of players saved by the player given by
+// PlayerIdentifier, who is not a member of the group but is always the host of games
+// created from it. MemberIdentifiers is ignored when deleting a group.
+type GroupDefinition struct {
+	PlayerIdentifier  string
+	GroupName         string
+	MemberIdentifiers []string
+}
+
 // Types accepted by server.gameEndpointHandler:
+//
+// Players are referred to by the stable identifiers which are given as
+// PlayerIdentifier in PlayerState, rather than by their display names, so the
+// fields with names such as PlayerName, PlayerNames, or HostName hold player
+// identifiers. This keeps games intact when players rename themselves.
 
-// GameDefinition encapsulates the necessary information to create a new game.
+// GameDefinition encapsulates the necessary information to create a new game. If
+// GroupName is not empty, PlayerNames should hold only the host, and the members of
+// the group of the host with that name are invited along with the host.
 type GameDefinition struct {
 	GameName          string
 	RulesetIdentifier int
 	PlayerNames       []string
+	GroupName         string
+}
+
+// HostName returns the name of the first player, who is the host of the new game, or
+// an empty string if there are no players.
+func (gameDefinition GameDefinition) HostName() string {
+	if len(gameDefinition.PlayerNames) < 1 {
+		return ""
+	}
+
+	return gameDefinition.PlayerNames[0]
+}
+
+// LobbyDefinition encapsulates the necessary information to create a new lobby with open
+// seats for a game which will be dealt once all the seats are filled.
+type LobbyDefinition struct {
+	GameName          string
+	RulesetIdentifier int
+	NumberOfSeats     int
+	HostName          string
 }
 
 // PlayerInGameIndication is a struct to identify a player and a game together.
@@ -22,16 +107,22 @@ type PlayerChatMessage struct {
 }
 
 // PlayerCardIndication is a struct to hold a single indication of a card in the hand of
-// a player, from that player to a game.
+// a player, from that player to a game, along with the turn of the game, as given in the
+// view of the game, which the player saw when choosing the card, so that the turn is
+// rejected if another turn has been taken since. Chat messages do not change the turn.
+// An ExpectedTurn of 0, as sent by clients which leave it out, is not checked.
 type PlayerCardIndication struct {
 	PlayerInGameIndication
-	CardIndex int
+	ExpectedTurn int
+	CardIndex    int
 }
 
 // PlayerHintToReceiver is a struct to hold a single hint from a (hinting) player to a
-// receiving player.
+// receiving player, along with the turn of the game which the hinting player saw when
+// choosing the hint, as for PlayerCardIndication.
 type PlayerHintToReceiver struct {
 	PlayerInGameIndication
+	ExpectedTurn int
 	ReceiverName string
 }
 
@@ -48,3 +139,25 @@ type PlayerIndexHint struct {
 	PlayerHintToReceiver
 	HintedNumber int
 }
+
+// SpectatingSettings is a struct to hold whether the creator of a game allows players
+// who are not participants to spectate it, and how many turns the view where every
+// hand is visible should lag behind the game.
+type SpectatingSettings struct {
+	PlayerInGameIndication
+	SpectatingIsAllowed bool
+	DelayInTurns        int
+}
+
+// LeaderboardRequest holds the ruleset and number of players of the games to rank on a
+// leaderboard, with the range of time in which they finished. FinishedFrom and
+// FinishedBefore may be omitted for no bound on that side, so that omitting both
+// covers all time. A MaximumNumberOfEntries which is not positive gives the default
+// number of entries.
+type LeaderboardRequest struct {
+	RulesetIdentifier      int
+	NumberOfPlayers        int
+	FinishedFrom           time.Time
+	FinishedBefore         time.Time
+	MaximumNumberOfEntries int
+}
@@ -1,14 +1,30 @@
 package parsing
 
+import (
+	"time"
+)
+
 // Types emitted by server.State:
 
 type VersionForBody struct {
 	Version string
 }
 
-// ErrorForBody allows a string to be expressed as JSON stating that it is an error.
+// ErrorForBody allows a string to be expressed as JSON stating that it is an error,
+// along with a code which identifies the kind of error and which, unlike the string,
+// does not change when the wording of the message changes.
 type ErrorForBody struct {
 	Error string
+	Code  string
+}
+
+// TaggedBody wraps an object which should be written as the body of a response along
+// with an entity tag identifying its content, which server.State sends as the ETag
+// header so that clients can make conditional requests. The wrapper itself is never
+// written as JSON, only the body which it wraps.
+type TaggedBody struct {
+	EntityTag string
+	Body      interface{}
 }
 
 // Types emitted by server.playerEndpointHandler:
@@ -18,12 +34,45 @@ type PlayerList struct {
 	Players []PlayerState
 }
 
+// PlayerPage holds a page of players found by a search, along with the cursor which
+// should be sent to get the next page, which is empty if there are no more players.
+type PlayerPage struct {
+	Players    []PlayerState
+	NextCursor string
+}
+
+// PlayerGroup holds a named group of players, referred to by their identifiers.
+type PlayerGroup struct {
+	GroupName         string
+	MemberIdentifiers []string
+}
+
+// ContactList holds the friends of a player and the groups of players saved by the
+// player. It is only sent to the player who owns it.
+type ContactList struct {
+	Friends []PlayerState
+	Groups  []PlayerGroup
+}
+
 // ChatColorList ensures that the list of available chat colors is encapsulated within a single JSON object.
 type ChatColorList struct {
 	Colors []string
 }
 
+// SessionToken holds the signed token which a player who has logged in should send in
+// the Authorization header of subsequent requests made on behalf of that player, along
+// with the identifiers and display name of the player, as in PlayerState.
+type SessionToken struct {
+	Identifier       string
+	PlayerIdentifier string
+	PlayerName       string
+	Token            string
+}
+
 // Types emitted by server.gameEndpointHandler:
+//
+// As for the types accepted by server.gameEndpointHandler, fields with names such as
+// HostName or SeatedPlayerNames hold player identifiers, except where noted.
 
 // SelectableRuleset contains the information required to enable a player to select a ruleset,
 // plus the pertinent information from the ruleset to allow the frontend to form a valid request
@@ -43,11 +92,13 @@ type RulesetList struct {
 }
 
 // TurnSummary contains the information to determine what games involve a player and whose turn it is.
+// The version increases whenever the state of the game changes.
 // All the fields need to be public so that the JSON encoder can see them to serialize them.
 type TurnSummary struct {
 	GameIdentifier string
 	GameName       string
 	IsPlayerTurn   bool
+	Version        int
 }
 
 // TurnSummaryList ensures that the TurnSummary list is encapsulated within a single JSON object.
@@ -55,6 +106,132 @@ type TurnSummaryList struct {
 	TurnSummaries []TurnSummary
 }
 
+// LobbySummary contains the information about a game which has not yet been dealt so that
+// players can see which seats are still open and join it.
+type LobbySummary struct {
+	GameIdentifier     string
+	GameName           string
+	RulesetIdentifier  int
+	RulesetDescription string
+	HostName           string
+	NumberOfSeats      int
+	SeatedPlayerNames  []string
+}
+
+// LobbyList ensures that the LobbySummary list is encapsulated within a single JSON object.
+type LobbyList struct {
+	Lobbies []LobbySummary
+}
+
+// InvitationSummary contains the information about a game to which a player has been
+// invited so that the player can decide whether to accept or to decline.
+type InvitationSummary struct {
+	GameIdentifier      string
+	GameName            string
+	RulesetDescription  string
+	HostName            string
+	InvitedPlayerNames  []string
+	AcceptedPlayerNames []string
+}
+
+// InvitationList ensures that the InvitationSummary list is encapsulated within a single
+// JSON object.
+type InvitationList struct {
+	Invitations []InvitationSummary
+}
+
+// SeriesGameSummary contains the information about a single game within a series of
+// rematches.
+type SeriesGameSummary struct {
+	GameIdentifier string
+	GameName       string
+	GameIsFinished bool
+	ScoreSoFar     int
+}
+
+// SeriesView contains the information about all the games in a series of rematches,
+// in the order in which they were played, along with the scores summed over all the
+// games of the series.
+type SeriesView struct {
+	Games                 []SeriesGameSummary
+	NumberOfFinishedGames int
+	TotalScore            int
+	TotalFinishedScore    int
+}
+
+// StatisticsForRulesetAndPlayerCount contains the statistics of a player over the
+// finished games with a particular ruleset and number of players.
+type StatisticsForRulesetAndPlayerCount struct {
+	RulesetIdentifier     int
+	RulesetDescription    string
+	NumberOfPlayers       int
+	NumberOfGamesFinished int
+	NumberOfPerfectGames  int
+	AverageScore          float64
+	BestScore             int
+	MaximumScore          int
+}
+
+// PlayerStatistics contains the statistics of a player over all the finished games
+// in which the player was a participant. The rates of discards and mistakes are per
+// turn taken by the player, and the streaks count consecutive finished games which
+// did not end because of too many mistakes.
+type PlayerStatistics struct {
+	PlayerIdentifier                         string
+	NumberOfGamesPlayed                      int
+	NumberOfGamesFinished                    int
+	DiscardRate                              float64
+	MistakeRate                              float64
+	CurrentStreakWithoutGameOverFromMistakes int
+	LongestStreakWithoutGameOverFromMistakes int
+	ByRulesetAndPlayerCount                  []StatisticsForRulesetAndPlayerCount
+}
+
+// LeaderboardEntry contains the information about a single finished game on a
+// leaderboard. PlayerIdentifiers are the stable identifiers of the participants and
+// PlayerNames are their display names, in the same order.
+type LeaderboardEntry struct {
+	GameName           string
+	PlayerIdentifiers  []string
+	PlayerNames        []string
+	Score              int
+	NumberOfTurnsTaken int
+	ElapsedSeconds     int64
+	TimeOfFinish       time.Time
+}
+
+// Leaderboard contains the finished games with the highest scores and the perfect
+// games which were finished in the shortest time, for a single ruleset and number of
+// players over a range of time.
+type Leaderboard struct {
+	RulesetIdentifier   int
+	RulesetDescription  string
+	NumberOfPlayers     int
+	HighestScores       []LeaderboardEntry
+	FastestPerfectGames []LeaderboardEntry
+}
+
+// SpectatorView contains the information of what a spectator who is not sitting in
+// the seat of any participant can see about a game, which includes the hands of all
+// the players in the order of their next turns, and the chat log of the spectators
+// rather than that of the participants. The view may be of the game as it was some
+// turns ago, as given by TurnOfView.
+type SpectatorView struct {
+	ChatLog                            []LogMessage
+	ActionLog                          []LogMessage
+	TurnOfView                         int
+	GameIsFinished                     bool
+	ScoreSoFar                         int
+	NumberOfReadyHints                 int
+	MaximumNumberOfHints               int
+	NumberOfMistakesMade               int
+	NumberOfMistakesIndicatingGameOver int
+	NumberOfCardsLeftInDeck            int
+	PlayedCards                        [][]VisibleCard
+	DiscardedCards                     []VisibleCard
+	HandsInTurnOrder                   []VisibleHand
+}
+
 // LogMessage is a struct to hold the details of a single outgoing log message.
 type LogMessage struct {
 	TimestampInSeconds int64
@@ -72,13 +249,19 @@ type VisibleCard struct {
 
 // VisibleHand is a struct to hold the details of the hand of cards held by a player
 // other than the player who is viewing the game state, including what the holding
-// player knows about the cards.
+// player knows about the cards. PlayerIdentifier is the stable identifier of the
+// holding player, to be used when giving hints, and PlayerName is the display name.
+// PlayerPresence is "online", "idle", or "offline", and PlayerIsViewingGame is true if
+// the holding player currently has this game open.
 type VisibleHand struct {
+	PlayerIdentifier       string
 	PlayerName             string
 	PlayerColor            string
 	HandCards              []VisibleCard
 	KnowledgeOfOwnHand     []CardFromBehind
 	PlayerHasTakenLastTurn bool
+	PlayerPresence         string
+	PlayerIsViewingGame    bool
 }
 
 // CardFromBehind is a struct to hold the details of a single outgoing card as known
@@ -97,7 +280,11 @@ type CardFromBehind struct {
 //    turn, in order.
 // The lists for before and after may be empty, if this player is the first
 // or last in order at the moment, respectively.
+// The version increases whenever the state of the game changes, while the turn
+// increases only when a turn is taken, and is the turn which actions should expect.
 type GameView struct {
+	Version                            int
+	Turn                               int
 	ChatLog                            []LogMessage
 	ActionLog                          []LogMessage
 	GameIsFinished                     bool
@@ -0,0 +1,151 @@
+package parsing
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/benoleary/ilutulestikud/backend/failure"
+)
+
+// Types emitted and accepted by server.httpResourceHandler:
+//
+// The second version of the API wraps everything in envelopes modelled on JSON:API,
+// so the envelopes have lower-case JSON names. The attributes inside the envelopes
+// keep the names of the fields, as in the rest of this package. Players are referred
+// to by their stable identifiers and games by their names, given as they are in the
+// path of the URI rather than as encoded segments.
+
+// ResourceDocument is the top-level object of every successful response, where Data is
+// a single ResourceObject, a slice of them, or nil for a resource which has been
+// deleted. Links is only set for pages of a larger collection.
+type ResourceDocument struct {
+	Data  interface{}    `json:"data"`
+	Links *DocumentLinks `json:"links,omitempty"`
+}
+
+// DocumentLinks holds the URI of the next page of a collection, which is empty if
+// there are no more pages.
+type DocumentLinks struct {
+	Next string `json:"next,omitempty"`
+}
+
+// ResourceObject is a single resource of the given type, identified by ID within that
+// type, with its attributes.
+type ResourceObject struct {
+	Type       string      `json:"type"`
+	ID         string      `json:"id"`
+	Attributes interface{} `json:"attributes"`
+}
+
+// ErrorDocument is the top-level object of every response to a request which failed.
+type ErrorDocument struct {
+	Errors []ErrorObject `json:"errors"`
+}
+
+// ErrorObject describes why a request failed. Status is the HTTP status as a string,
+// Code is the code from github.com/benoleary/ilutulestikud/backend/failure, and Detail
+// is the message of the error, which may change with its wording.
+type ErrorObject struct {
+	Status string `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// ErrorDocumentFor wraps the message and code of the given error in the top-level
+// object for a request which failed with the given HTTP status.
+func ErrorDocumentFor(errorToReport error, httpStatus int) ErrorDocument {
+	return ErrorDocument{
+		Errors: []ErrorObject{
+			{
+				Status: strconv.Itoa(httpStatus),
+				Code:   failure.CodeOf(errorToReport),
+				Detail: errorToReport.Error(),
+			},
+		},
+	}
+}
+
+// ResourceInput is the top-level object of the body of every request which creates or
+// changes a resource. The attributes are kept as raw JSON until the type has been
+// checked, so that they can be decoded into the struct for that type.
+type ResourceInput struct {
+	Data ResourceInputObject `json:"data"`
+}
+
+// ResourceInputObject is the resource in the body of a request.
+type ResourceInputObject struct {
+	Type       string          `json:"type"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// PlayerAttributes are the attributes of a resource of type "players", which is
+// identified by the identifier of the player.
+type PlayerAttributes struct {
+	Name     string
+	Color    string
+	Role     string
+	Profile  PlayerProfile
+	Presence string
+}
+
+// PlayerPatch holds the changes to a player, where attributes which are nil are left
+// unchanged. Only an administrator may change the role of a player, and only the player
+// themself may change any of the other attributes.
+type PlayerPatch struct {
+	Name    *string
+	Color   *string
+	Role    *string
+	Profile *PlayerProfile
+}
+
+// SessionAttributes are the attributes of a resource of type "sessions", which is
+// identified by the identifier of the player who logged in.
+type SessionAttributes struct {
+	PlayerName string
+	Token      string
+}
+
+// RulesetAttributes are the attributes of a resource of type "rulesets", which is
+// identified by the identifier of the ruleset.
+type RulesetAttributes struct {
+	Description            string
+	MinimumNumberOfPlayers int
+	MaximumNumberOfPlayers int
+}
+
+// GameSummaryAttributes are the attributes of a resource of type "games" in the list of
+// the games of a player, where the resource is identified by the name of the game.
+type GameSummaryAttributes struct {
+	IsPlayerTurn bool
+	Version      int
+}
+
+// NewGameAttributes are the attributes of a resource of type "games" which is to be
+// created by inviting the given players, with the player who makes the request as the
+// host. The same attributes, with the host prepended to the players, are sent back as a
+// resource of type "invitations".
+type NewGameAttributes struct {
+	Name              string
+	RulesetIdentifier int
+	PlayerIdentifiers []string
+}
+
+// GameActionAttributes are the attributes of a resource of type "actions", which is a
+// turn taken in a game by the player who makes the request. Kind is "discard", "play",
+// "hint-color", or "hint-number", and determines which of the other attributes are
+// used. ExpectedTurn is the turn of the game which the player saw when choosing the
+// action, as for PlayerCardIndication.
+type GameActionAttributes struct {
+	Kind               string
+	ExpectedTurn       int
+	CardIndex          int
+	ReceiverIdentifier string
+	HintedColor        string
+	HintedNumber       int
+}
+
+// ChatMessageAttributes are the attributes of a resource of type "chat-messages",
+// which is a message from the player who makes the request to a game.
+type ChatMessageAttributes struct {
+	MessageText string
+}
@@ -8,7 +8,8 @@ import (
 
 // StateCollection defines what a struct should do to allow a Handler from
 // github.com/benoleary/ilutulestikud/backend/endpoint/player to read and write
-// player states.
+// player states. Players are identified by stable identifiers, and the names are
+// display names which may be changed.
 type StateCollection interface {
 	// All should return a slice of all the players in the collection. The order is not
 	// mandated, and may even change with repeated calls to the same unchanged collection
@@ -16,18 +17,126 @@ type StateCollection interface {
 	// course an implementation may order the slice consistently.
 	All(executionContext context.Context) ([]player.ReadonlyState, error)
 
-	// Get should return a read-only state for the given player.
-	Get(executionContext context.Context, playerName string) (player.ReadonlyState, error)
+	// Search should return a page of at most the given number of players whose names
+	// start with the given prefix, in the given order, starting after the player
+	// encoded in the given cursor, along with the cursor for the next page.
+	Search(
+		executionContext context.Context,
+		namePrefix string,
+		nameOrder player.NameOrder,
+		pageCursor string,
+		pageSize int) (player.SearchResult, error)
+
+	// Get should return a read-only state for the player with the given identifier.
+	Get(executionContext context.Context, playerIdentifier string) (player.ReadonlyState, error)
 
 	// AvailableChatColors should return the chat colors available to the collection.
 	AvailableChatColors(executionContext context.Context) []string
 
-	// Add should add a new player to the collection, defined by the given arguments.
-	Add(executionContext context.Context, playerName string, chatColor string) error
+	// Add should add a new player to the collection, defined by the given arguments, and
+	// return the identifier which was given to the new player.
+	Add(
+		executionContext context.Context,
+		playerName string,
+		chatColor string,
+		plainPassword string) (string, error)
+
+	// Authenticate should return the identifier of the player with the given name if the
+	// given password is correct for that player, and an error otherwise.
+	Authenticate(
+		executionContext context.Context,
+		playerName string,
+		plainPassword string) (string, error)
+
+	// Rename should update the given player with the given display name.
+	Rename(executionContext context.Context, playerIdentifier string, playerName string) error
 
 	// UpdateColor should update the given player with the given chat color.
-	UpdateColor(executionContext context.Context, playerName string, chatColor string) error
+	UpdateColor(executionContext context.Context, playerIdentifier string, chatColor string) error
+
+	// UpdateRole should update the given player with the given role.
+	UpdateRole(executionContext context.Context, playerIdentifier string, playerRole string) error
+
+	// UpdateProfile should replace the profile of the given player with the given
+	// profile.
+	UpdateProfile(
+		executionContext context.Context,
+		playerIdentifier string,
+		playerProfile player.Profile) error
+
+	// Contacts should return the friends and groups of the given player.
+	Contacts(executionContext context.Context, playerIdentifier string) (player.Contacts, error)
+
+	// Friends should return the states of the friends of the given player who have not
+	// been deleted.
+	Friends(executionContext context.Context, playerIdentifier string) ([]player.ReadonlyState, error)
+
+	// AddFriend should add the second given player to the friends of the first.
+	AddFriend(executionContext context.Context, playerIdentifier string, friendIdentifier string) error
+
+	// RemoveFriend should remove the second given player from the friends of the first.
+	RemoveFriend(
+		executionContext context.Context,
+		playerIdentifier string,
+		friendIdentifier string) error
+
+	// SaveGroup should add or replace the group of the given player with the given name
+	// so that it has the given members.
+	SaveGroup(
+		executionContext context.Context,
+		playerIdentifier string,
+		groupName string,
+		memberIdentifiers []string) error
+
+	// DeleteGroup should remove the group of the given player with the given name.
+	DeleteGroup(executionContext context.Context, playerIdentifier string, groupName string) error
+
+	// RecordHeartbeat should record that the given player is present with the given
+	// game open, which may be empty, and whether the player is inactive.
+	RecordHeartbeat(
+		executionContext context.Context,
+		playerIdentifier string,
+		viewedGameName string,
+		isIdle bool)
+
+	// Presence should return whether the given player is online, idle, or offline, and
+	// which game the player has open.
+	Presence(executionContext context.Context, playerIdentifier string) player.Presence
+
+	// IsAdministrator should return true if the given player has the role of
+	// administrator.
+	IsAdministrator(executionContext context.Context, playerIdentifier string) (bool, error)
+
+	// IsDeleted should return true if the given player has been deleted, so that the
+	// session tokens of the player are no longer accepted.
+	IsDeleted(executionContext context.Context, playerIdentifier string) (bool, error)
 
 	// Delete should delete the given player from the collection.
-	Delete(executionContext context.Context, playerName string) error
+	Delete(executionContext context.Context, playerIdentifier string) error
+}
+
+// PlayerAuthorizer defines what a struct should do to allow a Handler to check that
+// a request is being made by the player on whose behalf it claims to act.
+type PlayerAuthorizer interface {
+	// AuthorizeActingPlayer should return nil if the request with the given context
+	// may act on behalf of the given player, and an error otherwise.
+	AuthorizeActingPlayer(requestContext context.Context, playerIdentifier string) error
+
+	// AuthorizeActingPlayerOrAdministrator should return nil if the request with the
+	// given context may act on behalf of the given player or is from an administrator,
+	// and an error otherwise.
+	AuthorizeActingPlayerOrAdministrator(
+		requestContext context.Context,
+		playerIdentifier string) error
+
+	// AuthorizeAdministrator should return nil if the request with the given context
+	// is from an administrator, and an error otherwise.
+	AuthorizeAdministrator(requestContext context.Context) error
+}
+
+// SessionTokenIssuer defines what a struct should do to allow a Handler to give a
+// session token to a player who has logged in.
+type SessionTokenIssuer interface {
+	// IssueToken should return a signed token identifying the given player.
+	IssueToken(playerIdentifier string) (string, error)
 }
@@ -8,8 +8,17 @@ import (
 var colorsAvailableInTest []string = defaults.AvailableColors()
 
 type mockPlayerState struct {
-	name  string
-	color string
+	identifier string
+	name       string
+	color      string
+	role       string
+	profile    player.Profile
+	contacts   player.Contacts
+}
+
+// Identifier returns the private identifier field.
+func (playerState *mockPlayerState) Identifier() string {
+	return playerState.identifier
 }
 
 // Name returns the private name field.
@@ -22,18 +31,47 @@ func (playerState *mockPlayerState) Color() string {
 	return playerState.color
 }
 
+// Role returns the private role field.
+func (playerState *mockPlayerState) Role() string {
+	return playerState.role
+}
+
+// Profile returns the private profile field.
+func (playerState *mockPlayerState) Profile() player.Profile {
+	return playerState.profile
+}
+
+// Contacts returns the private contacts field.
+func (playerState *mockPlayerState) Contacts() player.Contacts {
+	return playerState.contacts
+}
+
 var testPlayerStates []player.ReadonlyState = []player.ReadonlyState{
 	&mockPlayerState{
-		name:  "Player One",
-		color: colorsAvailableInTest[0],
+		identifier: "player identifier 1",
+		name:       "Player One",
+		color:      colorsAvailableInTest[0],
+		role:       player.RolePlayer,
 	},
 	// Player Two has the same color as Player One
 	&mockPlayerState{
-		name:  "Player Two",
-		color: colorsAvailableInTest[0],
+		identifier: "player identifier 2",
+		name:       "Player Two",
+		color:      colorsAvailableInTest[0],
+		role:       player.RolePlayer,
 	},
 	&mockPlayerState{
-		name:  "Player Three",
-		color: colorsAvailableInTest[1],
+		identifier: "player identifier 3",
+		name:       "Player Three",
+		color:      colorsAvailableInTest[1],
+		role:       player.RoleAdministrator,
+		profile: player.Profile{
+			PreferredRulesets:    []int{2, 1},
+			PreferredHintDisplay: player.HintDisplaySymbols,
+			Timezone:             "UTC",
+			Notifications: player.NotificationPreferences{
+				OnTurn: true,
+			},
+		},
 	},
 }
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/benoleary/ilutulestikud/backend/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
 )
 
@@ -16,17 +18,23 @@ import (
 // available to the endpoints.
 // It implements github.com/benoleary/ilutulestikud/server.httpGetAndPostHandler.
 type Handler struct {
-	stateCollection   StateCollection
-	segmentTranslator parsing.SegmentTranslator
+	stateCollection    StateCollection
+	segmentTranslator  parsing.SegmentTranslator
+	playerAuthorizer   PlayerAuthorizer
+	sessionTokenIssuer SessionTokenIssuer
 }
 
 // New returns a pointer to a new Handler.
 func New(
 	collectionOfStates StateCollection,
-	translatorForSegments parsing.SegmentTranslator) *Handler {
+	translatorForSegments parsing.SegmentTranslator,
+	authorizerForPlayers PlayerAuthorizer,
+	issuerOfTokens SessionTokenIssuer) *Handler {
 	return &Handler{
-		stateCollection:   collectionOfStates,
-		segmentTranslator: translatorForSegments,
+		stateCollection:    collectionOfStates,
+		segmentTranslator:  translatorForSegments,
+		playerAuthorizer:   authorizerForPlayers,
+		sessionTokenIssuer: issuerOfTokens,
 	}
 }
 
@@ -44,6 +52,8 @@ func (handler *Handler) HandleGet(
 		return handler.writeRegisteredPlayers(requestContext)
 	case "available-colors":
 		return handler.writeAvailableColors(requestContext)
+	case "contacts-of-player":
+		return handler.writeContactsForPlayer(requestContext, relevantSegments[1:])
 	default:
 		return "URI segment " + relevantSegments[0] + " not valid", http.StatusNotFound
 	}
@@ -62,8 +72,28 @@ func (handler *Handler) HandlePost(
 	switch relevantSegments[0] {
 	case "new-player":
 		return handler.handleNewPlayer(requestContext, httpBodyDecoder)
+	case "log-in":
+		return handler.handleLogIn(requestContext, httpBodyDecoder)
+	case "search-players":
+		return handler.handleSearchPlayers(requestContext, httpBodyDecoder)
 	case "update-player":
 		return handler.handleUpdatePlayer(requestContext, httpBodyDecoder)
+	case "rename-player":
+		return handler.handleRenamePlayer(requestContext, httpBodyDecoder)
+	case "update-profile":
+		return handler.handleUpdateProfile(requestContext, httpBodyDecoder)
+	case "heartbeat":
+		return handler.handleHeartbeat(requestContext, httpBodyDecoder)
+	case "add-friend":
+		return handler.handleAddFriend(requestContext, httpBodyDecoder)
+	case "remove-friend":
+		return handler.handleRemoveFriend(requestContext, httpBodyDecoder)
+	case "save-group":
+		return handler.handleSaveGroup(requestContext, httpBodyDecoder)
+	case "delete-group":
+		return handler.handleDeleteGroup(requestContext, httpBodyDecoder)
+	case "set-player-role":
+		return handler.handleSetPlayerRole(requestContext, httpBodyDecoder)
 	case "delete-player":
 		return handler.handleDeletePlayer(requestContext, httpBodyDecoder)
 	default:
@@ -74,25 +104,55 @@ func (handler *Handler) HandlePost(
 // writeRegisteredPlayers writes a JSON object into the HTTP response which has
 // the list of player objects as its "Players" attribute. The order of the players
 // may not consistent with repeated calls as ForEndpoint does not guarantee it.
+//
+// Deprecated: this lists every player, so handleSearchPlayers should be used instead,
+// as it returns the players a page at a time. It is kept only for old frontends.
 func (handler *Handler) writeRegisteredPlayers(
 	requestContext context.Context) (interface{}, int) {
 	playerStates, errorFromAll := handler.stateCollection.All(requestContext)
 	if errorFromAll != nil {
-		return errorFromAll, http.StatusInternalServerError
+		return errorFromAll, parsing.StatusForError(errorFromAll, http.StatusInternalServerError)
 	}
 
-	playerList := make([]parsing.PlayerState, 0, len(playerStates))
-	for _, playerState := range playerStates {
-		playerName := playerState.Name()
-		playerList = append(playerList, parsing.PlayerState{
-			Identifier: handler.segmentTranslator.ToSegment(playerName),
-			Name:       playerName,
-			Color:      playerState.Color(),
-		})
+	endpointObject := parsing.PlayerList{
+		Players: handler.playerStatesForEndpoint(requestContext, playerStates),
 	}
 
-	endpointObject := parsing.PlayerList{
-		Players: playerList,
+	return endpointObject, http.StatusOK
+}
+
+// writePlayer writes a JSON object into the HTTP response which is the state of the
+// given player, so that the handlers which change a single player only have to read
+// that player rather than every player.
+func (handler *Handler) writePlayer(
+	requestContext context.Context,
+	playerIdentifier string) (interface{}, int) {
+	playerState, errorFromGet := handler.stateCollection.Get(requestContext, playerIdentifier)
+	if errorFromGet != nil {
+		return errorFromGet, parsing.StatusForError(errorFromGet, http.StatusInternalServerError)
+	}
+
+	endpointPlayers :=
+		handler.playerStatesForEndpoint(requestContext, []player.ReadonlyState{playerState})
+
+	return endpointPlayers[0], http.StatusOK
+}
+
+// writeFirstPlayerPage writes a JSON object into the HTTP response which has the first
+// page of all the players in ascending order of name, with the default size of page, as
+// handleSearchPlayers would for a search without any prefix.
+func (handler *Handler) writeFirstPlayerPage(
+	requestContext context.Context) (interface{}, int) {
+	searchResult, errorFromSearch :=
+		handler.stateCollection.Search(requestContext, "", player.NameAscending, "", 0)
+	if errorFromSearch != nil {
+		return errorFromSearch,
+			parsing.StatusForError(errorFromSearch, http.StatusInternalServerError)
+	}
+
+	endpointObject := parsing.PlayerPage{
+		Players:    handler.playerStatesForEndpoint(requestContext, searchResult.Players),
+		NextCursor: searchResult.NextCursor,
 	}
 
 	return endpointObject, http.StatusOK
@@ -110,39 +170,125 @@ func (handler *Handler) writeAvailableColors(
 }
 
 // handleNewPlayer adds the player defined by the JSON of the request's body to the list
-// of registered players, and returns the updated list as writeRegisteredPlayerNameListJson
-// would.
+// of registered players, and returns the new player as writePlayer would.
 func (handler *Handler) handleNewPlayer(
 	requestContext context.Context,
 	httpBodyDecoder *json.Decoder) (interface{}, int) {
-	var endpointPlayer parsing.PlayerState
+	var endpointPlayer parsing.NewPlayerDefinition
 	errorFromParse := httpBodyDecoder.Decode(&endpointPlayer)
 	if errorFromParse != nil {
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
-	errorFromAdd :=
-		handler.stateCollection.Add(requestContext, endpointPlayer.Name, endpointPlayer.Color)
+	playerIdentifier, errorFromAdd :=
+		handler.stateCollection.Add(
+			requestContext,
+			endpointPlayer.Name,
+			endpointPlayer.Color,
+			endpointPlayer.Password)
 
 	if errorFromAdd != nil {
-		return errorFromAdd, http.StatusBadRequest
+		return errorFromAdd, parsing.StatusForError(errorFromAdd, http.StatusBadRequest)
 	}
 
-	playerIdentifier := handler.segmentTranslator.ToSegment(endpointPlayer.Name)
+	identifierSegment := handler.segmentTranslator.ToSegment(playerIdentifier)
 
-	if strings.Contains(playerIdentifier, "/") {
+	if strings.Contains(identifierSegment, "/") {
 		errorMessage := fmt.Sprintf(
 			"Server set up with encoding which cannot convert %v to identifier with '/' in it",
-			endpointPlayer.Name)
+			playerIdentifier)
 		return errorMessage, http.StatusBadRequest
 	}
 
-	return handler.writeRegisteredPlayers(requestContext)
+	return handler.writePlayer(requestContext, playerIdentifier)
+}
+
+// handleSearchPlayers returns a page of the players whose names start with the prefix
+// given by the JSON of the request's body, in the order given by the body, starting
+// after the cursor given by the body, along with the cursor for the next page.
+func (handler *Handler) handleSearchPlayers(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerSearch parsing.PlayerSearch
+	errorFromParse := httpBodyDecoder.Decode(&playerSearch)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	var nameOrder player.NameOrder
+	switch playerSearch.SortOrder {
+	case "", "name":
+		nameOrder = player.NameAscending
+	case "-name":
+		nameOrder = player.NameDescending
+	default:
+		return "Sort order " + playerSearch.SortOrder + " not valid", http.StatusBadRequest
+	}
+
+	searchResult, errorFromSearch :=
+		handler.stateCollection.Search(
+			requestContext,
+			playerSearch.NamePrefix,
+			nameOrder,
+			playerSearch.Cursor,
+			playerSearch.PageSize)
+
+	if errorFromSearch != nil {
+		return errorFromSearch, parsing.StatusForError(errorFromSearch, http.StatusBadRequest)
+	}
+
+	endpointObject := parsing.PlayerPage{
+		Players:    handler.playerStatesForEndpoint(requestContext, searchResult.Players),
+		NextCursor: searchResult.NextCursor,
+	}
+
+	return endpointObject, http.StatusOK
+}
+
+// handleLogIn checks the name and password given by the JSON of the request's body, and
+// if they are correct, returns a session token for the player along with the identifier
+// of the player.
+func (handler *Handler) handleLogIn(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerCredentials parsing.PlayerCredentials
+	errorFromParse := httpBodyDecoder.Decode(&playerCredentials)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	playerIdentifier, errorFromAuthentication :=
+		handler.stateCollection.Authenticate(
+			requestContext,
+			playerCredentials.Name,
+			playerCredentials.Password)
+
+	if errorFromAuthentication != nil {
+		return errorFromAuthentication, http.StatusUnauthorized
+	}
+
+	sessionToken, errorFromIssue :=
+		handler.sessionTokenIssuer.IssueToken(playerIdentifier)
+
+	if errorFromIssue != nil {
+		return errorFromIssue,
+			parsing.StatusForError(errorFromIssue, http.StatusInternalServerError)
+	}
+
+	endpointObject := parsing.SessionToken{
+		Identifier:       handler.segmentTranslator.ToSegment(playerIdentifier),
+		PlayerIdentifier: playerIdentifier,
+		PlayerName:       playerCredentials.Name,
+		Token:            sessionToken,
+	}
+
+	return endpointObject, http.StatusOK
 }
 
 // handleUpdatePlayer updates the player defined by the JSON of the request's body, taking
-// the "Name" attribute as the key, and returns the updated list as writeRegisteredPlayers
-// would. Attributes which are present are updated, those which are missing remain unchanged.
+// the "PlayerIdentifier" attribute as the key, and returns the updated player as
+// writePlayer would. Attributes which are present are updated, those which are missing
+// remain unchanged. The display name is changed through handleRenamePlayer.
 func (handler *Handler) handleUpdatePlayer(
 	requestContext context.Context,
 	httpBodyDecoder *json.Decoder) (interface{}, int) {
@@ -152,22 +298,357 @@ func (handler *Handler) handleUpdatePlayer(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			playerUpdate.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	updateError :=
 		handler.stateCollection.UpdateColor(
 			requestContext,
-			playerUpdate.Name,
+			playerUpdate.PlayerIdentifier,
 			playerUpdate.Color)
 
 	if updateError != nil {
-		return updateError, http.StatusBadRequest
+		return updateError, parsing.StatusForError(updateError, http.StatusBadRequest)
 	}
 
-	return handler.writeRegisteredPlayers(requestContext)
+	return handler.writePlayer(requestContext, playerUpdate.PlayerIdentifier)
 }
 
-// handleUpdatePlayer updates the player defined by the JSON of the request's body, taking
-// the "Name" attribute as the key, and returns the updated list as writeRegisteredPlayers
-// would. Attributes which are present are updated, those which are missing remain unchanged.
+// handleRenamePlayer sets the display name of the player given by the "PlayerIdentifier"
+// attribute of the JSON of the request's body to the "Name" attribute, and returns the
+// renamed player as writePlayer would. Only the player themself may change the display
+// name. The games of the player are unaffected as they refer to the identifier.
+func (handler *Handler) handleRenamePlayer(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerUpdate parsing.PlayerState
+	errorFromParse := httpBodyDecoder.Decode(&playerUpdate)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			playerUpdate.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	renameError :=
+		handler.stateCollection.Rename(
+			requestContext,
+			playerUpdate.PlayerIdentifier,
+			playerUpdate.Name)
+
+	if renameError != nil {
+		return renameError, parsing.StatusForError(renameError, http.StatusBadRequest)
+	}
+
+	return handler.writePlayer(requestContext, playerUpdate.PlayerIdentifier)
+}
+
+// handleUpdateProfile replaces the profile of the player given by the "PlayerIdentifier"
+// attribute of the JSON of the request's body with the "Profile" attribute, and returns
+// the updated player as writePlayer would. Only the player themself may change the
+// profile.
+func (handler *Handler) handleUpdateProfile(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerUpdate parsing.PlayerState
+	errorFromParse := httpBodyDecoder.Decode(&playerUpdate)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			playerUpdate.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	updateError :=
+		handler.stateCollection.UpdateProfile(
+			requestContext,
+			playerUpdate.PlayerIdentifier,
+			ProfileFromEndpoint(playerUpdate.Profile))
+
+	if updateError != nil {
+		return updateError, parsing.StatusForError(updateError, http.StatusBadRequest)
+	}
+
+	return handler.writePlayer(requestContext, playerUpdate.PlayerIdentifier)
+}
+
+// handleHeartbeat records that the player given by the JSON of the request's body is
+// present, with the game given by the body open. It returns only "OK" so that it stays
+// cheap enough to be called often.
+func (handler *Handler) handleHeartbeat(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerHeartbeat parsing.Heartbeat
+	errorFromParse := httpBodyDecoder.Decode(&playerHeartbeat)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			playerHeartbeat.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	handler.stateCollection.RecordHeartbeat(
+		requestContext,
+		playerHeartbeat.PlayerIdentifier,
+		playerHeartbeat.ViewedGameName,
+		playerHeartbeat.IsIdle)
+
+	return "OK", http.StatusOK
+}
+
+// writeContactsForPlayer writes a JSON object into the HTTP response which has the
+// friends and the groups of the player given by the segment, as writeContacts would.
+// Only the player may see their own contacts.
+func (handler *Handler) writeContactsForPlayer(
+	requestContext context.Context,
+	relevantSegments []string) (interface{}, int) {
+	if len(relevantSegments) < 1 {
+		return "Not enough segments in URI to determine player", http.StatusBadRequest
+	}
+
+	playerIdentifier, errorFromIdentification :=
+		handler.segmentTranslator.FromSegment(relevantSegments[0])
+
+	if errorFromIdentification != nil {
+		return errorFromIdentification,
+			parsing.StatusForError(errorFromIdentification, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	return handler.writeContacts(requestContext, playerIdentifier)
+}
+
+// writeContacts writes a JSON object into the HTTP response which has the states of
+// the friends of the given player as its "Friends" attribute and the groups of the
+// player as its "Groups" attribute. It does not check the authorization, so the
+// callers have to.
+func (handler *Handler) writeContacts(
+	requestContext context.Context,
+	playerIdentifier string) (interface{}, int) {
+	playerContacts, errorFromContacts :=
+		handler.stateCollection.Contacts(requestContext, playerIdentifier)
+	if errorFromContacts != nil {
+		return errorFromContacts, parsing.StatusForError(errorFromContacts, http.StatusBadRequest)
+	}
+
+	friendStates, errorFromFriends :=
+		handler.stateCollection.Friends(requestContext, playerIdentifier)
+	if errorFromFriends != nil {
+		return errorFromFriends,
+			parsing.StatusForError(errorFromFriends, http.StatusInternalServerError)
+	}
+
+	playerGroups := make([]parsing.PlayerGroup, 0, len(playerContacts.Groups))
+	for _, playerGroup := range playerContacts.Groups {
+		playerGroups = append(playerGroups, parsing.PlayerGroup{
+			GroupName:         playerGroup.Name,
+			MemberIdentifiers: playerGroup.MemberIdentifiers,
+		})
+	}
+
+	endpointObject := parsing.ContactList{
+		Friends: handler.playerStatesForEndpoint(requestContext, friendStates),
+		Groups:  playerGroups,
+	}
+
+	return endpointObject, http.StatusOK
+}
+
+// handleAddFriend adds the player given by the "FriendIdentifier" attribute of the
+// JSON of the request's body to the friends of the player given by the
+// "PlayerIdentifier" attribute, and returns the updated contacts as writeContacts
+// would.
+func (handler *Handler) handleAddFriend(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var friendIndication parsing.FriendIndication
+	errorFromParse := httpBodyDecoder.Decode(&friendIndication)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			friendIndication.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromAdd :=
+		handler.stateCollection.AddFriend(
+			requestContext,
+			friendIndication.PlayerIdentifier,
+			friendIndication.FriendIdentifier)
+
+	if errorFromAdd != nil {
+		return errorFromAdd, parsing.StatusForError(errorFromAdd, http.StatusBadRequest)
+	}
+
+	return handler.writeContacts(requestContext, friendIndication.PlayerIdentifier)
+}
+
+// handleRemoveFriend removes the player given by the "FriendIdentifier" attribute of
+// the JSON of the request's body from the friends of the player given by the
+// "PlayerIdentifier" attribute, and returns the updated contacts as writeContacts
+// would.
+func (handler *Handler) handleRemoveFriend(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var friendIndication parsing.FriendIndication
+	errorFromParse := httpBodyDecoder.Decode(&friendIndication)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			friendIndication.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromRemove :=
+		handler.stateCollection.RemoveFriend(
+			requestContext,
+			friendIndication.PlayerIdentifier,
+			friendIndication.FriendIdentifier)
+
+	if errorFromRemove != nil {
+		return errorFromRemove, parsing.StatusForError(errorFromRemove, http.StatusBadRequest)
+	}
+
+	return handler.writeContacts(requestContext, friendIndication.PlayerIdentifier)
+}
+
+// handleSaveGroup saves the group given by the JSON of the request's body for the
+// player given by its "PlayerIdentifier" attribute, replacing any group of the player
+// with the same name, and returns the updated contacts as writeContacts would.
+func (handler *Handler) handleSaveGroup(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var groupDefinition parsing.GroupDefinition
+	errorFromParse := httpBodyDecoder.Decode(&groupDefinition)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			groupDefinition.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromSave :=
+		handler.stateCollection.SaveGroup(
+			requestContext,
+			groupDefinition.PlayerIdentifier,
+			groupDefinition.GroupName,
+			groupDefinition.MemberIdentifiers)
+
+	if errorFromSave != nil {
+		return errorFromSave, parsing.StatusForError(errorFromSave, http.StatusBadRequest)
+	}
+
+	return handler.writeContacts(requestContext, groupDefinition.PlayerIdentifier)
+}
+
+// handleDeleteGroup deletes the group with the "GroupName" attribute of the JSON of
+// the request's body from the groups of the player given by its "PlayerIdentifier"
+// attribute, and returns the updated contacts as writeContacts would.
+func (handler *Handler) handleDeleteGroup(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var groupDefinition parsing.GroupDefinition
+	errorFromParse := httpBodyDecoder.Decode(&groupDefinition)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(
+			requestContext,
+			groupDefinition.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	errorFromDelete :=
+		handler.stateCollection.DeleteGroup(
+			requestContext,
+			groupDefinition.PlayerIdentifier,
+			groupDefinition.GroupName)
+
+	if errorFromDelete != nil {
+		return errorFromDelete, parsing.StatusForError(errorFromDelete, http.StatusBadRequest)
+	}
+
+	return handler.writeContacts(requestContext, groupDefinition.PlayerIdentifier)
+}
+
+// handleSetPlayerRole sets the role of the player given by the "PlayerIdentifier"
+// attribute of the JSON of the request's body to the "Role" attribute, and returns the
+// updated player as writePlayer would. Only administrators may change roles.
+func (handler *Handler) handleSetPlayerRole(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerUpdate parsing.PlayerState
+	errorFromParse := httpBodyDecoder.Decode(&playerUpdate)
+	if errorFromParse != nil {
+		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeAdministrator(requestContext)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
+	updateError :=
+		handler.stateCollection.UpdateRole(
+			requestContext,
+			playerUpdate.PlayerIdentifier,
+			playerUpdate.Role)
+
+	if updateError != nil {
+		return updateError, parsing.StatusForError(updateError, http.StatusBadRequest)
+	}
+
+	return handler.writePlayer(requestContext, playerUpdate.PlayerIdentifier)
+}
+
+// handleDeletePlayer deletes the player given by the "PlayerIdentifier" attribute of the
+// JSON of the request's body, and returns the first page of the remaining players as
+// writeFirstPlayerPage would. Only the player themself or an administrator may delete a
+// player.
 func (handler *Handler) handleDeletePlayer(
 	requestContext context.Context,
 	httpBodyDecoder *json.Decoder) (interface{}, int) {
@@ -177,11 +658,77 @@ func (handler *Handler) handleDeletePlayer(
 		return "Error parsing JSON: " + errorFromParse.Error(), http.StatusBadRequest
 	}
 
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayerOrAdministrator(
+			requestContext,
+			playerToDelete.PlayerIdentifier)
+	if errorFromAuthorization != nil {
+		return errorFromAuthorization, authentication.StatusForError(errorFromAuthorization)
+	}
+
 	deleteError :=
-		handler.stateCollection.Delete(requestContext, playerToDelete.Name)
+		handler.stateCollection.Delete(requestContext, playerToDelete.PlayerIdentifier)
 	if deleteError != nil {
-		return deleteError, http.StatusInternalServerError
+		return deleteError, parsing.StatusForError(deleteError, http.StatusInternalServerError)
 	}
 
-	return handler.writeRegisteredPlayers(requestContext)
+	return handler.writeFirstPlayerPage(requestContext)
+}
+
+// playerStatesForEndpoint converts the given players into the form which is sent to
+// the frontend, keeping their order, along with their current presence.
+func (handler *Handler) playerStatesForEndpoint(
+	requestContext context.Context,
+	playerStates []player.ReadonlyState) []parsing.PlayerState {
+	playerList := make([]parsing.PlayerState, 0, len(playerStates))
+	for _, playerState := range playerStates {
+		playerIdentifier := playerState.Identifier()
+		playerPresence :=
+			handler.stateCollection.Presence(requestContext, playerIdentifier)
+		playerList = append(playerList, parsing.PlayerState{
+			Identifier:       handler.segmentTranslator.ToSegment(playerIdentifier),
+			PlayerIdentifier: playerIdentifier,
+			Name:             playerState.Name(),
+			Color:            playerState.Color(),
+			Role:             playerState.Role(),
+			Profile:          ProfileForEndpoint(playerState.Profile()),
+			Presence:         playerPresence.Status,
+		})
+	}
+
+	return playerList
+}
+
+// ProfileForEndpoint converts the given profile into the form which is sent to the
+// frontend. It is exported so that the handler of the second version of the API sends
+// profiles in the same form.
+func ProfileForEndpoint(playerProfile player.Profile) parsing.PlayerProfile {
+	preferredRulesets := playerProfile.PreferredRulesets
+	if preferredRulesets == nil {
+		preferredRulesets = []int{}
+	}
+
+	return parsing.PlayerProfile{
+		PreferredRulesets:    preferredRulesets,
+		PreferredHintDisplay: playerProfile.PreferredHintDisplay,
+		Timezone:             playerProfile.Timezone,
+		NotifyOnTurn:         playerProfile.Notifications.OnTurn,
+		NotifyOnInvitation:   playerProfile.Notifications.OnInvitation,
+		NotifyOnChatMessage:  playerProfile.Notifications.OnChatMessage,
+	}
+}
+
+// ProfileFromEndpoint converts the given profile from the frontend into the form which
+// is stored.
+func ProfileFromEndpoint(endpointProfile parsing.PlayerProfile) player.Profile {
+	return player.Profile{
+		PreferredRulesets:    endpointProfile.PreferredRulesets,
+		PreferredHintDisplay: endpointProfile.PreferredHintDisplay,
+		Timezone:             endpointProfile.Timezone,
+		Notifications: player.NotificationPreferences{
+			OnTurn:        endpointProfile.NotifyOnTurn,
+			OnInvitation:  endpointProfile.NotifyOnInvitation,
+			OnChatMessage: endpointProfile.NotifyOnChatMessage,
+		},
+	}
 }
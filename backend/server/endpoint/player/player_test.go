@@ -7,9 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"testing"
 
 	player_state "github.com/benoleary/ilutulestikud/backend/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
 	player_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/player"
 )
@@ -33,19 +35,40 @@ func decoderAroundDefaultPlayer(
 var testPlayerList parsing.PlayerList = parsing.PlayerList{
 	Players: []parsing.PlayerState{
 		parsing.PlayerState{
-			Identifier: segmentTranslatorForTest().ToSegment(testPlayerStates[0].Name()),
-			Name:       testPlayerStates[0].Name(),
-			Color:      testPlayerStates[0].Color(),
+			Identifier:       segmentTranslatorForTest().ToSegment(testPlayerStates[0].Identifier()),
+			PlayerIdentifier: testPlayerStates[0].Identifier(),
+			Name:             testPlayerStates[0].Name(),
+			Color:            testPlayerStates[0].Color(),
+			Role:             testPlayerStates[0].Role(),
+			Presence:         player_state.PresenceOffline,
+			Profile: parsing.PlayerProfile{
+				PreferredRulesets: []int{},
+			},
 		},
 		parsing.PlayerState{
-			Identifier: segmentTranslatorForTest().ToSegment(testPlayerStates[1].Name()),
-			Name:       testPlayerStates[1].Name(),
-			Color:      testPlayerStates[1].Color(),
+			Identifier:       segmentTranslatorForTest().ToSegment(testPlayerStates[1].Identifier()),
+			PlayerIdentifier: testPlayerStates[1].Identifier(),
+			Name:             testPlayerStates[1].Name(),
+			Color:            testPlayerStates[1].Color(),
+			Role:             testPlayerStates[1].Role(),
+			Presence:         player_state.PresenceOffline,
+			Profile: parsing.PlayerProfile{
+				PreferredRulesets: []int{},
+			},
 		},
 		parsing.PlayerState{
-			Identifier: segmentTranslatorForTest().ToSegment(testPlayerStates[2].Name()),
-			Name:       testPlayerStates[2].Name(),
-			Color:      testPlayerStates[2].Color(),
+			Identifier:       segmentTranslatorForTest().ToSegment(testPlayerStates[2].Identifier()),
+			PlayerIdentifier: testPlayerStates[2].Identifier(),
+			Name:             testPlayerStates[2].Name(),
+			Color:            testPlayerStates[2].Color(),
+			Role:             testPlayerStates[2].Role(),
+			Presence:         player_state.PresenceOffline,
+			Profile: parsing.PlayerProfile{
+				PreferredRulesets:    []int{2, 1},
+				PreferredHintDisplay: player_state.HintDisplaySymbols,
+				Timezone:             "UTC",
+				NotifyOnTurn:         true,
+			},
 		},
 	},
 }
@@ -54,7 +77,31 @@ type mockPlayerCollection struct {
 	FunctionsAndArgumentsReceived []functionNameAndArgument
 	ErrorToReturn                 error
 	ReturnForAll                  []player_state.ReadonlyState
+	ReturnForSearch               player_state.SearchResult
+	ReturnForIdentifier           string
 	ReturnForAvailableChatColors  []string
+	ReturnForIsAdministrator      bool
+	ArgumentForUpdateProfile      player_state.Profile
+	ReturnForContacts             player_state.Contacts
+	ReturnForFriends              []player_state.ReadonlyState
+	ArgumentForSaveGroup          []string
+	ReturnForPresence             map[string]player_state.Presence
+}
+
+// heartbeatArguments holds the arguments of a call to RecordHeartbeat(...) in a
+// comparable form.
+type heartbeatArguments struct {
+	playerIdentifier string
+	viewedGameName   string
+	isIdle           bool
+}
+
+// searchArguments holds the arguments of a call to Search(...) in a comparable form.
+type searchArguments struct {
+	namePrefix string
+	nameOrder  player_state.NameOrder
+	pageCursor string
+	pageSize   int
 }
 
 func (mockCollection *mockPlayerCollection) recordFunctionAndArgument(
@@ -86,24 +133,184 @@ func (mockCollection *mockPlayerCollection) getFirstAndEnsureOnly(
 func (mockCollection *mockPlayerCollection) Add(
 	executionContext context.Context,
 	playerName string,
-	chatColor string) error {
+	chatColor string,
+	plainPassword string) (string, error) {
 	mockCollection.recordFunctionAndArgument(
 		"Add",
-		stringPair{first: playerName, second: chatColor})
+		stringTriple{first: playerName, second: chatColor, third: plainPassword})
+	return mockCollection.ReturnForIdentifier, mockCollection.ErrorToReturn
+}
+
+// Authenticate gets mocked.
+func (mockCollection *mockPlayerCollection) Authenticate(
+	executionContext context.Context,
+	playerName string,
+	plainPassword string) (string, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Authenticate",
+		stringPair{first: playerName, second: plainPassword})
+	return mockCollection.ReturnForIdentifier, mockCollection.ErrorToReturn
+}
+
+// Rename gets mocked.
+func (mockCollection *mockPlayerCollection) Rename(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"Rename",
+		stringPair{first: playerIdentifier, second: playerName})
 	return mockCollection.ErrorToReturn
 }
 
 // UpdateColor gets mocked.
 func (mockCollection *mockPlayerCollection) UpdateColor(
 	executionContext context.Context,
-	playerName string,
+	playerIdentifier string,
 	chatColor string) error {
 	mockCollection.recordFunctionAndArgument(
 		"UpdateColor",
-		stringPair{first: playerName, second: chatColor})
+		stringPair{first: playerIdentifier, second: chatColor})
+	return mockCollection.ErrorToReturn
+}
+
+// UpdateRole gets mocked.
+func (mockCollection *mockPlayerCollection) UpdateRole(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerRole string) error {
+	mockCollection.recordFunctionAndArgument(
+		"UpdateRole",
+		stringPair{first: playerIdentifier, second: playerRole})
+	return mockCollection.ErrorToReturn
+}
+
+// UpdateProfile gets mocked. Only the identifier is recorded with the function name,
+// as records have to be comparable, so the profile is kept separately.
+func (mockCollection *mockPlayerCollection) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile player_state.Profile) error {
+	mockCollection.recordFunctionAndArgument(
+		"UpdateProfile",
+		playerIdentifier)
+	mockCollection.ArgumentForUpdateProfile = playerProfile
 	return mockCollection.ErrorToReturn
 }
 
+// Contacts gets mocked.
+func (mockCollection *mockPlayerCollection) Contacts(
+	executionContext context.Context,
+	playerIdentifier string) (player_state.Contacts, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Contacts",
+		playerIdentifier)
+	return mockCollection.ReturnForContacts, mockCollection.ErrorToReturn
+}
+
+// Friends gets mocked.
+func (mockCollection *mockPlayerCollection) Friends(
+	executionContext context.Context,
+	playerIdentifier string) ([]player_state.ReadonlyState, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Friends",
+		playerIdentifier)
+	return mockCollection.ReturnForFriends, mockCollection.ErrorToReturn
+}
+
+// AddFriend gets mocked.
+func (mockCollection *mockPlayerCollection) AddFriend(
+	executionContext context.Context,
+	playerIdentifier string,
+	friendIdentifier string) error {
+	mockCollection.recordFunctionAndArgument(
+		"AddFriend",
+		stringPair{first: playerIdentifier, second: friendIdentifier})
+	return mockCollection.ErrorToReturn
+}
+
+// RemoveFriend gets mocked.
+func (mockCollection *mockPlayerCollection) RemoveFriend(
+	executionContext context.Context,
+	playerIdentifier string,
+	friendIdentifier string) error {
+	mockCollection.recordFunctionAndArgument(
+		"RemoveFriend",
+		stringPair{first: playerIdentifier, second: friendIdentifier})
+	return mockCollection.ErrorToReturn
+}
+
+// SaveGroup gets mocked. Only the identifier and the group name are recorded with the
+// function name, as records have to be comparable, so the members are kept separately.
+func (mockCollection *mockPlayerCollection) SaveGroup(
+	executionContext context.Context,
+	playerIdentifier string,
+	groupName string,
+	memberIdentifiers []string) error {
+	mockCollection.recordFunctionAndArgument(
+		"SaveGroup",
+		stringPair{first: playerIdentifier, second: groupName})
+	mockCollection.ArgumentForSaveGroup = memberIdentifiers
+	return mockCollection.ErrorToReturn
+}
+
+// DeleteGroup gets mocked.
+func (mockCollection *mockPlayerCollection) DeleteGroup(
+	executionContext context.Context,
+	playerIdentifier string,
+	groupName string) error {
+	mockCollection.recordFunctionAndArgument(
+		"DeleteGroup",
+		stringPair{first: playerIdentifier, second: groupName})
+	return mockCollection.ErrorToReturn
+}
+
+// RecordHeartbeat gets mocked.
+func (mockCollection *mockPlayerCollection) RecordHeartbeat(
+	executionContext context.Context,
+	playerIdentifier string,
+	viewedGameName string,
+	isIdle bool) {
+	mockCollection.recordFunctionAndArgument(
+		"RecordHeartbeat",
+		heartbeatArguments{
+			playerIdentifier: playerIdentifier,
+			viewedGameName:   viewedGameName,
+			isIdle:           isIdle,
+		})
+}
+
+// Presence gets mocked. It is not recorded, as it is called for every player which is
+// written for the frontend. Players not in the map of presences are offline.
+func (mockCollection *mockPlayerCollection) Presence(
+	executionContext context.Context,
+	playerIdentifier string) player_state.Presence {
+	playerPresence, hasPresence := mockCollection.ReturnForPresence[playerIdentifier]
+	if !hasPresence {
+		return player_state.Presence{Status: player_state.PresenceOffline}
+	}
+
+	return playerPresence
+}
+
+// IsAdministrator gets mocked.
+func (mockCollection *mockPlayerCollection) IsAdministrator(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	mockCollection.recordFunctionAndArgument(
+		"IsAdministrator",
+		playerIdentifier)
+	return mockCollection.ReturnForIsAdministrator, mockCollection.ErrorToReturn
+}
+
+// IsDeleted gets mocked, treating nobody as deleted, and is not recorded as it is
+// called by the authorizer before every check.
+func (mockCollection *mockPlayerCollection) IsDeleted(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	return false, nil
+}
+
 // All gets mocked.
 func (mockCollection *mockPlayerCollection) All(
 	executionContext context.Context) ([]player_state.ReadonlyState, error) {
@@ -113,23 +320,41 @@ func (mockCollection *mockPlayerCollection) All(
 	return mockCollection.ReturnForAll, mockCollection.ErrorToReturn
 }
 
-// Get gets mocked.
+// Search gets mocked.
+func (mockCollection *mockPlayerCollection) Search(
+	executionContext context.Context,
+	namePrefix string,
+	nameOrder player_state.NameOrder,
+	pageCursor string,
+	pageSize int) (player_state.SearchResult, error) {
+	mockCollection.recordFunctionAndArgument(
+		"Search",
+		searchArguments{
+			namePrefix: namePrefix,
+			nameOrder:  nameOrder,
+			pageCursor: pageCursor,
+			pageSize:   pageSize,
+		})
+	return mockCollection.ReturnForSearch, mockCollection.ErrorToReturn
+}
+
+// Get gets mocked, returning a player with the given identifier.
 func (mockCollection *mockPlayerCollection) Get(
 	executionContext context.Context,
 	playerIdentifier string) (player_state.ReadonlyState, error) {
 	mockCollection.recordFunctionAndArgument(
-		"playerIdentifier",
+		"Get",
 		playerIdentifier)
-	return nil, mockCollection.ErrorToReturn
+	return &mockPlayerState{identifier: playerIdentifier}, mockCollection.ErrorToReturn
 }
 
 // Delete gets mocked.
 func (mockCollection *mockPlayerCollection) Delete(
 	executionContext context.Context,
-	playerName string) error {
+	playerIdentifier string) error {
 	mockCollection.recordFunctionAndArgument(
 		"Delete",
-		playerName)
+		playerIdentifier)
 	return mockCollection.ErrorToReturn
 }
 
@@ -142,6 +367,41 @@ func (mockCollection *mockPlayerCollection) AvailableChatColors(
 	return mockCollection.ReturnForAvailableChatColors
 }
 
+// mockAuthorizer allows every request unless it has an error to return.
+type mockAuthorizer struct {
+	ErrorToReturn error
+}
+
+// AuthorizeActingPlayer gets mocked.
+func (authorizer *mockAuthorizer) AuthorizeActingPlayer(
+	requestContext context.Context,
+	playerIdentifier string) error {
+	return authorizer.ErrorToReturn
+}
+
+// AuthorizeActingPlayerOrAdministrator gets mocked.
+func (authorizer *mockAuthorizer) AuthorizeActingPlayerOrAdministrator(
+	requestContext context.Context,
+	playerIdentifier string) error {
+	return authorizer.ErrorToReturn
+}
+
+// AuthorizeAdministrator gets mocked.
+func (authorizer *mockAuthorizer) AuthorizeAdministrator(
+	requestContext context.Context) error {
+	return authorizer.ErrorToReturn
+}
+
+// mockTokenIssuer issues tokens which are just the player identifier with a prefix.
+type mockTokenIssuer struct {
+	ErrorToReturn error
+}
+
+// IssueToken gets mocked.
+func (tokenIssuer *mockTokenIssuer) IssueToken(playerIdentifier string) (string, error) {
+	return "token for " + playerIdentifier, tokenIssuer.ErrorToReturn
+}
+
 // newPlayerCollectionAndHandler prepares a mock player collection and uses it to
 // prepare a player_endpoint.Handler with the default endpoint segment translator
 // for the tests, in a consistent way for the tests of the player endpoints,
@@ -160,7 +420,11 @@ func newPlayerCollectionAndHandlerForTranslator(
 	mockCollection := &mockPlayerCollection{}
 
 	handlerForPlayer :=
-		player_endpoint.New(mockCollection, segmentTranslator)
+		player_endpoint.New(
+			mockCollection,
+			segmentTranslator,
+			&mockAuthorizer{},
+			&mockTokenIssuer{})
 
 	return mockCollection, handlerForPlayer
 }
@@ -386,7 +650,9 @@ func TestPlayerListDelivered(unitTest *testing.T) {
 		for _, actualPlayer := range responsePlayerList.Players {
 			if (actualPlayer.Identifier == expectedPlayer.Identifier) &&
 				(actualPlayer.Name == expectedPlayer.Name) &&
-				(actualPlayer.Color == expectedPlayer.Color) {
+				(actualPlayer.Color == expectedPlayer.Color) &&
+				(actualPlayer.Presence == expectedPlayer.Presence) &&
+				reflect.DeepEqual(actualPlayer.Profile, expectedPlayer.Profile) {
 				foundPlayer = true
 			}
 		}
@@ -528,9 +794,10 @@ func TestRejectNewPlayerIfCollectionRejectsIt(unitTest *testing.T) {
 	mockCollection.ErrorToReturn = errors.New("expected error")
 	mockCollection.ReturnForAll = testPlayerStates
 
-	bodyObject := parsing.PlayerState{
-		Name:  "A. Player Name",
-		Color: "The color",
+	bodyObject := parsing.NewPlayerDefinition{
+		Name:     "A. Player Name",
+		Color:    "The color",
+		Password: "The password",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
@@ -554,8 +821,12 @@ func TestRejectNewPlayerIfCollectionRejectsIt(unitTest *testing.T) {
 		unitTest,
 		functionRecord,
 		functionNameAndArgument{
-			FunctionName:     "Add",
-			FunctionArgument: stringPair{first: bodyObject.Name, second: bodyObject.Color},
+			FunctionName: "Add",
+			FunctionArgument: stringTriple{
+				first:  bodyObject.Name,
+				second: bodyObject.Color,
+				third:  bodyObject.Password,
+			},
 		},
 		testIdentifier)
 }
@@ -576,9 +847,14 @@ func TestRejectNewPlayerIfIdentifierHasSegmentDelimiter(unitTest *testing.T) {
 	// It should unescape to \/\\\? as a literal.
 	breaksBase64 := "\\/\\\\\\?"
 
-	bodyObject := parsing.PlayerState{
-		Name:  breaksBase64,
-		Color: "The color",
+	// The identifier is chosen by the collection, so the mock collection has to return
+	// the problematic identifier.
+	mockCollection.ReturnForIdentifier = breaksBase64
+
+	bodyObject := parsing.NewPlayerDefinition{
+		Name:     breaksBase64,
+		Color:    "The color",
+		Password: "The password",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
@@ -602,8 +878,12 @@ func TestRejectNewPlayerIfIdentifierHasSegmentDelimiter(unitTest *testing.T) {
 		unitTest,
 		functionRecord,
 		functionNameAndArgument{
-			FunctionName:     "Add",
-			FunctionArgument: stringPair{first: bodyObject.Name, second: bodyObject.Color},
+			FunctionName: "Add",
+			FunctionArgument: stringTriple{
+				first:  bodyObject.Name,
+				second: bodyObject.Color,
+				third:  bodyObject.Password,
+			},
 		},
 		testIdentifier)
 }
@@ -614,9 +894,10 @@ func TestAcceptValidNewPlayer(unitTest *testing.T) {
 	mockCollection.ErrorToReturn = nil
 	mockCollection.ReturnForAll = testPlayerStates
 
-	bodyObject := parsing.PlayerState{
-		Name:  "A. Player Name",
-		Color: "The color",
+	bodyObject := parsing.NewPlayerDefinition{
+		Name:     "A. Player Name",
+		Color:    "The color",
+		Password: "The password",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
@@ -633,12 +914,16 @@ func TestAcceptValidNewPlayer(unitTest *testing.T) {
 
 	expectedRecords := []functionNameAndArgument{
 		functionNameAndArgument{
-			FunctionName:     "Add",
-			FunctionArgument: stringPair{first: bodyObject.Name, second: bodyObject.Color},
+			FunctionName: "Add",
+			FunctionArgument: stringTriple{
+				first:  bodyObject.Name,
+				second: bodyObject.Color,
+				third:  bodyObject.Password,
+			},
 		},
 		functionNameAndArgument{
-			FunctionName:     "All",
-			FunctionArgument: nil,
+			FunctionName:     "Get",
+			FunctionArgument: mockCollection.ReturnForIdentifier,
 		},
 	}
 
@@ -686,8 +971,8 @@ func TestRejectUpdatePlayerIfCollectionRejectsIt(unitTest *testing.T) {
 	mockCollection.ReturnForAll = testPlayerStates
 
 	bodyObject := parsing.PlayerState{
-		Name:  "A. Player Name",
-		Color: "The color",
+		PlayerIdentifier: "a player identifier",
+		Color:            "The color",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
@@ -712,7 +997,7 @@ func TestRejectUpdatePlayerIfCollectionRejectsIt(unitTest *testing.T) {
 		functionRecord,
 		functionNameAndArgument{
 			FunctionName:     "UpdateColor",
-			FunctionArgument: stringPair{first: bodyObject.Name, second: bodyObject.Color},
+			FunctionArgument: stringPair{first: bodyObject.PlayerIdentifier, second: bodyObject.Color},
 		},
 		testIdentifier)
 }
@@ -724,13 +1009,13 @@ func TestAcceptValidUpdatePlayer(unitTest *testing.T) {
 	mockCollection.ReturnForAll = testPlayerStates
 
 	bodyObject := parsing.PlayerState{
-		Name:  "A. Player Name",
-		Color: "The color",
+		PlayerIdentifier: "a player identifier",
+		Color:            "The color",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
 
-	_, responseCode :=
+	returnedInterface, responseCode :=
 		testHandler.HandlePost(context.Background(), bodyDecoder, []string{"update-player"})
 
 	if responseCode != http.StatusOK {
@@ -743,11 +1028,11 @@ func TestAcceptValidUpdatePlayer(unitTest *testing.T) {
 	expectedRecords := []functionNameAndArgument{
 		functionNameAndArgument{
 			FunctionName:     "UpdateColor",
-			FunctionArgument: stringPair{first: bodyObject.Name, second: bodyObject.Color},
+			FunctionArgument: stringPair{first: bodyObject.PlayerIdentifier, second: bodyObject.Color},
 		},
 		functionNameAndArgument{
-			FunctionName:     "All",
-			FunctionArgument: nil,
+			FunctionName:     "Get",
+			FunctionArgument: bodyObject.PlayerIdentifier,
 		},
 	}
 
@@ -756,6 +1041,14 @@ func TestAcceptValidUpdatePlayer(unitTest *testing.T) {
 		mockCollection.FunctionsAndArgumentsReceived,
 		expectedRecords,
 		testIdentifier)
+
+	responsePlayer, isInterfaceCorrect := returnedInterface.(parsing.PlayerState)
+	if !isInterfaceCorrect || (responsePlayer.PlayerIdentifier != bodyObject.PlayerIdentifier) {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected player %v",
+			returnedInterface,
+			bodyObject.PlayerIdentifier)
+	}
 }
 func TestRejectInvalidDeletePlayerWithMalformedRequest(unitTest *testing.T) {
 	testIdentifier := "Reject invalid POST delete-player with malformed JSON body"
@@ -794,8 +1087,8 @@ func TestRejectDeletePlayerIfCollectionRejectsIt(unitTest *testing.T) {
 	mockCollection.ReturnForAll = testPlayerStates
 
 	bodyObject := parsing.PlayerState{
-		Name:  "A. Player Name",
-		Color: "The color",
+		PlayerIdentifier: "a player identifier",
+		Color:            "The color",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
@@ -820,7 +1113,7 @@ func TestRejectDeletePlayerIfCollectionRejectsIt(unitTest *testing.T) {
 		functionRecord,
 		functionNameAndArgument{
 			FunctionName:     "Delete",
-			FunctionArgument: bodyObject.Name,
+			FunctionArgument: bodyObject.PlayerIdentifier,
 		},
 		testIdentifier)
 }
@@ -829,15 +1122,17 @@ func TestAcceptValidDeletePlayer(unitTest *testing.T) {
 	testIdentifier := "POST delete-player"
 	mockCollection, testHandler := newPlayerCollectionAndHandler()
 	mockCollection.ErrorToReturn = nil
+	mockCollection.ReturnForSearch =
+		player_state.SearchResult{Players: testPlayerStates}
 
 	bodyObject := parsing.PlayerState{
-		Name:  "A. Player Name",
-		Color: "The color",
+		PlayerIdentifier: "a player identifier",
+		Color:            "The color",
 	}
 
 	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
 
-	_, responseCode :=
+	returnedInterface, responseCode :=
 		testHandler.HandlePost(context.Background(), bodyDecoder, []string{"delete-player"})
 
 	if responseCode != http.StatusOK {
@@ -850,11 +1145,11 @@ func TestAcceptValidDeletePlayer(unitTest *testing.T) {
 	expectedRecords := []functionNameAndArgument{
 		functionNameAndArgument{
 			FunctionName:     "Delete",
-			FunctionArgument: bodyObject.Name,
+			FunctionArgument: bodyObject.PlayerIdentifier,
 		},
 		functionNameAndArgument{
-			FunctionName:     "All",
-			FunctionArgument: nil,
+			FunctionName:     "Search",
+			FunctionArgument: searchArguments{nameOrder: player_state.NameAscending},
 		},
 	}
 
@@ -863,4 +1158,1108 @@ func TestAcceptValidDeletePlayer(unitTest *testing.T) {
 		mockCollection.FunctionsAndArgumentsReceived,
 		expectedRecords,
 		testIdentifier)
+
+	responsePage, isInterfaceCorrect := returnedInterface.(parsing.PlayerPage)
+	if !isInterfaceCorrect || (len(responsePage.Players) != len(testPlayerStates)) {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected page of %v players",
+			returnedInterface,
+			len(testPlayerStates))
+	}
+}
+
+func TestRejectUpdatePlayerWithoutAuthentication(unitTest *testing.T) {
+	testCases := []struct {
+		testName             string
+		requestContext       context.Context
+		expectedResponseCode int
+	}{
+		{
+			testName:             "no session",
+			requestContext:       context.Background(),
+			expectedResponseCode: http.StatusUnauthorized,
+		},
+		{
+			testName: "different player",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				"Someone Else"),
+			expectedResponseCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "Reject POST update-player/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockPlayerCollection{}
+			testHandler :=
+				player_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					&authentication.ContextAuthorizer{},
+					&mockTokenIssuer{})
+
+			bodyObject := parsing.PlayerState{
+				PlayerIdentifier: "a player identifier",
+				Color:            "The color",
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					testCase.requestContext,
+					bodyDecoder,
+					[]string{"update-player"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertNoFunctionWasCalled(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testIdentifier)
+		})
+	}
+}
+
+func TestRejectLogInIfCollectionRejectsIt(unitTest *testing.T) {
+	testIdentifier := "Reject POST log-in if collection rejects it"
+	mockCollection, testHandler := newPlayerCollectionAndHandler()
+	mockCollection.ErrorToReturn = errors.New("expected error")
+
+	bodyObject := parsing.PlayerCredentials{
+		Name:     "A. Player Name",
+		Password: "The password",
+	}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	_, responseCode :=
+		testHandler.HandlePost(context.Background(), bodyDecoder, []string{"log-in"})
+
+	if responseCode != http.StatusUnauthorized {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusUnauthorized,
+			responseCode)
+	}
+
+	functionRecord :=
+		mockCollection.getFirstAndEnsureOnly(
+			unitTest,
+			testIdentifier)
+
+	assertFunctionRecordIsCorrect(
+		unitTest,
+		functionRecord,
+		functionNameAndArgument{
+			FunctionName:     "Authenticate",
+			FunctionArgument: stringPair{first: bodyObject.Name, second: bodyObject.Password},
+		},
+		testIdentifier)
+}
+
+func TestAcceptValidLogIn(unitTest *testing.T) {
+	testIdentifier := "POST log-in"
+	mockCollection, testHandler := newPlayerCollectionAndHandler()
+	mockCollection.ErrorToReturn = nil
+	mockCollection.ReturnForIdentifier = "a player identifier"
+
+	bodyObject := parsing.PlayerCredentials{
+		Name:     "A. Player Name",
+		Password: "The password",
+	}
+
+	bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+	returnedInterface, responseCode :=
+		testHandler.HandlePost(context.Background(), bodyDecoder, []string{"log-in"})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	// The token should be issued for the identifier rather than the display name.
+	expectedToken := parsing.SessionToken{
+		Identifier: segmentTranslatorForTest().ToSegment(
+			mockCollection.ReturnForIdentifier),
+		PlayerIdentifier: mockCollection.ReturnForIdentifier,
+		PlayerName:       bodyObject.Name,
+		Token:            "token for " + mockCollection.ReturnForIdentifier,
+	}
+
+	if returnedInterface != expectedToken {
+		unitTest.Fatalf(
+			testIdentifier+"/returned %v instead of expected %v",
+			returnedInterface,
+			expectedToken)
+	}
+}
+
+func TestRenamePlayer(unitTest *testing.T) {
+	bodyObject := parsing.PlayerState{
+		PlayerIdentifier: "a player identifier",
+		Name:             "A. New Name",
+	}
+
+	renameRecord := functionNameAndArgument{
+		FunctionName: "Rename",
+		FunctionArgument: stringPair{
+			first:  bodyObject.PlayerIdentifier,
+			second: bodyObject.Name,
+		},
+	}
+
+	testCases := []struct {
+		testName                 string
+		authenticatedPlayer      string
+		errorFromCollection      error
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "no session",
+			expectedResponseCode:     http.StatusUnauthorized,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:                 "different player",
+			authenticatedPlayer:      "someone else",
+			expectedResponseCode:     http.StatusForbidden,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:                 "collection rejects new name",
+			authenticatedPlayer:      bodyObject.PlayerIdentifier,
+			errorFromCollection:      errors.New("expected error"),
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{renameRecord},
+		},
+		{
+			testName:             "same player",
+			authenticatedPlayer:  bodyObject.PlayerIdentifier,
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				renameRecord,
+				functionNameAndArgument{
+					FunctionName:     "Get",
+					FunctionArgument: bodyObject.PlayerIdentifier,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST rename-player/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockPlayerCollection{}
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+			testHandler :=
+				player_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					authentication.NewContextAuthorizer(mockCollection),
+					&mockTokenIssuer{})
+
+			requestContext := context.Background()
+			if testCase.authenticatedPlayer != "" {
+				requestContext =
+					authentication.ContextWithAuthenticatedPlayer(
+						requestContext,
+						testCase.authenticatedPlayer)
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					requestContext,
+					bodyDecoder,
+					[]string{"rename-player"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+		})
+	}
+}
+
+func TestUpdateProfile(unitTest *testing.T) {
+	bodyObject := parsing.PlayerState{
+		PlayerIdentifier: "a player identifier",
+		Profile: parsing.PlayerProfile{
+			PreferredRulesets:    []int{3},
+			PreferredHintDisplay: player_state.HintDisplayText,
+			Timezone:             "UTC",
+			NotifyOnInvitation:   true,
+			NotifyOnChatMessage:  true,
+		},
+	}
+
+	expectedProfile := player_state.Profile{
+		PreferredRulesets:    []int{3},
+		PreferredHintDisplay: player_state.HintDisplayText,
+		Timezone:             "UTC",
+		Notifications: player_state.NotificationPreferences{
+			OnInvitation:  true,
+			OnChatMessage: true,
+		},
+	}
+
+	updateRecord := functionNameAndArgument{
+		FunctionName:     "UpdateProfile",
+		FunctionArgument: bodyObject.PlayerIdentifier,
+	}
+
+	testCases := []struct {
+		testName                 string
+		authenticatedPlayer      string
+		errorFromCollection      error
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "no session",
+			expectedResponseCode:     http.StatusUnauthorized,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:                 "different player",
+			authenticatedPlayer:      "someone else",
+			expectedResponseCode:     http.StatusForbidden,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:                 "collection rejects profile",
+			authenticatedPlayer:      bodyObject.PlayerIdentifier,
+			errorFromCollection:      errors.New("expected error"),
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{updateRecord},
+		},
+		{
+			testName:             "same player",
+			authenticatedPlayer:  bodyObject.PlayerIdentifier,
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				updateRecord,
+				functionNameAndArgument{
+					FunctionName:     "Get",
+					FunctionArgument: bodyObject.PlayerIdentifier,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST update-profile/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockPlayerCollection{}
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+			testHandler :=
+				player_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					authentication.NewContextAuthorizer(mockCollection),
+					&mockTokenIssuer{})
+
+			requestContext := context.Background()
+			if testCase.authenticatedPlayer != "" {
+				requestContext =
+					authentication.ContextWithAuthenticatedPlayer(
+						requestContext,
+						testCase.authenticatedPlayer)
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					requestContext,
+					bodyDecoder,
+					[]string{"update-profile"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+
+			if (len(testCase.expectedFunctionsAndArgs) > 0) &&
+				!reflect.DeepEqual(mockCollection.ArgumentForUpdateProfile, expectedProfile) {
+				unitTest.Fatalf(
+					testIdentifier+"/passed profile %+v to collection, expected %+v.",
+					mockCollection.ArgumentForUpdateProfile,
+					expectedProfile)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDeletePlayerForPlayerOrAdministrator(unitTest *testing.T) {
+	playerToDelete := "identifier of player to delete"
+
+	deletionRecords := []functionNameAndArgument{
+		functionNameAndArgument{
+			FunctionName:     "Delete",
+			FunctionArgument: playerToDelete,
+		},
+		functionNameAndArgument{
+			FunctionName:     "Search",
+			FunctionArgument: searchArguments{nameOrder: player_state.NameAscending},
+		},
+	}
+
+	testCases := []struct {
+		testName                 string
+		requestContext           context.Context
+		authenticatedIsAdmin     bool
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "no session",
+			requestContext:           context.Background(),
+			expectedResponseCode:     http.StatusUnauthorized,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName: "different player",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				"Someone Else"),
+			expectedResponseCode: http.StatusForbidden,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName:     "IsAdministrator",
+					FunctionArgument: "Someone Else",
+				},
+			},
+		},
+		{
+			testName: "same player",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				playerToDelete),
+			expectedResponseCode:     http.StatusOK,
+			expectedFunctionsAndArgs: deletionRecords,
+		},
+		{
+			testName: "administrator",
+			requestContext: authentication.ContextWithAuthenticatedPlayer(
+				context.Background(),
+				"An Administrator"),
+			authenticatedIsAdmin: true,
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: append(
+				[]functionNameAndArgument{
+					functionNameAndArgument{
+						FunctionName:     "IsAdministrator",
+						FunctionArgument: "An Administrator",
+					},
+				},
+				deletionRecords...),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST delete-player/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockPlayerCollection{}
+			mockCollection.ReturnForIsAdministrator = testCase.authenticatedIsAdmin
+			testHandler :=
+				player_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					authentication.NewContextAuthorizer(mockCollection),
+					&mockTokenIssuer{})
+
+			bodyObject := parsing.PlayerState{
+				PlayerIdentifier: playerToDelete,
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					testCase.requestContext,
+					bodyDecoder,
+					[]string{"delete-player"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+		})
+	}
+}
+
+func TestAuthorizeSetPlayerRoleOnlyForAdministrator(unitTest *testing.T) {
+	bodyObject := parsing.PlayerState{
+		PlayerIdentifier: "a player identifier",
+		Role:             player_state.RoleAdministrator,
+	}
+
+	testCases := []struct {
+		testName                 string
+		authenticatedPlayer      string
+		authenticatedIsAdmin     bool
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "no session",
+			expectedResponseCode:     http.StatusUnauthorized,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:             "player setting own role",
+			authenticatedPlayer:  bodyObject.PlayerIdentifier,
+			expectedResponseCode: http.StatusForbidden,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName:     "IsAdministrator",
+					FunctionArgument: bodyObject.PlayerIdentifier,
+				},
+			},
+		},
+		{
+			testName:             "administrator",
+			authenticatedPlayer:  "An Administrator",
+			authenticatedIsAdmin: true,
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName:     "IsAdministrator",
+					FunctionArgument: "An Administrator",
+				},
+				functionNameAndArgument{
+					FunctionName:     "UpdateRole",
+					FunctionArgument: stringPair{first: bodyObject.PlayerIdentifier, second: bodyObject.Role},
+				},
+				functionNameAndArgument{
+					FunctionName:     "Get",
+					FunctionArgument: bodyObject.PlayerIdentifier,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST set-player-role/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockPlayerCollection{}
+			mockCollection.ReturnForIsAdministrator = testCase.authenticatedIsAdmin
+			testHandler :=
+				player_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					authentication.NewContextAuthorizer(mockCollection),
+					&mockTokenIssuer{})
+
+			requestContext := context.Background()
+			if testCase.authenticatedPlayer != "" {
+				requestContext =
+					authentication.ContextWithAuthenticatedPlayer(
+						requestContext,
+						testCase.authenticatedPlayer)
+			}
+
+			bodyDecoder := DecoderAroundInterface(unitTest, testIdentifier, bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					requestContext,
+					bodyDecoder,
+					[]string{"set-player-role"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+		})
+	}
+}
+
+func TestSearchPlayers(unitTest *testing.T) {
+	testCases := []struct {
+		testName                 string
+		bodyObject               interface{}
+		errorFromCollection      error
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "malformed request",
+			bodyObject:               "not a search",
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName: "invalid sort order",
+			bodyObject: parsing.PlayerSearch{
+				NamePrefix: "Bob",
+				SortOrder:  "color",
+			},
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName: "collection rejects search",
+			bodyObject: parsing.PlayerSearch{
+				NamePrefix: "Bob",
+				Cursor:     "bad cursor",
+			},
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName: "Search",
+					FunctionArgument: searchArguments{
+						namePrefix: "Bob",
+						nameOrder:  player_state.NameAscending,
+						pageCursor: "bad cursor",
+					},
+				},
+			},
+		},
+		{
+			testName: "default order",
+			bodyObject: parsing.PlayerSearch{
+				NamePrefix: "Bob",
+				PageSize:   2,
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName: "Search",
+					FunctionArgument: searchArguments{
+						namePrefix: "Bob",
+						nameOrder:  player_state.NameAscending,
+						pageSize:   2,
+					},
+				},
+			},
+		},
+		{
+			testName: "descending order with cursor",
+			bodyObject: parsing.PlayerSearch{
+				SortOrder: "-name",
+				Cursor:    "a cursor",
+			},
+			expectedResponseCode: http.StatusOK,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName: "Search",
+					FunctionArgument: searchArguments{
+						nameOrder:  player_state.NameDescending,
+						pageCursor: "a cursor",
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST search-players/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection, testHandler := newPlayerCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+			mockCollection.ReturnForSearch =
+				player_state.SearchResult{
+					Players:    testPlayerStates[:2],
+					NextCursor: "next cursor",
+				}
+
+			bodyDecoder :=
+				DecoderAroundInterface(unitTest, testIdentifier, testCase.bodyObject)
+
+			returnedInterface, responseCode :=
+				testHandler.HandlePost(
+					context.Background(),
+					bodyDecoder,
+					[]string{"search-players"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+
+			if responseCode != http.StatusOK {
+				return
+			}
+
+			expectedPage := parsing.PlayerPage{
+				Players:    testPlayerList.Players[:2],
+				NextCursor: "next cursor",
+			}
+
+			if !reflect.DeepEqual(returnedInterface, expectedPage) {
+				unitTest.Fatalf(
+					testIdentifier+"/returned %+v, expected %+v.",
+					returnedInterface,
+					expectedPage)
+			}
+		})
+	}
+}
+
+func TestContactsOfPlayer(unitTest *testing.T) {
+	playerIdentifier := "a player identifier"
+	playerSegment := segmentTranslatorForTest().ToSegment(playerIdentifier)
+	contactsRecords := []functionNameAndArgument{
+		functionNameAndArgument{
+			FunctionName:     "Contacts",
+			FunctionArgument: playerIdentifier,
+		},
+		functionNameAndArgument{
+			FunctionName:     "Friends",
+			FunctionArgument: playerIdentifier,
+		},
+	}
+
+	testCases := []struct {
+		testName                 string
+		relevantSegments         []string
+		errorFromCollection      error
+		expectedResponseCode     int
+		expectedFunctionsAndArgs []functionNameAndArgument
+	}{
+		{
+			testName:                 "no player segment",
+			relevantSegments:         []string{"contacts-of-player"},
+			expectedResponseCode:     http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{},
+		},
+		{
+			testName:             "error from collection",
+			relevantSegments:     []string{"contacts-of-player", playerSegment},
+			errorFromCollection:  errors.New("expected error"),
+			expectedResponseCode: http.StatusBadRequest,
+			expectedFunctionsAndArgs: []functionNameAndArgument{
+				contactsRecords[0],
+			},
+		},
+		{
+			testName:                 "valid player",
+			relevantSegments:         []string{"contacts-of-player", playerSegment},
+			expectedResponseCode:     http.StatusOK,
+			expectedFunctionsAndArgs: contactsRecords,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "GET contacts-of-player/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection, testHandler := newPlayerCollectionAndHandler()
+			mockCollection.ErrorToReturn = testCase.errorFromCollection
+			mockCollection.ReturnForFriends = testPlayerStates[1:]
+			mockCollection.ReturnForContacts =
+				player_state.Contacts{
+					FriendIdentifiers: []string{
+						testPlayerStates[1].Identifier(),
+						testPlayerStates[2].Identifier(),
+					},
+					Groups: []player_state.Group{
+						player_state.Group{
+							Name:              "Tuesday",
+							MemberIdentifiers: []string{testPlayerStates[2].Identifier()},
+						},
+					},
+				}
+
+			returnedInterface, responseCode :=
+				testHandler.HandleGet(context.Background(), testCase.relevantSegments)
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedFunctionsAndArgs,
+				testIdentifier)
+
+			if responseCode != http.StatusOK {
+				return
+			}
+
+			expectedContacts := parsing.ContactList{
+				Friends: testPlayerList.Players[1:],
+				Groups: []parsing.PlayerGroup{
+					parsing.PlayerGroup{
+						GroupName:         "Tuesday",
+						MemberIdentifiers: []string{testPlayerStates[2].Identifier()},
+					},
+				},
+			}
+
+			if !reflect.DeepEqual(returnedInterface, expectedContacts) {
+				unitTest.Fatalf(
+					testIdentifier+"/returned %+v, expected %+v.",
+					returnedInterface,
+					expectedContacts)
+			}
+		})
+	}
+}
+
+func TestUpdateContacts(unitTest *testing.T) {
+	playerIdentifier := "a player identifier"
+	friendIndication :=
+		parsing.FriendIndication{
+			PlayerIdentifier: playerIdentifier,
+			FriendIdentifier: "a friend identifier",
+		}
+	groupDefinition :=
+		parsing.GroupDefinition{
+			PlayerIdentifier:  playerIdentifier,
+			GroupName:         "Tuesday",
+			MemberIdentifiers: []string{"a friend identifier", "another identifier"},
+		}
+
+	testCases := []struct {
+		segment        string
+		bodyObject     interface{}
+		expectedRecord functionNameAndArgument
+	}{
+		{
+			segment:    "add-friend",
+			bodyObject: friendIndication,
+			expectedRecord: functionNameAndArgument{
+				FunctionName: "AddFriend",
+				FunctionArgument: stringPair{
+					first:  playerIdentifier,
+					second: friendIndication.FriendIdentifier,
+				},
+			},
+		},
+		{
+			segment:    "remove-friend",
+			bodyObject: friendIndication,
+			expectedRecord: functionNameAndArgument{
+				FunctionName: "RemoveFriend",
+				FunctionArgument: stringPair{
+					first:  playerIdentifier,
+					second: friendIndication.FriendIdentifier,
+				},
+			},
+		},
+		{
+			segment:    "save-group",
+			bodyObject: groupDefinition,
+			expectedRecord: functionNameAndArgument{
+				FunctionName: "SaveGroup",
+				FunctionArgument: stringPair{
+					first:  playerIdentifier,
+					second: groupDefinition.GroupName,
+				},
+			},
+		},
+		{
+			segment:    "delete-group",
+			bodyObject: groupDefinition,
+			expectedRecord: functionNameAndArgument{
+				FunctionName: "DeleteGroup",
+				FunctionArgument: stringPair{
+					first:  playerIdentifier,
+					second: groupDefinition.GroupName,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, authenticatedPlayer := range []string{"", "someone else", playerIdentifier} {
+			for _, errorFromCollection := range []error{nil, errors.New("expected error")} {
+				testIdentifier :=
+					fmt.Sprintf(
+						"POST %v/session of %q/error %v",
+						testCase.segment,
+						authenticatedPlayer,
+						errorFromCollection)
+				unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+					mockCollection := &mockPlayerCollection{}
+					mockCollection.ErrorToReturn = errorFromCollection
+					testHandler :=
+						player_endpoint.New(
+							mockCollection,
+							segmentTranslatorForTest(),
+							authentication.NewContextAuthorizer(mockCollection),
+							&mockTokenIssuer{})
+
+					requestContext := context.Background()
+					if authenticatedPlayer != "" {
+						requestContext =
+							authentication.ContextWithAuthenticatedPlayer(
+								requestContext,
+								authenticatedPlayer)
+					}
+
+					bodyDecoder :=
+						DecoderAroundInterface(unitTest, testIdentifier, testCase.bodyObject)
+
+					_, responseCode :=
+						testHandler.HandlePost(
+							requestContext,
+							bodyDecoder,
+							[]string{testCase.segment})
+
+					expectedResponseCode := http.StatusOK
+					expectedFunctionsAndArgs := []functionNameAndArgument{
+						testCase.expectedRecord,
+						functionNameAndArgument{
+							FunctionName:     "Contacts",
+							FunctionArgument: playerIdentifier,
+						},
+						functionNameAndArgument{
+							FunctionName:     "Friends",
+							FunctionArgument: playerIdentifier,
+						},
+					}
+
+					if authenticatedPlayer == "" {
+						expectedResponseCode = http.StatusUnauthorized
+						expectedFunctionsAndArgs = []functionNameAndArgument{}
+					} else if authenticatedPlayer != playerIdentifier {
+						expectedResponseCode = http.StatusForbidden
+						expectedFunctionsAndArgs = []functionNameAndArgument{}
+					} else if errorFromCollection != nil {
+						expectedResponseCode = http.StatusBadRequest
+						expectedFunctionsAndArgs = expectedFunctionsAndArgs[:1]
+					}
+
+					if responseCode != expectedResponseCode {
+						unitTest.Fatalf(
+							testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+							expectedResponseCode,
+							responseCode)
+					}
+
+					assertFunctionRecordsAreCorrect(
+						unitTest,
+						mockCollection.FunctionsAndArgumentsReceived,
+						expectedFunctionsAndArgs,
+						testIdentifier)
+
+					if (testCase.segment == "save-group") &&
+						(len(expectedFunctionsAndArgs) > 0) &&
+						!reflect.DeepEqual(
+							mockCollection.ArgumentForSaveGroup,
+							groupDefinition.MemberIdentifiers) {
+						unitTest.Fatalf(
+							testIdentifier+"/passed members %v to collection, expected %v.",
+							mockCollection.ArgumentForSaveGroup,
+							groupDefinition.MemberIdentifiers)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestHeartbeat(unitTest *testing.T) {
+	playerIdentifier := "a player identifier"
+	heartbeatBody :=
+		parsing.Heartbeat{
+			PlayerIdentifier: playerIdentifier,
+			ViewedGameName:   "test game",
+			IsIdle:           true,
+		}
+
+	testCases := []struct {
+		testName             string
+		authenticatedPlayer  string
+		bodyObject           interface{}
+		expectedResponseCode int
+		expectedRecords      []functionNameAndArgument
+	}{
+		{
+			testName:             "no session",
+			bodyObject:           heartbeatBody,
+			expectedResponseCode: http.StatusUnauthorized,
+			expectedRecords:      []functionNameAndArgument{},
+		},
+		{
+			testName:             "session of other player",
+			authenticatedPlayer:  "someone else",
+			bodyObject:           heartbeatBody,
+			expectedResponseCode: http.StatusForbidden,
+			expectedRecords:      []functionNameAndArgument{},
+		},
+		{
+			testName:             "malformed request",
+			authenticatedPlayer:  playerIdentifier,
+			bodyObject:           "not a heartbeat",
+			expectedResponseCode: http.StatusBadRequest,
+			expectedRecords:      []functionNameAndArgument{},
+		},
+		{
+			testName:             "session of player",
+			authenticatedPlayer:  playerIdentifier,
+			bodyObject:           heartbeatBody,
+			expectedResponseCode: http.StatusOK,
+			expectedRecords: []functionNameAndArgument{
+				functionNameAndArgument{
+					FunctionName: "RecordHeartbeat",
+					FunctionArgument: heartbeatArguments{
+						playerIdentifier: playerIdentifier,
+						viewedGameName:   heartbeatBody.ViewedGameName,
+						isIdle:           heartbeatBody.IsIdle,
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testIdentifier := "POST heartbeat/" + testCase.testName
+		unitTest.Run(testIdentifier, func(unitTest *testing.T) {
+			mockCollection := &mockPlayerCollection{}
+			testHandler :=
+				player_endpoint.New(
+					mockCollection,
+					segmentTranslatorForTest(),
+					authentication.NewContextAuthorizer(mockCollection),
+					&mockTokenIssuer{})
+
+			requestContext := context.Background()
+			if testCase.authenticatedPlayer != "" {
+				requestContext =
+					authentication.ContextWithAuthenticatedPlayer(
+						requestContext,
+						testCase.authenticatedPlayer)
+			}
+
+			bodyDecoder :=
+				DecoderAroundInterface(unitTest, testIdentifier, testCase.bodyObject)
+
+			_, responseCode :=
+				testHandler.HandlePost(
+					requestContext,
+					bodyDecoder,
+					[]string{"heartbeat"})
+
+			if responseCode != testCase.expectedResponseCode {
+				unitTest.Fatalf(
+					testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+					testCase.expectedResponseCode,
+					responseCode)
+			}
+
+			assertFunctionRecordsAreCorrect(
+				unitTest,
+				mockCollection.FunctionsAndArgumentsReceived,
+				testCase.expectedRecords,
+				testIdentifier)
+		})
+	}
+}
+
+func TestPlayerListShowsPresence(unitTest *testing.T) {
+	testIdentifier := "GET registered-players with presence"
+	mockCollection, testHandler := newPlayerCollectionAndHandler()
+	mockCollection.ReturnForAll = testPlayerStates
+	mockCollection.ReturnForPresence =
+		map[string]player_state.Presence{
+			testPlayerStates[0].Identifier(): player_state.Presence{
+				Status:         player_state.PresenceOnline,
+				ViewedGameName: "test game",
+			},
+			testPlayerStates[1].Identifier(): player_state.Presence{
+				Status: player_state.PresenceIdle,
+			},
+		}
+
+	returnedInterface, responseCode :=
+		testHandler.HandleGet(context.Background(), []string{"registered-players"})
+
+	if responseCode != http.StatusOK {
+		unitTest.Fatalf(
+			testIdentifier+"/did not return expected HTTP code %v, instead was %v.",
+			http.StatusOK,
+			responseCode)
+	}
+
+	responsePlayerList, isInterfaceCorrect := returnedInterface.(parsing.PlayerList)
+	if !isInterfaceCorrect {
+		unitTest.Fatalf(
+			testIdentifier+"/received %v instead of expected parsing.PlayerList",
+			returnedInterface)
+	}
+
+	expectedPresences := []string{
+		player_state.PresenceOnline,
+		player_state.PresenceIdle,
+		player_state.PresenceOffline,
+	}
+
+	if len(responsePlayerList.Players) != len(expectedPresences) {
+		unitTest.Fatalf(
+			testIdentifier+"/returned %v, expected %v players.",
+			responsePlayerList,
+			len(expectedPresences))
+	}
+
+	for playerIndex, expectedPresence := range expectedPresences {
+		actualPresence := responsePlayerList.Players[playerIndex].Presence
+		if actualPresence != expectedPresence {
+			unitTest.Fatalf(
+				testIdentifier+"/player %v had presence %q, expected %q.",
+				playerIndex,
+				actualPresence,
+				expectedPresence)
+		}
+	}
 }
@@ -0,0 +1,410 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// handleRulesets responds to requests for the collection of rulesets at /rulesets and
+// for single rulesets at /rulesets/{identifier}, which can only be read.
+func (handler *Handler) handleRulesets(
+	httpMethod string,
+	relevantSegments []string) (interface{}, int) {
+	if httpMethod != http.MethodGet {
+		return methodNotAllowed(httpMethod, "rulesets")
+	}
+
+	switch len(relevantSegments) {
+	case 0:
+		rulesetResources := make([]parsing.ResourceObject, 0)
+		for _, rulesetIdentifier := range game.ValidRulesetIdentifiers() {
+			// There definitely will not be an error from RulesetFromIdentifier if we
+			// iterate only over the valid identifiers.
+			availableRuleset, _ := game.RulesetFromIdentifier(rulesetIdentifier)
+			rulesetResources =
+				append(rulesetResources, rulesetResource(rulesetIdentifier, availableRuleset))
+		}
+
+		return parsing.ResourceDocument{Data: rulesetResources}, http.StatusOK
+	case 1:
+		rulesetIdentifier, errorFromIdentifier := strconv.Atoi(relevantSegments[0])
+		if errorFromIdentifier != nil {
+			return resourceNotFound(relevantSegments)
+		}
+
+		chosenRuleset, errorFromRuleset := game.RulesetFromIdentifier(rulesetIdentifier)
+		if errorFromRuleset != nil {
+			return errorResponse(errorFromRuleset, http.StatusNotFound)
+		}
+
+		resourceDocument := parsing.ResourceDocument{
+			Data: rulesetResource(rulesetIdentifier, chosenRuleset),
+		}
+
+		return resourceDocument, http.StatusOK
+	default:
+		return resourceNotFound(relevantSegments)
+	}
+}
+
+// handleGames responds to requests for the collection of games of the authenticated
+// player at /games, for single games at /games/{name}, for the actions and chat
+// messages of the authenticated player in a game at /games/{name}/actions and
+// /games/{name}/chat-messages, and for the participants of a game at
+// /games/{name}/participants/{identifier}.
+func (handler *Handler) handleGames(
+	requestContext context.Context,
+	httpMethod string,
+	relevantSegments []string,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	switch len(relevantSegments) {
+	case 0:
+		switch httpMethod {
+		case http.MethodGet:
+			return handler.writeGamesForPlayer(requestContext)
+		case http.MethodPost:
+			return handler.handleNewGame(requestContext, httpBodyDecoder)
+		default:
+			return methodNotAllowed(httpMethod, "games")
+		}
+	case 1:
+		gameName := relevantSegments[0]
+		switch httpMethod {
+		case http.MethodGet:
+			return handler.writeGame(requestContext, gameName)
+		case http.MethodDelete:
+			return handler.handleDeleteGame(requestContext, gameName)
+		default:
+			return methodNotAllowed(httpMethod, "a game")
+		}
+	case 2:
+		gameName := relevantSegments[0]
+		switch relevantSegments[1] {
+		case "actions":
+			if httpMethod != http.MethodPost {
+				return methodNotAllowed(httpMethod, "the actions of a game")
+			}
+
+			return handler.handleGameAction(requestContext, gameName, httpBodyDecoder)
+		case "chat-messages":
+			if httpMethod != http.MethodPost {
+				return methodNotAllowed(httpMethod, "the chat messages of a game")
+			}
+
+			return handler.handleChatMessage(requestContext, gameName, httpBodyDecoder)
+		default:
+			return resourceNotFound(relevantSegments)
+		}
+	case 3:
+		if relevantSegments[1] != "participants" {
+			return resourceNotFound(relevantSegments)
+		}
+
+		if httpMethod != http.MethodDelete {
+			return methodNotAllowed(httpMethod, "a participant of a game")
+		}
+
+		return handler.handleLeaveGame(requestContext, relevantSegments[0], relevantSegments[2])
+	default:
+		return resourceNotFound(relevantSegments)
+	}
+}
+
+// writeGamesForPlayer writes the summaries of all the games which have the
+// authenticated player as a participant.
+func (handler *Handler) writeGamesForPlayer(
+	requestContext context.Context) (interface{}, int) {
+	playerIdentifier, errorFromAuthentication :=
+		authentication.RequireAuthentication(requestContext)
+	if errorFromAuthentication != nil {
+		return classifiedErrorResponse(errorFromAuthentication, http.StatusUnauthorized)
+	}
+
+	allGamesWithPlayer, errorFromView :=
+		handler.gameCollection.ViewAllWithPlayer(requestContext, playerIdentifier)
+	if errorFromView != nil {
+		return classifiedErrorResponse(errorFromView, http.StatusBadRequest)
+	}
+
+	gameResources := make([]parsing.ResourceObject, 0, len(allGamesWithPlayer))
+	for _, gameView := range allGamesWithPlayer {
+		_, playerTurnIndex, _ := gameView.CurrentTurnOrder()
+		gameResources = append(gameResources, parsing.ResourceObject{
+			Type: "games",
+			ID:   gameView.GameName(),
+			Attributes: parsing.GameSummaryAttributes{
+				IsPlayerTurn: playerTurnIndex == 0,
+				Version:      gameView.Version(),
+			},
+		})
+	}
+
+	// The versions of the games are part of the body, so the hash of the body is enough
+	// to identify it.
+	return parsing.NewTaggedBody(parsing.ResourceDocument{Data: gameResources}, ""),
+		http.StatusOK
+}
+
+// handleNewGame invites the players given by the attributes of the resource in the
+// body to a new game with the authenticated player as the host, and writes the
+// invitation along with Accepted, as the game is only created once all the invited
+// players have accepted.
+func (handler *Handler) handleNewGame(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	hostIdentifier, errorFromAuthentication :=
+		authentication.RequireAuthentication(requestContext)
+	if errorFromAuthentication != nil {
+		return classifiedErrorResponse(errorFromAuthentication, http.StatusUnauthorized)
+	}
+
+	var newGame parsing.NewGameAttributes
+	statusFromDecode, errorFromDecode :=
+		decodeAttributes(httpBodyDecoder, "games", &newGame)
+	if errorFromDecode != nil {
+		return errorResponse(errorFromDecode, statusFromDecode)
+	}
+
+	gameRuleset, errorFromRuleset := game.RulesetFromIdentifier(newGame.RulesetIdentifier)
+	if errorFromRuleset != nil {
+		return classifiedErrorResponse(errorFromRuleset, http.StatusBadRequest)
+	}
+
+	invitedPlayers := append([]string{hostIdentifier}, newGame.PlayerIdentifiers...)
+
+	errorFromInvitation :=
+		handler.gameCollection.InviteToNew(
+			requestContext,
+			newGame.Name,
+			gameRuleset,
+			invitedPlayers)
+	if errorFromInvitation != nil {
+		return classifiedErrorResponse(errorFromInvitation, http.StatusBadRequest)
+	}
+
+	resourceDocument := parsing.ResourceDocument{
+		Data: parsing.ResourceObject{
+			Type: "invitations",
+			ID:   newGame.Name,
+			Attributes: parsing.NewGameAttributes{
+				Name:              newGame.Name,
+				RulesetIdentifier: newGame.RulesetIdentifier,
+				PlayerIdentifiers: invitedPlayers,
+			},
+		},
+	}
+
+	return resourceDocument, http.StatusAccepted
+}
+
+// writeGame writes the given game as seen by the authenticated player, in the same form
+// as the first version of the API, with an entity tag as for the first version.
+func (handler *Handler) writeGame(
+	requestContext context.Context,
+	gameName string) (interface{}, int) {
+	playerIdentifier, errorFromAuthentication :=
+		authentication.RequireAuthentication(requestContext)
+	if errorFromAuthentication != nil {
+		return classifiedErrorResponse(errorFromAuthentication, http.StatusUnauthorized)
+	}
+
+	gameView, errorFromView :=
+		handler.gameCollection.ViewState(requestContext, gameName, playerIdentifier)
+	if errorFromView != nil {
+		return classifiedErrorResponse(errorFromView, http.StatusInternalServerError)
+	}
+
+	endpointObject, errorFromConversion :=
+		handler.gameViewConverter.GameViewForFrontend(
+			requestContext,
+			gameView,
+			playerIdentifier)
+	if errorFromConversion != nil {
+		return classifiedErrorResponse(errorFromConversion, http.StatusInternalServerError)
+	}
+
+	resourceDocument := parsing.ResourceDocument{
+		Data: parsing.ResourceObject{
+			Type:       "games",
+			ID:         gameName,
+			Attributes: endpointObject,
+		},
+	}
+
+	return parsing.NewTaggedBody(resourceDocument, strconv.Itoa(gameView.Version())),
+		http.StatusOK
+}
+
+// handleDeleteGame deletes the given game, as long as the request comes from the
+// creator of the game or from an administrator.
+func (handler *Handler) handleDeleteGame(
+	requestContext context.Context,
+	gameName string) (interface{}, int) {
+	gameCreator, errorFromCreator := handler.gameCollection.Creator(requestContext, gameName)
+	if errorFromCreator != nil {
+		return classifiedErrorResponse(errorFromCreator, http.StatusBadRequest)
+	}
+
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayerOrAdministrator(
+			requestContext,
+			gameCreator)
+	if errorFromAuthorization != nil {
+		return classifiedErrorResponse(errorFromAuthorization, http.StatusBadRequest)
+	}
+
+	errorFromDelete := handler.gameCollection.Delete(requestContext, gameName)
+	if errorFromDelete != nil {
+		return classifiedErrorResponse(errorFromDelete, http.StatusInternalServerError)
+	}
+
+	return deletedResponse()
+}
+
+// handleGameAction takes the turn of the authenticated player in the given game with
+// the action given by the attributes of the resource in the body, and writes the game
+// as it is after the turn, as writeGame would.
+func (handler *Handler) handleGameAction(
+	requestContext context.Context,
+	gameName string,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	playerIdentifier, errorFromAuthentication :=
+		authentication.RequireAuthentication(requestContext)
+	if errorFromAuthentication != nil {
+		return classifiedErrorResponse(errorFromAuthentication, http.StatusUnauthorized)
+	}
+
+	var gameAction parsing.GameActionAttributes
+	statusFromDecode, errorFromDecode :=
+		decodeAttributes(httpBodyDecoder, "actions", &gameAction)
+	if errorFromDecode != nil {
+		return errorResponse(errorFromDecode, statusFromDecode)
+	}
+
+	actionExecutor, errorFromExecutor :=
+		handler.gameCollection.ExecuteAction(requestContext, gameName, playerIdentifier)
+	if errorFromExecutor != nil {
+		return classifiedErrorResponse(errorFromExecutor, http.StatusBadRequest)
+	}
+
+	var errorFromAction error
+	switch gameAction.Kind {
+	case "discard":
+		errorFromAction =
+			actionExecutor.TakeTurnByDiscarding(
+				requestContext,
+				gameAction.ExpectedTurn,
+				gameAction.CardIndex)
+	case "play":
+		errorFromAction =
+			actionExecutor.TakeTurnByPlaying(
+				requestContext,
+				gameAction.ExpectedTurn,
+				gameAction.CardIndex)
+	case "hint-color":
+		errorFromAction =
+			actionExecutor.TakeTurnByHintingColor(
+				requestContext,
+				gameAction.ExpectedTurn,
+				gameAction.ReceiverIdentifier,
+				gameAction.HintedColor)
+	case "hint-number":
+		errorFromAction =
+			actionExecutor.TakeTurnByHintingIndex(
+				requestContext,
+				gameAction.ExpectedTurn,
+				gameAction.ReceiverIdentifier,
+				gameAction.HintedNumber)
+	default:
+		return errorResponse(
+			fmt.Errorf("Action kind %v not valid", gameAction.Kind),
+			http.StatusBadRequest)
+	}
+
+	if errorFromAction != nil {
+		return classifiedErrorResponse(errorFromAction, http.StatusBadRequest)
+	}
+
+	return handler.writeGame(requestContext, gameName)
+}
+
+// handleChatMessage records the chat message given by the attributes of the resource in
+// the body from the authenticated player in the given game, and writes the game as it
+// is after the message, as writeGame would.
+func (handler *Handler) handleChatMessage(
+	requestContext context.Context,
+	gameName string,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	playerIdentifier, errorFromAuthentication :=
+		authentication.RequireAuthentication(requestContext)
+	if errorFromAuthentication != nil {
+		return classifiedErrorResponse(errorFromAuthentication, http.StatusUnauthorized)
+	}
+
+	var chatMessage parsing.ChatMessageAttributes
+	statusFromDecode, errorFromDecode :=
+		decodeAttributes(httpBodyDecoder, "chat-messages", &chatMessage)
+	if errorFromDecode != nil {
+		return errorResponse(errorFromDecode, statusFromDecode)
+	}
+
+	actionExecutor, errorFromExecutor :=
+		handler.gameCollection.ExecuteAction(requestContext, gameName, playerIdentifier)
+	if errorFromExecutor != nil {
+		return classifiedErrorResponse(errorFromExecutor, http.StatusBadRequest)
+	}
+
+	errorFromRecord :=
+		actionExecutor.RecordChatMessage(requestContext, chatMessage.MessageText)
+	if errorFromRecord != nil {
+		return classifiedErrorResponse(errorFromRecord, http.StatusInternalServerError)
+	}
+
+	return handler.writeGame(requestContext, gameName)
+}
+
+// handleLeaveGame removes the given game from the list of games of the given
+// participant, as long as the request comes from that participant.
+func (handler *Handler) handleLeaveGame(
+	requestContext context.Context,
+	gameName string,
+	participantIdentifier string) (interface{}, int) {
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, participantIdentifier)
+	if errorFromAuthorization != nil {
+		return classifiedErrorResponse(errorFromAuthorization, http.StatusBadRequest)
+	}
+
+	errorFromLeaving :=
+		handler.gameCollection.RemoveGameFromListForPlayer(
+			requestContext,
+			gameName,
+			participantIdentifier)
+	if errorFromLeaving != nil {
+		return classifiedErrorResponse(errorFromLeaving, http.StatusInternalServerError)
+	}
+
+	return deletedResponse()
+}
+
+// rulesetResource converts the given ruleset into a resource.
+func rulesetResource(
+	rulesetIdentifier int,
+	gameRuleset game.Ruleset) parsing.ResourceObject {
+	return parsing.ResourceObject{
+		Type: "rulesets",
+		ID:   strconv.Itoa(rulesetIdentifier),
+		Attributes: parsing.RulesetAttributes{
+			Description:            gameRuleset.FrontendDescription(),
+			MinimumNumberOfPlayers: gameRuleset.MinimumNumberOfPlayers(),
+			MaximumNumberOfPlayers: gameRuleset.MaximumNumberOfPlayers(),
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package resource
+
+import (
+	"context"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// GameViewConverter defines what a struct should do to allow a Handler to send games
+// in the same form as the first version of the API, which is implemented by the
+// Handler from github.com/benoleary/ilutulestikud/backend/server/endpoint/game.
+type GameViewConverter interface {
+	// GameViewForFrontend should convert the given view of a game into the form which
+	// is sent to the frontend, for the given viewing player.
+	GameViewForFrontend(
+		requestContext context.Context,
+		gameView game.ViewForPlayer,
+		playerName string) (parsing.GameView, error)
+}
@@ -0,0 +1,433 @@
+package resource_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	player_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/resource"
+)
+
+// This file defines mock implementations of interfaces. The mock collections embed the
+// interfaces which they implement so that only the functions which the Handler uses
+// need to be written out: calling any other function would panic on the nil interface,
+// which is what should happen if the Handler calls something unexpected.
+
+const administratorForTest = "administrator"
+
+// callAsString writes the name of a function and its arguments separated by spaces, so
+// that the calls received by a mock can be compared as strings.
+func callAsString(functionName string, functionArguments []interface{}) string {
+	return strings.TrimSpace(
+		fmt.Sprintln(append([]interface{}{functionName}, functionArguments...)...))
+}
+
+// mockGameView returns a fixed name, version, and position in the turn order.
+type mockGameView struct {
+	game.ViewForPlayer
+	MockGameName        string
+	MockVersion         int
+	MockPlayerTurnIndex int
+}
+
+// GameName gets mocked.
+func (mockView *mockGameView) GameName() string {
+	return mockView.MockGameName
+}
+
+// Version gets mocked.
+func (mockView *mockGameView) Version() int {
+	return mockView.MockVersion
+}
+
+// CurrentTurnOrder gets mocked.
+func (mockView *mockGameView) CurrentTurnOrder() ([]string, int, int) {
+	return nil, mockView.MockPlayerTurnIndex, 0
+}
+
+// mockActionExecutor records the calls to it as strings, and returns ErrorToReturn
+// from every action.
+type mockActionExecutor struct {
+	CallsReceived []string
+	ErrorToReturn error
+}
+
+// RecordChatMessage gets mocked.
+func (mockExecutor *mockActionExecutor) RecordChatMessage(
+	executionContext context.Context,
+	chatMessage string) error {
+	mockExecutor.CallsReceived =
+		append(mockExecutor.CallsReceived, "RecordChatMessage "+chatMessage)
+	return mockExecutor.ErrorToReturn
+}
+
+// TakeTurnByDiscarding gets mocked.
+func (mockExecutor *mockActionExecutor) TakeTurnByDiscarding(
+	executionContext context.Context,
+	expectedTurn int,
+	indexInHand int) error {
+	mockExecutor.CallsReceived =
+		append(
+			mockExecutor.CallsReceived,
+			fmt.Sprintf("TakeTurnByDiscarding %v %v", expectedTurn, indexInHand))
+	return mockExecutor.ErrorToReturn
+}
+
+// TakeTurnByPlaying gets mocked.
+func (mockExecutor *mockActionExecutor) TakeTurnByPlaying(
+	executionContext context.Context,
+	expectedTurn int,
+	indexInHand int) error {
+	mockExecutor.CallsReceived =
+		append(
+			mockExecutor.CallsReceived,
+			fmt.Sprintf("TakeTurnByPlaying %v %v", expectedTurn, indexInHand))
+	return mockExecutor.ErrorToReturn
+}
+
+// TakeTurnByHintingColor gets mocked.
+func (mockExecutor *mockActionExecutor) TakeTurnByHintingColor(
+	executionContext context.Context,
+	expectedTurn int,
+	receivingPlayer string,
+	hintedColor string) error {
+	mockExecutor.CallsReceived =
+		append(
+			mockExecutor.CallsReceived,
+			fmt.Sprintf(
+				"TakeTurnByHintingColor %v %v %v",
+				expectedTurn,
+				receivingPlayer,
+				hintedColor))
+	return mockExecutor.ErrorToReturn
+}
+
+// TakeTurnByHintingIndex gets mocked.
+func (mockExecutor *mockActionExecutor) TakeTurnByHintingIndex(
+	executionContext context.Context,
+	expectedTurn int,
+	receivingPlayer string,
+	hintedIndex int) error {
+	mockExecutor.CallsReceived =
+		append(
+			mockExecutor.CallsReceived,
+			fmt.Sprintf(
+				"TakeTurnByHintingIndex %v %v %v",
+				expectedTurn,
+				receivingPlayer,
+				hintedIndex))
+	return mockExecutor.ErrorToReturn
+}
+
+// mockGameCollection records the calls to it as strings, and returns ErrorToReturn
+// from every function.
+type mockGameCollection struct {
+	game_endpoint.StateCollection
+	CallsReceived      []string
+	ErrorToReturn      error
+	ReturnForView      *mockGameView
+	ReturnForViewAll   []game.ViewForPlayer
+	ReturnForCreator   string
+	ReturnForExecution *mockActionExecutor
+}
+
+func (mockCollection *mockGameCollection) recordCall(
+	functionName string,
+	functionArguments ...interface{}) {
+	mockCollection.CallsReceived =
+		append(
+			mockCollection.CallsReceived,
+			callAsString(functionName, functionArguments))
+}
+
+// ViewState gets mocked.
+func (mockCollection *mockGameCollection) ViewState(
+	executionContext context.Context,
+	gameName string,
+	playerName string) (game.ViewForPlayer, error) {
+	mockCollection.recordCall("ViewState", gameName, playerName)
+	return mockCollection.ReturnForView, mockCollection.ErrorToReturn
+}
+
+// ViewAllWithPlayer gets mocked.
+func (mockCollection *mockGameCollection) ViewAllWithPlayer(
+	executionContext context.Context,
+	playerName string) ([]game.ViewForPlayer, error) {
+	mockCollection.recordCall("ViewAllWithPlayer", playerName)
+	return mockCollection.ReturnForViewAll, mockCollection.ErrorToReturn
+}
+
+// ExecuteAction gets mocked.
+func (mockCollection *mockGameCollection) ExecuteAction(
+	executionContext context.Context,
+	gameName string,
+	playerName string) (game.ExecutorForPlayer, error) {
+	mockCollection.recordCall("ExecuteAction", gameName, playerName)
+	return mockCollection.ReturnForExecution, mockCollection.ErrorToReturn
+}
+
+// InviteToNew gets mocked.
+func (mockCollection *mockGameCollection) InviteToNew(
+	executionContext context.Context,
+	gameName string,
+	gameRuleset game.Ruleset,
+	playerNames []string) error {
+	mockCollection.recordCall("InviteToNew", gameName, playerNames)
+	return mockCollection.ErrorToReturn
+}
+
+// RemoveGameFromListForPlayer gets mocked.
+func (mockCollection *mockGameCollection) RemoveGameFromListForPlayer(
+	executionContext context.Context,
+	gameName string,
+	playerName string) error {
+	mockCollection.recordCall("RemoveGameFromListForPlayer", gameName, playerName)
+	return mockCollection.ErrorToReturn
+}
+
+// Creator gets mocked.
+func (mockCollection *mockGameCollection) Creator(
+	executionContext context.Context,
+	gameName string) (string, error) {
+	mockCollection.recordCall("Creator", gameName)
+	return mockCollection.ReturnForCreator, mockCollection.ErrorToReturn
+}
+
+// Delete gets mocked.
+func (mockCollection *mockGameCollection) Delete(
+	executionContext context.Context,
+	gameName string) error {
+	mockCollection.recordCall("Delete", gameName)
+	return mockCollection.ErrorToReturn
+}
+
+// mockPlayerCollection records the calls to it as strings, and returns ErrorToReturn
+// from every function which can return an error. It treats administratorForTest as
+// the only administrator.
+type mockPlayerCollection struct {
+	player_endpoint.StateCollection
+	CallsReceived       []string
+	ErrorToReturn       error
+	ReturnForSearch     player.SearchResult
+	ReturnForIdentifier string
+}
+
+func (mockCollection *mockPlayerCollection) recordCall(
+	functionName string,
+	functionArguments ...interface{}) {
+	mockCollection.CallsReceived =
+		append(
+			mockCollection.CallsReceived,
+			callAsString(functionName, functionArguments))
+}
+
+// Search gets mocked.
+func (mockCollection *mockPlayerCollection) Search(
+	executionContext context.Context,
+	namePrefix string,
+	nameOrder player.NameOrder,
+	pageCursor string,
+	pageSize int) (player.SearchResult, error) {
+	mockCollection.recordCall(
+		"Search",
+		namePrefix,
+		nameOrder == player.NameDescending,
+		pageCursor,
+		pageSize)
+	return mockCollection.ReturnForSearch, mockCollection.ErrorToReturn
+}
+
+// Get gets mocked, returning a player with the given identifier and a name derived
+// from it.
+func (mockCollection *mockPlayerCollection) Get(
+	executionContext context.Context,
+	playerIdentifier string) (player.ReadonlyState, error) {
+	mockCollection.recordCall("Get", playerIdentifier)
+	playerState := &player.ReadAndWriteState{
+		PlayerIdentifier: playerIdentifier,
+		PlayerName:       "Name of " + playerIdentifier,
+		PlayerRole:       player.RolePlayer,
+	}
+
+	return playerState, mockCollection.ErrorToReturn
+}
+
+// Add gets mocked.
+func (mockCollection *mockPlayerCollection) Add(
+	executionContext context.Context,
+	playerName string,
+	chatColor string,
+	plainPassword string) (string, error) {
+	mockCollection.recordCall("Add", playerName, chatColor, plainPassword)
+	return mockCollection.ReturnForIdentifier, mockCollection.ErrorToReturn
+}
+
+// Authenticate gets mocked.
+func (mockCollection *mockPlayerCollection) Authenticate(
+	executionContext context.Context,
+	playerName string,
+	plainPassword string) (string, error) {
+	mockCollection.recordCall("Authenticate", playerName, plainPassword)
+	return mockCollection.ReturnForIdentifier, mockCollection.ErrorToReturn
+}
+
+// Rename gets mocked.
+func (mockCollection *mockPlayerCollection) Rename(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerName string) error {
+	mockCollection.recordCall("Rename", playerIdentifier, playerName)
+	return mockCollection.ErrorToReturn
+}
+
+// UpdateColor gets mocked.
+func (mockCollection *mockPlayerCollection) UpdateColor(
+	executionContext context.Context,
+	playerIdentifier string,
+	chatColor string) error {
+	mockCollection.recordCall("UpdateColor", playerIdentifier, chatColor)
+	return mockCollection.ErrorToReturn
+}
+
+// UpdateRole gets mocked.
+func (mockCollection *mockPlayerCollection) UpdateRole(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerRole string) error {
+	mockCollection.recordCall("UpdateRole", playerIdentifier, playerRole)
+	return mockCollection.ErrorToReturn
+}
+
+// UpdateProfile gets mocked.
+func (mockCollection *mockPlayerCollection) UpdateProfile(
+	executionContext context.Context,
+	playerIdentifier string,
+	playerProfile player.Profile) error {
+	mockCollection.recordCall("UpdateProfile", playerIdentifier, playerProfile.Timezone)
+	return mockCollection.ErrorToReturn
+}
+
+// Delete gets mocked.
+func (mockCollection *mockPlayerCollection) Delete(
+	executionContext context.Context,
+	playerIdentifier string) error {
+	mockCollection.recordCall("Delete", playerIdentifier)
+	return mockCollection.ErrorToReturn
+}
+
+// Presence gets mocked, and is not recorded as it is only ever read.
+func (mockCollection *mockPlayerCollection) Presence(
+	executionContext context.Context,
+	playerIdentifier string) player.Presence {
+	return player.Presence{Status: player.PresenceOffline}
+}
+
+// IsAdministrator gets mocked, and is not recorded as it is called by the authorizer.
+func (mockCollection *mockPlayerCollection) IsAdministrator(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	return playerIdentifier == administratorForTest, nil
+}
+
+// IsDeleted gets mocked, treating nobody as deleted, and is not recorded as it is
+// called by the authorizer.
+func (mockCollection *mockPlayerCollection) IsDeleted(
+	executionContext context.Context,
+	playerIdentifier string) (bool, error) {
+	return false, nil
+}
+
+// mockTokenIssuer issues tokens which are just the identifier of the player with a
+// prefix.
+type mockTokenIssuer struct {
+}
+
+// IssueToken gets mocked.
+func (mockIssuer *mockTokenIssuer) IssueToken(playerIdentifier string) (string, error) {
+	return "token for " + playerIdentifier, nil
+}
+
+// mockGameViewConverter converts every view into a parsing.GameView which only has
+// the version of the view.
+type mockGameViewConverter struct {
+}
+
+// GameViewForFrontend gets mocked.
+func (mockConverter *mockGameViewConverter) GameViewForFrontend(
+	requestContext context.Context,
+	gameView game.ViewForPlayer,
+	playerName string) (parsing.GameView, error) {
+	return parsing.GameView{Version: gameView.Version()}, nil
+}
+
+// newHandlerForTest returns a Handler around the given mocks, which authorizes
+// requests in the same way as the server does.
+func newHandlerForTest(
+	gameCollection *mockGameCollection,
+	playerCollection *mockPlayerCollection) *resource.Handler {
+	return resource.New(
+		gameCollection,
+		playerCollection,
+		authentication.NewContextAuthorizer(playerCollection),
+		&mockTokenIssuer{},
+		&mockGameViewConverter{})
+}
+
+// contextForPlayer returns a context authenticated as the given player, or without any
+// authenticated player if the given identifier is empty.
+func contextForPlayer(playerIdentifier string) context.Context {
+	if playerIdentifier == "" {
+		return context.Background()
+	}
+
+	return authentication.ContextWithAuthenticatedPlayer(
+		context.Background(),
+		playerIdentifier)
+}
+
+// decoderAroundResource returns a decoder around the envelope of a resource of the
+// given type with the given attributes.
+func decoderAroundResource(
+	unitTest *testing.T,
+	resourceType string,
+	resourceAttributes interface{}) *json.Decoder {
+	encodedAttributes, errorFromAttributes := json.Marshal(resourceAttributes)
+	if errorFromAttributes != nil {
+		unitTest.Fatalf(
+			"encoding attributes %v as JSON generated an error: %v",
+			resourceAttributes,
+			errorFromAttributes)
+	}
+
+	resourceInput := parsing.ResourceInput{
+		Data: parsing.ResourceInputObject{
+			Type:       resourceType,
+			Attributes: encodedAttributes,
+		},
+	}
+
+	bytesBuffer := new(bytes.Buffer)
+	errorFromEncoding := json.NewEncoder(bytesBuffer).Encode(resourceInput)
+	if errorFromEncoding != nil {
+		unitTest.Fatalf(
+			"encoding %v as JSON generated an error: %v",
+			resourceInput,
+			errorFromEncoding)
+	}
+
+	return json.NewDecoder(bytes.NewReader(bytesBuffer.Bytes()))
+}
+
+// emptyDecoder returns a decoder around an empty body.
+func emptyDecoder() *json.Decoder {
+	return json.NewDecoder(strings.NewReader(""))
+}
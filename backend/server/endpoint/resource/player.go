@@ -0,0 +1,331 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/benoleary/ilutulestikud/backend/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	player_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/player"
+)
+
+// handlePlayers responds to requests for the collection of players at /players and
+// for single players at /players/{identifier}.
+func (handler *Handler) handlePlayers(
+	requestContext context.Context,
+	httpMethod string,
+	relevantSegments []string,
+	queryParameters url.Values,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	switch len(relevantSegments) {
+	case 0:
+		switch httpMethod {
+		case http.MethodGet:
+			return handler.writePlayerPage(requestContext, queryParameters)
+		case http.MethodPost:
+			return handler.handleNewPlayer(requestContext, httpBodyDecoder)
+		default:
+			return methodNotAllowed(httpMethod, "players")
+		}
+	case 1:
+		playerIdentifier := relevantSegments[0]
+		switch httpMethod {
+		case http.MethodGet:
+			return handler.writePlayer(requestContext, playerIdentifier, http.StatusOK)
+		case http.MethodPatch:
+			return handler.handlePatchPlayer(requestContext, playerIdentifier, httpBodyDecoder)
+		case http.MethodDelete:
+			return handler.handleDeletePlayer(requestContext, playerIdentifier)
+		default:
+			return methodNotAllowed(httpMethod, "a player")
+		}
+	default:
+		return resourceNotFound(relevantSegments)
+	}
+}
+
+// writePlayerPage writes a page of the players whose names start with the prefix given
+// by the filter[name] query parameter, sorted by the sort parameter ("name" or "-name"),
+// starting after the player encoded in the page[cursor] parameter, with at most the
+// number of players given by the page[size] parameter. If there are more players, the
+// link to the next page is given as the next link of the document.
+func (handler *Handler) writePlayerPage(
+	requestContext context.Context,
+	queryParameters url.Values) (interface{}, int) {
+	var nameOrder player.NameOrder
+	sortOrder := queryParameters.Get("sort")
+	switch sortOrder {
+	case "", "name":
+		nameOrder = player.NameAscending
+	case "-name":
+		nameOrder = player.NameDescending
+	default:
+		return errorResponse(
+			fmt.Errorf("Sort order %v not valid", sortOrder),
+			http.StatusBadRequest)
+	}
+
+	pageSize := 0
+	pageSizeParameter := queryParameters.Get("page[size]")
+	if pageSizeParameter != "" {
+		parsedSize, errorFromSize := strconv.Atoi(pageSizeParameter)
+		if errorFromSize != nil {
+			return errorResponse(
+				fmt.Errorf("Page size %v is not a number", pageSizeParameter),
+				http.StatusBadRequest)
+		}
+
+		pageSize = parsedSize
+	}
+
+	searchResult, errorFromSearch :=
+		handler.playerCollection.Search(
+			requestContext,
+			queryParameters.Get("filter[name]"),
+			nameOrder,
+			queryParameters.Get("page[cursor]"),
+			pageSize)
+
+	if errorFromSearch != nil {
+		return classifiedErrorResponse(errorFromSearch, http.StatusBadRequest)
+	}
+
+	playerResources := make([]parsing.ResourceObject, 0, len(searchResult.Players))
+	for _, playerState := range searchResult.Players {
+		playerResources =
+			append(playerResources, handler.playerResource(requestContext, playerState))
+	}
+
+	resourceDocument := parsing.ResourceDocument{Data: playerResources}
+
+	if searchResult.NextCursor != "" {
+		nextParameters := url.Values{}
+		for parameterName, parameterValues := range queryParameters {
+			nextParameters[parameterName] = parameterValues
+		}
+
+		nextParameters.Set("page[cursor]", searchResult.NextCursor)
+		resourceDocument.Links = &parsing.DocumentLinks{
+			Next: PathPrefix + "/players?" + nextParameters.Encode(),
+		}
+	}
+
+	return resourceDocument, http.StatusOK
+}
+
+// handleNewPlayer registers the player given by the attributes of the resource in the
+// body, and writes the new player along with Created.
+func (handler *Handler) handleNewPlayer(
+	requestContext context.Context,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var newPlayer parsing.NewPlayerDefinition
+	statusFromDecode, errorFromDecode :=
+		decodeAttributes(httpBodyDecoder, "players", &newPlayer)
+	if errorFromDecode != nil {
+		return errorResponse(errorFromDecode, statusFromDecode)
+	}
+
+	playerIdentifier, errorFromAdd :=
+		handler.playerCollection.Add(
+			requestContext,
+			newPlayer.Name,
+			newPlayer.Color,
+			newPlayer.Password)
+
+	if errorFromAdd != nil {
+		return classifiedErrorResponse(errorFromAdd, http.StatusBadRequest)
+	}
+
+	return handler.writePlayer(requestContext, playerIdentifier, http.StatusCreated)
+}
+
+// writePlayer writes the player with the given identifier along with the given status.
+func (handler *Handler) writePlayer(
+	requestContext context.Context,
+	playerIdentifier string,
+	httpStatus int) (interface{}, int) {
+	playerState, errorFromGet := handler.playerCollection.Get(requestContext, playerIdentifier)
+	if errorFromGet != nil {
+		return classifiedErrorResponse(errorFromGet, http.StatusInternalServerError)
+	}
+
+	resourceDocument := parsing.ResourceDocument{
+		Data: handler.playerResource(requestContext, playerState),
+	}
+
+	return resourceDocument, httpStatus
+}
+
+// handlePatchPlayer changes the attributes of the given player which are present in
+// the resource in the body, and writes the updated player. Every change is authorized
+// before any change is made, so that a request is either entirely allowed or has no
+// effect.
+func (handler *Handler) handlePatchPlayer(
+	requestContext context.Context,
+	playerIdentifier string,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	var playerPatch parsing.PlayerPatch
+	statusFromDecode, errorFromDecode :=
+		decodeAttributes(httpBodyDecoder, "players", &playerPatch)
+	if errorFromDecode != nil {
+		return errorResponse(errorFromDecode, statusFromDecode)
+	}
+
+	changesOwnAttributes :=
+		(playerPatch.Name != nil) || (playerPatch.Color != nil) || (playerPatch.Profile != nil)
+
+	if changesOwnAttributes {
+		errorFromAuthorization :=
+			handler.playerAuthorizer.AuthorizeActingPlayer(requestContext, playerIdentifier)
+		if errorFromAuthorization != nil {
+			return classifiedErrorResponse(errorFromAuthorization, http.StatusBadRequest)
+		}
+	}
+
+	if playerPatch.Role != nil {
+		errorFromAuthorization := handler.playerAuthorizer.AuthorizeAdministrator(requestContext)
+		if errorFromAuthorization != nil {
+			return classifiedErrorResponse(errorFromAuthorization, http.StatusBadRequest)
+		}
+	}
+
+	if playerPatch.Name != nil {
+		errorFromRename :=
+			handler.playerCollection.Rename(requestContext, playerIdentifier, *playerPatch.Name)
+		if errorFromRename != nil {
+			return classifiedErrorResponse(errorFromRename, http.StatusBadRequest)
+		}
+	}
+
+	if playerPatch.Color != nil {
+		errorFromColor :=
+			handler.playerCollection.UpdateColor(
+				requestContext,
+				playerIdentifier,
+				*playerPatch.Color)
+		if errorFromColor != nil {
+			return classifiedErrorResponse(errorFromColor, http.StatusBadRequest)
+		}
+	}
+
+	if playerPatch.Profile != nil {
+		errorFromProfile :=
+			handler.playerCollection.UpdateProfile(
+				requestContext,
+				playerIdentifier,
+				player_endpoint.ProfileFromEndpoint(*playerPatch.Profile))
+		if errorFromProfile != nil {
+			return classifiedErrorResponse(errorFromProfile, http.StatusBadRequest)
+		}
+	}
+
+	if playerPatch.Role != nil {
+		errorFromRole :=
+			handler.playerCollection.UpdateRole(
+				requestContext,
+				playerIdentifier,
+				*playerPatch.Role)
+		if errorFromRole != nil {
+			return classifiedErrorResponse(errorFromRole, http.StatusBadRequest)
+		}
+	}
+
+	return handler.writePlayer(requestContext, playerIdentifier, http.StatusOK)
+}
+
+// handleDeletePlayer deletes the given player, as long as the request comes from the
+// player themself or from an administrator.
+func (handler *Handler) handleDeletePlayer(
+	requestContext context.Context,
+	playerIdentifier string) (interface{}, int) {
+	errorFromAuthorization :=
+		handler.playerAuthorizer.AuthorizeActingPlayerOrAdministrator(
+			requestContext,
+			playerIdentifier)
+	if errorFromAuthorization != nil {
+		return classifiedErrorResponse(errorFromAuthorization, http.StatusBadRequest)
+	}
+
+	errorFromDelete := handler.playerCollection.Delete(requestContext, playerIdentifier)
+	if errorFromDelete != nil {
+		return classifiedErrorResponse(errorFromDelete, http.StatusInternalServerError)
+	}
+
+	return deletedResponse()
+}
+
+// handleSessions responds to requests to log in, which create a resource in the
+// collection of sessions at /sessions.
+func (handler *Handler) handleSessions(
+	requestContext context.Context,
+	httpMethod string,
+	relevantSegments []string,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	if len(relevantSegments) > 0 {
+		return resourceNotFound(relevantSegments)
+	}
+
+	if httpMethod != http.MethodPost {
+		return methodNotAllowed(httpMethod, "sessions")
+	}
+
+	var playerCredentials parsing.PlayerCredentials
+	statusFromDecode, errorFromDecode :=
+		decodeAttributes(httpBodyDecoder, "sessions", &playerCredentials)
+	if errorFromDecode != nil {
+		return errorResponse(errorFromDecode, statusFromDecode)
+	}
+
+	playerIdentifier, errorFromAuthentication :=
+		handler.playerCollection.Authenticate(
+			requestContext,
+			playerCredentials.Name,
+			playerCredentials.Password)
+
+	if errorFromAuthentication != nil {
+		return classifiedErrorResponse(errorFromAuthentication, http.StatusUnauthorized)
+	}
+
+	sessionToken, errorFromIssue := handler.sessionTokenIssuer.IssueToken(playerIdentifier)
+	if errorFromIssue != nil {
+		return classifiedErrorResponse(errorFromIssue, http.StatusInternalServerError)
+	}
+
+	resourceDocument := parsing.ResourceDocument{
+		Data: parsing.ResourceObject{
+			Type: "sessions",
+			ID:   playerIdentifier,
+			Attributes: parsing.SessionAttributes{
+				PlayerName: playerCredentials.Name,
+				Token:      sessionToken,
+			},
+		},
+	}
+
+	return resourceDocument, http.StatusCreated
+}
+
+// playerResource converts the given player into a resource, along with the current
+// presence of the player.
+func (handler *Handler) playerResource(
+	requestContext context.Context,
+	playerState player.ReadonlyState) parsing.ResourceObject {
+	playerIdentifier := playerState.Identifier()
+	playerPresence := handler.playerCollection.Presence(requestContext, playerIdentifier)
+
+	return parsing.ResourceObject{
+		Type: "players",
+		ID:   playerIdentifier,
+		Attributes: parsing.PlayerAttributes{
+			Name:     playerState.Name(),
+			Color:    playerState.Color(),
+			Role:     playerState.Role(),
+			Profile:  player_endpoint.ProfileForEndpoint(playerState.Profile()),
+			Presence: playerPresence.Status,
+		},
+	}
+}
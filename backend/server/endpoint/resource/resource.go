@@ -0,0 +1,162 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	player_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/player"
+)
+
+// PathPrefix is the start of the path of every URI handled by a Handler, which is used
+// for the links to further pages of collections.
+const PathPrefix = "/backend/v2"
+
+// Handler is a struct meant to encapsulate all the state co-ordinating interaction
+// with the players and games through the second version of the API, where the path
+// of the URI identifies a resource, the HTTP method identifies what to do with it, and
+// bodies are wrapped in envelopes modelled on JSON:API. It shares the state collections
+// of the handlers of the first version.
+// It implements github.com/benoleary/ilutulestikud/server.httpResourceHandler.
+type Handler struct {
+	gameCollection     game_endpoint.StateCollection
+	playerCollection   player_endpoint.StateCollection
+	playerAuthorizer   player_endpoint.PlayerAuthorizer
+	sessionTokenIssuer player_endpoint.SessionTokenIssuer
+	gameViewConverter  GameViewConverter
+}
+
+// New returns a pointer to a new Handler.
+func New(
+	collectionOfGames game_endpoint.StateCollection,
+	collectionOfPlayers player_endpoint.StateCollection,
+	authorizerForPlayers player_endpoint.PlayerAuthorizer,
+	issuerOfTokens player_endpoint.SessionTokenIssuer,
+	converterOfGameViews GameViewConverter) *Handler {
+	return &Handler{
+		gameCollection:     collectionOfGames,
+		playerCollection:   collectionOfPlayers,
+		playerAuthorizer:   authorizerForPlayers,
+		sessionTokenIssuer: issuerOfTokens,
+		gameViewConverter:  converterOfGameViews,
+	}
+}
+
+// HandleRequest chooses the collection of resources from the first segment and passes
+// the request on to the function for that collection.
+// This implements github.com/benoleary/ilutulestikud/server.httpResourceHandler.
+func (handler *Handler) HandleRequest(
+	requestContext context.Context,
+	httpMethod string,
+	relevantSegments []string,
+	queryParameters url.Values,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	if len(relevantSegments) < 1 {
+		return errorResponse(
+			fmt.Errorf("Not enough segments in URI to determine resource"),
+			http.StatusNotFound)
+	}
+
+	switch relevantSegments[0] {
+	case "players":
+		return handler.handlePlayers(
+			requestContext,
+			httpMethod,
+			relevantSegments[1:],
+			queryParameters,
+			httpBodyDecoder)
+	case "sessions":
+		return handler.handleSessions(
+			requestContext,
+			httpMethod,
+			relevantSegments[1:],
+			httpBodyDecoder)
+	case "rulesets":
+		return handler.handleRulesets(httpMethod, relevantSegments[1:])
+	case "games":
+		return handler.handleGames(
+			requestContext,
+			httpMethod,
+			relevantSegments[1:],
+			httpBodyDecoder)
+	default:
+		return errorResponse(
+			fmt.Errorf("Resource %v not found", relevantSegments[0]),
+			http.StatusNotFound)
+	}
+}
+
+// errorResponse returns the envelope for the given error along with the given status.
+func errorResponse(errorToReport error, httpStatus int) (interface{}, int) {
+	return parsing.ErrorDocumentFor(errorToReport, httpStatus), httpStatus
+}
+
+// classifiedErrorResponse returns the envelope for the given error along with the
+// status which corresponds to its code, or the given fallback status if it has none.
+func classifiedErrorResponse(errorToReport error, fallbackStatus int) (interface{}, int) {
+	return errorResponse(errorToReport, parsing.StatusForError(errorToReport, fallbackStatus))
+}
+
+// methodNotAllowed returns the envelope for the error of a request with the given method
+// to a resource which does not support it.
+func methodNotAllowed(httpMethod string, resourcePath string) (interface{}, int) {
+	return errorResponse(
+		fmt.Errorf("Method %v is not allowed for %v", httpMethod, resourcePath),
+		http.StatusMethodNotAllowed)
+}
+
+// resourceNotFound returns the envelope for the error of a request to a path which does
+// not identify a resource.
+func resourceNotFound(relevantSegments []string) (interface{}, int) {
+	return errorResponse(
+		fmt.Errorf("No resource found for segments %v", relevantSegments),
+		http.StatusNotFound)
+}
+
+// deletedResponse returns the envelope for a resource which has been deleted.
+func deletedResponse() (interface{}, int) {
+	return parsing.ResourceDocument{Data: nil}, http.StatusOK
+}
+
+// decodeAttributes decodes the envelope of the body of a request into the given
+// struct, returning an error along with the appropriate status if the body is not
+// valid JSON, or if the resource in it is not of the given type. The status is OK if
+// there is no error.
+func decodeAttributes(
+	httpBodyDecoder *json.Decoder,
+	expectedType string,
+	resourceAttributes interface{}) (int, error) {
+	var resourceInput parsing.ResourceInput
+
+	errorFromParse := httpBodyDecoder.Decode(&resourceInput)
+	if errorFromParse != nil {
+		return http.StatusBadRequest, fmt.Errorf("Error parsing JSON: %v", errorFromParse)
+	}
+
+	// JSON:API answers a resource of the wrong type with Conflict.
+	if resourceInput.Data.Type != expectedType {
+		errorFromType :=
+			fmt.Errorf(
+				"Resource of type %v given where type %v was expected",
+				resourceInput.Data.Type,
+				expectedType)
+		return http.StatusConflict, errorFromType
+	}
+
+	if len(resourceInput.Data.Attributes) == 0 {
+		return http.StatusBadRequest,
+			fmt.Errorf("Resource of type %v has no attributes", expectedType)
+	}
+
+	errorFromAttributes := json.Unmarshal(resourceInput.Data.Attributes, resourceAttributes)
+	if errorFromAttributes != nil {
+		return http.StatusBadRequest,
+			fmt.Errorf("Error parsing attributes: %v", errorFromAttributes)
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,1067 @@
+package resource_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func assertStatus(
+	unitTest *testing.T,
+	testIdentifier string,
+	actualStatus int,
+	expectedStatus int,
+	returnedBody interface{}) {
+	if actualStatus != expectedStatus {
+		unitTest.Fatalf(
+			testIdentifier+"/returned status %v with body %v instead of expected %v",
+			actualStatus,
+			returnedBody,
+			expectedStatus)
+	}
+}
+
+func assertErrorCode(
+	unitTest *testing.T,
+	testIdentifier string,
+	returnedBody interface{},
+	expectedCode string) {
+	errorDocument, isErrorDocument := returnedBody.(parsing.ErrorDocument)
+	if !isErrorDocument ||
+		(len(errorDocument.Errors) != 1) ||
+		(errorDocument.Errors[0].Code != expectedCode) {
+		unitTest.Fatalf(
+			testIdentifier+"/returned body %v instead of error document with code %v",
+			returnedBody,
+			expectedCode)
+	}
+}
+
+func assertCalls(
+	unitTest *testing.T,
+	testIdentifier string,
+	actualCalls []string,
+	expectedCalls []string) {
+	if !reflect.DeepEqual(actualCalls, expectedCalls) {
+		unitTest.Fatalf(
+			testIdentifier+"/received calls %v instead of expected %v",
+			actualCalls,
+			expectedCalls)
+	}
+}
+
+func singleResource(
+	unitTest *testing.T,
+	testIdentifier string,
+	returnedBody interface{}) parsing.ResourceObject {
+	resourceDocument, isResourceDocument := returnedBody.(parsing.ResourceDocument)
+	if !isResourceDocument {
+		unitTest.Fatalf(
+			testIdentifier+"/returned body %v instead of a resource document",
+			returnedBody)
+	}
+
+	resourceObject, isResourceObject := resourceDocument.Data.(parsing.ResourceObject)
+	if !isResourceObject {
+		unitTest.Fatalf(
+			testIdentifier+"/returned data %v instead of a single resource",
+			resourceDocument.Data)
+	}
+
+	return resourceObject
+}
+
+func TestRejectUnknownResourcesAndMethods(unitTest *testing.T) {
+	testCases := []struct {
+		testName         string
+		httpMethod       string
+		relevantSegments []string
+		expectedStatus   int
+		expectedCode     string
+	}{
+		{
+			testName:         "no segments",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{},
+			expectedStatus:   http.StatusNotFound,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "unknown collection",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"not-a-collection"},
+			expectedStatus:   http.StatusNotFound,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "too many segments for player",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"players", "a", "b"},
+			expectedStatus:   http.StatusNotFound,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "unknown part of game",
+			httpMethod:       http.MethodPost,
+			relevantSegments: []string{"games", "a", "not-a-part"},
+			expectedStatus:   http.StatusNotFound,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "unknown ruleset",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"rulesets", "-1"},
+			expectedStatus:   http.StatusNotFound,
+			expectedCode:     "unknown-ruleset",
+		},
+		{
+			testName:         "DELETE all players",
+			httpMethod:       http.MethodDelete,
+			relevantSegments: []string{"players"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "POST to single player",
+			httpMethod:       http.MethodPost,
+			relevantSegments: []string{"players", "a"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "GET sessions",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"sessions"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "POST rulesets",
+			httpMethod:       http.MethodPost,
+			relevantSegments: []string{"rulesets"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "PATCH game",
+			httpMethod:       http.MethodPatch,
+			relevantSegments: []string{"games", "a"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "GET actions",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"games", "a", "actions"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+		{
+			testName:         "GET participant",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"games", "a", "participants", "b"},
+			expectedStatus:   http.StatusMethodNotAllowed,
+			expectedCode:     "unclassified",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			gameCollection := &mockGameCollection{}
+			playerCollection := &mockPlayerCollection{}
+			testHandler := newHandlerForTest(gameCollection, playerCollection)
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer("a"),
+					testCase.httpMethod,
+					testCase.relevantSegments,
+					url.Values{},
+					emptyDecoder())
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				testCase.expectedStatus,
+				returnedBody)
+			assertErrorCode(unitTest, testCase.testName, returnedBody, testCase.expectedCode)
+			assertCalls(unitTest, testCase.testName, gameCollection.CallsReceived, nil)
+			assertCalls(unitTest, testCase.testName, playerCollection.CallsReceived, nil)
+		})
+	}
+}
+
+func TestPlayerPageWithLinkToNextPage(unitTest *testing.T) {
+	testIdentifier := "GET players"
+	playerCollection := &mockPlayerCollection{
+		ReturnForSearch: player.SearchResult{
+			Players: []player.ReadonlyState{
+				&player.ReadAndWriteState{PlayerIdentifier: "a", PlayerName: "Alice"},
+				&player.ReadAndWriteState{PlayerIdentifier: "b", PlayerName: "Bob"},
+			},
+			NextCursor: "next cursor",
+		},
+	}
+
+	testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+	queryParameters := url.Values{
+		"filter[name]": []string{"prefix"},
+		"sort":         []string{"-name"},
+		"page[size]":   []string{"2"},
+	}
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer(""),
+			http.MethodGet,
+			[]string{"players"},
+			queryParameters,
+			emptyDecoder())
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusOK, returnedBody)
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		playerCollection.CallsReceived,
+		[]string{"Search prefix true  2"})
+
+	resourceDocument := returnedBody.(parsing.ResourceDocument)
+	playerResources := resourceDocument.Data.([]parsing.ResourceObject)
+	if (len(playerResources) != 2) ||
+		(playerResources[1].Type != "players") ||
+		(playerResources[1].ID != "b") ||
+		(playerResources[1].Attributes.(parsing.PlayerAttributes).Name != "Bob") {
+		unitTest.Fatalf(
+			testIdentifier+"/returned players %v not as expected",
+			playerResources)
+	}
+
+	if resourceDocument.Links == nil {
+		unitTest.Fatal(testIdentifier + "/returned no link to next page")
+	}
+
+	nextLink, errorFromParse := url.Parse(resourceDocument.Links.Next)
+	if (errorFromParse != nil) ||
+		(nextLink.Path != "/backend/v2/players") ||
+		(nextLink.Query().Get("page[cursor]") != "next cursor") ||
+		(nextLink.Query().Get("filter[name]") != "prefix") {
+		unitTest.Fatalf(
+			testIdentifier+"/returned link to next page %v (parse error %v) not as expected",
+			resourceDocument.Links.Next,
+			errorFromParse)
+	}
+
+	// The query of the request must not be changed by building the link.
+	if queryParameters.Get("page[cursor]") != "" {
+		unitTest.Fatalf(
+			testIdentifier+"/changed query parameters of request to %v",
+			queryParameters)
+	}
+}
+
+func TestRejectInvalidPlayerQuery(unitTest *testing.T) {
+	testCases := []struct {
+		testName        string
+		queryParameters url.Values
+	}{
+		{
+			testName:        "invalid sort",
+			queryParameters: url.Values{"sort": []string{"color"}},
+		},
+		{
+			testName:        "invalid page size",
+			queryParameters: url.Values{"page[size]": []string{"many"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			playerCollection := &mockPlayerCollection{}
+			testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer(""),
+					http.MethodGet,
+					[]string{"players"},
+					testCase.queryParameters,
+					emptyDecoder())
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				http.StatusBadRequest,
+				returnedBody)
+			assertCalls(unitTest, testCase.testName, playerCollection.CallsReceived, nil)
+		})
+	}
+}
+
+func TestNewPlayerCreated(unitTest *testing.T) {
+	testIdentifier := "POST players"
+	playerCollection := &mockPlayerCollection{ReturnForIdentifier: "new identifier"}
+	testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+	newPlayer := parsing.NewPlayerDefinition{
+		Name:     "New Player",
+		Color:    "green",
+		Password: "secret",
+	}
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer(""),
+			http.MethodPost,
+			[]string{"players"},
+			url.Values{},
+			decoderAroundResource(unitTest, "players", newPlayer))
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusCreated, returnedBody)
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		playerCollection.CallsReceived,
+		[]string{"Add New Player green secret", "Get new identifier"})
+
+	playerResource := singleResource(unitTest, testIdentifier, returnedBody)
+	if (playerResource.Type != "players") || (playerResource.ID != "new identifier") {
+		unitTest.Fatalf(
+			testIdentifier+"/returned resource %v not as expected",
+			playerResource)
+	}
+}
+
+func TestRejectResourceOfWrongType(unitTest *testing.T) {
+	testIdentifier := "POST games as players"
+	playerCollection := &mockPlayerCollection{}
+	testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer(""),
+			http.MethodPost,
+			[]string{"players"},
+			url.Values{},
+			decoderAroundResource(unitTest, "games", parsing.NewPlayerDefinition{}))
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusConflict, returnedBody)
+	assertCalls(unitTest, testIdentifier, playerCollection.CallsReceived, nil)
+}
+
+func TestPatchPlayer(unitTest *testing.T) {
+	newName := "New Name"
+	newColor := "blue"
+	newRole := player.RoleAdministrator
+
+	testCases := []struct {
+		testName       string
+		actingPlayer   string
+		playerPatch    parsing.PlayerPatch
+		expectedStatus int
+		expectedCalls  []string
+	}{
+		{
+			testName:       "name and color by player",
+			actingPlayer:   "a",
+			playerPatch:    parsing.PlayerPatch{Name: &newName, Color: &newColor},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"Rename a New Name", "UpdateColor a blue", "Get a"},
+		},
+		{
+			testName:       "profile by player",
+			actingPlayer:   "a",
+			playerPatch:    parsing.PlayerPatch{Profile: &parsing.PlayerProfile{Timezone: "UTC"}},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"UpdateProfile a UTC", "Get a"},
+		},
+		{
+			testName:       "nothing by anyone",
+			actingPlayer:   "",
+			playerPatch:    parsing.PlayerPatch{},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"Get a"},
+		},
+		{
+			testName:       "name without authentication",
+			actingPlayer:   "",
+			playerPatch:    parsing.PlayerPatch{Name: &newName},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCalls:  nil,
+		},
+		{
+			testName:       "name by other player",
+			actingPlayer:   "b",
+			playerPatch:    parsing.PlayerPatch{Name: &newName},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  nil,
+		},
+		{
+			testName:       "name by administrator",
+			actingPlayer:   administratorForTest,
+			playerPatch:    parsing.PlayerPatch{Name: &newName},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  nil,
+		},
+		{
+			testName:       "role by player",
+			actingPlayer:   "a",
+			playerPatch:    parsing.PlayerPatch{Role: &newRole},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  nil,
+		},
+		{
+			testName:       "name and role by player",
+			actingPlayer:   "a",
+			playerPatch:    parsing.PlayerPatch{Name: &newName, Role: &newRole},
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  nil,
+		},
+		{
+			testName:       "role by administrator",
+			actingPlayer:   administratorForTest,
+			playerPatch:    parsing.PlayerPatch{Role: &newRole},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"UpdateRole a admin", "Get a"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			playerCollection := &mockPlayerCollection{}
+			testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer(testCase.actingPlayer),
+					http.MethodPatch,
+					[]string{"players", "a"},
+					url.Values{},
+					decoderAroundResource(unitTest, "players", testCase.playerPatch))
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				testCase.expectedStatus,
+				returnedBody)
+			assertCalls(
+				unitTest,
+				testCase.testName,
+				playerCollection.CallsReceived,
+				testCase.expectedCalls)
+		})
+	}
+}
+
+func TestDeletePlayer(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		actingPlayer   string
+		expectedStatus int
+		expectedCalls  []string
+	}{
+		{
+			testName:       "by player",
+			actingPlayer:   "a",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"Delete a"},
+		},
+		{
+			testName:       "by administrator",
+			actingPlayer:   administratorForTest,
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"Delete a"},
+		},
+		{
+			testName:       "by other player",
+			actingPlayer:   "b",
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			playerCollection := &mockPlayerCollection{}
+			testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer(testCase.actingPlayer),
+					http.MethodDelete,
+					[]string{"players", "a"},
+					url.Values{},
+					emptyDecoder())
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				testCase.expectedStatus,
+				returnedBody)
+			assertCalls(
+				unitTest,
+				testCase.testName,
+				playerCollection.CallsReceived,
+				testCase.expectedCalls)
+		})
+	}
+}
+
+func TestLogInCreatesSession(unitTest *testing.T) {
+	testIdentifier := "POST sessions"
+	playerCollection := &mockPlayerCollection{ReturnForIdentifier: "a"}
+	testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+	playerCredentials := parsing.PlayerCredentials{Name: "Alice", Password: "secret"}
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer(""),
+			http.MethodPost,
+			[]string{"sessions"},
+			url.Values{},
+			decoderAroundResource(unitTest, "sessions", playerCredentials))
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusCreated, returnedBody)
+
+	sessionResource := singleResource(unitTest, testIdentifier, returnedBody)
+	expectedResource := parsing.ResourceObject{
+		Type: "sessions",
+		ID:   "a",
+		Attributes: parsing.SessionAttributes{
+			PlayerName: "Alice",
+			Token:      "token for a",
+		},
+	}
+
+	if !reflect.DeepEqual(sessionResource, expectedResource) {
+		unitTest.Fatalf(
+			testIdentifier+"/returned resource %v instead of expected %v",
+			sessionResource,
+			expectedResource)
+	}
+}
+
+func TestLogInWithIncorrectCredentials(unitTest *testing.T) {
+	testIdentifier := "POST sessions with incorrect password"
+	playerCollection := &mockPlayerCollection{
+		ErrorToReturn: player.ErrIncorrectCredentials,
+	}
+
+	testHandler := newHandlerForTest(&mockGameCollection{}, playerCollection)
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer(""),
+			http.MethodPost,
+			[]string{"sessions"},
+			url.Values{},
+			decoderAroundResource(unitTest, "sessions", parsing.PlayerCredentials{}))
+
+	assertStatus(
+		unitTest,
+		testIdentifier,
+		returnedStatus,
+		http.StatusUnauthorized,
+		returnedBody)
+	assertErrorCode(unitTest, testIdentifier, returnedBody, "incorrect-credentials")
+}
+
+func TestRulesetsListed(unitTest *testing.T) {
+	testIdentifier := "GET rulesets"
+	testHandler := newHandlerForTest(&mockGameCollection{}, &mockPlayerCollection{})
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer(""),
+			http.MethodGet,
+			[]string{"rulesets"},
+			url.Values{},
+			emptyDecoder())
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusOK, returnedBody)
+
+	rulesetResources :=
+		returnedBody.(parsing.ResourceDocument).Data.([]parsing.ResourceObject)
+	if len(rulesetResources) != len(game.ValidRulesetIdentifiers()) {
+		unitTest.Fatalf(
+			testIdentifier+"/returned rulesets %v instead of one for each of %v",
+			rulesetResources,
+			game.ValidRulesetIdentifiers())
+	}
+}
+
+func TestGamesOfAuthenticatedPlayer(unitTest *testing.T) {
+	testIdentifier := "GET games"
+	gameCollection := &mockGameCollection{
+		ReturnForViewAll: []game.ViewForPlayer{
+			&mockGameView{MockGameName: "first game", MockVersion: 3, MockPlayerTurnIndex: 0},
+			&mockGameView{MockGameName: "second game", MockVersion: 5, MockPlayerTurnIndex: 1},
+		},
+	}
+
+	testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer("a"),
+			http.MethodGet,
+			[]string{"games"},
+			url.Values{},
+			emptyDecoder())
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusOK, returnedBody)
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		gameCollection.CallsReceived,
+		[]string{"ViewAllWithPlayer a"})
+
+	taggedBody := returnedBody.(parsing.TaggedBody)
+	gameResources := taggedBody.Body.(parsing.ResourceDocument).Data.([]parsing.ResourceObject)
+	expectedResources := []parsing.ResourceObject{
+		{
+			Type:       "games",
+			ID:         "first game",
+			Attributes: parsing.GameSummaryAttributes{IsPlayerTurn: true, Version: 3},
+		},
+		{
+			Type:       "games",
+			ID:         "second game",
+			Attributes: parsing.GameSummaryAttributes{IsPlayerTurn: false, Version: 5},
+		},
+	}
+
+	if !reflect.DeepEqual(gameResources, expectedResources) {
+		unitTest.Fatalf(
+			testIdentifier+"/returned games %v instead of expected %v",
+			gameResources,
+			expectedResources)
+	}
+}
+
+func TestGameRequestsWithoutAuthentication(unitTest *testing.T) {
+	testCases := []struct {
+		testName         string
+		httpMethod       string
+		relevantSegments []string
+	}{
+		{
+			testName:         "GET games",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"games"},
+		},
+		{
+			testName:         "POST games",
+			httpMethod:       http.MethodPost,
+			relevantSegments: []string{"games"},
+		},
+		{
+			testName:         "GET game",
+			httpMethod:       http.MethodGet,
+			relevantSegments: []string{"games", "a"},
+		},
+		{
+			testName:         "POST action",
+			httpMethod:       http.MethodPost,
+			relevantSegments: []string{"games", "a", "actions"},
+		},
+		{
+			testName:         "POST chat message",
+			httpMethod:       http.MethodPost,
+			relevantSegments: []string{"games", "a", "chat-messages"},
+		},
+		{
+			testName:         "DELETE participant",
+			httpMethod:       http.MethodDelete,
+			relevantSegments: []string{"games", "a", "participants", "b"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			gameCollection := &mockGameCollection{}
+			testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer(""),
+					testCase.httpMethod,
+					testCase.relevantSegments,
+					url.Values{},
+					emptyDecoder())
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				http.StatusUnauthorized,
+				returnedBody)
+			assertErrorCode(unitTest, testCase.testName, returnedBody, "not-authenticated")
+			assertCalls(unitTest, testCase.testName, gameCollection.CallsReceived, nil)
+		})
+	}
+}
+
+func TestNewGameInvitesHostFirst(unitTest *testing.T) {
+	testIdentifier := "POST games"
+	gameCollection := &mockGameCollection{}
+	testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+	newGame := parsing.NewGameAttributes{
+		Name:              "new game",
+		RulesetIdentifier: game.ValidRulesetIdentifiers()[0],
+		PlayerIdentifiers: []string{"b", "c"},
+	}
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer("a"),
+			http.MethodPost,
+			[]string{"games"},
+			url.Values{},
+			decoderAroundResource(unitTest, "games", newGame))
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusAccepted, returnedBody)
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		gameCollection.CallsReceived,
+		[]string{"InviteToNew new game [a b c]"})
+
+	invitationResource := singleResource(unitTest, testIdentifier, returnedBody)
+	if (invitationResource.Type != "invitations") || (invitationResource.ID != "new game") {
+		unitTest.Fatalf(
+			testIdentifier+"/returned resource %v not as expected",
+			invitationResource)
+	}
+}
+
+func TestGameAsSeenByAuthenticatedPlayer(unitTest *testing.T) {
+	testIdentifier := "GET game"
+	gameCollection := &mockGameCollection{
+		ReturnForView: &mockGameView{MockGameName: "a/b", MockVersion: 7},
+	}
+
+	testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer("a"),
+			http.MethodGet,
+			[]string{"games", "a/b"},
+			url.Values{},
+			emptyDecoder())
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusOK, returnedBody)
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		gameCollection.CallsReceived,
+		[]string{"ViewState a/b a"})
+
+	taggedBody := returnedBody.(parsing.TaggedBody)
+	if !strings.HasPrefix(taggedBody.EntityTag, "7-") {
+		unitTest.Fatalf(
+			testIdentifier+"/returned entity tag %v which does not start with version",
+			taggedBody.EntityTag)
+	}
+
+	gameResource := singleResource(unitTest, testIdentifier, taggedBody.Body)
+	expectedResource := parsing.ResourceObject{
+		Type:       "games",
+		ID:         "a/b",
+		Attributes: parsing.GameView{Version: 7},
+	}
+
+	if !reflect.DeepEqual(gameResource, expectedResource) {
+		unitTest.Fatalf(
+			testIdentifier+"/returned resource %v instead of expected %v",
+			gameResource,
+			expectedResource)
+	}
+}
+
+func TestGameActions(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		gameAction     parsing.GameActionAttributes
+		errorToReturn  error
+		expectedStatus int
+		expectedCalls  []string
+	}{
+		{
+			testName: "discard",
+			gameAction: parsing.GameActionAttributes{
+				Kind:         "discard",
+				ExpectedTurn: 4,
+				CardIndex:    1,
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"TakeTurnByDiscarding 4 1"},
+		},
+		{
+			testName: "play",
+			gameAction: parsing.GameActionAttributes{
+				Kind:         "play",
+				ExpectedTurn: 4,
+				CardIndex:    2,
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"TakeTurnByPlaying 4 2"},
+		},
+		{
+			testName: "hint color",
+			gameAction: parsing.GameActionAttributes{
+				Kind:               "hint-color",
+				ExpectedTurn:       4,
+				ReceiverIdentifier: "b",
+				HintedColor:        "red",
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"TakeTurnByHintingColor 4 b red"},
+		},
+		{
+			testName: "hint number",
+			gameAction: parsing.GameActionAttributes{
+				Kind:               "hint-number",
+				ExpectedTurn:       4,
+				ReceiverIdentifier: "b",
+				HintedNumber:       3,
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"TakeTurnByHintingIndex 4 b 3"},
+		},
+		{
+			testName: "stale turn",
+			gameAction: parsing.GameActionAttributes{
+				Kind:         "discard",
+				ExpectedTurn: 3,
+			},
+			errorToReturn:  game.NewTurnConflictError("g", 3, 4),
+			expectedStatus: http.StatusConflict,
+			expectedCalls:  []string{"TakeTurnByDiscarding 3 0"},
+		},
+		{
+			testName: "out of turn",
+			gameAction: parsing.GameActionAttributes{
+				Kind:         "play",
+				ExpectedTurn: 4,
+			},
+			errorToReturn:  game.ErrNotYourTurn,
+			expectedStatus: http.StatusConflict,
+			expectedCalls:  []string{"TakeTurnByPlaying 4 0"},
+		},
+		{
+			testName: "unknown kind",
+			gameAction: parsing.GameActionAttributes{
+				Kind: "pass",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCalls:  nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			actionExecutor := &mockActionExecutor{ErrorToReturn: testCase.errorToReturn}
+			gameCollection := &mockGameCollection{
+				ReturnForView:      &mockGameView{MockGameName: "g", MockVersion: 5},
+				ReturnForExecution: actionExecutor,
+			}
+
+			testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer("a"),
+					http.MethodPost,
+					[]string{"games", "g", "actions"},
+					url.Values{},
+					decoderAroundResource(unitTest, "actions", testCase.gameAction))
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				testCase.expectedStatus,
+				returnedBody)
+			assertCalls(
+				unitTest,
+				testCase.testName,
+				actionExecutor.CallsReceived,
+				testCase.expectedCalls)
+
+			// A successful action is answered with the game as it is afterwards.
+			if returnedStatus == http.StatusOK {
+				gameResource :=
+					singleResource(
+						unitTest,
+						testCase.testName,
+						returnedBody.(parsing.TaggedBody).Body)
+				if gameResource.Attributes.(parsing.GameView).Version != 5 {
+					unitTest.Fatalf(
+						testCase.testName+"/returned game %v not as expected",
+						gameResource)
+				}
+			}
+		})
+	}
+}
+
+func TestChatMessageRecorded(unitTest *testing.T) {
+	testIdentifier := "POST chat message"
+	actionExecutor := &mockActionExecutor{}
+	gameCollection := &mockGameCollection{
+		ReturnForView:      &mockGameView{MockGameName: "g", MockVersion: 5},
+		ReturnForExecution: actionExecutor,
+	}
+
+	testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+	returnedBody, returnedStatus :=
+		testHandler.HandleRequest(
+			contextForPlayer("a"),
+			http.MethodPost,
+			[]string{"games", "g", "chat-messages"},
+			url.Values{},
+			decoderAroundResource(
+				unitTest,
+				"chat-messages",
+				parsing.ChatMessageAttributes{MessageText: "hello"}))
+
+	assertStatus(unitTest, testIdentifier, returnedStatus, http.StatusOK, returnedBody)
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		gameCollection.CallsReceived,
+		[]string{"ExecuteAction g a", "ViewState g a"})
+	assertCalls(
+		unitTest,
+		testIdentifier,
+		actionExecutor.CallsReceived,
+		[]string{"RecordChatMessage hello"})
+}
+
+func TestLeaveGame(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		actingPlayer   string
+		expectedStatus int
+		expectedCalls  []string
+	}{
+		{
+			testName:       "by participant",
+			actingPlayer:   "a",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"RemoveGameFromListForPlayer g a"},
+		},
+		{
+			testName:       "by other player",
+			actingPlayer:   "b",
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			gameCollection := &mockGameCollection{}
+			testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer(testCase.actingPlayer),
+					http.MethodDelete,
+					[]string{"games", "g", "participants", "a"},
+					url.Values{},
+					emptyDecoder())
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				testCase.expectedStatus,
+				returnedBody)
+			assertCalls(
+				unitTest,
+				testCase.testName,
+				gameCollection.CallsReceived,
+				testCase.expectedCalls)
+		})
+	}
+}
+
+func TestDeleteGame(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		actingPlayer   string
+		expectedStatus int
+		expectedCalls  []string
+	}{
+		{
+			testName:       "by creator",
+			actingPlayer:   "a",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"Creator g", "Delete g"},
+		},
+		{
+			testName:       "by administrator",
+			actingPlayer:   administratorForTest,
+			expectedStatus: http.StatusOK,
+			expectedCalls:  []string{"Creator g", "Delete g"},
+		},
+		{
+			testName:       "by other player",
+			actingPlayer:   "b",
+			expectedStatus: http.StatusForbidden,
+			expectedCalls:  []string{"Creator g"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			gameCollection := &mockGameCollection{ReturnForCreator: "a"}
+			testHandler := newHandlerForTest(gameCollection, &mockPlayerCollection{})
+
+			returnedBody, returnedStatus :=
+				testHandler.HandleRequest(
+					contextForPlayer(testCase.actingPlayer),
+					http.MethodDelete,
+					[]string{"games", "g"},
+					url.Values{},
+					emptyDecoder())
+
+			assertStatus(
+				unitTest,
+				testCase.testName,
+				returnedStatus,
+				testCase.expectedStatus,
+				returnedBody)
+			assertCalls(
+				unitTest,
+				testCase.testName,
+				gameCollection.CallsReceived,
+				testCase.expectedCalls)
+
+			if returnedStatus == http.StatusOK {
+				resourceDocument := returnedBody.(parsing.ResourceDocument)
+				if resourceDocument.Data != nil {
+					unitTest.Fatalf(
+						testCase.testName+"/returned data %v for deleted game",
+						resourceDocument.Data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+func newStateWithTaggedBodyForGame(
+	unitTest *testing.T,
+	entityTag string) *server.State {
+	gameHandler := ErrorEndpointHandler(unitTest)
+	gameHandler.TestErrorForGet = nil
+	gameHandler.TestErrorForPost = nil
+	gameHandler.ReturnInterface =
+		parsing.TaggedBody{
+			EntityTag: entityTag,
+			Body:      parsing.VersionForBody{Version: "tagged"},
+		}
+	gameHandler.ReturnCode = http.StatusOK
+
+	return server.NewWithGivenHandlers(
+		mockContextProvider,
+		[]string{"irrelevant to tests"},
+		"test",
+		nil,
+		nil,
+		nil,
+		ErrorEndpointHandler(unitTest),
+		gameHandler,
+		nil,
+		nil)
+}
+
+func TestTaggedBodyWrittenWithEntityTag(unitTest *testing.T) {
+	serverState := newStateWithTaggedBodyForGame(unitTest, "3-abc")
+
+	responseRecorder := mockGet(serverState, "/backend/game/game-as-seen-by-player/a/b")
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	if responseRecorder.Header().Get("ETag") != "\"3-abc\"" {
+		unitTest.Fatalf(
+			"returned ETag %q instead of expected %q",
+			responseRecorder.Header().Get("ETag"),
+			"\"3-abc\"")
+	}
+
+	expectedBody := "{\"Version\":\"tagged\"}\n"
+	if responseRecorder.Body.String() != expectedBody {
+		unitTest.Fatalf(
+			"returned body %q instead of expected %q",
+			responseRecorder.Body.String(),
+			expectedBody)
+	}
+}
+
+func TestConditionalRequestsForTaggedBody(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		requestMethod  string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{
+			testName:       "GET without condition",
+			requestMethod:  http.MethodGet,
+			ifNoneMatch:    "",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "GET with matching tag",
+			requestMethod:  http.MethodGet,
+			ifNoneMatch:    "\"3-abc\"",
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			testName:       "GET with matching weak tag in list",
+			requestMethod:  http.MethodGet,
+			ifNoneMatch:    "\"2-def\", W/\"3-abc\"",
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			testName:       "GET with wildcard",
+			requestMethod:  http.MethodGet,
+			ifNoneMatch:    "*",
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			testName:       "GET with older tag",
+			requestMethod:  http.MethodGet,
+			ifNoneMatch:    "\"2-def\"",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "GET with unquoted tag",
+			requestMethod:  http.MethodGet,
+			ifNoneMatch:    "3-abc",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "POST with matching tag",
+			requestMethod:  http.MethodPost,
+			ifNoneMatch:    "\"3-abc\"",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			serverState := newStateWithTaggedBodyForGame(unitTest, "3-abc")
+
+			// The body is only read for POST requests.
+			httpRequest :=
+				httptest.NewRequest(
+					testCase.requestMethod,
+					"/backend/game/game-as-seen-by-player/a/b",
+					strings.NewReader("{}"))
+
+			if testCase.ifNoneMatch != "" {
+				httpRequest.Header.Set("If-None-Match", testCase.ifNoneMatch)
+			}
+
+			responseRecorder := mockHandleBackend(serverState, httpRequest)
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				unitTest.Fatalf(
+					"returned wrong status %v instead of expected %v",
+					responseRecorder.Code,
+					testCase.expectedStatus)
+			}
+
+			// The tag is sent even when the body is not, so that the client knows
+			// which version it still has.
+			if responseRecorder.Header().Get("ETag") != "\"3-abc\"" {
+				unitTest.Fatalf(
+					"returned ETag %q instead of expected %q",
+					responseRecorder.Header().Get("ETag"),
+					"\"3-abc\"")
+			}
+
+			isBodyExpected := testCase.expectedStatus != http.StatusNotModified
+			if isBodyExpected != (responseRecorder.Body.Len() > 0) {
+				unitTest.Fatalf(
+					"returned body %q for status %v",
+					responseRecorder.Body.String(),
+					responseRecorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// handleGameUpdates responds to a GET request for a stream of updates to a game with
+// server-sent events, where each update is the JSON of the body which the equivalent
+// request to the game endpoint would have written, sent as the data of an event of the
+// default type. If the stream ends because of an error after any update has been sent,
+// the error is sent as the data of a final event of the type "stream-error", as the
+// status of the response can no longer be changed. A stream which ends because the
+// connection was closed does not end with an event, and the client is expected to
+// reconnect, which the EventSource of browsers does automatically.
+func (state *State) handleGameUpdates(
+	httpResponseWriter http.ResponseWriter,
+	httpRequest *http.Request,
+	relevantSegments []string) {
+	if state.gameUpdateHandler == nil {
+		http.NotFound(httpResponseWriter, httpRequest)
+		return
+	}
+
+	if httpRequest.Method == http.MethodOptions {
+		return
+	}
+
+	if httpRequest.Method != http.MethodGet {
+		http.Error(httpResponseWriter, "Method not GET: "+httpRequest.Method, http.StatusBadRequest)
+		return
+	}
+
+	responseFlusher, isFlusher := httpResponseWriter.(http.Flusher)
+	if !isFlusher {
+		http.Error(httpResponseWriter, "Streaming is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	requestContext, errorFromAuthentication := state.authenticatedContext(httpRequest, true)
+	if errorFromAuthentication != nil {
+		writeResponse(httpResponseWriter, httpRequest, errorFromAuthentication, http.StatusUnauthorized)
+		return
+	}
+
+	streamContext, cancelStream := contextEndingWithRequest(requestContext, httpRequest)
+	defer cancelStream()
+
+	hasSentUpdate := false
+	sendUpdate := func(objectForUpdate interface{}) error {
+		if !hasSentUpdate {
+			httpResponseWriter.Header().Set("Content-Type", "text/event-stream")
+			httpResponseWriter.Header().Set("Cache-Control", "no-cache")
+			httpResponseWriter.WriteHeader(http.StatusOK)
+			hasSentUpdate = true
+		}
+
+		errorFromWrite := writeEvent(httpResponseWriter, "", objectForUpdate)
+		if errorFromWrite != nil {
+			return errorFromWrite
+		}
+
+		responseFlusher.Flush()
+		return nil
+	}
+
+	objectForBody, httpStatus :=
+		state.gameUpdateHandler.HandleStream(streamContext, relevantSegments, sendUpdate)
+
+	if !hasSentUpdate {
+		writeResponse(httpResponseWriter, httpRequest, objectForBody, httpStatus)
+		return
+	}
+
+	// There is no point in trying to tell the client about an error if the client has
+	// gone away.
+	if (httpStatus != http.StatusOK) && (streamContext.Err() == nil) {
+		writeEvent(httpResponseWriter, "stream-error", objectForBody)
+		responseFlusher.Flush()
+	}
+}
+
+// contextEndingWithRequest returns a context derived from the given context which is
+// also done when the given HTTP request is done, which is when the client closes the
+// connection, as the given context may not come from the request. The returned
+// function must be called once the context is no longer needed.
+func contextEndingWithRequest(
+	requestContext context.Context,
+	httpRequest *http.Request) (context.Context, context.CancelFunc) {
+	streamContext, cancelStream := context.WithCancel(requestContext)
+
+	go func() {
+		select {
+		case <-httpRequest.Context().Done():
+			cancelStream()
+		case <-streamContext.Done():
+		}
+	}()
+
+	return streamContext, cancelStream
+}
+
+// writeEvent writes the JSON of the given object as the data of a server-sent event
+// of the given type, or of the default type if the given type is empty, with the entity
+// tag of the object as the identifier of the event if the object has a tag.
+func writeEvent(
+	httpResponseWriter http.ResponseWriter,
+	eventType string,
+	objectForData interface{}) error {
+	// The entity tag of a tagged object is sent as the identifier of the event, which
+	// allows the client to tell whether it has already seen the data.
+	eventIdentifier := ""
+	taggedBody, isTagged := objectForData.(parsing.TaggedBody)
+	if isTagged {
+		objectForData = taggedBody.Body
+		eventIdentifier = taggedBody.EntityTag
+	}
+
+	// The JSON has no line breaks, so it fits in a single data line.
+	jsonForData, errorFromMarshal := json.Marshal(bodyForObject(objectForData))
+	if errorFromMarshal != nil {
+		return errorFromMarshal
+	}
+
+	if eventType != "" {
+		_, errorFromType := fmt.Fprintf(httpResponseWriter, "event: %s\n", eventType)
+		if errorFromType != nil {
+			return errorFromType
+		}
+	}
+
+	if eventIdentifier != "" {
+		_, errorFromIdentifier := fmt.Fprintf(httpResponseWriter, "id: %s\n", eventIdentifier)
+		if errorFromIdentifier != nil {
+			return errorFromIdentifier
+		}
+	}
+
+	_, errorFromData := fmt.Fprintf(httpResponseWriter, "data: %s\n\n", jsonForData)
+	return errorFromData
+}
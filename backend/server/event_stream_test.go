@@ -0,0 +1,329 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+type mockStreamHandler struct {
+	UpdatesToSend    []interface{}
+	ReturnInterface  interface{}
+	ReturnCode       int
+	ReceivedContext  context.Context
+	ReceivedSegments []string
+}
+
+// HandleStream sends all the updates which it has been given and then returns
+// straight away.
+// This implements github.com/benoleary/ilutulestikud/server.httpEventStreamHandler.
+func (mockHandler *mockStreamHandler) HandleStream(
+	requestContext context.Context,
+	relevantSegments []string,
+	sendUpdate func(interface{}) error) (interface{}, int) {
+	mockHandler.ReceivedContext = requestContext
+	mockHandler.ReceivedSegments = relevantSegments
+
+	for _, updateToSend := range mockHandler.UpdatesToSend {
+		errorFromSend := sendUpdate(updateToSend)
+		if errorFromSend != nil {
+			return errorFromSend, http.StatusInternalServerError
+		}
+	}
+
+	return mockHandler.ReturnInterface, mockHandler.ReturnCode
+}
+
+func newStateWithStreamHandler(
+	unitTest *testing.T,
+	streamHandler *mockStreamHandler,
+	tokenSigner server.SessionTokenSigner) *server.State {
+	return server.NewWithGivenHandlers(
+		mockContextProvider,
+		[]string{"irrelevant to tests"},
+		"test",
+		nil,
+		tokenSigner,
+		nil,
+		ErrorEndpointHandler(unitTest),
+		ErrorEndpointHandler(unitTest),
+		streamHandler,
+		nil)
+}
+
+func TestGameUpdatesNotFoundWithoutHandler(unitTest *testing.T) {
+	serverState :=
+		server.NewWithGivenHandlers(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			nil,
+			nil,
+			nil,
+			ErrorEndpointHandler(unitTest),
+			ErrorEndpointHandler(unitTest),
+			nil,
+			nil)
+
+	responseRecorder :=
+		mockGet(serverState, "/backend/game-updates/game-as-seen-by-player/a/b")
+
+	if responseRecorder.Code != http.StatusNotFound {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusNotFound)
+	}
+}
+
+func TestGameUpdatesRejectPost(unitTest *testing.T) {
+	streamHandler := &mockStreamHandler{}
+	serverState := newStateWithStreamHandler(unitTest, streamHandler, nil)
+
+	responseRecorder, encodingError :=
+		mockPost(serverState, "/backend/game-updates/game-as-seen-by-player/a/b", "{}")
+
+	assertResponseIsCorrect(
+		unitTest,
+		"POST game-updates",
+		responseRecorder,
+		encodingError,
+		http.StatusBadRequest)
+
+	if streamHandler.ReceivedSegments != nil {
+		unitTest.Fatalf(
+			"stream handler unexpectedly called with segments %v",
+			streamHandler.ReceivedSegments)
+	}
+}
+
+func TestGameUpdatesSentAsEvents(unitTest *testing.T) {
+	streamHandler := &mockStreamHandler{
+		UpdatesToSend: []interface{}{
+			parsing.VersionForBody{Version: "first"},
+			parsing.VersionForBody{Version: "second"},
+		},
+		ReturnInterface: nil,
+		ReturnCode:      http.StatusOK,
+	}
+
+	serverState := newStateWithStreamHandler(unitTest, streamHandler, nil)
+
+	responseRecorder :=
+		mockGet(serverState, "/backend/game-updates/game-as-seen-by-player/a/b")
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	contentType := responseRecorder.Header().Get("Content-Type")
+	if contentType != "text/event-stream" {
+		unitTest.Fatalf("returned Content-Type %v instead of text/event-stream", contentType)
+	}
+
+	expectedSegments := []string{"game-as-seen-by-player", "a", "b"}
+	if strings.Join(streamHandler.ReceivedSegments, "/") != strings.Join(expectedSegments, "/") {
+		unitTest.Fatalf(
+			"stream handler received segments %v instead of expected %v",
+			streamHandler.ReceivedSegments,
+			expectedSegments)
+	}
+
+	expectedBody :=
+		"data: {\"Version\":\"first\"}\n\n" +
+			"data: {\"Version\":\"second\"}\n\n"
+
+	if responseRecorder.Body.String() != expectedBody {
+		unitTest.Fatalf(
+			"returned body %q instead of expected %q",
+			responseRecorder.Body.String(),
+			expectedBody)
+	}
+}
+
+func TestGameUpdatesSendEntityTagsAsEventIdentifiers(unitTest *testing.T) {
+	streamHandler := &mockStreamHandler{
+		UpdatesToSend: []interface{}{
+			parsing.TaggedBody{
+				EntityTag: "1-abc",
+				Body:      parsing.VersionForBody{Version: "first"},
+			},
+		},
+		ReturnInterface: nil,
+		ReturnCode:      http.StatusOK,
+	}
+
+	serverState := newStateWithStreamHandler(unitTest, streamHandler, nil)
+
+	responseRecorder :=
+		mockGet(serverState, "/backend/game-updates/game-as-seen-by-player/a/b")
+
+	expectedBody := "id: 1-abc\ndata: {\"Version\":\"first\"}\n\n"
+
+	if responseRecorder.Body.String() != expectedBody {
+		unitTest.Fatalf(
+			"returned body %q instead of expected %q",
+			responseRecorder.Body.String(),
+			expectedBody)
+	}
+}
+
+func TestGameUpdatesErrorBeforeAnyUpdateWrittenAsResponse(unitTest *testing.T) {
+	expectedError := fmt.Errorf("expected error")
+	streamHandler := &mockStreamHandler{
+		ReturnInterface: expectedError,
+		ReturnCode:      http.StatusBadRequest,
+	}
+
+	serverState := newStateWithStreamHandler(unitTest, streamHandler, nil)
+
+	responseRecorder :=
+		mockGet(serverState, "/backend/game-updates/game-as-seen-by-player/a/b")
+
+	if responseRecorder.Code != http.StatusBadRequest {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusBadRequest)
+	}
+
+	var errorForBody parsing.ErrorForBody
+	errorFromUnmarshall :=
+		json.Unmarshal(responseRecorder.Body.Bytes(), &errorForBody)
+
+	if (errorFromUnmarshall != nil) || (errorForBody.Error != expectedError.Error()) {
+		unitTest.Fatalf(
+			"response body %v (unmarshalling error %v) did not have expected Error %v",
+			responseRecorder.Body,
+			errorFromUnmarshall,
+			expectedError)
+	}
+}
+
+func TestGameUpdatesErrorAfterUpdateSentAsEvent(unitTest *testing.T) {
+	streamHandler := &mockStreamHandler{
+		UpdatesToSend:   []interface{}{parsing.VersionForBody{Version: "first"}},
+		ReturnInterface: fmt.Errorf("game deleted"),
+		ReturnCode:      http.StatusInternalServerError,
+	}
+
+	serverState := newStateWithStreamHandler(unitTest, streamHandler, nil)
+
+	responseRecorder :=
+		mockGet(serverState, "/backend/game-updates/game-as-seen-by-player/a/b")
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	expectedBody :=
+		"data: {\"Version\":\"first\"}\n\n" +
+			"event: stream-error\ndata: {\"Error\":\"game deleted\",\"Code\":\"unclassified\"}\n\n"
+
+	if responseRecorder.Body.String() != expectedBody {
+		unitTest.Fatalf(
+			"returned body %q instead of expected %q",
+			responseRecorder.Body.String(),
+			expectedBody)
+	}
+}
+
+func TestGameUpdatesAcceptSessionTokenFromQuery(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+
+	expectedPlayer := "Test Player"
+	sessionToken, errorFromIssue := tokenSigner.IssueToken(expectedPlayer)
+	if errorFromIssue != nil {
+		unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+	}
+
+	streamHandler := &mockStreamHandler{
+		ReturnInterface: nil,
+		ReturnCode:      http.StatusOK,
+	}
+
+	serverState := newStateWithStreamHandler(unitTest, streamHandler, tokenSigner)
+
+	httpRequest :=
+		httptest.NewRequest(
+			http.MethodGet,
+			"/backend/game-updates/game-as-seen-by-player/a/b?access_token="+sessionToken,
+			nil)
+
+	responseRecorder := httptest.NewRecorder()
+	serverState.HandleBackend(responseRecorder, httpRequest)
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	actualPlayer, isAuthenticated :=
+		authentication.AuthenticatedPlayer(streamHandler.ReceivedContext)
+
+	if !isAuthenticated || (actualPlayer != expectedPlayer) {
+		unitTest.Fatalf(
+			"handler received context with player %v (authenticated: %v), expected %v",
+			actualPlayer,
+			isAuthenticated,
+			expectedPlayer)
+	}
+}
+
+func TestSessionTokenFromQueryIgnoredForOtherRequests(unitTest *testing.T) {
+	tokenSigner :=
+		authentication.NewTokenSigner([]byte("test signing key"), time.Hour)
+
+	sessionToken, errorFromIssue := tokenSigner.IssueToken("Test Player")
+	if errorFromIssue != nil {
+		unitTest.Fatalf("IssueToken(...) produced unexpected error %v", errorFromIssue)
+	}
+
+	testHandler := ErrorEndpointHandler(unitTest)
+	testHandler.TestErrorForGet = nil
+	testHandler.ReturnCode = http.StatusOK
+
+	serverState :=
+		server.NewWithGivenHandlers(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			nil,
+			tokenSigner,
+			nil,
+			testHandler,
+			ErrorEndpointHandler(unitTest),
+			nil,
+			nil)
+
+	responseRecorder := mockGet(serverState, "/backend/player?access_token="+sessionToken)
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	if _, isAuthenticated := authentication.AuthenticatedPlayer(testHandler.ReceivedContext); isAuthenticated {
+		unitTest.Fatalf("handler received authenticated context from token in query")
+	}
+}
@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/cloud"
+)
+
+// CloudDatastoreKeyKind denotes the kind for the entities which will store the
+// responses to state-changing requests in the Google Cloud Datastore.
+const CloudDatastoreKeyKind = "IdempotentResponses"
+
+// serializableResponse holds a stored response in the form in which it is put into
+// the Cloud Datastore. Only the time of storage is indexed, so that expired responses
+// could be found by a query.
+type serializableResponse struct {
+	RequestFingerprint string `datastore:",noindex"`
+	HTTPStatus         int    `datastore:",noindex"`
+	EntityTag          string `datastore:",noindex"`
+	EncodedBody        []byte `datastore:",noindex"`
+	TimeOfStorage      time.Time
+}
+
+// inCloudDatastorePersister stores responses in Google Cloud Datastore, keyed by
+// their idempotency keys, so that every instance of the server can replay them.
+type inCloudDatastorePersister struct {
+	clientProvider  cloud.DatastoreClientProvider
+	datastoreClient cloud.LimitedClient
+	retentionPeriod time.Duration
+}
+
+// NewInCloudDatastore creates a persister which keeps responses in the Cloud Datastore
+// for the given retention period.
+func NewInCloudDatastore(
+	clientProvider cloud.DatastoreClientProvider,
+	retentionPeriod time.Duration) Persister {
+	return &inCloudDatastorePersister{
+		clientProvider:  clientProvider,
+		datastoreClient: nil,
+		retentionPeriod: retentionPeriod,
+	}
+}
+
+// ReadResponse returns the response stored for the given key if it was stored within
+// the retention period before the given time. A response which has expired is deleted.
+func (inCloudDatastore *inCloudDatastorePersister) ReadResponse(
+	executionContext context.Context,
+	idempotencyKey string,
+	currentTime time.Time) (StoredResponse, bool, error) {
+	initializedClient, errorFromAcquiral :=
+		inCloudDatastore.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return StoredResponse{}, false, errorFromAcquiral
+	}
+
+	isStored, errorFromCheck :=
+		cloud.DoesNameExist(executionContext, initializedClient, idempotencyKey)
+
+	if errorFromCheck != nil || !isStored {
+		return StoredResponse{}, false, errorFromCheck
+	}
+
+	var retrievedResponse serializableResponse
+	errorFromGet :=
+		initializedClient.Get(executionContext, idempotencyKey, &retrievedResponse)
+
+	if errorFromGet != nil {
+		return StoredResponse{}, false, errorFromGet
+	}
+
+	storedResponse := StoredResponse{
+		RequestFingerprint: retrievedResponse.RequestFingerprint,
+		HTTPStatus:         retrievedResponse.HTTPStatus,
+		EntityTag:          retrievedResponse.EntityTag,
+		EncodedBody:        retrievedResponse.EncodedBody,
+		TimeOfStorage:      retrievedResponse.TimeOfStorage,
+	}
+
+	if hasExpired(storedResponse, inCloudDatastore.retentionPeriod, currentTime) {
+		errorFromDelete := initializedClient.Delete(executionContext, idempotencyKey)
+		return StoredResponse{}, false, errorFromDelete
+	}
+
+	return storedResponse, true, nil
+}
+
+// StoreResponse puts the given response into the Cloud Datastore for the given key,
+// replacing any response already stored for the key.
+func (inCloudDatastore *inCloudDatastorePersister) StoreResponse(
+	executionContext context.Context,
+	idempotencyKey string,
+	responseToStore StoredResponse) error {
+	initializedClient, errorFromAcquiral :=
+		inCloudDatastore.acquireClient(executionContext)
+
+	if errorFromAcquiral != nil {
+		return errorFromAcquiral
+	}
+
+	responseToPut := serializableResponse{
+		RequestFingerprint: responseToStore.RequestFingerprint,
+		HTTPStatus:         responseToStore.HTTPStatus,
+		EntityTag:          responseToStore.EntityTag,
+		EncodedBody:        responseToStore.EncodedBody,
+		TimeOfStorage:      responseToStore.TimeOfStorage,
+	}
+
+	return initializedClient.Put(executionContext, idempotencyKey, &responseToPut)
+}
+
+// acquireClient returns the connection to the Cloud Datastore,
+// initializing it if it has not already been initialized.
+func (inCloudDatastore *inCloudDatastorePersister) acquireClient(
+	executionContext context.Context) (cloud.LimitedClient, error) {
+	if inCloudDatastore.datastoreClient == nil {
+		cloudDatastoreClient, errorFromCloudDatastore :=
+			inCloudDatastore.clientProvider.NewClient(executionContext)
+		if errorFromCloudDatastore != nil {
+			return nil, errorFromCloudDatastore
+		}
+
+		inCloudDatastore.datastoreClient = cloudDatastoreClient
+	}
+
+	return inCloudDatastore.datastoreClient, nil
+}
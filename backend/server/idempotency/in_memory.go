@@ -0,0 +1,73 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// inMemoryPersister stores responses mapped to by their idempotency keys. It ignores
+// all context structs passed to its functions.
+type inMemoryPersister struct {
+	mutualExclusion sync.Mutex
+	retentionPeriod time.Duration
+	responsesByKey  map[string]StoredResponse
+}
+
+// NewInMemory creates a persister which keeps responses in a map for the given
+// retention period.
+func NewInMemory(retentionPeriod time.Duration) Persister {
+	return &inMemoryPersister{
+		mutualExclusion: sync.Mutex{},
+		retentionPeriod: retentionPeriod,
+		responsesByKey:  make(map[string]StoredResponse, 0),
+	}
+}
+
+// ReadResponse returns the response stored for the given key if it was stored within
+// the retention period before the given time. Responses which have expired are
+// removed from the map. The context is ignored.
+func (inMemory *inMemoryPersister) ReadResponse(
+	executionContext context.Context,
+	idempotencyKey string,
+	currentTime time.Time) (StoredResponse, bool, error) {
+	inMemory.mutualExclusion.Lock()
+	defer inMemory.mutualExclusion.Unlock()
+
+	for storedKey, storedResponse := range inMemory.responsesByKey {
+		if hasExpired(storedResponse, inMemory.retentionPeriod, currentTime) {
+			delete(inMemory.responsesByKey, storedKey)
+		}
+	}
+
+	storedResponse, isStored := inMemory.responsesByKey[idempotencyKey]
+
+	return storedResponse, isStored, nil
+}
+
+// StoreResponse stores the given response for the given key, with its own copy of the
+// encoded body. The context is ignored.
+func (inMemory *inMemoryPersister) StoreResponse(
+	executionContext context.Context,
+	idempotencyKey string,
+	responseToStore StoredResponse) error {
+	inMemory.mutualExclusion.Lock()
+	defer inMemory.mutualExclusion.Unlock()
+
+	copiedBody := make([]byte, len(responseToStore.EncodedBody))
+	copy(copiedBody, responseToStore.EncodedBody)
+	responseToStore.EncodedBody = copiedBody
+
+	inMemory.responsesByKey[idempotencyKey] = responseToStore
+
+	return nil
+}
+
+// hasExpired returns true if the given response was stored longer than the given
+// retention period before the given time.
+func hasExpired(
+	storedResponse StoredResponse,
+	retentionPeriod time.Duration,
+	currentTime time.Time) bool {
+	return currentTime.Sub(storedResponse.TimeOfStorage) > retentionPeriod
+}
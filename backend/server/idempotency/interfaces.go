@@ -0,0 +1,37 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// StoredResponse holds what is needed to write the response to a state-changing
+// request again, along with what is needed to recognize whether a repeated request
+// really is the same request.
+type StoredResponse struct {
+	RequestFingerprint string
+	HTTPStatus         int
+	EntityTag          string
+	EncodedBody        []byte
+	TimeOfStorage      time.Time
+}
+
+// Persister defines the interface for structs which should be able to store the
+// responses to state-changing requests by the keys which the clients sent with the
+// requests, for a retention period which is set when the persister is created.
+type Persister interface {
+	// ReadResponse should return the response stored for the given key along with
+	// true, if there is one which was stored within the retention period before the
+	// given time, and otherwise an empty response along with false.
+	ReadResponse(
+		executionContext context.Context,
+		idempotencyKey string,
+		currentTime time.Time) (StoredResponse, bool, error)
+
+	// StoreResponse should store the given response for the given key, replacing
+	// any response already stored for the key.
+	StoreResponse(
+		executionContext context.Context,
+		idempotencyKey string,
+		responseToStore StoredResponse) error
+}
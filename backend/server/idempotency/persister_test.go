@@ -0,0 +1,141 @@
+package idempotency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server/idempotency"
+)
+
+func TestReadResponseWithinRetentionPeriod(unitTest *testing.T) {
+	retentionPeriod := time.Hour
+	timeOfStorage := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	testKey := "test key"
+
+	testCases := []struct {
+		testName         string
+		keyToRead        string
+		timeOfRead       time.Time
+		expectedIsStored bool
+	}{
+		{
+			testName:         "unknown key",
+			keyToRead:        "unknown key",
+			timeOfRead:       timeOfStorage,
+			expectedIsStored: false,
+		},
+		{
+			testName:         "immediately after storage",
+			keyToRead:        testKey,
+			timeOfRead:       timeOfStorage,
+			expectedIsStored: true,
+		},
+		{
+			testName:         "at end of retention period",
+			keyToRead:        testKey,
+			timeOfRead:       timeOfStorage.Add(retentionPeriod),
+			expectedIsStored: true,
+		},
+		{
+			testName:         "after retention period",
+			keyToRead:        testKey,
+			timeOfRead:       timeOfStorage.Add(retentionPeriod + time.Second),
+			expectedIsStored: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			testPersister := idempotency.NewInMemory(retentionPeriod)
+
+			originalBody := []byte("{\"Test\":\"body\"}\n")
+			responseToStore := idempotency.StoredResponse{
+				RequestFingerprint: "test fingerprint",
+				HTTPStatus:         201,
+				EntityTag:          "test tag",
+				EncodedBody:        originalBody,
+				TimeOfStorage:      timeOfStorage,
+			}
+
+			errorFromStore :=
+				testPersister.StoreResponse(context.Background(), testKey, responseToStore)
+
+			if errorFromStore != nil {
+				unitTest.Fatalf("StoreResponse(...) produced error %v", errorFromStore)
+			}
+
+			// Changes to the original body should not affect the stored response.
+			originalBody[0] = 'X'
+
+			storedResponse, isStored, errorFromRead :=
+				testPersister.ReadResponse(
+					context.Background(),
+					testCase.keyToRead,
+					testCase.timeOfRead)
+
+			if errorFromRead != nil {
+				unitTest.Fatalf("ReadResponse(...) produced error %v", errorFromRead)
+			}
+
+			if isStored != testCase.expectedIsStored {
+				unitTest.Fatalf(
+					"ReadResponse(...) returned %v for whether the response is stored, expected %v",
+					isStored,
+					testCase.expectedIsStored)
+			}
+
+			if !isStored {
+				return
+			}
+
+			if (storedResponse.RequestFingerprint != responseToStore.RequestFingerprint) ||
+				(storedResponse.HTTPStatus != responseToStore.HTTPStatus) ||
+				(storedResponse.EntityTag != responseToStore.EntityTag) ||
+				(string(storedResponse.EncodedBody) != "{\"Test\":\"body\"}\n") ||
+				!storedResponse.TimeOfStorage.Equal(timeOfStorage) {
+				unitTest.Fatalf(
+					"ReadResponse(...) returned %+v, expected %+v with the original body",
+					storedResponse,
+					responseToStore)
+			}
+		})
+	}
+}
+
+func TestStoreResponseReplacesExpiredResponse(unitTest *testing.T) {
+	retentionPeriod := time.Minute
+	timeOfFirstStorage := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	timeOfSecondStorage := timeOfFirstStorage.Add(time.Hour)
+	testKey := "test key"
+	testPersister := idempotency.NewInMemory(retentionPeriod)
+
+	for _, timeOfStorage := range []time.Time{timeOfFirstStorage, timeOfSecondStorage} {
+		errorFromStore :=
+			testPersister.StoreResponse(
+				context.Background(),
+				testKey,
+				idempotency.StoredResponse{TimeOfStorage: timeOfStorage})
+
+		if errorFromStore != nil {
+			unitTest.Fatalf("StoreResponse(...) produced error %v", errorFromStore)
+		}
+	}
+
+	storedResponse, isStored, errorFromRead :=
+		testPersister.ReadResponse(context.Background(), testKey, timeOfSecondStorage)
+
+	if errorFromRead != nil || !isStored {
+		unitTest.Fatalf(
+			"ReadResponse(...) returned %v for whether the response is stored, with error %v",
+			isStored,
+			errorFromRead)
+	}
+
+	if !storedResponse.TimeOfStorage.Equal(timeOfSecondStorage) {
+		unitTest.Fatalf(
+			"ReadResponse(...) returned response stored at %v, expected %v",
+			storedResponse.TimeOfStorage,
+			timeOfSecondStorage)
+	}
+}
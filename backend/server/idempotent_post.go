@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	"github.com/benoleary/ilutulestikud/backend/server/idempotency"
+)
+
+// idempotencyKeyHeader is the header in which a client may send a key of its choice
+// with a POST request, so that if it has to repeat the request, the server replays the
+// response to the first request rather than performing its actions again.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// replayedResponseHeader is the header which is set to "true" when the response is a
+// replay of the response to an earlier request with the same idempotency key.
+const replayedResponseHeader = "Idempotent-Replayed"
+
+// maximumIdempotencyKeyLength is the number of bytes allowed in an idempotency key,
+// which is plenty for a UUID or similar.
+const maximumIdempotencyKeyLength = 255
+
+// handleIdempotentPost handles a POST request which came with the given idempotency
+// key. If a response to a request with the same key, from the same player, to the same
+// path, has been stored, it is written again without performing the request, as long
+// as the request has the same body. Otherwise the given function performs the request
+// and its response is stored before being written, unless it is a server error, in
+// which case the client may try again with the same key. Errors from handling the key
+// are wrapped by the given function in the form used by the version of the API to which
+// the request was made.
+func (state *State) handleIdempotentPost(
+	httpResponseWriter http.ResponseWriter,
+	httpRequest *http.Request,
+	requestContext context.Context,
+	performPost func(httpBodyDecoder *json.Decoder) (interface{}, int),
+	wrapError func(errorFromServer error, httpStatus int) interface{},
+	idempotencyKey string) {
+	if len(idempotencyKey) > maximumIdempotencyKeyLength {
+		errorFromKey :=
+			fmt.Errorf(
+				"Idempotency key must not be longer than %v bytes",
+				maximumIdempotencyKeyLength)
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			wrapError(errorFromKey, http.StatusBadRequest),
+			http.StatusBadRequest)
+		return
+	}
+
+	// The body has to be read completely so that it can be compared to the body of any
+	// earlier request with the same key.
+	requestBody, errorFromRead := ioutil.ReadAll(httpRequest.Body)
+	if errorFromRead != nil {
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			wrapError(errorFromRead, http.StatusBadRequest),
+			http.StatusBadRequest)
+		return
+	}
+
+	scopedKey :=
+		scopedIdempotencyKey(requestContext, httpRequest.URL.Path, idempotencyKey)
+	requestFingerprint := fingerprintOf(requestBody)
+
+	if !state.claimIdempotencyKey(scopedKey) {
+		errorFromClaim :=
+			fmt.Errorf(
+				"A request with idempotency key %v is still being processed",
+				idempotencyKey)
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			wrapError(errorFromClaim, http.StatusConflict),
+			http.StatusConflict)
+		return
+	}
+
+	defer state.releaseIdempotencyKey(scopedKey)
+
+	storedResponse, isStored, errorFromPersister :=
+		state.idempotencyPersister.ReadResponse(requestContext, scopedKey, time.Now())
+
+	if errorFromPersister != nil {
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			wrapError(errorFromPersister, http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if isStored {
+		if storedResponse.RequestFingerprint != requestFingerprint {
+			errorFromFingerprint :=
+				fmt.Errorf(
+					"Idempotency key %v was already used for a different request",
+					idempotencyKey)
+			writeResponse(
+				httpResponseWriter,
+				httpRequest,
+				wrapError(errorFromFingerprint, http.StatusUnprocessableEntity),
+				http.StatusUnprocessableEntity)
+			return
+		}
+
+		httpResponseWriter.Header().Set(replayedResponseHeader, "true")
+		writeStoredResponse(httpResponseWriter, storedResponse)
+		return
+	}
+
+	objectForBody, httpStatus := performPost(json.NewDecoder(bytes.NewReader(requestBody)))
+
+	responseToStore :=
+		storedResponseFor(requestFingerprint, objectForBody, httpStatus, time.Now())
+
+	// The actions of the request have already been performed, so the response is
+	// written even if it could not be stored, as there is nothing better to tell the
+	// client.
+	if httpStatus < http.StatusInternalServerError {
+		state.idempotencyPersister.StoreResponse(requestContext, scopedKey, responseToStore)
+	}
+
+	writeStoredResponse(httpResponseWriter, responseToStore)
+}
+
+// claimIdempotencyKey marks the given key as belonging to a request which is being
+// processed, returning false if it was already marked, so that a request which is
+// repeated before the first attempt has finished is not performed twice by this
+// instance of the server.
+func (state *State) claimIdempotencyKey(scopedKey string) bool {
+	state.idempotencyMutualExclusion.Lock()
+	defer state.idempotencyMutualExclusion.Unlock()
+
+	if state.idempotencyKeysInProgress[scopedKey] {
+		return false
+	}
+
+	state.idempotencyKeysInProgress[scopedKey] = true
+	return true
+}
+
+// releaseIdempotencyKey removes the mark set by claimIdempotencyKey.
+func (state *State) releaseIdempotencyKey(scopedKey string) {
+	state.idempotencyMutualExclusion.Lock()
+	defer state.idempotencyMutualExclusion.Unlock()
+
+	delete(state.idempotencyKeysInProgress, scopedKey)
+}
+
+// scopedIdempotencyKey combines the given key with the authenticated player and the
+// path of the request, so that different players, or the same player for different
+// endpoints, cannot receive each other's responses by choosing the same key. The
+// combination is hashed so that it can be used as the name of a Datastore key.
+func scopedIdempotencyKey(
+	requestContext context.Context,
+	requestPath string,
+	idempotencyKey string) string {
+	authenticatedPlayer, _ := authentication.AuthenticatedPlayer(requestContext)
+	keyHash := sha256.New()
+	keyHash.Write([]byte(authenticatedPlayer))
+	keyHash.Write([]byte{0})
+	keyHash.Write([]byte(requestPath))
+	keyHash.Write([]byte{0})
+	keyHash.Write([]byte(idempotencyKey))
+
+	return hex.EncodeToString(keyHash.Sum(nil))
+}
+
+// fingerprintOf returns a hash of the given request body.
+func fingerprintOf(requestBody []byte) string {
+	bodyHash := sha256.Sum256(requestBody)
+	return hex.EncodeToString(bodyHash[:])
+}
+
+// storedResponseFor encodes the given object as the body of a response in the same way
+// as writeResponse does, and returns the response in the form in which it is stored.
+func storedResponseFor(
+	requestFingerprint string,
+	objectForBody interface{},
+	httpStatus int,
+	timeOfStorage time.Time) idempotency.StoredResponse {
+	entityTag := ""
+	taggedBody, isTagged := objectForBody.(parsing.TaggedBody)
+	if isTagged {
+		objectForBody = taggedBody.Body
+		entityTag = taggedBody.EntityTag
+	}
+
+	bytesBuffer := new(bytes.Buffer)
+	json.NewEncoder(bytesBuffer).Encode(bodyForObject(objectForBody))
+
+	return idempotency.StoredResponse{
+		RequestFingerprint: requestFingerprint,
+		HTTPStatus:         httpStatus,
+		EntityTag:          entityTag,
+		EncodedBody:        bytesBuffer.Bytes(),
+		TimeOfStorage:      timeOfStorage,
+	}
+}
+
+// writeStoredResponse writes the given response, with its entity tag as the ETag
+// header if it has one.
+func writeStoredResponse(
+	httpResponseWriter http.ResponseWriter,
+	storedResponse idempotency.StoredResponse) {
+	if storedResponse.EntityTag != "" {
+		httpResponseWriter.Header().Set("ETag", strconv.Quote(storedResponse.EntityTag))
+	}
+
+	httpResponseWriter.WriteHeader(storedResponse.HTTPStatus)
+	httpResponseWriter.Write(storedResponse.EncodedBody)
+}
@@ -0,0 +1,207 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/idempotency"
+)
+
+func newStateWithIdempotentGameHandler(
+	unitTest *testing.T) (*server.State, *mockEndpointHandler) {
+	gameHandler := ErrorEndpointHandler(unitTest)
+	gameHandler.TestErrorForPost = nil
+	gameHandler.ReturnInterface = "success"
+	gameHandler.ReturnCode = http.StatusOK
+
+	serverState :=
+		server.NewWithGivenHandlers(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			nil,
+			nil,
+			idempotency.NewInMemory(time.Hour),
+			ErrorEndpointHandler(unitTest),
+			gameHandler,
+			nil,
+			nil)
+
+	return serverState, gameHandler
+}
+
+func postWithIdempotencyKey(
+	serverState *server.State,
+	requestPath string,
+	requestBody string,
+	idempotencyKey string) *httptest.ResponseRecorder {
+	httpRequest :=
+		httptest.NewRequest(
+			http.MethodPost,
+			requestPath,
+			strings.NewReader(requestBody))
+
+	if idempotencyKey != "" {
+		httpRequest.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	return mockHandleBackend(serverState, httpRequest)
+}
+
+func TestRepeatedPostWithIdempotencyKeyReplaysResponse(unitTest *testing.T) {
+	serverState, gameHandler := newStateWithIdempotentGameHandler(unitTest)
+	requestPath := "/backend/game/record-chat-message"
+	requestBody := "{\"ChatMessage\":\"hello\"}"
+
+	firstResponse :=
+		postWithIdempotencyKey(serverState, requestPath, requestBody, "test key")
+	assertResponseIsCorrect(unitTest, "first POST", firstResponse, nil, http.StatusOK)
+
+	if firstResponse.Header().Get("Idempotent-Replayed") != "" {
+		unitTest.Fatalf("first POST was marked as replayed")
+	}
+
+	// The handler now returns something else, which should not be seen by the
+	// repeated request.
+	gameHandler.ReturnInterface = "not your turn"
+	gameHandler.ReturnCode = http.StatusBadRequest
+
+	repeatedResponse :=
+		postWithIdempotencyKey(serverState, requestPath, requestBody, "test key")
+	assertResponseIsCorrect(unitTest, "repeated POST", repeatedResponse, nil, http.StatusOK)
+
+	if repeatedResponse.Header().Get("Idempotent-Replayed") != "true" {
+		unitTest.Fatalf("repeated POST was not marked as replayed")
+	}
+
+	if repeatedResponse.Body.String() != firstResponse.Body.String() {
+		unitTest.Fatalf(
+			"repeated POST returned body %q instead of %q",
+			repeatedResponse.Body.String(),
+			firstResponse.Body.String())
+	}
+
+	if gameHandler.NumberOfPosts != 1 {
+		unitTest.Fatalf(
+			"handler received %v POST requests instead of 1",
+			gameHandler.NumberOfPosts)
+	}
+}
+
+func TestIdempotencyKeyOnlyAppliesToSameRequest(unitTest *testing.T) {
+	testCases := []struct {
+		testName              string
+		secondPath            string
+		secondBody            string
+		secondKey             string
+		expectedStatus        int
+		expectedNumberOfPosts int
+	}{
+		{
+			testName:              "same key with different body",
+			secondPath:            "/backend/game/record-chat-message",
+			secondBody:            "{\"ChatMessage\":\"goodbye\"}",
+			secondKey:             "test key",
+			expectedStatus:        http.StatusUnprocessableEntity,
+			expectedNumberOfPosts: 1,
+		},
+		{
+			testName:              "same key for different path",
+			secondPath:            "/backend/game/take-turn-by-discarding",
+			secondBody:            "{\"ChatMessage\":\"hello\"}",
+			secondKey:             "test key",
+			expectedStatus:        http.StatusOK,
+			expectedNumberOfPosts: 2,
+		},
+		{
+			testName:              "different key",
+			secondPath:            "/backend/game/record-chat-message",
+			secondBody:            "{\"ChatMessage\":\"hello\"}",
+			secondKey:             "other key",
+			expectedStatus:        http.StatusOK,
+			expectedNumberOfPosts: 2,
+		},
+		{
+			testName:              "no key",
+			secondPath:            "/backend/game/record-chat-message",
+			secondBody:            "{\"ChatMessage\":\"hello\"}",
+			secondKey:             "",
+			expectedStatus:        http.StatusOK,
+			expectedNumberOfPosts: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			serverState, gameHandler := newStateWithIdempotentGameHandler(unitTest)
+
+			firstResponse :=
+				postWithIdempotencyKey(
+					serverState,
+					"/backend/game/record-chat-message",
+					"{\"ChatMessage\":\"hello\"}",
+					"test key")
+			assertResponseIsCorrect(
+				unitTest,
+				testCase.testName+"/first POST",
+				firstResponse,
+				nil,
+				http.StatusOK)
+
+			secondResponse :=
+				postWithIdempotencyKey(
+					serverState,
+					testCase.secondPath,
+					testCase.secondBody,
+					testCase.secondKey)
+			assertResponseIsCorrect(
+				unitTest,
+				testCase.testName+"/second POST",
+				secondResponse,
+				nil,
+				testCase.expectedStatus)
+
+			if secondResponse.Header().Get("Idempotent-Replayed") != "" {
+				unitTest.Fatalf("second POST was marked as replayed")
+			}
+
+			if gameHandler.NumberOfPosts != testCase.expectedNumberOfPosts {
+				unitTest.Fatalf(
+					"handler received %v POST requests instead of %v",
+					gameHandler.NumberOfPosts,
+					testCase.expectedNumberOfPosts)
+			}
+		})
+	}
+}
+
+func TestServerErrorNotStoredForIdempotencyKey(unitTest *testing.T) {
+	serverState, gameHandler := newStateWithIdempotentGameHandler(unitTest)
+	gameHandler.ReturnCode = http.StatusInternalServerError
+
+	for attemptIndex := 1; attemptIndex <= 2; attemptIndex++ {
+		attemptResponse :=
+			postWithIdempotencyKey(
+				serverState,
+				"/backend/game/record-chat-message",
+				"{}",
+				"test key")
+		assertResponseIsCorrect(
+			unitTest,
+			"POST with server error",
+			attemptResponse,
+			nil,
+			http.StatusInternalServerError)
+
+		if gameHandler.NumberOfPosts != attemptIndex {
+			unitTest.Fatalf(
+				"handler received %v POST requests instead of %v",
+				gameHandler.NumberOfPosts,
+				attemptIndex)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"net/url"
 )
 
 type httpGetAndPostHandler interface {
@@ -19,3 +20,39 @@ type httpGetAndPostHandler interface {
 		httpBodyDecoder *json.Decoder,
 		relevantSegments []string) (interface{}, int)
 }
+
+type httpEventStreamHandler interface {
+	// HandleStream should send the body of each update for the given HTTP GET request
+	// through the given function until the context is done, and then return the body
+	// for the request along with the HTTP response code, which are only written as the
+	// response if no update was sent.
+	HandleStream(
+		requestContext context.Context,
+		relevantSegments []string,
+		sendUpdate func(interface{}) error) (interface{}, int)
+}
+
+type httpResourceHandler interface {
+	// HandleRequest should perform the relevant actions for the given HTTP method on
+	// the resource identified by the given segments, which have already been unescaped,
+	// and return the body for the request along with the HTTP response code. Errors
+	// should be returned in the body already wrapped in the form in which they are sent.
+	HandleRequest(
+		requestContext context.Context,
+		httpMethod string,
+		relevantSegments []string,
+		queryParameters url.Values,
+		httpBodyDecoder *json.Decoder) (interface{}, int)
+}
+
+// SessionTokenSigner defines what a struct should do to allow the server to issue
+// session tokens to players who log in and to identify the player from the token
+// which comes with each later request.
+type SessionTokenSigner interface {
+	// IssueToken should return a signed token identifying the given player.
+	IssueToken(playerIdentifier string) (string, error)
+
+	// PlayerFromToken should return the identifier of the player identified by the given
+	// token, or an error if the token is not valid.
+	PlayerFromToken(sessionToken string) (string, error)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/benoleary/ilutulestikud/backend/failure"
 	"github.com/benoleary/ilutulestikud/backend/server"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
 )
@@ -20,6 +21,8 @@ type mockEndpointHandler struct {
 	TestErrorForPost error
 	ReturnInterface  interface{}
 	ReturnCode       int
+	ReceivedContext  context.Context
+	NumberOfPosts    int
 }
 
 func ErrorEndpointHandler(unitTest *testing.T) *mockEndpointHandler {
@@ -44,6 +47,8 @@ func (mockHandler *mockEndpointHandler) HandleGet(
 			mockHandler.TestErrorForGet)
 	}
 
+	mockHandler.ReceivedContext = requestContext
+
 	return mockHandler.ReturnInterface, mockHandler.ReturnCode
 }
 
@@ -61,6 +66,8 @@ func (mockHandler *mockEndpointHandler) HandlePost(
 			mockHandler.TestErrorForPost)
 	}
 
+	mockHandler.NumberOfPosts++
+
 	return mockHandler.ReturnInterface, mockHandler.ReturnCode
 }
 
@@ -79,10 +86,12 @@ func TestVersionReturnedCorrectly(unitTest *testing.T) {
 	serverState :=
 		server.New(
 			mockContextProvider,
-			"irrelevant to tests",
+			[]string{"irrelevant to tests"},
 			expectedVersion,
 			nil,
 			nil,
+			nil,
+			nil,
 			nil)
 
 	// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
@@ -208,7 +217,7 @@ func TestRejectInvalidRequestsBeforeCallingHandler(unitTest *testing.T) {
 			// endpoints which are not covered by requests which would get validly redirected
 			// to either of the endpoint handlers.
 			serverState :=
-				server.New(mockContextProvider, "irrelevant to tests", "test", nil, nil, nil)
+				server.New(mockContextProvider, []string{"irrelevant to tests"}, "test", nil, nil, nil, nil, nil)
 
 			// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
 			responseRecorder := httptest.NewRecorder()
@@ -284,11 +293,15 @@ func TestSelectCorrectHandlerForValidRequest(unitTest *testing.T) {
 			serverState :=
 				server.NewWithGivenHandlers(
 					mockContextProvider,
-					"irrelevant to tests",
+					[]string{"irrelevant to tests"},
 					"test",
 					nil,
+					nil,
+					nil,
 					testCase.playerHandler,
-					testCase.gameHandler)
+					testCase.gameHandler,
+					nil,
+					nil)
 
 			// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
 			responseRecorder := httptest.NewRecorder()
@@ -321,10 +334,14 @@ func TestWrapReturnedError(unitTest *testing.T) {
 	serverState :=
 		server.NewWithGivenHandlers(
 			mockContextProvider,
-			"irrelevant to tests",
+			[]string{"irrelevant to tests"},
 			"test",
 			nil,
+			nil,
+			nil,
 			testHandler,
+			nil,
+			nil,
 			nil)
 
 	// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
@@ -353,4 +370,42 @@ func TestWrapReturnedError(unitTest *testing.T) {
 			responseRecorder.Body,
 			expectedError)
 	}
+
+	if errorForBody.Code != failure.CodeUnclassified {
+		unitTest.Errorf(
+			"response body %v did not have expected Code %v",
+			responseRecorder.Body,
+			failure.CodeUnclassified)
+	}
+}
+
+func TestWrapReturnedErrorWithCode(unitTest *testing.T) {
+	testSentinel := failure.NewSentinel("test-code", "test message")
+	testHandler := ErrorEndpointHandler(unitTest)
+	testHandler.TestErrorForGet = nil
+	testHandler.ReturnInterface = testSentinel.Errorf("expected %v", "error")
+	testHandler.ReturnCode = http.StatusConflict
+
+	serverState :=
+		server.NewWithGivenHandlers(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			nil,
+			nil,
+			nil,
+			testHandler,
+			nil,
+			nil,
+			nil)
+
+	responseRecorder := mockGet(serverState, "/backend/player")
+
+	expectedBody := "{\"Error\":\"expected error\",\"Code\":\"test-code\"}\n"
+	if responseRecorder.Body.String() != expectedBody {
+		unitTest.Fatalf(
+			"returned body %q instead of expected %q",
+			responseRecorder.Body.String(),
+			expectedBody)
+	}
 }
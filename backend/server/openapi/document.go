@@ -0,0 +1,276 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document is the top-level object of an OpenAPI 3 description of the backend. Only
+// the parts of the specification which are needed to describe this backend are
+// represented.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info holds the title of the API and the version of the backend which serves it.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps the lower-case names of the HTTP methods which are allowed for a path
+// to the operations which they perform.
+type PathItem map[string]*Operation
+
+// Operation describes what a single HTTP method does for a path.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a parameter of an operation, which is either a segment of the
+// path or a parameter of the query.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the JSON body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response, where Content is empty if the response has no body.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body of a given media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas which are referred to by name from the rest of the
+// document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of the OpenAPI 3 schema object which is needed to describe the
+// types of the parsing package. A schema with neither a reference nor a type allows
+// any value.
+type Schema struct {
+	Reference            string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const (
+	// DocumentPath is the path at which the backend serves the document.
+	DocumentPath = "/backend/openapi.json"
+
+	jsonMediaType        = "application/json"
+	eventStreamMediaType = "text/event-stream"
+	componentPrefix      = "#/components/schemas/"
+)
+
+// New creates the description of every route in FirstVersionRoutes and ResourceRoutes,
+// with the schemas of the bodies derived from the types of the parsing package, for
+// the given version of the backend.
+func New(backendVersion string) Document {
+	generatorForSchemas := newSchemaGenerator()
+	describedPaths := make(map[string]PathItem, 0)
+
+	addOperation := func(httpMethod string, routePath string, routeOperation *Operation) {
+		pathItem, isKnown := describedPaths[routePath]
+		if !isKnown {
+			pathItem = make(PathItem, 0)
+			describedPaths[routePath] = pathItem
+		}
+
+		pathItem[strings.ToLower(httpMethod)] = routeOperation
+	}
+
+	firstVersionError := generatorForSchemas.schemaFor(errorForFirstVersion)
+	for _, firstVersionRoute := range FirstVersionRoutes() {
+		routeOperation := &Operation{
+			OperationID: firstVersionRoute.OperationID,
+			Summary:     firstVersionRoute.Summary,
+			Deprecated:  firstVersionRoute.IsDeprecated,
+			Parameters:  pathParameters(firstVersionRoute.Path, firstVersionParameterDescription),
+			Responses: map[string]Response{
+				"default": jsonResponse(
+					"The request failed, usually with a message and code as ErrorForBody",
+					firstVersionError),
+			},
+		}
+
+		if firstVersionRoute.RequestBody != nil {
+			routeOperation.RequestBody =
+				jsonRequestBody(generatorForSchemas.schemaFor(firstVersionRoute.RequestBody))
+		}
+
+		successfulResponse :=
+			jsonResponse("Success", generatorForSchemas.schemaFor(firstVersionRoute.ResponseBody))
+		if firstVersionRoute.IsEventStream {
+			successfulResponse = Response{
+				Description: "A stream of server-sent events, each with the JSON as its data",
+				Content: map[string]MediaType{
+					eventStreamMediaType: {
+						Schema: generatorForSchemas.schemaFor(firstVersionRoute.ResponseBody),
+					},
+				},
+			}
+		}
+
+		routeOperation.Responses[strconv.Itoa(firstVersionRoute.SuccessStatus)] = successfulResponse
+		addOperation(firstVersionRoute.Method, firstVersionRoute.Path, routeOperation)
+	}
+
+	secondVersionError := generatorForSchemas.schemaFor(errorForSecondVersion)
+	for _, resourceRoute := range ResourceRoutes() {
+		routeOperation := &Operation{
+			OperationID: resourceRoute.OperationID,
+			Summary:     resourceRoute.Summary,
+			Parameters:  pathParameters(resourceRoute.Path, ""),
+			Responses: map[string]Response{
+				"default": jsonResponse("The request failed", secondVersionError),
+			},
+		}
+
+		routeOperation.Parameters =
+			append(routeOperation.Parameters, resourceRoute.QueryParameters...)
+
+		if resourceRoute.RequestAttributes != nil {
+			routeOperation.RequestBody =
+				jsonRequestBody(
+					generatorForSchemas.resourceInputSchema(
+						resourceRoute.RequestType,
+						resourceRoute.RequestAttributes))
+		}
+
+		routeOperation.Responses[strconv.Itoa(resourceRoute.SuccessStatus)] =
+			jsonResponse(
+				"Success",
+				generatorForSchemas.resourceDocumentSchema(
+					resourceRoute.ResponseAttributes,
+					resourceRoute.IsCollection))
+		addOperation(resourceRoute.Method, resourceRoute.Path, routeOperation)
+	}
+
+	return Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   "Ilutulestikud backend",
+			Version: backendVersion,
+		},
+		Paths: describedPaths,
+		Components: Components{
+			Schemas: generatorForSchemas.namedSchemas,
+		},
+	}
+}
+
+// Operations returns the operations of the document in a consistent order, along with
+// the HTTP method and path of each, so that they can be checked against the handlers.
+func (document Document) Operations() []MethodAndPath {
+	methodsAndPaths := make([]MethodAndPath, 0)
+	for routePath, pathItem := range document.Paths {
+		for lowerCaseMethod := range pathItem {
+			methodsAndPaths =
+				append(
+					methodsAndPaths,
+					MethodAndPath{Method: strings.ToUpper(lowerCaseMethod), Path: routePath})
+		}
+	}
+
+	sort.Slice(methodsAndPaths, func(firstIndex int, secondIndex int) bool {
+		if methodsAndPaths[firstIndex].Path != methodsAndPaths[secondIndex].Path {
+			return methodsAndPaths[firstIndex].Path < methodsAndPaths[secondIndex].Path
+		}
+
+		return methodsAndPaths[firstIndex].Method < methodsAndPaths[secondIndex].Method
+	})
+
+	return methodsAndPaths
+}
+
+// Resolve returns the schema to which the given schema refers, or the given schema
+// itself if it does not refer to a named schema.
+func (document Document) Resolve(givenSchema *Schema) *Schema {
+	for (givenSchema != nil) && (givenSchema.Reference != "") {
+		givenSchema =
+			document.Components.Schemas[strings.TrimPrefix(givenSchema.Reference, componentPrefix)]
+	}
+
+	return givenSchema
+}
+
+// MethodAndPath identifies an operation of the document.
+type MethodAndPath struct {
+	Method string
+	Path   string
+}
+
+// jsonResponse describes a response with a JSON body with the given schema.
+func jsonResponse(responseDescription string, bodySchema *Schema) Response {
+	return Response{
+		Description: responseDescription,
+		Content: map[string]MediaType{
+			jsonMediaType: {Schema: bodySchema},
+		},
+	}
+}
+
+// jsonRequestBody describes a required JSON body with the given schema.
+func jsonRequestBody(bodySchema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			jsonMediaType: {Schema: bodySchema},
+		},
+	}
+}
+
+// pathParameters describes each segment of the given path which is a parameter, given
+// as a name in braces, as a required string with the given description.
+func pathParameters(routePath string, parameterDescription string) []Parameter {
+	describedParameters := make([]Parameter, 0)
+	for _, pathSegment := range strings.Split(routePath, "/") {
+		if !strings.HasPrefix(pathSegment, "{") || !strings.HasSuffix(pathSegment, "}") {
+			continue
+		}
+
+		describedParameters =
+			append(
+				describedParameters,
+				Parameter{
+					Name:        strings.Trim(pathSegment, "{}"),
+					In:          "path",
+					Description: parameterDescription,
+					Required:    true,
+					Schema:      &Schema{Type: "string"},
+				})
+	}
+
+	return describedParameters
+}
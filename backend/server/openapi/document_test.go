@@ -0,0 +1,546 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/defaults"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	game_persister "github.com/benoleary/ilutulestikud/backend/game/persister"
+	"github.com/benoleary/ilutulestikud/backend/player"
+	player_persister "github.com/benoleary/ilutulestikud/backend/player/persister"
+	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	"github.com/benoleary/ilutulestikud/backend/server/openapi"
+)
+
+const testVersion = "test version"
+
+// testBackend holds a server built around in-memory persisters with a single registered
+// player who has logged in, so that requests can be made on behalf of that player.
+type testBackend struct {
+	serverState      *server.State
+	playerIdentifier string
+	sessionToken     string
+}
+
+func newTestBackend(unitTest *testing.T) testBackend {
+	gamePersister := game_persister.NewInMemory()
+	playerCollection :=
+		player.NewCollection(
+			player_persister.NewInMemory(),
+			defaults.AvailableColors(),
+			defaults.ChatBackgroundColor(),
+			nil,
+			player.DeletionLeavesTombstone,
+			game.NewParticipationChecker(gamePersister))
+	gameCollection :=
+		game.NewCollection(
+			gamePersister,
+			game_persister.NewLobbyInMemory(),
+			game_persister.NewSeriesInMemory(),
+			game_persister.NewSpectatorInMemory(),
+			game_persister.NewHistoryInMemory(),
+			8,
+			playerCollection,
+			playerCollection)
+
+	serverState :=
+		server.New(
+			&server.BackgroundContextProvider{},
+			nil,
+			testVersion,
+			&parsing.Base32Translator{},
+			authentication.NewTokenSigner([]byte("test key"), time.Hour),
+			nil,
+			playerCollection,
+			gameCollection)
+
+	newPlayer := parsing.NewPlayerDefinition{
+		Name:     "Test Player",
+		Color:    defaults.AvailableColors()[0],
+		Password: "test password",
+	}
+
+	registrationRecorder :=
+		sendRequest(serverState, http.MethodPost, "/backend/player/new-player", newPlayer, "")
+	if registrationRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"could not register test player: status %v, body %v",
+			registrationRecorder.Code,
+			registrationRecorder.Body)
+	}
+
+	credentials := parsing.PlayerCredentials{
+		Name:     newPlayer.Name,
+		Password: newPlayer.Password,
+	}
+
+	logInRecorder :=
+		sendRequest(serverState, http.MethodPost, "/backend/player/log-in", credentials, "")
+
+	var sessionToken parsing.SessionToken
+	errorFromUnmarshal := json.Unmarshal(logInRecorder.Body.Bytes(), &sessionToken)
+	if (logInRecorder.Code != http.StatusOK) || (errorFromUnmarshal != nil) {
+		unitTest.Fatalf(
+			"could not log in test player: status %v, body %v, unmarshalling error %v",
+			logInRecorder.Code,
+			logInRecorder.Body,
+			errorFromUnmarshal)
+	}
+
+	return testBackend{
+		serverState:      serverState,
+		playerIdentifier: sessionToken.PlayerIdentifier,
+		sessionToken:     sessionToken.Token,
+	}
+}
+
+// sendRequest sends a request with the given object as its JSON body, unless the object
+// is nil, and with the given session token, unless it is empty.
+func sendRequest(
+	serverState *server.State,
+	httpMethod string,
+	requestAddress string,
+	objectForBody interface{},
+	sessionToken string) *httptest.ResponseRecorder {
+	var httpRequest *http.Request
+	if objectForBody == nil {
+		httpRequest = httptest.NewRequest(httpMethod, requestAddress, nil)
+	} else {
+		bytesBuffer := new(bytes.Buffer)
+		json.NewEncoder(bytesBuffer).Encode(objectForBody)
+		httpRequest = httptest.NewRequest(httpMethod, requestAddress, bytesBuffer)
+	}
+
+	if sessionToken != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+sessionToken)
+	}
+
+	// Streams end when their requests are done, so a request for a stream which is
+	// already done gets at most the first update.
+	if strings.HasPrefix(requestAddress, "/backend/game-updates/") {
+		cancelledContext, cancelRequest := context.WithCancel(httpRequest.Context())
+		cancelRequest()
+		httpRequest = httpRequest.WithContext(cancelledContext)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	serverState.HandleBackend(responseRecorder, httpRequest)
+
+	return responseRecorder
+}
+
+// addressFor replaces the parameters in the given path with the test player, a game
+// which does not exist, or a valid ruleset, encoded as the version of the API requires.
+func (backend testBackend) addressFor(documentedPath string) string {
+	encodeSegment := (&parsing.Base32Translator{}).ToSegment
+	if strings.HasPrefix(documentedPath, "/backend/v2/") {
+		encodeSegment = url.PathEscape
+	}
+
+	parameterReplacer :=
+		strings.NewReplacer(
+			"{playerIdentifier}", encodeSegment(backend.playerIdentifier),
+			"{gameName}", encodeSegment("test game"),
+			"{rulesetIdentifier}", strconv.Itoa(game.ValidRulesetIdentifiers()[0]))
+
+	return parameterReplacer.Replace(documentedPath)
+}
+
+// requestBodyFor returns an empty object for a request to the first version of the API
+// and an envelope around empty attributes of the documented type for a request to the
+// second version, or nil if the operation has no documented body.
+func requestBodyFor(
+	document openapi.Document,
+	documentedOperation *openapi.Operation) interface{} {
+	if documentedOperation.RequestBody == nil {
+		return nil
+	}
+
+	bodySchema :=
+		document.Resolve(documentedOperation.RequestBody.Content["application/json"].Schema)
+	dataSchema, isEnvelope := bodySchema.Properties["data"]
+	if !isEnvelope {
+		return map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"type":       dataSchema.Properties["type"].Enum[0],
+			"attributes": map[string]interface{}{},
+		},
+	}
+}
+
+// isUnknownRoute is true if the response is what the server or the handlers write for
+// a path or method which they do not handle at all, rather than for a request which
+// they understood but could not fulfil.
+func isUnknownRoute(responseRecorder *httptest.ResponseRecorder) bool {
+	responseBody := responseRecorder.Body.String()
+
+	switch responseRecorder.Code {
+	case http.StatusMethodNotAllowed:
+		return true
+	case http.StatusBadRequest:
+		return strings.Contains(responseBody, "Method not GET")
+	case http.StatusNotFound:
+		return strings.Contains(responseBody, "404 page not found") ||
+			strings.Contains(responseBody, "not valid") ||
+			strings.Contains(responseBody, "No resource found") ||
+			strings.Contains(responseBody, "Not enough segments") ||
+			strings.HasPrefix(responseBody, "{\"errors\":[{\"status\":\"404\",\"code\":\"unclassified\"")
+	default:
+		return false
+	}
+}
+
+// assertMatchesSchema checks that the given value, decoded from JSON, could have been
+// encoded from the type described by the given schema.
+func assertMatchesSchema(
+	unitTest *testing.T,
+	document openapi.Document,
+	givenSchema *openapi.Schema,
+	decodedValue interface{},
+	valueLocation string) {
+	resolvedSchema := document.Resolve(givenSchema)
+	if resolvedSchema == nil {
+		unitTest.Errorf("%v refers to a schema which is not in the document", valueLocation)
+		return
+	}
+
+	if decodedValue == nil {
+		if !resolvedSchema.Nullable && (resolvedSchema.Type != "") {
+			unitTest.Errorf("%v is null but the schema does not allow null", valueLocation)
+		}
+
+		return
+	}
+
+	for _, includedSchema := range resolvedSchema.AllOf {
+		assertMatchesSchema(unitTest, document, includedSchema, decodedValue, valueLocation)
+	}
+
+	isExpectedType := true
+	switch resolvedSchema.Type {
+	case "object":
+		decodedObject, isObject := decodedValue.(map[string]interface{})
+		isExpectedType = isObject
+		for propertyName, propertyValue := range decodedObject {
+			propertyLocation := valueLocation + "." + propertyName
+			propertySchema, isDocumented := resolvedSchema.Properties[propertyName]
+			switch {
+			case isDocumented:
+				assertMatchesSchema(unitTest, document, propertySchema, propertyValue, propertyLocation)
+			case resolvedSchema.AdditionalProperties != nil:
+				assertMatchesSchema(
+					unitTest,
+					document,
+					resolvedSchema.AdditionalProperties,
+					propertyValue,
+					propertyLocation)
+			default:
+				unitTest.Errorf("%v is not in the schema", propertyLocation)
+			}
+		}
+	case "array":
+		decodedArray, isArray := decodedValue.([]interface{})
+		isExpectedType = isArray
+		for elementIndex, elementValue := range decodedArray {
+			assertMatchesSchema(
+				unitTest,
+				document,
+				resolvedSchema.Items,
+				elementValue,
+				fmt.Sprintf("%v[%v]", valueLocation, elementIndex))
+		}
+	case "string":
+		_, isExpectedType = decodedValue.(string)
+	case "boolean":
+		_, isExpectedType = decodedValue.(bool)
+	case "number":
+		_, isExpectedType = decodedValue.(float64)
+	case "integer":
+		decodedNumber, isNumber := decodedValue.(float64)
+		isExpectedType = isNumber && (decodedNumber == math.Trunc(decodedNumber))
+	}
+
+	if !isExpectedType {
+		unitTest.Errorf(
+			"%v is %#v which is not of type %v",
+			valueLocation,
+			decodedValue,
+			resolvedSchema.Type)
+	}
+}
+
+func TestDocumentServedAtDocumentPath(unitTest *testing.T) {
+	backend := newTestBackend(unitTest)
+
+	responseRecorder :=
+		sendRequest(backend.serverState, http.MethodGet, openapi.DocumentPath, nil, "")
+
+	if responseRecorder.Code != http.StatusOK {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusOK)
+	}
+
+	expectedJSON, errorFromMarshal := json.Marshal(openapi.New(testVersion))
+	if errorFromMarshal != nil {
+		unitTest.Fatalf("could not marshal expected document: %v", errorFromMarshal)
+	}
+
+	servedJSON := bytes.TrimSpace(responseRecorder.Body.Bytes())
+	if !bytes.Equal(servedJSON, expectedJSON) {
+		unitTest.Fatalf(
+			"served document %s was not expected document %s",
+			servedJSON,
+			expectedJSON)
+	}
+}
+
+func TestDocumentDescribesStructsOfParsing(unitTest *testing.T) {
+	document := openapi.New(testVersion)
+
+	testCases := []struct {
+		schemaName         string
+		expectedProperties map[string]string
+	}{
+		{
+			schemaName: "PlayerCredentials",
+			expectedProperties: map[string]string{
+				"Name":     "string",
+				"Password": "string",
+			},
+		},
+		{
+			// The fields of the embedded PlayerHintToReceiver and PlayerInGameIndication
+			// are promoted, as they are by encoding/json.
+			schemaName: "PlayerColorHint",
+			expectedProperties: map[string]string{
+				"GameName":     "string",
+				"PlayerName":   "string",
+				"ExpectedTurn": "integer",
+				"ReceiverName": "string",
+				"HintedColor":  "string",
+			},
+		},
+		{
+			schemaName: "LeaderboardRequest",
+			expectedProperties: map[string]string{
+				"RulesetIdentifier":      "integer",
+				"NumberOfPlayers":        "integer",
+				"FinishedFrom":           "string",
+				"FinishedBefore":         "string",
+				"MaximumNumberOfEntries": "integer",
+			},
+		},
+		{
+			// The envelopes of the second version of the API have lower-case names.
+			schemaName: "ErrorObject",
+			expectedProperties: map[string]string{
+				"status": "string",
+				"code":   "string",
+				"detail": "string",
+			},
+		},
+		{
+			schemaName: "PlayerPatch",
+			expectedProperties: map[string]string{
+				"Name":    "string",
+				"Color":   "string",
+				"Role":    "string",
+				"Profile": "",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.schemaName, func(unitTest *testing.T) {
+			namedSchema, isNamed := document.Components.Schemas[testCase.schemaName]
+			if !isNamed {
+				unitTest.Fatalf("no schema named %v", testCase.schemaName)
+			}
+
+			if len(namedSchema.Properties) != len(testCase.expectedProperties) {
+				unitTest.Fatalf(
+					"schema had properties %v instead of expected %v",
+					namedSchema.Properties,
+					testCase.expectedProperties)
+			}
+
+			for propertyName, expectedType := range testCase.expectedProperties {
+				propertySchema, isPresent := namedSchema.Properties[propertyName]
+				if !isPresent || (propertySchema.Type != expectedType) {
+					unitTest.Errorf(
+						"property %v was %+v instead of having type %v",
+						propertyName,
+						propertySchema,
+						expectedType)
+				}
+			}
+		})
+	}
+}
+
+func TestOperationIdentifiersUnique(unitTest *testing.T) {
+	document := openapi.New(testVersion)
+	operationsByIdentifier := make(map[string]openapi.MethodAndPath, 0)
+
+	for _, methodAndPath := range document.Operations() {
+		documentedOperation :=
+			document.Paths[methodAndPath.Path][strings.ToLower(methodAndPath.Method)]
+		existingOperation, isAlreadyUsed := operationsByIdentifier[documentedOperation.OperationID]
+		if isAlreadyUsed {
+			unitTest.Errorf(
+				"operation identifier %v used for both %v and %v",
+				documentedOperation.OperationID,
+				existingOperation,
+				methodAndPath)
+		}
+
+		operationsByIdentifier[documentedOperation.OperationID] = methodAndPath
+	}
+
+	expectedNumberOfOperations :=
+		len(openapi.FirstVersionRoutes()) + len(openapi.ResourceRoutes())
+	if len(operationsByIdentifier) != expectedNumberOfOperations {
+		unitTest.Fatalf(
+			"document had %v operations instead of expected %v",
+			len(operationsByIdentifier),
+			expectedNumberOfOperations)
+	}
+}
+
+func TestUndocumentedRoutesRecognizedAsUnknown(unitTest *testing.T) {
+	backend := newTestBackend(unitTest)
+
+	testCases := []struct {
+		httpMethod     string
+		requestAddress string
+	}{
+		{
+			httpMethod:     http.MethodGet,
+			requestAddress: "/backend/not-an-endpoint",
+		},
+		{
+			httpMethod:     http.MethodGet,
+			requestAddress: "/backend/player/not-a-route",
+		},
+		{
+			httpMethod:     http.MethodPost,
+			requestAddress: "/backend/game/not-a-route",
+		},
+		{
+			httpMethod:     http.MethodPut,
+			requestAddress: "/backend/player/registered-players",
+		},
+		{
+			httpMethod:     http.MethodGet,
+			requestAddress: "/backend/v2/not-a-resource",
+		},
+		{
+			httpMethod:     http.MethodGet,
+			requestAddress: "/backend/v2/games/test%20game/not-a-relationship",
+		},
+		{
+			httpMethod:     http.MethodPatch,
+			requestAddress: "/backend/v2/games",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testName := testCase.httpMethod + " " + testCase.requestAddress
+		unitTest.Run(testName, func(unitTest *testing.T) {
+			responseRecorder :=
+				sendRequest(
+					backend.serverState,
+					testCase.httpMethod,
+					testCase.requestAddress,
+					map[string]interface{}{},
+					backend.sessionToken)
+
+			if !isUnknownRoute(responseRecorder) {
+				unitTest.Fatalf(
+					"response with status %v and body %v not recognized as unknown route",
+					responseRecorder.Code,
+					responseRecorder.Body)
+			}
+		})
+	}
+}
+
+func TestEveryDocumentedOperationHandledWithDocumentedBody(unitTest *testing.T) {
+	document := openapi.New(testVersion)
+	numberOfCheckedBodies := 0
+
+	for _, methodAndPath := range document.Operations() {
+		documentedOperation :=
+			document.Paths[methodAndPath.Path][strings.ToLower(methodAndPath.Method)]
+
+		testName := methodAndPath.Method + " " + methodAndPath.Path
+		unitTest.Run(testName, func(unitTest *testing.T) {
+			// Each operation gets a new backend so that operations such as deleting the
+			// test player do not affect the others.
+			backend := newTestBackend(unitTest)
+
+			responseRecorder :=
+				sendRequest(
+					backend.serverState,
+					methodAndPath.Method,
+					backend.addressFor(methodAndPath.Path),
+					requestBodyFor(document, documentedOperation),
+					backend.sessionToken)
+
+			if isUnknownRoute(responseRecorder) {
+				unitTest.Fatalf(
+					"documented operation not handled: status %v, body %v",
+					responseRecorder.Code,
+					responseRecorder.Body)
+			}
+
+			documentedResponse, isDocumented :=
+				documentedOperation.Responses[strconv.Itoa(responseRecorder.Code)]
+			jsonContent, isJSON := documentedResponse.Content["application/json"]
+			if !isDocumented || !isJSON {
+				// Failures are only described in general, and streams are not JSON.
+				return
+			}
+
+			var decodedBody interface{}
+			errorFromUnmarshal := json.Unmarshal(responseRecorder.Body.Bytes(), &decodedBody)
+			if errorFromUnmarshal != nil {
+				unitTest.Fatalf(
+					"body %v was not JSON: %v",
+					responseRecorder.Body,
+					errorFromUnmarshal)
+			}
+
+			assertMatchesSchema(unitTest, document, jsonContent.Schema, decodedBody, "body")
+			numberOfCheckedBodies++
+		})
+	}
+
+	// Most operations need more than a single player, but at least the operations which
+	// only read or create players and rulesets should have succeeded.
+	minimumNumberOfCheckedBodies := 15
+	if numberOfCheckedBodies < minimumNumberOfCheckedBodies {
+		unitTest.Fatalf(
+			"only %v successful responses checked against the document, expected at least %v",
+			numberOfCheckedBodies,
+			minimumNumberOfCheckedBodies)
+	}
+}
@@ -0,0 +1,531 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// firstVersionParameterDescription describes the parameters in the paths of the first
+// version of the API, which are names or identifiers encoded as URI segments by the
+// parsing.SegmentTranslator of the backend.
+const firstVersionParameterDescription = "Encoded as a URI segment by the segment translator"
+
+var (
+	// errorForFirstVersion is the body of most of the responses to requests to the first
+	// version of the API which failed.
+	errorForFirstVersion = parsing.ErrorForBody{}
+
+	// errorForSecondVersion is the body of every response to a request to the second
+	// version of the API which failed.
+	errorForSecondVersion = parsing.ErrorDocument{}
+
+	// acknowledgement is the string "OK" which is the body of the responses to requests
+	// which change something but have nothing else to report.
+	acknowledgement = "OK"
+)
+
+// FirstVersionRoute describes a path under /backend which is handled by server.State
+// itself or by the handlers for players, games, or updates to games. RequestBody and
+// ResponseBody are objects of the types from the parsing package which are decoded
+// from the body of the request, which is nil if there is no body, and encoded as the
+// body of a successful response, or as the data of each event if IsEventStream is true.
+// IsDeprecated marks routes which are kept only for old clients.
+type FirstVersionRoute struct {
+	Method        string
+	Path          string
+	OperationID   string
+	Summary       string
+	RequestBody   interface{}
+	ResponseBody  interface{}
+	SuccessStatus int
+	IsEventStream bool
+	IsDeprecated  bool
+}
+
+// ResourceRoute describes a path under /backend/v2 which is handled by the handler for
+// resources. RequestAttributes and ResponseAttributes are objects of the types from the
+// parsing package which are the attributes of the resources in the envelopes of the
+// request, which is nil if there is no body, and of the successful response, which is
+// nil if the response holds no resource. RequestType is the type of the resource which
+// the request must hold.
+type ResourceRoute struct {
+	Method             string
+	Path               string
+	OperationID        string
+	Summary            string
+	QueryParameters    []Parameter
+	RequestType        string
+	RequestAttributes  interface{}
+	ResponseAttributes interface{}
+	IsCollection       bool
+	SuccessStatus      int
+}
+
+// FirstVersionRoutes returns every route of the first version of the API, which has
+// to be kept consistent with the switch statements of server.State and of the handlers
+// for players and games.
+func FirstVersionRoutes() []FirstVersionRoute {
+	return []FirstVersionRoute{
+		getRoute(
+			"/backend/version",
+			"getVersion",
+			"Returns the version of the backend",
+			parsing.VersionForBody{}),
+		getRoute(
+			DocumentPath,
+			"getOpenAPIDocument",
+			"Returns this description of the API",
+			map[string]interface{}{}),
+		deprecatedRoute(getRoute(
+			"/backend/player/registered-players",
+			"getRegisteredPlayers",
+			"Lists every registered player, superseded by postSearchPlayers",
+			parsing.PlayerList{})),
+		getRoute(
+			"/backend/player/available-colors",
+			"getAvailableColors",
+			"Lists the colors which players may choose",
+			parsing.ChatColorList{}),
+		getRoute(
+			"/backend/player/contacts-of-player/{playerIdentifier}",
+			"getContactsOfPlayer",
+			"Lists the friends and groups of the player",
+			parsing.ContactList{}),
+		postRoute(
+			"/backend/player/new-player",
+			"postNewPlayer",
+			"Registers a new player",
+			parsing.NewPlayerDefinition{},
+			parsing.PlayerState{}),
+		postRoute(
+			"/backend/player/log-in",
+			"postLogIn",
+			"Logs a player in, returning a session token",
+			parsing.PlayerCredentials{},
+			parsing.SessionToken{}),
+		postRoute(
+			"/backend/player/search-players",
+			"postSearchPlayers",
+			"Returns a page of the players who match the search",
+			parsing.PlayerSearch{},
+			parsing.PlayerPage{}),
+		postRoute(
+			"/backend/player/update-player",
+			"postUpdatePlayer",
+			"Changes the color of the player",
+			parsing.PlayerState{},
+			parsing.PlayerState{}),
+		postRoute(
+			"/backend/player/rename-player",
+			"postRenamePlayer",
+			"Changes the display name of the player",
+			parsing.PlayerState{},
+			parsing.PlayerState{}),
+		postRoute(
+			"/backend/player/update-profile",
+			"postUpdateProfile",
+			"Changes the preferences of the player",
+			parsing.PlayerState{},
+			parsing.PlayerState{}),
+		postRoute(
+			"/backend/player/heartbeat",
+			"postHeartbeat",
+			"Records that the player is present",
+			parsing.Heartbeat{},
+			acknowledgement),
+		postRoute(
+			"/backend/player/add-friend",
+			"postAddFriend",
+			"Adds a friend of the player",
+			parsing.FriendIndication{},
+			parsing.ContactList{}),
+		postRoute(
+			"/backend/player/remove-friend",
+			"postRemoveFriend",
+			"Removes a friend of the player",
+			parsing.FriendIndication{},
+			parsing.ContactList{}),
+		postRoute(
+			"/backend/player/save-group",
+			"postSaveGroup",
+			"Saves a group of players for the player",
+			parsing.GroupDefinition{},
+			parsing.ContactList{}),
+		postRoute(
+			"/backend/player/delete-group",
+			"postDeleteGroup",
+			"Deletes a group of players of the player",
+			parsing.GroupDefinition{},
+			parsing.ContactList{}),
+		postRoute(
+			"/backend/player/set-player-role",
+			"postSetPlayerRole",
+			"Changes the role of a player, for administrators only",
+			parsing.PlayerState{},
+			parsing.PlayerState{}),
+		postRoute(
+			"/backend/player/delete-player",
+			"postDeletePlayer",
+			"Deletes a player, returning the first page of the remaining players",
+			parsing.PlayerState{},
+			parsing.PlayerPage{}),
+		getRoute(
+			"/backend/game/available-rulesets",
+			"getAvailableRulesets",
+			"Lists the rulesets which games may use",
+			parsing.RulesetList{}),
+		getRoute(
+			"/backend/game/all-games-with-player/{playerIdentifier}",
+			"getAllGamesWithPlayer",
+			"Summarizes every game of the player",
+			parsing.TurnSummaryList{}),
+		getRoute(
+			"/backend/game/pending-invitations-for-player/{playerIdentifier}",
+			"getPendingInvitationsForPlayer",
+			"Lists the invitations to games which the player has not yet answered",
+			parsing.InvitationList{}),
+		getRoute(
+			"/backend/game/game-as-seen-by-player/{gameName}/{playerIdentifier}",
+			"getGameAsSeenByPlayer",
+			"Returns the game as the player sees it, with an entity tag",
+			parsing.GameView{}),
+		getRoute(
+			"/backend/game/series-as-seen-by-player/{gameName}/{playerIdentifier}",
+			"getSeriesAsSeenByPlayer",
+			"Summarizes the series of rematches which includes the game",
+			parsing.SeriesView{}),
+		getRoute(
+			"/backend/game/pending-lobbies",
+			"getPendingLobbies",
+			"Lists every lobby which still has open seats",
+			parsing.LobbyList{}),
+		getRoute(
+			"/backend/game/pending-lobbies-with-friends/{playerIdentifier}",
+			"getPendingLobbiesWithFriends",
+			"Lists the lobbies with open seats which include friends of the player",
+			parsing.LobbyList{}),
+		getRoute(
+			"/backend/game/game-as-seen-by-spectator/{gameName}/{playerIdentifier}",
+			"getGameAsSeenBySpectator",
+			"Returns the game with every hand visible, delayed by some turns",
+			parsing.SpectatorView{}),
+		getRoute(
+			"/backend/game/player-statistics/{playerIdentifier}",
+			"getPlayerStatistics",
+			"Returns the statistics of the finished games of the player",
+			parsing.PlayerStatistics{}),
+		postRoute(
+			"/backend/game/create-new-game",
+			"postCreateNewGame",
+			"Invites players to a new game",
+			parsing.GameDefinition{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/accept-invitation",
+			"postAcceptInvitation",
+			"Accepts an invitation to a game",
+			parsing.PlayerInGameIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/decline-invitation",
+			"postDeclineInvitation",
+			"Declines an invitation to a game",
+			parsing.PlayerInGameIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/create-new-lobby",
+			"postCreateNewLobby",
+			"Opens a lobby with seats for a new game",
+			parsing.LobbyDefinition{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/join-lobby",
+			"postJoinLobby",
+			"Takes a seat in a lobby",
+			parsing.PlayerInGameIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/leave-lobby",
+			"postLeaveLobby",
+			"Gives up a seat in a lobby",
+			parsing.PlayerInGameIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/start-game-from-lobby",
+			"postStartGameFromLobby",
+			"Starts the game of a lobby with the players who are seated",
+			parsing.PlayerInGameIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/record-chat-message",
+			"postRecordChatMessage",
+			"Records a chat message from a participant of a game",
+			parsing.PlayerChatMessage{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/take-turn-by-discarding",
+			"postTakeTurnByDiscarding",
+			"Takes a turn by discarding a card",
+			parsing.PlayerCardIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/take-turn-by-attempting-to-play",
+			"postTakeTurnByAttemptingToPlay",
+			"Takes a turn by attempting to play a card",
+			parsing.PlayerCardIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/take-turn-by-hinting-color",
+			"postTakeTurnByHintingColor",
+			"Takes a turn by hinting a color suit to another player",
+			parsing.PlayerColorHint{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/take-turn-by-hinting-number",
+			"postTakeTurnByHintingNumber",
+			"Takes a turn by hinting a sequence index to another player",
+			parsing.PlayerIndexHint{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/rematch",
+			"postRematch",
+			"Invites the players of a finished game to a rematch",
+			parsing.PlayerInGameIndication{},
+			parsing.TurnSummary{}),
+		postRoute(
+			"/backend/game/set-spectating",
+			"postSetSpectating",
+			"Changes whether and with what delay a game may be spectated",
+			parsing.SpectatingSettings{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/record-spectator-chat-message",
+			"postRecordSpectatorChatMessage",
+			"Records a chat message from a spectator of a game",
+			parsing.PlayerChatMessage{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/leave-game",
+			"postLeaveGame",
+			"Removes the player from a game",
+			parsing.PlayerInGameIndication{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/delete-game",
+			"postDeleteGame",
+			"Deletes a game",
+			parsing.GameDefinition{},
+			acknowledgement),
+		postRoute(
+			"/backend/game/leaderboard",
+			"postLeaderboard",
+			"Ranks the finished games of a ruleset and number of players",
+			parsing.LeaderboardRequest{},
+			parsing.Leaderboard{}),
+		{
+			Method:        http.MethodGet,
+			Path:          "/backend/game-updates/game-as-seen-by-player/{gameName}/{playerIdentifier}",
+			OperationID:   "getGameUpdatesAsSeenByPlayer",
+			Summary:       "Streams the game as the player sees it after every change",
+			ResponseBody:  parsing.GameView{},
+			SuccessStatus: http.StatusOK,
+			IsEventStream: true,
+		},
+	}
+}
+
+// ResourceRoutes returns every route of the second version of the API, which has to be
+// kept consistent with the switch statements of the handler for resources.
+func ResourceRoutes() []ResourceRoute {
+	return []ResourceRoute{
+		{
+			Method:      http.MethodGet,
+			Path:        "/backend/v2/players",
+			OperationID: "listPlayers",
+			Summary:     "Returns a page of the players who match the filter",
+			QueryParameters: []Parameter{
+				queryParameter("filter[name]", "Prefix of the names of the players"),
+				queryParameter("sort", "Either name or -name"),
+				queryParameter("page[cursor]", "Cursor from the next link of the previous page"),
+				queryParameter("page[size]", "Maximum number of players on the page"),
+			},
+			ResponseAttributes: parsing.PlayerAttributes{},
+			IsCollection:       true,
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:             http.MethodPost,
+			Path:               "/backend/v2/players",
+			OperationID:        "createPlayer",
+			Summary:            "Registers a new player",
+			RequestType:        "players",
+			RequestAttributes:  parsing.NewPlayerDefinition{},
+			ResponseAttributes: parsing.PlayerAttributes{},
+			SuccessStatus:      http.StatusCreated,
+		},
+		{
+			Method:             http.MethodGet,
+			Path:               "/backend/v2/players/{playerIdentifier}",
+			OperationID:        "getPlayer",
+			Summary:            "Returns a player",
+			ResponseAttributes: parsing.PlayerAttributes{},
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:             http.MethodPatch,
+			Path:               "/backend/v2/players/{playerIdentifier}",
+			OperationID:        "updatePlayer",
+			Summary:            "Changes the attributes of a player which are given",
+			RequestType:        "players",
+			RequestAttributes:  parsing.PlayerPatch{},
+			ResponseAttributes: parsing.PlayerAttributes{},
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:        http.MethodDelete,
+			Path:          "/backend/v2/players/{playerIdentifier}",
+			OperationID:   "deletePlayer",
+			Summary:       "Deletes a player",
+			SuccessStatus: http.StatusOK,
+		},
+		{
+			Method:             http.MethodPost,
+			Path:               "/backend/v2/sessions",
+			OperationID:        "createSession",
+			Summary:            "Logs a player in, returning a session token",
+			RequestType:        "sessions",
+			RequestAttributes:  parsing.PlayerCredentials{},
+			ResponseAttributes: parsing.SessionAttributes{},
+			SuccessStatus:      http.StatusCreated,
+		},
+		{
+			Method:             http.MethodGet,
+			Path:               "/backend/v2/rulesets",
+			OperationID:        "listRulesets",
+			Summary:            "Lists the rulesets which games may use",
+			ResponseAttributes: parsing.RulesetAttributes{},
+			IsCollection:       true,
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:             http.MethodGet,
+			Path:               "/backend/v2/rulesets/{rulesetIdentifier}",
+			OperationID:        "getRuleset",
+			Summary:            "Returns a ruleset",
+			ResponseAttributes: parsing.RulesetAttributes{},
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:             http.MethodGet,
+			Path:               "/backend/v2/games",
+			OperationID:        "listGames",
+			Summary:            "Summarizes every game of the authenticated player",
+			ResponseAttributes: parsing.GameSummaryAttributes{},
+			IsCollection:       true,
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:             http.MethodPost,
+			Path:               "/backend/v2/games",
+			OperationID:        "createGame",
+			Summary:            "Invites players to a new game hosted by the authenticated player",
+			RequestType:        "games",
+			RequestAttributes:  parsing.NewGameAttributes{},
+			ResponseAttributes: parsing.NewGameAttributes{},
+			SuccessStatus:      http.StatusAccepted,
+		},
+		{
+			Method:             http.MethodGet,
+			Path:               "/backend/v2/games/{gameName}",
+			OperationID:        "getGame",
+			Summary:            "Returns the game as the authenticated player sees it, with an entity tag",
+			ResponseAttributes: parsing.GameView{},
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:        http.MethodDelete,
+			Path:          "/backend/v2/games/{gameName}",
+			OperationID:   "deleteGame",
+			Summary:       "Deletes a game",
+			SuccessStatus: http.StatusOK,
+		},
+		{
+			Method:             http.MethodPost,
+			Path:               "/backend/v2/games/{gameName}/actions",
+			OperationID:        "createGameAction",
+			Summary:            "Takes a turn in a game, returning the updated game",
+			RequestType:        "actions",
+			RequestAttributes:  parsing.GameActionAttributes{},
+			ResponseAttributes: parsing.GameView{},
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:             http.MethodPost,
+			Path:               "/backend/v2/games/{gameName}/chat-messages",
+			OperationID:        "createChatMessage",
+			Summary:            "Records a chat message in a game, returning the updated game",
+			RequestType:        "chat-messages",
+			RequestAttributes:  parsing.ChatMessageAttributes{},
+			ResponseAttributes: parsing.GameView{},
+			SuccessStatus:      http.StatusOK,
+		},
+		{
+			Method:        http.MethodDelete,
+			Path:          "/backend/v2/games/{gameName}/participants/{playerIdentifier}",
+			OperationID:   "deleteParticipant",
+			Summary:       "Removes the authenticated player from a game",
+			SuccessStatus: http.StatusOK,
+		},
+	}
+}
+
+// getRoute describes a GET request of the first version of the API.
+func getRoute(
+	routePath string,
+	operationIdentifier string,
+	routeSummary string,
+	responseBody interface{}) FirstVersionRoute {
+	return FirstVersionRoute{
+		Method:        http.MethodGet,
+		Path:          routePath,
+		OperationID:   operationIdentifier,
+		Summary:       routeSummary,
+		ResponseBody:  responseBody,
+		SuccessStatus: http.StatusOK,
+	}
+}
+
+// postRoute describes a POST request of the first version of the API.
+func postRoute(
+	routePath string,
+	operationIdentifier string,
+	routeSummary string,
+	requestBody interface{},
+	responseBody interface{}) FirstVersionRoute {
+	return FirstVersionRoute{
+		Method:        http.MethodPost,
+		Path:          routePath,
+		OperationID:   operationIdentifier,
+		Summary:       routeSummary,
+		RequestBody:   requestBody,
+		ResponseBody:  responseBody,
+		SuccessStatus: http.StatusOK,
+	}
+}
+
+// deprecatedRoute marks the given route of the first version of the API as deprecated.
+func deprecatedRoute(firstVersionRoute FirstVersionRoute) FirstVersionRoute {
+	firstVersionRoute.IsDeprecated = true
+	return firstVersionRoute
+}
+
+// queryParameter describes an optional parameter of the query with the given name.
+func queryParameter(parameterName string, parameterDescription string) Parameter {
+	return Parameter{
+		Name:        parameterName,
+		In:          "query",
+		Description: parameterDescription,
+		Schema:      &Schema{Type: "string"},
+	}
+}
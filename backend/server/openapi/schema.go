@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+var (
+	typeOfTime       = reflect.TypeOf(time.Time{})
+	typeOfRawMessage = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaGenerator derives schemas from Go types in the same way as encoding/json
+// marshals them, so that the schemas describe what the backend actually writes and
+// reads. Named struct types become named schemas which are referred to by name.
+type schemaGenerator struct {
+	namedSchemas map[string]*Schema
+	namedTypes   map[string]reflect.Type
+}
+
+// newSchemaGenerator creates a generator without any named schemas.
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		namedSchemas: make(map[string]*Schema, 0),
+		namedTypes:   make(map[string]reflect.Type, 0),
+	}
+}
+
+// schemaFor returns the schema of the type of the given object.
+func (generator *schemaGenerator) schemaFor(objectOfType interface{}) *Schema {
+	if objectOfType == nil {
+		return &Schema{}
+	}
+
+	return generator.schemaForType(reflect.TypeOf(objectOfType))
+}
+
+// schemaForType returns the schema of the given type, adding a named schema for the
+// type if it is a named struct which has not been seen before.
+func (generator *schemaGenerator) schemaForType(givenType reflect.Type) *Schema {
+	switch {
+	case givenType == typeOfTime:
+		return &Schema{Type: "string", Format: "date-time"}
+	case givenType == typeOfRawMessage:
+		return &Schema{}
+	}
+
+	switch givenType.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// A nil slice is marshalled as null rather than as an empty array.
+		return &Schema{
+			Type:     "array",
+			Items:    generator.schemaForType(givenType.Elem()),
+			Nullable: givenType.Kind() == reflect.Slice,
+		}
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: generator.schemaForType(givenType.Elem()),
+			Nullable:             true,
+		}
+	case reflect.Ptr:
+		pointedSchema := generator.schemaForType(givenType.Elem())
+		if pointedSchema.Reference != "" {
+			// Other fields are ignored alongside a reference, so a reference which may
+			// be null has to be wrapped.
+			return &Schema{AllOf: []*Schema{pointedSchema}, Nullable: true}
+		}
+
+		pointedSchema.Nullable = true
+		return pointedSchema
+	case reflect.Struct:
+		if givenType.Name() == "" {
+			return generator.objectSchema(givenType)
+		}
+
+		return generator.referenceTo(givenType)
+	default:
+		// Interfaces could hold anything.
+		return &Schema{}
+	}
+}
+
+// referenceTo returns a reference to the named schema for the given named struct type,
+// creating the named schema if necessary. The name of the schema is the name of the
+// type, prefixed by the name of its package only if another type of the same name has
+// already been seen.
+func (generator *schemaGenerator) referenceTo(namedType reflect.Type) *Schema {
+	schemaName := namedType.Name()
+	if (generator.namedTypes[schemaName] != nil) && (generator.namedTypes[schemaName] != namedType) {
+		schemaName = path.Base(namedType.PkgPath()) + "." + schemaName
+	}
+
+	if generator.namedTypes[schemaName] == nil {
+		// The type is recorded before its fields are examined so that a type which
+		// refers to itself gets a reference rather than endless recursion.
+		generator.namedTypes[schemaName] = namedType
+		generator.namedSchemas[schemaName] = &Schema{}
+		*generator.namedSchemas[schemaName] = *generator.objectSchema(namedType)
+	}
+
+	return &Schema{Reference: componentPrefix + schemaName}
+}
+
+// objectSchema returns the schema of the object into which the given struct type is
+// marshalled, where the fields of embedded structs are promoted as if they were
+// fields of the given struct, fields without JSON names are left out, and the JSON
+// names of the fields are taken from their tags where given.
+func (generator *schemaGenerator) objectSchema(structType reflect.Type) *Schema {
+	objectProperties := make(map[string]*Schema, 0)
+
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		structField := structType.Field(fieldIndex)
+		jsonName := strings.Split(structField.Tag.Get("json"), ",")[0]
+
+		if jsonName == "-" {
+			continue
+		}
+
+		if structField.Anonymous && (jsonName == "") && (structField.Type.Kind() == reflect.Struct) {
+			embeddedSchema := generator.objectSchema(structField.Type)
+			for propertyName, propertySchema := range embeddedSchema.Properties {
+				// Fields of the outer struct take precedence over promoted fields.
+				if _, isAlreadyPresent := objectProperties[propertyName]; !isAlreadyPresent {
+					objectProperties[propertyName] = propertySchema
+				}
+			}
+
+			continue
+		}
+
+		if structField.PkgPath != "" {
+			// Unexported fields are not marshalled.
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = structField.Name
+		}
+
+		objectProperties[jsonName] = generator.schemaForType(structField.Type)
+	}
+
+	return &Schema{
+		Type:       "object",
+		Properties: objectProperties,
+	}
+}
+
+// resourceDocumentSchema returns the schema of the envelope of the second version of the
+// API around a single resource with attributes of the type of the given object, or a
+// collection of such resources. If the given object is nil, the envelope holds null, as
+// for a resource which has been deleted.
+func (generator *schemaGenerator) resourceDocumentSchema(
+	resourceAttributes interface{},
+	isCollection bool) *Schema {
+	dataSchema := &Schema{Nullable: true}
+
+	if resourceAttributes != nil {
+		dataSchema = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"type":       {Type: "string"},
+				"id":         {Type: "string"},
+				"attributes": generator.schemaFor(resourceAttributes),
+			},
+		}
+	}
+
+	if isCollection {
+		dataSchema = &Schema{Type: "array", Items: dataSchema}
+	}
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data":  dataSchema,
+			"links": generator.schemaFor(&parsing.DocumentLinks{}),
+		},
+	}
+}
+
+// resourceInputSchema returns the schema of the envelope of the second version of the
+// API around the attributes of the type of the given object for a resource of the given
+// type which is to be created or changed.
+func (generator *schemaGenerator) resourceInputSchema(
+	resourceType string,
+	resourceAttributes interface{}) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"type":       {Type: "string", Enum: []string{resourceType}},
+					"attributes": generator.schemaFor(resourceAttributes),
+				},
+			},
+		},
+	}
+}
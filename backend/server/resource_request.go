@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// handleResourceRequest passes a request to the second version of the API, under
+// /backend/v2, on to the handler of resources. Unlike the first version, the segments
+// of the path are unescaped individually, so that identifiers may contain any
+// character, even "/". Errors which arise before the request reaches the handler are
+// written in the same envelope as errors from the handler.
+func (state *State) handleResourceRequest(
+	httpResponseWriter http.ResponseWriter,
+	httpRequest *http.Request) {
+	if state.resourceHandler == nil {
+		http.NotFound(httpResponseWriter, httpRequest)
+		return
+	}
+
+	if httpRequest.Method == http.MethodOptions {
+		return
+	}
+
+	relevantSegments, errorFromPath := unescapedResourceSegments(httpRequest)
+	if errorFromPath != nil {
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			errorForSecondVersion(errorFromPath, http.StatusBadRequest),
+			http.StatusBadRequest)
+		return
+	}
+
+	requestContext, errorFromAuthentication := state.authenticatedContext(httpRequest, false)
+	if errorFromAuthentication != nil {
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			errorForSecondVersion(errorFromAuthentication, http.StatusUnauthorized),
+			http.StatusUnauthorized)
+		return
+	}
+
+	switch httpRequest.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete:
+	default:
+		errorFromMethod := fmt.Errorf("Method %v not supported", httpRequest.Method)
+		writeResponse(
+			httpResponseWriter,
+			httpRequest,
+			errorForSecondVersion(errorFromMethod, http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+
+	queryParameters := httpRequest.URL.Query()
+	performRequest := func(httpBodyDecoder *json.Decoder) (interface{}, int) {
+		return state.resourceHandler.HandleRequest(
+			requestContext,
+			httpRequest.Method,
+			relevantSegments,
+			queryParameters,
+			httpBodyDecoder)
+	}
+
+	idempotencyKey := httpRequest.Header.Get(idempotencyKeyHeader)
+	if (httpRequest.Method == http.MethodPost) &&
+		(idempotencyKey != "") &&
+		(state.idempotencyPersister != nil) &&
+		(httpRequest.Body != nil) {
+		state.handleIdempotentPost(
+			httpResponseWriter,
+			httpRequest,
+			requestContext,
+			performRequest,
+			errorForSecondVersion,
+			idempotencyKey)
+		return
+	}
+
+	// Requests such as GET usually have no body, in which case the handler only finds
+	// out that there is no body if it tries to decode one.
+	var requestBody io.Reader = httpRequest.Body
+	if requestBody == nil {
+		requestBody = strings.NewReader("")
+	}
+
+	objectForBody, httpStatus := performRequest(json.NewDecoder(requestBody))
+
+	writeResponse(httpResponseWriter, httpRequest, objectForBody, httpStatus)
+}
+
+// errorForSecondVersion wraps the given error in the envelope which is used by the
+// second version of the API.
+func errorForSecondVersion(errorFromServer error, httpStatus int) interface{} {
+	return parsing.ErrorDocumentFor(errorFromServer, httpStatus)
+}
+
+// unescapedResourceSegments returns the segments of the path of the given request after
+// "/backend/v2", each unescaped separately from the escaped form of the path.
+func unescapedResourceSegments(httpRequest *http.Request) ([]string, error) {
+	escapedSegments := strings.Split(strings.TrimPrefix(httpRequest.URL.EscapedPath(), "/"), "/")
+
+	// The first two segments are "backend" and "v2", which have already been matched.
+	if len(escapedSegments) < 3 {
+		return []string{}, nil
+	}
+
+	unescapedSegments := make([]string, 0, len(escapedSegments)-2)
+	for _, escapedSegment := range escapedSegments[2:] {
+		unescapedSegment, errorFromUnescape := url.PathUnescape(escapedSegment)
+		if errorFromUnescape != nil {
+			return nil, errorFromUnescape
+		}
+
+		unescapedSegments = append(unescapedSegments, unescapedSegment)
+	}
+
+	return unescapedSegments, nil
+}
@@ -0,0 +1,292 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	"github.com/benoleary/ilutulestikud/backend/server/idempotency"
+)
+
+type mockResourceHandler struct {
+	ReturnInterface         interface{}
+	ReturnCode              int
+	NumberOfRequests        int
+	ReceivedMethod          string
+	ReceivedSegments        []string
+	ReceivedQueryParameters url.Values
+}
+
+// HandleRequest records what it received and returns what it has been given.
+// This implements github.com/benoleary/ilutulestikud/server.httpResourceHandler.
+func (mockHandler *mockResourceHandler) HandleRequest(
+	requestContext context.Context,
+	httpMethod string,
+	relevantSegments []string,
+	queryParameters url.Values,
+	httpBodyDecoder *json.Decoder) (interface{}, int) {
+	mockHandler.NumberOfRequests++
+	mockHandler.ReceivedMethod = httpMethod
+	mockHandler.ReceivedSegments = relevantSegments
+	mockHandler.ReceivedQueryParameters = queryParameters
+
+	return mockHandler.ReturnInterface, mockHandler.ReturnCode
+}
+
+func newStateWithResourceHandler(
+	unitTest *testing.T,
+	resourceHandler *mockResourceHandler) *server.State {
+	return server.NewWithGivenHandlers(
+		mockContextProvider,
+		[]string{"irrelevant to tests"},
+		"test",
+		nil,
+		nil,
+		idempotency.NewInMemory(time.Hour),
+		ErrorEndpointHandler(unitTest),
+		ErrorEndpointHandler(unitTest),
+		nil,
+		resourceHandler)
+}
+
+func TestResourceRequestsNotFoundWithoutHandler(unitTest *testing.T) {
+	serverState :=
+		server.NewWithGivenHandlers(
+			mockContextProvider,
+			[]string{"irrelevant to tests"},
+			"test",
+			nil,
+			nil,
+			nil,
+			ErrorEndpointHandler(unitTest),
+			ErrorEndpointHandler(unitTest),
+			nil,
+			nil)
+
+	responseRecorder := mockGet(serverState, "/backend/v2/players")
+
+	if responseRecorder.Code != http.StatusNotFound {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusNotFound)
+	}
+}
+
+func TestResourceRequestsPassedOnWithUnescapedSegments(unitTest *testing.T) {
+	testCases := []struct {
+		testName         string
+		httpMethod       string
+		requestAddress   string
+		expectedSegments []string
+	}{
+		{
+			testName:         "GET collection",
+			httpMethod:       http.MethodGet,
+			requestAddress:   "/backend/v2/players?sort=-name",
+			expectedSegments: []string{"players"},
+		},
+		{
+			testName:         "PATCH player with escaped slash",
+			httpMethod:       http.MethodPatch,
+			requestAddress:   "/backend/v2/players/a%2Fb",
+			expectedSegments: []string{"players", "a/b"},
+		},
+		{
+			testName:         "DELETE participant with escaped space",
+			httpMethod:       http.MethodDelete,
+			requestAddress:   "/backend/v2/games/game%20name/participants/c",
+			expectedSegments: []string{"games", "game name", "participants", "c"},
+		},
+		{
+			testName:         "POST without segments",
+			httpMethod:       http.MethodPost,
+			requestAddress:   "/backend/v2",
+			expectedSegments: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			resourceHandler := &mockResourceHandler{
+				ReturnInterface: parsing.ResourceDocument{},
+				ReturnCode:      http.StatusOK,
+			}
+
+			serverState := newStateWithResourceHandler(unitTest, resourceHandler)
+
+			httpRequest :=
+				httptest.NewRequest(
+					testCase.httpMethod,
+					testCase.requestAddress,
+					strings.NewReader("{}"))
+			responseRecorder := mockHandleBackend(serverState, httpRequest)
+
+			if responseRecorder.Code != http.StatusOK {
+				unitTest.Fatalf(
+					"returned wrong status %v instead of expected %v",
+					responseRecorder.Code,
+					http.StatusOK)
+			}
+
+			if (resourceHandler.ReceivedMethod != testCase.httpMethod) ||
+				!reflect.DeepEqual(resourceHandler.ReceivedSegments, testCase.expectedSegments) {
+				unitTest.Fatalf(
+					"handler received method %v and segments %#v instead of expected %v and %#v",
+					resourceHandler.ReceivedMethod,
+					resourceHandler.ReceivedSegments,
+					testCase.httpMethod,
+					testCase.expectedSegments)
+			}
+		})
+	}
+}
+
+func TestResourceRequestPassesOnQuery(unitTest *testing.T) {
+	resourceHandler := &mockResourceHandler{
+		ReturnInterface: parsing.ResourceDocument{},
+		ReturnCode:      http.StatusOK,
+	}
+
+	serverState := newStateWithResourceHandler(unitTest, resourceHandler)
+
+	mockGet(serverState, "/backend/v2/players?filter%5Bname%5D=Al&page%5Bsize%5D=2")
+
+	if (resourceHandler.ReceivedQueryParameters.Get("filter[name]") != "Al") ||
+		(resourceHandler.ReceivedQueryParameters.Get("page[size]") != "2") {
+		unitTest.Fatalf(
+			"handler received query parameters %v",
+			resourceHandler.ReceivedQueryParameters)
+	}
+}
+
+func TestResourceRequestErrorsFromServerInEnvelope(unitTest *testing.T) {
+	testCases := []struct {
+		testName       string
+		httpMethod     string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			testName:       "invalid authorization",
+			httpMethod:     http.MethodGet,
+			authorization:  "Basic abc",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testName:       "unsupported method",
+			httpMethod:     http.MethodPut,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			resourceHandler := &mockResourceHandler{}
+			serverState := newStateWithResourceHandler(unitTest, resourceHandler)
+
+			httpRequest :=
+				httptest.NewRequest(
+					testCase.httpMethod,
+					"/backend/v2/players",
+					strings.NewReader("{}"))
+			if testCase.authorization != "" {
+				httpRequest.Header.Set("Authorization", testCase.authorization)
+			}
+
+			responseRecorder := mockHandleBackend(serverState, httpRequest)
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				unitTest.Fatalf(
+					"returned wrong status %v instead of expected %v",
+					responseRecorder.Code,
+					testCase.expectedStatus)
+			}
+
+			var errorDocument parsing.ErrorDocument
+			errorFromUnmarshal :=
+				json.Unmarshal(responseRecorder.Body.Bytes(), &errorDocument)
+
+			if (errorFromUnmarshal != nil) ||
+				(len(errorDocument.Errors) != 1) ||
+				(errorDocument.Errors[0].Detail == "") {
+				unitTest.Fatalf(
+					"response body %v (unmarshalling error %v) was not an error document",
+					responseRecorder.Body,
+					errorFromUnmarshal)
+			}
+
+			if resourceHandler.NumberOfRequests != 0 {
+				unitTest.Fatalf("handler unexpectedly called")
+			}
+		})
+	}
+}
+
+func TestRepeatedResourcePostWithIdempotencyKeyReplaysResponse(unitTest *testing.T) {
+	resourceHandler := &mockResourceHandler{
+		ReturnInterface: parsing.ResourceDocument{},
+		ReturnCode:      http.StatusCreated,
+	}
+
+	serverState := newStateWithResourceHandler(unitTest, resourceHandler)
+	requestBody := "{\"data\":{\"type\":\"players\",\"attributes\":{}}}"
+
+	for requestIndex := 0; requestIndex < 2; requestIndex++ {
+		responseRecorder :=
+			postWithIdempotencyKey(serverState, "/backend/v2/players", requestBody, "key")
+
+		if responseRecorder.Code != http.StatusCreated {
+			unitTest.Fatalf(
+				"request %v returned wrong status %v instead of expected %v",
+				requestIndex,
+				responseRecorder.Code,
+				http.StatusCreated)
+		}
+	}
+
+	if resourceHandler.NumberOfRequests != 1 {
+		unitTest.Fatalf(
+			"handler called %v times instead of once",
+			resourceHandler.NumberOfRequests)
+	}
+}
+
+func TestReusedIdempotencyKeyForResourceInEnvelope(unitTest *testing.T) {
+	resourceHandler := &mockResourceHandler{
+		ReturnInterface: parsing.ResourceDocument{},
+		ReturnCode:      http.StatusCreated,
+	}
+
+	serverState := newStateWithResourceHandler(unitTest, resourceHandler)
+
+	postWithIdempotencyKey(serverState, "/backend/v2/players", "{}", "key")
+	responseRecorder :=
+		postWithIdempotencyKey(serverState, "/backend/v2/players", "{\"data\":{}}", "key")
+
+	if responseRecorder.Code != http.StatusUnprocessableEntity {
+		unitTest.Fatalf(
+			"returned wrong status %v instead of expected %v",
+			responseRecorder.Code,
+			http.StatusUnprocessableEntity)
+	}
+
+	var errorDocument parsing.ErrorDocument
+	errorFromUnmarshal := json.Unmarshal(responseRecorder.Body.Bytes(), &errorDocument)
+	if (errorFromUnmarshal != nil) ||
+		(len(errorDocument.Errors) != 1) ||
+		(errorDocument.Errors[0].Status != "422") {
+		unitTest.Fatalf(
+			"response body %v (unmarshalling error %v) was not the expected error document",
+			responseRecorder.Body,
+			errorFromUnmarshal)
+	}
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	"github.com/benoleary/ilutulestikud/backend/server/rpc/protocol"
+)
+
+// gameViewForProtocol converts the view of a game in the form sent by the HTTP
+// endpoints into the equivalent message.
+func gameViewForProtocol(endpointView parsing.GameView) *protocol.GameView {
+	playedCards := make([]*protocol.CardPile, 0, len(endpointView.PlayedCards))
+	for _, playedPile := range endpointView.PlayedCards {
+		playedCards = append(
+			playedCards,
+			&protocol.CardPile{Cards: visibleCardsForProtocol(playedPile)})
+	}
+
+	return &protocol.GameView{
+		Version:                            int64(endpointView.Version),
+		Turn:                               int64(endpointView.Turn),
+		ChatLog:                            logMessagesForProtocol(endpointView.ChatLog),
+		ActionLog:                          logMessagesForProtocol(endpointView.ActionLog),
+		GameIsFinished:                     endpointView.GameIsFinished,
+		ScoreSoFar:                         int64(endpointView.ScoreSoFar),
+		NumberOfReadyHints:                 int64(endpointView.NumberOfReadyHints),
+		MaximumNumberOfHints:               int64(endpointView.MaximumNumberOfHints),
+		HintColorSuits:                     endpointView.HintColorSuits,
+		HintSequenceIndices:                integersForProtocol(endpointView.HintSequenceIndices),
+		NumberOfMistakesMade:               int64(endpointView.NumberOfMistakesMade),
+		NumberOfMistakesIndicatingGameOver: int64(endpointView.NumberOfMistakesIndicatingGameOver),
+		NumberOfCardsLeftInDeck:            int64(endpointView.NumberOfCardsLeftInDeck),
+		PlayedCards:                        playedCards,
+		DiscardedCards:                     visibleCardsForProtocol(endpointView.DiscardedCards),
+		HandsBeforeThisPlayer:              visibleHandsForProtocol(endpointView.HandsBeforeThisPlayer),
+		HandOfThisPlayer:                   cardsFromBehindForProtocol(endpointView.HandOfThisPlayer),
+		HandsAfterThisPlayer:               visibleHandsForProtocol(endpointView.HandsAfterThisPlayer),
+		ThisPlayerCanTakeTurn:              endpointView.ThisPlayerCanTakeTurn,
+	}
+}
+
+// logMessagesForProtocol converts log messages into the equivalent messages.
+func logMessagesForProtocol(endpointMessages []parsing.LogMessage) []*protocol.LogMessage {
+	protocolMessages := make([]*protocol.LogMessage, 0, len(endpointMessages))
+	for _, endpointMessage := range endpointMessages {
+		protocolMessages = append(protocolMessages, &protocol.LogMessage{
+			TimestampInSeconds: endpointMessage.TimestampInSeconds,
+			PlayerName:         endpointMessage.PlayerName,
+			TextColor:          endpointMessage.TextColor,
+			MessageText:        endpointMessage.MessageText,
+		})
+	}
+
+	return protocolMessages
+}
+
+// visibleCardsForProtocol converts visible cards into the equivalent messages.
+func visibleCardsForProtocol(endpointCards []parsing.VisibleCard) []*protocol.VisibleCard {
+	protocolCards := make([]*protocol.VisibleCard, 0, len(endpointCards))
+	for _, endpointCard := range endpointCards {
+		protocolCards = append(protocolCards, &protocol.VisibleCard{
+			ColorSuit:     endpointCard.ColorSuit,
+			SequenceIndex: int64(endpointCard.SequenceIndex),
+		})
+	}
+
+	return protocolCards
+}
+
+// cardsFromBehindForProtocol converts the knowledge of cards held by a player into the
+// equivalent messages.
+func cardsFromBehindForProtocol(
+	endpointCards []parsing.CardFromBehind) []*protocol.CardFromBehind {
+	protocolCards := make([]*protocol.CardFromBehind, 0, len(endpointCards))
+	for _, endpointCard := range endpointCards {
+		protocolCards = append(protocolCards, &protocol.CardFromBehind{
+			PossibleColorSuits:      endpointCard.PossibleColorSuits,
+			PossibleSequenceIndices: integersForProtocol(endpointCard.PossibleSequenceIndices),
+		})
+	}
+
+	return protocolCards
+}
+
+// visibleHandsForProtocol converts the hands of other players into the equivalent
+// messages.
+func visibleHandsForProtocol(endpointHands []parsing.VisibleHand) []*protocol.VisibleHand {
+	protocolHands := make([]*protocol.VisibleHand, 0, len(endpointHands))
+	for _, endpointHand := range endpointHands {
+		protocolHands = append(protocolHands, &protocol.VisibleHand{
+			PlayerIdentifier:       endpointHand.PlayerIdentifier,
+			PlayerName:             endpointHand.PlayerName,
+			PlayerColor:            endpointHand.PlayerColor,
+			HandCards:              visibleCardsForProtocol(endpointHand.HandCards),
+			KnowledgeOfOwnHand:     cardsFromBehindForProtocol(endpointHand.KnowledgeOfOwnHand),
+			PlayerHasTakenLastTurn: endpointHand.PlayerHasTakenLastTurn,
+			PlayerPresence:         endpointHand.PlayerPresence,
+			PlayerIsViewingGame:    endpointHand.PlayerIsViewingGame,
+		})
+	}
+
+	return protocolHands
+}
+
+// integersForProtocol converts integers into the 64-bit integers of the messages.
+func integersForProtocol(endpointIntegers []int) []int64 {
+	protocolIntegers := make([]int64, 0, len(endpointIntegers))
+	for _, endpointInteger := range endpointIntegers {
+		protocolIntegers = append(protocolIntegers, int64(endpointInteger))
+	}
+
+	return protocolIntegers
+}
@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+)
+
+// SessionTokenSigner defines what a struct should do to allow the service to issue
+// session tokens to players who log in and to identify the player from the token
+// which comes with each later call, which is implemented by the same signer as for
+// the HTTP endpoints so that the tokens can be used with either.
+type SessionTokenSigner interface {
+	// IssueToken should return a signed token identifying the given player.
+	IssueToken(playerIdentifier string) (string, error)
+
+	// PlayerFromToken should return the identifier of the player identified by the given
+	// token, or an error if the token is not valid.
+	PlayerFromToken(sessionToken string) (string, error)
+}
+
+// GameViewConverter defines what a struct should do to allow a Service to send games
+// in the same form as the HTTP endpoints, which is implemented by the Handler from
+// github.com/benoleary/ilutulestikud/backend/server/endpoint/game.
+type GameViewConverter interface {
+	// GameViewForFrontend should convert the given view of a game into the form which
+	// is sent to the frontend, for the given viewing player.
+	GameViewForFrontend(
+		requestContext context.Context,
+		gameView game.ViewForPlayer,
+		playerName string) (parsing.GameView, error)
+}
@@ -0,0 +1,6 @@
+// Package protocol holds the protobuf messages and the gRPC service which are generated
+// from ilutulestikud.proto by protoc with the protoc-gen-go and protoc-gen-go-grpc
+// plugins.
+package protocol
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ilutulestikud.proto
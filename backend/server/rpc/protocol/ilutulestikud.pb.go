@@ -0,0 +1,1584 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.28.3
+// source: ilutulestikud.proto
+
+package protocol
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Acknowledgement is returned by calls which change something but have nothing else to
+// report.
+type Acknowledgement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Acknowledgement) Reset() {
+	*x = Acknowledgement{}
+	mi := &file_ilutulestikud_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Acknowledgement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Acknowledgement) ProtoMessage() {}
+
+func (x *Acknowledgement) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Acknowledgement.ProtoReflect.Descriptor instead.
+func (*Acknowledgement) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{0}
+}
+
+type PlayerCredentials struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerCredentials) Reset() {
+	*x = PlayerCredentials{}
+	mi := &file_ilutulestikud_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerCredentials) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerCredentials) ProtoMessage() {}
+
+func (x *PlayerCredentials) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerCredentials.ProtoReflect.Descriptor instead.
+func (*PlayerCredentials) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlayerCredentials) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PlayerCredentials) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// SessionToken matches parsing.SessionToken without the form of the identifier which is
+// encoded as a URI segment.
+type SessionToken struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PlayerIdentifier string                 `protobuf:"bytes,1,opt,name=player_identifier,json=playerIdentifier,proto3" json:"player_identifier,omitempty"`
+	PlayerName       string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	Token            string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SessionToken) Reset() {
+	*x = SessionToken{}
+	mi := &file_ilutulestikud_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionToken) ProtoMessage() {}
+
+func (x *SessionToken) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionToken.ProtoReflect.Descriptor instead.
+func (*SessionToken) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SessionToken) GetPlayerIdentifier() string {
+	if x != nil {
+		return x.PlayerIdentifier
+	}
+	return ""
+}
+
+func (x *SessionToken) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *SessionToken) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type PlayerIndication struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PlayerIdentifier string                 `protobuf:"bytes,1,opt,name=player_identifier,json=playerIdentifier,proto3" json:"player_identifier,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PlayerIndication) Reset() {
+	*x = PlayerIndication{}
+	mi := &file_ilutulestikud_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerIndication) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerIndication) ProtoMessage() {}
+
+func (x *PlayerIndication) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerIndication.ProtoReflect.Descriptor instead.
+func (*PlayerIndication) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlayerIndication) GetPlayerIdentifier() string {
+	if x != nil {
+		return x.PlayerIdentifier
+	}
+	return ""
+}
+
+// TurnSummary matches parsing.TurnSummary without the form of the name of the game which
+// is encoded as a URI segment.
+type TurnSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	IsPlayerTurn  bool                   `protobuf:"varint,2,opt,name=is_player_turn,json=isPlayerTurn,proto3" json:"is_player_turn,omitempty"`
+	Version       int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnSummary) Reset() {
+	*x = TurnSummary{}
+	mi := &file_ilutulestikud_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnSummary) ProtoMessage() {}
+
+func (x *TurnSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnSummary.ProtoReflect.Descriptor instead.
+func (*TurnSummary) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TurnSummary) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *TurnSummary) GetIsPlayerTurn() bool {
+	if x != nil {
+		return x.IsPlayerTurn
+	}
+	return false
+}
+
+func (x *TurnSummary) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type TurnSummaryList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TurnSummaries []*TurnSummary         `protobuf:"bytes,1,rep,name=turn_summaries,json=turnSummaries,proto3" json:"turn_summaries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnSummaryList) Reset() {
+	*x = TurnSummaryList{}
+	mi := &file_ilutulestikud_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnSummaryList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnSummaryList) ProtoMessage() {}
+
+func (x *TurnSummaryList) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnSummaryList.ProtoReflect.Descriptor instead.
+func (*TurnSummaryList) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TurnSummaryList) GetTurnSummaries() []*TurnSummary {
+	if x != nil {
+		return x.TurnSummaries
+	}
+	return nil
+}
+
+// InvitationSummary matches parsing.InvitationSummary without the form of the name of the
+// game which is encoded as a URI segment.
+type InvitationSummary struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	GameName            string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	RulesetDescription  string                 `protobuf:"bytes,2,opt,name=ruleset_description,json=rulesetDescription,proto3" json:"ruleset_description,omitempty"`
+	HostName            string                 `protobuf:"bytes,3,opt,name=host_name,json=hostName,proto3" json:"host_name,omitempty"`
+	InvitedPlayerNames  []string               `protobuf:"bytes,4,rep,name=invited_player_names,json=invitedPlayerNames,proto3" json:"invited_player_names,omitempty"`
+	AcceptedPlayerNames []string               `protobuf:"bytes,5,rep,name=accepted_player_names,json=acceptedPlayerNames,proto3" json:"accepted_player_names,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *InvitationSummary) Reset() {
+	*x = InvitationSummary{}
+	mi := &file_ilutulestikud_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvitationSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvitationSummary) ProtoMessage() {}
+
+func (x *InvitationSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvitationSummary.ProtoReflect.Descriptor instead.
+func (*InvitationSummary) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InvitationSummary) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *InvitationSummary) GetRulesetDescription() string {
+	if x != nil {
+		return x.RulesetDescription
+	}
+	return ""
+}
+
+func (x *InvitationSummary) GetHostName() string {
+	if x != nil {
+		return x.HostName
+	}
+	return ""
+}
+
+func (x *InvitationSummary) GetInvitedPlayerNames() []string {
+	if x != nil {
+		return x.InvitedPlayerNames
+	}
+	return nil
+}
+
+func (x *InvitationSummary) GetAcceptedPlayerNames() []string {
+	if x != nil {
+		return x.AcceptedPlayerNames
+	}
+	return nil
+}
+
+type InvitationList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invitations   []*InvitationSummary   `protobuf:"bytes,1,rep,name=invitations,proto3" json:"invitations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvitationList) Reset() {
+	*x = InvitationList{}
+	mi := &file_ilutulestikud_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvitationList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvitationList) ProtoMessage() {}
+
+func (x *InvitationList) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvitationList.ProtoReflect.Descriptor instead.
+func (*InvitationList) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *InvitationList) GetInvitations() []*InvitationSummary {
+	if x != nil {
+		return x.Invitations
+	}
+	return nil
+}
+
+type GameDefinition struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GameName          string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	RulesetIdentifier int64                  `protobuf:"varint,2,opt,name=ruleset_identifier,json=rulesetIdentifier,proto3" json:"ruleset_identifier,omitempty"`
+	PlayerNames       []string               `protobuf:"bytes,3,rep,name=player_names,json=playerNames,proto3" json:"player_names,omitempty"`
+	GroupName         string                 `protobuf:"bytes,4,opt,name=group_name,json=groupName,proto3" json:"group_name,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GameDefinition) Reset() {
+	*x = GameDefinition{}
+	mi := &file_ilutulestikud_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameDefinition) ProtoMessage() {}
+
+func (x *GameDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameDefinition.ProtoReflect.Descriptor instead.
+func (*GameDefinition) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GameDefinition) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *GameDefinition) GetRulesetIdentifier() int64 {
+	if x != nil {
+		return x.RulesetIdentifier
+	}
+	return 0
+}
+
+func (x *GameDefinition) GetPlayerNames() []string {
+	if x != nil {
+		return x.PlayerNames
+	}
+	return nil
+}
+
+func (x *GameDefinition) GetGroupName() string {
+	if x != nil {
+		return x.GroupName
+	}
+	return ""
+}
+
+type PlayerInGameIndication struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerInGameIndication) Reset() {
+	*x = PlayerInGameIndication{}
+	mi := &file_ilutulestikud_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerInGameIndication) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerInGameIndication) ProtoMessage() {}
+
+func (x *PlayerInGameIndication) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerInGameIndication.ProtoReflect.Descriptor instead.
+func (*PlayerInGameIndication) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PlayerInGameIndication) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *PlayerInGameIndication) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+type PlayerChatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	ChatMessage   string                 `protobuf:"bytes,3,opt,name=chat_message,json=chatMessage,proto3" json:"chat_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerChatMessage) Reset() {
+	*x = PlayerChatMessage{}
+	mi := &file_ilutulestikud_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerChatMessage) ProtoMessage() {}
+
+func (x *PlayerChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerChatMessage.ProtoReflect.Descriptor instead.
+func (*PlayerChatMessage) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PlayerChatMessage) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *PlayerChatMessage) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *PlayerChatMessage) GetChatMessage() string {
+	if x != nil {
+		return x.ChatMessage
+	}
+	return ""
+}
+
+type PlayerCardIndication struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	ExpectedTurn  int64                  `protobuf:"varint,3,opt,name=expected_turn,json=expectedTurn,proto3" json:"expected_turn,omitempty"`
+	CardIndex     int64                  `protobuf:"varint,4,opt,name=card_index,json=cardIndex,proto3" json:"card_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerCardIndication) Reset() {
+	*x = PlayerCardIndication{}
+	mi := &file_ilutulestikud_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerCardIndication) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerCardIndication) ProtoMessage() {}
+
+func (x *PlayerCardIndication) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerCardIndication.ProtoReflect.Descriptor instead.
+func (*PlayerCardIndication) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PlayerCardIndication) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *PlayerCardIndication) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *PlayerCardIndication) GetExpectedTurn() int64 {
+	if x != nil {
+		return x.ExpectedTurn
+	}
+	return 0
+}
+
+func (x *PlayerCardIndication) GetCardIndex() int64 {
+	if x != nil {
+		return x.CardIndex
+	}
+	return 0
+}
+
+type PlayerColorHint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	ExpectedTurn  int64                  `protobuf:"varint,3,opt,name=expected_turn,json=expectedTurn,proto3" json:"expected_turn,omitempty"`
+	ReceiverName  string                 `protobuf:"bytes,4,opt,name=receiver_name,json=receiverName,proto3" json:"receiver_name,omitempty"`
+	HintedColor   string                 `protobuf:"bytes,5,opt,name=hinted_color,json=hintedColor,proto3" json:"hinted_color,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerColorHint) Reset() {
+	*x = PlayerColorHint{}
+	mi := &file_ilutulestikud_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerColorHint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerColorHint) ProtoMessage() {}
+
+func (x *PlayerColorHint) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerColorHint.ProtoReflect.Descriptor instead.
+func (*PlayerColorHint) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PlayerColorHint) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *PlayerColorHint) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *PlayerColorHint) GetExpectedTurn() int64 {
+	if x != nil {
+		return x.ExpectedTurn
+	}
+	return 0
+}
+
+func (x *PlayerColorHint) GetReceiverName() string {
+	if x != nil {
+		return x.ReceiverName
+	}
+	return ""
+}
+
+func (x *PlayerColorHint) GetHintedColor() string {
+	if x != nil {
+		return x.HintedColor
+	}
+	return ""
+}
+
+type PlayerIndexHint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameName      string                 `protobuf:"bytes,1,opt,name=game_name,json=gameName,proto3" json:"game_name,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	ExpectedTurn  int64                  `protobuf:"varint,3,opt,name=expected_turn,json=expectedTurn,proto3" json:"expected_turn,omitempty"`
+	ReceiverName  string                 `protobuf:"bytes,4,opt,name=receiver_name,json=receiverName,proto3" json:"receiver_name,omitempty"`
+	HintedNumber  int64                  `protobuf:"varint,5,opt,name=hinted_number,json=hintedNumber,proto3" json:"hinted_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerIndexHint) Reset() {
+	*x = PlayerIndexHint{}
+	mi := &file_ilutulestikud_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerIndexHint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerIndexHint) ProtoMessage() {}
+
+func (x *PlayerIndexHint) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerIndexHint.ProtoReflect.Descriptor instead.
+func (*PlayerIndexHint) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PlayerIndexHint) GetGameName() string {
+	if x != nil {
+		return x.GameName
+	}
+	return ""
+}
+
+func (x *PlayerIndexHint) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *PlayerIndexHint) GetExpectedTurn() int64 {
+	if x != nil {
+		return x.ExpectedTurn
+	}
+	return 0
+}
+
+func (x *PlayerIndexHint) GetReceiverName() string {
+	if x != nil {
+		return x.ReceiverName
+	}
+	return ""
+}
+
+func (x *PlayerIndexHint) GetHintedNumber() int64 {
+	if x != nil {
+		return x.HintedNumber
+	}
+	return 0
+}
+
+type LogMessage struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TimestampInSeconds int64                  `protobuf:"varint,1,opt,name=timestamp_in_seconds,json=timestampInSeconds,proto3" json:"timestamp_in_seconds,omitempty"`
+	PlayerName         string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	TextColor          string                 `protobuf:"bytes,3,opt,name=text_color,json=textColor,proto3" json:"text_color,omitempty"`
+	MessageText        string                 `protobuf:"bytes,4,opt,name=message_text,json=messageText,proto3" json:"message_text,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *LogMessage) Reset() {
+	*x = LogMessage{}
+	mi := &file_ilutulestikud_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogMessage) ProtoMessage() {}
+
+func (x *LogMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogMessage.ProtoReflect.Descriptor instead.
+func (*LogMessage) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LogMessage) GetTimestampInSeconds() int64 {
+	if x != nil {
+		return x.TimestampInSeconds
+	}
+	return 0
+}
+
+func (x *LogMessage) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *LogMessage) GetTextColor() string {
+	if x != nil {
+		return x.TextColor
+	}
+	return ""
+}
+
+func (x *LogMessage) GetMessageText() string {
+	if x != nil {
+		return x.MessageText
+	}
+	return ""
+}
+
+type VisibleCard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ColorSuit     string                 `protobuf:"bytes,1,opt,name=color_suit,json=colorSuit,proto3" json:"color_suit,omitempty"`
+	SequenceIndex int64                  `protobuf:"varint,2,opt,name=sequence_index,json=sequenceIndex,proto3" json:"sequence_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VisibleCard) Reset() {
+	*x = VisibleCard{}
+	mi := &file_ilutulestikud_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VisibleCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VisibleCard) ProtoMessage() {}
+
+func (x *VisibleCard) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VisibleCard.ProtoReflect.Descriptor instead.
+func (*VisibleCard) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VisibleCard) GetColorSuit() string {
+	if x != nil {
+		return x.ColorSuit
+	}
+	return ""
+}
+
+func (x *VisibleCard) GetSequenceIndex() int64 {
+	if x != nil {
+		return x.SequenceIndex
+	}
+	return 0
+}
+
+// CardPile holds the cards of a single color suit which have been played, as protobuf
+// has no repeated field of repeated fields.
+type CardPile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cards         []*VisibleCard         `protobuf:"bytes,1,rep,name=cards,proto3" json:"cards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CardPile) Reset() {
+	*x = CardPile{}
+	mi := &file_ilutulestikud_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardPile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardPile) ProtoMessage() {}
+
+func (x *CardPile) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardPile.ProtoReflect.Descriptor instead.
+func (*CardPile) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CardPile) GetCards() []*VisibleCard {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+type CardFromBehind struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	PossibleColorSuits      []string               `protobuf:"bytes,1,rep,name=possible_color_suits,json=possibleColorSuits,proto3" json:"possible_color_suits,omitempty"`
+	PossibleSequenceIndices []int64                `protobuf:"varint,2,rep,packed,name=possible_sequence_indices,json=possibleSequenceIndices,proto3" json:"possible_sequence_indices,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *CardFromBehind) Reset() {
+	*x = CardFromBehind{}
+	mi := &file_ilutulestikud_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardFromBehind) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardFromBehind) ProtoMessage() {}
+
+func (x *CardFromBehind) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardFromBehind.ProtoReflect.Descriptor instead.
+func (*CardFromBehind) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CardFromBehind) GetPossibleColorSuits() []string {
+	if x != nil {
+		return x.PossibleColorSuits
+	}
+	return nil
+}
+
+func (x *CardFromBehind) GetPossibleSequenceIndices() []int64 {
+	if x != nil {
+		return x.PossibleSequenceIndices
+	}
+	return nil
+}
+
+type VisibleHand struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	PlayerIdentifier       string                 `protobuf:"bytes,1,opt,name=player_identifier,json=playerIdentifier,proto3" json:"player_identifier,omitempty"`
+	PlayerName             string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	PlayerColor            string                 `protobuf:"bytes,3,opt,name=player_color,json=playerColor,proto3" json:"player_color,omitempty"`
+	HandCards              []*VisibleCard         `protobuf:"bytes,4,rep,name=hand_cards,json=handCards,proto3" json:"hand_cards,omitempty"`
+	KnowledgeOfOwnHand     []*CardFromBehind      `protobuf:"bytes,5,rep,name=knowledge_of_own_hand,json=knowledgeOfOwnHand,proto3" json:"knowledge_of_own_hand,omitempty"`
+	PlayerHasTakenLastTurn bool                   `protobuf:"varint,6,opt,name=player_has_taken_last_turn,json=playerHasTakenLastTurn,proto3" json:"player_has_taken_last_turn,omitempty"`
+	PlayerPresence         string                 `protobuf:"bytes,7,opt,name=player_presence,json=playerPresence,proto3" json:"player_presence,omitempty"`
+	PlayerIsViewingGame    bool                   `protobuf:"varint,8,opt,name=player_is_viewing_game,json=playerIsViewingGame,proto3" json:"player_is_viewing_game,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *VisibleHand) Reset() {
+	*x = VisibleHand{}
+	mi := &file_ilutulestikud_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VisibleHand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VisibleHand) ProtoMessage() {}
+
+func (x *VisibleHand) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VisibleHand.ProtoReflect.Descriptor instead.
+func (*VisibleHand) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VisibleHand) GetPlayerIdentifier() string {
+	if x != nil {
+		return x.PlayerIdentifier
+	}
+	return ""
+}
+
+func (x *VisibleHand) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *VisibleHand) GetPlayerColor() string {
+	if x != nil {
+		return x.PlayerColor
+	}
+	return ""
+}
+
+func (x *VisibleHand) GetHandCards() []*VisibleCard {
+	if x != nil {
+		return x.HandCards
+	}
+	return nil
+}
+
+func (x *VisibleHand) GetKnowledgeOfOwnHand() []*CardFromBehind {
+	if x != nil {
+		return x.KnowledgeOfOwnHand
+	}
+	return nil
+}
+
+func (x *VisibleHand) GetPlayerHasTakenLastTurn() bool {
+	if x != nil {
+		return x.PlayerHasTakenLastTurn
+	}
+	return false
+}
+
+func (x *VisibleHand) GetPlayerPresence() string {
+	if x != nil {
+		return x.PlayerPresence
+	}
+	return ""
+}
+
+func (x *VisibleHand) GetPlayerIsViewingGame() bool {
+	if x != nil {
+		return x.PlayerIsViewingGame
+	}
+	return false
+}
+
+type GameView struct {
+	state                              protoimpl.MessageState `protogen:"open.v1"`
+	Version                            int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	ChatLog                            []*LogMessage          `protobuf:"bytes,2,rep,name=chat_log,json=chatLog,proto3" json:"chat_log,omitempty"`
+	ActionLog                          []*LogMessage          `protobuf:"bytes,3,rep,name=action_log,json=actionLog,proto3" json:"action_log,omitempty"`
+	GameIsFinished                     bool                   `protobuf:"varint,4,opt,name=game_is_finished,json=gameIsFinished,proto3" json:"game_is_finished,omitempty"`
+	ScoreSoFar                         int64                  `protobuf:"varint,5,opt,name=score_so_far,json=scoreSoFar,proto3" json:"score_so_far,omitempty"`
+	NumberOfReadyHints                 int64                  `protobuf:"varint,6,opt,name=number_of_ready_hints,json=numberOfReadyHints,proto3" json:"number_of_ready_hints,omitempty"`
+	MaximumNumberOfHints               int64                  `protobuf:"varint,7,opt,name=maximum_number_of_hints,json=maximumNumberOfHints,proto3" json:"maximum_number_of_hints,omitempty"`
+	HintColorSuits                     []string               `protobuf:"bytes,8,rep,name=hint_color_suits,json=hintColorSuits,proto3" json:"hint_color_suits,omitempty"`
+	HintSequenceIndices                []int64                `protobuf:"varint,9,rep,packed,name=hint_sequence_indices,json=hintSequenceIndices,proto3" json:"hint_sequence_indices,omitempty"`
+	NumberOfMistakesMade               int64                  `protobuf:"varint,10,opt,name=number_of_mistakes_made,json=numberOfMistakesMade,proto3" json:"number_of_mistakes_made,omitempty"`
+	NumberOfMistakesIndicatingGameOver int64                  `protobuf:"varint,11,opt,name=number_of_mistakes_indicating_game_over,json=numberOfMistakesIndicatingGameOver,proto3" json:"number_of_mistakes_indicating_game_over,omitempty"`
+	NumberOfCardsLeftInDeck            int64                  `protobuf:"varint,12,opt,name=number_of_cards_left_in_deck,json=numberOfCardsLeftInDeck,proto3" json:"number_of_cards_left_in_deck,omitempty"`
+	PlayedCards                        []*CardPile            `protobuf:"bytes,13,rep,name=played_cards,json=playedCards,proto3" json:"played_cards,omitempty"`
+	DiscardedCards                     []*VisibleCard         `protobuf:"bytes,14,rep,name=discarded_cards,json=discardedCards,proto3" json:"discarded_cards,omitempty"`
+	HandsBeforeThisPlayer              []*VisibleHand         `protobuf:"bytes,15,rep,name=hands_before_this_player,json=handsBeforeThisPlayer,proto3" json:"hands_before_this_player,omitempty"`
+	HandOfThisPlayer                   []*CardFromBehind      `protobuf:"bytes,16,rep,name=hand_of_this_player,json=handOfThisPlayer,proto3" json:"hand_of_this_player,omitempty"`
+	HandsAfterThisPlayer               []*VisibleHand         `protobuf:"bytes,17,rep,name=hands_after_this_player,json=handsAfterThisPlayer,proto3" json:"hands_after_this_player,omitempty"`
+	ThisPlayerCanTakeTurn              bool                   `protobuf:"varint,18,opt,name=this_player_can_take_turn,json=thisPlayerCanTakeTurn,proto3" json:"this_player_can_take_turn,omitempty"`
+	Turn                               int64                  `protobuf:"varint,19,opt,name=turn,proto3" json:"turn,omitempty"`
+	unknownFields                      protoimpl.UnknownFields
+	sizeCache                          protoimpl.SizeCache
+}
+
+func (x *GameView) Reset() {
+	*x = GameView{}
+	mi := &file_ilutulestikud_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameView) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameView) ProtoMessage() {}
+
+func (x *GameView) ProtoReflect() protoreflect.Message {
+	mi := &file_ilutulestikud_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameView.ProtoReflect.Descriptor instead.
+func (*GameView) Descriptor() ([]byte, []int) {
+	return file_ilutulestikud_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GameView) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *GameView) GetChatLog() []*LogMessage {
+	if x != nil {
+		return x.ChatLog
+	}
+	return nil
+}
+
+func (x *GameView) GetActionLog() []*LogMessage {
+	if x != nil {
+		return x.ActionLog
+	}
+	return nil
+}
+
+func (x *GameView) GetGameIsFinished() bool {
+	if x != nil {
+		return x.GameIsFinished
+	}
+	return false
+}
+
+func (x *GameView) GetScoreSoFar() int64 {
+	if x != nil {
+		return x.ScoreSoFar
+	}
+	return 0
+}
+
+func (x *GameView) GetNumberOfReadyHints() int64 {
+	if x != nil {
+		return x.NumberOfReadyHints
+	}
+	return 0
+}
+
+func (x *GameView) GetMaximumNumberOfHints() int64 {
+	if x != nil {
+		return x.MaximumNumberOfHints
+	}
+	return 0
+}
+
+func (x *GameView) GetHintColorSuits() []string {
+	if x != nil {
+		return x.HintColorSuits
+	}
+	return nil
+}
+
+func (x *GameView) GetHintSequenceIndices() []int64 {
+	if x != nil {
+		return x.HintSequenceIndices
+	}
+	return nil
+}
+
+func (x *GameView) GetNumberOfMistakesMade() int64 {
+	if x != nil {
+		return x.NumberOfMistakesMade
+	}
+	return 0
+}
+
+func (x *GameView) GetNumberOfMistakesIndicatingGameOver() int64 {
+	if x != nil {
+		return x.NumberOfMistakesIndicatingGameOver
+	}
+	return 0
+}
+
+func (x *GameView) GetNumberOfCardsLeftInDeck() int64 {
+	if x != nil {
+		return x.NumberOfCardsLeftInDeck
+	}
+	return 0
+}
+
+func (x *GameView) GetPlayedCards() []*CardPile {
+	if x != nil {
+		return x.PlayedCards
+	}
+	return nil
+}
+
+func (x *GameView) GetDiscardedCards() []*VisibleCard {
+	if x != nil {
+		return x.DiscardedCards
+	}
+	return nil
+}
+
+func (x *GameView) GetHandsBeforeThisPlayer() []*VisibleHand {
+	if x != nil {
+		return x.HandsBeforeThisPlayer
+	}
+	return nil
+}
+
+func (x *GameView) GetHandOfThisPlayer() []*CardFromBehind {
+	if x != nil {
+		return x.HandOfThisPlayer
+	}
+	return nil
+}
+
+func (x *GameView) GetHandsAfterThisPlayer() []*VisibleHand {
+	if x != nil {
+		return x.HandsAfterThisPlayer
+	}
+	return nil
+}
+
+func (x *GameView) GetThisPlayerCanTakeTurn() bool {
+	if x != nil {
+		return x.ThisPlayerCanTakeTurn
+	}
+	return false
+}
+
+func (x *GameView) GetTurn() int64 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+var File_ilutulestikud_proto protoreflect.FileDescriptor
+
+const file_ilutulestikud_proto_rawDesc = "" +
+	"\n" +
+	"\x13ilutulestikud.proto\x12\rilutulestikud\"\x11\n" +
+	"\x0fAcknowledgement\"C\n" +
+	"\x11PlayerCredentials\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"r\n" +
+	"\fSessionToken\x12+\n" +
+	"\x11player_identifier\x18\x01 \x01(\tR\x10playerIdentifier\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"?\n" +
+	"\x10PlayerIndication\x12+\n" +
+	"\x11player_identifier\x18\x01 \x01(\tR\x10playerIdentifier\"j\n" +
+	"\vTurnSummary\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12$\n" +
+	"\x0eis_player_turn\x18\x02 \x01(\bR\fisPlayerTurn\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\"T\n" +
+	"\x0fTurnSummaryList\x12A\n" +
+	"\x0eturn_summaries\x18\x01 \x03(\v2\x1a.ilutulestikud.TurnSummaryR\rturnSummaries\"\xe4\x01\n" +
+	"\x11InvitationSummary\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12/\n" +
+	"\x13ruleset_description\x18\x02 \x01(\tR\x12rulesetDescription\x12\x1b\n" +
+	"\thost_name\x18\x03 \x01(\tR\bhostName\x120\n" +
+	"\x14invited_player_names\x18\x04 \x03(\tR\x12invitedPlayerNames\x122\n" +
+	"\x15accepted_player_names\x18\x05 \x03(\tR\x13acceptedPlayerNames\"T\n" +
+	"\x0eInvitationList\x12B\n" +
+	"\vinvitations\x18\x01 \x03(\v2 .ilutulestikud.InvitationSummaryR\vinvitations\"\x9e\x01\n" +
+	"\x0eGameDefinition\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12-\n" +
+	"\x12ruleset_identifier\x18\x02 \x01(\x03R\x11rulesetIdentifier\x12!\n" +
+	"\fplayer_names\x18\x03 \x03(\tR\vplayerNames\x12\x1d\n" +
+	"\n" +
+	"group_name\x18\x04 \x01(\tR\tgroupName\"V\n" +
+	"\x16PlayerInGameIndication\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\"t\n" +
+	"\x11PlayerChatMessage\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12!\n" +
+	"\fchat_message\x18\x03 \x01(\tR\vchatMessage\"\x98\x01\n" +
+	"\x14PlayerCardIndication\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12#\n" +
+	"\rexpected_turn\x18\x03 \x01(\x03R\fexpectedTurn\x12\x1d\n" +
+	"\n" +
+	"card_index\x18\x04 \x01(\x03R\tcardIndex\"\xbc\x01\n" +
+	"\x0fPlayerColorHint\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12#\n" +
+	"\rexpected_turn\x18\x03 \x01(\x03R\fexpectedTurn\x12#\n" +
+	"\rreceiver_name\x18\x04 \x01(\tR\freceiverName\x12!\n" +
+	"\fhinted_color\x18\x05 \x01(\tR\vhintedColor\"\xbe\x01\n" +
+	"\x0fPlayerIndexHint\x12\x1b\n" +
+	"\tgame_name\x18\x01 \x01(\tR\bgameName\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12#\n" +
+	"\rexpected_turn\x18\x03 \x01(\x03R\fexpectedTurn\x12#\n" +
+	"\rreceiver_name\x18\x04 \x01(\tR\freceiverName\x12#\n" +
+	"\rhinted_number\x18\x05 \x01(\x03R\fhintedNumber\"\xa1\x01\n" +
+	"\n" +
+	"LogMessage\x120\n" +
+	"\x14timestamp_in_seconds\x18\x01 \x01(\x03R\x12timestampInSeconds\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x1d\n" +
+	"\n" +
+	"text_color\x18\x03 \x01(\tR\ttextColor\x12!\n" +
+	"\fmessage_text\x18\x04 \x01(\tR\vmessageText\"S\n" +
+	"\vVisibleCard\x12\x1d\n" +
+	"\n" +
+	"color_suit\x18\x01 \x01(\tR\tcolorSuit\x12%\n" +
+	"\x0esequence_index\x18\x02 \x01(\x03R\rsequenceIndex\"<\n" +
+	"\bCardPile\x120\n" +
+	"\x05cards\x18\x01 \x03(\v2\x1a.ilutulestikud.VisibleCardR\x05cards\"~\n" +
+	"\x0eCardFromBehind\x120\n" +
+	"\x14possible_color_suits\x18\x01 \x03(\tR\x12possibleColorSuits\x12:\n" +
+	"\x19possible_sequence_indices\x18\x02 \x03(\x03R\x17possibleSequenceIndices\"\xa5\x03\n" +
+	"\vVisibleHand\x12+\n" +
+	"\x11player_identifier\x18\x01 \x01(\tR\x10playerIdentifier\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12!\n" +
+	"\fplayer_color\x18\x03 \x01(\tR\vplayerColor\x129\n" +
+	"\n" +
+	"hand_cards\x18\x04 \x03(\v2\x1a.ilutulestikud.VisibleCardR\thandCards\x12P\n" +
+	"\x15knowledge_of_own_hand\x18\x05 \x03(\v2\x1d.ilutulestikud.CardFromBehindR\x12knowledgeOfOwnHand\x12:\n" +
+	"\x1aplayer_has_taken_last_turn\x18\x06 \x01(\bR\x16playerHasTakenLastTurn\x12'\n" +
+	"\x0fplayer_presence\x18\a \x01(\tR\x0eplayerPresence\x123\n" +
+	"\x16player_is_viewing_game\x18\b \x01(\bR\x13playerIsViewingGame\"\xb8\b\n" +
+	"\bGameView\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x124\n" +
+	"\bchat_log\x18\x02 \x03(\v2\x19.ilutulestikud.LogMessageR\achatLog\x128\n" +
+	"\n" +
+	"action_log\x18\x03 \x03(\v2\x19.ilutulestikud.LogMessageR\tactionLog\x12(\n" +
+	"\x10game_is_finished\x18\x04 \x01(\bR\x0egameIsFinished\x12 \n" +
+	"\fscore_so_far\x18\x05 \x01(\x03R\n" +
+	"scoreSoFar\x121\n" +
+	"\x15number_of_ready_hints\x18\x06 \x01(\x03R\x12numberOfReadyHints\x125\n" +
+	"\x17maximum_number_of_hints\x18\a \x01(\x03R\x14maximumNumberOfHints\x12(\n" +
+	"\x10hint_color_suits\x18\b \x03(\tR\x0ehintColorSuits\x122\n" +
+	"\x15hint_sequence_indices\x18\t \x03(\x03R\x13hintSequenceIndices\x125\n" +
+	"\x17number_of_mistakes_made\x18\n" +
+	" \x01(\x03R\x14numberOfMistakesMade\x12S\n" +
+	"'number_of_mistakes_indicating_game_over\x18\v \x01(\x03R\"numberOfMistakesIndicatingGameOver\x12=\n" +
+	"\x1cnumber_of_cards_left_in_deck\x18\f \x01(\x03R\x17numberOfCardsLeftInDeck\x12:\n" +
+	"\fplayed_cards\x18\r \x03(\v2\x17.ilutulestikud.CardPileR\vplayedCards\x12C\n" +
+	"\x0fdiscarded_cards\x18\x0e \x03(\v2\x1a.ilutulestikud.VisibleCardR\x0ediscardedCards\x12S\n" +
+	"\x18hands_before_this_player\x18\x0f \x03(\v2\x1a.ilutulestikud.VisibleHandR\x15handsBeforeThisPlayer\x12L\n" +
+	"\x13hand_of_this_player\x18\x10 \x03(\v2\x1d.ilutulestikud.CardFromBehindR\x10handOfThisPlayer\x12Q\n" +
+	"\x17hands_after_this_player\x18\x11 \x03(\v2\x1a.ilutulestikud.VisibleHandR\x14handsAfterThisPlayer\x128\n" +
+	"\x19this_player_can_take_turn\x18\x12 \x01(\bR\x15thisPlayerCanTakeTurn\x12\x12\n" +
+	"\x04turn\x18\x13 \x01(\x03R\x04turn2\x94\a\n" +
+	"\rIlutulestikud\x12F\n" +
+	"\x05LogIn\x12 .ilutulestikud.PlayerCredentials\x1a\x1b.ilutulestikud.SessionToken\x12L\n" +
+	"\tListGames\x12\x1f.ilutulestikud.PlayerIndication\x1a\x1e.ilutulestikud.TurnSummaryList\x12Q\n" +
+	"\x0fListInvitations\x12\x1f.ilutulestikud.PlayerIndication\x1a\x1d.ilutulestikud.InvitationList\x12K\n" +
+	"\n" +
+	"CreateGame\x12\x1d.ilutulestikud.GameDefinition\x1a\x1e.ilutulestikud.Acknowledgement\x12Y\n" +
+	"\x10AcceptInvitation\x12%.ilutulestikud.PlayerInGameIndication\x1a\x1e.ilutulestikud.Acknowledgement\x12I\n" +
+	"\aGetGame\x12%.ilutulestikud.PlayerInGameIndication\x1a\x17.ilutulestikud.GameView\x12N\n" +
+	"\x11RecordChatMessage\x12 .ilutulestikud.PlayerChatMessage\x1a\x17.ilutulestikud.GameView\x12T\n" +
+	"\x14TakeTurnByDiscarding\x12#.ilutulestikud.PlayerCardIndication\x1a\x17.ilutulestikud.GameView\x12Z\n" +
+	"\x1aTakeTurnByAttemptingToPlay\x12#.ilutulestikud.PlayerCardIndication\x1a\x17.ilutulestikud.GameView\x12Q\n" +
+	"\x16TakeTurnByHintingColor\x12\x1e.ilutulestikud.PlayerColorHint\x1a\x17.ilutulestikud.GameView\x12R\n" +
+	"\x17TakeTurnByHintingNumber\x12\x1e.ilutulestikud.PlayerIndexHint\x1a\x17.ilutulestikud.GameViewB@Z>github.com/benoleary/ilutulestikud/backend/server/rpc/protocolb\x06proto3"
+
+var (
+	file_ilutulestikud_proto_rawDescOnce sync.Once
+	file_ilutulestikud_proto_rawDescData []byte
+)
+
+func file_ilutulestikud_proto_rawDescGZIP() []byte {
+	file_ilutulestikud_proto_rawDescOnce.Do(func() {
+		file_ilutulestikud_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ilutulestikud_proto_rawDesc), len(file_ilutulestikud_proto_rawDesc)))
+	})
+	return file_ilutulestikud_proto_rawDescData
+}
+
+var file_ilutulestikud_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_ilutulestikud_proto_goTypes = []any{
+	(*Acknowledgement)(nil),        // 0: ilutulestikud.Acknowledgement
+	(*PlayerCredentials)(nil),      // 1: ilutulestikud.PlayerCredentials
+	(*SessionToken)(nil),           // 2: ilutulestikud.SessionToken
+	(*PlayerIndication)(nil),       // 3: ilutulestikud.PlayerIndication
+	(*TurnSummary)(nil),            // 4: ilutulestikud.TurnSummary
+	(*TurnSummaryList)(nil),        // 5: ilutulestikud.TurnSummaryList
+	(*InvitationSummary)(nil),      // 6: ilutulestikud.InvitationSummary
+	(*InvitationList)(nil),         // 7: ilutulestikud.InvitationList
+	(*GameDefinition)(nil),         // 8: ilutulestikud.GameDefinition
+	(*PlayerInGameIndication)(nil), // 9: ilutulestikud.PlayerInGameIndication
+	(*PlayerChatMessage)(nil),      // 10: ilutulestikud.PlayerChatMessage
+	(*PlayerCardIndication)(nil),   // 11: ilutulestikud.PlayerCardIndication
+	(*PlayerColorHint)(nil),        // 12: ilutulestikud.PlayerColorHint
+	(*PlayerIndexHint)(nil),        // 13: ilutulestikud.PlayerIndexHint
+	(*LogMessage)(nil),             // 14: ilutulestikud.LogMessage
+	(*VisibleCard)(nil),            // 15: ilutulestikud.VisibleCard
+	(*CardPile)(nil),               // 16: ilutulestikud.CardPile
+	(*CardFromBehind)(nil),         // 17: ilutulestikud.CardFromBehind
+	(*VisibleHand)(nil),            // 18: ilutulestikud.VisibleHand
+	(*GameView)(nil),               // 19: ilutulestikud.GameView
+}
+var file_ilutulestikud_proto_depIdxs = []int32{
+	4,  // 0: ilutulestikud.TurnSummaryList.turn_summaries:type_name -> ilutulestikud.TurnSummary
+	6,  // 1: ilutulestikud.InvitationList.invitations:type_name -> ilutulestikud.InvitationSummary
+	15, // 2: ilutulestikud.CardPile.cards:type_name -> ilutulestikud.VisibleCard
+	15, // 3: ilutulestikud.VisibleHand.hand_cards:type_name -> ilutulestikud.VisibleCard
+	17, // 4: ilutulestikud.VisibleHand.knowledge_of_own_hand:type_name -> ilutulestikud.CardFromBehind
+	14, // 5: ilutulestikud.GameView.chat_log:type_name -> ilutulestikud.LogMessage
+	14, // 6: ilutulestikud.GameView.action_log:type_name -> ilutulestikud.LogMessage
+	16, // 7: ilutulestikud.GameView.played_cards:type_name -> ilutulestikud.CardPile
+	15, // 8: ilutulestikud.GameView.discarded_cards:type_name -> ilutulestikud.VisibleCard
+	18, // 9: ilutulestikud.GameView.hands_before_this_player:type_name -> ilutulestikud.VisibleHand
+	17, // 10: ilutulestikud.GameView.hand_of_this_player:type_name -> ilutulestikud.CardFromBehind
+	18, // 11: ilutulestikud.GameView.hands_after_this_player:type_name -> ilutulestikud.VisibleHand
+	1,  // 12: ilutulestikud.Ilutulestikud.LogIn:input_type -> ilutulestikud.PlayerCredentials
+	3,  // 13: ilutulestikud.Ilutulestikud.ListGames:input_type -> ilutulestikud.PlayerIndication
+	3,  // 14: ilutulestikud.Ilutulestikud.ListInvitations:input_type -> ilutulestikud.PlayerIndication
+	8,  // 15: ilutulestikud.Ilutulestikud.CreateGame:input_type -> ilutulestikud.GameDefinition
+	9,  // 16: ilutulestikud.Ilutulestikud.AcceptInvitation:input_type -> ilutulestikud.PlayerInGameIndication
+	9,  // 17: ilutulestikud.Ilutulestikud.GetGame:input_type -> ilutulestikud.PlayerInGameIndication
+	10, // 18: ilutulestikud.Ilutulestikud.RecordChatMessage:input_type -> ilutulestikud.PlayerChatMessage
+	11, // 19: ilutulestikud.Ilutulestikud.TakeTurnByDiscarding:input_type -> ilutulestikud.PlayerCardIndication
+	11, // 20: ilutulestikud.Ilutulestikud.TakeTurnByAttemptingToPlay:input_type -> ilutulestikud.PlayerCardIndication
+	12, // 21: ilutulestikud.Ilutulestikud.TakeTurnByHintingColor:input_type -> ilutulestikud.PlayerColorHint
+	13, // 22: ilutulestikud.Ilutulestikud.TakeTurnByHintingNumber:input_type -> ilutulestikud.PlayerIndexHint
+	2,  // 23: ilutulestikud.Ilutulestikud.LogIn:output_type -> ilutulestikud.SessionToken
+	5,  // 24: ilutulestikud.Ilutulestikud.ListGames:output_type -> ilutulestikud.TurnSummaryList
+	7,  // 25: ilutulestikud.Ilutulestikud.ListInvitations:output_type -> ilutulestikud.InvitationList
+	0,  // 26: ilutulestikud.Ilutulestikud.CreateGame:output_type -> ilutulestikud.Acknowledgement
+	0,  // 27: ilutulestikud.Ilutulestikud.AcceptInvitation:output_type -> ilutulestikud.Acknowledgement
+	19, // 28: ilutulestikud.Ilutulestikud.GetGame:output_type -> ilutulestikud.GameView
+	19, // 29: ilutulestikud.Ilutulestikud.RecordChatMessage:output_type -> ilutulestikud.GameView
+	19, // 30: ilutulestikud.Ilutulestikud.TakeTurnByDiscarding:output_type -> ilutulestikud.GameView
+	19, // 31: ilutulestikud.Ilutulestikud.TakeTurnByAttemptingToPlay:output_type -> ilutulestikud.GameView
+	19, // 32: ilutulestikud.Ilutulestikud.TakeTurnByHintingColor:output_type -> ilutulestikud.GameView
+	19, // 33: ilutulestikud.Ilutulestikud.TakeTurnByHintingNumber:output_type -> ilutulestikud.GameView
+	23, // [23:34] is the sub-list for method output_type
+	12, // [12:23] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_ilutulestikud_proto_init() }
+func file_ilutulestikud_proto_init() {
+	if File_ilutulestikud_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ilutulestikud_proto_rawDesc), len(file_ilutulestikud_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ilutulestikud_proto_goTypes,
+		DependencyIndexes: file_ilutulestikud_proto_depIdxs,
+		MessageInfos:      file_ilutulestikud_proto_msgTypes,
+	}.Build()
+	File_ilutulestikud_proto = out.File
+	file_ilutulestikud_proto_goTypes = nil
+	file_ilutulestikud_proto_depIdxs = nil
+}
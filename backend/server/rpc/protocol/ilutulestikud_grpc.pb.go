@@ -0,0 +1,533 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.28.3
+// source: ilutulestikud.proto
+
+package protocol
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Ilutulestikud_LogIn_FullMethodName                      = "/ilutulestikud.Ilutulestikud/LogIn"
+	Ilutulestikud_ListGames_FullMethodName                  = "/ilutulestikud.Ilutulestikud/ListGames"
+	Ilutulestikud_ListInvitations_FullMethodName            = "/ilutulestikud.Ilutulestikud/ListInvitations"
+	Ilutulestikud_CreateGame_FullMethodName                 = "/ilutulestikud.Ilutulestikud/CreateGame"
+	Ilutulestikud_AcceptInvitation_FullMethodName           = "/ilutulestikud.Ilutulestikud/AcceptInvitation"
+	Ilutulestikud_GetGame_FullMethodName                    = "/ilutulestikud.Ilutulestikud/GetGame"
+	Ilutulestikud_RecordChatMessage_FullMethodName          = "/ilutulestikud.Ilutulestikud/RecordChatMessage"
+	Ilutulestikud_TakeTurnByDiscarding_FullMethodName       = "/ilutulestikud.Ilutulestikud/TakeTurnByDiscarding"
+	Ilutulestikud_TakeTurnByAttemptingToPlay_FullMethodName = "/ilutulestikud.Ilutulestikud/TakeTurnByAttemptingToPlay"
+	Ilutulestikud_TakeTurnByHintingColor_FullMethodName     = "/ilutulestikud.Ilutulestikud/TakeTurnByHintingColor"
+	Ilutulestikud_TakeTurnByHintingNumber_FullMethodName    = "/ilutulestikud.Ilutulestikud/TakeTurnByHintingNumber"
+)
+
+// IlutulestikudClient is the client API for Ilutulestikud service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Ilutulestikud offers the games of the backend to tools and bots. The messages match the
+// structs of github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing field for
+// field, with the fields of embedded structs given directly, as they are in the JSON.
+// Players are referred to by their stable identifiers, as in the JSON, and there are no
+// identifiers encoded as URI segments. Every call apart from LogIn must carry the session
+// token from LogIn as "Bearer <token>" in the "authorization" metadata.
+type IlutulestikudClient interface {
+	// LogIn checks the credentials of a player and returns a session token.
+	LogIn(ctx context.Context, in *PlayerCredentials, opts ...grpc.CallOption) (*SessionToken, error)
+	// ListGames summarizes every game of the given player.
+	ListGames(ctx context.Context, in *PlayerIndication, opts ...grpc.CallOption) (*TurnSummaryList, error)
+	// ListInvitations lists the invitations which the given player has not yet answered.
+	ListInvitations(ctx context.Context, in *PlayerIndication, opts ...grpc.CallOption) (*InvitationList, error)
+	// CreateGame invites the given players to a new game, with the first as the host.
+	CreateGame(ctx context.Context, in *GameDefinition, opts ...grpc.CallOption) (*Acknowledgement, error)
+	// AcceptInvitation accepts the invitation of the given player to the given game.
+	AcceptInvitation(ctx context.Context, in *PlayerInGameIndication, opts ...grpc.CallOption) (*Acknowledgement, error)
+	// GetGame returns the given game as the given player sees it.
+	GetGame(ctx context.Context, in *PlayerInGameIndication, opts ...grpc.CallOption) (*GameView, error)
+	// RecordChatMessage records a chat message and returns the game as the player sees it.
+	RecordChatMessage(ctx context.Context, in *PlayerChatMessage, opts ...grpc.CallOption) (*GameView, error)
+	// TakeTurnByDiscarding takes the turn of the player and returns the game as the player
+	// then sees it, as do the other calls which take turns.
+	TakeTurnByDiscarding(ctx context.Context, in *PlayerCardIndication, opts ...grpc.CallOption) (*GameView, error)
+	TakeTurnByAttemptingToPlay(ctx context.Context, in *PlayerCardIndication, opts ...grpc.CallOption) (*GameView, error)
+	TakeTurnByHintingColor(ctx context.Context, in *PlayerColorHint, opts ...grpc.CallOption) (*GameView, error)
+	TakeTurnByHintingNumber(ctx context.Context, in *PlayerIndexHint, opts ...grpc.CallOption) (*GameView, error)
+}
+
+type ilutulestikudClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIlutulestikudClient(cc grpc.ClientConnInterface) IlutulestikudClient {
+	return &ilutulestikudClient{cc}
+}
+
+func (c *ilutulestikudClient) LogIn(ctx context.Context, in *PlayerCredentials, opts ...grpc.CallOption) (*SessionToken, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionToken)
+	err := c.cc.Invoke(ctx, Ilutulestikud_LogIn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) ListGames(ctx context.Context, in *PlayerIndication, opts ...grpc.CallOption) (*TurnSummaryList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TurnSummaryList)
+	err := c.cc.Invoke(ctx, Ilutulestikud_ListGames_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) ListInvitations(ctx context.Context, in *PlayerIndication, opts ...grpc.CallOption) (*InvitationList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InvitationList)
+	err := c.cc.Invoke(ctx, Ilutulestikud_ListInvitations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) CreateGame(ctx context.Context, in *GameDefinition, opts ...grpc.CallOption) (*Acknowledgement, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Acknowledgement)
+	err := c.cc.Invoke(ctx, Ilutulestikud_CreateGame_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) AcceptInvitation(ctx context.Context, in *PlayerInGameIndication, opts ...grpc.CallOption) (*Acknowledgement, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Acknowledgement)
+	err := c.cc.Invoke(ctx, Ilutulestikud_AcceptInvitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) GetGame(ctx context.Context, in *PlayerInGameIndication, opts ...grpc.CallOption) (*GameView, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameView)
+	err := c.cc.Invoke(ctx, Ilutulestikud_GetGame_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) RecordChatMessage(ctx context.Context, in *PlayerChatMessage, opts ...grpc.CallOption) (*GameView, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameView)
+	err := c.cc.Invoke(ctx, Ilutulestikud_RecordChatMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) TakeTurnByDiscarding(ctx context.Context, in *PlayerCardIndication, opts ...grpc.CallOption) (*GameView, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameView)
+	err := c.cc.Invoke(ctx, Ilutulestikud_TakeTurnByDiscarding_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) TakeTurnByAttemptingToPlay(ctx context.Context, in *PlayerCardIndication, opts ...grpc.CallOption) (*GameView, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameView)
+	err := c.cc.Invoke(ctx, Ilutulestikud_TakeTurnByAttemptingToPlay_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) TakeTurnByHintingColor(ctx context.Context, in *PlayerColorHint, opts ...grpc.CallOption) (*GameView, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameView)
+	err := c.cc.Invoke(ctx, Ilutulestikud_TakeTurnByHintingColor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ilutulestikudClient) TakeTurnByHintingNumber(ctx context.Context, in *PlayerIndexHint, opts ...grpc.CallOption) (*GameView, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameView)
+	err := c.cc.Invoke(ctx, Ilutulestikud_TakeTurnByHintingNumber_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IlutulestikudServer is the server API for Ilutulestikud service.
+// All implementations must embed UnimplementedIlutulestikudServer
+// for forward compatibility.
+//
+// Ilutulestikud offers the games of the backend to tools and bots. The messages match the
+// structs of github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing field for
+// field, with the fields of embedded structs given directly, as they are in the JSON.
+// Players are referred to by their stable identifiers, as in the JSON, and there are no
+// identifiers encoded as URI segments. Every call apart from LogIn must carry the session
+// token from LogIn as "Bearer <token>" in the "authorization" metadata.
+type IlutulestikudServer interface {
+	// LogIn checks the credentials of a player and returns a session token.
+	LogIn(context.Context, *PlayerCredentials) (*SessionToken, error)
+	// ListGames summarizes every game of the given player.
+	ListGames(context.Context, *PlayerIndication) (*TurnSummaryList, error)
+	// ListInvitations lists the invitations which the given player has not yet answered.
+	ListInvitations(context.Context, *PlayerIndication) (*InvitationList, error)
+	// CreateGame invites the given players to a new game, with the first as the host.
+	CreateGame(context.Context, *GameDefinition) (*Acknowledgement, error)
+	// AcceptInvitation accepts the invitation of the given player to the given game.
+	AcceptInvitation(context.Context, *PlayerInGameIndication) (*Acknowledgement, error)
+	// GetGame returns the given game as the given player sees it.
+	GetGame(context.Context, *PlayerInGameIndication) (*GameView, error)
+	// RecordChatMessage records a chat message and returns the game as the player sees it.
+	RecordChatMessage(context.Context, *PlayerChatMessage) (*GameView, error)
+	// TakeTurnByDiscarding takes the turn of the player and returns the game as the player
+	// then sees it, as do the other calls which take turns.
+	TakeTurnByDiscarding(context.Context, *PlayerCardIndication) (*GameView, error)
+	TakeTurnByAttemptingToPlay(context.Context, *PlayerCardIndication) (*GameView, error)
+	TakeTurnByHintingColor(context.Context, *PlayerColorHint) (*GameView, error)
+	TakeTurnByHintingNumber(context.Context, *PlayerIndexHint) (*GameView, error)
+	mustEmbedUnimplementedIlutulestikudServer()
+}
+
+// UnimplementedIlutulestikudServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIlutulestikudServer struct{}
+
+func (UnimplementedIlutulestikudServer) LogIn(context.Context, *PlayerCredentials) (*SessionToken, error) {
+	return nil, status.Error(codes.Unimplemented, "method LogIn not implemented")
+}
+func (UnimplementedIlutulestikudServer) ListGames(context.Context, *PlayerIndication) (*TurnSummaryList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListGames not implemented")
+}
+func (UnimplementedIlutulestikudServer) ListInvitations(context.Context, *PlayerIndication) (*InvitationList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListInvitations not implemented")
+}
+func (UnimplementedIlutulestikudServer) CreateGame(context.Context, *GameDefinition) (*Acknowledgement, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateGame not implemented")
+}
+func (UnimplementedIlutulestikudServer) AcceptInvitation(context.Context, *PlayerInGameIndication) (*Acknowledgement, error) {
+	return nil, status.Error(codes.Unimplemented, "method AcceptInvitation not implemented")
+}
+func (UnimplementedIlutulestikudServer) GetGame(context.Context, *PlayerInGameIndication) (*GameView, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetGame not implemented")
+}
+func (UnimplementedIlutulestikudServer) RecordChatMessage(context.Context, *PlayerChatMessage) (*GameView, error) {
+	return nil, status.Error(codes.Unimplemented, "method RecordChatMessage not implemented")
+}
+func (UnimplementedIlutulestikudServer) TakeTurnByDiscarding(context.Context, *PlayerCardIndication) (*GameView, error) {
+	return nil, status.Error(codes.Unimplemented, "method TakeTurnByDiscarding not implemented")
+}
+func (UnimplementedIlutulestikudServer) TakeTurnByAttemptingToPlay(context.Context, *PlayerCardIndication) (*GameView, error) {
+	return nil, status.Error(codes.Unimplemented, "method TakeTurnByAttemptingToPlay not implemented")
+}
+func (UnimplementedIlutulestikudServer) TakeTurnByHintingColor(context.Context, *PlayerColorHint) (*GameView, error) {
+	return nil, status.Error(codes.Unimplemented, "method TakeTurnByHintingColor not implemented")
+}
+func (UnimplementedIlutulestikudServer) TakeTurnByHintingNumber(context.Context, *PlayerIndexHint) (*GameView, error) {
+	return nil, status.Error(codes.Unimplemented, "method TakeTurnByHintingNumber not implemented")
+}
+func (UnimplementedIlutulestikudServer) mustEmbedUnimplementedIlutulestikudServer() {}
+func (UnimplementedIlutulestikudServer) testEmbeddedByValue()                       {}
+
+// UnsafeIlutulestikudServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IlutulestikudServer will
+// result in compilation errors.
+type UnsafeIlutulestikudServer interface {
+	mustEmbedUnimplementedIlutulestikudServer()
+}
+
+func RegisterIlutulestikudServer(s grpc.ServiceRegistrar, srv IlutulestikudServer) {
+	// If the following call panics, it indicates UnimplementedIlutulestikudServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Ilutulestikud_ServiceDesc, srv)
+}
+
+func _Ilutulestikud_LogIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerCredentials)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).LogIn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_LogIn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).LogIn(ctx, req.(*PlayerCredentials))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_ListGames_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerIndication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).ListGames(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_ListGames_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).ListGames(ctx, req.(*PlayerIndication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_ListInvitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerIndication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).ListInvitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_ListInvitations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).ListInvitations(ctx, req.(*PlayerIndication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_CreateGame_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GameDefinition)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).CreateGame(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_CreateGame_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).CreateGame(ctx, req.(*GameDefinition))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_AcceptInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerInGameIndication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).AcceptInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_AcceptInvitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).AcceptInvitation(ctx, req.(*PlayerInGameIndication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_GetGame_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerInGameIndication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).GetGame(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_GetGame_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).GetGame(ctx, req.(*PlayerInGameIndication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_RecordChatMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerChatMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).RecordChatMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_RecordChatMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).RecordChatMessage(ctx, req.(*PlayerChatMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_TakeTurnByDiscarding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerCardIndication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).TakeTurnByDiscarding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_TakeTurnByDiscarding_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).TakeTurnByDiscarding(ctx, req.(*PlayerCardIndication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_TakeTurnByAttemptingToPlay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerCardIndication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).TakeTurnByAttemptingToPlay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_TakeTurnByAttemptingToPlay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).TakeTurnByAttemptingToPlay(ctx, req.(*PlayerCardIndication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_TakeTurnByHintingColor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerColorHint)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).TakeTurnByHintingColor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_TakeTurnByHintingColor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).TakeTurnByHintingColor(ctx, req.(*PlayerColorHint))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ilutulestikud_TakeTurnByHintingNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerIndexHint)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IlutulestikudServer).TakeTurnByHintingNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Ilutulestikud_TakeTurnByHintingNumber_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IlutulestikudServer).TakeTurnByHintingNumber(ctx, req.(*PlayerIndexHint))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Ilutulestikud_ServiceDesc is the grpc.ServiceDesc for Ilutulestikud service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Ilutulestikud_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ilutulestikud.Ilutulestikud",
+	HandlerType: (*IlutulestikudServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "LogIn",
+			Handler:    _Ilutulestikud_LogIn_Handler,
+		},
+		{
+			MethodName: "ListGames",
+			Handler:    _Ilutulestikud_ListGames_Handler,
+		},
+		{
+			MethodName: "ListInvitations",
+			Handler:    _Ilutulestikud_ListInvitations_Handler,
+		},
+		{
+			MethodName: "CreateGame",
+			Handler:    _Ilutulestikud_CreateGame_Handler,
+		},
+		{
+			MethodName: "AcceptInvitation",
+			Handler:    _Ilutulestikud_AcceptInvitation_Handler,
+		},
+		{
+			MethodName: "GetGame",
+			Handler:    _Ilutulestikud_GetGame_Handler,
+		},
+		{
+			MethodName: "RecordChatMessage",
+			Handler:    _Ilutulestikud_RecordChatMessage_Handler,
+		},
+		{
+			MethodName: "TakeTurnByDiscarding",
+			Handler:    _Ilutulestikud_TakeTurnByDiscarding_Handler,
+		},
+		{
+			MethodName: "TakeTurnByAttemptingToPlay",
+			Handler:    _Ilutulestikud_TakeTurnByAttemptingToPlay_Handler,
+		},
+		{
+			MethodName: "TakeTurnByHintingColor",
+			Handler:    _Ilutulestikud_TakeTurnByHintingColor_Handler,
+		},
+		{
+			MethodName: "TakeTurnByHintingNumber",
+			Handler:    _Ilutulestikud_TakeTurnByHintingNumber_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ilutulestikud.proto",
+}
@@ -0,0 +1,511 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/benoleary/ilutulestikud/backend/failure"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	game_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	player_endpoint "github.com/benoleary/ilutulestikud/backend/server/endpoint/player"
+	"github.com/benoleary/ilutulestikud/backend/server/rpc/protocol"
+)
+
+const (
+	// authorizationKey is the key of the metadata which holds the session token, which
+	// gRPC requires to be in lower case.
+	authorizationKey = "authorization"
+
+	// ErrorCodeKey is the key of the trailing metadata which holds the code of the error
+	// from github.com/benoleary/ilutulestikud/backend/failure when a call fails, which
+	// distinguishes errors which share a gRPC status code.
+	ErrorCodeKey = "error-code"
+)
+
+// grpcCodesForCodes maps the codes of errors to the gRPC status codes which should be
+// returned with them, as parsing.StatusForError does for HTTP statuses. Codes which are
+// not in the map get the fallback code given to errorStatus.
+var grpcCodesForCodes = map[string]codes.Code{
+	failure.CodeNotAuthenticated:     codes.Unauthenticated,
+	failure.CodeIncorrectCredentials: codes.Unauthenticated,
+	failure.CodeForbidden:            codes.PermissionDenied,
+	failure.CodeNotParticipant:       codes.PermissionDenied,
+	failure.CodeNotInvited:           codes.PermissionDenied,
+	failure.CodeSpectatingNotAllowed: codes.PermissionDenied,
+	failure.CodeUnknownPlayer:        codes.NotFound,
+	failure.CodeUnknownGame:          codes.NotFound,
+	failure.CodeDeletedPlayer:        codes.NotFound,
+	failure.CodeNameTaken:            codes.AlreadyExists,
+	failure.CodePlayerInGame:         codes.FailedPrecondition,
+	failure.CodeNotYourTurn:          codes.FailedPrecondition,
+	failure.CodeGameFinished:         codes.FailedPrecondition,
+	failure.CodeGameNotFinished:      codes.FailedPrecondition,
+	failure.CodeNoHintsLeft:          codes.FailedPrecondition,
+	failure.CodeNoOpenSeats:          codes.FailedPrecondition,
+	failure.CodeTurnConflict:         codes.Aborted,
+	failure.CodeUnknownRuleset:       codes.InvalidArgument,
+	failure.CodeHintToSelf:           codes.InvalidArgument,
+	failure.CodeInvalidCardIndex:     codes.InvalidArgument,
+}
+
+// Service is a struct meant to encapsulate all the state co-ordinating interaction
+// with the players and games through gRPC, for tools and bots which would rather use
+// typed calls than the HTTP endpoints. It shares the state collections of the HTTP
+// endpoints, so that games played through either are the same games.
+// It implements github.com/benoleary/ilutulestikud/backend/server/rpc/protocol.IlutulestikudServer.
+type Service struct {
+	protocol.UnimplementedIlutulestikudServer
+	gameCollection     game_endpoint.StateCollection
+	playerCollection   player_endpoint.StateCollection
+	playerAuthorizer   player_endpoint.PlayerAuthorizer
+	sessionTokenSigner SessionTokenSigner
+	gameViewConverter  GameViewConverter
+}
+
+// New creates a new Service around the given state collections, which checks that
+// calls made on behalf of a player come with a session token for that player signed
+// by the given signer, and which uses the player state collection to determine who is
+// an administrator, as server.New does for the HTTP endpoints.
+func New(
+	collectionOfGames game_endpoint.StateCollection,
+	collectionOfPlayers player_endpoint.StateCollection,
+	signerOfTokens SessionTokenSigner) *Service {
+	playerAuthorizer := authentication.NewContextAuthorizer(collectionOfPlayers)
+
+	// The games are converted by the handler of the HTTP endpoints so that they are sent
+	// in the same form. Games are never referred to by URI segments here, so the
+	// translator for segments is irrelevant.
+	gameHandler :=
+		game_endpoint.New(
+			collectionOfGames,
+			&parsing.NoOperationTranslator{},
+			playerAuthorizer,
+			collectionOfPlayers,
+			collectionOfPlayers)
+
+	return &Service{
+		gameCollection:     collectionOfGames,
+		playerCollection:   collectionOfPlayers,
+		playerAuthorizer:   playerAuthorizer,
+		sessionTokenSigner: signerOfTokens,
+		gameViewConverter:  gameHandler,
+	}
+}
+
+// NewServer creates a gRPC server with the given options which serves the given
+// service, where the session token in the metadata of each call identifies the player
+// who makes the call. Calls without a session token are passed on without an
+// authenticated player, as logging in needs no token, and calls with a session token
+// which is not valid are rejected.
+func NewServer(serviceToServe *Service, serverOptions ...grpc.ServerOption) *grpc.Server {
+	serverOptions =
+		append(serverOptions, grpc.UnaryInterceptor(serviceToServe.authenticateCall))
+	grpcServer := grpc.NewServer(serverOptions...)
+	protocol.RegisterIlutulestikudServer(grpcServer, serviceToServe)
+
+	return grpcServer
+}
+
+// LogIn checks the given credentials and returns a session token for the player.
+func (service *Service) LogIn(
+	callContext context.Context,
+	playerCredentials *protocol.PlayerCredentials) (*protocol.SessionToken, error) {
+	playerIdentifier, errorFromAuthentication :=
+		service.playerCollection.Authenticate(
+			callContext,
+			playerCredentials.GetName(),
+			playerCredentials.GetPassword())
+	if errorFromAuthentication != nil {
+		return nil, errorStatus(callContext, errorFromAuthentication, codes.Unauthenticated)
+	}
+
+	sessionToken, errorFromIssue := service.sessionTokenSigner.IssueToken(playerIdentifier)
+	if errorFromIssue != nil {
+		return nil, errorStatus(callContext, errorFromIssue, codes.Internal)
+	}
+
+	return &protocol.SessionToken{
+		PlayerIdentifier: playerIdentifier,
+		PlayerName:       playerCredentials.GetName(),
+		Token:            sessionToken,
+	}, nil
+}
+
+// ListGames summarizes the games of the given player, as long as the call comes from
+// that player.
+func (service *Service) ListGames(
+	callContext context.Context,
+	playerIndication *protocol.PlayerIndication) (*protocol.TurnSummaryList, error) {
+	playerIdentifier := playerIndication.GetPlayerIdentifier()
+	errorFromAuthorization :=
+		service.playerAuthorizer.AuthorizeActingPlayer(callContext, playerIdentifier)
+	if errorFromAuthorization != nil {
+		return nil, errorStatus(callContext, errorFromAuthorization, codes.InvalidArgument)
+	}
+
+	allGamesWithPlayer, errorFromView :=
+		service.gameCollection.ViewAllWithPlayer(callContext, playerIdentifier)
+	if errorFromView != nil {
+		return nil, errorStatus(callContext, errorFromView, codes.InvalidArgument)
+	}
+
+	turnSummaries := make([]*protocol.TurnSummary, 0, len(allGamesWithPlayer))
+	for _, gameView := range allGamesWithPlayer {
+		_, playerTurnIndex, _ := gameView.CurrentTurnOrder()
+		turnSummaries = append(turnSummaries, &protocol.TurnSummary{
+			GameName:     gameView.GameName(),
+			IsPlayerTurn: playerTurnIndex == 0,
+			Version:      int64(gameView.Version()),
+		})
+	}
+
+	return &protocol.TurnSummaryList{TurnSummaries: turnSummaries}, nil
+}
+
+// ListInvitations lists the invitations which the given player has not yet answered,
+// as long as the call comes from that player.
+func (service *Service) ListInvitations(
+	callContext context.Context,
+	playerIndication *protocol.PlayerIndication) (*protocol.InvitationList, error) {
+	playerIdentifier := playerIndication.GetPlayerIdentifier()
+	errorFromAuthorization :=
+		service.playerAuthorizer.AuthorizeActingPlayer(callContext, playerIdentifier)
+	if errorFromAuthorization != nil {
+		return nil, errorStatus(callContext, errorFromAuthorization, codes.InvalidArgument)
+	}
+
+	invitationLobbies, errorFromView :=
+		service.gameCollection.ViewInvitationsForPlayer(callContext, playerIdentifier)
+	if errorFromView != nil {
+		return nil, errorStatus(callContext, errorFromView, codes.InvalidArgument)
+	}
+
+	invitationSummaries := make([]*protocol.InvitationSummary, 0, len(invitationLobbies))
+	for _, invitationLobby := range invitationLobbies {
+		rulesetDescription := ""
+		lobbyRuleset, errorFromRuleset :=
+			game.RulesetFromIdentifier(invitationLobby.RulesetIdentifier)
+		if errorFromRuleset == nil {
+			rulesetDescription = lobbyRuleset.FrontendDescription()
+		}
+
+		invitationSummaries = append(invitationSummaries, &protocol.InvitationSummary{
+			GameName:            invitationLobby.GameName,
+			RulesetDescription:  rulesetDescription,
+			HostName:            invitationLobby.HostName,
+			InvitedPlayerNames:  invitationLobby.InvitedPlayerNames,
+			AcceptedPlayerNames: invitationLobby.SeatedPlayerNames,
+		})
+	}
+
+	return &protocol.InvitationList{Invitations: invitationSummaries}, nil
+}
+
+// CreateGame invites the players of the given definition to a new game, as long as the
+// call comes from the first of them, who is the host. As for the HTTP endpoint, if the
+// definition names a group, only the host should be listed and the members of the
+// group of the host with that name are invited along with the host.
+func (service *Service) CreateGame(
+	callContext context.Context,
+	gameDefinition *protocol.GameDefinition) (*protocol.Acknowledgement, error) {
+	invitedPlayers := gameDefinition.GetPlayerNames()
+	hostName := ""
+	if len(invitedPlayers) > 0 {
+		hostName = invitedPlayers[0]
+	}
+
+	errorFromAuthorization :=
+		service.playerAuthorizer.AuthorizeActingPlayer(callContext, hostName)
+	if errorFromAuthorization != nil {
+		return nil, errorStatus(callContext, errorFromAuthorization, codes.InvalidArgument)
+	}
+
+	gameRuleset, errorFromRuleset :=
+		game.RulesetFromIdentifier(int(gameDefinition.GetRulesetIdentifier()))
+	if errorFromRuleset != nil {
+		return nil, errorStatus(callContext, errorFromRuleset, codes.InvalidArgument)
+	}
+
+	if gameDefinition.GetGroupName() != "" {
+		playersFromGroup, errorFromGroup :=
+			service.hostAndGroupMembers(callContext, invitedPlayers, gameDefinition.GetGroupName())
+		if errorFromGroup != nil {
+			return nil, errorStatus(callContext, errorFromGroup, codes.InvalidArgument)
+		}
+
+		invitedPlayers = playersFromGroup
+	}
+
+	errorFromInvitation :=
+		service.gameCollection.InviteToNew(
+			callContext,
+			gameDefinition.GetGameName(),
+			gameRuleset,
+			invitedPlayers)
+	if errorFromInvitation != nil {
+		return nil, errorStatus(callContext, errorFromInvitation, codes.InvalidArgument)
+	}
+
+	return &protocol.Acknowledgement{}, nil
+}
+
+// AcceptInvitation accepts the invitation of the given player to the given game, as
+// long as the call comes from that player.
+func (service *Service) AcceptInvitation(
+	callContext context.Context,
+	playerInGame *protocol.PlayerInGameIndication) (*protocol.Acknowledgement, error) {
+	errorFromAuthorization :=
+		service.playerAuthorizer.AuthorizeActingPlayer(callContext, playerInGame.GetPlayerName())
+	if errorFromAuthorization != nil {
+		return nil, errorStatus(callContext, errorFromAuthorization, codes.InvalidArgument)
+	}
+
+	errorFromAccepting :=
+		service.gameCollection.AcceptInvitation(
+			callContext,
+			playerInGame.GetGameName(),
+			playerInGame.GetPlayerName())
+	if errorFromAccepting != nil {
+		return nil, errorStatus(callContext, errorFromAccepting, codes.InvalidArgument)
+	}
+
+	return &protocol.Acknowledgement{}, nil
+}
+
+// GetGame returns the given game as the given player sees it, as long as the call
+// comes from that player.
+func (service *Service) GetGame(
+	callContext context.Context,
+	playerInGame *protocol.PlayerInGameIndication) (*protocol.GameView, error) {
+	errorFromAuthorization :=
+		service.playerAuthorizer.AuthorizeActingPlayer(callContext, playerInGame.GetPlayerName())
+	if errorFromAuthorization != nil {
+		return nil, errorStatus(callContext, errorFromAuthorization, codes.InvalidArgument)
+	}
+
+	return service.gameAsSeenBy(callContext, playerInGame.GetGameName(), playerInGame.GetPlayerName())
+}
+
+// RecordChatMessage records the given chat message from the given player in the given
+// game, and returns the game as the player then sees it.
+func (service *Service) RecordChatMessage(
+	callContext context.Context,
+	chatMessage *protocol.PlayerChatMessage) (*protocol.GameView, error) {
+	return service.performAction(
+		callContext,
+		chatMessage.GetGameName(),
+		chatMessage.GetPlayerName(),
+		func(actionExecutor game.ExecutorForPlayer) error {
+			return actionExecutor.RecordChatMessage(callContext, chatMessage.GetChatMessage())
+		})
+}
+
+// TakeTurnByDiscarding takes the turn of the given player in the given game by
+// discarding the indicated card, and returns the game as the player then sees it.
+func (service *Service) TakeTurnByDiscarding(
+	callContext context.Context,
+	cardIndication *protocol.PlayerCardIndication) (*protocol.GameView, error) {
+	return service.performAction(
+		callContext,
+		cardIndication.GetGameName(),
+		cardIndication.GetPlayerName(),
+		func(actionExecutor game.ExecutorForPlayer) error {
+			return actionExecutor.TakeTurnByDiscarding(
+				callContext,
+				int(cardIndication.GetExpectedTurn()),
+				int(cardIndication.GetCardIndex()))
+		})
+}
+
+// TakeTurnByAttemptingToPlay takes the turn of the given player in the given game by
+// attempting to play the indicated card, and returns the game as the player then sees
+// it.
+func (service *Service) TakeTurnByAttemptingToPlay(
+	callContext context.Context,
+	cardIndication *protocol.PlayerCardIndication) (*protocol.GameView, error) {
+	return service.performAction(
+		callContext,
+		cardIndication.GetGameName(),
+		cardIndication.GetPlayerName(),
+		func(actionExecutor game.ExecutorForPlayer) error {
+			return actionExecutor.TakeTurnByPlaying(
+				callContext,
+				int(cardIndication.GetExpectedTurn()),
+				int(cardIndication.GetCardIndex()))
+		})
+}
+
+// TakeTurnByHintingColor takes the turn of the given player in the given game by
+// hinting a color suit to the receiver, and returns the game as the player then sees
+// it.
+func (service *Service) TakeTurnByHintingColor(
+	callContext context.Context,
+	colorHint *protocol.PlayerColorHint) (*protocol.GameView, error) {
+	return service.performAction(
+		callContext,
+		colorHint.GetGameName(),
+		colorHint.GetPlayerName(),
+		func(actionExecutor game.ExecutorForPlayer) error {
+			return actionExecutor.TakeTurnByHintingColor(
+				callContext,
+				int(colorHint.GetExpectedTurn()),
+				colorHint.GetReceiverName(),
+				colorHint.GetHintedColor())
+		})
+}
+
+// TakeTurnByHintingNumber takes the turn of the given player in the given game by
+// hinting a sequence index to the receiver, and returns the game as the player then
+// sees it.
+func (service *Service) TakeTurnByHintingNumber(
+	callContext context.Context,
+	indexHint *protocol.PlayerIndexHint) (*protocol.GameView, error) {
+	return service.performAction(
+		callContext,
+		indexHint.GetGameName(),
+		indexHint.GetPlayerName(),
+		func(actionExecutor game.ExecutorForPlayer) error {
+			return actionExecutor.TakeTurnByHintingIndex(
+				callContext,
+				int(indexHint.GetExpectedTurn()),
+				indexHint.GetReceiverName(),
+				int(indexHint.GetHintedNumber()))
+		})
+}
+
+// authenticateCall passes the call on with the context carrying the identifier of the
+// player identified by the session token in the metadata of the call, if there is one,
+// or rejects the call if the session token is not valid.
+func (service *Service) authenticateCall(
+	callContext context.Context,
+	callRequest interface{},
+	callInformation *grpc.UnaryServerInfo,
+	callHandler grpc.UnaryHandler) (interface{}, error) {
+	callMetadata, _ := metadata.FromIncomingContext(callContext)
+	authorizationValues := callMetadata.Get(authorizationKey)
+	if len(authorizationValues) == 0 {
+		return callHandler(callContext, callRequest)
+	}
+
+	bearerPrefix := "Bearer "
+	if !strings.HasPrefix(authorizationValues[0], bearerPrefix) {
+		return nil, status.Error(
+			codes.Unauthenticated,
+			"Authorization metadata must be a bearer token")
+	}
+
+	playerIdentifier, errorFromToken :=
+		service.sessionTokenSigner.PlayerFromToken(
+			strings.TrimPrefix(authorizationValues[0], bearerPrefix))
+	if errorFromToken != nil {
+		return nil, errorStatus(callContext, errorFromToken, codes.Unauthenticated)
+	}
+
+	return callHandler(
+		authentication.ContextWithAuthenticatedPlayer(callContext, playerIdentifier),
+		callRequest)
+}
+
+// performAction performs the given action on the given game for the given player, as
+// long as the call comes from that player, and returns the game as the player then
+// sees it.
+func (service *Service) performAction(
+	callContext context.Context,
+	gameName string,
+	playerName string,
+	gameAction func(game.ExecutorForPlayer) error) (*protocol.GameView, error) {
+	errorFromAuthorization :=
+		service.playerAuthorizer.AuthorizeActingPlayer(callContext, playerName)
+	if errorFromAuthorization != nil {
+		return nil, errorStatus(callContext, errorFromAuthorization, codes.InvalidArgument)
+	}
+
+	actionExecutor, errorFromExecutor :=
+		service.gameCollection.ExecuteAction(callContext, gameName, playerName)
+	if errorFromExecutor != nil {
+		return nil, errorStatus(callContext, errorFromExecutor, codes.InvalidArgument)
+	}
+
+	errorFromAction := gameAction(actionExecutor)
+	if errorFromAction != nil {
+		return nil, errorStatus(callContext, errorFromAction, codes.InvalidArgument)
+	}
+
+	return service.gameAsSeenBy(callContext, gameName, playerName)
+}
+
+// gameAsSeenBy returns the given game as the given player sees it, converted first
+// into the form which is sent by the HTTP endpoints and then into the message.
+func (service *Service) gameAsSeenBy(
+	callContext context.Context,
+	gameName string,
+	playerName string) (*protocol.GameView, error) {
+	gameView, errorFromView :=
+		service.gameCollection.ViewState(callContext, gameName, playerName)
+	if errorFromView != nil {
+		return nil, errorStatus(callContext, errorFromView, codes.Internal)
+	}
+
+	endpointObject, errorFromConversion :=
+		service.gameViewConverter.GameViewForFrontend(callContext, gameView, playerName)
+	if errorFromConversion != nil {
+		return nil, errorStatus(callContext, errorFromConversion, codes.Internal)
+	}
+
+	return gameViewForProtocol(endpointObject), nil
+}
+
+// hostAndGroupMembers returns the given host followed by the members of the group of
+// the host with the given name, where the given players should be only the host.
+func (service *Service) hostAndGroupMembers(
+	callContext context.Context,
+	givenPlayers []string,
+	groupName string) ([]string, error) {
+	if len(givenPlayers) != 1 {
+		return nil, fmt.Errorf(
+			"A game created from group %v should list only the host, not %v",
+			groupName,
+			givenPlayers)
+	}
+
+	hostName := givenPlayers[0]
+	hostContacts, errorFromContacts := service.playerCollection.Contacts(callContext, hostName)
+	if errorFromContacts != nil {
+		return nil, errorFromContacts
+	}
+
+	hostGroup, hasGroup := hostContacts.GroupWithName(groupName)
+	if !hasGroup {
+		return nil, fmt.Errorf("Player %v has no group %v", hostName, groupName)
+	}
+
+	return append([]string{hostName}, hostGroup.MemberIdentifiers...), nil
+}
+
+// errorStatus wraps the message of the given error in a gRPC status with the code which
+// corresponds to the code of the error, or the given fallback code if the error has no
+// code with a status code of its own. The code of the error is sent in the trailing
+// metadata of the call.
+func errorStatus(
+	callContext context.Context,
+	errorToReport error,
+	fallbackCode codes.Code) error {
+	errorCode := failure.CodeOf(errorToReport)
+	statusCode, hasStatusCode := grpcCodesForCodes[errorCode]
+	if !hasStatusCode {
+		statusCode = fallbackCode
+	}
+
+	// There is no trailing metadata to set if the context does not come from a call.
+	grpc.SetTrailer(callContext, metadata.Pairs(ErrorCodeKey, errorCode))
+
+	return status.Error(statusCode, errorToReport.Error())
+}
@@ -0,0 +1,544 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/benoleary/ilutulestikud/backend/defaults"
+	"github.com/benoleary/ilutulestikud/backend/failure"
+	"github.com/benoleary/ilutulestikud/backend/game"
+	game_persister "github.com/benoleary/ilutulestikud/backend/game/persister"
+	"github.com/benoleary/ilutulestikud/backend/player"
+	player_persister "github.com/benoleary/ilutulestikud/backend/player/persister"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
+	"github.com/benoleary/ilutulestikud/backend/server/rpc"
+	"github.com/benoleary/ilutulestikud/backend/server/rpc/protocol"
+)
+
+const (
+	testGameName = "test game"
+	testPassword = "test password"
+)
+
+// testService holds a client connected to a service built around in-memory persisters
+// with two registered players, a host and a guest, along with their identifiers.
+type testService struct {
+	serviceClient    protocol.IlutulestikudClient
+	hostIdentifier   string
+	guestIdentifier  string
+	playerCollection *player.StateCollection
+}
+
+func newTestService(unitTest *testing.T) testService {
+	gamePersister := game_persister.NewInMemory()
+	playerCollection :=
+		player.NewCollection(
+			player_persister.NewInMemory(),
+			defaults.AvailableColors(),
+			defaults.ChatBackgroundColor(),
+			nil,
+			player.DeletionLeavesTombstone,
+			game.NewParticipationChecker(gamePersister))
+	gameCollection :=
+		game.NewCollection(
+			gamePersister,
+			game_persister.NewLobbyInMemory(),
+			game_persister.NewSeriesInMemory(),
+			game_persister.NewSpectatorInMemory(),
+			game_persister.NewHistoryInMemory(),
+			8,
+			playerCollection,
+			playerCollection)
+
+	testPlayerIdentifiers := make([]string, 2)
+	for playerIndex, playerName := range []string{"Test Host", "Test Guest"} {
+		playerIdentifier, errorFromAdd :=
+			playerCollection.Add(context.Background(), playerName, "", testPassword)
+		if errorFromAdd != nil {
+			unitTest.Fatalf("could not register %v: %v", playerName, errorFromAdd)
+		}
+
+		testPlayerIdentifiers[playerIndex] = playerIdentifier
+	}
+
+	grpcServer :=
+		rpc.NewServer(
+			rpc.New(
+				gameCollection,
+				playerCollection,
+				authentication.NewTokenSigner([]byte("test key"), time.Hour)))
+
+	bufferListener := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(bufferListener)
+	unitTest.Cleanup(grpcServer.Stop)
+
+	clientConnection, errorFromDial :=
+		grpc.NewClient(
+			"passthrough:///bufnet",
+			grpc.WithContextDialer(
+				func(dialContext context.Context, _ string) (net.Conn, error) {
+					return bufferListener.DialContext(dialContext)
+				}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if errorFromDial != nil {
+		unitTest.Fatalf("could not connect to test service: %v", errorFromDial)
+	}
+
+	unitTest.Cleanup(func() { clientConnection.Close() })
+
+	return testService{
+		serviceClient:    protocol.NewIlutulestikudClient(clientConnection),
+		hostIdentifier:   testPlayerIdentifiers[0],
+		guestIdentifier:  testPlayerIdentifiers[1],
+		playerCollection: playerCollection,
+	}
+}
+
+// contextFor logs the given player in through the service and returns a context which
+// carries the session token in its metadata.
+func (service testService) contextFor(
+	unitTest *testing.T,
+	playerName string) context.Context {
+	sessionToken, errorFromLogIn :=
+		service.serviceClient.LogIn(
+			context.Background(),
+			&protocol.PlayerCredentials{Name: playerName, Password: testPassword})
+	if errorFromLogIn != nil {
+		unitTest.Fatalf("could not log in %v: %v", playerName, errorFromLogIn)
+	}
+
+	return metadata.AppendToOutgoingContext(
+		context.Background(),
+		"authorization",
+		"Bearer "+sessionToken.GetToken())
+}
+
+// startTestGame creates a game hosted by the host with the guest as the other player,
+// and has the guest accept so that the game is dealt.
+func (service testService) startTestGame(
+	unitTest *testing.T,
+	hostContext context.Context,
+	guestContext context.Context) {
+	_, errorFromCreation :=
+		service.serviceClient.CreateGame(
+			hostContext,
+			&protocol.GameDefinition{
+				GameName:          testGameName,
+				RulesetIdentifier: game.StandardWithoutRainbowIdentifier,
+				PlayerNames:       []string{service.hostIdentifier, service.guestIdentifier},
+			})
+	if errorFromCreation != nil {
+		unitTest.Fatalf("could not create test game: %v", errorFromCreation)
+	}
+
+	_, errorFromAccepting :=
+		service.serviceClient.AcceptInvitation(
+			guestContext,
+			&protocol.PlayerInGameIndication{
+				GameName:   testGameName,
+				PlayerName: service.guestIdentifier,
+			})
+	if errorFromAccepting != nil {
+		unitTest.Fatalf("could not accept invitation to test game: %v", errorFromAccepting)
+	}
+}
+
+// assertErrorCodes checks that the given error has the given gRPC status code and that
+// the given trailer carries the given error code.
+func assertErrorCodes(
+	unitTest *testing.T,
+	testIdentifier string,
+	actualError error,
+	actualTrailer metadata.MD,
+	expectedStatusCode codes.Code,
+	expectedErrorCode string) {
+	if status.Code(actualError) != expectedStatusCode {
+		unitTest.Fatalf(
+			testIdentifier+"/expected status code %v, instead got error %v",
+			expectedStatusCode,
+			actualError)
+	}
+
+	actualErrorCodes := actualTrailer.Get(rpc.ErrorCodeKey)
+	if (len(actualErrorCodes) != 1) || (actualErrorCodes[0] != expectedErrorCode) {
+		unitTest.Fatalf(
+			testIdentifier+"/expected error code %v in trailer, instead trailer was %v",
+			expectedErrorCode,
+			actualTrailer)
+	}
+}
+
+func TestLogInReturnsTokenForSameServiceAndRejectsWrongPassword(unitTest *testing.T) {
+	testIdentifier := "LogIn"
+	testService := newTestService(unitTest)
+
+	sessionToken, errorFromLogIn :=
+		testService.serviceClient.LogIn(
+			context.Background(),
+			&protocol.PlayerCredentials{Name: "Test Host", Password: testPassword})
+	if errorFromLogIn != nil {
+		unitTest.Fatalf(testIdentifier+"/could not log in: %v", errorFromLogIn)
+	}
+
+	if (sessionToken.GetPlayerIdentifier() != testService.hostIdentifier) ||
+		(sessionToken.GetPlayerName() != "Test Host") ||
+		(sessionToken.GetToken() == "") {
+		unitTest.Fatalf(
+			testIdentifier+"/unexpected session token %v for player %v",
+			sessionToken,
+			testService.hostIdentifier)
+	}
+
+	var failureTrailer metadata.MD
+	_, errorFromWrongPassword :=
+		testService.serviceClient.LogIn(
+			context.Background(),
+			&protocol.PlayerCredentials{Name: "Test Host", Password: "wrong password"},
+			grpc.Trailer(&failureTrailer))
+
+	assertErrorCodes(
+		unitTest,
+		testIdentifier+"/wrong password",
+		errorFromWrongPassword,
+		failureTrailer,
+		codes.Unauthenticated,
+		failure.CodeIncorrectCredentials)
+}
+
+func TestCallsForPlayersAreAuthorized(unitTest *testing.T) {
+	testService := newTestService(unitTest)
+	guestContext := testService.contextFor(unitTest, "Test Guest")
+
+	testCases := []struct {
+		testName           string
+		callContext        context.Context
+		expectedStatusCode codes.Code
+		expectedErrorCode  string
+	}{
+		{
+			testName:           "No token",
+			callContext:        context.Background(),
+			expectedStatusCode: codes.Unauthenticated,
+			expectedErrorCode:  failure.CodeNotAuthenticated,
+		},
+		{
+			testName:           "Token for other player",
+			callContext:        guestContext,
+			expectedStatusCode: codes.PermissionDenied,
+			expectedErrorCode:  failure.CodeForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			var failureTrailer metadata.MD
+			_, errorFromCreation :=
+				testService.serviceClient.CreateGame(
+					testCase.callContext,
+					&protocol.GameDefinition{
+						GameName:          testGameName,
+						RulesetIdentifier: game.StandardWithoutRainbowIdentifier,
+						PlayerNames: []string{
+							testService.hostIdentifier,
+							testService.guestIdentifier,
+						},
+					},
+					grpc.Trailer(&failureTrailer))
+
+			assertErrorCodes(
+				unitTest,
+				testCase.testName,
+				errorFromCreation,
+				failureTrailer,
+				testCase.expectedStatusCode,
+				testCase.expectedErrorCode)
+		})
+	}
+}
+
+func TestRejectInvalidSessionToken(unitTest *testing.T) {
+	testIdentifier := "Invalid token"
+	testService := newTestService(unitTest)
+
+	testCases := []struct {
+		testName            string
+		authorizationHeader string
+	}{
+		{
+			testName:            "Not bearer",
+			authorizationHeader: "Basic something",
+		},
+		{
+			testName:            "Malformed token",
+			authorizationHeader: "Bearer not-a-token",
+		},
+	}
+
+	for _, testCase := range testCases {
+		unitTest.Run(testCase.testName, func(unitTest *testing.T) {
+			callContext :=
+				metadata.AppendToOutgoingContext(
+					context.Background(),
+					"authorization",
+					testCase.authorizationHeader)
+
+			_, errorFromList :=
+				testService.serviceClient.ListGames(
+					callContext,
+					&protocol.PlayerIndication{PlayerIdentifier: testService.hostIdentifier})
+			if status.Code(errorFromList) != codes.Unauthenticated {
+				unitTest.Fatalf(
+					testIdentifier+"/"+testCase.testName+"/expected unauthenticated, got %v",
+					errorFromList)
+			}
+		})
+	}
+}
+
+func TestRejectSessionTokenOfDeletedPlayer(unitTest *testing.T) {
+	testService := newTestService(unitTest)
+	hostContext := testService.contextFor(unitTest, "Test Host")
+
+	errorFromDelete :=
+		testService.playerCollection.Delete(context.Background(), testService.hostIdentifier)
+	if errorFromDelete != nil {
+		unitTest.Fatalf("Delete(...) produced unexpected error %v", errorFromDelete)
+	}
+
+	var failureTrailer metadata.MD
+	_, errorFromList :=
+		testService.serviceClient.ListGames(
+			hostContext,
+			&protocol.PlayerIndication{PlayerIdentifier: testService.hostIdentifier},
+			grpc.Trailer(&failureTrailer))
+
+	assertErrorCodes(
+		unitTest,
+		"Deleted player",
+		errorFromList,
+		failureTrailer,
+		codes.Unauthenticated,
+		failure.CodeNotAuthenticated)
+}
+
+func TestCreateGameFromGroupOfHost(unitTest *testing.T) {
+	testIdentifier := "CreateGame from group"
+	testService := newTestService(unitTest)
+	hostContext := testService.contextFor(unitTest, "Test Host")
+	groupName := "test group"
+
+	errorFromGroup :=
+		testService.playerCollection.SaveGroup(
+			context.Background(),
+			testService.hostIdentifier,
+			groupName,
+			[]string{testService.guestIdentifier})
+	if errorFromGroup != nil {
+		unitTest.Fatalf(testIdentifier+"/could not create group: %v", errorFromGroup)
+	}
+
+	_, errorFromCreation :=
+		testService.serviceClient.CreateGame(
+			hostContext,
+			&protocol.GameDefinition{
+				GameName:          testGameName,
+				RulesetIdentifier: game.StandardWithoutRainbowIdentifier,
+				PlayerNames:       []string{testService.hostIdentifier},
+				GroupName:         groupName,
+			})
+	if errorFromCreation != nil {
+		unitTest.Fatalf(testIdentifier+"/could not create game: %v", errorFromCreation)
+	}
+
+	guestContext := testService.contextFor(unitTest, "Test Guest")
+	invitationList, errorFromList :=
+		testService.serviceClient.ListInvitations(
+			guestContext,
+			&protocol.PlayerIndication{PlayerIdentifier: testService.guestIdentifier})
+	if errorFromList != nil {
+		unitTest.Fatalf(testIdentifier+"/could not list invitations: %v", errorFromList)
+	}
+
+	receivedInvitations := invitationList.GetInvitations()
+	if (len(receivedInvitations) != 1) ||
+		(receivedInvitations[0].GetGameName() != testGameName) ||
+		(receivedInvitations[0].GetHostName() != testService.hostIdentifier) {
+		unitTest.Fatalf(
+			testIdentifier+"/expected single invitation to %v from %v, instead got %v",
+			testGameName,
+			testService.hostIdentifier,
+			receivedInvitations)
+	}
+}
+
+func TestPlayGameThroughService(unitTest *testing.T) {
+	testIdentifier := "Play game"
+	testService := newTestService(unitTest)
+	hostContext := testService.contextFor(unitTest, "Test Host")
+	guestContext := testService.contextFor(unitTest, "Test Guest")
+	testService.startTestGame(unitTest, hostContext, guestContext)
+
+	turnSummaryList, errorFromList :=
+		testService.serviceClient.ListGames(
+			hostContext,
+			&protocol.PlayerIndication{PlayerIdentifier: testService.hostIdentifier})
+	if errorFromList != nil {
+		unitTest.Fatalf(testIdentifier+"/could not list games: %v", errorFromList)
+	}
+
+	turnSummaries := turnSummaryList.GetTurnSummaries()
+	if (len(turnSummaries) != 1) ||
+		(turnSummaries[0].GetGameName() != testGameName) ||
+		!turnSummaries[0].GetIsPlayerTurn() {
+		unitTest.Fatalf(
+			testIdentifier+"/expected only %v with turn for host, instead got %v",
+			testGameName,
+			turnSummaries)
+	}
+
+	initialView, errorFromView :=
+		testService.serviceClient.GetGame(
+			hostContext,
+			&protocol.PlayerInGameIndication{
+				GameName:   testGameName,
+				PlayerName: testService.hostIdentifier,
+			})
+	if errorFromView != nil {
+		unitTest.Fatalf(testIdentifier+"/could not get game: %v", errorFromView)
+	}
+
+	visibleHands := initialView.GetHandsAfterThisPlayer()
+	if !initialView.GetThisPlayerCanTakeTurn() ||
+		(len(initialView.GetHandOfThisPlayer()) == 0) ||
+		(len(visibleHands) != 1) ||
+		(visibleHands[0].GetPlayerIdentifier() != testService.guestIdentifier) ||
+		(len(visibleHands[0].GetHandCards()) != len(initialView.GetHandOfThisPlayer())) ||
+		(initialView.GetVersion() != int64(turnSummaries[0].GetVersion())) {
+		unitTest.Fatalf(
+			testIdentifier+"/unexpected initial view %v",
+			initialView)
+	}
+
+	var failureTrailer metadata.MD
+	_, errorFromOutOfTurn :=
+		testService.serviceClient.TakeTurnByDiscarding(
+			guestContext,
+			&protocol.PlayerCardIndication{
+				GameName:     testGameName,
+				PlayerName:   testService.guestIdentifier,
+				ExpectedTurn: initialView.GetTurn(),
+				CardIndex:    0,
+			},
+			grpc.Trailer(&failureTrailer))
+
+	assertErrorCodes(
+		unitTest,
+		testIdentifier+"/out of turn",
+		errorFromOutOfTurn,
+		failureTrailer,
+		codes.FailedPrecondition,
+		failure.CodeNotYourTurn)
+
+	_, errorFromStaleTurn :=
+		testService.serviceClient.TakeTurnByHintingNumber(
+			hostContext,
+			&protocol.PlayerIndexHint{
+				GameName:     testGameName,
+				PlayerName:   testService.hostIdentifier,
+				ExpectedTurn: initialView.GetTurn() + 1,
+				ReceiverName: testService.guestIdentifier,
+				HintedNumber: visibleHands[0].GetHandCards()[0].GetSequenceIndex(),
+			},
+			grpc.Trailer(&failureTrailer))
+
+	assertErrorCodes(
+		unitTest,
+		testIdentifier+"/stale turn",
+		errorFromStaleTurn,
+		failureTrailer,
+		codes.Aborted,
+		failure.CodeTurnConflict)
+
+	viewAfterHint, errorFromHint :=
+		testService.serviceClient.TakeTurnByHintingColor(
+			hostContext,
+			&protocol.PlayerColorHint{
+				GameName:     testGameName,
+				PlayerName:   testService.hostIdentifier,
+				ExpectedTurn: initialView.GetTurn(),
+				ReceiverName: testService.guestIdentifier,
+				HintedColor:  visibleHands[0].GetHandCards()[0].GetColorSuit(),
+			})
+	if errorFromHint != nil {
+		unitTest.Fatalf(testIdentifier+"/could not hint: %v", errorFromHint)
+	}
+
+	if (viewAfterHint.GetVersion() <= initialView.GetVersion()) ||
+		(viewAfterHint.GetTurn() != initialView.GetTurn()+1) ||
+		viewAfterHint.GetThisPlayerCanTakeTurn() ||
+		(viewAfterHint.GetNumberOfReadyHints() != initialView.GetNumberOfReadyHints()-1) ||
+		(len(viewAfterHint.GetHandsBeforeThisPlayer()) != 1) {
+		unitTest.Fatalf(
+			testIdentifier+"/view after hint %v did not follow from initial view %v",
+			viewAfterHint,
+			initialView)
+	}
+
+	chatText := "test chat"
+	viewAfterChat, errorFromChat :=
+		testService.serviceClient.RecordChatMessage(
+			guestContext,
+			&protocol.PlayerChatMessage{
+				GameName:    testGameName,
+				PlayerName:  testService.guestIdentifier,
+				ChatMessage: chatText,
+			})
+	if errorFromChat != nil {
+		unitTest.Fatalf(testIdentifier+"/could not chat: %v", errorFromChat)
+	}
+
+	chatLog := viewAfterChat.GetChatLog()
+	if (len(chatLog) == 0) ||
+		(chatLog[len(chatLog)-1].GetMessageText() != chatText) ||
+		(viewAfterChat.GetVersion() <= viewAfterHint.GetVersion()) ||
+		(viewAfterChat.GetTurn() != viewAfterHint.GetTurn()) ||
+		!viewAfterChat.GetThisPlayerCanTakeTurn() {
+		unitTest.Fatalf(
+			testIdentifier+"/expected chat %v at end of log of guest on turn, instead got %v",
+			chatText,
+			viewAfterChat)
+	}
+
+	// The chat message changed the version of the game but not its turn, so a turn
+	// based on the view from before the chat message is still current.
+	viewAfterDiscard, errorFromDiscard :=
+		testService.serviceClient.TakeTurnByDiscarding(
+			guestContext,
+			&protocol.PlayerCardIndication{
+				GameName:     testGameName,
+				PlayerName:   testService.guestIdentifier,
+				ExpectedTurn: viewAfterHint.GetTurn(),
+				CardIndex:    0,
+			})
+	if errorFromDiscard != nil {
+		unitTest.Fatalf(testIdentifier+"/could not discard: %v", errorFromDiscard)
+	}
+
+	if (len(viewAfterDiscard.GetDiscardedCards()) != 1) ||
+		(viewAfterDiscard.GetNumberOfCardsLeftInDeck() !=
+			viewAfterChat.GetNumberOfCardsLeftInDeck()-1) {
+		unitTest.Fatalf(
+			testIdentifier+"/view after discard %v did not follow from view %v",
+			viewAfterDiscard,
+			viewAfterChat)
+	}
+}
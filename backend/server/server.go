@@ -1,57 +1,128 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/game"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
 	"github.com/benoleary/ilutulestikud/backend/server/endpoint/player"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/resource"
+	"github.com/benoleary/ilutulestikud/backend/server/idempotency"
+	"github.com/benoleary/ilutulestikud/backend/server/openapi"
 )
 
 // State contains all the state to allow the backend to function.
 type State struct {
-	contextProvider            ContextProvider
-	accessControlAllowedOrigin string
-	backendVersion             string
-	playerHandler              httpGetAndPostHandler
-	gameHandler                httpGetAndPostHandler
+	contextProvider             ContextProvider
+	accessControlAllowedOrigins []string
+	backendVersion              string
+	sessionTokenSigner          SessionTokenSigner
+	sessionAuthorizer           *authentication.ContextAuthorizer
+	idempotencyPersister        idempotency.Persister
+	idempotencyMutualExclusion  sync.Mutex
+	idempotencyKeysInProgress   map[string]bool
+	playerHandler               httpGetAndPostHandler
+	gameHandler                 httpGetAndPostHandler
+	gameUpdateHandler           httpEventStreamHandler
+	resourceHandler             httpResourceHandler
+	apiDescription              openapi.Document
 }
 
 // New creates a new State object with handlers built around the given
-// state collections.
+// state collections, which check that requests made on behalf of a player
+// come with a session token for that player signed by the given signer, and
+// which use the player state collection to determine who is an administrator and
+// to reject the session tokens of players who have been deleted. POST requests with an idempotency key have their responses stored by the given
+// persister, unless it is nil. The second version of the API, under /backend/v2, is
+// served by a handler built around the same state collections. Cross-origin requests
+// are allowed from the given origins, where "*" allows any origin.
 func New(
 	contextProvider ContextProvider,
-	accessControlAllowedOrigin string,
+	accessControlAllowedOrigins []string,
 	backendVersion string,
 	segmentTranslator parsing.SegmentTranslator,
+	sessionTokenSigner SessionTokenSigner,
+	idempotencyPersister idempotency.Persister,
 	playerStateCollection player.StateCollection,
 	gameStateCollection game.StateCollection) *State {
-	return NewWithGivenHandlers(
+	playerAuthorizer := authentication.NewContextAuthorizer(playerStateCollection)
+
+	// The same handler serves both the requests for games and the streams of updates
+	// to games.
+	gameHandler :=
+		game.New(
+			gameStateCollection,
+			segmentTranslator,
+			playerAuthorizer,
+			playerStateCollection,
+			playerStateCollection)
+
+	serverState := NewWithGivenHandlers(
 		contextProvider,
-		accessControlAllowedOrigin,
+		accessControlAllowedOrigins,
 		backendVersion,
 		segmentTranslator,
-		player.New(playerStateCollection, segmentTranslator),
-		game.New(gameStateCollection, segmentTranslator))
+		sessionTokenSigner,
+		idempotencyPersister,
+		player.New(
+			playerStateCollection,
+			segmentTranslator,
+			playerAuthorizer,
+			sessionTokenSigner),
+		gameHandler,
+		gameHandler,
+		resource.New(
+			gameStateCollection,
+			playerStateCollection,
+			playerAuthorizer,
+			sessionTokenSigner,
+			gameHandler))
+
+	serverState.sessionAuthorizer = playerAuthorizer
+
+	return serverState
 }
 
 // NewWithGivenHandlers creates a new State object and returns a pointer to it,
-// assuming that the given handlers are consistent.
+// assuming that the given handlers are consistent. The handler for updates to games
+// may be nil, in which case there are no streams of updates, the handler for resources
+// may be nil, in which case there is no second version of the API, and the persister
+// for the responses to requests with idempotency keys may be nil, in which case such
+// keys are ignored. The OpenAPI description of the routes is served as
+// /backend/openapi.json whichever handlers are given. Session tokens are accepted
+// as long as they are valid, without checking whether their players still exist.
 func NewWithGivenHandlers(
 	contextProvider ContextProvider,
-	accessControlAllowedOrigin string,
+	accessControlAllowedOrigins []string,
 	backendVersion string,
 	segmentTranslator parsing.SegmentTranslator,
+	sessionTokenSigner SessionTokenSigner,
+	idempotencyPersister idempotency.Persister,
 	handlerForPlayer httpGetAndPostHandler,
-	handlerForGame httpGetAndPostHandler) *State {
+	handlerForGame httpGetAndPostHandler,
+	handlerForGameUpdates httpEventStreamHandler,
+	handlerForResources httpResourceHandler) *State {
 	return &State{
-		contextProvider:            contextProvider,
-		accessControlAllowedOrigin: accessControlAllowedOrigin,
-		backendVersion:             backendVersion,
-		playerHandler:              handlerForPlayer,
-		gameHandler:                handlerForGame,
+		contextProvider:             contextProvider,
+		accessControlAllowedOrigins: accessControlAllowedOrigins,
+		backendVersion:              backendVersion,
+		sessionTokenSigner:          sessionTokenSigner,
+		sessionAuthorizer:           nil,
+		idempotencyPersister:        idempotencyPersister,
+		idempotencyMutualExclusion:  sync.Mutex{},
+		idempotencyKeysInProgress:   make(map[string]bool, 0),
+		playerHandler:               handlerForPlayer,
+		gameHandler:                 handlerForGame,
+		gameUpdateHandler:           handlerForGameUpdates,
+		resourceHandler:             handlerForResources,
+		apiDescription:              openapi.New(backendVersion),
 	}
 }
 
@@ -60,13 +131,24 @@ func NewWithGivenHandlers(
 func (state *State) HandleBackend(
 	httpResponseWriter http.ResponseWriter,
 	httpRequest *http.Request) {
-	// If an allowed origin for access control has been set, we set all the headers to allow it.
-	if state.accessControlAllowedOrigin != "" {
-		httpResponseWriter.Header().Set("Access-Control-Allow-Origin", state.accessControlAllowedOrigin)
-		httpResponseWriter.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	// The response depends on the origin of the request if any origins are allowed, so
+	// caches must not give the response for one origin to another.
+	if len(state.accessControlAllowedOrigins) > 0 {
+		httpResponseWriter.Header().Add("Vary", "Origin")
+	}
+
+	// If the request comes from an allowed origin, we set all the headers to allow it.
+	allowedOrigin := state.allowedOriginOf(httpRequest)
+	if allowedOrigin != "" {
+		httpResponseWriter.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+		httpResponseWriter.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 		httpResponseWriter.Header().Set(
 			"Access-Control-Allow-Headers",
-			"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, If-None-Match, "+
+				idempotencyKeyHeader)
+		httpResponseWriter.Header().Set(
+			"Access-Control-Expose-Headers",
+			"ETag, "+replayedResponseHeader)
 	}
 
 	// There should always be an initial "/", but unless it is present, with at least one character
@@ -94,36 +176,71 @@ func (state *State) HandleBackend(
 			json.NewEncoder(httpResponseWriter).Encode(versionForBody)
 			return
 		}
+	case "openapi.json":
+		json.NewEncoder(httpResponseWriter).Encode(state.apiDescription)
+		return
 	case "player":
 		requestHandler = state.playerHandler
 	case "game":
 		requestHandler = state.gameHandler
+	case "game-updates":
+		state.handleGameUpdates(httpResponseWriter, httpRequest, pathSegments[2:])
+		return
+	case "v2":
+		state.handleResourceRequest(httpResponseWriter, httpRequest)
+		return
 	default:
 		http.NotFound(httpResponseWriter, httpRequest)
 		return
 	}
 
+	if httpRequest.Method == http.MethodOptions {
+		return
+	}
+
 	var objectForBody interface{}
 	var httpStatus int
 
-	switch httpRequest.Method {
-	case http.MethodOptions:
-		return
-	case http.MethodGet:
+	requestContext, errorFromAuthentication := state.authenticatedContext(httpRequest, false)
+
+	switch {
+	case errorFromAuthentication != nil:
+		objectForBody = errorFromAuthentication
+		httpStatus = http.StatusUnauthorized
+	case httpRequest.Method == http.MethodGet:
 		objectForBody, httpStatus =
 			requestHandler.HandleGet(
-				state.contextProvider.FromRequest(httpRequest),
+				requestContext,
 				pathSegments[2:])
-	case http.MethodPost:
+	case httpRequest.Method == http.MethodPost:
 		{
 			if httpRequest.Body == nil {
 				http.Error(httpResponseWriter, "Empty request body", http.StatusBadRequest)
 				return
 			}
 
+			idempotencyKey := httpRequest.Header.Get(idempotencyKeyHeader)
+			if (idempotencyKey != "") && (state.idempotencyPersister != nil) {
+				performPost := func(httpBodyDecoder *json.Decoder) (interface{}, int) {
+					return requestHandler.HandlePost(
+						requestContext,
+						httpBodyDecoder,
+						pathSegments[2:])
+				}
+
+				state.handleIdempotentPost(
+					httpResponseWriter,
+					httpRequest,
+					requestContext,
+					performPost,
+					errorForFirstVersion,
+					idempotencyKey)
+				return
+			}
+
 			objectForBody, httpStatus =
 				requestHandler.HandlePost(
-					state.contextProvider.FromRequest(httpRequest),
+					requestContext,
 					json.NewDecoder(httpRequest.Body),
 					pathSegments[2:])
 		}
@@ -131,20 +248,144 @@ func (state *State) HandleBackend(
 		http.Error(httpResponseWriter, "Method not GET or POST: "+httpRequest.Method, http.StatusBadRequest)
 	}
 
+	writeResponse(httpResponseWriter, httpRequest, objectForBody, httpStatus)
+}
+
+// writeResponse writes the given HTTP status and then the given object as the JSON body
+// of the response. If the object has an entity tag, the tag is sent as the ETag header,
+// and a successful GET request which already has the tag in its If-None-Match header is
+// answered with Not Modified and no body.
+func writeResponse(
+	httpResponseWriter http.ResponseWriter,
+	httpRequest *http.Request,
+	objectForBody interface{},
+	httpStatus int) {
+	taggedBody, isTagged := objectForBody.(parsing.TaggedBody)
+	if isTagged {
+		objectForBody = taggedBody.Body
+		quotedTag := strconv.Quote(taggedBody.EntityTag)
+		httpResponseWriter.Header().Set("ETag", quotedTag)
+
+		if (httpStatus == http.StatusOK) &&
+			(httpRequest.Method == http.MethodGet) &&
+			matchesAnyEntityTag(httpRequest.Header.Get("If-None-Match"), quotedTag) {
+			httpResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
 	// If the status is OK, writing the header with OK won't make any difference.
 	httpResponseWriter.WriteHeader(httpStatus)
 
+	json.NewEncoder(httpResponseWriter).Encode(bodyForObject(objectForBody))
+}
+
+// matchesAnyEntityTag returns true if the given value of an If-None-Match header
+// matches the given quoted entity tag, either as "*" or as a comma-separated list of
+// quoted tags which includes the given tag. The comparison is weak, as the GET
+// requests for which it is used are only ever answered with whole bodies, so a tag
+// prefixed with W/ matches the same tag without the prefix.
+func matchesAnyEntityTag(ifNoneMatchHeader string, quotedTag string) bool {
+	if strings.TrimSpace(ifNoneMatchHeader) == "*" {
+		return true
+	}
+
+	for _, listedTag := range strings.Split(ifNoneMatchHeader, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(listedTag), "W/") == quotedTag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyForObject returns the given object unless it is an error, in which case it returns
+// the object which the frontend recognizes as an error.
+func bodyForObject(objectForBody interface{}) interface{} {
 	errorMessageForBody, isError := objectForBody.(error)
 
 	// Since errors go out just as strings, we wrap the .Error() string in an object recognized
-	// by the frontend.
+	// by the frontend, along with the code of the error.
 	if isError {
-		objectForBody = &parsing.ErrorForBody{
-			Error: errorMessageForBody.Error(),
+		return parsing.ErrorForBodyFrom(errorMessageForBody)
+	}
+
+	return objectForBody
+}
+
+// errorForFirstVersion returns the given error unchanged, as writeResponse wraps errors
+// in the form which is used by the first version of the API.
+func errorForFirstVersion(errorFromServer error, httpStatus int) interface{} {
+	return errorFromServer
+}
+
+// allowedOriginOf returns the value for the Access-Control-Allow-Origin header for the
+// given request, which is "*" if any origin is allowed, or else the origin of the
+// request if it is one of the allowed origins, or else an empty string.
+func (state *State) allowedOriginOf(httpRequest *http.Request) string {
+	requestOrigin := httpRequest.Header.Get("Origin")
+	for _, allowedOrigin := range state.accessControlAllowedOrigins {
+		if (allowedOrigin == "*") || (allowedOrigin == requestOrigin) {
+			return allowedOrigin
+		}
+	}
+
+	return ""
+}
+
+// authenticatedContext returns the context for the given request, carrying the
+// identifier of the player identified by the session token in the Authorization
+// header if there is one. It returns an error if there is an Authorization header
+// which does not hold a valid session token, or which holds the session token of a
+// player who has since been deleted. Requests without an Authorization header are
+// allowed, as some requests, such as registering a new player, are not made on behalf
+// of any player who has logged in. If the token is allowed in the query, a request without an
+// Authorization header may instead give the session token as its access_token query
+// parameter, as browsers cannot set headers for streams of server-sent events.
+func (state *State) authenticatedContext(
+	httpRequest *http.Request,
+	allowsTokenInQuery bool) (context.Context, error) {
+	requestContext := state.contextProvider.FromRequest(httpRequest)
+	bearerPrefix := "Bearer "
+
+	authorizationHeader := httpRequest.Header.Get("Authorization")
+	if (authorizationHeader == "") && allowsTokenInQuery {
+		tokenFromQuery := httpRequest.URL.Query().Get("access_token")
+		if tokenFromQuery != "" {
+			authorizationHeader = bearerPrefix + tokenFromQuery
+		}
+	}
+
+	if authorizationHeader == "" {
+		return requestContext, nil
+	}
+
+	if !strings.HasPrefix(authorizationHeader, bearerPrefix) {
+		return nil, fmt.Errorf("Authorization header must be a bearer token")
+	}
+
+	if state.sessionTokenSigner == nil {
+		return nil, fmt.Errorf("Server cannot verify session tokens")
+	}
+
+	playerIdentifier, errorFromToken :=
+		state.sessionTokenSigner.PlayerFromToken(
+			strings.TrimPrefix(authorizationHeader, bearerPrefix))
+
+	if errorFromToken != nil {
+		return nil, errorFromToken
+	}
+
+	if state.sessionAuthorizer != nil {
+		errorFromSession :=
+			state.sessionAuthorizer.AuthorizeSession(requestContext, playerIdentifier)
+
+		if errorFromSession != nil {
+			return nil, errorFromSession
 		}
 	}
 
-	json.NewEncoder(httpResponseWriter).Encode(objectForBody)
+	return authentication.ContextWithAuthenticatedPlayer(requestContext, playerIdentifier), nil
 }
 
 // parsePathSegments returns the segments of the URI path as a slice of a string array.
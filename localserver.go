@@ -1,52 +1,225 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"time"
 
-	"github.com/benoleary/ilutulestikud/backend/cloud"
-	"github.com/benoleary/ilutulestikud/backend/defaults"
+	"github.com/benoleary/ilutulestikud/backend/configuration"
 	"github.com/benoleary/ilutulestikud/backend/game"
 	game_persister "github.com/benoleary/ilutulestikud/backend/game/persister"
+	"github.com/benoleary/ilutulestikud/backend/naming"
 	"github.com/benoleary/ilutulestikud/backend/player"
 	player_persister "github.com/benoleary/ilutulestikud/backend/player/persister"
 	"github.com/benoleary/ilutulestikud/backend/server"
+	"github.com/benoleary/ilutulestikud/backend/server/endpoint/authentication"
 	endpoint_parsing "github.com/benoleary/ilutulestikud/backend/server/endpoint/parsing"
+	"github.com/benoleary/ilutulestikud/backend/server/idempotency"
+	"github.com/benoleary/ilutulestikud/backend/server/rpc"
 )
 
-// This main function just injects hard-coded dependencies.
+// This main function injects the dependencies chosen by the configuration, which comes
+// from the defaults overridden by a configuration file, environment variables and flags.
 func main() {
-	fmt.Printf("Local server started.\n")
+	serverConfiguration, errorFromConfiguration :=
+		configuration.Load(os.Args[0], os.Args[1:], os.LookupEnv)
+	if errorFromConfiguration == flag.ErrHelp {
+		return
+	}
+
+	if errorFromConfiguration != nil {
+		exitWithError("Invalid configuration: %v", errorFromConfiguration)
+	}
+
 	contextProvider := &server.BackgroundContextProvider{}
 
-	playerDatastoreClientProvider :=
-		cloud.NewIlutulestikudDatastoreClientProvider(player_persister.CloudDatastoreKeyKind)
-	playerPersister :=
-		player_persister.NewInCloudDatastore(playerDatastoreClientProvider)
+	// Players, games and lobbies share the same naming rules, so that a name which is
+	// valid for one is valid for the others, and names differ in the same way.
+	nameRules := naming.DefaultRules()
+	storeSettings := serverConfiguration.Stores
+
+	playerPersister := playerPersisterFor(storeSettings.Player, nameRules)
+	gamePersister := gamePersisterFor(storeSettings.Game, nameRules)
+
+	// Deleted players are replaced by tombstones so that the views of their games can
+	// still show their names. The participation checker is still needed to stop players
+	// from choosing nearly the same colors as the players with whom they share games.
+	// The configured administrators are always treated as administrators, even if they
+	// were added as normal players.
 	playerCollection :=
 		player.NewCollection(
 			playerPersister,
-			defaults.AvailableColors())
+			serverConfiguration.ChatColors,
+			serverConfiguration.ChatBackgroundColor,
+			serverConfiguration.AdministratorIdentifiers,
+			player.DeletionLeavesTombstone,
+			game.NewParticipationChecker(gamePersister))
 
-	gameDatastoreClientProvider :=
-		cloud.NewIlutulestikudDatastoreClientProvider(game_persister.CloudDatastoreKeyKind)
-	gamePersister :=
-		game_persister.NewInCloudDatastore(gameDatastoreClientProvider)
 	gameCollection :=
 		game.NewCollection(
 			gamePersister,
-			8,
+			lobbyPersisterFor(storeSettings.Lobby, nameRules),
+			seriesPersisterFor(storeSettings.Series),
+			spectatorPersisterFor(storeSettings.Spectator),
+			historyPersisterFor(storeSettings.History),
+			serverConfiguration.ChatLogLength,
+			playerCollection,
 			playerCollection)
 
-	// We could load the allowed origin from a file, but this app is very specific to a set of fixed addresses.
+	// A random key is good enough for a local server, as players just have to log in
+	// again after a restart.
+	signingKey, errorFromKey := authentication.RandomSigningKey()
+	if errorFromKey != nil {
+		exitWithError("Could not generate key for session tokens: %v", errorFromKey)
+	}
+
+	tokenSigner := authentication.NewTokenSigner(signingKey, 24*time.Hour)
+
+	// Responses to requests with idempotency keys are kept for a day, which is long
+	// enough for any client which is retrying a request.
+	idempotencyPersister := idempotencyPersisterFor(storeSettings.Idempotency, 24*time.Hour)
+
 	serverState :=
 		server.New(
 			contextProvider,
-			"http://localhost:4233",
-			"Local version 2.0",
+			serverConfiguration.AllowedOrigins,
+			serverConfiguration.BackendVersion,
 			&endpoint_parsing.Base32Translator{},
+			tokenSigner,
+			idempotencyPersister,
 			playerCollection,
 			gameCollection)
+
+	// Both listeners are opened before anything is served, so that the server is only
+	// reported as started once it can accept both HTTP requests and gRPC calls.
+	httpListener, errorFromHttpListen := net.Listen("tcp", serverConfiguration.ListenAddress)
+	if errorFromHttpListen != nil {
+		exitWithError("Could not listen for HTTP requests: %v", errorFromHttpListen)
+	}
+
+	// The gRPC service for tools and bots shares the collections and the token signer
+	// with the HTTP endpoints, so that it sees the same players and games and accepts
+	// the same session tokens, but it needs a separate address.
+	if serverConfiguration.RPCListenAddress != "" {
+		rpcListener, errorFromRpcListen :=
+			net.Listen("tcp", serverConfiguration.RPCListenAddress)
+		if errorFromRpcListen != nil {
+			exitWithError("Could not listen for gRPC calls: %v", errorFromRpcListen)
+		}
+
+		rpcServer := rpc.NewServer(rpc.New(gameCollection, playerCollection, tokenSigner))
+		go rpcServer.Serve(rpcListener)
+	}
+
+	fmt.Printf("Local server started.\n")
+
 	http.HandleFunc("/backend/", serverState.HandleBackend)
-	http.ListenAndServe(":8080", nil)
+	errorFromServing := http.Serve(httpListener, nil)
+	exitWithError("Local server stopped: %v", errorFromServing)
+}
+
+// exitWithError writes the given message to the standard error stream and exits with a
+// non-zero status, so that whatever started the server can tell that it failed.
+func exitWithError(messageFormat string, messageArguments ...interface{}) {
+	fmt.Fprintf(os.Stderr, messageFormat+"\n", messageArguments...)
+	os.Exit(1)
+}
+
+// playerPersisterFor creates the persister for players in the backend of the given
+// settings, which must already have been validated, as must those given to the other
+// functions which create persisters.
+func playerPersisterFor(
+	storeSettings configuration.StoreSettings,
+	nameRules naming.Rules) player.StatePersister {
+	switch storeSettings.Backend {
+	case configuration.BackendInMemory:
+		return player_persister.NewInMemoryWithNameRules(nameRules)
+	case configuration.BackendPostgresql:
+		return player_persister.NewInPostgresqlWithNameRules(
+			storeSettings.PostgresqlConnection,
+			nameRules)
+	default:
+		return player_persister.NewInCloudDatastoreWithNameRules(
+			storeSettings.DatastoreClientProvider(player_persister.CloudDatastoreKeyKind),
+			nameRules)
+	}
+}
+
+// gamePersisterFor creates the persister for games in the backend of the given
+// settings.
+func gamePersisterFor(
+	storeSettings configuration.StoreSettings,
+	nameRules naming.Rules) game.StatePersister {
+	if storeSettings.Backend == configuration.BackendInMemory {
+		return game_persister.NewInMemoryWithNameRules(nameRules)
+	}
+
+	return game_persister.NewInCloudDatastoreWithNameRules(
+		storeSettings.DatastoreClientProvider(game_persister.CloudDatastoreKeyKind),
+		nameRules)
+}
+
+// lobbyPersisterFor creates the persister for lobbies in the backend of the given
+// settings, applying the given naming rules.
+func lobbyPersisterFor(
+	storeSettings configuration.StoreSettings,
+	nameRules naming.Rules) game.LobbyPersister {
+	if storeSettings.Backend == configuration.BackendInMemory {
+		return game_persister.NewLobbyInMemoryWithNameRules(nameRules)
+	}
+
+	return game_persister.NewLobbyInCloudDatastoreWithNameRules(
+		storeSettings.DatastoreClientProvider(game_persister.CloudDatastoreLobbyKeyKind),
+		nameRules)
+}
+
+// seriesPersisterFor creates the persister for series of games in the backend of the
+// given settings.
+func seriesPersisterFor(storeSettings configuration.StoreSettings) game.SeriesPersister {
+	if storeSettings.Backend == configuration.BackendInMemory {
+		return game_persister.NewSeriesInMemory()
+	}
+
+	return game_persister.NewSeriesInCloudDatastore(
+		storeSettings.DatastoreClientProvider(game_persister.CloudDatastoreSeriesKeyKind))
+}
+
+// spectatorPersisterFor creates the persister for spectators in the backend of the
+// given settings.
+func spectatorPersisterFor(storeSettings configuration.StoreSettings) game.SpectatorPersister {
+	if storeSettings.Backend == configuration.BackendInMemory {
+		return game_persister.NewSpectatorInMemory()
+	}
+
+	return game_persister.NewSpectatorInCloudDatastore(
+		storeSettings.DatastoreClientProvider(game_persister.CloudDatastoreSpectatorKeyKind))
+}
+
+// historyPersisterFor creates the persister for the histories of finished games in the
+// backend of the given settings.
+func historyPersisterFor(storeSettings configuration.StoreSettings) game.HistoryPersister {
+	if storeSettings.Backend == configuration.BackendInMemory {
+		return game_persister.NewHistoryInMemory()
+	}
+
+	return game_persister.NewHistoryInCloudDatastore(
+		storeSettings.DatastoreClientProvider(game_persister.CloudDatastoreHistoryKeyKind))
+}
+
+// idempotencyPersisterFor creates the persister for the responses to requests with
+// idempotency keys in the backend of the given settings, which keeps the responses for
+// the given period.
+func idempotencyPersisterFor(
+	storeSettings configuration.StoreSettings,
+	retentionPeriod time.Duration) idempotency.Persister {
+	if storeSettings.Backend == configuration.BackendInMemory {
+		return idempotency.NewInMemory(retentionPeriod)
+	}
+
+	return idempotency.NewInCloudDatastore(
+		storeSettings.DatastoreClientProvider(idempotency.CloudDatastoreKeyKind),
+		retentionPeriod)
 }